@@ -0,0 +1,37 @@
+package ipc
+
+import (
+	"net/http"
+
+	"github.com/influxdata/flux"
+)
+
+const DialectType = "arrow"
+
+// ContentType is the media type of an Arrow IPC stream.
+const ContentType = "application/vnd.apache.arrow.stream"
+
+// AddDialectMappings adds the arrow specific dialect mappings.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	return mappings.Add(DialectType, func() flux.Dialect {
+		return &Dialect{}
+	})
+}
+
+// Dialect describes the output format of queries as Arrow IPC streams.
+type Dialect struct {
+	ResultEncoderConfig
+}
+
+func (d Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+func (d Dialect) Encoder() flux.MultiResultEncoder {
+	return NewMultiResultEncoder(d.ResultEncoderConfig)
+}
+
+func (d Dialect) DialectType() flux.DialectType {
+	return DialectType
+}
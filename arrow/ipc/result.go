@@ -0,0 +1,667 @@
+// Package ipc contains result encoders and decoders
+// for the Arrow IPC streaming format.
+//
+// Each table is written as its own IPC stream with a schema,
+// any number of record batches and an end-of-stream marker.
+// The streams for all of the tables in all of the results
+// are written one after another.
+//
+// The schema metadata holds the name of the result the table
+// belongs to. Columns that are part of the group key are marked
+// in their field metadata, which also holds the value of the column
+// in the group key. Errors are written as a stream with a schema
+// that has no fields and the error message in its metadata.
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	arrowlib "github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/arrowutil"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// Keys used in the schema and field metadata.
+const (
+	resultKey        = "flux.result"
+	errorKey         = "flux.error"
+	groupKeyKey      = "flux.group_key"
+	groupKeyValueKey = "flux.group_key_value"
+)
+
+// ResultEncoderConfig configures the ResultEncoder.
+type ResultEncoderConfig struct {
+	// Allocator is the memory allocator used for any buffers
+	// that have to be copied before they are written.
+	// The default is to use an unlimited allocator when this is not set.
+	Allocator memory.Allocator
+}
+
+// ResultEncoder encodes a result as Arrow IPC streams.
+type ResultEncoder struct {
+	c ResultEncoderConfig
+}
+
+// NewResultEncoder creates a new encoder with the provided configuration.
+func NewResultEncoder(c ResultEncoderConfig) *ResultEncoder {
+	if c.Allocator == nil {
+		c.Allocator = memory.DefaultAllocator
+	}
+	return &ResultEncoder{c: c}
+}
+
+// NewMultiResultEncoder creates a new encoder for multiple results.
+// No delimiter is needed between results because every table
+// stream records the name of the result it belongs to.
+func NewMultiResultEncoder(c ResultEncoderConfig) flux.MultiResultEncoder {
+	return &flux.DelimitedMultiResultEncoder{
+		Encoder: NewResultEncoder(c),
+	}
+}
+
+type arrowEncoderError struct {
+	err error
+}
+
+func (e *arrowEncoderError) Error() string {
+	return fmt.Sprintf("arrow encoder error: %s", e.err.Error())
+}
+
+func (e *arrowEncoderError) IsEncoderError() bool {
+	return true
+}
+
+func (e *arrowEncoderError) Unwrap() error {
+	return e.err
+}
+
+func wrapEncodingError(err error) error {
+	if err == nil {
+		return err
+	}
+	return &arrowEncoderError{err: err}
+}
+
+func (e *ResultEncoder) Encode(w io.Writer, result flux.Result) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+	err := result.Tables().Do(func(tbl flux.Table) error {
+		return e.encodeTable(wc, result.Name(), tbl)
+	})
+	return wc.Count(), err
+}
+
+func (e *ResultEncoder) encodeTable(w io.Writer, resultName string, tbl flux.Table) error {
+	schema, err := newSchema(resultName, tbl.Key(), tbl.Cols())
+	if err != nil {
+		tbl.Done()
+		return wrapEncodingError(err)
+	}
+
+	writer := ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(e.c.Allocator))
+	err = tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() == 0 {
+			return nil
+		}
+		rec, err := e.newRecord(schema, cr)
+		if err != nil {
+			return wrapEncodingError(err)
+		}
+		defer rec.Release()
+		return wrapEncodingError(writer.Write(rec))
+	})
+
+	// The stream is closed even when reading the table failed
+	// so the error that is encoded next starts a new stream.
+	if cerr := writer.Close(); cerr != nil && err == nil {
+		err = wrapEncodingError(cerr)
+	}
+	return err
+}
+
+func (e *ResultEncoder) newRecord(schema *arrowlib.Schema, cr flux.ColReader) (array.Record, error) {
+	arrs := make([]array.Interface, len(cr.Cols()))
+	defer func() {
+		for _, arr := range arrs {
+			if arr != nil {
+				arr.Release()
+			}
+		}
+	}()
+
+	for j, c := range cr.Cols() {
+		// The ipc writer does not encode sliced arrays correctly.
+		arr := arrowutil.Compact(table.Values(cr, j), e.c.Allocator)
+		switch c.Type {
+		case flux.TString:
+			// Flux stores strings in binary arrays, but the
+			// ipc writer expects a string array for the schema.
+			arrs[j] = array.NewStringData(arr.Data())
+			arr.Release()
+		case flux.TTime:
+			arrs[j] = withDataType(arr, arrowlib.FixedWidthTypes.Timestamp_ns)
+			arr.Release()
+		default:
+			arrs[j] = arr
+		}
+	}
+	return array.NewRecord(schema, arrs, int64(cr.Len())), nil
+}
+
+// EncodeError encodes the error as a stream with
+// no fields and the error message in its metadata.
+func (e *ResultEncoder) EncodeError(w io.Writer, err error) error {
+	md := arrowlib.NewMetadata([]string{errorKey}, []string{err.Error()})
+	writer := ipc.NewWriter(w, ipc.WithSchema(arrowlib.NewSchema(nil, &md)), ipc.WithAllocator(e.c.Allocator))
+	return writer.Close()
+}
+
+// newSchema creates the schema of the stream for a table.
+func newSchema(resultName string, key flux.GroupKey, cols []flux.ColMeta) (*arrowlib.Schema, error) {
+	fields := make([]arrowlib.Field, len(cols))
+	for j, c := range cols {
+		typ, err := dataType(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields[j] = arrowlib.Field{
+			Name:     c.Label,
+			Type:     typ,
+			Nullable: true,
+		}
+
+		idx := execute.ColIdx(c.Label, key.Cols())
+		if idx < 0 {
+			continue
+		}
+		keys, vals := []string{groupKeyKey}, []string{"true"}
+		if !key.IsNull(idx) {
+			keys = append(keys, groupKeyValueKey)
+			vals = append(vals, encodeValue(key.Value(idx)))
+		}
+		fields[j].Metadata = arrowlib.NewMetadata(keys, vals)
+	}
+	md := arrowlib.NewMetadata([]string{resultKey}, []string{resultName})
+	return arrowlib.NewSchema(fields, &md), nil
+}
+
+// dataType returns the arrow data type used on the wire for a column type.
+func dataType(typ flux.ColType) (arrowlib.DataType, error) {
+	switch typ {
+	case flux.TInt:
+		return arrowlib.PrimitiveTypes.Int64, nil
+	case flux.TUInt:
+		return arrowlib.PrimitiveTypes.Uint64, nil
+	case flux.TFloat:
+		return arrowlib.PrimitiveTypes.Float64, nil
+	case flux.TString:
+		return arrowlib.BinaryTypes.String, nil
+	case flux.TBool:
+		return arrowlib.FixedWidthTypes.Boolean, nil
+	case flux.TTime:
+		return arrowlib.FixedWidthTypes.Timestamp_ns, nil
+	default:
+		return nil, errors.Newf(codes.Internal, "unsupported column type: %s", typ)
+	}
+}
+
+// colType returns the column type for an arrow data type read from the wire.
+func colType(typ arrowlib.DataType) (flux.ColType, error) {
+	switch typ := typ.(type) {
+	case *arrowlib.Int64Type:
+		return flux.TInt, nil
+	case *arrowlib.Uint64Type:
+		return flux.TUInt, nil
+	case *arrowlib.Float64Type:
+		return flux.TFloat, nil
+	case *arrowlib.StringType:
+		return flux.TString, nil
+	case *arrowlib.BooleanType:
+		return flux.TBool, nil
+	case *arrowlib.TimestampType:
+		if typ.Unit == arrowlib.Nanosecond {
+			return flux.TTime, nil
+		}
+	}
+	return flux.TInvalid, errors.Newf(codes.Invalid, "unsupported arrow data type: %s", typ)
+}
+
+// withDataType returns an array with the same buffers as arr
+// that is interpreted as the given data type.
+func withDataType(arr array.Interface, typ arrowlib.DataType) array.Interface {
+	data := arr.Data()
+	nd := array.NewData(typ, data.Len(), data.Buffers(), nil, data.NullN(), data.Offset())
+	defer nd.Release()
+	return array.MakeFromData(nd)
+}
+
+func encodeValue(v values.Value) string {
+	switch v.Type().Nature() {
+	case semantic.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case semantic.UInt:
+		return strconv.FormatUint(v.UInt(), 10)
+	case semantic.Float:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case semantic.Bool:
+		return strconv.FormatBool(v.Bool())
+	case semantic.Time:
+		return strconv.FormatInt(int64(v.Time()), 10)
+	default:
+		return v.Str()
+	}
+}
+
+func decodeValue(s string, typ flux.ColType) (values.Value, error) {
+	switch typ {
+	case flux.TInt:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return values.NewInt(v), nil
+	case flux.TUInt:
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return values.NewUInt(v), nil
+	case flux.TFloat:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return values.NewFloat(v), nil
+	case flux.TBool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return values.NewBool(v), nil
+	case flux.TTime:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return values.NewTime(values.Time(v)), nil
+	default:
+		return values.NewString(s), nil
+	}
+}
+
+// ResultDecoderConfig configures the result decoders.
+type ResultDecoderConfig struct {
+	// Allocator is the memory allocator that will be used during decoding.
+	// The default is to use an unlimited allocator when this is not set.
+	Allocator memory.Allocator
+	// Context is the context for the decoder.
+	// When the context is canceled, the decoder will also be canceled.
+	// This defaults to context.Background.
+	Context context.Context
+}
+
+func (c ResultDecoderConfig) withDefaults() ResultDecoderConfig {
+	if c.Allocator == nil {
+		c.Allocator = memory.DefaultAllocator
+	}
+	if c.Context == nil {
+		c.Context = context.Background()
+	}
+	return c
+}
+
+// ResultDecoder decodes a single result from Arrow IPC streams.
+type ResultDecoder struct {
+	c ResultDecoderConfig
+}
+
+// NewResultDecoder creates a new ResultDecoder.
+func NewResultDecoder(c ResultDecoderConfig) *ResultDecoder {
+	return &ResultDecoder{c: c.withDefaults()}
+}
+
+// Decode reads the first result from r. The tables of the
+// result are read from r as the result is consumed.
+func (d *ResultDecoder) Decode(r io.Reader) (flux.Result, error) {
+	s := newStreamReader(r, d.c)
+	tbl, err := s.peek()
+	if err != nil {
+		return nil, err
+	} else if tbl == nil {
+		return nil, errors.New(codes.Invalid, "no result found")
+	}
+	return &resultDecoder{name: tbl.result, s: s}, nil
+}
+
+// MultiResultDecoder reads multiple results from Arrow IPC streams.
+// A new result starts when the result name of a table changes.
+type MultiResultDecoder struct {
+	c ResultDecoderConfig
+}
+
+// NewMultiResultDecoder creates a new MultiResultDecoder.
+func NewMultiResultDecoder(c ResultDecoderConfig) *MultiResultDecoder {
+	return &MultiResultDecoder{c: c.withDefaults()}
+}
+
+func (d *MultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	return &resultIterator{
+		r: r,
+		s: newStreamReader(r, d.c),
+	}, nil
+}
+
+// resultIterator iterates through the results encoded in r.
+type resultIterator struct {
+	r    io.ReadCloser
+	s    *streamReader
+	next *resultDecoder
+	err  error
+
+	released bool
+}
+
+func (r *resultIterator) More() bool {
+	if r.released {
+		return false
+	}
+
+	// Discard whatever was not read from the previous result.
+	if r.next != nil {
+		if err := r.next.Do(func(tbl flux.Table) error {
+			tbl.Done()
+			return nil
+		}); err != nil {
+			r.err = err
+			r.Release()
+			return false
+		}
+	}
+
+	tbl, err := r.s.peek()
+	if err != nil || tbl == nil {
+		r.err = err
+		r.Release()
+		return false
+	}
+	r.next = &resultDecoder{name: tbl.result, s: r.s}
+	return true
+}
+
+func (r *resultIterator) Next() flux.Result {
+	return r.next
+}
+
+func (r *resultIterator) Release() {
+	if r.released {
+		return
+	}
+	r.s.release()
+	if err := r.r.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	r.released = true
+}
+
+func (r *resultIterator) Err() error {
+	return r.err
+}
+
+func (r *resultIterator) Statistics() flux.Statistics {
+	return flux.Statistics{}
+}
+
+// resultDecoder is a result whose tables are read
+// from the stream reader as long as they have its name.
+type resultDecoder struct {
+	name string
+	s    *streamReader
+}
+
+func (r *resultDecoder) Name() string {
+	return r.name
+}
+
+func (r *resultDecoder) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *resultDecoder) Do(f func(flux.Table) error) error {
+	for {
+		tbl, err := r.s.peek()
+		if err != nil {
+			return err
+		} else if tbl == nil || tbl.result != r.name {
+			return nil
+		}
+		r.s.next = nil
+
+		// Call f on the table, f can return before the table has been fully consumed.
+		if err := f(tbl); err != nil {
+			tbl.Done()
+			return err
+		}
+		// Block until the table has been fully consumed or the context is canceled
+		select {
+		case <-tbl.done:
+		case <-r.s.c.Context.Done():
+			return r.s.c.Context.Err()
+		}
+	}
+}
+
+// streamReader reads the table streams one after another.
+type streamReader struct {
+	c    ResultDecoderConfig
+	r    *bufio.Reader
+	next *tableDecoder
+	err  error
+}
+
+func newStreamReader(r io.Reader, c ResultDecoderConfig) *streamReader {
+	return &streamReader{
+		c: c,
+		r: bufio.NewReader(r),
+	}
+}
+
+// peek returns the next table without consuming it.
+// It returns nil when there are no more tables and
+// returns the error if the next stream holds an error.
+// The previous table must have been consumed before this is called.
+func (s *streamReader) peek() (*tableDecoder, error) {
+	if s.next != nil || s.err != nil {
+		return s.next, s.err
+	}
+	s.next, s.err = s.readTable()
+	return s.next, s.err
+}
+
+func (s *streamReader) readTable() (*tableDecoder, error) {
+	// The ipc reader fails on an empty input,
+	// so check for the end of the input first.
+	if _, err := s.r.Peek(1); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, codes.Inherit, "failed to read arrow stream")
+	}
+
+	r, err := ipc.NewReader(s.r, ipc.WithAllocator(s.c.Allocator))
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "failed to read arrow schema")
+	}
+	md := r.Schema().Metadata()
+	if idx := md.FindKey(errorKey); idx >= 0 {
+		r.Release()
+		// TODO: We should determine the correct error code here:
+		//   https://github.com/influxdata/flux/issues/1916
+		return nil, errors.New(codes.Internal, md.Values()[idx])
+	}
+
+	tbl, err := newTableDecoder(r)
+	if err != nil {
+		r.Release()
+		return nil, err
+	}
+	return tbl, nil
+}
+
+func (s *streamReader) release() {
+	if s.next != nil {
+		s.next.Done()
+		s.next = nil
+	}
+}
+
+// tableDecoder is a flux.Table that reads the
+// record batches of a single stream.
+type tableDecoder struct {
+	result string
+	key    flux.GroupKey
+	cols   []flux.ColMeta
+	r      *ipc.Reader
+	empty  bool
+
+	used int32
+	done chan struct{}
+}
+
+func newTableDecoder(r *ipc.Reader) (*tableDecoder, error) {
+	schema := r.Schema()
+	var resultName string
+	if idx := schema.Metadata().FindKey(resultKey); idx >= 0 {
+		resultName = schema.Metadata().Values()[idx]
+	}
+
+	fields := schema.Fields()
+	cols := make([]flux.ColMeta, len(fields))
+	var (
+		keyCols   []flux.ColMeta
+		keyValues []values.Value
+	)
+	for j, field := range fields {
+		typ, err := colType(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		cols[j] = flux.ColMeta{Label: field.Name, Type: typ}
+
+		if field.Metadata.FindKey(groupKeyKey) < 0 {
+			continue
+		}
+		v := values.NewNull(flux.SemanticType(typ))
+		if idx := field.Metadata.FindKey(groupKeyValueKey); idx >= 0 {
+			v, err = decodeValue(field.Metadata.Values()[idx], typ)
+			if err != nil {
+				return nil, errors.Wrapf(err, codes.Invalid, "invalid group key value for column %q", field.Name)
+			}
+		}
+		keyCols = append(keyCols, cols[j])
+		keyValues = append(keyValues, v)
+	}
+
+	tbl := &tableDecoder{
+		result: resultName,
+		key:    execute.NewGroupKey(keyCols, keyValues),
+		cols:   cols,
+		r:      r,
+		done:   make(chan struct{}),
+	}
+	// Read the first record so we know if the table is empty.
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to read arrow record")
+		}
+		tbl.empty = true
+	}
+	return tbl, nil
+}
+
+func (d *tableDecoder) Key() flux.GroupKey {
+	return d.key
+}
+
+func (d *tableDecoder) Cols() []flux.ColMeta {
+	return d.cols
+}
+
+func (d *tableDecoder) Empty() bool {
+	return d.empty
+}
+
+func (d *tableDecoder) Do(f func(flux.ColReader) error) error {
+	if !atomic.CompareAndSwapInt32(&d.used, 0, 1) {
+		return errors.New(codes.Internal, "table already read")
+	}
+	// Read the rest of the stream even if f fails
+	// so the next table can be read.
+	defer d.release()
+
+	if d.empty {
+		return nil
+	}
+	for {
+		buf := d.newBuffer(d.r.Record())
+		err := f(buf)
+		buf.Release()
+		if err != nil {
+			return err
+		}
+
+		if !d.r.Next() {
+			if err := d.r.Err(); err != nil {
+				return errors.Wrap(err, codes.Invalid, "failed to read arrow record")
+			}
+			return nil
+		}
+	}
+}
+
+func (d *tableDecoder) newBuffer(rec array.Record) *arrow.TableBuffer {
+	buf := &arrow.TableBuffer{
+		GroupKey: d.key,
+		Columns:  d.cols,
+		Values:   make([]array.Interface, len(d.cols)),
+	}
+	for j, c := range d.cols {
+		col := rec.Column(j)
+		switch c.Type {
+		case flux.TString:
+			// The ipc reader produces string arrays, but flux
+			// reads string columns as binary arrays.
+			buf.Values[j] = array.NewBinaryData(col.Data())
+		case flux.TTime:
+			buf.Values[j] = withDataType(col, arrowlib.PrimitiveTypes.Int64)
+		default:
+			col.Retain()
+			buf.Values[j] = col
+		}
+	}
+	return buf
+}
+
+func (d *tableDecoder) Done() {
+	_ = d.Do(func(flux.ColReader) error { return nil })
+}
+
+func (d *tableDecoder) release() {
+	for d.r.Next() {
+	}
+	d.r.Release()
+	close(d.done)
+}
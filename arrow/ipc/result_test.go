@@ -0,0 +1,216 @@
+package ipc_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow/ipc"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+)
+
+// tableResult is a flux.Result with any kind of table.
+type tableResult struct {
+	name   string
+	tables table.Iterator
+}
+
+func (r *tableResult) Name() string {
+	return r.name
+}
+
+func (r *tableResult) Tables() flux.TableIterator {
+	return r.tables
+}
+
+func newTables() []*executetest.Table {
+	return []*executetest.Table{
+		{
+			KeyCols: []string{"_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "n", Type: flux.TInt},
+				{Label: "u", Type: flux.TUInt},
+				{Label: "b", Type: flux.TBool},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "cpu", "a", 2.5, int64(1), uint64(1), true},
+				{execute.Time(2), "cpu", "a", nil, nil, uint64(2), nil},
+				{execute.Time(3), "cpu", "a", 4.0, int64(-3), nil, false},
+			},
+		},
+		{
+			// The value of host in the group key is null.
+			KeyCols:   []string{"_measurement", "host"},
+			KeyValues: []interface{}{"cpu", nil},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "cpu", nil, 1.0},
+			},
+		},
+		{
+			KeyCols:   []string{"_start", "_value"},
+			KeyValues: []interface{}{execute.Time(10), int64(42)},
+			ColMeta: []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_value", Type: flux.TInt},
+			},
+		},
+	}
+}
+
+func TestMultiResultEncoder_RoundTrip(t *testing.T) {
+	results := []flux.Result{
+		&executetest.Result{Nm: "_result", Tbls: newTables()},
+		&executetest.Result{Nm: "other", Tbls: newTables()[:1]},
+	}
+
+	var buf bytes.Buffer
+	enc := ipc.NewMultiResultEncoder(ipc.ResultEncoderConfig{})
+	if _, err := enc.Encode(&buf, flux.NewSliceResultIterator(results)); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := ipc.NewMultiResultDecoder(ipc.ResultDecoderConfig{})
+	iter, err := dec.Decode(ioutil.NopCloser(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*executetest.Result{
+		{Nm: "_result", Tbls: newTables()},
+		{Nm: "other", Tbls: newTables()[:1]},
+	}
+	var got []*executetest.Result
+	for iter.More() {
+		got = append(got, executetest.ConvertResult(iter.Next()))
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range want {
+		r.Normalize()
+	}
+	for _, r := range got {
+		r.Normalize()
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected results -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestMultiResultDecoder_SkipResult(t *testing.T) {
+	results := []flux.Result{
+		&executetest.Result{Nm: "a", Tbls: newTables()},
+		&executetest.Result{Nm: "b", Tbls: newTables()[2:]},
+	}
+
+	var buf bytes.Buffer
+	enc := ipc.NewMultiResultEncoder(ipc.ResultEncoderConfig{})
+	if _, err := enc.Encode(&buf, flux.NewSliceResultIterator(results)); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := ipc.NewMultiResultDecoder(ipc.ResultDecoderConfig{})
+	iter, err := dec.Decode(ioutil.NopCloser(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tables of the first result are never read.
+	var names []string
+	for iter.More() {
+		names = append(names, iter.Next().Name())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !cmp.Equal(want, names) {
+		t.Errorf("unexpected result names -want/+got:\n%s", cmp.Diff(want, names))
+	}
+}
+
+func TestMultiResultEncoder_Error(t *testing.T) {
+	results := []flux.Result{
+		&executetest.Result{Nm: "_result", Tbls: newTables()[:1]},
+		&executetest.Result{Nm: "other", Err: errors.New(codes.Internal, "expected error")},
+	}
+
+	var buf bytes.Buffer
+	enc := ipc.NewMultiResultEncoder(ipc.ResultEncoderConfig{})
+	if _, err := enc.Encode(&buf, flux.NewSliceResultIterator(results)); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := ipc.NewMultiResultDecoder(ipc.ResultDecoderConfig{})
+	iter, err := dec.Decode(ioutil.NopCloser(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !iter.More() {
+		t.Fatalf("expected a result, got error: %v", iter.Err())
+	}
+
+	// The error is reported by the result that was being read when
+	// it was found and then by the iterator.
+	got := executetest.ConvertResult(iter.Next())
+	if want := 1; len(got.Tbls) != want {
+		t.Errorf("unexpected number of tables -want/+got:\n\t- %d\n\t+ %d", want, len(got.Tbls))
+	}
+	if got.Err == nil {
+		t.Error("expected error from result")
+	}
+	if iter.More() {
+		t.Fatal("expected no more results")
+	}
+	if err := iter.Err(); err == nil {
+		t.Fatal("expected error")
+	} else if want, got := "expected error", err.Error(); want != got {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestResultEncoder_SlicedBuffers(t *testing.T) {
+	// A row wise table produces buffers that are slices
+	// of larger arrays, which are copied before they are written.
+	mem := &memory.Allocator{}
+	result := &tableResult{
+		name:   "_result",
+		tables: table.Iterator{&executetest.RowWiseTable{Table: newTables()[0]}},
+	}
+
+	var buf bytes.Buffer
+	if _, err := ipc.NewResultEncoder(ipc.ResultEncoderConfig{Allocator: mem}).Encode(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if got := mem.Allocated(); got != 0 {
+		t.Errorf("expected all memory to be released, got %d bytes", got)
+	}
+
+	res, err := ipc.NewResultDecoder(ipc.ResultDecoderConfig{}).Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &executetest.Result{Nm: "_result", Tbls: newTables()[:1]}
+	got := executetest.ConvertResult(res)
+	want.Normalize()
+	got.Normalize()
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected result -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -32,6 +32,25 @@ func NewBuilder(typ flux.ColType, mem memory.Allocator) array.Builder {
 	}
 }
 
+// DataType returns the arrow data type used to store
+// a column of the given type.
+func DataType(typ flux.ColType) arrow.DataType {
+	switch typ {
+	case flux.TInt, flux.TTime:
+		return arrow.PrimitiveTypes.Int64
+	case flux.TUInt:
+		return arrow.PrimitiveTypes.Uint64
+	case flux.TFloat:
+		return arrow.PrimitiveTypes.Float64
+	case flux.TString:
+		return arrow.BinaryTypes.String
+	case flux.TBool:
+		return arrow.FixedWidthTypes.Boolean
+	default:
+		panic(fmt.Errorf("unknown data type for type: %s", typ))
+	}
+}
+
 // AppendValue will append a value to the builder.
 //
 // Be aware when using this function that it will perform
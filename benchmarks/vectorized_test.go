@@ -0,0 +1,127 @@
+// Package benchmarks_test compares evaluating the functions passed
+// to map and filter one row at a time with evaluating them over a
+// whole column reader at once.
+//
+// Run the benchmarks with:
+//
+//	go test -run=^$ -bench=. ./benchmarks
+package benchmarks_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/compiler"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const benchmarkRows = 10000
+
+func benchmarkTable(n int) *executetest.Table {
+	tbl := &executetest.Table{
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "host", Type: flux.TString},
+			{Label: "n", Type: flux.TInt},
+		},
+	}
+	for i := 0; i < n; i++ {
+		var v interface{} = float64(i%100) - 50
+		if i%10 == 0 {
+			v = nil
+		}
+		tbl.Data = append(tbl.Data, []interface{}{
+			execute.Time(i), v, fmt.Sprintf("server%02d", i%20), int64(i % 7),
+		})
+	}
+	return tbl
+}
+
+func BenchmarkFilter(b *testing.B) {
+	for _, fn := range []string{
+		`(r) => r._value > 0.0`,
+		`(r) => r._value > 0.0 and r.host == "server01"`,
+		`(r) => r.host =~ /^server0/ or r.n != 0 and 100 / r.n > 20`,
+	} {
+		b.Run(fn, func(b *testing.B) {
+			benchmarkFunction(b, executetest.FunctionExpression(b, fn))
+		})
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	for _, fn := range []string{
+		`(r) => ({r with _value: r._value * 2.0})`,
+		`(r) => ({_time: r._time, _value: if r._value > 0.0 then r._value else -r._value, n: r.n + 1})`,
+	} {
+		b.Run(fn, func(b *testing.B) {
+			benchmarkFunction(b, executetest.FunctionExpression(b, fn))
+		})
+	}
+}
+
+func benchmarkFunction(b *testing.B, fn *semantic.FunctionExpression) {
+	tbl := benchmarkTable(benchmarkRows)
+	cols := tbl.Cols()
+	properties := make([]semantic.PropertyType, len(cols))
+	for j, c := range cols {
+		properties[j] = semantic.PropertyType{Key: []byte(c.Label), Value: flux.SemanticType(c.Type)}
+	}
+	recordType := semantic.NewObjectType(properties)
+	inType := semantic.NewObjectType([]semantic.PropertyType{
+		{Key: []byte("r"), Value: recordType},
+	})
+
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		b.Run("row", func(b *testing.B) {
+			f, err := compiler.Compile(nil, fn, inType)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				for i := 0; i < cr.Len(); i++ {
+					record := values.NewObject(recordType)
+					for j, c := range cols {
+						record.Set(c.Label, execute.ValueForRow(cr, i, j))
+					}
+					args := values.NewObject(inType)
+					args.Set("r", record)
+					if _, err := f.Eval(context.Background(), args); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run("vectorized", func(b *testing.B) {
+			f, err := compiler.CompileVectorized(nil, fn, cols)
+			if err != nil {
+				b.Fatal(err)
+			}
+			mem := &memory.Allocator{}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				arrs, err := f.Eval(context.Background(), cr, mem)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, arr := range arrs {
+					arr.Release()
+				}
+			}
+		})
+		return nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux/fluxinit"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/repl"
+	"github.com/influxdata/flux/resultdiff"
+	"github.com/influxdata/flux/runtime"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <script> <script>",
+	Short: "Compare the results of two Flux scripts",
+	Long: `Compare the results of two Flux scripts from strings or files (use @ as prefix to the file).
+
+The results are aligned by name and group key, and the rows that the second
+script added, removed or changed compared to the first one are printed.
+The command fails when the results differ.`,
+	Args: cobra.ExactArgs(2),
+	RunE: diff,
+}
+
+type diffFlags struct {
+	absTolerance float64
+	relTolerance float64
+}
+
+var diffOpts diffFlags
+
+// errResultsDiffer is returned when the results of the scripts differ
+// so that the command exits with a non-zero status.
+var errResultsDiffer = errors.New("results differ")
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.SilenceUsage = true
+	diffCmd.SilenceErrors = true
+	diffCmd.Flags().Float64Var(&diffOpts.absTolerance, "abs-tolerance", 0, "largest absolute difference between two numeric values that are equal")
+	diffCmd.Flags().Float64Var(&diffOpts.relTolerance, "rel-tolerance", 0, "largest difference between two numeric values that are equal, relative to the larger of their magnitudes")
+}
+
+func diff(cmd *cobra.Command, args []string) error {
+	if diffOpts.absTolerance < 0 || diffOpts.relTolerance < 0 {
+		return fmt.Errorf("tolerances must not be negative")
+	}
+	fluxinit.FluxInit()
+	ctx, _, err := injectDependencies(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// Both scripts are compiled with the same now so
+	// that relative time ranges are the same.
+	now := time.Now()
+	programs := make([]*lang.AstProgram, len(args))
+	for i, arg := range args {
+		script, err := repl.LoadQuery(arg)
+		if err != nil {
+			return err
+		}
+		if programs[i], err = lang.Compile(script, runtime.Default, now); err != nil {
+			return fmt.Errorf("failed to compile script %d: %v", i+1, err)
+		}
+	}
+
+	report, err := resultdiff.Programs(ctx, programs[0], programs[1], &memory.Allocator{}, resultdiff.Options{
+		AbsTolerance: diffOpts.absTolerance,
+		RelTolerance: diffOpts.relTolerance,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := report.WriteTo(cmd.OutOrStdout()); err != nil {
+		return err
+	}
+	if !report.Equal() {
+		return errResultsDiffer
+	}
+	return nil
+}
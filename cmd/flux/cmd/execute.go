@@ -27,9 +27,14 @@ func init() {
 
 const DefaultInfluxDBHost = "http://localhost:8086"
 
-func injectDependencies(ctx context.Context) (context.Context, flux.Dependencies) {
+func injectDependencies(ctx context.Context) (context.Context, flux.Dependencies, error) {
 	deps := flux.NewDefaultDependencies()
-	deps.Deps.FilesystemService = filesystem.SystemFS
+	deps.Deps.FilesystemService = filesystem.WritableSystemFS
+	ss, err := newSecretService(secretOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	deps.Deps.SecretService = ss
 
 	// inject the dependencies to the context.
 	// one useful example is socket.from, kafka.to, and sql.from/sql.to where we need
@@ -43,12 +48,15 @@ func injectDependencies(ctx context.Context) (context.Context, flux.Dependencies
 			},
 		},
 	}
-	return ip.Inject(ctx), deps
+	return ip.Inject(ctx), deps, nil
 }
 
 func execute(cmd *cobra.Command, args []string) error {
 	fluxinit.FluxInit()
-	ctx, deps := injectDependencies(context.Background())
+	ctx, deps, err := injectDependencies(context.Background())
+	if err != nil {
+		return err
+	}
 	r := repl.New(ctx, deps)
 	if err := r.Input(args[0]); err != nil {
 		return fmt.Errorf("failed to execute query: %v", err)
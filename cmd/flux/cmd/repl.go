@@ -13,11 +13,15 @@ var replCmd = &cobra.Command{
 	Use:   "repl",
 	Short: "Launch a Flux REPL",
 	Long:  "Launch a Flux REPL (Read-Eval-Print-Loop)",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		fluxinit.FluxInit()
-		ctx, deps := injectDependencies(context.Background())
+		ctx, deps, err := injectDependencies(context.Background())
+		if err != nil {
+			return err
+		}
 		r := repl.New(ctx, deps)
 		r.Run()
+		return nil
 	},
 }
 
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/secret"
+)
+
+type secretFlags struct {
+	service string
+
+	dir     string
+	mapFile string
+
+	vaultAddr      string
+	vaultToken     string
+	vaultMount     string
+	vaultPrefix    string
+	vaultField     string
+	vaultKVVersion int
+	vaultCacheTTL  time.Duration
+}
+
+var secretOpts secretFlags
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&secretOpts.service, "secrets", "empty", "secret service that secrets are loaded from: empty, env, file or vault")
+	flags.StringVar(&secretOpts.dir, "secrets-dir", "", "directory with one file per secret key for the file secret service")
+	flags.StringVar(&secretOpts.mapFile, "secrets-file", "", "JSON or YAML file of secret keys to secrets for the file secret service")
+	flags.StringVar(&secretOpts.vaultAddr, "vault-addr", os.Getenv("VAULT_ADDR"), "address of the vault server (defaults to $VAULT_ADDR)")
+	flags.StringVar(&secretOpts.vaultToken, "vault-token", "", "token of the vault server (defaults to $VAULT_TOKEN)")
+	flags.StringVar(&secretOpts.vaultMount, "vault-mount", secret.DefaultVaultMount, "mount of the vault key/value secrets engine")
+	flags.StringVar(&secretOpts.vaultPrefix, "vault-prefix", "", "path within the vault mount that secret keys are relative to")
+	flags.StringVar(&secretOpts.vaultField, "vault-field", secret.DefaultVaultField, "field of a vault secret that holds its value")
+	flags.IntVar(&secretOpts.vaultKVVersion, "vault-kv-version", 2, "version of the vault key/value secrets engine")
+	flags.DurationVar(&secretOpts.vaultCacheTTL, "vault-cache-ttl", 5*time.Minute, "how long secrets read from vault are cached (0 disables caching)")
+}
+
+// newSecretService creates the secret service that the flags select.
+func newSecretService(opts secretFlags) (secret.Service, error) {
+	switch opts.service {
+	case "", "empty":
+		return secret.EmptySecretService{}, nil
+	case "env":
+		return secret.EnvironmentSecretService{}, nil
+	case "file":
+		if opts.dir == "" && opts.mapFile == "" {
+			return nil, fmt.Errorf("the file secret service requires --secrets-dir or --secrets-file")
+		}
+		return secret.FileSecretService{
+			Dir:     opts.dir,
+			MapFile: opts.mapFile,
+		}, nil
+	case "vault":
+		// The token is not a flag default so it is not printed with the usage.
+		token := opts.vaultToken
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+		return secret.NewVaultSecretService(secret.VaultConfig{
+			Address:   opts.vaultAddr,
+			Token:     token,
+			Mount:     opts.vaultMount,
+			Prefix:    opts.vaultPrefix,
+			Field:     opts.vaultField,
+			KVVersion: opts.vaultKVVersion,
+			CacheTTL:  opts.vaultCacheTTL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown secret service %q, must be one of empty, env, file or vault", opts.service)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/secret"
+)
+
+func TestNewSecretService(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		opts    secretFlags
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: secret.EmptySecretService{},
+		},
+		{
+			name: "env",
+			opts: secretFlags{service: "env"},
+			want: secret.EnvironmentSecretService{},
+		},
+		{
+			name: "file",
+			opts: secretFlags{service: "file", dir: "/run/secrets", mapFile: "secrets.yaml"},
+			want: secret.FileSecretService{Dir: "/run/secrets", MapFile: "secrets.yaml"},
+		},
+		{
+			name:    "file without files",
+			opts:    secretFlags{service: "file"},
+			wantErr: true,
+		},
+		{
+			name: "vault",
+			opts: secretFlags{service: "vault", vaultAddr: "http://127.0.0.1:8200", vaultKVVersion: 2},
+			want: &secret.VaultSecretService{},
+		},
+		{
+			name:    "vault without address",
+			opts:    secretFlags{service: "vault", vaultKVVersion: 2},
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			opts:    secretFlags{service: "keychain"},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newSecretService(tc.opts)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			switch want := tc.want.(type) {
+			case *secret.VaultSecretService:
+				if _, ok := got.(*secret.VaultSecretService); !ok {
+					t.Errorf("unexpected secret service: want %T got %T", want, got)
+				}
+			default:
+				if got != want {
+					t.Errorf("unexpected secret service: want %#v got %#v", want, got)
+				}
+			}
+		})
+	}
+}
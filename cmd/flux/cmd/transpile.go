@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/fluxinit"
+	"github.com/influxdata/flux/promql"
+	"github.com/influxdata/flux/repl"
+	promqlparser "github.com/influxdata/promql/v2"
+	"github.com/spf13/cobra"
+)
+
+// transpileCmd represents the transpile command
+var transpileCmd = &cobra.Command{
+	Use:   "transpile [expression]",
+	Short: "Transpile a PromQL expression into a Flux script",
+	Long:  "Transpile a PromQL expression into a Flux script (reads the expression from stdin when no argument is given)",
+	RunE:  transpile,
+}
+
+type transpileFlags struct {
+	start      string
+	end        string
+	resolution time.Duration
+	bucket     string
+	execute    bool
+}
+
+var transpileOpts transpileFlags
+
+func init() {
+	rootCmd.AddCommand(transpileCmd)
+	transpileCmd.SilenceUsage = true
+	transpileCmd.SilenceErrors = true
+	transpileCmd.Flags().StringVar(&transpileOpts.start, "start", "", "start of the evaluation range as an RFC3339 time or a duration relative to now (defaults to the end)")
+	transpileCmd.Flags().StringVar(&transpileOpts.end, "end", "", "end of the evaluation range as an RFC3339 time or a duration relative to now (defaults to now)")
+	transpileCmd.Flags().DurationVar(&transpileOpts.resolution, "resolution", time.Minute, "resolution step of the evaluation range")
+	transpileCmd.Flags().StringVar(&transpileOpts.bucket, "bucket", "prometheus", "bucket to read the samples from")
+	transpileCmd.Flags().BoolVarP(&transpileOpts.execute, "execute", "e", false, "execute the transpiled Flux script instead of printing it")
+}
+
+func transpile(cmd *cobra.Command, args []string) error {
+	var expr string
+	if len(args) > 0 {
+		expr = strings.Join(args, " ")
+	} else {
+		data, err := ioutil.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		expr = string(data)
+	}
+
+	script, err := transpilePromQL(expr, transpileOpts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !transpileOpts.execute {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), script)
+		return err
+	}
+
+	fluxinit.FluxInit()
+	ctx, deps, err := injectDependencies(context.Background())
+	if err != nil {
+		return err
+	}
+	r := repl.New(ctx, deps)
+	if err := r.Input(script); err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+	return nil
+}
+
+// transpilePromQL transpiles the PromQL expression into a formatted Flux script.
+func transpilePromQL(expr string, opts transpileFlags, now time.Time) (string, error) {
+	if strings.TrimSpace(expr) == "" {
+		return "", fmt.Errorf("no PromQL expression given")
+	}
+	end, err := parseTranspileTime(opts.end, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid end: %v", err)
+	}
+	start := end
+	if opts.start != "" {
+		if start, err = parseTranspileTime(opts.start, now); err != nil {
+			return "", fmt.Errorf("invalid start: %v", err)
+		}
+	}
+	if start.After(end) {
+		return "", fmt.Errorf("start %s must not be after end %s", start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+	}
+	if opts.resolution <= 0 {
+		return "", fmt.Errorf("resolution must be positive, got %s", opts.resolution)
+	}
+
+	node, err := promqlparser.ParseExpr(expr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse PromQL expression: %v", err)
+	}
+	t := &promql.Transpiler{
+		Bucket:     opts.bucket,
+		Start:      start,
+		End:        end,
+		Resolution: opts.resolution,
+	}
+	file, err := t.Transpile(node)
+	if err != nil {
+		return "", err
+	}
+
+	return ast.Format(file), nil
+}
+
+// parseTranspileTime parses an RFC3339 time or a duration relative to now.
+// An empty string is now.
+func parseTranspileTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return now, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
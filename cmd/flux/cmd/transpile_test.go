@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+)
+
+func TestTranspilePromQL(t *testing.T) {
+	now := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+	script, err := transpilePromQL(`rate(http_requests_total{job="api"}[5m])`, transpileFlags{
+		start:      "2020-01-01T00:00:00Z",
+		end:        "-30m",
+		resolution: time.Minute,
+		bucket:     "metrics",
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg := parser.ParseSource(script); ast.Check(pkg) > 0 {
+		t.Fatalf("unexpected error in the transpiled script: %s\n%s", ast.GetError(pkg), script)
+	}
+	for _, want := range []string{
+		`import "internal/promql"`,
+		`from(bucket: "metrics")`,
+		// The range starts five minutes before the start for the range selector.
+		`start: 2019-12-31T23:55:00Z`,
+		`stop: 2020-01-01T00:30:00Z`,
+		`r.job == "api"`,
+		`promql.extrapolatedRate(`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q in the transpiled script:\n%s", want, script)
+		}
+	}
+}
+
+func TestTranspilePromQL_Errors(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		expr string
+		opts transpileFlags
+		want string
+	}{
+		{
+			name: "empty expression",
+			expr: " \n",
+			opts: transpileFlags{resolution: time.Minute},
+			want: "no PromQL expression given",
+		},
+		{
+			name: "invalid expression",
+			expr: `up{`,
+			opts: transpileFlags{resolution: time.Minute},
+			want: "failed to parse PromQL expression",
+		},
+		{
+			name: "invalid start",
+			expr: `up`,
+			opts: transpileFlags{start: "yesterday", resolution: time.Minute},
+			want: "invalid start",
+		},
+		{
+			name: "start after end",
+			expr: `up`,
+			opts: transpileFlags{start: "1h", resolution: time.Minute},
+			want: "must not be after end",
+		},
+		{
+			name: "zero resolution",
+			expr: `up`,
+			opts: transpileFlags{},
+			want: "resolution must be positive",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := transpilePromQL(tc.expr, tc.opts, now); err == nil {
+				t.Fatal("expected error")
+			} else if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", tc.want, err)
+			}
+		})
+	}
+}
+
+// runTranspile runs the transpile command with the expression on stdin.
+func runTranspile(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	// The flags keep their values between runs of the command.
+	transpileOpts = transpileFlags{resolution: time.Minute, bucket: "prometheus"}
+	defer func() { transpileOpts = transpileFlags{resolution: time.Minute, bucket: "prometheus"} }()
+
+	var out bytes.Buffer
+	rootCmd.SetArgs(append([]string{"transpile"}, args...))
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetIn(nil)
+		rootCmd.SetOut(nil)
+	}()
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestTranspileCommand_Stdin(t *testing.T) {
+	out, err := runTranspile(t, `rate(http_requests_total{job="api"}[5m])`+"\n",
+		"--bucket", "metrics",
+		"--start", "2020-01-01T00:00:00Z",
+		"--end", "2020-01-01T00:30:00Z",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`from(bucket: "metrics")`,
+		`r.job == "api"`,
+		`promql.extrapolatedRate(`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the output:\n%s", want, out)
+		}
+	}
+}
+
+func TestTranspileCommand_StdinEmpty(t *testing.T) {
+	if _, err := runTranspile(t, ""); err == nil {
+		t.Fatal("expected error")
+	} else if want := "no PromQL expression given"; !strings.Contains(err.Error(), want) {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, err)
+	}
+}
+
+func TestTranspileCommand_Execute(t *testing.T) {
+	// The server stands in for InfluxDB and records the
+	// queries that the transpiled script sends to it.
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("unexpected request body: %s", err)
+		}
+		queries = append(queries, req.Query)
+		w.Header().Set("Content-Type", "text/csv")
+	}))
+	defer server.Close()
+
+	// The flux binary sets the default host in the same way.
+	plan.RegisterLogicalRules(influxdb.DefaultFromAttributes{
+		Host: &server.URL,
+	})
+
+	// The flux runtime can only be initialized once,
+	// so this is the only test that executes a script.
+	if _, err := runTranspile(t, `up{job="api"}`,
+		"--execute",
+		"--bucket", "metrics",
+		"--start", "2020-01-01T00:00:00Z",
+		"--end", "2020-01-01T00:30:00Z",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 1 {
+		t.Fatalf("expected one query, got %d", len(queries))
+	}
+	for _, want := range []string{
+		`from(bucket: "metrics")`,
+		`r["job"] == "api"`,
+	} {
+		if !strings.Contains(queries[0], want) {
+			t.Errorf("expected %q in the query sent to the server:\n%s", want, queries[0])
+		}
+	}
+}
@@ -0,0 +1,1249 @@
+package compiler
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// VectorizedFunc is a function that has been compiled to evaluate
+// every row of a flux.ColReader at once instead of one record at a time.
+type VectorizedFunc interface {
+	// Type returns the return type of the function.
+	Type() semantic.MonoType
+
+	// Labels returns the property names of the record returned by
+	// the function in sorted order. It returns nil if the function
+	// does not return a record.
+	Labels() []string
+
+	// Passthrough reports whether the property with the label is
+	// the unmodified input column with the same label.
+	Passthrough(label string) bool
+
+	// Eval evaluates the function for every row of the column reader.
+	// The column reader must have the columns that the function was
+	// compiled with. It returns an array for each of the labels or a
+	// single array if the function does not return a record.
+	// The caller is responsible for releasing the arrays.
+	Eval(ctx context.Context, cr flux.ColReader, mem memory.Allocator) ([]array.Interface, error)
+}
+
+// CompileVectorized compiles a function with a single record parameter
+// for the columns of a table.
+//
+// Only a subset of the language is supported. The body of the function
+// must be a single expression made of literals, values in scope, column
+// accesses, arithmetic, comparisons, logical operators and conditionals,
+// optionally wrapped in a record. An error with the code
+// codes.Unimplemented is returned for any other function and the function
+// should be evaluated with Compile instead.
+func CompileVectorized(scope Scope, f *semantic.FunctionExpression, cols []flux.ColMeta) (VectorizedFunc, error) {
+	if f.Parameters == nil || len(f.Parameters.List) != 1 {
+		return nil, errors.New(codes.Unimplemented, "vectorized functions must have a single parameter")
+	}
+	body, ok := f.GetFunctionBodyExpression()
+	if !ok {
+		return nil, errors.New(codes.Unimplemented, "vectorized functions must have a single expression")
+	}
+
+	c := &vectorCompiler{
+		scope:      scope,
+		recordName: f.Parameters.List[0].Key.Name,
+		cols:       cols,
+	}
+	if obj, ok := body.(*semantic.ObjectExpression); ok {
+		return c.compileRecord(obj)
+	}
+	root, err := c.compile(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := basicTypes[root.nature()]; !ok {
+		return nil, errors.Newf(codes.Unimplemented, "vectorized functions cannot return %v", root.nature())
+	}
+	return &vectorizedFn{root: root}, nil
+}
+
+// basicTypes are the types that a vectorized function
+// can evaluate into an array.
+var basicTypes = map[semantic.Nature]semantic.MonoType{
+	semantic.Bool:   semantic.BasicBool,
+	semantic.Int:    semantic.BasicInt,
+	semantic.UInt:   semantic.BasicUint,
+	semantic.Float:  semantic.BasicFloat,
+	semantic.String: semantic.BasicString,
+	semantic.Time:   semantic.BasicTime,
+}
+
+type vectorCompiler struct {
+	scope      Scope
+	recordName string
+	cols       []flux.ColMeta
+}
+
+func (c *vectorCompiler) compileRecord(obj *semantic.ObjectExpression) (VectorizedFunc, error) {
+	properties := make(map[string]vectorEvaluator)
+	if obj.With != nil {
+		if obj.With.Name != c.recordName {
+			return nil, errors.New(codes.Unimplemented, "vectorized functions can only extend the input record")
+		}
+		for j, col := range c.cols {
+			e, err := c.column(j)
+			if err != nil {
+				return nil, err
+			}
+			properties[col.Label] = e
+		}
+	}
+	for _, p := range obj.Properties {
+		e, err := c.compile(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := basicTypes[e.nature()]; !ok {
+			return nil, errors.Newf(codes.Unimplemented, "vectorized functions cannot return %v", e.nature())
+		}
+		properties[p.Key.Key()] = e
+	}
+
+	fn := &vectorizedRecordFn{
+		labels:     make([]string, 0, len(properties)),
+		properties: make([]vectorEvaluator, 0, len(properties)),
+	}
+	for label := range properties {
+		fn.labels = append(fn.labels, label)
+	}
+	sort.Strings(fn.labels)
+	for _, label := range fn.labels {
+		fn.properties = append(fn.properties, properties[label])
+	}
+	return fn, nil
+}
+
+func (c *vectorCompiler) column(j int) (vectorEvaluator, error) {
+	t := flux.SemanticType(c.cols[j].Type).Nature()
+	if _, ok := basicTypes[t]; !ok {
+		return nil, errors.Newf(codes.Unimplemented, "vectorized functions do not support column type %v", c.cols[j].Type)
+	}
+	return &columnVectorEvaluator{t: t, label: c.cols[j].Label, j: j}, nil
+}
+
+func (c *vectorCompiler) compile(n semantic.Expression) (vectorEvaluator, error) {
+	switch n := n.(type) {
+	case *semantic.BooleanLiteral:
+		return constant(values.NewBool(n.Value)), nil
+	case *semantic.IntegerLiteral:
+		return constant(values.NewInt(n.Value)), nil
+	case *semantic.UnsignedIntegerLiteral:
+		return constant(values.NewUInt(n.Value)), nil
+	case *semantic.FloatLiteral:
+		return constant(values.NewFloat(n.Value)), nil
+	case *semantic.StringLiteral:
+		return constant(values.NewString(n.Value)), nil
+	case *semantic.RegexpLiteral:
+		return constant(values.NewRegexp(n.Value)), nil
+	case *semantic.DateTimeLiteral:
+		return constant(values.NewTime(values.ConvertTime(n.Value))), nil
+	case *semantic.IdentifierExpression:
+		v, err := c.lookup(n.Name)
+		if err != nil {
+			return nil, err
+		}
+		return c.constant(v)
+	case *semantic.MemberExpression:
+		if id, ok := n.Object.(*semantic.IdentifierExpression); ok && id.Name == c.recordName {
+			for j, col := range c.cols {
+				if col.Label == n.Property {
+					return c.column(j)
+				}
+			}
+			return nil, errors.Newf(codes.Unimplemented, "column %q is not in the table", n.Property)
+		}
+		// Members of records in scope are constants.
+		obj, err := c.compile(n.Object)
+		if err != nil {
+			return nil, err
+		}
+		o, ok := obj.(*constVectorEvaluator)
+		if !ok || o.v.Type().Nature() != semantic.Object {
+			return nil, errors.New(codes.Unimplemented, "vectorized functions only support members of records")
+		}
+		p, ok := o.v.Object().Get(n.Property)
+		if !ok {
+			return nil, errors.Newf(codes.Unimplemented, "member %q is not in the record", n.Property)
+		}
+		return c.constant(p)
+	case *semantic.UnaryExpression:
+		node, err := c.compile(n.Argument)
+		if err != nil {
+			return nil, err
+		}
+		return compileUnary(n.Operator, node)
+	case *semantic.BinaryExpression:
+		l, err := c.compile(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		r, err := c.compile(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return compileBinary(n.Operator, l, r)
+	case *semantic.LogicalExpression:
+		l, err := c.compile(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		r, err := c.compile(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		if l.nature() != semantic.Bool || r.nature() != semantic.Bool {
+			return nil, errors.New(codes.Unimplemented, "logical operators require booleans")
+		}
+		return &logicalVectorEvaluator{operator: n.Operator, left: l, right: r}, nil
+	case *semantic.ConditionalExpression:
+		test, err := c.compile(n.Test)
+		if err != nil {
+			return nil, err
+		}
+		consequent, err := c.compile(n.Consequent)
+		if err != nil {
+			return nil, err
+		}
+		alternate, err := c.compile(n.Alternate)
+		if err != nil {
+			return nil, err
+		}
+		if test.nature() != semantic.Bool {
+			return nil, errors.New(codes.Unimplemented, "conditional test must be a boolean")
+		}
+		if consequent.nature() != alternate.nature() {
+			return nil, errors.New(codes.Unimplemented, "conditional branches must have the same type")
+		}
+		if _, ok := basicTypes[consequent.nature()]; !ok {
+			return nil, errors.Newf(codes.Unimplemented, "vectorized conditionals cannot return %v", consequent.nature())
+		}
+		return &conditionalVectorEvaluator{
+			test:       test,
+			consequent: consequent,
+			alternate:  alternate,
+		}, nil
+	default:
+		return nil, errors.Newf(codes.Unimplemented, "vectorized functions do not support %s", n.NodeType())
+	}
+}
+
+func (c *vectorCompiler) lookup(name string) (values.Value, error) {
+	if name != c.recordName && c.scope != nil {
+		if v, ok := c.scope.Lookup(name); ok {
+			return v, nil
+		}
+	}
+	return nil, errors.Newf(codes.Unimplemented, "identifier %q cannot be vectorized", name)
+}
+
+// constant returns an evaluator for a value in scope.
+// Records are allowed so their members can be accessed.
+func (c *vectorCompiler) constant(v values.Value) (vectorEvaluator, error) {
+	if v.IsNull() {
+		return nil, errors.New(codes.Unimplemented, "vectorized functions do not support null values")
+	}
+	switch n := v.Type().Nature(); n {
+	case semantic.Object, semantic.Regexp:
+	default:
+		if _, ok := basicTypes[n]; !ok {
+			return nil, errors.Newf(codes.Unimplemented, "vectorized functions do not support %v values", n)
+		}
+	}
+	return constant(v), nil
+}
+
+type vectorizedFn struct {
+	root vectorEvaluator
+}
+
+func (f *vectorizedFn) Type() semantic.MonoType {
+	return basicTypes[f.root.nature()]
+}
+
+func (f *vectorizedFn) Labels() []string {
+	return nil
+}
+
+func (f *vectorizedFn) Passthrough(label string) bool {
+	return false
+}
+
+func (f *vectorizedFn) Eval(ctx context.Context, cr flux.ColReader, mem memory.Allocator) ([]array.Interface, error) {
+	b := &vectorBatch{cr: cr, n: cr.Len(), mem: mem}
+	v, err := f.root.eval(ctx, b, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []array.Interface{b.materialize(v, f.root.nature())}, nil
+}
+
+type vectorizedRecordFn struct {
+	labels     []string
+	properties []vectorEvaluator
+}
+
+func (f *vectorizedRecordFn) Type() semantic.MonoType {
+	properties := make([]semantic.PropertyType, len(f.labels))
+	for i, label := range f.labels {
+		properties[i] = semantic.PropertyType{
+			Key:   []byte(label),
+			Value: basicTypes[f.properties[i].nature()],
+		}
+	}
+	return semantic.NewObjectType(properties)
+}
+
+func (f *vectorizedRecordFn) Labels() []string {
+	return f.labels
+}
+
+func (f *vectorizedRecordFn) Passthrough(label string) bool {
+	i := sort.SearchStrings(f.labels, label)
+	if i == len(f.labels) || f.labels[i] != label {
+		return false
+	}
+	col, ok := f.properties[i].(*columnVectorEvaluator)
+	return ok && col.label == label
+}
+
+func (f *vectorizedRecordFn) Eval(ctx context.Context, cr flux.ColReader, mem memory.Allocator) ([]array.Interface, error) {
+	b := &vectorBatch{cr: cr, n: cr.Len(), mem: mem}
+	arrs := make([]array.Interface, len(f.properties))
+	for i, p := range f.properties {
+		v, err := p.eval(ctx, b, nil)
+		if err != nil {
+			for _, arr := range arrs[:i] {
+				arr.Release()
+			}
+			return nil, err
+		}
+		arrs[i] = b.materialize(v, p.nature())
+	}
+	return arrs, nil
+}
+
+// vectorBatch holds the column reader that is being evaluated.
+type vectorBatch struct {
+	cr  flux.ColReader
+	n   int
+	mem memory.Allocator
+}
+
+// materialize converts the vector into an array
+// with a value for each row of the batch.
+func (b *vectorBatch) materialize(v vector, t semantic.Nature) array.Interface {
+	if v.arr != nil {
+		return v.arr
+	}
+	switch t {
+	case semantic.Bool:
+		c := v.bools()
+		builder := array.NewBooleanBuilder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if c.null {
+				builder.AppendNull()
+			} else {
+				builder.Append(c.c)
+			}
+		}
+		return builder.NewArray()
+	case semantic.Int, semantic.Time:
+		c := v.int64s()
+		builder := array.NewInt64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if c.null {
+				builder.AppendNull()
+			} else {
+				builder.Append(c.c)
+			}
+		}
+		return builder.NewArray()
+	case semantic.UInt:
+		c := v.uint64s()
+		builder := array.NewUint64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if c.null {
+				builder.AppendNull()
+			} else {
+				builder.Append(c.c)
+			}
+		}
+		return builder.NewArray()
+	case semantic.Float:
+		c := v.float64s()
+		builder := array.NewFloat64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if c.null {
+				builder.AppendNull()
+			} else {
+				builder.Append(c.c)
+			}
+		}
+		return builder.NewArray()
+	case semantic.String:
+		c := v.strings()
+		builder := array.NewBinaryBuilder(b.mem, arrow.BinaryTypes.String)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if c.null {
+				builder.AppendNull()
+			} else {
+				builder.AppendString(c.c)
+			}
+		}
+		return builder.NewArray()
+	default:
+		panic(errors.Newf(codes.Internal, "cannot materialize vector of type %v", t))
+	}
+}
+
+// vector is the result of a vectorized evaluation.
+// It is either an array with a value for each row
+// or a constant value that is the same for every row.
+type vector struct {
+	arr array.Interface
+	// c is the constant value when arr is nil.
+	c values.Value
+}
+
+func (v vector) release() {
+	if v.arr != nil {
+		v.arr.Release()
+	}
+}
+
+type boolVector struct {
+	arr  *array.Boolean
+	c    bool
+	null bool
+}
+
+func (v vector) bools() boolVector {
+	if v.arr != nil {
+		return boolVector{arr: v.arr.(*array.Boolean)}
+	}
+	if v.c.IsNull() {
+		return boolVector{null: true}
+	}
+	return boolVector{c: v.c.Bool()}
+}
+
+func (v boolVector) isNull(i int) bool {
+	if v.arr != nil {
+		return v.arr.IsNull(i)
+	}
+	return v.null
+}
+
+func (v boolVector) value(i int) bool {
+	if v.arr != nil {
+		return v.arr.Value(i)
+	}
+	return v.c
+}
+
+// isTrue reports whether the row is true in the same
+// way as a conditional. Null is not true.
+func (v boolVector) isTrue(i int) bool {
+	return !v.isNull(i) && v.value(i)
+}
+
+type int64Vector struct {
+	arr  *array.Int64
+	c    int64
+	null bool
+}
+
+// int64s returns the vector of an int or a time.
+func (v vector) int64s() int64Vector {
+	if v.arr != nil {
+		return int64Vector{arr: v.arr.(*array.Int64)}
+	}
+	if v.c.IsNull() {
+		return int64Vector{null: true}
+	}
+	if v.c.Type().Nature() == semantic.Time {
+		return int64Vector{c: int64(v.c.Time())}
+	}
+	return int64Vector{c: v.c.Int()}
+}
+
+func (v int64Vector) isNull(i int) bool {
+	if v.arr != nil {
+		return v.arr.IsNull(i)
+	}
+	return v.null
+}
+
+func (v int64Vector) value(i int) int64 {
+	if v.arr != nil {
+		return v.arr.Value(i)
+	}
+	return v.c
+}
+
+type uint64Vector struct {
+	arr  *array.Uint64
+	c    uint64
+	null bool
+}
+
+func (v vector) uint64s() uint64Vector {
+	if v.arr != nil {
+		return uint64Vector{arr: v.arr.(*array.Uint64)}
+	}
+	if v.c.IsNull() {
+		return uint64Vector{null: true}
+	}
+	return uint64Vector{c: v.c.UInt()}
+}
+
+func (v uint64Vector) isNull(i int) bool {
+	if v.arr != nil {
+		return v.arr.IsNull(i)
+	}
+	return v.null
+}
+
+func (v uint64Vector) value(i int) uint64 {
+	if v.arr != nil {
+		return v.arr.Value(i)
+	}
+	return v.c
+}
+
+type float64Vector struct {
+	arr  *array.Float64
+	c    float64
+	null bool
+}
+
+func (v vector) float64s() float64Vector {
+	if v.arr != nil {
+		return float64Vector{arr: v.arr.(*array.Float64)}
+	}
+	if v.c.IsNull() {
+		return float64Vector{null: true}
+	}
+	return float64Vector{c: v.c.Float()}
+}
+
+func (v float64Vector) isNull(i int) bool {
+	if v.arr != nil {
+		return v.arr.IsNull(i)
+	}
+	return v.null
+}
+
+func (v float64Vector) value(i int) float64 {
+	if v.arr != nil {
+		return v.arr.Value(i)
+	}
+	return v.c
+}
+
+type stringVector struct {
+	arr  *array.Binary
+	c    string
+	null bool
+}
+
+func (v vector) strings() stringVector {
+	if v.arr != nil {
+		return stringVector{arr: v.arr.(*array.Binary)}
+	}
+	if v.c.IsNull() {
+		return stringVector{null: true}
+	}
+	return stringVector{c: v.c.Str()}
+}
+
+func (v stringVector) isNull(i int) bool {
+	if v.arr != nil {
+		return v.arr.IsNull(i)
+	}
+	return v.null
+}
+
+func (v stringVector) value(i int) string {
+	if v.arr != nil {
+		return v.arr.ValueString(i)
+	}
+	return v.c
+}
+
+// vectorEvaluator evaluates an expression for every row of a batch.
+//
+// The selection marks the rows that the row at a time evaluator would
+// evaluate the expression for. The value of the other rows is ignored,
+// so they must not produce an error. A nil selection selects every row.
+type vectorEvaluator interface {
+	nature() semantic.Nature
+	// mayFail reports whether evaluating the expression may fail.
+	mayFail() bool
+	eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error)
+}
+
+type constVectorEvaluator struct {
+	v values.Value
+}
+
+func constant(v values.Value) *constVectorEvaluator {
+	return &constVectorEvaluator{v: v}
+}
+
+func (e *constVectorEvaluator) nature() semantic.Nature {
+	return e.v.Type().Nature()
+}
+
+func (e *constVectorEvaluator) mayFail() bool {
+	return false
+}
+
+func (e *constVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	return vector{c: e.v}, nil
+}
+
+type columnVectorEvaluator struct {
+	t     semantic.Nature
+	label string
+	j     int
+}
+
+func (e *columnVectorEvaluator) nature() semantic.Nature {
+	return e.t
+}
+
+func (e *columnVectorEvaluator) mayFail() bool {
+	return false
+}
+
+func (e *columnVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	var arr array.Interface
+	switch e.t {
+	case semantic.Bool:
+		arr = b.cr.Bools(e.j)
+	case semantic.Int:
+		arr = b.cr.Ints(e.j)
+	case semantic.UInt:
+		arr = b.cr.UInts(e.j)
+	case semantic.Float:
+		arr = b.cr.Floats(e.j)
+	case semantic.String:
+		arr = b.cr.Strings(e.j)
+	case semantic.Time:
+		arr = b.cr.Times(e.j)
+	}
+	arr.Retain()
+	return vector{arr: arr}, nil
+}
+
+type logicalVectorEvaluator struct {
+	operator    ast.LogicalOperatorKind
+	left, right vectorEvaluator
+}
+
+func (e *logicalVectorEvaluator) nature() semantic.Nature {
+	return semantic.Bool
+}
+
+func (e *logicalVectorEvaluator) mayFail() bool {
+	return e.left.mayFail() || e.right.mayFail()
+}
+
+func (e *logicalVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	lv, err := e.left.eval(ctx, b, sel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer lv.release()
+	l := lv.bools()
+
+	// The right side is only evaluated for the rows
+	// where the left side does not decide the result.
+	and := e.operator == ast.AndOperator
+	var rsel []bool
+	if e.right.mayFail() {
+		rsel = make([]bool, b.n)
+		for i := range rsel {
+			rsel[i] = (sel == nil || sel[i]) && l.isTrue(i) == and
+		}
+	}
+	rv, err := e.right.eval(ctx, b, rsel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer rv.release()
+	r := rv.bools()
+
+	builder := array.NewBooleanBuilder(b.mem)
+	builder.Reserve(b.n)
+	for i := 0; i < b.n; i++ {
+		if l.isTrue(i) != and {
+			// The left side is false for and or true for or.
+			builder.Append(!and)
+		} else if r.isNull(i) {
+			builder.AppendNull()
+		} else {
+			builder.Append(r.value(i))
+		}
+	}
+	return vector{arr: builder.NewArray()}, nil
+}
+
+type conditionalVectorEvaluator struct {
+	test       vectorEvaluator
+	consequent vectorEvaluator
+	alternate  vectorEvaluator
+}
+
+func (e *conditionalVectorEvaluator) nature() semantic.Nature {
+	return e.alternate.nature()
+}
+
+func (e *conditionalVectorEvaluator) mayFail() bool {
+	return e.test.mayFail() || e.consequent.mayFail() || e.alternate.mayFail()
+}
+
+func (e *conditionalVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	tv, err := e.test.eval(ctx, b, sel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer tv.release()
+	t := tv.bools()
+
+	var csel, asel []bool
+	if e.consequent.mayFail() || e.alternate.mayFail() {
+		csel, asel = make([]bool, b.n), make([]bool, b.n)
+		for i := 0; i < b.n; i++ {
+			selected := sel == nil || sel[i]
+			csel[i] = selected && t.isTrue(i)
+			asel[i] = selected && !t.isTrue(i)
+		}
+	}
+	cv, err := e.consequent.eval(ctx, b, csel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer cv.release()
+	av, err := e.alternate.eval(ctx, b, asel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer av.release()
+
+	switch e.nature() {
+	case semantic.Bool:
+		c, a := cv.bools(), av.bools()
+		builder := array.NewBooleanBuilder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			v := a
+			if t.isTrue(i) {
+				v = c
+			}
+			if v.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(v.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	case semantic.Int, semantic.Time:
+		c, a := cv.int64s(), av.int64s()
+		builder := array.NewInt64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			v := a
+			if t.isTrue(i) {
+				v = c
+			}
+			if v.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(v.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	case semantic.UInt:
+		c, a := cv.uint64s(), av.uint64s()
+		builder := array.NewUint64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			v := a
+			if t.isTrue(i) {
+				v = c
+			}
+			if v.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(v.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	case semantic.Float:
+		c, a := cv.float64s(), av.float64s()
+		builder := array.NewFloat64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			v := a
+			if t.isTrue(i) {
+				v = c
+			}
+			if v.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(v.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	case semantic.String:
+		c, a := cv.strings(), av.strings()
+		builder := array.NewBinaryBuilder(b.mem, arrow.BinaryTypes.String)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			v := a
+			if t.isTrue(i) {
+				v = c
+			}
+			if v.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.AppendString(v.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	default:
+		return vector{}, errors.Newf(codes.Internal, "unsupported conditional type %v", e.nature())
+	}
+}
+
+func compileUnary(op ast.OperatorKind, node vectorEvaluator) (vectorEvaluator, error) {
+	t := node.nature()
+	switch {
+	case op == ast.ExistsOperator:
+		t = semantic.Bool
+	case op == ast.AdditionOperator:
+		return node, nil
+	case op == ast.SubtractionOperator && (t == semantic.Int || t == semantic.Float):
+	case op == ast.NotOperator && t == semantic.Bool:
+	default:
+		return nil, errors.Newf(codes.Unimplemented, "vectorized functions do not support %v on %v", op, t)
+	}
+	return &unaryVectorEvaluator{t: t, op: op, node: node}, nil
+}
+
+type unaryVectorEvaluator struct {
+	t    semantic.Nature
+	op   ast.OperatorKind
+	node vectorEvaluator
+}
+
+func (e *unaryVectorEvaluator) nature() semantic.Nature {
+	return e.t
+}
+
+func (e *unaryVectorEvaluator) mayFail() bool {
+	return e.node.mayFail()
+}
+
+func (e *unaryVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	v, err := e.node.eval(ctx, b, sel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer v.release()
+
+	if e.op == ast.ExistsOperator {
+		builder := array.NewBooleanBuilder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if v.arr != nil {
+				builder.Append(v.arr.IsValid(i))
+			} else {
+				builder.Append(!v.c.IsNull())
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	}
+
+	switch e.t {
+	case semantic.Bool:
+		x := v.bools()
+		builder := array.NewBooleanBuilder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if x.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(!x.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	case semantic.Int:
+		x := v.int64s()
+		builder := array.NewInt64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if x.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(-x.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	default:
+		x := v.float64s()
+		builder := array.NewFloat64Builder(b.mem)
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if x.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append(-x.value(i))
+			}
+		}
+		return vector{arr: builder.NewArray()}, nil
+	}
+}
+
+func compileBinary(op ast.OperatorKind, l, r vectorEvaluator) (vectorEvaluator, error) {
+	lt, rt := l.nature(), r.nature()
+	e := &binaryVectorEvaluator{op: op, operand: lt, left: l, right: r}
+	switch op {
+	case ast.AdditionOperator, ast.SubtractionOperator, ast.MultiplicationOperator,
+		ast.DivisionOperator, ast.ModuloOperator:
+		if lt != rt || !(lt == semantic.Int || lt == semantic.UInt || lt == semantic.Float ||
+			lt == semantic.String && op == ast.AdditionOperator) {
+			break
+		}
+		e.t = lt
+		return e, nil
+	case ast.LessThanOperator, ast.LessThanEqualOperator,
+		ast.GreaterThanOperator, ast.GreaterThanEqualOperator:
+		if lt != rt || lt == semantic.Bool {
+			break
+		}
+		if _, ok := basicTypes[lt]; !ok {
+			break
+		}
+		e.t = semantic.Bool
+		return e, nil
+	case ast.EqualOperator, ast.NotEqualOperator:
+		if lt != rt {
+			break
+		}
+		if _, ok := basicTypes[lt]; !ok {
+			break
+		}
+		e.t = semantic.Bool
+		return e, nil
+	case ast.RegexpMatchOperator, ast.NotRegexpMatchOperator:
+		re, ok := r.(*constVectorEvaluator)
+		if lt != semantic.String || !ok || rt != semantic.Regexp {
+			break
+		}
+		return &regexpVectorEvaluator{
+			left:   l,
+			re:     re.v.Regexp(),
+			negate: op == ast.NotRegexpMatchOperator,
+		}, nil
+	}
+	return nil, errors.Newf(codes.Unimplemented, "vectorized functions do not support %v %v %v", lt, op, rt)
+}
+
+type binaryVectorEvaluator struct {
+	t           semantic.Nature
+	op          ast.OperatorKind
+	operand     semantic.Nature
+	left, right vectorEvaluator
+}
+
+func (e *binaryVectorEvaluator) nature() semantic.Nature {
+	return e.t
+}
+
+func (e *binaryVectorEvaluator) mayFail() bool {
+	if e.left.mayFail() || e.right.mayFail() {
+		return true
+	}
+	// Integer division and modulo fail when dividing by zero.
+	return (e.op == ast.DivisionOperator || e.op == ast.ModuloOperator) &&
+		(e.operand == semantic.Int || e.operand == semantic.UInt)
+}
+
+func (e *binaryVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	lv, err := e.left.eval(ctx, b, sel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer lv.release()
+	rv, err := e.right.eval(ctx, b, sel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer rv.release()
+
+	var arr array.Interface
+	if e.t == semantic.Bool {
+		arr = e.compare(b, lv, rv)
+	} else {
+		arr, err = e.arithmetic(b, lv, rv, sel)
+		if err != nil {
+			return vector{}, err
+		}
+	}
+	return vector{arr: arr}, nil
+}
+
+func (e *binaryVectorEvaluator) compare(b *vectorBatch, lv, rv vector) array.Interface {
+	builder := array.NewBooleanBuilder(b.mem)
+	builder.Reserve(b.n)
+	switch e.operand {
+	case semantic.Bool:
+		l, r := lv.bools(), rv.bools()
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+			} else {
+				builder.Append((l.value(i) == r.value(i)) == (e.op == ast.EqualOperator))
+			}
+		}
+	case semantic.Int, semantic.Time:
+		l, r := lv.int64s(), rv.int64s()
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+			} else {
+				x, y := l.value(i), r.value(i)
+				builder.Append(compareResult(e.op, x < y, x == y, x > y))
+			}
+		}
+	case semantic.UInt:
+		l, r := lv.uint64s(), rv.uint64s()
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+			} else {
+				x, y := l.value(i), r.value(i)
+				builder.Append(compareResult(e.op, x < y, x == y, x > y))
+			}
+		}
+	case semantic.Float:
+		l, r := lv.float64s(), rv.float64s()
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+			} else {
+				x, y := l.value(i), r.value(i)
+				builder.Append(compareResult(e.op, x < y, x == y, x > y))
+			}
+		}
+	case semantic.String:
+		l, r := lv.strings(), rv.strings()
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+			} else {
+				x, y := l.value(i), r.value(i)
+				builder.Append(compareResult(e.op, x < y, x == y, x > y))
+			}
+		}
+	}
+	return builder.NewArray()
+}
+
+// compareResult returns the result of the comparison operator.
+// The results of each comparison are passed in so that comparisons
+// with NaN are false in the same way as the row at a time evaluator.
+func compareResult(op ast.OperatorKind, lt, eq, gt bool) bool {
+	switch op {
+	case ast.LessThanOperator:
+		return lt
+	case ast.LessThanEqualOperator:
+		return lt || eq
+	case ast.GreaterThanOperator:
+		return gt
+	case ast.GreaterThanEqualOperator:
+		return gt || eq
+	case ast.EqualOperator:
+		return eq
+	default:
+		return !eq
+	}
+}
+
+func (e *binaryVectorEvaluator) arithmetic(b *vectorBatch, lv, rv vector, sel []bool) (array.Interface, error) {
+	switch e.operand {
+	case semantic.Int:
+		l, r := lv.int64s(), rv.int64s()
+		builder := array.NewInt64Builder(b.mem)
+		defer builder.Release()
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+				continue
+			}
+			x, y := l.value(i), r.value(i)
+			switch e.op {
+			case ast.AdditionOperator:
+				builder.Append(x + y)
+			case ast.SubtractionOperator:
+				builder.Append(x - y)
+			case ast.MultiplicationOperator:
+				builder.Append(x * y)
+			case ast.DivisionOperator, ast.ModuloOperator:
+				if y == 0 {
+					if sel == nil || sel[i] {
+						return nil, divideByZero(e.op)
+					}
+					builder.AppendNull()
+				} else if e.op == ast.DivisionOperator {
+					builder.Append(x / y)
+				} else {
+					builder.Append(x % y)
+				}
+			}
+		}
+		return builder.NewArray(), nil
+	case semantic.UInt:
+		l, r := lv.uint64s(), rv.uint64s()
+		builder := array.NewUint64Builder(b.mem)
+		defer builder.Release()
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+				continue
+			}
+			x, y := l.value(i), r.value(i)
+			switch e.op {
+			case ast.AdditionOperator:
+				builder.Append(x + y)
+			case ast.SubtractionOperator:
+				builder.Append(x - y)
+			case ast.MultiplicationOperator:
+				builder.Append(x * y)
+			case ast.DivisionOperator, ast.ModuloOperator:
+				if y == 0 {
+					if sel == nil || sel[i] {
+						return nil, divideByZero(e.op)
+					}
+					builder.AppendNull()
+				} else if e.op == ast.DivisionOperator {
+					builder.Append(x / y)
+				} else {
+					builder.Append(x % y)
+				}
+			}
+		}
+		return builder.NewArray(), nil
+	case semantic.Float:
+		l, r := lv.float64s(), rv.float64s()
+		builder := array.NewFloat64Builder(b.mem)
+		defer builder.Release()
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+				continue
+			}
+			x, y := l.value(i), r.value(i)
+			switch e.op {
+			case ast.AdditionOperator:
+				builder.Append(x + y)
+			case ast.SubtractionOperator:
+				builder.Append(x - y)
+			case ast.MultiplicationOperator:
+				builder.Append(x * y)
+			case ast.DivisionOperator:
+				builder.Append(x / y)
+			case ast.ModuloOperator:
+				builder.Append(math.Mod(x, y))
+			}
+		}
+		return builder.NewArray(), nil
+	case semantic.String:
+		l, r := lv.strings(), rv.strings()
+		builder := array.NewBinaryBuilder(b.mem, arrow.BinaryTypes.String)
+		defer builder.Release()
+		builder.Reserve(b.n)
+		for i := 0; i < b.n; i++ {
+			if l.isNull(i) || r.isNull(i) {
+				builder.AppendNull()
+				continue
+			}
+			builder.AppendString(l.value(i) + r.value(i))
+		}
+		return builder.NewArray(), nil
+	default:
+		return nil, errors.Newf(codes.Internal, "unsupported arithmetic type %v", e.operand)
+	}
+}
+
+// divideByZero returns the same error as the row at a time evaluator.
+func divideByZero(op ast.OperatorKind) error {
+	if op == ast.ModuloOperator {
+		return errors.New(codes.FailedPrecondition, "cannot mod zero")
+	}
+	return errors.New(codes.FailedPrecondition, "cannot divide by zero")
+}
+
+type regexpVectorEvaluator struct {
+	left   vectorEvaluator
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (e *regexpVectorEvaluator) nature() semantic.Nature {
+	return semantic.Bool
+}
+
+func (e *regexpVectorEvaluator) mayFail() bool {
+	return e.left.mayFail()
+}
+
+func (e *regexpVectorEvaluator) eval(ctx context.Context, b *vectorBatch, sel []bool) (vector, error) {
+	lv, err := e.left.eval(ctx, b, sel)
+	if err != nil {
+		return vector{}, err
+	}
+	defer lv.release()
+	l := lv.strings()
+
+	builder := array.NewBooleanBuilder(b.mem)
+	builder.Reserve(b.n)
+	for i := 0; i < b.n; i++ {
+		if l.isNull(i) {
+			builder.AppendNull()
+		} else {
+			builder.Append(e.re.MatchString(l.value(i)) != e.negate)
+		}
+	}
+	return vector{arr: builder.NewArray()}, nil
+}
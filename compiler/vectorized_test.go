@@ -0,0 +1,259 @@
+package compiler_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/compiler"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+func vectorizedTable() *executetest.Table {
+	return &executetest.Table{
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "i", Type: flux.TInt},
+			{Label: "u", Type: flux.TUInt},
+			{Label: "s", Type: flux.TString},
+			{Label: "b", Type: flux.TBool},
+		},
+		Data: [][]interface{}{
+			{execute.Time(1), 1.5, int64(3), uint64(7), "a", true},
+			{execute.Time(2), -2.0, int64(0), uint64(0), "b", false},
+			{execute.Time(3), nil, nil, nil, nil, nil},
+			{execute.Time(4), math.NaN(), int64(-4), uint64(2), "ab", true},
+			{execute.Time(5), 0.0, int64(10), uint64(12), "", false},
+		},
+	}
+}
+
+func TestCompileVectorized(t *testing.T) {
+	for _, fn := range []string{
+		`(r) => r._value > 1.0`,
+		`(r) => r._value <= 0.0`,
+		`(r) => r._value == r._value`,
+		`(r) => r._value != 1.5`,
+		`(r) => r.i + 2 * r.i - 1`,
+		`(r) => r.i % 3`,
+		`(r) => r.u * r.u + r.u - r.u`,
+		`(r) => r.u >= r.u`,
+		`(r) => r._value / 2.0 - r._value % 1.0`,
+		`(r) => -r._value`,
+		`(r) => -r.i`,
+		`(r) => +r.i`,
+		`(r) => r.s + "x"`,
+		`(r) => r.s < "b"`,
+		`(r) => r.s =~ /a/`,
+		`(r) => r.s !~ /^a/`,
+		`(r) => r.b == true`,
+		`(r) => r.b != r.b`,
+		`(r) => not r.b`,
+		`(r) => exists r.s`,
+		`(r) => not exists r._value`,
+		`(r) => r.b and r._value > 0.0`,
+		`(r) => r.b or r.i > 2`,
+		`(r) => r.i > 2 or r.b`,
+		`(r) => r.i > 2 and r.b`,
+		`(r) => r._time >= 1970-01-01T00:00:00.000000003Z`,
+		`(r) => r._time`,
+		`(r) => if r.i > 1 then r._value else -r._value`,
+		`(r) => if r.b then r.s else "none"`,
+		`(r) => if r.b then r.b else false`,
+		`(r) => if r.b then r.u else r.u * r.u`,
+		`(r) => if r.b then r._time else 1970-01-01T00:00:00Z`,
+		`(r) => r.i != 0 and 10 / r.i > 1`,
+		`(r) => r.i == 0 or 10 / r.i > 1`,
+		`(r) => if r.i == 0 then 0 else 10 % r.i`,
+		`(r) => if r.b then r.u / r.u else r.u`,
+		`(r) => true`,
+		`(r) => 1 + 2`,
+		`(r) => ({r with x: r.i * 2, _value: r._value / 2.0})`,
+		`(r) => ({a: r.s, b: 1, _time: r._time})`,
+	} {
+		t.Run(fn, func(t *testing.T) {
+			testVectorized(t, executetest.FunctionExpression(t, fn), nil)
+		})
+	}
+}
+
+func TestCompileVectorized_Scope(t *testing.T) {
+	pkg, err := runtime.AnalyzeSource(`
+threshold = 1.0
+params = {n: 2, name: "a"}
+f = (r) => ({r with ok: r._value < threshold and r.i * params.n > 0, name: params.name})
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := pkg.Files[0].Body[2].(*semantic.NativeVariableAssignment).Init.(*semantic.FunctionExpression)
+
+	scope := compiler.NewScope()
+	scope.Set("threshold", values.NewFloat(1.0))
+	scope.Set("params", values.NewObjectWithValues(map[string]values.Value{
+		"n":    values.NewInt(2),
+		"name": values.NewString("a"),
+	}))
+	testVectorized(t, fn, scope)
+}
+
+func TestCompileVectorized_Errors(t *testing.T) {
+	for _, fn := range []string{
+		`(r) => 10 / r.i`,
+		`(r) => r.i % r.i`,
+		`(r) => r.u % r.u`,
+	} {
+		t.Run(fn, func(t *testing.T) {
+			f := executetest.FunctionExpression(t, fn)
+			tbl := vectorizedTable()
+			vfn, err := compiler.CompileVectorized(nil, f, tbl.Cols())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tbl.Do(func(cr flux.ColReader) error {
+				_, err := vfn.Eval(context.Background(), cr, &memory.Allocator{})
+				return err
+			}); err == nil {
+				t.Fatal("expected error")
+			} else if code := errors.Code(err); code != codes.FailedPrecondition {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", codes.FailedPrecondition, code)
+			}
+		})
+	}
+}
+
+func TestCompileVectorized_Unsupported(t *testing.T) {
+	for _, fn := range []string{
+		`(r) => r._time > now()`,
+		`(r) => float(v: r.i) > 1.0`,
+		`(r) => r.i > 1.0`,
+		`(r) => r.missing > 1`,
+		`(r) => ({r with x: [r.i]})`,
+		`(r) => "${r.s}!"`,
+		`(r) => [r.i]`,
+		`(r) => {
+			x = r.i
+			return x + 1
+		}`,
+	} {
+		t.Run(fn, func(t *testing.T) {
+			f := executetest.FunctionExpression(t, fn)
+			_, err := compiler.CompileVectorized(nil, f, vectorizedTable().Cols())
+			if err == nil {
+				t.Fatal("expected error")
+			} else if code := errors.Code(err); code != codes.Unimplemented {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", codes.Unimplemented, code)
+			}
+		})
+	}
+}
+
+// testVectorized checks that the vectorized function
+// produces the same values as the row at a time function.
+func testVectorized(t *testing.T, fn *semantic.FunctionExpression, scope compiler.Scope) {
+	t.Helper()
+
+	tbl := vectorizedTable()
+	cols := tbl.Cols()
+	properties := make([]semantic.PropertyType, len(cols))
+	for j, c := range cols {
+		properties[j] = semantic.PropertyType{Key: []byte(c.Label), Value: flux.SemanticType(c.Type)}
+	}
+	recordType := semantic.NewObjectType(properties)
+	rowFn, err := compiler.Compile(scope, fn, semantic.NewObjectType([]semantic.PropertyType{
+		{Key: []byte("r"), Value: recordType},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vfn, err := compiler.CompileVectorized(scope, fn, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mem := &memory.Allocator{}
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		arrs, err := vfn.Eval(context.Background(), cr, mem)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			for _, arr := range arrs {
+				arr.Release()
+			}
+		}()
+
+		for i := 0; i < cr.Len(); i++ {
+			record := values.NewObject(recordType)
+			for j, c := range cols {
+				record.Set(c.Label, execute.ValueForRow(cr, i, j))
+			}
+			want, err := rowFn.Eval(context.Background(), values.NewObjectWithValues(map[string]values.Value{
+				"r": record,
+			}))
+			if err != nil {
+				return err
+			}
+
+			if labels := vfn.Labels(); labels != nil {
+				for k, label := range labels {
+					v, _ := want.Object().Get(label)
+					checkVectorValue(t, i, label, v, arrs[k])
+				}
+				continue
+			}
+			checkVectorValue(t, i, "", want, arrs[0])
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := mem.Allocated(); got != 0 {
+		t.Errorf("unexpected allocated memory: %d", got)
+	}
+}
+
+func checkVectorValue(t *testing.T, i int, label string, want values.Value, arr array.Interface) {
+	t.Helper()
+	if want.IsNull() || arr.IsNull(i) {
+		if want.IsNull() != arr.IsNull(i) {
+			t.Errorf("row %d %s: unexpected null -want/+got:\n\t- %v\n\t+ %v", i, label, want.IsNull(), arr.IsNull(i))
+		}
+		return
+	}
+
+	var got values.Value
+	switch arr := arr.(type) {
+	case *array.Boolean:
+		got = values.NewBool(arr.Value(i))
+	case *array.Int64:
+		if want.Type().Nature() == semantic.Time {
+			got = values.NewTime(values.Time(arr.Value(i)))
+		} else {
+			got = values.NewInt(arr.Value(i))
+		}
+	case *array.Uint64:
+		got = values.NewUInt(arr.Value(i))
+	case *array.Float64:
+		got = values.NewFloat(arr.Value(i))
+		if math.IsNaN(want.Float()) && math.IsNaN(arr.Value(i)) {
+			return
+		}
+	case *array.Binary:
+		got = values.NewString(arr.ValueString(i))
+	}
+	if !want.Equal(got) {
+		t.Errorf("row %d %s: unexpected value -want/+got:\n\t- %v\n\t+ %v", i, label, want, got)
+	}
+}
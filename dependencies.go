@@ -6,6 +6,7 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/dependencies/filesystem"
 	"github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/dependencies/scratch"
 	"github.com/influxdata/flux/dependencies/secret"
 	"github.com/influxdata/flux/dependencies/url"
 	"github.com/influxdata/flux/internal/errors"
@@ -22,7 +23,10 @@ type Dependency interface {
 
 type key int
 
-const dependenciesKey key = iota
+const (
+	dependenciesKey key = iota
+	queryLimitsKey
+)
 
 type Dependencies interface {
 	Dependency
@@ -43,6 +47,16 @@ type WrappedDeps struct {
 	FilesystemService filesystem.Service
 	SecretService     secret.Service
 	URLValidator      url.Validator
+
+	// ScratchService is optional. When it is set, blocking transformations
+	// may move buffered tables into scratch space instead of exceeding
+	// the memory limit of a query.
+	ScratchService scratch.Service
+
+	// QueryLimits are the limits of the queries that use these dependencies.
+	// A query may be compiled with limits of its own, in which case the lower
+	// of each limit applies.
+	QueryLimits QueryLimits
 }
 
 func (d Deps) HTTPClient() (http.Client, error) {
@@ -78,6 +92,12 @@ func (d Deps) Inject(ctx context.Context) context.Context {
 	if d.Deps.FilesystemService != nil {
 		ctx = filesystem.Inject(ctx, d.Deps.FilesystemService)
 	}
+	if d.Deps.ScratchService != nil {
+		ctx = scratch.Inject(ctx, d.Deps.ScratchService)
+	}
+	if !d.Deps.QueryLimits.IsZero() {
+		ctx = WithQueryLimits(ctx, d.Deps.QueryLimits)
+	}
 	return ctx
 }
 
@@ -2,8 +2,12 @@ package filesystem
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
 	"os"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
 )
 
 // ReadFile will open the file from the service and read
@@ -43,3 +47,17 @@ func Stat(ctx context.Context, filename string) (os.FileInfo, error) {
 	defer func() { _ = f.Close() }()
 	return f.Stat()
 }
+
+// CreateFile will create or truncate the file using the service.
+// It returns an error if the service does not support writing files.
+func CreateFile(ctx context.Context, filename string) (io.WriteCloser, error) {
+	fs, err := Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wfs, ok := fs.(WritableService)
+	if !ok {
+		return nil, errors.New(codes.Unimplemented, "filesystem service does not support writing files")
+	}
+	return wfs.Create(filename)
+}
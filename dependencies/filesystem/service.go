@@ -20,6 +20,12 @@ type Service interface {
 	Open(fpath string) (File, error)
 }
 
+// WritableService is a Service that can also create files.
+type WritableService interface {
+	Service
+	Create(fpath string) (io.WriteCloser, error)
+}
+
 type key int
 
 const serviceKey key = iota
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"io"
 	"os"
 )
 
@@ -8,6 +9,11 @@ import (
 // to the filesystem.
 var SystemFS Service = systemFS{}
 
+// WritableSystemFS implements the filesystem.WritableService by proxying
+// all requests to the filesystem. Unlike SystemFS, it allows queries to
+// create and overwrite files, so embedders must opt into it.
+var WritableSystemFS WritableService = writableSystemFS{}
+
 type systemFS struct{}
 
 func (systemFS) Open(fpath string) (File, error) {
@@ -17,3 +23,15 @@ func (systemFS) Open(fpath string) (File, error) {
 	}
 	return f, nil
 }
+
+type writableSystemFS struct {
+	systemFS
+}
+
+func (writableSystemFS) Create(fpath string) (io.WriteCloser, error) {
+	f, err := os.Create(fpath)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
@@ -95,3 +95,51 @@ func TestSystemFS_ReadFile(t *testing.T) {
 		t.Fatalf("unexpected file contents -want/+got:\n\t- %q\n\t+ %q", want, got)
 	}
 }
+
+func TestSystemFS_CreateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-systemfs-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// The default system filesystem is read only.
+	ctx := filesystem.Inject(context.Background(), filesystem.SystemFS)
+	fpath := filepath.Join(dir, "out.txt")
+	if _, err := filesystem.CreateFile(ctx, fpath); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := os.Stat(fpath); !os.IsNotExist(err) {
+		t.Fatalf("expected file to not exist, got %v", err)
+	}
+}
+
+func TestWritableSystemFS_CreateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-systemfs-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := filesystem.Inject(context.Background(), filesystem.WritableSystemFS)
+	fpath := filepath.Join(dir, "out.txt")
+	f, err := filesystem.CreateFile(ctx, fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(f, "Hello, World!"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := filesystem.ReadFile(ctx, fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(data), "Hello, World!"; got != want {
+		t.Fatalf("unexpected file contents -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"mime"
 	stdhttp "net/http"
 	"net/url"
 	"sort"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/apache/arrow/go/arrow/memory"
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow/ipc"
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/csv"
@@ -152,7 +154,7 @@ func (h *HttpClient) Query(ctx context.Context, f func(table flux.Table) error,
 		}
 		return h.parseError(data)
 	}
-	return h.processResult(resp.Body, f, mem)
+	return h.processResult(resp.Header.Get("Content-Type"), resp.Body, f, mem)
 }
 
 // newFile constructs a new ast.File with the default values filled in.
@@ -268,12 +270,18 @@ func (h *HttpClient) newRequestBody(file *ast.File, now time.Time) ([]byte, erro
 	return json.Marshal(req)
 }
 
-// processResult reads a single csv encoded result from the io.Reader.
+// processResult reads a single result from the io.Reader.
+// The result is decoded as Arrow IPC streams when the content type
+// says so and as csv otherwise.
 // Produced tables are passed to the function. If there is more than one
 // result, this method will discard any additional results.
-func (h *HttpClient) processResult(r io.ReadCloser, f func(flux.Table) error, mem memory.Allocator) error {
-	config := csv.ResultDecoderConfig{Allocator: mem}
-	dec := csv.NewMultiResultDecoder(config)
+func (h *HttpClient) processResult(contentType string, r io.ReadCloser, f func(flux.Table) error, mem memory.Allocator) error {
+	var dec flux.MultiResultDecoder
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == ipc.ContentType {
+		dec = ipc.NewMultiResultDecoder(ipc.ResultDecoderConfig{Allocator: mem})
+	} else {
+		dec = csv.NewMultiResultDecoder(csv.ResultDecoderConfig{Allocator: mem})
+	}
 	results, err := dec.Decode(r)
 	if err != nil {
 		return err
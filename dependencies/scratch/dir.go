@@ -0,0 +1,32 @@
+package scratch
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// Dir implements the scratch Service by creating temporary
+// files in a directory. If the directory is empty, the default
+// directory for temporary files is used.
+type Dir string
+
+func (d Dir) Create() (File, error) {
+	f, err := ioutil.TempFile(string(d), "flux-scratch-")
+	if err != nil {
+		return nil, err
+	}
+	return &dirFile{File: f}, nil
+}
+
+// dirFile removes the temporary file when it is closed.
+type dirFile struct {
+	*os.File
+}
+
+func (f *dirFile) Close() error {
+	err := f.File.Close()
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
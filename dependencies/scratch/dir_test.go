@@ -0,0 +1,70 @@
+package scratch_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/scratch"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestDir_Create(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-scratch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	f, err := scratch.Dir(dir).Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.WriteString(f, "Hello, World!"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "Hello, World!"; got != want {
+		t.Fatalf("unexpected file contents -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the file should have removed it.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected scratch directory to be empty, found %d files", len(files))
+	}
+}
+
+func TestGet(t *testing.T) {
+	if _, err := scratch.Get(context.Background()); err == nil {
+		t.Fatal("expected error")
+	} else if got, want := errors.Code(err), codes.Unimplemented; got != want {
+		t.Fatalf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	ctx := scratch.Dependency{Scratch: scratch.Dir("")}.Inject(context.Background())
+	s, err := scratch.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s, scratch.Service(scratch.Dir("")); got != want {
+		t.Fatalf("unexpected service -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
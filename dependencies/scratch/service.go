@@ -0,0 +1,53 @@
+package scratch
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// File is a temporary file used to hold data that does not fit in memory.
+// Closing the file releases it and any data written to it.
+type File interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+// Service is the service for creating scratch space.
+type Service interface {
+	// Create returns a new, empty scratch file.
+	Create() (File, error)
+}
+
+type key int
+
+const serviceKey key = iota
+
+// Dependency will inject the scratch Service into the dependency chain.
+type Dependency struct {
+	Scratch Service
+}
+
+// Inject will inject the scratch Service into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	if d.Scratch != nil {
+		ctx = Inject(ctx, d.Scratch)
+	}
+	return ctx
+}
+
+// Inject will inject this scratch Service into the context.
+func Inject(ctx context.Context, s Service) context.Context {
+	return context.WithValue(ctx, serviceKey, s)
+}
+
+// Get will retrieve a scratch Service from the context.Context.
+func Get(ctx context.Context) (Service, error) {
+	s := ctx.Value(serviceKey)
+	if s == nil {
+		return nil, errors.New(codes.Unimplemented, "scratch service is uninitialized")
+	}
+	return s.(Service), nil
+}
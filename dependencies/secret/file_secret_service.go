@@ -0,0 +1,75 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// FileSecretService loads secrets from files.
+//
+// Each file in Dir holds the secret of the key that is its name,
+// such as the secrets that are mounted into a container. MapFile
+// is a JSON or YAML object that maps keys to their secrets. The
+// files are read on every lookup so secrets that are rotated on
+// disk are picked up. A key is looked up in Dir before MapFile,
+// and either of them may be empty.
+type FileSecretService struct {
+	Dir     string
+	MapFile string
+}
+
+func (s FileSecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	if s.Dir != "" {
+		if v, ok, err := s.loadFromDir(k); err != nil || ok {
+			return v, err
+		}
+	}
+	if s.MapFile != "" {
+		secrets, err := readSecretsFile(s.MapFile)
+		if err != nil {
+			return "", err
+		}
+		if v, ok := secrets[k]; ok {
+			return v, nil
+		}
+	}
+	return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+}
+
+func (s FileSecretService) loadFromDir(k string) (string, bool, error) {
+	// A key may only name a file in the directory.
+	if k == "" || k == "." || k == ".." || strings.ContainsAny(k, `/\`) {
+		return "", false, nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(s.Dir, k))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, errors.Wrapf(err, codes.Internal, "failed to read secret key %q", k)
+	}
+	// Editors and tools commonly end files with a newline
+	// that is not part of the secret.
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// readSecretsFile reads a JSON or YAML object of keys to their secrets.
+// JSON is read as YAML, which it is a subset of.
+func readSecretsFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Internal, "failed to read secrets file %s", path)
+	}
+	var secrets map[string]string
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, errors.Wrapf(err, codes.Invalid, "secrets file %s must be an object of keys to secrets", path)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,108 @@
+package secret_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestFileSecretService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	secretsDir := filepath.Join(dir, "keys")
+	if err := os.Mkdir(secretsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile("keys/token", "mytoken\n")
+	writeFile("keys/password", "frompassword")
+	jsonFile := writeFile("secrets.json", `{"password": "jsonpassword", "user": "admin"}`)
+	yamlFile := writeFile("secrets.yaml", "user: yamladmin\nport: 5432\n")
+	invalidFile := writeFile("invalid.yaml", "- not\n- a map\n")
+
+	for _, tc := range []struct {
+		name    string
+		svc     secret.FileSecretService
+		key     string
+		want    string
+		wantErr codes.Code
+	}{
+		{
+			name: "directory",
+			svc:  secret.FileSecretService{Dir: secretsDir},
+			key:  "token",
+			want: "mytoken",
+		},
+		{
+			name: "directory before map file",
+			svc:  secret.FileSecretService{Dir: secretsDir, MapFile: jsonFile},
+			key:  "password",
+			want: "frompassword",
+		},
+		{
+			name: "map file after directory",
+			svc:  secret.FileSecretService{Dir: secretsDir, MapFile: jsonFile},
+			key:  "user",
+			want: "admin",
+		},
+		{
+			name: "yaml map file",
+			svc:  secret.FileSecretService{MapFile: yamlFile},
+			key:  "port",
+			want: "5432",
+		},
+		{
+			name:    "not found",
+			svc:     secret.FileSecretService{Dir: secretsDir, MapFile: yamlFile},
+			key:     "missing",
+			wantErr: codes.NotFound,
+		},
+		{
+			name:    "key outside of the directory",
+			svc:     secret.FileSecretService{Dir: secretsDir},
+			key:     "../secrets.json",
+			wantErr: codes.NotFound,
+		},
+		{
+			name:    "invalid map file",
+			svc:     secret.FileSecretService{MapFile: invalidFile},
+			key:     "user",
+			wantErr: codes.Invalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.svc.LoadSecret(context.Background(), tc.key)
+			if tc.wantErr != 0 {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				if code := errors.Code(err); code != tc.wantErr {
+					t.Fatalf("unexpected error code: want %v got %v (%v)", tc.wantErr, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("unexpected secret: want %q got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+const (
+	// DefaultVaultMount is the mount of the key/value secrets engine
+	// that a Vault server enables by default.
+	DefaultVaultMount = "secret"
+	// DefaultVaultField is the field of a secret that holds its value.
+	DefaultVaultField = "value"
+)
+
+// VaultConfig configures a VaultSecretService.
+type VaultConfig struct {
+	// Address is the URL of the server, such as http://127.0.0.1:8200.
+	Address string
+	// Token authenticates the requests to the server.
+	Token string
+	// Mount is the path the key/value secrets engine is mounted at.
+	// It defaults to DefaultVaultMount.
+	Mount string
+	// Prefix is prepended to the path of every key.
+	Prefix string
+	// Field is the field of a secret that holds its value.
+	// It defaults to DefaultVaultField.
+	Field string
+	// KVVersion is the version of the key/value secrets engine, 1 or 2.
+	// It defaults to 2.
+	KVVersion int
+	// CacheTTL is how long a secret is kept after it is read.
+	// Secrets are read from the server on every lookup when it is zero.
+	CacheTTL time.Duration
+	// Client sends the requests. It defaults to a client with a ten second timeout.
+	Client *http.Client
+}
+
+// VaultSecretService loads secrets from a Vault-compatible key/value HTTP API.
+// The key of a secret is its path within the mount and prefix of the service.
+type VaultSecretService struct {
+	addr   *url.URL
+	config VaultConfig
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]vaultCacheEntry
+}
+
+type vaultCacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NewVaultSecretService creates a secret service for the server of the config.
+func NewVaultSecretService(c VaultConfig) (*VaultSecretService, error) {
+	if c.Address == "" {
+		return nil, errors.New(codes.Invalid, "vault address is required")
+	}
+	addr, err := url.Parse(c.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid vault address")
+	}
+	if addr.Scheme != "http" && addr.Scheme != "https" {
+		return nil, errors.Newf(codes.Invalid, "vault address scheme must be http or https but was %q", addr.Scheme)
+	}
+	if c.Mount == "" {
+		c.Mount = DefaultVaultMount
+	}
+	if c.Field == "" {
+		c.Field = DefaultVaultField
+	}
+	switch c.KVVersion {
+	case 0:
+		c.KVVersion = 2
+	case 1, 2:
+	default:
+		return nil, errors.Newf(codes.Invalid, "vault kv version must be 1 or 2, got %d", c.KVVersion)
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &VaultSecretService{
+		addr:   addr,
+		config: c,
+		now:    time.Now,
+		cache:  make(map[string]vaultCacheEntry),
+	}, nil
+}
+
+func (s *VaultSecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	if s.config.CacheTTL > 0 {
+		s.mu.Lock()
+		e, ok := s.cache[k]
+		s.mu.Unlock()
+		if ok && s.now().Before(e.expires) {
+			return e.value, nil
+		}
+	}
+
+	v, err := s.read(ctx, k)
+	if err != nil {
+		return "", err
+	}
+
+	if s.config.CacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[k] = vaultCacheEntry{
+			value:   v,
+			expires: s.now().Add(s.config.CacheTTL),
+		}
+		s.mu.Unlock()
+	}
+	return v, nil
+}
+
+// secretURL is the URL of the secret of the key.
+func (s *VaultSecretService) secretURL(k string) string {
+	p := []string{"v1", s.config.Mount}
+	if s.config.KVVersion == 2 {
+		p = append(p, "data")
+	}
+	p = append(p, s.config.Prefix, k)
+	u := *s.addr
+	u.Path = path.Join(append([]string{u.Path}, p...)...)
+	return u.String()
+}
+
+func (s *VaultSecretService) read(ctx context.Context, k string) (string, error) {
+	if k == "" || strings.Contains(k, "..") {
+		return "", errors.Newf(codes.Invalid, "invalid secret key %q", k)
+	}
+	req, err := http.NewRequest(http.MethodGet, s.secretURL(k), nil)
+	if err != nil {
+		return "", errors.Wrap(err, codes.Internal, "failed to create vault request")
+	}
+	req = req.WithContext(ctx)
+	if s.config.Token != "" {
+		req.Header.Set("X-Vault-Token", s.config.Token)
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, codes.Unavailable, "failed to read secret from vault")
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", errors.Newf(codes.PermissionDenied, "permission denied reading secret key %q from vault", k)
+	default:
+		return "", errors.Newf(codes.Unavailable, "unexpected status reading secret key %q from vault: %s", k, resp.Status)
+	}
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrap(err, codes.Internal, "failed to decode vault response")
+	}
+	data := body.Data
+	if s.config.KVVersion == 2 {
+		// Version 2 nests the secret with its metadata.
+		var v2 struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &v2); err != nil {
+			return "", errors.Wrap(err, codes.Internal, "failed to decode vault response")
+		}
+		data = v2.Data
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", errors.Wrap(err, codes.Internal, "failed to decode vault response")
+	}
+	// A deleted version 2 secret has no data.
+	if fields == nil {
+		return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+	}
+	v, ok := fields[s.config.Field]
+	if !ok {
+		return "", errors.Newf(codes.NotFound, "secret key %q has no field %q", k, s.config.Field)
+	}
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(v), nil
+}
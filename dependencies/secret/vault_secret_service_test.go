@@ -0,0 +1,174 @@
+package secret_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// newVaultServer creates a stand-in for a Vault server
+// that serves the secrets with the token.
+func newVaultServer(t *testing.T, token string, secrets map[string]string, requests *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(requests, 1)
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+			return
+		}
+		body, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"errors": []}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, body)
+	}))
+}
+
+func TestVaultSecretService(t *testing.T) {
+	var requests int64
+	server := newVaultServer(t, "s.token", map[string]string{
+		"/v1/secret/data/flux/db":     `{"data": {"data": {"value": "dbpassword", "port": 5432}, "metadata": {"version": 1}}}`,
+		"/v1/secret/data/flux/delete": `{"data": {"data": null, "metadata": {"version": 2}}}`,
+		"/v1/kv/flux/db":              `{"data": {"value": "v1password"}}`,
+	}, &requests)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name    string
+		config  secret.VaultConfig
+		key     string
+		want    string
+		wantErr codes.Code
+	}{
+		{
+			name:   "kv version 2",
+			config: secret.VaultConfig{Token: "s.token", Prefix: "flux"},
+			key:    "db",
+			want:   "dbpassword",
+		},
+		{
+			name:   "field",
+			config: secret.VaultConfig{Token: "s.token", Prefix: "flux", Field: "port"},
+			key:    "db",
+			want:   "5432",
+		},
+		{
+			name:   "kv version 1",
+			config: secret.VaultConfig{Token: "s.token", Mount: "kv", KVVersion: 1},
+			key:    "flux/db",
+			want:   "v1password",
+		},
+		{
+			name:    "not found",
+			config:  secret.VaultConfig{Token: "s.token", Prefix: "flux"},
+			key:     "missing",
+			wantErr: codes.NotFound,
+		},
+		{
+			name:    "deleted",
+			config:  secret.VaultConfig{Token: "s.token", Prefix: "flux"},
+			key:     "delete",
+			wantErr: codes.NotFound,
+		},
+		{
+			name:    "missing field",
+			config:  secret.VaultConfig{Token: "s.token", Prefix: "flux", Field: "user"},
+			key:     "db",
+			wantErr: codes.NotFound,
+		},
+		{
+			name:    "invalid token",
+			config:  secret.VaultConfig{Token: "s.other", Prefix: "flux"},
+			key:     "db",
+			wantErr: codes.PermissionDenied,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.Address = server.URL
+			s, err := secret.NewVaultSecretService(tc.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.LoadSecret(context.Background(), tc.key)
+			if tc.wantErr != 0 {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				if code := errors.Code(err); code != tc.wantErr {
+					t.Fatalf("unexpected error code: want %v got %v (%v)", tc.wantErr, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("unexpected secret: want %q got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVaultSecretService_Cache(t *testing.T) {
+	var requests int64
+	server := newVaultServer(t, "s.token", map[string]string{
+		"/v1/secret/data/db": `{"data": {"data": {"value": "dbpassword"}}}`,
+	}, &requests)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name string
+		ttl  time.Duration
+		want int64
+	}{
+		{name: "cached", ttl: time.Minute, want: 1},
+		{name: "not cached", want: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt64(&requests, 0)
+			s, err := secret.NewVaultSecretService(secret.VaultConfig{
+				Address:  server.URL,
+				Token:    "s.token",
+				CacheTTL: tc.ttl,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 3; i++ {
+				if v, err := s.LoadSecret(context.Background(), "db"); err != nil {
+					t.Fatal(err)
+				} else if v != "dbpassword" {
+					t.Fatalf("unexpected secret %q", v)
+				}
+			}
+			if got := atomic.LoadInt64(&requests); got != tc.want {
+				t.Errorf("unexpected number of requests: want %d got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewVaultSecretService_Errors(t *testing.T) {
+	for _, c := range []secret.VaultConfig{
+		{},
+		{Address: "ftp://127.0.0.1"},
+		{Address: "http://127.0.0.1:8200", KVVersion: 3},
+	} {
+		if _, err := secret.NewVaultSecretService(c); err == nil {
+			t.Errorf("expected an error for config %+v", c)
+		} else if code := errors.Code(err); code != codes.Invalid {
+			t.Errorf("unexpected error code: want %v got %v (%v)", codes.Invalid, code, err)
+		}
+	}
+}
@@ -36,6 +36,14 @@ func (c AggregateConfig) Copy() AggregateConfig {
 	return nc
 }
 
+// Cost estimates an aggregate as producing a single row for each table.
+func (c AggregateConfig) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	stats := plan.CombineStatistics(inStats)
+	cost := plan.Cost{CPU: stats.Cardinality}
+	stats.Cardinality = stats.GroupCardinality
+	return cost, stats
+}
+
 func (c *AggregateConfig) ReadArgs(args flux.Arguments) error {
 	if col, ok, err := args.GetString("column"); err != nil {
 		return err
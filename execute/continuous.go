@@ -0,0 +1,224 @@
+package execute
+
+import (
+	"context"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/metadata"
+	"github.com/influxdata/flux/plan"
+)
+
+// ContinuousExecutor executes plans that read unbounded data.
+type ContinuousExecutor interface {
+	// ExecuteContinuous will begin execution of the plan.Spec in continuous mode.
+	//
+	// Rather than one result per yield, the results are sent on the returned
+	// channel as panes. Each time the watermark or the processing time of a
+	// yield advances, the tables the yield received since its last pane are sent
+	// as a result with the name of the yield. The channel is closed once all of
+	// the sources have finished or the context is cancelled, so a query that
+	// reads from a long-lived source produces results until it is cancelled.
+	//
+	// The metadata channel behaves like the one returned by Execute.
+	ExecuteContinuous(ctx context.Context, p *plan.Spec, a *memory.Allocator) (<-chan flux.Result, <-chan metadata.Metadata, error)
+}
+
+type continuousKey struct{}
+
+// WithContinuousExecution marks the context of a query that is executed continuously.
+// The planner and the sources use it to produce incremental results.
+func WithContinuousExecution(ctx context.Context) context.Context {
+	return context.WithValue(ctx, continuousKey{}, true)
+}
+
+// IsContinuousExecution reports whether the query of the context is executed continuously.
+// A source that supports it keeps reading once its input is exhausted and advances
+// the watermark of its transformations as it reads, instead of finishing.
+func IsContinuousExecution(ctx context.Context) bool {
+	continuous, _ := ctx.Value(continuousKey{}).(bool)
+	return continuous
+}
+
+func (e *executor) ExecuteContinuous(ctx context.Context, p *plan.Spec, a *memory.Allocator) (<-chan flux.Result, <-chan metadata.Metadata, error) {
+	panes := newPaneStream(ctx)
+	es, err := e.createExecutionState(WithContinuousExecution(ctx), p, a, panes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, codes.Inherit, "failed to initialize execute state")
+	}
+	panes.start()
+	es.do()
+	return panes.ch, es.metaCh, nil
+}
+
+// paneStream sends the panes of the continuous results of a query.
+type paneStream struct {
+	// done is closed once the caller of the query is no longer reading.
+	done <-chan struct{}
+	ch   chan flux.Result
+
+	// aborted is closed when the query is aborted so that
+	// the results stop sending panes.
+	aborted   chan struct{}
+	abortOnce sync.Once
+
+	// mu guards closing ch. Senders hold a read lock while they send.
+	mu        sync.RWMutex
+	remaining int
+	closed    bool
+}
+
+func newPaneStream(ctx context.Context) *paneStream {
+	return &paneStream{
+		done:    ctx.Done(),
+		ch:      make(chan flux.Result),
+		aborted: make(chan struct{}),
+	}
+}
+
+func (s *paneStream) newResult(name string) *continuousResult {
+	s.remaining++
+	return &continuousResult{
+		name:  name,
+		panes: s,
+	}
+}
+
+// start closes the stream if there are no results to wait for.
+func (s *paneStream) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remaining == 0 {
+		s.close()
+	}
+}
+
+func (s *paneStream) send(p flux.Result) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- p:
+	case <-s.aborted:
+	}
+}
+
+// finish records that one of the results has finished
+// and closes the stream after the last one.
+func (s *paneStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remaining--; s.remaining == 0 && !s.closed {
+		s.close()
+	}
+}
+
+// abort stops the results from sending panes and closes the stream.
+// The error is sent as a last pane unless the caller has stopped reading.
+func (s *paneStream) abort(err error) {
+	s.abortOnce.Do(func() {
+		close(s.aborted)
+		go func() {
+			// The lock can only be taken once the senders
+			// have observed that the stream is aborted.
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.closed {
+				return
+			}
+			select {
+			case <-s.done:
+			default:
+				if err != nil {
+					select {
+					case s.ch <- &pane{err: err}:
+					case <-s.done:
+					}
+				}
+			}
+			s.close()
+		}()
+	})
+}
+
+func (s *paneStream) close() {
+	s.closed = true
+	close(s.ch)
+}
+
+// continuousResult buffers the tables of a yield and sends them
+// as a pane when the watermark or the processing time advances.
+type continuousResult struct {
+	ExecutionNode
+	name   string
+	panes  *paneStream
+	tables []flux.Table
+}
+
+func (r *continuousResult) RetractTable(DatasetID, flux.GroupKey) error {
+	return nil
+}
+
+func (r *continuousResult) Process(id DatasetID, tbl flux.Table) error {
+	r.tables = append(r.tables, tbl)
+	return nil
+}
+
+func (r *continuousResult) UpdateWatermark(id DatasetID, mark Time) error {
+	r.flush()
+	return nil
+}
+
+func (r *continuousResult) UpdateProcessingTime(id DatasetID, t Time) error {
+	r.flush()
+	return nil
+}
+
+func (r *continuousResult) Finish(id DatasetID, err error) {
+	r.flush()
+	if err != nil {
+		r.panes.send(&pane{name: r.name, err: err})
+	}
+	r.panes.finish()
+}
+
+func (r *continuousResult) flush() {
+	if len(r.tables) == 0 {
+		return
+	}
+	r.panes.send(&pane{name: r.name, tables: r.tables})
+	r.tables = nil
+}
+
+// pane is the part of a continuous result that was produced
+// between two advances of the watermark or the processing time.
+type pane struct {
+	name   string
+	tables []flux.Table
+	err    error
+}
+
+func (p *pane) Name() string {
+	return p.name
+}
+
+func (p *pane) Tables() flux.TableIterator {
+	return p
+}
+
+func (p *pane) Do(f func(flux.Table) error) error {
+	if p.err != nil {
+		return p.err
+	}
+	for _, tbl := range p.tables {
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
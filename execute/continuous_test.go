@@ -0,0 +1,267 @@
+package execute_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/stdlib/universe"
+	"go.uber.org/zap/zaptest"
+)
+
+const streamTestKind = "stream-test"
+
+func init() {
+	execute.RegisterSource(streamTestKind, func(spec plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
+		return &streamSource{id: id, spec: spec.(*streamProcedureSpec)}, nil
+	})
+}
+
+// streamBatch is a batch of tables that a streamSource
+// processes before it advances the watermark.
+type streamBatch struct {
+	tables    []*executetest.Table
+	watermark execute.Time
+}
+
+type streamProcedureSpec struct {
+	plan.DefaultCost
+	batches []streamBatch
+	// wait makes the source wait until it is cancelled
+	// once it has processed its batches.
+	wait bool
+	err  error
+}
+
+func (s *streamProcedureSpec) Kind() plan.ProcedureKind {
+	return streamTestKind
+}
+
+func (s *streamProcedureSpec) Copy() plan.ProcedureSpec {
+	return s
+}
+
+type streamSource struct {
+	execute.ExecutionNode
+	id   execute.DatasetID
+	spec *streamProcedureSpec
+	ts   []execute.Transformation
+}
+
+func (s *streamSource) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *streamSource) Run(ctx context.Context) {
+	err := s.run(ctx)
+	for _, t := range s.ts {
+		t.Finish(s.id, err)
+	}
+}
+
+func (s *streamSource) run(ctx context.Context) error {
+	for _, b := range s.spec.batches {
+		for _, tbl := range b.tables {
+			tbl.Normalize()
+			for _, t := range s.ts {
+				if err := t.Process(s.id, tbl); err != nil {
+					return err
+				}
+			}
+		}
+		for _, t := range s.ts {
+			if err := t.UpdateWatermark(s.id, b.watermark); err != nil {
+				return err
+			}
+		}
+	}
+	if s.spec.wait {
+		<-ctx.Done()
+	}
+	return s.spec.err
+}
+
+func windowTable(start, stop execute.Time, values ...float64) *executetest.Table {
+	tbl := &executetest.Table{
+		KeyCols: []string{"_start", "_stop"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_start", Type: flux.TTime},
+			{Label: "_stop", Type: flux.TTime},
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+		},
+	}
+	for i, v := range values {
+		tbl.Data = append(tbl.Data, []interface{}{start, stop, start + execute.Time(i), v})
+	}
+	return tbl
+}
+
+func sumTable(start, stop execute.Time, sum float64) *executetest.Table {
+	return &executetest.Table{
+		KeyCols: []string{"_start", "_stop"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_start", Type: flux.TTime},
+			{Label: "_stop", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{{start, stop, sum}},
+	}
+}
+
+// executeContinuous executes a source followed by a sum in continuous mode.
+func executeContinuous(ctx context.Context, t *testing.T, spec *streamProcedureSpec) <-chan flux.Result {
+	ps := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("stream", spec),
+			plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+				AggregateConfig: execute.DefaultAggregateConfig,
+			}),
+			plan.CreatePhysicalNode("yield", executetest.NewYieldProcedureSpec("_result")),
+		},
+		Edges: [][2]int{
+			{0, 1},
+			{1, 2},
+		},
+		Resources: flux.ResourceManagement{
+			ConcurrencyQuota: 1,
+			MemoryBytesQuota: math.MaxInt64,
+		},
+		Now: time.Now(),
+	})
+
+	exe := execute.NewExecutor(zaptest.NewLogger(t)).(execute.ContinuousExecutor)
+	ctx = executetest.NewTestExecuteDependencies().Inject(ctx)
+	panes, _, err := exe.ExecuteContinuous(ctx, ps, executetest.UnlimitedAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return panes
+}
+
+// nextPane reads the tables of the next pane.
+// It returns false when there are no more panes.
+func nextPane(t *testing.T, panes <-chan flux.Result) ([]*executetest.Table, bool, error) {
+	t.Helper()
+	select {
+	case res, ok := <-panes:
+		if !ok {
+			return nil, false, nil
+		}
+		if res.Name() != "_result" && res.Name() != "" {
+			t.Errorf("unexpected result name %q", res.Name())
+		}
+		var tables []*executetest.Table
+		err := res.Tables().Do(func(tbl flux.Table) error {
+			cb, err := executetest.ConvertTable(tbl)
+			if err != nil {
+				return err
+			}
+			tables = append(tables, cb)
+			return nil
+		})
+		executetest.NormalizeTables(tables)
+		return tables, true, err
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a pane")
+		return nil, false, nil
+	}
+}
+
+func TestExecutor_ExecuteContinuous(t *testing.T) {
+	panes := executeContinuous(context.Background(), t, &streamProcedureSpec{
+		batches: []streamBatch{
+			{tables: []*executetest.Table{windowTable(0, 10, 1, 2)}, watermark: 5},
+			{tables: []*executetest.Table{windowTable(10, 20, 3)}, watermark: 12},
+			{tables: []*executetest.Table{windowTable(20, 30, 4, 5)}, watermark: 25},
+		},
+	})
+
+	// The sum of a window is emitted once the watermark passes the window
+	// and the last one is emitted when the source finishes.
+	want := [][]*executetest.Table{
+		{sumTable(0, 10, 3)},
+		{sumTable(10, 20, 3)},
+		{sumTable(20, 30, 9)},
+	}
+	for _, w := range want {
+		executetest.NormalizeTables(w)
+	}
+
+	var got [][]*executetest.Table
+	for {
+		tables, ok, err := nextPane(t, panes)
+		if !ok {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tables)
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected panes -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestExecutor_ExecuteContinuous_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	panes := executeContinuous(ctx, t, &streamProcedureSpec{
+		batches: []streamBatch{
+			{tables: []*executetest.Table{windowTable(0, 10, 1, 2)}, watermark: 10},
+		},
+		wait: true,
+	})
+
+	want := []*executetest.Table{sumTable(0, 10, 3)}
+	executetest.NormalizeTables(want)
+	got, ok, err := nextPane(t, panes)
+	if !ok {
+		t.Fatal("expected a pane before the query is cancelled")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected pane -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	cancel()
+	for {
+		if _, ok, _ := nextPane(t, panes); !ok {
+			break
+		}
+	}
+}
+
+func TestExecutor_ExecuteContinuous_Error(t *testing.T) {
+	panes := executeContinuous(context.Background(), t, &streamProcedureSpec{
+		batches: []streamBatch{
+			{tables: []*executetest.Table{windowTable(0, 10, 1, 2)}, watermark: 10},
+		},
+		err: errors.New(codes.Unavailable, "connection lost"),
+	})
+
+	if _, ok, err := nextPane(t, panes); !ok || err != nil {
+		t.Fatalf("expected the first pane, got ok=%v err=%v", ok, err)
+	}
+	_, ok, err := nextPane(t, panes)
+	if !ok {
+		t.Fatal("expected a pane with the error")
+	}
+	if got, want := errors.Code(err), codes.Unavailable; got != want {
+		t.Errorf("unexpected error code: got %v want %v (%v)", got, want, err)
+	}
+	if _, ok, _ := nextPane(t, panes); ok {
+		t.Error("expected no more panes")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/influxdata/flux/interval"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/metadata"
 	"go.uber.org/zap"
@@ -28,6 +29,11 @@ type ExecutionDependencies struct {
 	// Allowed to be nil
 	Logger *zap.Logger
 
+	// Location is the location option of the query resolved
+	// while it is compiled. Date functions use it when they
+	// are not given a location.
+	Location *interval.Location
+
 	// Metadata is passed up from any invocations of execution up to the parent
 	// execution, and out through the statistics.
 	Metadata metadata.Metadata
@@ -61,6 +67,7 @@ func NewExecutionDependencies(allocator *memory.Allocator, now *time.Time, logge
 	return ExecutionDependencies{
 		Allocator:        allocator,
 		Now:              now,
+		Location:         new(interval.Location),
 		Logger:           logger,
 		Metadata:         make(metadata.Metadata),
 		ExecutionOptions: &ExecutionOptions{},
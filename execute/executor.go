@@ -61,6 +61,9 @@ type executionState struct {
 	resources flux.ResourceManagement
 
 	results map[string]flux.Result
+	// panes sends the results of a continuous execution.
+	// It is nil unless the plan is executed continuously.
+	panes   *paneStream
 	sources []Source
 	metaCh  chan metadata.Metadata
 
@@ -68,10 +71,18 @@ type executionState struct {
 
 	dispatcher *poolDispatcher
 	logger     *zap.Logger
+
+	// profiler is the operator profiler of the query, if any.
+	profiler *OperatorProfiler
+
+	// limits are the limits of the query and readLimits
+	// enforces the limits on what its sources read.
+	limits     flux.QueryLimits
+	readLimits *readLimits
 }
 
 func (e *executor) Execute(ctx context.Context, p *plan.Spec, a *memory.Allocator) (map[string]flux.Result, <-chan metadata.Metadata, error) {
-	es, err := e.createExecutionState(ctx, p, a)
+	es, err := e.createExecutionState(ctx, p, a, nil)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, codes.Inherit, "failed to initialize execute state")
 	}
@@ -86,7 +97,7 @@ func validatePlan(p *plan.Spec) error {
 	return nil
 }
 
-func (e *executor) createExecutionState(ctx context.Context, p *plan.Spec, a *memory.Allocator) (*executionState, error) {
+func (e *executor) createExecutionState(ctx context.Context, p *plan.Spec, a *memory.Allocator, panes *paneStream) (*executionState, error) {
 	if err := validatePlan(p); err != nil {
 		return nil, errors.Wrap(err, codes.Invalid, "invalid plan")
 	}
@@ -99,13 +110,22 @@ func (e *executor) createExecutionState(ctx context.Context, p *plan.Spec, a *me
 		alloc:     a,
 		resources: p.Resources,
 		results:   make(map[string]flux.Result),
+		panes:     panes,
 		// TODO(nathanielc): Have the planner specify the dispatcher throughput
 		dispatcher: newPoolDispatcher(10, e.logger),
 		logger:     e.logger,
+		limits:     flux.GetQueryLimits(ctx),
+	}
+	es.readLimits = newReadLimits(es.limits)
+	if HaveExecutionDependencies(ctx) {
+		if opts := GetExecutionDependencies(ctx).ExecutionOptions; opts != nil {
+			es.profiler = opts.OperatorProfiler
+		}
 	}
 	v := &createExecutionNodeVisitor{
 		es:    es,
 		nodes: make(map[plan.Node]Node),
+		stats: make(map[plan.Node]*operatorStats),
 	}
 
 	if err := p.BottomUpWalk(v.Visit); err != nil {
@@ -125,6 +145,9 @@ func (e *executor) createExecutionState(ctx context.Context, p *plan.Spec, a *me
 type createExecutionNodeVisitor struct {
 	es    *executionState
 	nodes map[plan.Node]Node
+	// stats holds the statistics of each node
+	// when the operator profiler is enabled.
+	stats map[plan.Node]*operatorStats
 }
 
 func skipYields(pn plan.Node) plan.Node {
@@ -160,6 +183,11 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 	id := DatasetIDFromNodeID(node.ID())
 
 	if yieldSpec, ok := spec.(plan.YieldProcedureSpec); ok {
+		if v.es.panes != nil {
+			r := v.es.panes.newResult(yieldSpec.YieldName())
+			v.nodes[skipYields(node)].AddTransformation(r)
+			return nil
+		}
 		r := newResult(yieldSpec.YieldName())
 		v.es.results[yieldSpec.YieldName()] = r
 		v.nodes[skipYields(node)].AddTransformation(r)
@@ -186,6 +214,13 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 		ec.parents[i] = DatasetIDFromNodeID(pred.ID())
 	}
 
+	var stats *operatorStats
+	if v.es.profiler != nil {
+		stats = v.es.profiler.newOperatorStats(string(node.ID()), v.es.alloc)
+		v.stats[node] = stats
+		ec.alloc = stats.alloc
+	}
+
 	// If node is a leaf, create a source
 	if len(node.Predecessors()) == 0 {
 		createSourceFn, ok := procedureToSource[kind]
@@ -201,8 +236,20 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 		}
 
 		source.SetLabel(string(node.ID()))
+		if stats != nil {
+			stats.typ = reflect.TypeOf(source).String()
+		}
 		v.es.sources = append(v.es.sources, source)
 		v.nodes[node] = source
+		if v.es.readLimits != nil {
+			limiter := &sourceLimiter{limits: v.es.readLimits}
+			limiter.SetLabel(string(node.ID()))
+			source.AddTransformation(limiter)
+			v.nodes[node] = limiter
+		}
+		if stats != nil {
+			v.profileOutput(node, stats)
+		}
 	} else {
 
 		// If node is internal, create a transformation.
@@ -213,32 +260,54 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 			return fmt.Errorf("unsupported procedure %v", kind)
 		}
 
-		tr, ds, err := createTransformationFn(id, DiscardingMode, spec, ec)
-
-		if err != nil {
-			return err
+		if ppn.TriggerSpec == nil {
+			ppn.TriggerSpec = plan.DefaultTriggerSpec
 		}
 
-		if ds, ok := ds.(DatasetContext); ok {
-			ds.WithContext(v.es.ctx)
-		}
+		if ppn.Parallelism > 1 && len(node.Predecessors()) == 1 {
+			// Partition the input of the node across copies of the transformation.
+			ds, err := v.createPartitions(ppn, createTransformationFn, ec, stats)
+			if err != nil {
+				return err
+			}
+			v.nodes[node] = ds
+		} else {
+			tr, ds, err := createTransformationFn(id, DiscardingMode, spec, ec)
 
-		tr.SetLabel(string(node.ID()))
-		if ppn.TriggerSpec == nil {
-			ppn.TriggerSpec = plan.DefaultTriggerSpec
+			if err != nil {
+				return err
+			}
+
+			if ds, ok := ds.(DatasetContext); ok {
+				ds.WithContext(v.es.ctx)
+			}
+
+			tr.SetLabel(string(node.ID()))
+			if stats != nil {
+				stats.typ = reflect.TypeOf(tr).String()
+			}
+			ds.SetTriggerSpec(ppn.TriggerSpec)
+			v.nodes[node] = ds
+
+			for _, p := range nonYieldPredecessors(node) {
+				executionNode := v.nodes[p]
+				transport := newConsecutiveTransport(v.es.ctx, v.es.dispatcher, tr, node, v.es.logger)
+				transport.stats = stats
+				v.es.transports = append(v.es.transports, transport)
+				executionNode.AddTransformation(transport)
+			}
 		}
-		ds.SetTriggerSpec(ppn.TriggerSpec)
-		v.nodes[node] = ds
-
-		for _, p := range nonYieldPredecessors(node) {
-			executionNode := v.nodes[p]
-			transport := newConsecutiveTransport(v.es.ctx, v.es.dispatcher, tr, node, v.es.logger)
-			v.es.transports = append(v.es.transports, transport)
-			executionNode.AddTransformation(transport)
+		if stats != nil {
+			v.profileOutput(node, stats)
 		}
 
 		if plan.HasSideEffect(spec) && len(node.Successors()) == 0 {
 			name := string(node.ID())
+			if v.es.panes != nil {
+				r := v.es.panes.newResult(name)
+				v.nodes[skipYields(node)].AddTransformation(r)
+				return nil
+			}
 			r := newResult(name)
 			v.es.results[name] = r
 			v.nodes[skipYields(node)].AddTransformation(r)
@@ -248,24 +317,47 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 	return nil
 }
 
+// profileOutput counts the tables and rows that the operator
+// of the node outputs before they reach its transformations.
+func (v *createExecutionNodeVisitor) profileOutput(node plan.Node, stats *operatorStats) {
+	out := &profilingOutput{stats: stats}
+	out.SetLabel(string(node.ID()))
+	v.nodes[node].AddTransformation(out)
+	v.nodes[node] = out
+}
+
 func (es *executionState) abort(err error) {
 	for _, r := range es.results {
 		r.(*result).abort(err)
 	}
+	if es.panes != nil {
+		es.panes.abort(err)
+	}
 	es.cancel()
 }
 
 func (es *executionState) do() {
+	// Abort the query once it has run for longer than it may.
+	var timer *time.Timer
+	if d := es.limits.MaxRuntime; d > 0 {
+		timer = time.AfterFunc(d, func() {
+			es.abort(errors.Newf(codes.ResourceExhausted, "query exceeded the max runtime of %v", d))
+		})
+	}
+
 	var wg sync.WaitGroup
 	for _, src := range es.sources {
 		wg.Add(1)
 		go func(src Source) {
+			// The span must finish before the execution is marked
+			// as done so the profiler receives it before its results are read.
+			defer wg.Done()
+
 			ctx := es.ctx
 			if ctxWithSpan, span := StartSpanFromContext(ctx, reflect.TypeOf(src).String(), src.Label()); span != nil {
 				ctx = ctxWithSpan
 				defer span.Finish()
 			}
-			defer wg.Done()
 
 			// Setup panic handling on the source goroutines
 			defer func() {
@@ -324,6 +416,9 @@ func (es *executionState) do() {
 	go func() {
 		defer close(es.metaCh)
 		wg.Wait()
+		if timer != nil {
+			timer.Stop()
+		}
 	}()
 }
 
@@ -332,6 +427,9 @@ type executionContext struct {
 	es            *executionState
 	parents       []DatasetID
 	streamContext streamContext
+	// alloc is the allocator of the node when it
+	// differs from the allocator of the query.
+	alloc *memory.Allocator
 }
 
 func resolveTime(qt flux.Time, now time.Time) Time {
@@ -351,6 +449,9 @@ func (ec executionContext) StreamContext() StreamContext {
 }
 
 func (ec executionContext) Allocator() *memory.Allocator {
+	if ec.alloc != nil {
+		return ec.alloc
+	}
 	return ec.es.alloc
 }
 
@@ -0,0 +1,152 @@
+package execute
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// readLimits enforces the limits on the rows and the series
+// that the sources of a query read. The limits are shared
+// by all of the sources of the query.
+type readLimits struct {
+	maxRows   int64
+	maxSeries int64
+
+	rows int64
+
+	mu     sync.Mutex
+	series map[string]struct{}
+}
+
+func newReadLimits(l flux.QueryLimits) *readLimits {
+	if l.MaxRowsRead <= 0 && l.MaxSeries <= 0 {
+		return nil
+	}
+	return &readLimits{
+		maxRows:   l.MaxRowsRead,
+		maxSeries: l.MaxSeries,
+		series:    make(map[string]struct{}),
+	}
+}
+
+// addRows records that n more rows were read.
+func (l *readLimits) addRows(n int64) error {
+	if l.maxRows <= 0 {
+		return nil
+	}
+	if rows := atomic.AddInt64(&l.rows, n); rows > l.maxRows {
+		return errors.Newf(codes.ResourceExhausted, "query exceeded the max rows read limit of %d", l.maxRows)
+	}
+	return nil
+}
+
+// addSeries records that a table with the group key was read.
+func (l *readLimits) addSeries(key flux.GroupKey) error {
+	if l.maxSeries <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.series[key.String()] = struct{}{}
+	if int64(len(l.series)) > l.maxSeries {
+		return errors.Newf(codes.ResourceExhausted, "query exceeded the max series limit of %d", l.maxSeries)
+	}
+	return nil
+}
+
+// sourceLimiter sits between a source and its transformations
+// and counts the series and the rows the source reads.
+// Once the source exceeds the series limit, its transformations
+// receive no more tables and finish with the error.
+type sourceLimiter struct {
+	ExecutionNode
+	limits *readLimits
+	ts     []Transformation
+	err    error
+}
+
+func (s *sourceLimiter) AddTransformation(t Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *sourceLimiter) RetractTable(id DatasetID, key flux.GroupKey) error {
+	for _, t := range s.ts {
+		if err := t.RetractTable(id, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sourceLimiter) Process(id DatasetID, tbl flux.Table) error {
+	if s.err != nil {
+		tbl.Done()
+		return s.err
+	}
+	if err := s.limits.addSeries(tbl.Key()); err != nil {
+		tbl.Done()
+		s.err = err
+		return err
+	}
+	if s.limits.maxRows > 0 {
+		tbl = &limitedTable{Table: tbl, limits: s.limits}
+	}
+	for _, t := range s.ts {
+		if err := t.Process(id, tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sourceLimiter) UpdateWatermark(id DatasetID, mark Time) error {
+	for _, t := range s.ts {
+		if err := t.UpdateWatermark(id, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sourceLimiter) UpdateProcessingTime(id DatasetID, pt Time) error {
+	for _, t := range s.ts {
+		if err := t.UpdateProcessingTime(id, pt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sourceLimiter) Finish(id DatasetID, err error) {
+	if err == nil {
+		err = s.err
+	}
+	for _, t := range s.ts {
+		t.Finish(id, err)
+	}
+}
+
+// limitedTable counts the rows of a table as they are read.
+// A table that is read by more than one transformation
+// is counted the first time it is read.
+type limitedTable struct {
+	flux.Table
+	limits *readLimits
+	read   int32
+}
+
+func (t *limitedTable) Do(f func(flux.ColReader) error) error {
+	if !atomic.CompareAndSwapInt32(&t.read, 0, 1) {
+		return t.Table.Do(f)
+	}
+	return t.Table.Do(func(cr flux.ColReader) error {
+		if err := t.limits.addRows(int64(cr.Len())); err != nil {
+			return err
+		}
+		return f(cr)
+	})
+}
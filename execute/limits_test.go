@@ -0,0 +1,122 @@
+package execute_test
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/stdlib/universe"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExecutor_Execute_QueryLimits(t *testing.T) {
+	// Three series with five rows each.
+	input := func() []*executetest.Table {
+		return []*executetest.Table{
+			windowTable(0, 10, 1, 2, 3, 4, 5),
+			windowTable(10, 20, 1, 2, 3, 4, 5),
+			windowTable(20, 30, 1, 2, 3, 4, 5),
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		source  plan.PhysicalProcedureSpec
+		limits  flux.QueryLimits
+		wantErr string
+	}{
+		{
+			name:   "no limits",
+			source: executetest.NewFromProcedureSpec(input()),
+		},
+		{
+			name:   "within limits",
+			source: executetest.NewFromProcedureSpec(input()),
+			limits: flux.QueryLimits{
+				MaxRowsRead: 15,
+				MaxSeries:   3,
+				MaxRuntime:  time.Minute,
+			},
+		},
+		{
+			name:    "max rows read",
+			source:  executetest.NewFromProcedureSpec(input()),
+			limits:  flux.QueryLimits{MaxRowsRead: 12},
+			wantErr: "query exceeded the max rows read limit of 12",
+		},
+		{
+			name:    "max series",
+			source:  executetest.NewFromProcedureSpec(input()),
+			limits:  flux.QueryLimits{MaxSeries: 2},
+			wantErr: "query exceeded the max series limit of 2",
+		},
+		{
+			name:    "max runtime",
+			source:  &streamProcedureSpec{wait: true},
+			limits:  flux.QueryLimits{MaxRuntime: 10 * time.Millisecond},
+			wantErr: "query exceeded the max runtime of 10ms",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ps := plantest.CreatePlanSpec(&plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", tc.source),
+					plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+					plan.CreatePhysicalNode("yield", executetest.NewYieldProcedureSpec("_result")),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+				Resources: flux.ResourceManagement{
+					ConcurrencyQuota: 1,
+					MemoryBytesQuota: math.MaxInt64,
+				},
+				Now: time.Now(),
+			})
+
+			exe := execute.NewExecutor(zaptest.NewLogger(t))
+			ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+			ctx = flux.WithQueryLimits(ctx, tc.limits)
+			results, _, err := exe.Execute(ctx, ps, executetest.UnlimitedAllocator)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := 0
+			err = results["_result"].Tables().Do(func(tbl flux.Table) error {
+				n++
+				return tbl.Do(func(flux.ColReader) error { return nil })
+			})
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if n != 3 {
+					t.Errorf("unexpected number of tables: want 3 got %d", n)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Code(err); got != codes.ResourceExhausted {
+				t.Errorf("unexpected error code: want %v got %v (%v)", codes.ResourceExhausted, got, err)
+			}
+			if got := err.Error(); !strings.HasSuffix(got, tc.wantErr) {
+				t.Errorf("unexpected error: want %q got %q", tc.wantErr, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package execute
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interval"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// DefaultLocation returns the location of the location option of the query
+// or UTC when the query does not have one.
+func DefaultLocation(ctx context.Context) interval.Location {
+	if !HaveExecutionDependencies(ctx) {
+		return interval.UTC
+	}
+	deps := GetExecutionDependencies(ctx)
+	if deps.Location == nil {
+		return interval.UTC
+	}
+	return *deps.Location
+}
+
+// LocationFromValue reads a location from a record with a zone and an offset.
+func LocationFromValue(v values.Value) (interval.Location, error) {
+	if v.Type().Nature() != semantic.Object {
+		return interval.Location{}, errors.Newf(codes.Invalid, "location must be a record, got %v", v.Type())
+	}
+	var loc interval.Location
+	obj := v.Object()
+	if zone, ok := obj.Get("zone"); ok && !zone.IsNull() {
+		if zone.Type().Nature() != semantic.String {
+			return interval.Location{}, errors.Newf(codes.Invalid, "location zone must be a string, got %v", zone.Type())
+		}
+		loc.Zone = zone.Str()
+	}
+	if offset, ok := obj.Get("offset"); ok && !offset.IsNull() {
+		if offset.Type().Nature() != semantic.Duration {
+			return interval.Location{}, errors.Newf(codes.Invalid, "location offset must be a duration, got %v", offset.Type())
+		}
+		loc.Offset = offset.Duration()
+	}
+	if err := loc.Validate(); err != nil {
+		return interval.Location{}, err
+	}
+	return loc, nil
+}
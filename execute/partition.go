@@ -0,0 +1,245 @@
+package execute
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/plan"
+)
+
+// createPartitions creates the copies of the transformation of a node
+// whose input is partitioned by group key. The predecessor of the node sends
+// its tables to the copies through a partitioner, unless it is partitioned
+// itself and this node is its only successor. Its copies then partition
+// their tables straight to the copies of this node, so the tables of
+// consecutive partitioned nodes are not merged in between.
+func (v *createExecutionNodeVisitor) createPartitions(node *plan.PhysicalPlanNode, createFn CreateTransformation, ec executionContext, stats *operatorStats) (Node, error) {
+	n := node.Parallelism
+	parts := &partitions{
+		id:       DatasetIDFromNodeID(node.ID()),
+		ids:      make([]DatasetID, n),
+		datasets: make([]Dataset, n),
+	}
+	transports := make([]Transformation, n)
+	for i := range parts.ids {
+		id := DatasetIDFromNodeID(plan.NodeID(fmt.Sprintf("%s_partition%d", node.ID(), i)))
+		tr, ds, err := createFn(id, DiscardingMode, node.Spec.Copy(), ec)
+		if err != nil {
+			return nil, err
+		}
+		if ds, ok := ds.(DatasetContext); ok {
+			ds.WithContext(v.es.ctx)
+		}
+		tr.SetLabel(string(node.ID()))
+		if stats != nil {
+			stats.typ = reflect.TypeOf(tr).String()
+		}
+		ds.SetTriggerSpec(node.TriggerSpec)
+
+		transport := newConsecutiveTransport(v.es.ctx, v.es.dispatcher, tr, node, v.es.logger)
+		transport.stats = stats
+		v.es.transports = append(v.es.transports, transport)
+		transports[i] = transport
+		parts.ids[i], parts.datasets[i] = id, ds
+	}
+
+	pred := nonYieldPredecessors(node)[0]
+	if in, ok := v.nodes[pred].(*partitions); ok && in.merge == nil && len(pred.Successors()) == 1 {
+		// Each copy of this node merges the tables that
+		// the copies of the predecessor partition to it.
+		merges := make([]Transformation, n)
+		for i, t := range transports {
+			m := newPartitionMerge(in.id, in.ids)
+			m.AddTransformation(t)
+			merges[i] = m
+		}
+		for _, ds := range in.datasets {
+			ds.AddTransformation(&partitioner{ts: merges})
+		}
+	} else {
+		v.nodes[pred].AddTransformation(&partitioner{ts: transports})
+	}
+	return parts, nil
+}
+
+// partitions is the node of the copies of a transformation.
+// The tables of the copies are merged once the node is given
+// a transformation, which the copies of a partitioned successor
+// do not need.
+type partitions struct {
+	ExecutionNode
+	id       DatasetID
+	ids      []DatasetID
+	datasets []Dataset
+	merge    *partitionMerge
+}
+
+func (p *partitions) AddTransformation(t Transformation) {
+	if p.merge == nil {
+		p.merge = newPartitionMerge(p.id, p.ids)
+		for _, ds := range p.datasets {
+			ds.AddTransformation(p.merge)
+		}
+	}
+	p.merge.AddTransformation(t)
+}
+
+// partitioner sends each table to one of the transformations by its group key,
+// so the tables with the same group key are processed by the same transformation
+// in the order they were sent. The other messages are sent to all of them.
+type partitioner struct {
+	ExecutionNode
+	ts []Transformation
+}
+
+func (p *partitioner) partition(key flux.GroupKey) Transformation {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key.String()))
+	return p.ts[h.Sum32()%uint32(len(p.ts))]
+}
+
+func (p *partitioner) RetractTable(id DatasetID, key flux.GroupKey) error {
+	return p.partition(key).RetractTable(id, key)
+}
+
+func (p *partitioner) Process(id DatasetID, tbl flux.Table) error {
+	return p.partition(tbl.Key()).Process(id, tbl)
+}
+
+func (p *partitioner) UpdateWatermark(id DatasetID, mark Time) error {
+	for _, t := range p.ts {
+		if err := t.UpdateWatermark(id, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *partitioner) UpdateProcessingTime(id DatasetID, pt Time) error {
+	for _, t := range p.ts {
+		if err := t.UpdateProcessingTime(id, pt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *partitioner) Finish(id DatasetID, err error) {
+	for _, t := range p.ts {
+		t.Finish(id, err)
+	}
+}
+
+// partitionMerge merges the tables of the copies of a transformation.
+// A group key is only ever processed by one of the copies, so their tables
+// are sent on as they arrive, as the tables of the dataset with the id.
+// It advances the watermark and the processing time once all of the copies
+// have, and finishes once all of them have finished.
+type partitionMerge struct {
+	ExecutionNode
+	id DatasetID
+	ts TransformationSet
+
+	mu    sync.Mutex
+	parts map[DatasetID]*partitionState
+	// mark and processing are the watermark and
+	// the processing time sent to the transformations.
+	mark, processing Time
+	err              error
+	done             bool
+}
+
+type partitionState struct {
+	mark, processing Time
+	finished         bool
+}
+
+func newPartitionMerge(id DatasetID, parts []DatasetID) *partitionMerge {
+	m := &partitionMerge{
+		id:         id,
+		parts:      make(map[DatasetID]*partitionState, len(parts)),
+		mark:       Time(math.MinInt64),
+		processing: Time(math.MinInt64),
+	}
+	for _, id := range parts {
+		m.parts[id] = &partitionState{
+			mark:       Time(math.MinInt64),
+			processing: Time(math.MinInt64),
+		}
+	}
+	return m
+}
+
+func (m *partitionMerge) AddTransformation(t Transformation) {
+	m.ts = append(m.ts, t)
+}
+
+func (m *partitionMerge) RetractTable(id DatasetID, key flux.GroupKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ts.RetractTable(m.id, key)
+}
+
+func (m *partitionMerge) Process(id DatasetID, tbl flux.Table) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ts.Process(m.id, tbl)
+}
+
+func (m *partitionMerge) UpdateWatermark(id DatasetID, mark Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parts[id].mark = mark
+	min := Time(math.MaxInt64)
+	for _, p := range m.parts {
+		if p.mark < min {
+			min = p.mark
+		}
+	}
+	if min <= m.mark {
+		return nil
+	}
+	m.mark = min
+	return m.ts.UpdateWatermark(m.id, min)
+}
+
+func (m *partitionMerge) UpdateProcessingTime(id DatasetID, pt Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parts[id].processing = pt
+	min := Time(math.MaxInt64)
+	for _, p := range m.parts {
+		if p.processing < min {
+			min = p.processing
+		}
+	}
+	if min <= m.processing {
+		return nil
+	}
+	m.processing = min
+	return m.ts.UpdateProcessingTime(m.id, min)
+}
+
+func (m *partitionMerge) Finish(id DatasetID, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parts[id].finished = true
+	if err != nil && m.err == nil {
+		m.err = err
+	}
+	if m.done {
+		return
+	}
+	finished := true
+	for _, p := range m.parts {
+		finished = finished && p.finished
+	}
+	if finished || m.err != nil {
+		m.done = true
+		m.ts.Finish(m.id, m.err)
+	}
+}
@@ -0,0 +1,145 @@
+package execute_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/universe"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExecutor_Execute_Parallelism(t *testing.T) {
+	input := func() []*executetest.Table {
+		var input []*executetest.Table
+		for i := 0; i < 16; i++ {
+			tbl := &executetest.Table{
+				KeyCols: []string{"_start", "_stop", "host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_start", Type: flux.TTime},
+					{Label: "_stop", Type: flux.TTime},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "host", Type: flux.TString},
+				},
+			}
+			for j := 0; j < 10; j++ {
+				tbl.Data = append(tbl.Data, []interface{}{
+					execute.Time(0), execute.Time(100), execute.Time(j), float64(i * j), fmt.Sprintf("h%d", i),
+				})
+			}
+			input = append(input, tbl)
+		}
+		return input
+	}
+
+	// run runs the plan with the parallelism of the filter and the sum.
+	// The tables of the filter are merged before the sum when they
+	// are also yielded, and partitioned straight to the sum otherwise.
+	run := func(t *testing.T, filter, sum int, yieldFilter bool) []*executetest.Table {
+		nodes := []plan.Node{
+			plan.CreatePhysicalNode("from-test", executetest.NewFromProcedureSpec(input())),
+			plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{
+				Fn: interpreter.ResolvedFunction{
+					Fn:    executetest.FunctionExpression(t, "(r) => r._value > 10.0"),
+					Scope: runtime.Prelude(),
+				},
+			}),
+			plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+				AggregateConfig: execute.DefaultAggregateConfig,
+			}),
+			plan.CreatePhysicalNode("yield", executetest.NewYieldProcedureSpec("_result")),
+		}
+		edges := [][2]int{
+			{0, 1},
+			{1, 2},
+			{2, 3},
+		}
+		if yieldFilter {
+			nodes = append(nodes, plan.CreatePhysicalNode("yield-filter", executetest.NewYieldProcedureSpec("filter")))
+			edges = append(edges, [2]int{1, 4})
+		}
+		spec := plantest.CreatePlanSpec(&plantest.PlanSpec{
+			Nodes: nodes,
+			Edges: edges,
+			Resources: flux.ResourceManagement{
+				ConcurrencyQuota: 4,
+				MemoryBytesQuota: math.MaxInt64,
+			},
+			Now: time.Now(),
+		})
+		if err := spec.BottomUpWalk(func(node plan.Node) error {
+			switch node.ID() {
+			case "filter":
+				node.(*plan.PhysicalPlanNode).Parallelism = filter
+			case "sum":
+				node.(*plan.PhysicalPlanNode).Parallelism = sum
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		exe := execute.NewExecutor(zaptest.NewLogger(t))
+		ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+		results, _, err := exe.Execute(ctx, spec, executetest.UnlimitedAllocator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []*executetest.Table
+		if err := results["_result"].Tables().Do(func(tbl flux.Table) error {
+			cb, err := executetest.ConvertTable(tbl)
+			if err != nil {
+				return err
+			}
+			got = append(got, cb)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if r, ok := results["filter"]; ok {
+			if err := r.Tables().Do(func(tbl flux.Table) error {
+				tbl.Done()
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		executetest.NormalizeTables(got)
+		return got
+	}
+
+	want := run(t, 1, 1, false)
+	if len(want) != 14 {
+		t.Fatalf("unexpected number of tables: %d", len(want))
+	}
+	for _, tc := range []struct {
+		filter, sum int
+		yieldFilter bool
+	}{
+		{filter: 2, sum: 2},
+		{filter: 4, sum: 4},
+		{filter: 32, sum: 32},
+		{filter: 3, sum: 5},
+		{filter: 1, sum: 4},
+		{filter: 4, sum: 1},
+		{filter: 4, sum: 4, yieldFilter: true},
+	} {
+		tc := tc
+		t.Run(fmt.Sprintf("%d-%d-%t", tc.filter, tc.sum, tc.yieldFilter), func(t *testing.T) {
+			if got := run(t, tc.filter, tc.sum, tc.yieldFilter); !cmp.Equal(want, got) {
+				t.Errorf("unexpected results -want/+got:\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
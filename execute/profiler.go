@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/flux"
@@ -44,6 +46,17 @@ type OperatorProfilingResult struct {
 	Label string
 	Start time.Time
 	Stop  time.Time
+
+	// The remaining fields count the data that flowed through the operator
+	// over the whole execution. They are only set on the result that is
+	// reported for the operator once the query completes and are zero on
+	// the results reported by the spans.
+	TablesIn       int64
+	TablesOut      int64
+	RowsIn         int64
+	RowsOut        int64
+	BytesAllocated int64
+	MaxAllocated   int64
 }
 
 type OperatorProfilingSpan struct {
@@ -87,6 +100,13 @@ type operatorProfilingResultAggregate struct {
 	resultMax     int64
 	resultSum     int64
 	resultMean    float64
+
+	tablesIn       int64
+	tablesOut      int64
+	rowsIn         int64
+	rowsOut        int64
+	bytesAllocated int64
+	maxAllocated   int64
 }
 
 type operatorProfilerLabelGroup = map[string]*operatorProfilingResultAggregate
@@ -96,6 +116,139 @@ type OperatorProfiler struct {
 	// Receive the profiling results from the spans.
 	chIn  chan OperatorProfilingResult
 	chOut chan operatorProfilingResultAggregate
+
+	// The statistics of the operators that are executing.
+	// They are read once chIn is closed.
+	mu    sync.Mutex
+	stats []*operatorStats
+}
+
+// operatorStats counts the tables and rows that flow in and out
+// of an operator while it executes. The input is counted by the
+// transports and the output by a profilingOutput so operators do
+// not need to report them. Rows are counted as they are read so
+// a table that is never read only counts as a table.
+//
+// A nil *operatorStats ignores all updates.
+type operatorStats struct {
+	tablesIn  int64
+	tablesOut int64
+	rowsIn    int64
+	rowsOut   int64
+
+	typ   string
+	label string
+	// alloc is the allocator given to the operator.
+	// It forwards the allocations to the allocator of the query.
+	alloc *memory.Allocator
+}
+
+func (s *operatorStats) addTablesIn(n int64) {
+	if s != nil {
+		atomic.AddInt64(&s.tablesIn, n)
+	}
+}
+
+func (s *operatorStats) addTablesOut(n int64) {
+	if s != nil {
+		atomic.AddInt64(&s.tablesOut, n)
+	}
+}
+
+func (s *operatorStats) addRowsIn(n int64) {
+	if s != nil {
+		atomic.AddInt64(&s.rowsIn, n)
+	}
+}
+
+func (s *operatorStats) addRowsOut(n int64) {
+	if s != nil {
+		atomic.AddInt64(&s.rowsOut, n)
+	}
+}
+
+// result returns the profiling result with the current counts.
+func (s *operatorStats) result() OperatorProfilingResult {
+	r := OperatorProfilingResult{
+		Type:      s.typ,
+		Label:     s.label,
+		TablesIn:  atomic.LoadInt64(&s.tablesIn),
+		TablesOut: atomic.LoadInt64(&s.tablesOut),
+		RowsIn:    atomic.LoadInt64(&s.rowsIn),
+		RowsOut:   atomic.LoadInt64(&s.rowsOut),
+	}
+	if s.alloc != nil {
+		r.BytesAllocated = s.alloc.TotalAllocated()
+		r.MaxAllocated = s.alloc.MaxAllocated()
+	}
+	return r
+}
+
+// newOperatorStats registers an operator with the profiler.
+// The type is set once the operator has been created.
+// The allocator of the operator wraps the query allocator
+// so the memory used by the operator can be reported.
+func (o *OperatorProfiler) newOperatorStats(label string, alloc *memory.Allocator) *operatorStats {
+	s := &operatorStats{
+		label: label,
+		alloc: &memory.Allocator{},
+	}
+	if alloc != nil {
+		s.alloc.Allocator = alloc
+	}
+	o.mu.Lock()
+	o.stats = append(o.stats, s)
+	o.mu.Unlock()
+	return s
+}
+
+// profilingOutput sits between an operator and the transformations
+// that consume its tables. It counts the tables and rows the operator
+// produces once, however many transformations consume them.
+type profilingOutput struct {
+	ExecutionNode
+	stats *operatorStats
+	ts    TransformationSet
+}
+
+func (o *profilingOutput) AddTransformation(t Transformation) {
+	o.ts = append(o.ts, t)
+}
+
+func (o *profilingOutput) RetractTable(id DatasetID, key flux.GroupKey) error {
+	return o.ts.RetractTable(id, key)
+}
+
+func (o *profilingOutput) Process(id DatasetID, tbl flux.Table) error {
+	o.stats.addTablesOut(1)
+	return o.ts.Process(id, &profilingTable{Table: tbl, stats: o.stats})
+}
+
+func (o *profilingOutput) UpdateWatermark(id DatasetID, mark Time) error {
+	return o.ts.UpdateWatermark(id, mark)
+}
+
+func (o *profilingOutput) UpdateProcessingTime(id DatasetID, pt Time) error {
+	return o.ts.UpdateProcessingTime(id, pt)
+}
+
+func (o *profilingOutput) Finish(id DatasetID, err error) {
+	o.ts.Finish(id, err)
+}
+
+// profilingTable counts the rows of a table as they are read.
+// When there is more than one consumer, the table is copied
+// for them and so it is only read once.
+type profilingTable struct {
+	flux.Table
+	stats *operatorStats
+}
+
+func (t *profilingTable) Do(f func(flux.ColReader) error) error {
+	return t.Table.Do(func(cr flux.ColReader) error {
+		t.stats.addRowsOut(int64(cr.Len()))
+		return f(cr)
+	})
 }
 
 func createOperatorProfiler() Profiler {
@@ -105,7 +258,7 @@ func createOperatorProfiler() Profiler {
 	}
 	go func(p *OperatorProfiler) {
 		aggs := make(operatorProfilerTypeGroup)
-		for result := range p.chIn {
+		lookup := func(result OperatorProfilingResult) *operatorProfilingResultAggregate {
 			_, ok := aggs[result.Type]
 			if !ok {
 				aggs[result.Type] = make(operatorProfilerLabelGroup)
@@ -114,7 +267,10 @@ func createOperatorProfiler() Profiler {
 			if !ok {
 				aggs[result.Type][result.Label] = &operatorProfilingResultAggregate{}
 			}
-			a := aggs[result.Type][result.Label]
+			return aggs[result.Type][result.Label]
+		}
+		for result := range p.chIn {
+			a := lookup(result)
 
 			// Aggregate the results
 			a.resultCount++
@@ -128,11 +284,30 @@ func createOperatorProfiler() Profiler {
 			a.resultSum += duration
 		}
 
+		// The spans have all finished so the operators
+		// are done and their statistics are final.
+		p.mu.Lock()
+		for _, s := range p.stats {
+			result := s.result()
+			a := lookup(result)
+			a.tablesIn += result.TablesIn
+			a.tablesOut += result.TablesOut
+			a.rowsIn += result.RowsIn
+			a.rowsOut += result.RowsOut
+			a.bytesAllocated += result.BytesAllocated
+			if result.MaxAllocated > a.maxAllocated {
+				a.maxAllocated = result.MaxAllocated
+			}
+		}
+		p.mu.Unlock()
+
 		// Write the aggregated results to chOut, where they'll be
 		// converted into rows and appended to the final table
 		for typ, labels := range aggs {
 			for label, agg := range labels {
-				agg.resultMean = float64(agg.resultSum) / float64(agg.resultCount)
+				if agg.resultCount > 0 {
+					agg.resultMean = float64(agg.resultSum) / float64(agg.resultCount)
+				}
 				agg.operationType = typ
 				agg.label = label
 				p.chOut <- *agg
@@ -231,6 +406,30 @@ func (o *OperatorProfiler) getTableBuilder(alloc *memory.Allocator) (*ColListTab
 			Label: "MeanDuration",
 			Type:  flux.TFloat,
 		},
+		{
+			Label: "TablesIn",
+			Type:  flux.TInt,
+		},
+		{
+			Label: "TablesOut",
+			Type:  flux.TInt,
+		},
+		{
+			Label: "RowsIn",
+			Type:  flux.TInt,
+		},
+		{
+			Label: "RowsOut",
+			Type:  flux.TInt,
+		},
+		{
+			Label: "BytesAllocated",
+			Type:  flux.TInt,
+		},
+		{
+			Label: "MaxAllocated",
+			Type:  flux.TInt,
+		},
 	}
 	for _, col := range colMeta {
 		if _, err := b.AddCol(col); err != nil {
@@ -247,6 +446,12 @@ func (o *OperatorProfiler) getTableBuilder(alloc *memory.Allocator) (*ColListTab
 		b.AppendInt(5, agg.resultMax)
 		b.AppendInt(6, agg.resultSum)
 		b.AppendFloat(7, agg.resultMean)
+		b.AppendInt(8, agg.tablesIn)
+		b.AppendInt(9, agg.tablesOut)
+		b.AppendInt(10, agg.rowsIn)
+		b.AppendInt(11, agg.rowsOut)
+		b.AppendInt(12, agg.bytesAllocated)
+		b.AppendInt(13, agg.maxAllocated)
 	}
 	return b, nil
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"strings"
 	"sync"
 	"testing"
@@ -15,11 +16,17 @@ import (
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/metadata"
 	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/universe"
 	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap/zaptest"
 )
 
 // Simulates setting the profilers option in flux to "operator"
@@ -44,21 +51,21 @@ func TestOperatorProfiler_GetResult(t *testing.T) {
 	// Build the "want" table.
 	var wantStr bytes.Buffer
 	wantStr.WriteString(`
-#datatype,string,long,string,string,string,long,long,long,long,double
-#group,false,false,true,false,false,false,false,false,false,false
-#default,_profiler,,,,,,,,,
-,result,table,_measurement,Type,Label,Count,MinDuration,MaxDuration,DurationSum,MeanDuration
+#datatype,string,long,string,string,string,long,long,long,long,double,long,long,long,long,long,long
+#group,false,false,true,false,false,false,false,false,false,false,false,false,false,false,false,false
+#default,_profiler,,,,,,,,,,,,,,,
+,result,table,_measurement,Type,Label,Count,MinDuration,MaxDuration,DurationSum,MeanDuration,TablesIn,TablesOut,RowsIn,RowsOut,BytesAllocated,MaxAllocated
 `)
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,0,0,0,0,0,0\n",
 		"type0", "lab0", 4, 1000, 1606, 5212, 1303.0,
 	))
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,0,0,0,0,0,0\n",
 		"type1", "lab0", 4, 1101, 1707, 5616, 1404.0,
 	))
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,0,0,0,0,0,0\n",
 		"type0", "lab1", 4, 1808, 2414, 8444, 2111.0,
 	))
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,0,0,0,0,0,0\n",
 		"type1", "lab1", 4, 1909, 2515, 8848, 2212.0,
 	))
 	count := 16
@@ -142,3 +149,214 @@ func TestQueryProfiler_GetResult(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestOperatorProfiler_Stats(t *testing.T) {
+	deps := execute.DefaultExecutionDependencies()
+	ctx := deps.Inject(context.Background())
+	p := configureOperatorProfiler(ctx)
+
+	spec := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("from", executetest.NewFromProcedureSpec(
+				[]*executetest.Table{{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), 1.0},
+						{execute.Time(1), 2.0},
+						{execute.Time(2), 3.0},
+						{execute.Time(3), 4.0},
+						{execute.Time(4), 5.0},
+					},
+				}},
+			)),
+			plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{
+				Fn: interpreter.ResolvedFunction{
+					Fn:    executetest.FunctionExpression(t, "(r) => r._value < 2.5"),
+					Scope: runtime.Prelude(),
+				},
+			}),
+			plan.CreatePhysicalNode("yield", executetest.NewYieldProcedureSpec("_result")),
+			plan.CreatePhysicalNode("allocating", &executetest.AllocatingFromProcedureSpec{
+				ByteCount: 64,
+			}),
+			plan.CreatePhysicalNode("yield-alloc", executetest.NewYieldProcedureSpec("alloc")),
+		},
+		Edges: [][2]int{
+			{0, 1},
+			{1, 2},
+			{3, 4},
+		},
+		Resources: flux.ResourceManagement{
+			ConcurrencyQuota: 1,
+			MemoryBytesQuota: math.MaxInt64,
+		},
+		Now: time.Now(),
+	})
+
+	alloc := &memory.Allocator{}
+	results, metaCh, err := execute.NewExecutor(zaptest.NewLogger(t)).Execute(ctx, spec, alloc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if err := r.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Wait for the execution to finish.
+	for range metaCh {
+	}
+
+	got := readOperatorStats(t, p)
+
+	if want, got := (operatorStats{TablesOut: 1, RowsOut: 5}), got["from"]; want != got {
+		t.Errorf("unexpected stats for from -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+	if want, got := (operatorStats{BytesAllocated: 64, MaxAllocated: 64}), got["allocating"]; want != got {
+		t.Errorf("unexpected stats for allocating -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+	filter := got["filter"]
+	if want, got := (operatorStats{TablesIn: 1, TablesOut: 1, RowsIn: 5, RowsOut: 2}), (operatorStats{
+		TablesIn:  filter.TablesIn,
+		TablesOut: filter.TablesOut,
+		RowsIn:    filter.RowsIn,
+		RowsOut:   filter.RowsOut,
+	}); want != got {
+		t.Errorf("unexpected stats for filter -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+	if filter.BytesAllocated == 0 || filter.MaxAllocated == 0 {
+		t.Errorf("expected filter to allocate memory, got %+v", filter)
+	}
+	// The memory of the operators is accounted for in the query allocator.
+	if want, got := int64(64)+filter.BytesAllocated, alloc.TotalAllocated(); want != got {
+		t.Errorf("unexpected total allocated -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
+
+func TestOperatorProfiler_StatsMultipleConsumers(t *testing.T) {
+	deps := execute.DefaultExecutionDependencies()
+	ctx := deps.Inject(context.Background())
+	p := configureOperatorProfiler(ctx)
+
+	filter := func(fn string) *universe.FilterProcedureSpec {
+		return &universe.FilterProcedureSpec{
+			Fn: interpreter.ResolvedFunction{
+				Fn:    executetest.FunctionExpression(t, fn),
+				Scope: runtime.Prelude(),
+			},
+		}
+	}
+	spec := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("from", executetest.NewFromProcedureSpec(
+				[]*executetest.Table{{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), 1.0},
+						{execute.Time(1), 2.0},
+						{execute.Time(2), 3.0},
+						{execute.Time(3), 4.0},
+						{execute.Time(4), 5.0},
+					},
+				}},
+			)),
+			plan.CreatePhysicalNode("lt", filter("(r) => r._value < 2.5")),
+			plan.CreatePhysicalNode("yield-lt", executetest.NewYieldProcedureSpec("lt")),
+			plan.CreatePhysicalNode("gt", filter("(r) => r._value > 2.5")),
+			plan.CreatePhysicalNode("yield-gt", executetest.NewYieldProcedureSpec("gt")),
+		},
+		Edges: [][2]int{
+			{0, 1},
+			{1, 2},
+			{0, 3},
+			{3, 4},
+		},
+		Resources: flux.ResourceManagement{
+			ConcurrencyQuota: 1,
+			MemoryBytesQuota: math.MaxInt64,
+		},
+		Now: time.Now(),
+	})
+
+	results, metaCh, err := execute.NewExecutor(zaptest.NewLogger(t)).Execute(ctx, spec, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if err := r.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range metaCh {
+	}
+
+	got := readOperatorStats(t, p)
+	// The table of from is read by both filters
+	// but it is only output once.
+	if want, got := (operatorStats{TablesOut: 1, RowsOut: 5}), got["from"]; want != got {
+		t.Errorf("unexpected stats for from -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+	for name, rows := range map[string]int64{"lt": 2, "gt": 3} {
+		s := got[name]
+		if want, got := (operatorStats{TablesIn: 1, TablesOut: 1, RowsIn: 5, RowsOut: rows}), (operatorStats{
+			TablesIn:  s.TablesIn,
+			TablesOut: s.TablesOut,
+			RowsIn:    s.RowsIn,
+			RowsOut:   s.RowsOut,
+		}); want != got {
+			t.Errorf("unexpected stats for %s -want/+got:\n\t- %+v\n\t+ %+v", name, want, got)
+		}
+	}
+}
+
+type operatorStats struct {
+	TablesIn, TablesOut, RowsIn, RowsOut int64
+	BytesAllocated, MaxAllocated         int64
+}
+
+// readOperatorStats reads the statistics of each operator
+// from the result of the operator profiler.
+func readOperatorStats(t *testing.T, p *execute.OperatorProfiler) map[string]operatorStats {
+	t.Helper()
+	tbl, err := p.GetResult(nil, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := executetest.ConvertTable(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]operatorStats)
+	for _, row := range profile.Data {
+		s := operatorStats{}
+		for j, col := range profile.ColMeta {
+			switch col.Label {
+			case "TablesIn":
+				s.TablesIn = row[j].(int64)
+			case "TablesOut":
+				s.TablesOut = row[j].(int64)
+			case "RowsIn":
+				s.RowsIn = row[j].(int64)
+			case "RowsOut":
+				s.RowsOut = row[j].(int64)
+			case "BytesAllocated":
+				s.BytesAllocated = row[j].(int64)
+			case "MaxAllocated":
+				s.MaxAllocated = row[j].(int64)
+			}
+		}
+		got[row[execute.ColIdx("Label", profile.ColMeta)].(string)] = s
+	}
+	return got
+}
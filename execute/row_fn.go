@@ -79,6 +79,17 @@ func (f *dynamicFn) prepare(cols []flux.ColMeta, extraTypes map[string]semantic.
 	}, nil
 }
 
+// vectorize compiles the function to evaluate every row of a table
+// with the columns at once. It returns nil if the function uses
+// features that are not supported by the vectorized compiler.
+func (f *dynamicFn) vectorize(cols []flux.ColMeta) compiler.VectorizedFunc {
+	fn, err := compiler.CompileVectorized(f.scope, f.fn, cols)
+	if err != nil {
+		return nil
+	}
+	return fn
+}
+
 type preparedFn struct {
 	fn         compiler.Func
 	recordName string
@@ -183,6 +194,14 @@ func (f *TablePredicatePreparedFn) Eval(ctx context.Context, tbl flux.Table) (bo
 
 type rowFn struct {
 	preparedFn
+	vectorized compiler.VectorizedFunc
+}
+
+// Vectorized returns the function compiled to evaluate every row
+// of a flux.ColReader at once. It returns nil if the function
+// can only be evaluated one row at a time.
+func (f *rowFn) Vectorized() compiler.VectorizedFunc {
+	return f.vectorized
 }
 
 func (f *rowFn) eval(ctx context.Context, row int, cr flux.ColReader, extraParams map[string]values.Value) (values.Value, error) {
@@ -212,7 +231,10 @@ func (f *RowPredicateFn) Prepare(cols []flux.ColMeta) (*RowPredicatePreparedFn,
 		return nil, errors.New(codes.Invalid, "row predicate function does not evaluate to a boolean")
 	}
 	return &RowPredicatePreparedFn{
-		rowFn: rowFn{preparedFn: fn},
+		rowFn: rowFn{
+			preparedFn: fn,
+			vectorized: f.vectorize(cols),
+		},
 	}, nil
 }
 
@@ -269,7 +291,10 @@ func (f *RowMapFn) Prepare(cols []flux.ColMeta) (*RowMapPreparedFn, error) {
 		return nil, errors.Newf(codes.Invalid, "map function must return an object, got %s", k.String())
 	}
 	return &RowMapPreparedFn{
-		rowFn: rowFn{preparedFn: fn},
+		rowFn: rowFn{
+			preparedFn: fn,
+			vectorized: f.vectorize(cols),
+		},
 	}, nil
 }
 
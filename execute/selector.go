@@ -27,6 +27,14 @@ var DefaultSelectorConfig = SelectorConfig{
 	Column: DefaultValueColLabel,
 }
 
+// Cost estimates a selector as producing a single row for each table.
+func (c SelectorConfig) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	stats := plan.CombineStatistics(inStats)
+	cost := plan.Cost{CPU: stats.Cardinality}
+	stats.Cardinality = stats.GroupCardinality
+	return cost, stats
+}
+
 func (c *SelectorConfig) ReadArgs(args flux.Arguments) error {
 	if col, ok, err := args.GetString("column"); err != nil {
 		return err
@@ -0,0 +1,537 @@
+package execute
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"io"
+	"sync/atomic"
+
+	arrowlib "github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/scratch"
+	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/arrowutil"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+)
+
+// Spiller moves data buffered by a transformation into scratch space
+// once the memory used by a query passes a threshold.
+// Blocking transformations use it so that large queries finish
+// more slowly instead of exceeding the memory limit.
+//
+// A nil Spiller is valid and never spills.
+type Spiller struct {
+	fs        scratch.Service
+	mem       *memory.Allocator
+	threshold int64
+}
+
+// NewSpiller constructs a Spiller that creates files with the scratch
+// service once the allocator has at least threshold bytes allocated.
+func NewSpiller(fs scratch.Service, mem *memory.Allocator, threshold int64) *Spiller {
+	return &Spiller{
+		fs:        fs,
+		mem:       mem,
+		threshold: threshold,
+	}
+}
+
+// SpillerFromContext constructs a Spiller with the scratch service from the context.
+// Data is spilled once half of the memory limit of the allocator is in use.
+// If there is no scratch service or the allocator has no limit,
+// this returns nil and nothing is spilled.
+func SpillerFromContext(ctx context.Context, mem *memory.Allocator) *Spiller {
+	if mem == nil || mem.Limit == nil {
+		return nil
+	}
+	fs, err := scratch.Get(ctx)
+	if err != nil {
+		return nil
+	}
+	return NewSpiller(fs, mem, *mem.Limit/2)
+}
+
+// ShouldSpill reports whether the memory in use has passed the threshold
+// and buffered data should be moved into scratch space.
+func (s *Spiller) ShouldSpill() bool {
+	return s != nil && s.mem.Allocated() >= s.threshold
+}
+
+// Create creates a new SpillFile for buffers with the given key and columns.
+func (s *Spiller) Create(key flux.GroupKey, cols []flux.ColMeta) (*SpillFile, error) {
+	f, err := s.fs.Create()
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "could not create spill file")
+	}
+
+	fields := make([]arrowlib.Field, len(cols))
+	for j, c := range cols {
+		fields[j] = arrowlib.Field{
+			Name:     c.Label,
+			Type:     arrow.DataType(c.Type),
+			Nullable: true,
+		}
+	}
+	schema := arrowlib.NewSchema(fields, nil)
+	bw := bufio.NewWriter(f)
+	return &SpillFile{
+		key:    key,
+		cols:   cols,
+		schema: schema,
+		mem:    s.mem,
+		f:      f,
+		bw:     bw,
+		w:      ipc.NewWriter(bw, ipc.WithSchema(schema), ipc.WithAllocator(s.mem)),
+	}, nil
+}
+
+// SpillTable writes the table into scratch space and returns
+// a table that will read it back.
+func (s *Spiller) SpillTable(tbl flux.Table) (flux.Table, error) {
+	f, err := s.Create(tbl.Key(), tbl.Cols())
+	if err != nil {
+		tbl.Done()
+		return nil, err
+	}
+	if err := tbl.Do(f.Write); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f.Table()
+}
+
+// SpillFile holds the buffers of a single table in scratch space.
+// The buffers are written to the file in order and read back
+// in the same order once writing has finished.
+type SpillFile struct {
+	key    flux.GroupKey
+	cols   []flux.ColMeta
+	schema *arrowlib.Schema
+	mem    *memory.Allocator
+
+	f  scratch.File
+	bw *bufio.Writer
+	w  *ipc.Writer
+	n  int
+}
+
+func (f *SpillFile) Key() flux.GroupKey {
+	return f.key
+}
+
+func (f *SpillFile) Cols() []flux.ColMeta {
+	return f.cols
+}
+
+// Len returns the number of rows that have been written to the file.
+func (f *SpillFile) Len() int {
+	return f.n
+}
+
+// Write appends the rows in the column reader to the file.
+// The column reader must have the columns the file was created with.
+func (f *SpillFile) Write(cr flux.ColReader) error {
+	if cr.Len() == 0 {
+		return nil
+	}
+
+	arrs := make([]array.Interface, len(f.cols))
+	for j, c := range f.cols {
+		// The ipc writer does not encode sliced arrays correctly.
+		arr := arrowutil.Compact(table.Values(cr, j), f.mem)
+		defer arr.Release()
+		if c.Type == flux.TString {
+			// Flux stores strings in binary arrays, but the
+			// ipc writer expects a string array for the schema.
+			arr = array.NewStringData(arr.Data())
+			defer arr.Release()
+		}
+		arrs[j] = arr
+	}
+	rec := array.NewRecord(f.schema, arrs, int64(cr.Len()))
+	defer rec.Release()
+
+	if err := f.w.Write(rec); err != nil {
+		return errors.Wrap(err, codes.Internal, "could not write to spill file")
+	}
+	f.n += cr.Len()
+	return nil
+}
+
+// finish flushes everything written to the file
+// and rewinds it so it can be read.
+func (f *SpillFile) finish() error {
+	if err := f.w.Close(); err != nil {
+		return errors.Wrap(err, codes.Internal, "could not write to spill file")
+	}
+	if err := f.bw.Flush(); err != nil {
+		return errors.Wrap(err, codes.Internal, "could not write to spill file")
+	}
+	if _, err := f.f.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, codes.Internal, "could not read spill file")
+	}
+	return nil
+}
+
+// Table finishes writing to the file and returns a table that reads
+// the rows back one buffer at a time. The file is closed and removed
+// once the table has been read or discarded with Done.
+// Nothing may be written to the file after this is called.
+func (f *SpillFile) Table() (flux.Table, error) {
+	if err := f.finish(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &spillTable{file: f}, nil
+}
+
+// Close closes the file and removes any data that was written to it.
+func (f *SpillFile) Close() error {
+	return f.f.Close()
+}
+
+// reader returns an iterator over the buffers that were written to the file.
+// The file must have been finished before this is called.
+func (f *SpillFile) reader() (*spillReader, error) {
+	r, err := ipc.NewReader(bufio.NewReader(f.f), ipc.WithSchema(f.schema), ipc.WithAllocator(f.mem))
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "could not read spill file")
+	}
+	return &spillReader{file: f, r: r}, nil
+}
+
+type spillReader struct {
+	file *SpillFile
+	r    *ipc.Reader
+}
+
+// Next reads the next buffer from the file. It returns nil when
+// there are no buffers remaining. The caller is responsible for
+// releasing the returned buffer.
+func (r *spillReader) Next() (*arrow.TableBuffer, error) {
+	if !r.r.Next() {
+		if err := r.r.Err(); err != nil {
+			return nil, errors.Wrap(err, codes.Internal, "could not read spill file")
+		}
+		return nil, nil
+	}
+
+	rec := r.r.Record()
+	buf := &arrow.TableBuffer{
+		GroupKey: r.file.key,
+		Columns:  r.file.cols,
+		Values:   make([]array.Interface, len(r.file.cols)),
+	}
+	for j, c := range r.file.cols {
+		col := rec.Column(j)
+		if c.Type == flux.TString {
+			// The ipc reader produces string arrays, but flux
+			// reads string columns as binary arrays.
+			buf.Values[j] = array.NewBinaryData(col.Data())
+			continue
+		}
+		col.Retain()
+		buf.Values[j] = col
+	}
+	return buf, nil
+}
+
+func (r *spillReader) Release() {
+	r.r.Release()
+}
+
+// spillTable is a flux.Table that reads its buffers from a SpillFile.
+type spillTable struct {
+	file *SpillFile
+	used int32
+}
+
+func (t *spillTable) Key() flux.GroupKey {
+	return t.file.key
+}
+
+func (t *spillTable) Cols() []flux.ColMeta {
+	return t.file.cols
+}
+
+func (t *spillTable) Empty() bool {
+	return t.file.n == 0
+}
+
+func (t *spillTable) Do(f func(flux.ColReader) error) error {
+	if !atomic.CompareAndSwapInt32(&t.used, 0, 1) {
+		return errors.New(codes.Internal, "table already read")
+	}
+	defer func() { _ = t.file.Close() }()
+
+	r, err := t.file.reader()
+	if err != nil {
+		return err
+	}
+	defer r.Release()
+
+	for {
+		buf, err := r.Next()
+		if err != nil {
+			return err
+		} else if buf == nil {
+			return nil
+		}
+
+		err = f(buf)
+		buf.Release()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (t *spillTable) Done() {
+	if atomic.CompareAndSwapInt32(&t.used, 0, 1) {
+		_ = t.file.Close()
+	}
+}
+
+// MergeSpillFiles returns a table that merges the rows of spill files
+// that were each sorted by the given columns into a single sorted table.
+// Rows are compared the same way as ColListTableBuilder.Sort so null
+// values come first regardless of the sort direction.
+//
+// All of the files must have the same group key and columns.
+// The files are closed once the returned table has been read
+// or discarded with Done.
+func MergeSpillFiles(files []*SpillFile, cols []string, desc bool) (flux.Table, error) {
+	for _, f := range files {
+		if err := f.finish(); err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			return nil, err
+		}
+	}
+
+	first := files[0]
+	sortCols := make([]int, 0, len(cols))
+	for _, label := range cols {
+		if j := ColIdx(label, first.cols); j >= 0 {
+			sortCols = append(sortCols, j)
+		}
+	}
+	n := 0
+	for _, f := range files {
+		n += f.n
+	}
+	return &mergeSpillTable{
+		files:    files,
+		sortCols: sortCols,
+		desc:     desc,
+		n:        n,
+	}, nil
+}
+
+type mergeSpillTable struct {
+	files    []*SpillFile
+	sortCols []int
+	desc     bool
+	n        int
+	used     int32
+}
+
+func (t *mergeSpillTable) Key() flux.GroupKey {
+	return t.files[0].key
+}
+
+func (t *mergeSpillTable) Cols() []flux.ColMeta {
+	return t.files[0].cols
+}
+
+func (t *mergeSpillTable) Empty() bool {
+	return t.n == 0
+}
+
+func (t *mergeSpillTable) Done() {
+	if atomic.CompareAndSwapInt32(&t.used, 0, 1) {
+		t.close()
+	}
+}
+
+func (t *mergeSpillTable) close() {
+	for _, f := range t.files {
+		_ = f.Close()
+	}
+}
+
+func (t *mergeSpillTable) Do(f func(flux.ColReader) error) error {
+	if !atomic.CompareAndSwapInt32(&t.used, 0, 1) {
+		return errors.New(codes.Internal, "table already read")
+	}
+	defer t.close()
+
+	mh := &spillMergeHeap{
+		sortCols: t.sortCols,
+		desc:     t.desc,
+	}
+	defer mh.release()
+
+	for _, file := range t.files {
+		r, err := file.reader()
+		if err != nil {
+			return err
+		}
+		item := &spillMergeHeapItem{r: r}
+		if ok, err := item.Next(); err != nil {
+			r.Release()
+			return err
+		} else if !ok {
+			r.Release()
+			continue
+		}
+		mh.items = append(mh.items, item)
+	}
+	heap.Init(mh)
+
+	key, cols, mem := t.Key(), t.Cols(), t.files[0].mem
+	builders := make([]array.Builder, len(cols))
+	for j, c := range cols {
+		builders[j] = arrow.NewBuilder(c.Type, mem)
+	}
+	defer func() {
+		for _, b := range builders {
+			b.Release()
+		}
+	}()
+
+	for remaining := t.n; remaining > 0; {
+		size := remaining
+		if size > table.BufferSize {
+			size = table.BufferSize
+		}
+		for _, b := range builders {
+			b.Resize(size)
+		}
+		for i := 0; i < size; i++ {
+			item := mh.items[0]
+			for j, b := range builders {
+				arrowutil.CopyValue(b, item.buf.Values[j], item.i)
+			}
+
+			if ok, err := item.Next(); err != nil {
+				return err
+			} else if ok {
+				heap.Fix(mh, 0)
+			} else {
+				item.Release()
+				heap.Pop(mh)
+			}
+		}
+		remaining -= size
+
+		buf := &arrow.TableBuffer{
+			GroupKey: key,
+			Columns:  cols,
+			Values:   make([]array.Interface, len(cols)),
+		}
+		for j, b := range builders {
+			buf.Values[j] = b.NewArray()
+		}
+		err := f(buf)
+		buf.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type spillMergeHeapItem struct {
+	r   *spillReader
+	buf *arrow.TableBuffer
+	i   int
+}
+
+// Next moves to the next row of the spill file.
+// It returns false once there are no rows remaining.
+func (s *spillMergeHeapItem) Next() (bool, error) {
+	s.i++
+	if s.buf != nil && s.i < s.buf.Len() {
+		return true, nil
+	}
+	for {
+		if s.buf != nil {
+			s.buf.Release()
+			s.buf = nil
+		}
+		buf, err := s.r.Next()
+		if err != nil || buf == nil {
+			return false, err
+		}
+		s.buf, s.i = buf, 0
+		if buf.Len() > 0 {
+			return true, nil
+		}
+	}
+}
+
+func (s *spillMergeHeapItem) Release() {
+	if s.buf != nil {
+		s.buf.Release()
+		s.buf = nil
+	}
+	if s.r != nil {
+		s.r.Release()
+		s.r = nil
+	}
+}
+
+type spillMergeHeap struct {
+	items    []*spillMergeHeapItem
+	sortCols []int
+	desc     bool
+}
+
+func (s *spillMergeHeap) Len() int {
+	return len(s.items)
+}
+
+func (s *spillMergeHeap) Less(i, j int) bool {
+	x, y := s.items[i], s.items[j]
+	for _, col := range s.sortCols {
+		left, right := x.buf.Values[col], y.buf.Values[col]
+		if cmp := arrowutil.Compare(left, right, x.i, y.i); cmp != 0 {
+			// Null values are less than everything else
+			// in both ascending and descending order.
+			if s.desc && left.IsValid(x.i) && right.IsValid(y.i) {
+				cmp = -cmp
+			}
+			return cmp < 0
+		}
+	}
+	return false
+}
+
+func (s *spillMergeHeap) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+}
+
+func (s *spillMergeHeap) Push(x interface{}) {
+	s.items = append(s.items, x.(*spillMergeHeapItem))
+}
+
+func (s *spillMergeHeap) Pop() interface{} {
+	item := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return item
+}
+
+func (s *spillMergeHeap) release() {
+	for _, item := range s.items {
+		item.Release()
+	}
+	s.items = nil
+}
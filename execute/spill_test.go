@@ -0,0 +1,369 @@
+package execute_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/scratch"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+)
+
+func newTestSpiller(t *testing.T, mem *memory.Allocator) (*execute.Spiller, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "flux-spill-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A threshold of zero spills on every opportunity.
+	return execute.NewSpiller(scratch.Dir(dir), mem, 0), func() {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 0 {
+			t.Errorf("expected spill files to be removed, found %d files", len(files))
+		}
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestSpiller_SpillTable(t *testing.T) {
+	mem := &memory.Allocator{}
+	spiller, cleanup := newTestSpiller(t, mem)
+	defer cleanup()
+
+	in := &executetest.Table{
+		KeyCols: []string{"t0"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "t0", Type: flux.TString},
+			{Label: "n", Type: flux.TInt},
+			{Label: "u", Type: flux.TUInt},
+			{Label: "b", Type: flux.TBool},
+		},
+		Data: [][]interface{}{
+			{execute.Time(1), 2.0, "a", int64(1), uint64(1), true},
+			{execute.Time(2), nil, "a", nil, uint64(2), nil},
+			{execute.Time(3), 4.0, "a", int64(3), nil, false},
+		},
+	}
+	want, err := executetest.ConvertTable(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl, err := spiller.SpillTable(&executetest.RowWiseTable{Table: in})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := executetest.ConvertTable(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected table -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	if got := mem.Allocated(); got != 0 {
+		t.Errorf("expected all memory to be released, got %d bytes", got)
+	}
+}
+
+func TestMergeSpillFiles(t *testing.T) {
+	runs := [][][]interface{}{
+		{
+			{execute.Time(1), nil},
+			{execute.Time(2), 1.0},
+			{execute.Time(3), 4.0},
+		},
+		{
+			{execute.Time(4), 2.0},
+			{execute.Time(5), 3.0},
+		},
+		{
+			{execute.Time(6), nil},
+			{execute.Time(7), 0.5},
+		},
+	}
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+	}
+
+	for _, tc := range []struct {
+		name string
+		desc bool
+		want [][]interface{}
+	}{
+		{
+			name: "ascending",
+			want: [][]interface{}{
+				{execute.Time(1), nil},
+				{execute.Time(6), nil},
+				{execute.Time(7), 0.5},
+				{execute.Time(2), 1.0},
+				{execute.Time(4), 2.0},
+				{execute.Time(5), 3.0},
+				{execute.Time(3), 4.0},
+			},
+		},
+		{
+			name: "descending",
+			desc: true,
+			want: [][]interface{}{
+				{execute.Time(1), nil},
+				{execute.Time(6), nil},
+				{execute.Time(3), 4.0},
+				{execute.Time(5), 3.0},
+				{execute.Time(4), 2.0},
+				{execute.Time(2), 1.0},
+				{execute.Time(7), 0.5},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mem := &memory.Allocator{}
+			spiller, cleanup := newTestSpiller(t, mem)
+			defer cleanup()
+
+			files := make([]*execute.SpillFile, 0, len(runs))
+			for _, data := range runs {
+				in := &executetest.Table{ColMeta: cols, Data: data}
+				tbl, err := executetest.ConvertTable(in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				builder := execute.NewColListTableBuilder(tbl.Key(), mem)
+				if err := execute.AddTableCols(tbl, builder); err != nil {
+					t.Fatal(err)
+				}
+				if err := execute.AppendTable(tbl, builder); err != nil {
+					t.Fatal(err)
+				}
+				builder.Sort([]string{"_value"}, tc.desc)
+				sorted, err := builder.Table()
+				if err != nil {
+					t.Fatal(err)
+				}
+				builder.Release()
+
+				f, err := spiller.Create(sorted.Key(), sorted.Cols())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := sorted.Do(f.Write); err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, f)
+			}
+
+			out, err := execute.MergeSpillFiles(files, []string{"_value"}, tc.desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := executetest.ConvertTable(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := executetest.ConvertTable(&executetest.Table{ColMeta: cols, Data: tc.want})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(want, got) {
+				t.Errorf("unexpected table -want/+got:\n%s", cmp.Diff(want, got))
+			}
+			if got := mem.Allocated(); got != 0 {
+				t.Errorf("expected all memory to be released, got %d bytes", got)
+			}
+		})
+	}
+}
+
+func TestTableBuilderCache_Spill(t *testing.T) {
+	mem := &memory.Allocator{}
+	spiller, cleanup := newTestSpiller(t, mem)
+	defer cleanup()
+
+	cache := execute.NewTableBuilderCache(mem)
+	cache.SetTriggerSpec(plan.DefaultTriggerSpec)
+	cache.EnableSpilling(spiller)
+
+	key := execute.NewGroupKey(nil, nil)
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TInt},
+	}
+	for i := 0; i < 3; i++ {
+		builder, _ := cache.TableBuilder(key)
+		if i == 0 {
+			for _, c := range cols {
+				if _, err := builder.AddCol(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := builder.AppendTime(0, execute.Time(i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := builder.AppendInt(1, int64(i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Spill(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tbl, err := cache.Table(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := executetest.ConvertTable(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := executetest.ConvertTable(&executetest.Table{
+		ColMeta: cols,
+		Data: [][]interface{}{
+			{execute.Time(0), int64(0)},
+			{execute.Time(1), int64(1)},
+			{execute.Time(2), int64(2)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected table -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	cache.DiscardTable(key)
+	if got := mem.Allocated(); got != 0 {
+		t.Errorf("expected all memory to be released, got %d bytes", got)
+	}
+}
+
+// unreadableScratch is a scratch service whose files cannot be read back.
+type unreadableScratch struct {
+	scratch.Service
+}
+
+func (s unreadableScratch) Create() (scratch.File, error) {
+	f, err := s.Service.Create()
+	if err != nil {
+		return nil, err
+	}
+	return unreadableFile{File: f}, nil
+}
+
+type unreadableFile struct {
+	scratch.File
+}
+
+func (f unreadableFile) Read(p []byte) (int, error) {
+	return 0, errors.New("disk failure")
+}
+
+// appendSpilledRow appends a row to the table builder for the key
+// and spills the contents of the cache.
+func appendSpilledRow(t *testing.T, cache execute.SpillingTableBuilderCache, key flux.GroupKey, v int64) {
+	t.Helper()
+	builder, created, err := cache.RestoreTableBuilder(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		if _, err := builder.AddCol(flux.ColMeta{Label: "_value", Type: flux.TInt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := builder.AppendInt(0, v); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Spill(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTableBuilderCache_SpillAfterTable(t *testing.T) {
+	mem := &memory.Allocator{}
+	spiller, cleanup := newTestSpiller(t, mem)
+	defer cleanup()
+
+	cache := execute.NewTableBuilderCache(mem)
+	cache.SetTriggerSpec(plan.DefaultTriggerSpec)
+	cache.EnableSpilling(spiller)
+
+	key := execute.NewGroupKey(nil, nil)
+	appendSpilledRow(t, cache, key, 1)
+
+	// The spilled table is handed out, so the builder
+	// must not try to read it back again.
+	tbl, err := cache.Table(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executetest.ConvertTable(tbl); err != nil {
+		t.Fatal(err)
+	}
+	cache.DiscardTable(key)
+	appendSpilledRow(t, cache, key, 2)
+
+	tbl, err = cache.Table(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := executetest.ConvertTable(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := executetest.ConvertTable(&executetest.Table{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TInt}},
+		Data:    [][]interface{}{{int64(2)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected table -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	cache.ExpireTable(key)
+	if got := mem.Allocated(); got != 0 {
+		t.Errorf("expected all memory to be released, got %d bytes", got)
+	}
+}
+
+func TestTableBuilderCache_RestoreError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-spill-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	mem := &memory.Allocator{}
+	spiller := execute.NewSpiller(unreadableScratch{Service: scratch.Dir(dir)}, mem, 0)
+	cache := execute.NewTableBuilderCache(mem)
+	cache.SetTriggerSpec(plan.DefaultTriggerSpec)
+	cache.EnableSpilling(spiller)
+
+	key := execute.NewGroupKey(nil, nil)
+	appendSpilledRow(t, cache, key, 1)
+
+	if _, _, err := cache.RestoreTableBuilder(key); err == nil {
+		t.Fatal("expected an error restoring the spilled table")
+	}
+	// The error is also reported when the table is retrieved.
+	if _, err := cache.Table(key); err == nil {
+		t.Fatal("expected an error retrieving the table")
+	}
+	cache.ExpireTable(key)
+}
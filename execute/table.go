@@ -2014,9 +2014,34 @@ type TableBuilderCache interface {
 	ForEachBuilder(f func(flux.GroupKey, TableBuilder))
 }
 
+// SpillingTableBuilderCache is a TableBuilderCache that can move
+// the tables it holds into scratch space.
+type SpillingTableBuilderCache interface {
+	TableBuilderCache
+
+	// EnableSpilling allows the cache to spill using the Spiller.
+	// A nil Spiller disables spilling.
+	EnableSpilling(s *Spiller)
+
+	// Spill moves the contents of the table builders into scratch
+	// space if memory is running low. Callers must not hold on to
+	// a TableBuilder across a call to Spill.
+	Spill() error
+
+	// RestoreTableBuilder is like TableBuilder, but it returns an error
+	// if a builder that has been spilled cannot be read back.
+	RestoreTableBuilder(key flux.GroupKey) (TableBuilder, bool, error)
+
+	// ForEachRestoredBuilder is like ForEachBuilder, but it stops and
+	// returns an error if a builder that has been spilled cannot be
+	// read back or if f returns an error.
+	ForEachRestoredBuilder(f func(flux.GroupKey, TableBuilder) error) error
+}
+
 type tableBuilderCache struct {
-	tables *GroupLookup
-	alloc  *memory.Allocator
+	tables  *GroupLookup
+	alloc   *memory.Allocator
+	spiller *Spiller
 
 	triggerSpec plan.TriggerSpec
 }
@@ -2031,6 +2056,77 @@ func NewTableBuilderCache(a *memory.Allocator) *tableBuilderCache {
 type tableState struct {
 	builder TableBuilder
 	trigger Trigger
+
+	// spilled holds the contents of the builder after
+	// they have been moved into scratch space.
+	spilled     flux.Table
+	spilledRows int
+	// err is the error from reading a spilled table back.
+	// It is reported when the table is retrieved.
+	err error
+}
+
+// EnableSpilling allows the cache to move the tables it holds
+// into scratch space when the Spiller reports that memory is
+// running low. Transformations that enable spilling must call
+// Spill at a point where they do not hold on to any TableBuilder.
+func (d *tableBuilderCache) EnableSpilling(s *Spiller) {
+	d.spiller = s
+}
+
+// Spill moves the contents of every table builder into scratch
+// space if the Spiller reports that memory is running low.
+// A builder that has been spilled is restored the next time
+// it is retrieved with TableBuilder.
+func (d *tableBuilderCache) Spill() error {
+	if !d.spiller.ShouldSpill() {
+		return nil
+	}
+
+	var (
+		keys   []flux.GroupKey
+		states []tableState
+	)
+	d.tables.Range(func(key flux.GroupKey, value interface{}) {
+		b := value.(tableState)
+		if b.spilled == nil && b.builder.NRows() > 0 {
+			keys = append(keys, key)
+			states = append(states, b)
+		}
+	})
+
+	for i, b := range states {
+		tbl, err := b.builder.Table()
+		if err != nil {
+			return err
+		}
+		b.spilledRows = b.builder.NRows()
+		b.builder.Release()
+		if b.spilled, err = d.spiller.SpillTable(tbl); err != nil {
+			return err
+		}
+		d.tables.Set(keys[i], b)
+	}
+	return nil
+}
+
+// restore reads a spilled table back into a new table builder.
+// If the table cannot be read, the builder is left empty and the
+// error is kept so that it is reported when the table is retrieved.
+func (d *tableBuilderCache) restore(key flux.GroupKey, b tableState) (tableState, error) {
+	builder := NewColListTableBuilder(key, d.alloc)
+	err := AddTableCols(b.spilled, builder)
+	if err == nil {
+		err = AppendTable(b.spilled, builder)
+	}
+	b.spilled.Done()
+	b.builder, b.spilled, b.spilledRows = builder, nil, 0
+	if err != nil {
+		b.err = errors.Wrap(err, codes.Internal, "could not restore spilled table")
+		builder.ClearData()
+	}
+	d.tables.Set(key, b)
+	return b, b.err
 }
 
 func (d *tableBuilderCache) SetTriggerSpec(ts plan.TriggerSpec) {
@@ -2042,6 +2138,21 @@ func (d *tableBuilderCache) Table(key flux.GroupKey) (flux.Table, error) {
 	if !ok {
 		return nil, fmt.Errorf("table not found with key %v", key)
 	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.spilled != nil {
+		// The spilled table can only be read once, so it is
+		// replaced with an empty builder once it is handed out.
+		tbl := b.spilled
+		b.builder = NewColListTableBuilder(key, d.alloc)
+		if err := AddTableCols(tbl, b.builder); err != nil {
+			return nil, err
+		}
+		b.spilled, b.spilledRows = nil, 0
+		d.tables.Set(key, b)
+		return tbl, nil
+	}
 	return b.builder.Table()
 }
 
@@ -2055,7 +2166,18 @@ func (d *tableBuilderCache) lookupState(key flux.GroupKey) (tableState, bool) {
 
 // TableBuilder will return the builder for the specified table.
 // If no builder exists, one will be created.
+//
+// If the builder has been spilled and cannot be read back, an empty
+// builder is returned and the error is reported by Table.
 func (d *tableBuilderCache) TableBuilder(key flux.GroupKey) (TableBuilder, bool) {
+	builder, created, _ := d.RestoreTableBuilder(key)
+	return builder, created
+}
+
+// RestoreTableBuilder will return the builder for the specified table.
+// If no builder exists, one will be created. It returns an error if
+// the builder has been spilled and cannot be read back.
+func (d *tableBuilderCache) RestoreTableBuilder(key flux.GroupKey) (TableBuilder, bool, error) {
 	b, ok := d.lookupState(key)
 	if !ok {
 		builder := NewColListTableBuilder(key, d.alloc)
@@ -2065,19 +2187,51 @@ func (d *tableBuilderCache) TableBuilder(key flux.GroupKey) (TableBuilder, bool)
 			trigger: t,
 		}
 		d.tables.Set(key, b)
+	} else if b.spilled != nil {
+		var err error
+		if b, err = d.restore(key, b); err != nil {
+			return b.builder, false, err
+		}
 	}
-	return b.builder, !ok
+	return b.builder, !ok, b.err
 }
 
 func (d *tableBuilderCache) ForEachBuilder(f func(flux.GroupKey, TableBuilder)) {
+	_ = d.ForEachRestoredBuilder(func(key flux.GroupKey, builder TableBuilder) error {
+		f(key, builder)
+		return nil
+	})
+}
+
+func (d *tableBuilderCache) ForEachRestoredBuilder(f func(flux.GroupKey, TableBuilder) error) error {
+	var err error
 	d.tables.Range(func(key flux.GroupKey, value interface{}) {
-		f(key, value.(tableState).builder)
+		if err != nil {
+			return
+		}
+		b := value.(tableState)
+		if b.spilled != nil {
+			if b, err = d.restore(key, b); err != nil {
+				return
+			}
+		}
+		err = f(key, b.builder)
 	})
+	return err
 }
 
 func (d *tableBuilderCache) DiscardTable(key flux.GroupKey) {
 	b, ok := d.lookupState(key)
 	if ok {
+		if b.spilled != nil {
+			// The builder was released when it was spilled.
+			b.builder = NewColListTableBuilder(key, d.alloc)
+			_ = AddTableCols(b.spilled, b.builder)
+			b.spilled.Done()
+			b.spilled, b.spilledRows = nil, 0
+		}
+		b.err = nil
+		d.tables.Set(key, b)
 		b.builder.ClearData()
 	}
 }
@@ -2085,6 +2239,9 @@ func (d *tableBuilderCache) DiscardTable(key flux.GroupKey) {
 func (d *tableBuilderCache) ExpireTable(key flux.GroupKey) {
 	b, ok := d.tables.Delete(key)
 	if ok {
+		if spilled := b.(tableState).spilled; spilled != nil {
+			spilled.Done()
+		}
 		b.(tableState).builder.Release()
 	}
 }
@@ -2098,9 +2255,13 @@ func (d *tableBuilderCache) ForEach(f func(flux.GroupKey)) {
 func (d *tableBuilderCache) ForEachWithContext(f func(flux.GroupKey, Trigger, TableContext)) {
 	d.tables.Range(func(key flux.GroupKey, value interface{}) {
 		b := value.(tableState)
+		count := b.builder.NRows()
+		if b.spilled != nil {
+			count = b.spilledRows
+		}
 		f(key, b.trigger, TableContext{
 			Key:   key,
-			Count: b.builder.NRows(),
+			Count: count,
 		})
 	})
 }
@@ -40,6 +40,10 @@ type consecutiveTransport struct {
 
 	schedulerState int32
 	inflight       int32
+
+	// stats are the statistics of the transformation
+	// when the operator profiler is enabled.
+	stats *operatorStats
 }
 
 func newConsecutiveTransport(ctx context.Context, dispatcher Dispatcher, t Transformation, n plan.Node, logger *zap.Logger) *consecutiveTransport {
@@ -116,6 +120,7 @@ func (t *consecutiveTransport) Process(id DatasetID, tbl flux.Table) error {
 		return t.err()
 	default:
 	}
+	t.stats.addTablesIn(1)
 	t.pushMsg(&processMsg{
 		srcMessage: srcMessage(id),
 		table:      newConsecutiveTransportTable(t, tbl),
@@ -410,6 +415,7 @@ func (t *consecutiveTransportTable) Do(f func(flux.ColReader) error) error {
 			}
 			logger.Info("Invalid column reader received from predecessor", fields...)
 		}
+		t.transport.stats.addRowsIn(int64(cr.Len()))
 		return f(cr)
 	})
 }
@@ -0,0 +1,438 @@
+package influxql
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a list of statements separated by semicolons.
+type Query struct {
+	Statements []*SelectStatement
+}
+
+func (q *Query) String() string {
+	stmts := make([]string, len(q.Statements))
+	for i, stmt := range q.Statements {
+		stmts[i] = stmt.String()
+	}
+	return strings.Join(stmts, "; ")
+}
+
+// FillOption is the fill() option of a SELECT statement.
+type FillOption int
+
+const (
+	// NullFill fills empty windows with null values.
+	// This is the default.
+	NullFill FillOption = iota
+	// NoFill does not output empty windows.
+	NoFill
+	// NumberFill fills empty windows with FillValue.
+	NumberFill
+	// PreviousFill fills empty windows with the previous value.
+	PreviousFill
+	// LinearFill fills empty windows by interpolating between
+	// the surrounding values.
+	LinearFill
+)
+
+// SelectStatement is an InfluxQL SELECT statement.
+type SelectStatement struct {
+	Fields     []*Field
+	Sources    []*Measurement
+	Condition  Expr
+	Dimensions []Expr
+
+	Fill      FillOption
+	FillValue Expr
+
+	// Descending is set by ORDER BY time DESC.
+	Descending bool
+
+	Limit, Offset   int
+	SLimit, SOffset int
+
+	// Location is the time zone set by tz().
+	Location string
+}
+
+func (s *SelectStatement) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("SELECT ")
+	for i, f := range s.Fields {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(f.String())
+	}
+	buf.WriteString(" FROM ")
+	for i, m := range s.Sources {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(m.String())
+	}
+	if s.Condition != nil {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(s.Condition.String())
+	}
+	if len(s.Dimensions) > 0 {
+		buf.WriteString(" GROUP BY ")
+		for i, d := range s.Dimensions {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(d.String())
+		}
+	}
+	switch s.Fill {
+	case NoFill:
+		buf.WriteString(" fill(none)")
+	case NumberFill:
+		fmt.Fprintf(&buf, " fill(%s)", s.FillValue)
+	case PreviousFill:
+		buf.WriteString(" fill(previous)")
+	case LinearFill:
+		buf.WriteString(" fill(linear)")
+	}
+	if s.Descending {
+		buf.WriteString(" ORDER BY time DESC")
+	}
+	if s.Limit > 0 {
+		fmt.Fprintf(&buf, " LIMIT %d", s.Limit)
+	}
+	if s.Offset > 0 {
+		fmt.Fprintf(&buf, " OFFSET %d", s.Offset)
+	}
+	if s.SLimit > 0 {
+		fmt.Fprintf(&buf, " SLIMIT %d", s.SLimit)
+	}
+	if s.SOffset > 0 {
+		fmt.Fprintf(&buf, " SOFFSET %d", s.SOffset)
+	}
+	if s.Location != "" {
+		fmt.Fprintf(&buf, " tz(%s)", quoteString(s.Location))
+	}
+	return buf.String()
+}
+
+// Field is an expression in the SELECT clause with an optional alias.
+type Field struct {
+	Expr  Expr
+	Alias string
+}
+
+// Name returns the name of the column produced by the field.
+// This is the alias if one is set. Otherwise, it is the name
+// of the function or the variable that the field selects.
+func (f *Field) Name() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	switch expr := f.Expr.(type) {
+	case *Call:
+		return expr.Name
+	case *VarRef:
+		return expr.Val
+	case *ParenExpr:
+		return (&Field{Expr: expr.Expr}).Name()
+	case *BinaryExpr:
+		var names []string
+		Walk(func(e Expr) bool {
+			switch e := e.(type) {
+			case *Call:
+				names = append(names, e.Name)
+				return false
+			case *VarRef:
+				names = append(names, e.Val)
+			}
+			return true
+		}, expr)
+		return strings.Join(names, "_")
+	default:
+		return ""
+	}
+}
+
+func (f *Field) String() string {
+	if f.Alias == "" {
+		return f.Expr.String()
+	}
+	return f.Expr.String() + " AS " + quoteIdent(f.Alias)
+}
+
+// Measurement is a source in the FROM clause.
+// Either the name or the regular expression is set.
+type Measurement struct {
+	Database        string
+	RetentionPolicy string
+	Name            string
+	Regex           *RegexLiteral
+}
+
+func (m *Measurement) String() string {
+	var buf bytes.Buffer
+	if m.Database != "" {
+		buf.WriteString(quoteIdent(m.Database))
+		buf.WriteString(".")
+	}
+	if m.RetentionPolicy != "" || m.Database != "" {
+		if m.RetentionPolicy != "" {
+			buf.WriteString(quoteIdent(m.RetentionPolicy))
+		}
+		buf.WriteString(".")
+	}
+	if m.Regex != nil {
+		buf.WriteString(m.Regex.String())
+	} else {
+		buf.WriteString(quoteIdent(m.Name))
+	}
+	return buf.String()
+}
+
+// Expr is an InfluxQL expression.
+type Expr interface {
+	expr()
+	String() string
+}
+
+func (*VarRef) expr()          {}
+func (*Wildcard) expr()        {}
+func (*Call) expr()            {}
+func (*BinaryExpr) expr()      {}
+func (*ParenExpr) expr()       {}
+func (*StringLiteral) expr()   {}
+func (*NumberLiteral) expr()   {}
+func (*IntegerLiteral) expr()  {}
+func (*BooleanLiteral) expr()  {}
+func (*DurationLiteral) expr() {}
+func (*RegexLiteral) expr()    {}
+
+// VarRef is a reference to a field or a tag.
+// The type is set when the reference is cast with ::,
+// for example "host"::tag.
+type VarRef struct {
+	Val  string
+	Type string
+}
+
+func (r *VarRef) String() string {
+	if r.Type == "" {
+		return quoteIdent(r.Val)
+	}
+	return quoteIdent(r.Val) + "::" + r.Type
+}
+
+// Wildcard is the * in SELECT * and GROUP BY *.
+type Wildcard struct{}
+
+func (*Wildcard) String() string { return "*" }
+
+// Call is a function call.
+type Call struct {
+	Name string
+	Args []Expr
+}
+
+func (c *Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = arg.String()
+	}
+	return c.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Operator is a binary operator.
+type Operator string
+
+const (
+	OpAdd        Operator = "+"
+	OpSub        Operator = "-"
+	OpMul        Operator = "*"
+	OpDiv        Operator = "/"
+	OpMod        Operator = "%"
+	OpBitwiseAnd Operator = "&"
+	OpBitwiseOr  Operator = "|"
+	OpBitwiseXor Operator = "^"
+	OpAnd        Operator = "AND"
+	OpOr         Operator = "OR"
+	OpEq         Operator = "="
+	OpNeq        Operator = "!="
+	OpEqRegex    Operator = "=~"
+	OpNeqRegex   Operator = "!~"
+	OpLt         Operator = "<"
+	OpLte        Operator = "<="
+	OpGt         Operator = ">"
+	OpGte        Operator = ">="
+)
+
+// precedence returns the binding power of the operator.
+// Operators with a higher precedence bind tighter.
+func (op Operator) precedence() int {
+	switch op {
+	case OpOr:
+		return 1
+	case OpAnd:
+		return 2
+	case OpEq, OpNeq, OpEqRegex, OpNeqRegex, OpLt, OpLte, OpGt, OpGte:
+		return 3
+	case OpAdd, OpSub, OpBitwiseOr, OpBitwiseXor:
+		return 4
+	case OpMul, OpDiv, OpMod, OpBitwiseAnd:
+		return 5
+	}
+	return 0
+}
+
+func (op Operator) isComparison() bool {
+	return op.precedence() == 3
+}
+
+// BinaryExpr is an operation between two expressions.
+type BinaryExpr struct {
+	Op  Operator
+	LHS Expr
+	RHS Expr
+}
+
+func (e *BinaryExpr) String() string {
+	return e.LHS.String() + " " + string(e.Op) + " " + e.RHS.String()
+}
+
+// ParenExpr is an expression wrapped in parentheses.
+type ParenExpr struct {
+	Expr Expr
+}
+
+func (e *ParenExpr) String() string {
+	return "(" + e.Expr.String() + ")"
+}
+
+// StringLiteral is a single quoted string.
+type StringLiteral struct {
+	Val string
+}
+
+func (l *StringLiteral) String() string { return quoteString(l.Val) }
+
+// NumberLiteral is a floating point number.
+type NumberLiteral struct {
+	Val float64
+}
+
+func (l *NumberLiteral) String() string { return strconv.FormatFloat(l.Val, 'f', -1, 64) }
+
+// IntegerLiteral is an integer.
+type IntegerLiteral struct {
+	Val int64
+}
+
+func (l *IntegerLiteral) String() string { return strconv.FormatInt(l.Val, 10) }
+
+// BooleanLiteral is true or false.
+type BooleanLiteral struct {
+	Val bool
+}
+
+func (l *BooleanLiteral) String() string { return strconv.FormatBool(l.Val) }
+
+// DurationLiteral is a duration such as 5m or 1h30m.
+type DurationLiteral struct {
+	Val time.Duration
+}
+
+func (l *DurationLiteral) String() string { return formatDuration(l.Val) }
+
+// RegexLiteral is a regular expression between slashes.
+type RegexLiteral struct {
+	Val *regexp.Regexp
+}
+
+func (l *RegexLiteral) String() string {
+	return "/" + strings.Replace(l.Val.String(), "/", `\/`, -1) + "/"
+}
+
+// Walk calls fn for the expression and, while fn returns true,
+// for each of its sub-expressions.
+func Walk(fn func(Expr) bool, expr Expr) {
+	if expr == nil || !fn(expr) {
+		return
+	}
+	switch expr := expr.(type) {
+	case *Call:
+		for _, arg := range expr.Args {
+			Walk(fn, arg)
+		}
+	case *BinaryExpr:
+		Walk(fn, expr.LHS)
+		Walk(fn, expr.RHS)
+	case *ParenExpr:
+		Walk(fn, expr.Expr)
+	}
+}
+
+var durationUnits = []struct {
+	unit string
+	d    time.Duration
+}{
+	{unit: "w", d: 7 * 24 * time.Hour},
+	{unit: "d", d: 24 * time.Hour},
+	{unit: "h", d: time.Hour},
+	{unit: "m", d: time.Minute},
+	{unit: "s", d: time.Second},
+	{unit: "ms", d: time.Millisecond},
+	{unit: "u", d: time.Microsecond},
+	{unit: "ns", d: time.Nanosecond},
+}
+
+// formatDuration formats the duration with the largest unit
+// that divides it.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	for _, u := range durationUnits {
+		if d%u.d == 0 {
+			return sign + strconv.FormatInt(int64(d/u.d), 10) + u.unit
+		}
+	}
+	return sign + strconv.FormatInt(int64(d), 10) + "ns"
+}
+
+func quoteString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `'`, `\'`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return "'" + s + "'"
+}
+
+// quoteIdent quotes the identifier when it would not be
+// read back as the same identifier.
+func quoteIdent(s string) string {
+	if isBareIdent(s) {
+		return s
+	}
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+func isBareIdent(s string) bool {
+	if s == "" || isKeyword(s) {
+		return false
+	}
+	for i, ch := range s {
+		if !isIdentChar(ch) || i == 0 && isDigit(ch) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,685 @@
+package influxql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// ParseQuery parses an InfluxQL query made of one or more
+// SELECT statements separated by semicolons.
+func ParseQuery(s string) (*Query, error) {
+	p := &parser{s: scanner{s: s}}
+	q := &Query{}
+	for {
+		it, err := p.scan()
+		if err != nil {
+			return nil, err
+		}
+		switch it.tok {
+		case tokEOF:
+			if len(q.Statements) == 0 {
+				return nil, errors.New(codes.Invalid, "query is empty")
+			}
+			return q, nil
+		case tokSemicolon:
+			continue
+		}
+		p.unscan(it)
+
+		stmt, err := p.parseSelectStatement()
+		if err != nil {
+			return nil, err
+		}
+		q.Statements = append(q.Statements, stmt)
+
+		if it, err := p.scan(); err != nil {
+			return nil, err
+		} else if it.tok != tokSemicolon && it.tok != tokEOF {
+			return nil, p.unexpected(it, ";")
+		} else if it.tok == tokEOF {
+			return q, nil
+		}
+	}
+}
+
+// ParseStatement parses a single SELECT statement.
+func ParseStatement(s string) (*SelectStatement, error) {
+	q, err := ParseQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Statements) != 1 {
+		return nil, errors.Newf(codes.Invalid, "expected one statement, found %d", len(q.Statements))
+	}
+	return q.Statements[0], nil
+}
+
+type parser struct {
+	s   scanner
+	buf []item
+}
+
+func (p *parser) scan() (item, error) {
+	if n := len(p.buf); n > 0 {
+		it := p.buf[n-1]
+		p.buf = p.buf[:n-1]
+		return it, nil
+	}
+	return p.s.scan()
+}
+
+func (p *parser) unscan(it item) {
+	p.buf = append(p.buf, it)
+}
+
+func (p *parser) peek() (item, error) {
+	it, err := p.scan()
+	if err != nil {
+		return item{}, err
+	}
+	p.unscan(it)
+	return it, nil
+}
+
+// scanRegex reads a regular expression if one is next.
+func (p *parser) scanRegex() (*RegexLiteral, error) {
+	if len(p.buf) > 0 {
+		return nil, nil
+	}
+	it, ok, err := p.s.scanRegex()
+	if err != nil || !ok {
+		return nil, err
+	}
+	re, err := regexp.Compile(it.lit)
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Invalid, "invalid regular expression at position %d", it.pos)
+	}
+	return &RegexLiteral{Val: re}, nil
+}
+
+func (p *parser) unexpected(it item, expected string) error {
+	found := it.lit
+	if it.tok == tokEOF {
+		found = "EOF"
+	} else if it.tok == tokString {
+		found = quoteString(it.lit)
+	}
+	return errors.Newf(codes.Invalid, "found %s, expected %s at position %d", found, expected, it.pos)
+}
+
+// isKeyword reports whether the item is the unquoted keyword.
+func (it item) isKeyword(kw string) bool {
+	return it.tok == tokIdent && !it.quoted && strings.EqualFold(it.lit, kw)
+}
+
+// acceptKeyword consumes the keyword if it is next.
+func (p *parser) acceptKeyword(kw string) (bool, error) {
+	it, err := p.scan()
+	if err != nil {
+		return false, err
+	}
+	if it.isKeyword(kw) {
+		return true, nil
+	}
+	p.unscan(it)
+	return false, nil
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	it, err := p.scan()
+	if err != nil {
+		return err
+	}
+	if !it.isKeyword(kw) {
+		return p.unexpected(it, kw)
+	}
+	return nil
+}
+
+func (p *parser) expect(tok token, expected string) (item, error) {
+	it, err := p.scan()
+	if err != nil {
+		return item{}, err
+	}
+	if it.tok != tok {
+		return item{}, p.unexpected(it, expected)
+	}
+	return it, nil
+}
+
+func (p *parser) parseIdent() (string, error) {
+	it, err := p.scan()
+	if err != nil {
+		return "", err
+	}
+	if it.tok != tokIdent || !it.quoted && isKeyword(it.lit) {
+		return "", p.unexpected(it, "identifier")
+	}
+	return it.lit, nil
+}
+
+func (p *parser) parseInt() (int, error) {
+	it, err := p.expect(tokInteger, "integer")
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(it.lit)
+	if err != nil {
+		return 0, errors.Newf(codes.Invalid, "invalid integer %s at position %d", it.lit, it.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) parseSelectStatement() (*SelectStatement, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &SelectStatement{}
+	for {
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Fields = append(stmt.Fields, field)
+		if it, err := p.scan(); err != nil {
+			return nil, err
+		} else if it.tok != tokComma {
+			p.unscan(it)
+			break
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	for {
+		m, err := p.parseMeasurement()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Sources = append(stmt.Sources, m)
+		if it, err := p.scan(); err != nil {
+			return nil, err
+		} else if it.tok != tokComma {
+			p.unscan(it)
+			break
+		}
+	}
+
+	if ok, err := p.acceptKeyword("WHERE"); err != nil {
+		return nil, err
+	} else if ok {
+		if stmt.Condition, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.acceptKeyword("GROUP"); err != nil {
+		return nil, err
+	} else if ok {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if stmt.Dimensions, err = p.parseDimensions(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.parseFill(stmt); err != nil {
+		return nil, err
+	}
+
+	if ok, err := p.acceptKeyword("ORDER"); err != nil {
+		return nil, err
+	} else if ok {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if err := p.parseOrder(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, clause := range []struct {
+		keyword string
+		n       *int
+	}{
+		{keyword: "LIMIT", n: &stmt.Limit},
+		{keyword: "OFFSET", n: &stmt.Offset},
+		{keyword: "SLIMIT", n: &stmt.SLimit},
+		{keyword: "SOFFSET", n: &stmt.SOffset},
+	} {
+		if ok, err := p.acceptKeyword(clause.keyword); err != nil {
+			return nil, err
+		} else if ok {
+			if *clause.n, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := p.parseLocation(stmt); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseField() (*Field, error) {
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	field := &Field{Expr: expr}
+	if ok, err := p.acceptKeyword("AS"); err != nil {
+		return nil, err
+	} else if ok {
+		if field.Alias, err = p.parseIdent(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+// parseMeasurement parses a source such as cpu, db.rp.cpu,
+// db..cpu or /^cpu/.
+func (p *parser) parseMeasurement() (*Measurement, error) {
+	var idents []string
+	for {
+		re, err := p.scanRegex()
+		if err != nil {
+			return nil, err
+		} else if re != nil {
+			idents = append(idents, "")
+			return newMeasurement(idents, re)
+		}
+
+		it, err := p.scan()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case it.tok == tokDot:
+			// An empty retention policy as in db..cpu.
+			idents = append(idents, "")
+			continue
+		case it.tok == tokIdent && (it.quoted || !isKeyword(it.lit)):
+			idents = append(idents, it.lit)
+		default:
+			return nil, p.unexpected(it, "measurement")
+		}
+
+		if it, err = p.scan(); err != nil {
+			return nil, err
+		} else if it.tok != tokDot {
+			p.unscan(it)
+			return newMeasurement(idents, nil)
+		}
+	}
+}
+
+func newMeasurement(idents []string, re *RegexLiteral) (*Measurement, error) {
+	m := &Measurement{Regex: re}
+	switch len(idents) {
+	case 1:
+		m.Name = idents[0]
+	case 2:
+		m.RetentionPolicy, m.Name = idents[0], idents[1]
+	case 3:
+		m.Database, m.RetentionPolicy, m.Name = idents[0], idents[1], idents[2]
+	default:
+		return nil, errors.Newf(codes.Invalid, "too many segments in %s", strings.Join(idents, "."))
+	}
+	if m.Name == "" && re == nil {
+		return nil, errors.New(codes.Invalid, "measurement name is empty")
+	}
+	return m, nil
+}
+
+func (p *parser) parseDimensions() ([]Expr, error) {
+	var dims []Expr
+	for {
+		re, err := p.scanRegex()
+		if err != nil {
+			return nil, err
+		}
+		var dim Expr = re
+		if re == nil {
+			if dim, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		switch d := dim.(type) {
+		case *VarRef, *Wildcard, *RegexLiteral:
+		case *Call:
+			if !strings.EqualFold(d.Name, "time") {
+				return nil, errors.Newf(codes.Invalid, "only time() calls allowed in dimensions, found %s", d)
+			}
+			d.Name = "time"
+			if len(d.Args) < 1 || len(d.Args) > 2 {
+				return nil, errors.Newf(codes.Invalid, "time dimension expected 1 or 2 arguments, found %d", len(d.Args))
+			}
+		default:
+			return nil, errors.Newf(codes.Invalid, "invalid dimension %s", dim)
+		}
+		dims = append(dims, dim)
+
+		if it, err := p.scan(); err != nil {
+			return nil, err
+		} else if it.tok != tokComma {
+			p.unscan(it)
+			return dims, nil
+		}
+	}
+}
+
+func (p *parser) parseFill(stmt *SelectStatement) error {
+	if ok, err := p.acceptKeyword("FILL"); err != nil || !ok {
+		return err
+	}
+	if _, err := p.expect(tokLParen, "("); err != nil {
+		return err
+	}
+	it, err := p.scan()
+	if err != nil {
+		return err
+	}
+	switch {
+	case it.isKeyword("null"):
+		stmt.Fill = NullFill
+	case it.isKeyword("none"):
+		stmt.Fill = NoFill
+	case it.isKeyword("previous"):
+		stmt.Fill = PreviousFill
+	case it.isKeyword("linear"):
+		stmt.Fill = LinearFill
+	default:
+		p.unscan(it)
+		expr, err := p.parseUnaryExpr()
+		if err != nil {
+			return err
+		}
+		switch expr.(type) {
+		case *IntegerLiteral, *NumberLiteral:
+		default:
+			return errors.Newf(codes.Invalid, "fill must be null, none, previous, linear or a number, found %s", expr)
+		}
+		stmt.Fill, stmt.FillValue = NumberFill, expr
+	}
+	_, err = p.expect(tokRParen, ")")
+	return err
+}
+
+func (p *parser) parseOrder(stmt *SelectStatement) error {
+	it, err := p.scan()
+	if err != nil {
+		return err
+	}
+	if !it.isKeyword("time") {
+		return errors.Newf(codes.Invalid, "only ORDER BY time supported at this time, found %s", it.lit)
+	}
+	if ok, err := p.acceptKeyword("DESC"); err != nil {
+		return err
+	} else if ok {
+		stmt.Descending = true
+		return nil
+	}
+	_, err = p.acceptKeyword("ASC")
+	return err
+}
+
+func (p *parser) parseLocation(stmt *SelectStatement) error {
+	it, err := p.scan()
+	if err != nil {
+		return err
+	}
+	if !it.isKeyword("tz") {
+		p.unscan(it)
+		return nil
+	}
+	if _, err := p.expect(tokLParen, "("); err != nil {
+		return err
+	}
+	loc, err := p.expect(tokString, "string")
+	if err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(loc.lit); err != nil {
+		return errors.Newf(codes.Invalid, "unable to find time zone %s", loc.lit)
+	}
+	stmt.Location = loc.lit
+	_, err = p.expect(tokRParen, ")")
+	return err
+}
+
+// parseExpr parses an expression using precedence climbing.
+func (p *parser) parseExpr() (Expr, error) {
+	return p.parseBinaryExpr(1)
+}
+
+func (p *parser) parseBinaryExpr(minPrecedence int) (Expr, error) {
+	lhs, err := p.parseUnaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		it, err := p.scan()
+		if err != nil {
+			return nil, err
+		}
+		op, ok := binaryOperator(it)
+		if !ok || op.precedence() < minPrecedence {
+			p.unscan(it)
+			return lhs, nil
+		}
+
+		var rhs Expr
+		if op == OpEqRegex || op == OpNeqRegex {
+			re, err := p.scanRegex()
+			if err != nil {
+				return nil, err
+			} else if re == nil {
+				it, _ := p.peek()
+				return nil, p.unexpected(it, "regex")
+			}
+			rhs = re
+		} else if rhs, err = p.parseBinaryExpr(op.precedence() + 1); err != nil {
+			return nil, err
+		}
+		lhs = &BinaryExpr{Op: op, LHS: lhs, RHS: rhs}
+	}
+}
+
+func binaryOperator(it item) (Operator, bool) {
+	switch {
+	case it.tok == tokOperator:
+		return Operator(it.lit), true
+	case it.tok == tokMul:
+		return OpMul, true
+	case it.isKeyword("AND"):
+		return OpAnd, true
+	case it.isKeyword("OR"):
+		return OpOr, true
+	}
+	return "", false
+}
+
+func (p *parser) parseUnaryExpr() (Expr, error) {
+	it, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+	switch it.tok {
+	case tokLParen:
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &ParenExpr{Expr: expr}, nil
+	case tokMul:
+		return &Wildcard{}, nil
+	case tokString:
+		return &StringLiteral{Val: it.lit}, nil
+	case tokInteger:
+		n, err := strconv.ParseInt(it.lit, 10, 64)
+		if err != nil {
+			return nil, errors.Newf(codes.Invalid, "integer %s at position %d is out of range", it.lit, it.pos)
+		}
+		return &IntegerLiteral{Val: n}, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(it.lit, 64)
+		if err != nil {
+			return nil, errors.Newf(codes.Invalid, "invalid number %s at position %d", it.lit, it.pos)
+		}
+		return &NumberLiteral{Val: f}, nil
+	case tokDuration:
+		d, err := parseDuration(it.lit)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "invalid duration at position %d", it.pos)
+		}
+		return &DurationLiteral{Val: d}, nil
+	case tokOperator:
+		if it.lit != "-" && it.lit != "+" {
+			break
+		}
+		expr, err := p.parseUnaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		if it.lit == "+" {
+			return expr, nil
+		}
+		switch expr := expr.(type) {
+		case *IntegerLiteral:
+			expr.Val = -expr.Val
+			return expr, nil
+		case *NumberLiteral:
+			expr.Val = -expr.Val
+			return expr, nil
+		case *DurationLiteral:
+			expr.Val = -expr.Val
+			return expr, nil
+		}
+		return &BinaryExpr{Op: OpMul, LHS: &IntegerLiteral{Val: -1}, RHS: expr}, nil
+	case tokIdent:
+		if !it.quoted {
+			switch {
+			case it.isKeyword("true"):
+				return &BooleanLiteral{Val: true}, nil
+			case it.isKeyword("false"):
+				return &BooleanLiteral{Val: false}, nil
+			case isKeyword(it.lit):
+				return nil, p.unexpected(it, "identifier")
+			}
+		}
+
+		next, err := p.scan()
+		if err != nil {
+			return nil, err
+		}
+		if next.tok == tokLParen && !it.quoted {
+			return p.parseCall(strings.ToLower(it.lit))
+		}
+		ref := &VarRef{Val: it.lit}
+		if next.tok != tokDoubleColon {
+			p.unscan(next)
+			return ref, nil
+		}
+		typ, err := p.expect(tokIdent, "data type")
+		if err != nil {
+			return nil, err
+		}
+		switch t := strings.ToLower(typ.lit); t {
+		case "float", "integer", "unsigned", "string", "boolean", "field", "tag":
+			ref.Type = t
+		default:
+			return nil, p.unexpected(typ, "data type")
+		}
+		return ref, nil
+	}
+	return nil, p.unexpected(it, "expression")
+}
+
+func (p *parser) parseCall(name string) (Expr, error) {
+	call := &Call{Name: name}
+	if it, err := p.scan(); err != nil {
+		return nil, err
+	} else if it.tok == tokRParen {
+		return call, nil
+	} else {
+		p.unscan(it)
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+
+		it, err := p.scan()
+		if err != nil {
+			return nil, err
+		}
+		switch it.tok {
+		case tokComma:
+			continue
+		case tokRParen:
+			return call, nil
+		default:
+			return nil, p.unexpected(it, ", or )")
+		}
+	}
+}
+
+// parseDuration parses a duration literal such as 10s or 1h30m.
+func parseDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		n, err := strconv.ParseInt(s[:i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		s = s[i:]
+
+		var unit time.Duration
+		switch {
+		case strings.HasPrefix(s, "ns"):
+			unit, s = time.Nanosecond, s[2:]
+		case strings.HasPrefix(s, "ms"):
+			unit, s = time.Millisecond, s[2:]
+		case strings.HasPrefix(s, "us"):
+			unit, s = time.Microsecond, s[2:]
+		case strings.HasPrefix(s, "µs"):
+			unit, s = time.Microsecond, s[len("µs"):]
+		case strings.HasPrefix(s, "µ"):
+			unit, s = time.Microsecond, s[len("µ"):]
+		case strings.HasPrefix(s, "u"):
+			unit, s = time.Microsecond, s[1:]
+		case strings.HasPrefix(s, "s"):
+			unit, s = time.Second, s[1:]
+		case strings.HasPrefix(s, "m"):
+			unit, s = time.Minute, s[1:]
+		case strings.HasPrefix(s, "h"):
+			unit, s = time.Hour, s[1:]
+		case strings.HasPrefix(s, "d"):
+			unit, s = 24*time.Hour, s[1:]
+		case strings.HasPrefix(s, "w"):
+			unit, s = 7*24*time.Hour, s[1:]
+		default:
+			return 0, errors.Newf(codes.Invalid, "unknown duration unit in %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
@@ -0,0 +1,225 @@
+package influxql_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/influxql"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestParseStatement(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    string
+		want *influxql.SelectStatement
+	}{
+		{
+			name: "raw",
+			s:    `SELECT n FROM ctr`,
+			want: &influxql.SelectStatement{
+				Fields:  []*influxql.Field{{Expr: &influxql.VarRef{Val: "n"}}},
+				Sources: []*influxql.Measurement{{Name: "ctr"}},
+			},
+		},
+		{
+			name: "all clauses",
+			s: `select mean("value") AS "avg", max(value::float) from "telegraf"."autogen"."cpu", /^mem/
+				where host = 'server' and time >= now() - 1h30m
+				group by time(10m, -1m), "host" fill(0.5)
+				order by time desc limit 10 offset 2 slimit 3 soffset 1 tz('America/Chicago')`,
+			want: &influxql.SelectStatement{
+				Fields: []*influxql.Field{
+					{Expr: &influxql.Call{Name: "mean", Args: []influxql.Expr{&influxql.VarRef{Val: "value"}}}, Alias: "avg"},
+					{Expr: &influxql.Call{Name: "max", Args: []influxql.Expr{&influxql.VarRef{Val: "value", Type: "float"}}}},
+				},
+				Sources: []*influxql.Measurement{
+					{Database: "telegraf", RetentionPolicy: "autogen", Name: "cpu"},
+					{Regex: &influxql.RegexLiteral{Val: regexp.MustCompile(`^mem`)}},
+				},
+				Condition: &influxql.BinaryExpr{
+					Op: influxql.OpAnd,
+					LHS: &influxql.BinaryExpr{
+						Op:  influxql.OpEq,
+						LHS: &influxql.VarRef{Val: "host"},
+						RHS: &influxql.StringLiteral{Val: "server"},
+					},
+					RHS: &influxql.BinaryExpr{
+						Op:  influxql.OpGte,
+						LHS: &influxql.VarRef{Val: "time"},
+						RHS: &influxql.BinaryExpr{
+							Op:  influxql.OpSub,
+							LHS: &influxql.Call{Name: "now"},
+							RHS: &influxql.DurationLiteral{Val: 90 * time.Minute},
+						},
+					},
+				},
+				Dimensions: []influxql.Expr{
+					&influxql.Call{Name: "time", Args: []influxql.Expr{
+						&influxql.DurationLiteral{Val: 10 * time.Minute},
+						&influxql.DurationLiteral{Val: -time.Minute},
+					}},
+					&influxql.VarRef{Val: "host"},
+				},
+				Fill:       influxql.NumberFill,
+				FillValue:  &influxql.NumberLiteral{Val: 0.5},
+				Descending: true,
+				Limit:      10,
+				Offset:     2,
+				SLimit:     3,
+				SOffset:    1,
+				Location:   "America/Chicago",
+			},
+		},
+		{
+			name: "precedence",
+			s:    `SELECT a FROM m WHERE a > 1 + 2 * 3 OR b =~ /x\/y/ AND NOT_A_KEYWORD <> 'z'`,
+			want: &influxql.SelectStatement{
+				Fields:  []*influxql.Field{{Expr: &influxql.VarRef{Val: "a"}}},
+				Sources: []*influxql.Measurement{{Name: "m"}},
+				Condition: &influxql.BinaryExpr{
+					Op: influxql.OpOr,
+					LHS: &influxql.BinaryExpr{
+						Op:  influxql.OpGt,
+						LHS: &influxql.VarRef{Val: "a"},
+						RHS: &influxql.BinaryExpr{
+							Op:  influxql.OpAdd,
+							LHS: &influxql.IntegerLiteral{Val: 1},
+							RHS: &influxql.BinaryExpr{
+								Op:  influxql.OpMul,
+								LHS: &influxql.IntegerLiteral{Val: 2},
+								RHS: &influxql.IntegerLiteral{Val: 3},
+							},
+						},
+					},
+					RHS: &influxql.BinaryExpr{
+						Op: influxql.OpAnd,
+						LHS: &influxql.BinaryExpr{
+							Op:  influxql.OpEqRegex,
+							LHS: &influxql.VarRef{Val: "b"},
+							RHS: &influxql.RegexLiteral{Val: regexp.MustCompile(`x/y`)},
+						},
+						RHS: &influxql.BinaryExpr{
+							Op:  influxql.OpNeq,
+							LHS: &influxql.VarRef{Val: "NOT_A_KEYWORD"},
+							RHS: &influxql.StringLiteral{Val: "z"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "wildcard and retention policy",
+			s:    `SELECT * FROM db..m GROUP BY * fill(none) -- comment`,
+			want: &influxql.SelectStatement{
+				Fields:     []*influxql.Field{{Expr: &influxql.Wildcard{}}},
+				Sources:    []*influxql.Measurement{{Database: "db", Name: "m"}},
+				Dimensions: []influxql.Expr{&influxql.Wildcard{}},
+				Fill:       influxql.NoFill,
+			},
+		},
+		{
+			name: "negative numbers",
+			s:    `SELECT f * -2.5 FROM m WHERE f > -3 GROUP BY time(1h) fill(-1)`,
+			want: &influxql.SelectStatement{
+				Fields: []*influxql.Field{{Expr: &influxql.BinaryExpr{
+					Op:  influxql.OpMul,
+					LHS: &influxql.VarRef{Val: "f"},
+					RHS: &influxql.NumberLiteral{Val: -2.5},
+				}}},
+				Sources: []*influxql.Measurement{{Name: "m"}},
+				Condition: &influxql.BinaryExpr{
+					Op:  influxql.OpGt,
+					LHS: &influxql.VarRef{Val: "f"},
+					RHS: &influxql.IntegerLiteral{Val: -3},
+				},
+				Dimensions: []influxql.Expr{
+					&influxql.Call{Name: "time", Args: []influxql.Expr{&influxql.DurationLiteral{Val: time.Hour}}},
+				},
+				Fill:      influxql.NumberFill,
+				FillValue: &influxql.IntegerLiteral{Val: -1},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := influxql.ParseStatement(tc.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.want, got, cmp.Comparer(func(x, y *regexp.Regexp) bool {
+				return x.String() == y.String()
+			})) {
+				t.Errorf("unexpected statement -want/+got:\n%s", cmp.Diff(tc.want.String(), got.String()))
+			}
+
+			// The string of the statement must parse to the same statement.
+			again, err := influxql.ParseStatement(got.String())
+			if err != nil {
+				t.Fatalf("unable to parse %s: %s", got, err)
+			}
+			if want, got := got.String(), again.String(); want != got {
+				t.Errorf("unexpected string -want/+got:\n\t- %s\n\t+ %s", want, got)
+			}
+		})
+	}
+}
+
+func TestParseStatement_Quoting(t *testing.T) {
+	stmt, err := influxql.ParseStatement(`SELECT "my field" AS "select" FROM "my \"measurement\"" WHERE "tag" = 'it\'s'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.Fields[0].Name(), "select"; got != want {
+		t.Errorf("unexpected name -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if got, want := stmt.Sources[0].Name, `my "measurement"`; got != want {
+		t.Errorf("unexpected measurement -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	want := `SELECT "my field" AS "select" FROM "my \"measurement\"" WHERE tag = 'it\'s'`
+	if got := stmt.String(); got != want {
+		t.Errorf("unexpected string -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT a FROM m; SELECT b FROM n;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.String(), `SELECT a FROM m; SELECT b FROM n`; got != want {
+		t.Errorf("unexpected query -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`SELECT`,
+		`SELECT a`,
+		`SELECT a FROM`,
+		`SELECT a FROM m WHERE`,
+		`SELECT a FROM m WHERE b =~ 'c'`,
+		`SELECT a FROM m WHERE b =~ /(/`,
+		`SELECT a FROM m GROUP BY mean(a)`,
+		`SELECT a FROM m fill(nothing)`,
+		`SELECT a FROM m ORDER BY a`,
+		`SELECT a FROM m LIMIT x`,
+		`SELECT a FROM m tz('Nowhere/Nothing')`,
+		`SELECT a FROM m WHERE b = 'c`,
+		`SELECT a FROM m WHERE time > 5x`,
+		`SELECT a::number FROM m`,
+		`SELECT from FROM m`,
+		`SELECT a FROM m extra`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			if _, err := influxql.ParseQuery(s); err == nil {
+				t.Fatal("expected error")
+			} else if code := errors.Code(err); code != codes.Invalid {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", codes.Invalid, code)
+			}
+		})
+	}
+}
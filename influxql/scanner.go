@@ -0,0 +1,335 @@
+package influxql
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+type token int
+
+const (
+	tokEOF token = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokInteger
+	tokDuration
+	tokRegex
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+	tokSemicolon
+	tokDot
+	tokDoubleColon
+	tokMul
+)
+
+// item is a token read by the scanner.
+// Quoted is set for identifiers in double quotes,
+// which are never treated as keywords.
+type item struct {
+	tok    token
+	pos    int
+	lit    string
+	quoted bool
+}
+
+// keywords are the words that cannot be used as a bare identifier.
+var keywords = map[string]bool{
+	"AND":     true,
+	"AS":      true,
+	"ASC":     true,
+	"BY":      true,
+	"DESC":    true,
+	"FALSE":   true,
+	"FILL":    true,
+	"FROM":    true,
+	"GROUP":   true,
+	"LIMIT":   true,
+	"OFFSET":  true,
+	"OR":      true,
+	"ORDER":   true,
+	"SELECT":  true,
+	"SLIMIT":  true,
+	"SOFFSET": true,
+	"TRUE":    true,
+	"WHERE":   true,
+}
+
+func isKeyword(s string) bool {
+	return keywords[strings.ToUpper(s)]
+}
+
+// scanner reads the tokens of an InfluxQL query.
+type scanner struct {
+	s   string
+	pos int
+}
+
+func (s *scanner) peekRune() rune {
+	if s.pos >= len(s.s) {
+		return 0
+	}
+	ch, _ := utf8.DecodeRuneInString(s.s[s.pos:])
+	return ch
+}
+
+func (s *scanner) readRune() rune {
+	if s.pos >= len(s.s) {
+		return 0
+	}
+	ch, size := utf8.DecodeRuneInString(s.s[s.pos:])
+	s.pos += size
+	return ch
+}
+
+// skipSpace skips whitespace and comments.
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.s) {
+		switch rest := s.s[s.pos:]; {
+		case strings.HasPrefix(rest, "--"):
+			if i := strings.IndexByte(rest, '\n'); i >= 0 {
+				s.pos += i + 1
+			} else {
+				s.pos = len(s.s)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if i := strings.Index(rest[2:], "*/"); i >= 0 {
+				s.pos += i + 4
+			} else {
+				s.pos = len(s.s)
+			}
+		default:
+			ch, size := utf8.DecodeRuneInString(rest)
+			if !unicode.IsSpace(ch) {
+				return
+			}
+			s.pos += size
+		}
+	}
+}
+
+func (s *scanner) scan() (item, error) {
+	s.skipSpace()
+	pos := s.pos
+	ch := s.peekRune()
+	switch {
+	case ch == 0:
+		return item{tok: tokEOF, pos: pos}, nil
+	case isIdentChar(ch) && !isDigit(ch):
+		for isIdentChar(s.peekRune()) {
+			s.readRune()
+		}
+		return item{tok: tokIdent, pos: pos, lit: s.s[pos:s.pos]}, nil
+	case isDigit(ch) || ch == '.' && pos+1 < len(s.s) && isDigit(rune(s.s[pos+1])):
+		return s.scanNumber()
+	case ch == '"':
+		lit, err := s.scanQuoted('"')
+		if err != nil {
+			return item{}, err
+		}
+		return item{tok: tokIdent, pos: pos, lit: lit, quoted: true}, nil
+	case ch == '\'':
+		lit, err := s.scanQuoted('\'')
+		if err != nil {
+			return item{}, err
+		}
+		return item{tok: tokString, pos: pos, lit: lit}, nil
+	}
+
+	s.readRune()
+	next := s.peekRune()
+	switch ch {
+	case '(':
+		return item{tok: tokLParen, pos: pos, lit: "("}, nil
+	case ')':
+		return item{tok: tokRParen, pos: pos, lit: ")"}, nil
+	case ',':
+		return item{tok: tokComma, pos: pos, lit: ","}, nil
+	case ';':
+		return item{tok: tokSemicolon, pos: pos, lit: ";"}, nil
+	case '.':
+		return item{tok: tokDot, pos: pos, lit: "."}, nil
+	case '*':
+		return item{tok: tokMul, pos: pos, lit: "*"}, nil
+	case ':':
+		if next == ':' {
+			s.readRune()
+			return item{tok: tokDoubleColon, pos: pos, lit: "::"}, nil
+		}
+	case '+', '-', '/', '%', '&', '|', '^':
+		return item{tok: tokOperator, pos: pos, lit: string(ch)}, nil
+	case '=':
+		if next == '~' {
+			s.readRune()
+			return item{tok: tokOperator, pos: pos, lit: "=~"}, nil
+		}
+		return item{tok: tokOperator, pos: pos, lit: "="}, nil
+	case '!':
+		if next == '=' || next == '~' {
+			s.readRune()
+			return item{tok: tokOperator, pos: pos, lit: "!" + string(next)}, nil
+		}
+	case '<':
+		if next == '=' {
+			s.readRune()
+			return item{tok: tokOperator, pos: pos, lit: "<="}, nil
+		} else if next == '>' {
+			s.readRune()
+			return item{tok: tokOperator, pos: pos, lit: "!="}, nil
+		}
+		return item{tok: tokOperator, pos: pos, lit: "<"}, nil
+	case '>':
+		if next == '=' {
+			s.readRune()
+			return item{tok: tokOperator, pos: pos, lit: ">="}, nil
+		}
+		return item{tok: tokOperator, pos: pos, lit: ">"}, nil
+	}
+	return item{}, errors.Newf(codes.Invalid, "found unexpected character %q at position %d", ch, pos)
+}
+
+// scanNumber reads an integer, a number or a duration.
+func (s *scanner) scanNumber() (item, error) {
+	pos := s.pos
+	for isDigit(s.peekRune()) {
+		s.readRune()
+	}
+	if s.peekRune() == '.' {
+		s.readRune()
+		for isDigit(s.peekRune()) {
+			s.readRune()
+		}
+		if ch := s.peekRune(); ch == 'e' || ch == 'E' {
+			s.scanExponent()
+		}
+		return item{tok: tokNumber, pos: pos, lit: s.s[pos:s.pos]}, nil
+	}
+
+	// A duration is an integer followed by a unit and
+	// may have more than one part, such as 1h30m.
+	if unit := s.durationUnit(); unit != "" {
+		for {
+			s.pos += len(unit)
+			start := s.pos
+			for isDigit(s.peekRune()) {
+				s.readRune()
+			}
+			if s.pos == start {
+				break
+			}
+			if unit = s.durationUnit(); unit == "" {
+				return item{}, errors.Newf(codes.Invalid, "invalid duration %q at position %d", s.s[pos:s.pos], pos)
+			}
+		}
+		if isIdentChar(s.peekRune()) {
+			return item{}, errors.Newf(codes.Invalid, "invalid duration %q at position %d", s.s[pos:s.pos+1], pos)
+		}
+		return item{tok: tokDuration, pos: pos, lit: s.s[pos:s.pos]}, nil
+	}
+	if ch := s.peekRune(); ch == 'e' || ch == 'E' {
+		s.scanExponent()
+		return item{tok: tokNumber, pos: pos, lit: s.s[pos:s.pos]}, nil
+	}
+	return item{tok: tokInteger, pos: pos, lit: s.s[pos:s.pos]}, nil
+}
+
+func (s *scanner) scanExponent() {
+	start := s.pos
+	s.readRune()
+	if ch := s.peekRune(); ch == '+' || ch == '-' {
+		s.readRune()
+	}
+	if !isDigit(s.peekRune()) {
+		s.pos = start
+		return
+	}
+	for isDigit(s.peekRune()) {
+		s.readRune()
+	}
+}
+
+// durationUnit returns the duration unit at the current position.
+func (s *scanner) durationUnit() string {
+	rest := s.s[s.pos:]
+	for _, unit := range []string{"ns", "ms", "µs", "us", "u", "µ", "s", "m", "h", "d", "w"} {
+		if !strings.HasPrefix(rest, unit) {
+			continue
+		}
+		// A unit must not be the start of an identifier, except for the
+		// next part of the duration, such as the m in 1h30m.
+		if ch, _ := utf8.DecodeRuneInString(rest[len(unit):]); !isIdentChar(ch) || isDigit(ch) {
+			return unit
+		}
+	}
+	return ""
+}
+
+// scanQuoted reads a string or identifier that ends with the quote.
+func (s *scanner) scanQuoted(quote rune) (string, error) {
+	pos := s.pos
+	s.readRune()
+	var buf strings.Builder
+	for {
+		ch := s.readRune()
+		switch ch {
+		case 0:
+			return "", errors.Newf(codes.Invalid, "unterminated quoted string at position %d", pos)
+		case quote:
+			return buf.String(), nil
+		case '\\':
+			switch next := s.readRune(); next {
+			case 'n':
+				buf.WriteRune('\n')
+			case '\\', '\'', '"':
+				buf.WriteRune(next)
+			default:
+				return "", errors.Newf(codes.Invalid, "invalid escape sequence \\%c at position %d", next, s.pos-2)
+			}
+		default:
+			buf.WriteRune(ch)
+		}
+	}
+}
+
+// scanRegex reads a regular expression if the next token starts with a slash.
+// Slashes within the expression are escaped with a backslash.
+func (s *scanner) scanRegex() (item, bool, error) {
+	s.skipSpace()
+	pos := s.pos
+	if s.peekRune() != '/' {
+		return item{}, false, nil
+	}
+	s.readRune()
+	var buf strings.Builder
+	for {
+		ch := s.readRune()
+		switch ch {
+		case 0:
+			return item{}, false, errors.Newf(codes.Invalid, "unterminated regular expression at position %d", pos)
+		case '/':
+			return item{tok: tokRegex, pos: pos, lit: buf.String()}, true, nil
+		case '\\':
+			if s.peekRune() == '/' {
+				buf.WriteRune(s.readRune())
+				continue
+			}
+			buf.WriteRune(ch)
+		default:
+			buf.WriteRune(ch)
+		}
+	}
+}
+
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentChar(ch rune) bool {
+	return ch == '_' || isDigit(ch) || unicode.IsLetter(ch)
+}
@@ -0,0 +1,1526 @@
+package influxql
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+var (
+	// epoch is the time of aggregates when the query has no lower time bound.
+	epoch = time.Unix(0, 0).UTC()
+	// minTime and maxTime are the bounds of queries without a time range.
+	minTime = time.Unix(0, math.MinInt64+2).UTC()
+	maxTime = time.Unix(0, math.MaxInt64-1).UTC()
+)
+
+// A Transpiler translates InfluxQL SELECT statements into Flux.
+//
+// Each statement reads its measurements from a bucket and turns the fields
+// it selects into columns named as InfluxQL names them. The time column is
+// named time, tags and the _measurement column remain in the group key and
+// the result of each statement is yielded with the index of the statement
+// as its name, so that it can be matched with the statement_id of an
+// InfluxQL response.
+//
+// The schema is not known either, so an identifier in the SELECT clause is
+// a tag only when it has the ::tag type or the series are grouped by it.
+// Other identifiers are read as fields.
+//
+// The field type is not known when transpiling, so fill() with a number
+// fills count() with an integer, functions that always return floats with
+// a float and other functions with the type of the number as written.
+type Transpiler struct {
+	// Bucket is read by measurements that do not name a database.
+	// Measurements that do, such as db.rp.cpu, read the bucket db/rp
+	// and the retention policy defaults to autogen.
+	Bucket string
+}
+
+// Transpile converts the statements of an InfluxQL query into a Flux file.
+func (t *Transpiler) Transpile(q *Query) (*ast.File, error) {
+	file := &ast.File{}
+	imports := make(map[string]bool)
+	for i, stmt := range q.Statements {
+		st := &statementTranspiler{
+			bucket:  t.Bucket,
+			stmt:    stmt,
+			name:    strconv.Itoa(i),
+			imports: imports,
+		}
+		if len(q.Statements) > 1 {
+			st.suffix = "_" + strconv.Itoa(i)
+		}
+		body, err := st.transpile()
+		if err != nil {
+			if len(q.Statements) > 1 {
+				return nil, errors.Wrapf(err, codes.Inherit, "statement %d", i)
+			}
+			return nil, err
+		}
+		file.Body = append(file.Body, body...)
+	}
+
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		file.Imports = append(file.Imports, &ast.ImportDeclaration{
+			Path: &ast.StringLiteral{Value: path},
+		})
+	}
+	return file, nil
+}
+
+// fieldClass describes the rows produced for a field.
+type fieldClass int
+
+const (
+	// rawField is a field read as is.
+	rawField fieldClass = iota
+	// aggregateField produces one row per series, or per
+	// window with GROUP BY time().
+	aggregateField
+	// transformField produces a row for each input row,
+	// such as difference().
+	transformField
+)
+
+// field is a field of the SELECT clause translated to
+// the calls that compute it from the values of a field key.
+type field struct {
+	name  string
+	ref   string
+	calls []*ast.CallExpression
+	class fieldClass
+	// selector is set when the field keeps the time of the row
+	// it selects, such as max().
+	selector bool
+	// multiRow is set for selectors that select more than one row.
+	multiRow bool
+	fn       string
+}
+
+type timeBound struct {
+	t        time.Time
+	d        time.Duration
+	relative bool
+}
+
+func (b *timeBound) add(d time.Duration) *timeBound {
+	if b.relative {
+		return &timeBound{relative: true, d: b.d + d}
+	}
+	return &timeBound{t: b.t.Add(d)}
+}
+
+// exclusive returns the bound that excludes the time b.
+// Bounds relative to now are not adjusted, as they do
+// not refer to the time of a specific point.
+func (b *timeBound) exclusive() *timeBound {
+	if b.relative {
+		return b
+	}
+	return b.add(time.Nanosecond)
+}
+
+func (b *timeBound) after(o *timeBound) bool {
+	if b.relative != o.relative {
+		return true
+	}
+	if b.relative {
+		return b.d > o.d
+	}
+	return b.t.After(o.t)
+}
+
+type statementTranspiler struct {
+	bucket  string
+	stmt    *SelectStatement
+	name    string
+	suffix  string
+	imports map[string]bool
+
+	measurements []*Measurement
+	start, stop  *timeBound
+	interval     time.Duration
+	offset       time.Duration
+	tags         []string
+	groupAll     bool
+
+	// conditions are the parts of the WHERE clause
+	// that do not restrict time.
+	conditions []Expr
+	selected   map[string]bool
+	// conditionField is the field whose values are filtered.
+	conditionField string
+
+	fields   []*field
+	wildcard bool
+	// tagFields are the tags selected next to the fields.
+	// They are read from the rows of the fields.
+	tagFields []*field
+}
+
+func (t *statementTranspiler) transpile() ([]ast.Statement, error) {
+	if t.stmt.Location != "" {
+		return nil, errors.New(codes.Unimplemented, "tz() is not supported")
+	}
+	if err := t.resolveSources(); err != nil {
+		return nil, err
+	}
+	if err := t.resolveDimensions(); err != nil {
+		return nil, err
+	}
+	if err := t.splitCondition(t.stmt.Condition); err != nil {
+		return nil, err
+	}
+	if t.interval > 0 && t.start == nil {
+		return nil, errors.New(codes.Invalid, "GROUP BY time() requires a lower bound on time in the WHERE clause")
+	}
+	if err := t.resolveFields(); err != nil {
+		return nil, err
+	}
+	cond, err := t.condition()
+	if err != nil {
+		return nil, err
+	}
+
+	var body []ast.Statement
+	var seriesFilter *ast.CallExpression
+	if t.stmt.SLimit > 0 || t.stmt.SOffset > 0 {
+		series, err := t.seriesLimit(cond)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, series)
+		seriesFilter = call("filter", property("fn", rowFn(call("contains",
+			property("value", t.seriesKey()),
+			property("set", &ast.Identifier{Name: series.ID.Name}),
+		))))
+	}
+
+	var expr ast.Expression
+	switch {
+	case t.wildcard:
+		expr = t.wildcardPipeline(cond, seriesFilter)
+	case len(t.fields) == 1:
+		f := t.fields[0]
+		expr = buildPipeline(t.source(fieldFilter(f.ref), cond, seriesFilter), f.calls...)
+	case t.distinctRawFields():
+		refs := make([]string, len(t.fields))
+		for i, f := range t.fields {
+			refs[i] = f.ref
+		}
+		expr = buildPipeline(t.source(fieldFilter(refs...), cond, seriesFilter), pivotFieldsCall(t.ungroupedTags()...))
+	default:
+		tables := make([]ast.Expression, len(t.fields))
+		for i, f := range t.fields {
+			calls := append(f.calls, call("set",
+				property("key", &ast.StringLiteral{Value: "_field"}),
+				property("value", &ast.StringLiteral{Value: f.name}),
+			))
+			tables[i] = buildPipeline(t.source(fieldFilter(f.ref), cond, seriesFilter), calls...)
+		}
+		expr = buildPipeline(
+			call("union", property("tables", &ast.ArrayExpression{Elements: tables})),
+			pivotFieldsCall(t.ungroupedTags()...),
+		)
+	}
+
+	var calls []*ast.CallExpression
+	if t.stmt.Descending {
+		calls = append(calls, call("sort",
+			property("columns", columnList("_time")),
+			property("desc", &ast.BooleanLiteral{Value: true}),
+		))
+	}
+	if t.stmt.Limit > 0 || t.stmt.Offset > 0 {
+		calls = append(calls, limitCall(t.stmt.Limit, t.stmt.Offset))
+	}
+	calls = append(calls, t.shapeColumns()...)
+	calls = append(calls, call("yield", property("name", &ast.StringLiteral{Value: t.name})))
+	body = append(body, &ast.ExpressionStatement{Expression: buildPipeline(expr, calls...)})
+	return body, nil
+}
+
+// resolveSources checks that all measurements are read from the same bucket.
+func (t *statementTranspiler) resolveSources() error {
+	bucket := ""
+	for i, m := range t.stmt.Sources {
+		b := t.bucket
+		if m.Database != "" {
+			rp := m.RetentionPolicy
+			if rp == "" {
+				rp = "autogen"
+			}
+			b = m.Database + "/" + rp
+		} else if m.RetentionPolicy != "" {
+			return errors.Newf(codes.Invalid, "measurement %s names a retention policy without a database", m)
+		}
+		if b == "" {
+			return errors.Newf(codes.Invalid, "measurement %s does not name a database and the transpiler has no bucket", m)
+		}
+		if i > 0 && b != bucket {
+			return errors.New(codes.Unimplemented, "reading measurements from more than one database is not supported")
+		}
+		bucket = b
+	}
+	t.bucket = bucket
+	t.measurements = t.stmt.Sources
+	return nil
+}
+
+func (t *statementTranspiler) resolveDimensions() error {
+	for _, dim := range t.stmt.Dimensions {
+		switch dim := dim.(type) {
+		case *Call:
+			if t.interval > 0 {
+				return errors.New(codes.Invalid, "multiple time dimensions are not allowed")
+			}
+			every, ok := dim.Args[0].(*DurationLiteral)
+			if !ok || every.Val <= 0 {
+				return errors.Newf(codes.Invalid, "time dimension must have a positive duration argument, found %s", dim.Args[0])
+			}
+			t.interval = every.Val
+			if len(dim.Args) == 2 {
+				offset, ok := dim.Args[1].(*DurationLiteral)
+				if !ok {
+					return errors.Newf(codes.Unimplemented, "time dimension offset %s is not supported", dim.Args[1])
+				}
+				t.offset = offset.Val % t.interval
+			}
+		case *VarRef:
+			if strings.EqualFold(dim.Val, "time") {
+				return errors.New(codes.Invalid, "time() is a function and expects at least one argument")
+			}
+			t.tags = append(t.tags, dim.Val)
+		case *Wildcard:
+			t.groupAll = true
+		case *RegexLiteral:
+			return errors.Newf(codes.Unimplemented, "grouping by tags matching %s is not supported", dim)
+		}
+	}
+	if t.groupAll {
+		t.tags = nil
+	}
+	return nil
+}
+
+// splitCondition takes the time bounds out of the WHERE clause.
+// Time bounds may only be combined with AND.
+func (t *statementTranspiler) splitCondition(expr Expr) error {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case *ParenExpr:
+		return t.splitCondition(e.Expr)
+	case *BinaryExpr:
+		if e.Op == OpAnd {
+			if err := t.splitCondition(e.LHS); err != nil {
+				return err
+			}
+			return t.splitCondition(e.RHS)
+		}
+		if ok, err := t.timeCondition(e); err != nil || ok {
+			return err
+		}
+	}
+	if refersToTime(expr) {
+		return errors.Newf(codes.Unimplemented, "time condition %s must be combined with other conditions using AND", expr)
+	}
+	t.conditions = append(t.conditions, expr)
+	return nil
+}
+
+func refersToTime(expr Expr) bool {
+	found := false
+	Walk(func(e Expr) bool {
+		if ref, ok := e.(*VarRef); ok && strings.EqualFold(ref.Val, "time") {
+			found = true
+		}
+		return !found
+	}, expr)
+	return found
+}
+
+// timeCondition sets the time bounds for a comparison with time.
+func (t *statementTranspiler) timeCondition(e *BinaryExpr) (bool, error) {
+	if !e.Op.isComparison() {
+		return false, nil
+	}
+	op, lhs, rhs := e.Op, e.LHS, e.RHS
+	if ref, ok := rhs.(*VarRef); ok && strings.EqualFold(ref.Val, "time") {
+		op, lhs, rhs = flipComparison(op), rhs, lhs
+	}
+	if ref, ok := lhs.(*VarRef); !ok || !strings.EqualFold(ref.Val, "time") {
+		return false, nil
+	}
+
+	b, err := timeValue(rhs)
+	if err != nil {
+		return false, err
+	}
+	switch op {
+	case OpGt:
+		t.setStart(b.exclusive())
+	case OpGte:
+		t.setStart(b)
+	case OpLt:
+		t.setStop(b)
+	case OpLte:
+		t.setStop(b.exclusive())
+	case OpEq:
+		t.setStart(b)
+		t.setStop(b.exclusive())
+	default:
+		return false, errors.Newf(codes.Unimplemented, "time condition %s is not supported", e)
+	}
+	return true, nil
+}
+
+func (t *statementTranspiler) setStart(b *timeBound) {
+	if t.start == nil || b.after(t.start) {
+		t.start = b
+	}
+}
+
+func (t *statementTranspiler) setStop(b *timeBound) {
+	if t.stop == nil || t.stop.after(b) {
+		t.stop = b
+	}
+}
+
+func flipComparison(op Operator) Operator {
+	switch op {
+	case OpLt:
+		return OpGt
+	case OpLte:
+		return OpGte
+	case OpGt:
+		return OpLt
+	case OpGte:
+		return OpLte
+	}
+	return op
+}
+
+// timeValue evaluates the value that time is compared with.
+func timeValue(expr Expr) (*timeBound, error) {
+	switch e := expr.(type) {
+	case *ParenExpr:
+		return timeValue(e.Expr)
+	case *IntegerLiteral:
+		return &timeBound{t: time.Unix(0, e.Val).UTC()}, nil
+	case *NumberLiteral:
+		return &timeBound{t: time.Unix(0, int64(e.Val)).UTC()}, nil
+	case *DurationLiteral:
+		return &timeBound{t: epoch.Add(e.Val)}, nil
+	case *StringLiteral:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"} {
+			if ts, err := time.Parse(layout, e.Val); err == nil {
+				return &timeBound{t: ts.UTC()}, nil
+			}
+		}
+		return nil, errors.Newf(codes.Invalid, "invalid time %s", e)
+	case *Call:
+		if e.Name == "now" && len(e.Args) == 0 {
+			return &timeBound{relative: true}, nil
+		}
+	case *BinaryExpr:
+		if e.Op != OpAdd && e.Op != OpSub {
+			break
+		}
+		b, err := timeValue(e.LHS)
+		if err != nil {
+			return nil, err
+		}
+		var d time.Duration
+		switch rhs := e.RHS.(type) {
+		case *DurationLiteral:
+			d = rhs.Val
+		case *IntegerLiteral:
+			d = time.Duration(rhs.Val)
+		default:
+			return nil, errors.Newf(codes.Unimplemented, "time expression %s is not supported", e)
+		}
+		if e.Op == OpSub {
+			d = -d
+		}
+		return b.add(d), nil
+	}
+	return nil, errors.Newf(codes.Unimplemented, "time expression %s is not supported", expr)
+}
+
+func (t *statementTranspiler) resolveFields() error {
+	t.selected = make(map[string]bool)
+	if len(t.stmt.Fields) == 1 {
+		if _, ok := t.stmt.Fields[0].Expr.(*Wildcard); ok {
+			if t.interval > 0 {
+				return errors.New(codes.Invalid, "GROUP BY requires at least one aggregate function")
+			}
+			t.wildcard = true
+			return nil
+		}
+	}
+
+	names := make(map[string]int)
+	for _, f := range t.stmt.Fields {
+		out := &field{name: f.Name()}
+		tag, isTag := t.selectedTag(f.Expr)
+		if isTag {
+			out.ref = tag
+		} else {
+			class, err := t.compileFieldExpr(out, f.Expr)
+			if err != nil {
+				return err
+			}
+			out.class = class
+			if out.ref == "" {
+				return errors.Newf(codes.Invalid, "field %s must refer to a field", f)
+			}
+			t.selected[out.ref] = true
+		}
+
+		// Make the column names unique as InfluxQL does.
+		if n, ok := names[out.name]; ok {
+			for {
+				n++
+				name := out.name + "_" + strconv.Itoa(n)
+				if _, ok := names[name]; !ok {
+					names[out.name] = n
+					out.name = name
+					break
+				}
+			}
+		}
+		names[out.name] = 0
+		if isTag {
+			t.tagFields = append(t.tagFields, out)
+		} else {
+			t.fields = append(t.fields, out)
+		}
+	}
+	if len(t.fields) == 0 {
+		return errors.New(codes.Invalid, "at least one field must be selected")
+	}
+
+	class := t.fields[0].class
+	if class != rawField && len(t.ungroupedTags()) > 0 {
+		return errors.New(codes.Invalid, "mixing aggregate and non-aggregate queries is not supported")
+	}
+	for _, f := range t.fields {
+		if f.class == rawField && t.interval > 0 {
+			return errors.New(codes.Invalid, "GROUP BY requires at least one aggregate function")
+		}
+		if (f.class == rawField) != (class == rawField) {
+			return errors.New(codes.Invalid, "mixing aggregate and non-aggregate queries is not supported")
+		}
+		if t.interval == 0 && f.class != class {
+			return errors.New(codes.Invalid, "mixing aggregate and transformation functions requires GROUP BY time()")
+		}
+		if f.multiRow && len(t.fields) > 1 {
+			return errors.Newf(codes.Invalid, "selector function %s() cannot be combined with other functions", f.fn)
+		}
+	}
+
+	// Aggregates have the time of the start of the range,
+	// unless a single selector keeps the time of its row.
+	for _, f := range t.fields {
+		if t.interval == 0 && f.class == aggregateField && !f.multiRow && (!f.selector || len(t.fields) > 1) {
+			f.calls = append(f.calls, t.startTimeCall())
+		}
+	}
+	return nil
+}
+
+// selectedTag returns the tag that a field of the SELECT clause selects.
+// A tag is known by its ::tag type or because the series are grouped by it.
+func (t *statementTranspiler) selectedTag(expr Expr) (string, bool) {
+	for {
+		paren, ok := expr.(*ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren.Expr
+	}
+	ref, ok := expr.(*VarRef)
+	if !ok {
+		return "", false
+	}
+	switch ref.Type {
+	case "tag":
+		return ref.Val, true
+	case "":
+		for _, tag := range t.tags {
+			if tag == ref.Val {
+				return tag, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ungroupedTags returns the selected tags that are not in the
+// group key, so they are lost unless they are kept explicitly.
+func (t *statementTranspiler) ungroupedTags() []string {
+	if t.groupAll {
+		return nil
+	}
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range t.tags {
+		seen[tag] = true
+	}
+	for _, f := range t.tagFields {
+		if !seen[f.ref] {
+			seen[f.ref] = true
+			tags = append(tags, f.ref)
+		}
+	}
+	return tags
+}
+
+// startTimeCall sets the time of aggregates to the start of the range.
+func (t *statementTranspiler) startTimeCall() *ast.CallExpression {
+	if t.start == nil {
+		return call("map", property("fn", rowFn(&ast.ObjectExpression{
+			With: &ast.Identifier{Name: "r"},
+			Properties: []*ast.Property{
+				property("_time", &ast.DateTimeLiteral{Value: epoch}),
+			},
+		})))
+	}
+	return call("duplicate",
+		property("column", &ast.StringLiteral{Value: "_start"}),
+		property("as", &ast.StringLiteral{Value: "_time"}),
+	)
+}
+
+// needsStart reports whether the _start column is used
+// after the aggregates.
+func (t *statementTranspiler) needsStart() bool {
+	if t.interval > 0 || t.start == nil {
+		return false
+	}
+	for _, f := range t.fields {
+		if f.class == aggregateField && !f.multiRow && (!f.selector || len(t.fields) > 1) {
+			return true
+		}
+	}
+	return false
+}
+
+var aggregates = map[string]string{
+	"count":  "count",
+	"sum":    "sum",
+	"mean":   "mean",
+	"median": "median",
+	"spread": "spread",
+	"stddev": "stddev",
+}
+
+var selectors = map[string]string{
+	"first": "first",
+	"last":  "last",
+	"min":   "min",
+	"max":   "max",
+}
+
+// floatAggregates always return a float.
+var floatAggregates = map[string]bool{
+	"mean":     true,
+	"median":   true,
+	"stddev":   true,
+	"integral": true,
+}
+
+// compileFieldExpr appends the calls that compute the expression
+// to the field and returns the class of the expression.
+func (t *statementTranspiler) compileFieldExpr(out *field, expr Expr) (fieldClass, error) {
+	switch e := expr.(type) {
+	case *VarRef:
+		if e.Type == "tag" {
+			return 0, errors.Newf(codes.Invalid, "tag %s must be selected on its own", e.Val)
+		}
+		if out.ref != "" {
+			return 0, errors.Newf(codes.Unimplemented, "expressions with more than one field are not supported")
+		}
+		out.ref = e.Val
+		return rawField, nil
+	case *ParenExpr:
+		return t.compileFieldExpr(out, e.Expr)
+	case *BinaryExpr:
+		return t.compileMath(out, e)
+	case *Call:
+		return t.compileCall(out, e)
+	case *Wildcard:
+		return 0, errors.New(codes.Unimplemented, "a wildcard may only be selected on its own")
+	default:
+		return 0, errors.Newf(codes.Invalid, "field %s must refer to a field", expr)
+	}
+}
+
+// compileMath computes arithmetic between a field and a number.
+// The result is a float, as the type of the field is not known.
+func (t *statementTranspiler) compileMath(out *field, e *BinaryExpr) (fieldClass, error) {
+	var op ast.OperatorKind
+	switch e.Op {
+	case OpAdd:
+		op = ast.AdditionOperator
+	case OpSub:
+		op = ast.SubtractionOperator
+	case OpMul:
+		op = ast.MultiplicationOperator
+	case OpDiv:
+		op = ast.DivisionOperator
+	case OpMod:
+		op = ast.ModuloOperator
+	default:
+		return 0, errors.Newf(codes.Unimplemented, "operator %s is not supported in field %s", e.Op, e)
+	}
+
+	expr, number, swapped := e.LHS, e.RHS, false
+	if _, ok := numberValue(expr); ok {
+		expr, number, swapped = number, expr, true
+	}
+	f, ok := numberValue(number)
+	if !ok {
+		return 0, errors.Newf(codes.Unimplemented, "field %s must combine a field with a number", e)
+	}
+	class, err := t.compileFieldExpr(out, expr)
+	if err != nil {
+		return 0, err
+	}
+
+	var lhs, rhs ast.Expression = call("float", property("v", member("r", "_value"))), &ast.FloatLiteral{Value: f}
+	if swapped {
+		lhs, rhs = rhs, lhs
+	}
+	out.calls = append(out.calls, call("map", property("fn", rowFn(&ast.ObjectExpression{
+		With: &ast.Identifier{Name: "r"},
+		Properties: []*ast.Property{
+			property("_value", &ast.BinaryExpression{Operator: op, Left: lhs, Right: rhs}),
+		},
+	}))))
+	return class, nil
+}
+
+func numberValue(expr Expr) (float64, bool) {
+	switch e := expr.(type) {
+	case *IntegerLiteral:
+		return float64(e.Val), true
+	case *NumberLiteral:
+		return e.Val, true
+	case *ParenExpr:
+		return numberValue(e.Expr)
+	}
+	return 0, false
+}
+
+func (t *statementTranspiler) compileCall(out *field, c *Call) (fieldClass, error) {
+	if out.fn == "" {
+		out.fn = c.Name
+	}
+	if len(c.Args) == 0 {
+		return 0, errors.Newf(codes.Invalid, "invalid number of arguments for %s, expected at least 1, got 0", c.Name)
+	}
+
+	switch c.Name {
+	case "count", "sum", "mean", "median", "spread", "stddev", "integral",
+		"first", "last", "min", "max", "percentile", "top", "bottom":
+		if err := t.compileFieldRef(out, c); err != nil {
+			return 0, err
+		}
+		return aggregateField, t.compileAggregate(out, c)
+	case "difference", "non_negative_difference", "derivative", "non_negative_derivative",
+		"cumulative_sum", "moving_average", "elapsed":
+	default:
+		return 0, errors.Newf(codes.Unimplemented, "function %s() is not supported", c.Name)
+	}
+
+	var inner fieldClass
+	switch arg := c.Args[0].(type) {
+	case *VarRef:
+		if arg.Type == "tag" {
+			return 0, errors.Newf(codes.Invalid, "tag %s must be selected on its own", arg.Val)
+		}
+		if t.interval > 0 {
+			return 0, errors.Newf(codes.Invalid, "aggregate function required inside the call to %s", c.Name)
+		}
+		inner = rawField
+		out.ref = arg.Val
+	case *Call:
+		if t.interval == 0 {
+			return 0, errors.Newf(codes.Invalid, "%s aggregate requires a GROUP BY interval", c.Name)
+		}
+		class, err := t.compileCall(out, arg)
+		if err != nil {
+			return 0, err
+		}
+		if class != aggregateField {
+			return 0, errors.Newf(codes.Invalid, "aggregate function required inside the call to %s", c.Name)
+		}
+		inner = class
+	default:
+		return 0, errors.Newf(codes.Invalid, "expected field argument in %s()", c.Name)
+	}
+	if inner == rawField && t.stmt.Fill != NullFill {
+		return 0, errors.New(codes.Invalid, "fill() requires GROUP BY time()")
+	}
+
+	switch c.Name {
+	case "difference", "non_negative_difference":
+		if len(c.Args) != 1 {
+			return 0, errors.Newf(codes.Invalid, "invalid number of arguments for %s, expected 1, got %d", c.Name, len(c.Args))
+		}
+		var args []*ast.Property
+		if c.Name == "non_negative_difference" {
+			args = append(args, property("nonNegative", &ast.BooleanLiteral{Value: true}))
+		}
+		out.calls = append(out.calls, call("difference", args...))
+	case "derivative", "non_negative_derivative":
+		// The unit defaults to the interval of the windows.
+		unit := time.Second
+		if t.interval > 0 {
+			unit = t.interval
+		}
+		if err := durationArg(c, 1, &unit); err != nil {
+			return 0, err
+		}
+		out.calls = append(out.calls, call("derivative",
+			property("unit", durationLiteral(unit)),
+			property("nonNegative", &ast.BooleanLiteral{Value: c.Name == "non_negative_derivative"}),
+		))
+	case "cumulative_sum":
+		if len(c.Args) != 1 {
+			return 0, errors.Newf(codes.Invalid, "invalid number of arguments for %s, expected 1, got %d", c.Name, len(c.Args))
+		}
+		out.calls = append(out.calls, call("cumulativeSum"))
+	case "moving_average":
+		n, err := integerArg(c, 1)
+		if err != nil {
+			return 0, err
+		}
+		out.calls = append(out.calls, call("movingAverage", property("n", &ast.IntegerLiteral{Value: n})))
+	case "elapsed":
+		unit := time.Nanosecond
+		if err := durationArg(c, 1, &unit); err != nil {
+			return 0, err
+		}
+		out.calls = append(out.calls,
+			call("drop", property("columns", columnList("_value"))),
+			call("elapsed",
+				property("unit", durationLiteral(unit)),
+				property("columnName", &ast.StringLiteral{Value: "_value"}),
+			),
+		)
+	}
+	return transformField, nil
+}
+
+func (t *statementTranspiler) compileFieldRef(out *field, c *Call) error {
+	ref, ok := c.Args[0].(*VarRef)
+	if !ok {
+		return errors.Newf(codes.Unimplemented, "expected field argument in %s(), found %s", c.Name, c.Args[0])
+	}
+	if ref.Type == "tag" {
+		return errors.Newf(codes.Invalid, "tag %s must be selected on its own", ref.Val)
+	}
+	if out.ref != "" && out.ref != ref.Val {
+		return errors.Newf(codes.Unimplemented, "expressions with more than one field are not supported")
+	}
+	out.ref = ref.Val
+	return nil
+}
+
+// compileAggregate appends the aggregate or selector.
+// With GROUP BY time(), it is computed for each window.
+func (t *statementTranspiler) compileAggregate(out *field, c *Call) error {
+	name := c.Name
+	var args []*ast.Property
+	switch name {
+	case "median":
+		args = append(args, property("method", &ast.StringLiteral{Value: "exact_mean"}))
+	case "integral":
+		unit := time.Second
+		if err := durationArg(c, 1, &unit); err != nil {
+			return err
+		}
+		args = append(args, property("unit", durationLiteral(unit)))
+	case "percentile":
+		if len(c.Args) != 2 {
+			return errors.Newf(codes.Invalid, "invalid number of arguments for percentile, expected 2, got %d", len(c.Args))
+		}
+		p, ok := numberValue(c.Args[1])
+		if !ok || p < 0 || p > 100 {
+			return errors.Newf(codes.Invalid, "expected a percentile between 0 and 100 in percentile(), found %s", c.Args[1])
+		}
+		name = "quantile"
+		args = append(args,
+			property("q", &ast.FloatLiteral{Value: p / 100}),
+			property("method", &ast.StringLiteral{Value: "exact_selector"}),
+		)
+	case "top", "bottom":
+		n, err := integerArg(c, 1)
+		if err != nil {
+			return err
+		}
+		if t.interval > 0 {
+			return errors.Newf(codes.Unimplemented, "%s() with GROUP BY time() is not supported", name)
+		}
+		out.multiRow = true
+		args = append(args, property("n", &ast.IntegerLiteral{Value: n}))
+	default:
+		if len(c.Args) != 1 {
+			return errors.Newf(codes.Invalid, "invalid number of arguments for %s, expected 1, got %d", name, len(c.Args))
+		}
+	}
+	switch c.Name {
+	case "first", "last", "min", "max", "percentile", "top", "bottom":
+		out.selector = true
+	}
+
+	if t.interval == 0 {
+		out.calls = append(out.calls, call(name, args...))
+		if out.multiRow {
+			// InfluxQL returns the rows in time order.
+			out.calls = append(out.calls, call("sort", property("columns", columnList("_time"))))
+		}
+		return nil
+	}
+
+	var fn ast.Expression = &ast.Identifier{Name: name}
+	if len(args) > 0 {
+		// (column, tables=<-) => tables |> name(args..., column: column)
+		fn = &ast.FunctionExpression{
+			Params: []*ast.Property{
+				{Key: &ast.Identifier{Name: "column"}},
+				{Key: &ast.Identifier{Name: "tables"}, Value: &ast.PipeLiteral{}},
+			},
+			Body: buildPipeline(&ast.Identifier{Name: "tables"},
+				call(name, append(args, property("column", &ast.Identifier{Name: "column"}))...),
+			),
+		}
+	}
+	windowArgs := []*ast.Property{property("every", durationLiteral(t.interval))}
+	if t.offset != 0 {
+		windowArgs = append(windowArgs, property("offset", durationLiteral(t.offset)))
+	}
+	windowArgs = append(windowArgs,
+		property("fn", fn),
+		property("timeSrc", &ast.StringLiteral{Value: "_start"}),
+	)
+	if t.stmt.Fill == NoFill || t.stmt.Fill == LinearFill {
+		windowArgs = append(windowArgs, property("createEmpty", &ast.BooleanLiteral{Value: false}))
+	}
+	out.calls = append(out.calls, call("aggregateWindow", windowArgs...))
+
+	switch t.stmt.Fill {
+	case PreviousFill:
+		out.calls = append(out.calls, call("fill", property("usePrevious", &ast.BooleanLiteral{Value: true})))
+	case LinearFill:
+		t.imports["interpolate"] = true
+		out.calls = append(out.calls, call("interpolate.linear", property("every", durationLiteral(t.interval))))
+	case NumberFill:
+		var value ast.Expression
+		f, _ := numberValue(t.stmt.FillValue)
+		_, isInt := t.stmt.FillValue.(*IntegerLiteral)
+		switch {
+		case c.Name == "count":
+			value = &ast.IntegerLiteral{Value: int64(f)}
+		case floatAggregates[c.Name] || !isInt:
+			value = &ast.FloatLiteral{Value: f}
+		default:
+			value = &ast.IntegerLiteral{Value: int64(f)}
+		}
+		out.calls = append(out.calls, call("fill", property("value", value)))
+	}
+	return nil
+}
+
+func durationArg(c *Call, i int, d *time.Duration) error {
+	if len(c.Args) > i+1 {
+		return errors.Newf(codes.Invalid, "invalid number of arguments for %s, expected at most %d, got %d", c.Name, i+1, len(c.Args))
+	}
+	if len(c.Args) <= i {
+		return nil
+	}
+	lit, ok := c.Args[i].(*DurationLiteral)
+	if !ok || lit.Val <= 0 {
+		return errors.Newf(codes.Invalid, "second argument to %s must be a positive duration, found %s", c.Name, c.Args[i])
+	}
+	*d = lit.Val
+	return nil
+}
+
+func integerArg(c *Call, i int) (int64, error) {
+	if len(c.Args) != i+1 {
+		return 0, errors.Newf(codes.Invalid, "invalid number of arguments for %s, expected %d, got %d", c.Name, i+1, len(c.Args))
+	}
+	lit, ok := c.Args[i].(*IntegerLiteral)
+	if !ok || lit.Val <= 0 {
+		return 0, errors.Newf(codes.Invalid, "second argument to %s must be a positive integer, found %s", c.Name, c.Args[i])
+	}
+	return lit.Val, nil
+}
+
+// condition translates the parts of the WHERE clause that do not
+// restrict time into the body of a filter function. Comparisons with
+// strings and regular expressions compare tags, unless they refer to
+// a selected field. Other comparisons filter the values of the field.
+func (t *statementTranspiler) condition() (ast.Expression, error) {
+	var cond ast.Expression
+	for _, expr := range t.conditions {
+		e, err := t.conditionExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		if cond == nil {
+			cond = e
+			continue
+		}
+		cond = &ast.LogicalExpression{Operator: ast.AndOperator, Left: cond, Right: e}
+	}
+	if t.conditionField != "" {
+		if t.wildcard {
+			return nil, errors.Newf(codes.Unimplemented, "conditions on field %s with a wildcard are not supported", t.conditionField)
+		}
+		for _, f := range t.fields {
+			if f.ref != t.conditionField {
+				return nil, errors.Newf(codes.Unimplemented, "conditions on field %s are only supported when only that field is selected", t.conditionField)
+			}
+		}
+	}
+	return cond, nil
+}
+
+func (t *statementTranspiler) conditionExpr(expr Expr) (ast.Expression, error) {
+	switch e := expr.(type) {
+	case *ParenExpr:
+		return t.conditionExpr(e.Expr)
+	case *BinaryExpr:
+		switch {
+		case e.Op == OpAnd || e.Op == OpOr:
+			lhs, err := t.conditionExpr(e.LHS)
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := t.conditionExpr(e.RHS)
+			if err != nil {
+				return nil, err
+			}
+			op := ast.AndOperator
+			if e.Op == OpOr {
+				op = ast.OrOperator
+			}
+			return &ast.LogicalExpression{Operator: op, Left: lhs, Right: rhs}, nil
+		case e.Op.isComparison():
+			return t.comparison(e)
+		}
+	}
+	return nil, errors.Newf(codes.Unimplemented, "condition %s is not supported", expr)
+}
+
+func (t *statementTranspiler) comparison(e *BinaryExpr) (ast.Expression, error) {
+	op, lhs, rhs := e.Op, e.LHS, e.RHS
+	if _, ok := lhs.(*VarRef); !ok {
+		op, lhs, rhs = flipComparison(op), rhs, lhs
+	}
+	ref, ok := lhs.(*VarRef)
+	if !ok {
+		return nil, errors.Newf(codes.Unimplemented, "condition %s must compare a tag or a field with a value", e)
+	}
+
+	var value ast.Expression
+	isString := false
+	switch lit := rhs.(type) {
+	case *StringLiteral:
+		value, isString = &ast.StringLiteral{Value: lit.Val}, true
+	case *RegexLiteral:
+		value, isString = &ast.RegexpLiteral{Value: lit.Val}, true
+	case *IntegerLiteral:
+		value = &ast.IntegerLiteral{Value: lit.Val}
+	case *NumberLiteral:
+		value = &ast.FloatLiteral{Value: lit.Val}
+	case *BooleanLiteral:
+		value = &ast.BooleanLiteral{Value: lit.Val}
+	default:
+		return nil, errors.Newf(codes.Unimplemented, "condition %s must compare a tag or a field with a value", e)
+	}
+
+	var operator ast.OperatorKind
+	switch op {
+	case OpEq:
+		operator = ast.EqualOperator
+	case OpNeq:
+		operator = ast.NotEqualOperator
+	case OpEqRegex:
+		operator = ast.RegexpMatchOperator
+	case OpNeqRegex:
+		operator = ast.NotRegexpMatchOperator
+	case OpLt:
+		operator = ast.LessThanOperator
+	case OpLte:
+		operator = ast.LessThanEqualOperator
+	case OpGt:
+		operator = ast.GreaterThanOperator
+	case OpGte:
+		operator = ast.GreaterThanEqualOperator
+	}
+
+	isTag := ref.Type == "tag" || ref.Type == "" && isString && !t.selected[ref.Val]
+	if !isTag {
+		if t.conditionField != "" && t.conditionField != ref.Val {
+			return nil, errors.Newf(codes.Unimplemented, "conditions on more than one field are not supported")
+		}
+		t.conditionField = ref.Val
+		return &ast.BinaryExpression{Operator: operator, Left: member("r", "_value"), Right: value}, nil
+	}
+
+	if !isString {
+		return nil, errors.Newf(codes.Invalid, "tag %s can only be compared with a string or a regular expression", ref.Val)
+	}
+	tag := member("r", ref.Val)
+	cmp := &ast.BinaryExpression{Operator: operator, Left: tag, Right: value}
+	// A missing tag is equal to the empty string in InfluxQL.
+	if s, ok := rhs.(*StringLiteral); ok && s.Val == "" {
+		exists := &ast.UnaryExpression{Operator: ast.ExistsOperator, Argument: tag}
+		switch op {
+		case OpEq:
+			return &ast.LogicalExpression{
+				Operator: ast.OrOperator,
+				Left:     &ast.UnaryExpression{Operator: ast.NotOperator, Argument: exists},
+				Right:    cmp,
+			}, nil
+		case OpNeq:
+			return &ast.LogicalExpression{Operator: ast.AndOperator, Left: exists, Right: cmp}, nil
+		}
+	}
+	return cmp, nil
+}
+
+// source reads the series of the fields and groups them.
+func (t *statementTranspiler) source(fields, cond ast.Expression, seriesFilter *ast.CallExpression) ast.Expression {
+	calls := []*ast.CallExpression{
+		call("range", t.rangeArgs()...),
+		call("filter", property("fn", rowFn(t.measurementFilter()))),
+	}
+	if fields != nil {
+		calls = append(calls, call("filter", property("fn", rowFn(fields))))
+	}
+	if cond != nil {
+		calls = append(calls, call("filter", property("fn", rowFn(cond))))
+	}
+	if !t.groupAll {
+		columns := append([]string{"_measurement", "_field"}, t.tags...)
+		if t.needsStart() {
+			columns = append(columns, "_start")
+		}
+		calls = append(calls,
+			call("group", property("columns", columnList(columns...))),
+			call("sort", property("columns", columnList("_time"))),
+		)
+	}
+	calls = append(calls, seriesFilter)
+	return buildPipeline(t.from(), calls...)
+}
+
+func (t *statementTranspiler) from() *ast.CallExpression {
+	return call("from", property("bucket", &ast.StringLiteral{Value: t.bucket}))
+}
+
+func (t *statementTranspiler) rangeArgs() []*ast.Property {
+	start := &ast.DateTimeLiteral{Value: minTime}
+	var args []*ast.Property
+	if t.start != nil {
+		args = append(args, property("start", boundExpr(t.start)))
+	} else {
+		args = append(args, property("start", start))
+	}
+	switch {
+	case t.stop != nil:
+		args = append(args, property("stop", boundExpr(t.stop)))
+	case t.interval == 0:
+		args = append(args, property("stop", &ast.DateTimeLiteral{Value: maxTime}))
+	}
+	return args
+}
+
+func boundExpr(b *timeBound) ast.Expression {
+	if !b.relative {
+		return &ast.DateTimeLiteral{Value: b.t}
+	}
+	if b.d == 0 {
+		return call("now")
+	}
+	return durationLiteral(b.d)
+}
+
+func (t *statementTranspiler) measurementFilter() ast.Expression {
+	var cond ast.Expression
+	for _, m := range t.measurements {
+		var e ast.Expression
+		if m.Regex != nil {
+			e = &ast.BinaryExpression{
+				Operator: ast.RegexpMatchOperator,
+				Left:     member("r", "_measurement"),
+				Right:    &ast.RegexpLiteral{Value: m.Regex.Val},
+			}
+		} else {
+			e = &ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     member("r", "_measurement"),
+				Right:    &ast.StringLiteral{Value: m.Name},
+			}
+		}
+		if cond == nil {
+			cond = e
+			continue
+		}
+		cond = &ast.LogicalExpression{Operator: ast.OrOperator, Left: cond, Right: e}
+	}
+	return cond
+}
+
+func fieldFilter(refs ...string) ast.Expression {
+	var cond ast.Expression
+	for _, ref := range refs {
+		e := &ast.BinaryExpression{
+			Operator: ast.EqualOperator,
+			Left:     member("r", "_field"),
+			Right:    &ast.StringLiteral{Value: ref},
+		}
+		if cond == nil {
+			cond = e
+			continue
+		}
+		cond = &ast.LogicalExpression{Operator: ast.OrOperator, Left: cond, Right: e}
+	}
+	return cond
+}
+
+// distinctRawFields reports whether the fields are selected as is
+// and can be read with a single pivot.
+func (t *statementTranspiler) distinctRawFields() bool {
+	refs := make(map[string]bool)
+	for _, f := range t.fields {
+		if f.class != rawField || len(f.calls) > 0 || refs[f.ref] {
+			return false
+		}
+		refs[f.ref] = true
+	}
+	return true
+}
+
+// wildcardPipeline selects all fields of the series.
+// The fields are pivoted before the series are grouped
+// so that the tags are kept as columns.
+func (t *statementTranspiler) wildcardPipeline(cond ast.Expression, seriesFilter *ast.CallExpression) ast.Expression {
+	calls := []*ast.CallExpression{
+		call("range", t.rangeArgs()...),
+		call("filter", property("fn", rowFn(t.measurementFilter()))),
+	}
+	if cond != nil {
+		calls = append(calls, call("filter", property("fn", rowFn(cond))))
+	}
+	calls = append(calls, pivotFieldsCall())
+	if !t.groupAll {
+		calls = append(calls,
+			call("group", property("columns", columnList(append([]string{"_measurement"}, t.tags...)...))),
+			call("sort", property("columns", columnList("_time"))),
+		)
+	}
+	calls = append(calls, seriesFilter)
+	return buildPipeline(t.from(), calls...)
+}
+
+// shapeColumns keeps the columns of the InfluxQL result
+// and renames them.
+func (t *statementTranspiler) shapeColumns() []*ast.CallExpression {
+	var calls []*ast.CallExpression
+	for _, f := range t.tagFields {
+		if f.name != f.ref {
+			calls = append(calls, call("duplicate",
+				property("column", &ast.StringLiteral{Value: f.ref}),
+				property("as", &ast.StringLiteral{Value: f.name}),
+			))
+		}
+	}
+	renames := []*ast.Property{property("_time", &ast.StringLiteral{Value: "time"})}
+	switch {
+	case t.wildcard:
+		calls = append(calls, call("drop", property("columns", columnList("_start", "_stop"))))
+	case t.groupAll:
+		calls = append(calls, call("drop", property("columns", columnList("_start", "_stop", "_field"))))
+	default:
+		columns := append([]string{"_time", "_measurement"}, t.tags...)
+		if len(t.fields) == 1 {
+			columns = append(columns, "_value")
+		} else {
+			for _, f := range t.fields {
+				if t.distinctRawFields() {
+					columns = append(columns, f.ref)
+				} else {
+					columns = append(columns, f.name)
+				}
+			}
+		}
+		for _, f := range t.tagFields {
+			if !containsString(columns, f.name) {
+				columns = append(columns, f.name)
+			}
+		}
+		calls = append(calls, call("keep", property("columns", columnList(columns...))))
+	}
+
+	switch {
+	case t.wildcard:
+	case len(t.fields) == 1:
+		renames = append(renames, property("_value", &ast.StringLiteral{Value: t.fields[0].name}))
+	case t.distinctRawFields():
+		for _, f := range t.fields {
+			if f.name != f.ref {
+				renames = append(renames, property(f.ref, &ast.StringLiteral{Value: f.name}))
+			}
+		}
+	}
+	calls = append(calls, call("rename", property("columns", &ast.ObjectExpression{Properties: renames})))
+	return calls
+}
+
+// seriesLimit assigns the keys of the series selected by
+// SLIMIT and SOFFSET to a variable. The series are ordered
+// by measurement and then by the tags they are grouped by.
+func (t *statementTranspiler) seriesLimit(cond ast.Expression) (*ast.VariableAssignment, error) {
+	if t.groupAll {
+		return nil, errors.New(codes.Unimplemented, "SLIMIT and SOFFSET with GROUP BY * are not supported")
+	}
+	refs := make([]string, 0, len(t.fields))
+	for _, f := range t.fields {
+		refs = append(refs, f.ref)
+	}
+	calls := []*ast.CallExpression{
+		call("range", t.rangeArgs()...),
+		call("filter", property("fn", rowFn(t.measurementFilter()))),
+	}
+	if len(refs) > 0 {
+		calls = append(calls, call("filter", property("fn", rowFn(fieldFilter(refs...)))))
+	}
+	if cond != nil && !t.wildcard {
+		calls = append(calls, call("filter", property("fn", rowFn(cond))))
+	}
+	columns := append([]string{"_measurement"}, t.tags...)
+	calls = append(calls,
+		call("keep", property("columns", columnList(columns...))),
+		call("group", property("columns", columnList(columns...))),
+		call("first", property("column", &ast.StringLiteral{Value: "_measurement"})),
+		call("group"),
+		call("sort", property("columns", columnList(columns...))),
+		limitCall(t.stmt.SLimit, t.stmt.SOffset),
+		call("map", property("fn", rowFn(&ast.ObjectExpression{
+			Properties: []*ast.Property{property("_series", t.seriesKey())},
+		}))),
+		call("findColumn",
+			property("fn", &ast.FunctionExpression{
+				Params: []*ast.Property{{Key: &ast.Identifier{Name: "key"}}},
+				Body:   &ast.BooleanLiteral{Value: true},
+			}),
+			property("column", &ast.StringLiteral{Value: "_series"}),
+		),
+	)
+	return &ast.VariableAssignment{
+		ID:   &ast.Identifier{Name: "series" + t.suffix},
+		Init: buildPipeline(t.from(), calls...),
+	}, nil
+}
+
+// seriesKey returns the key of the series of a row,
+// such as cpu,host=a. A missing tag is the same as an empty tag.
+func (t *statementTranspiler) seriesKey() ast.Expression {
+	var key ast.Expression = member("r", "_measurement")
+	for _, tag := range t.tags {
+		value := &ast.ConditionalExpression{
+			Test:       &ast.UnaryExpression{Operator: ast.ExistsOperator, Argument: member("r", tag)},
+			Consequent: member("r", tag),
+			Alternate:  &ast.StringLiteral{Value: ""},
+		}
+		key = &ast.BinaryExpression{
+			Operator: ast.AdditionOperator,
+			Left: &ast.BinaryExpression{
+				Operator: ast.AdditionOperator,
+				Left:     key,
+				Right:    &ast.StringLiteral{Value: "," + tag + "="},
+			},
+			Right: value,
+		}
+	}
+	return key
+}
+
+func limitCall(n, offset int) *ast.CallExpression {
+	limit := int64(n)
+	if limit == 0 {
+		// Zero means there is no limit.
+		limit = math.MaxInt64
+	}
+	args := []*ast.Property{property("n", &ast.IntegerLiteral{Value: limit})}
+	if offset > 0 {
+		args = append(args, property("offset", &ast.IntegerLiteral{Value: int64(offset)}))
+	}
+	return call("limit", args...)
+}
+
+// pivotFieldsCall turns the fields into columns. The tags of the
+// row key are kept as columns when they are not in the group key.
+func pivotFieldsCall(tags ...string) *ast.CallExpression {
+	return call("pivot",
+		property("rowKey", columnList(append([]string{"_time"}, tags...)...)),
+		property("columnKey", columnList("_field")),
+		property("valueColumn", &ast.StringLiteral{Value: "_value"}),
+	)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func buildPipeline(arg ast.Expression, calls ...*ast.CallExpression) ast.Expression {
+	for _, c := range calls {
+		// Skip optional calls that are not set.
+		if c == nil {
+			continue
+		}
+		arg = &ast.PipeExpression{Argument: arg, Call: c}
+	}
+	return arg
+}
+
+func call(fn string, args ...*ast.Property) *ast.CallExpression {
+	var callee ast.Expression = &ast.Identifier{Name: fn}
+	if i := strings.IndexByte(fn, '.'); i >= 0 {
+		callee = &ast.MemberExpression{
+			Object:   &ast.Identifier{Name: fn[:i]},
+			Property: &ast.Identifier{Name: fn[i+1:]},
+		}
+	}
+	expr := &ast.CallExpression{Callee: callee}
+	if len(args) > 0 {
+		expr.Arguments = []ast.Expression{
+			&ast.ObjectExpression{Properties: args},
+		}
+	}
+	return expr
+}
+
+func property(key string, value ast.Expression) *ast.Property {
+	return &ast.Property{Key: propertyKey(key), Value: value}
+}
+
+// rowFn returns the function (r) => body.
+func rowFn(body ast.Expression) *ast.FunctionExpression {
+	return &ast.FunctionExpression{
+		Params: []*ast.Property{{Key: &ast.Identifier{Name: "r"}}},
+		Body:   body,
+	}
+}
+
+// member returns o.p, or o["p"] when p is not an identifier.
+func member(o, p string) *ast.MemberExpression {
+	return &ast.MemberExpression{
+		Object:   &ast.Identifier{Name: o},
+		Property: propertyKey(p),
+	}
+}
+
+func propertyKey(name string) ast.PropertyKey {
+	if isFluxIdent(name) {
+		return &ast.Identifier{Name: name}
+	}
+	return &ast.StringLiteral{Value: name}
+}
+
+var fluxKeywords = map[string]bool{
+	"and":      true,
+	"builtin":  true,
+	"else":     true,
+	"empty":    true,
+	"exists":   true,
+	"if":       true,
+	"import":   true,
+	"in":       true,
+	"not":      true,
+	"option":   true,
+	"or":       true,
+	"package":  true,
+	"return":   true,
+	"test":     true,
+	"testcase": true,
+	"then":     true,
+}
+
+func isFluxIdent(s string) bool {
+	if s == "" || fluxKeywords[s] {
+		return false
+	}
+	for i, ch := range s {
+		if !isIdentChar(ch) || i == 0 && isDigit(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+func columnList(strs ...string) *ast.ArrayExpression {
+	list := make([]ast.Expression, len(strs))
+	for i, str := range strs {
+		list[i] = &ast.StringLiteral{Value: str}
+	}
+	return &ast.ArrayExpression{Elements: list}
+}
+
+var fluxDurationUnits = []struct {
+	unit string
+	d    time.Duration
+}{
+	{unit: "w", d: 7 * 24 * time.Hour},
+	{unit: "d", d: 24 * time.Hour},
+	{unit: "h", d: time.Hour},
+	{unit: "m", d: time.Minute},
+	{unit: "s", d: time.Second},
+	{unit: "ms", d: time.Millisecond},
+	{unit: "us", d: time.Microsecond},
+	{unit: "ns", d: time.Nanosecond},
+}
+
+// durationLiteral returns the duration as a literal such as 1h30m.
+// Negative durations are negated literals.
+func durationLiteral(d time.Duration) ast.Expression {
+	if d < 0 {
+		return &ast.UnaryExpression{Operator: ast.SubtractionOperator, Argument: durationLiteral(-d)}
+	}
+	lit := &ast.DurationLiteral{}
+	for _, u := range fluxDurationUnits {
+		if n := d / u.d; n > 0 {
+			lit.Values = append(lit.Values, ast.Duration{Magnitude: int64(n), Unit: u.unit})
+			d -= n * u.d
+		}
+	}
+	if len(lit.Values) == 0 {
+		lit.Values = []ast.Duration{{Magnitude: 0, Unit: "s"}}
+	}
+	return lit
+}
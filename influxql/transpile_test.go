@@ -0,0 +1,296 @@
+package influxql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/influxql"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/runtime"
+)
+
+func transpile(t *testing.T, q string) *ast.File {
+	t.Helper()
+	query, err := influxql.ParseQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transpiler := &influxql.Transpiler{Bucket: "db/autogen"}
+	file, err := transpiler.Transpile(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestTranspiler(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "aggregate",
+			query: `SELECT mean(f) FROM m WHERE time >= '2020-01-01T00:00:00Z' AND time < '2020-01-02T00:00:00Z'`,
+			want: `
+from(bucket: "db/autogen")
+    |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-02T00:00:00Z)
+    |> filter(fn: (r) => r._measurement == "m")
+    |> filter(fn: (r) => r._field == "f")
+    |> group(columns: ["_measurement", "_field", "_start"])
+    |> sort(columns: ["_time"])
+    |> mean()
+    |> duplicate(column: "_start", as: "_time")
+    |> keep(columns: ["_time", "_measurement", "_value"])
+    |> rename(columns: {_time: "time", _value: "mean"})
+    |> yield(name: "0")
+`,
+		},
+		{
+			name:  "window",
+			query: `SELECT count(f) AS n FROM telegraf.autogen.m WHERE host =~ /^a/ AND time > now() - 1h GROUP BY time(10m), host fill(0)`,
+			want: `
+from(bucket: "telegraf/autogen")
+    |> range(start: -1h)
+    |> filter(fn: (r) => r._measurement == "m")
+    |> filter(fn: (r) => r._field == "f")
+    |> filter(fn: (r) => r.host =~ /^a/)
+    |> group(columns: ["_measurement", "_field", "host"])
+    |> sort(columns: ["_time"])
+    |> aggregateWindow(every: 10m, fn: count, timeSrc: "_start")
+    |> fill(value: 0)
+    |> keep(columns: ["_time", "_measurement", "host", "_value"])
+    |> rename(columns: {_time: "time", _value: "n"})
+    |> yield(name: "0")
+`,
+		},
+		{
+			name:  "tags",
+			query: `SELECT f, host::tag, region AS r FROM m WHERE time >= '2020-01-01T00:00:00Z' AND time < '2020-01-02T00:00:00Z' GROUP BY region`,
+			want: `
+from(bucket: "db/autogen")
+    |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-02T00:00:00Z)
+    |> filter(fn: (r) => r._measurement == "m")
+    |> filter(fn: (r) => r._field == "f")
+    |> group(columns: ["_measurement", "_field", "region"])
+    |> sort(columns: ["_time"])
+    |> duplicate(column: "region", as: "r")
+    |> keep(columns: ["_time", "_measurement", "region", "_value", "host", "r"])
+    |> rename(columns: {_time: "time", _value: "f"})
+    |> yield(name: "0")
+`,
+		},
+		{
+			name:  "tags with fields",
+			query: `SELECT a, b, host::tag FROM m WHERE time >= '2020-01-01T00:00:00Z' AND time < '2020-01-02T00:00:00Z'`,
+			want: `
+from(bucket: "db/autogen")
+    |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-02T00:00:00Z)
+    |> filter(fn: (r) => r._measurement == "m")
+    |> filter(fn: (r) => r._field == "a" or r._field == "b")
+    |> group(columns: ["_measurement", "_field"])
+    |> sort(columns: ["_time"])
+    |> pivot(rowKey: ["_time", "host"], columnKey: ["_field"], valueColumn: "_value")
+    |> keep(columns: ["_time", "_measurement", "a", "b", "host"])
+    |> rename(columns: {_time: "time"})
+    |> yield(name: "0")
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Format the expected Flux to compare it with the transpiled
+			// file without taking the positions of the nodes into account.
+			pkg := parser.ParseSource(tc.want)
+			if ast.Check(pkg) > 0 {
+				t.Fatal(ast.GetError(pkg))
+			}
+			want, got := ast.Format(pkg.Files[0]), ast.Format(transpile(t, tc.query))
+			if !cmp.Equal(want, got) {
+				t.Errorf("unexpected flux -want/+got:\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestTranspiler_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		code  codes.Code
+	}{
+		{query: `SELECT a FROM m tz('UTC')`, code: codes.Unimplemented},
+		{query: `SELECT a FROM m GROUP BY time(1m)`, code: codes.Invalid},
+		{query: `SELECT mean(a) FROM m GROUP BY time(1m)`, code: codes.Invalid},
+		{query: `SELECT a, mean(b) FROM m`, code: codes.Invalid},
+		{query: `SELECT mean(a), difference(b) FROM m`, code: codes.Invalid},
+		{query: `SELECT derivative(mean(a)) FROM m`, code: codes.Invalid},
+		{query: `SELECT derivative(a) FROM m WHERE time > 0 GROUP BY time(1m)`, code: codes.Invalid},
+		{query: `SELECT top(a, 3), max(b) FROM m`, code: codes.Invalid},
+		{query: `SELECT a + b FROM m`, code: codes.Unimplemented},
+		{query: `SELECT mode(a) FROM m`, code: codes.Unimplemented},
+		{query: `SELECT a FROM m WHERE time > 0 OR host = 'a'`, code: codes.Unimplemented},
+		{query: `SELECT a FROM m WHERE host::tag = 1`, code: codes.Invalid},
+		{query: `SELECT host::tag FROM m`, code: codes.Invalid},
+		{query: `SELECT mean(a), host::tag FROM m`, code: codes.Invalid},
+		{query: `SELECT mean(host::tag) FROM m`, code: codes.Invalid},
+		{query: `SELECT a FROM m WHERE b > 1`, code: codes.Unimplemented},
+		{query: `SELECT a FROM m GROUP BY /^h/`, code: codes.Unimplemented},
+		{query: `SELECT a FROM m GROUP BY * SLIMIT 1`, code: codes.Unimplemented},
+		{query: `SELECT a FROM db1..m, db2..n`, code: codes.Unimplemented},
+		{query: `SELECT a FROM m; SELECT b FROM rp.n`, code: codes.Invalid},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := influxql.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := (&influxql.Transpiler{Bucket: "db/autogen"}).Transpile(q); err == nil {
+				t.Fatal("expected error")
+			} else if code := errors.Code(err); code != tc.code {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v\n%s", tc.code, code, err)
+			}
+		})
+	}
+}
+
+// TestTranspiler_EndToEnd transpiles the queries of the InfluxQL
+// end-to-end tests and checks that the transpiled Flux returns the
+// results that the tests expect.
+func TestTranspiler_EndToEnd(t *testing.T) {
+	files, err := filepath.Glob("../stdlib/testing/influxql/*_test.flux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no end-to-end tests found")
+	}
+	for _, path := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(path), "_test.flux"), func(t *testing.T) {
+			src, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := regexp.MustCompile(`(?m)^// (SELECT .*)$`).FindSubmatch(src)
+			if query == nil {
+				t.Fatal("no InfluxQL query found")
+			}
+			pkg := parser.ParseSource(string(src))
+			if ast.Check(pkg) > 0 {
+				t.Fatal(ast.GetError(pkg))
+			}
+
+			// Read the test data from the in-memory CSV that the test
+			// loads and yield the expected results next to the results
+			// of the transpiled query.
+			file := transpile(t, string(query[1]))
+			file.Imports = append([]*ast.ImportDeclaration{
+				{Path: &ast.StringLiteral{Value: "testing"}},
+			}, file.Imports...)
+			var data []ast.Statement
+			for _, stmt := range pkg.Files[0].Body {
+				if a, ok := stmt.(*ast.VariableAssignment); ok && (a.ID.Name == "inData" || a.ID.Name == "outData") {
+					data = append(data, a)
+				}
+			}
+			file.Body = append(data, file.Body...)
+			file.Body = append(file.Body, &ast.ExpressionStatement{
+				Expression: &ast.PipeExpression{
+					Argument: &ast.CallExpression{
+						Callee: &ast.MemberExpression{
+							Object:   &ast.Identifier{Name: "testing"},
+							Property: &ast.Identifier{Name: "loadMem"},
+						},
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							Properties: []*ast.Property{{
+								Key:   &ast.Identifier{Name: "csv"},
+								Value: &ast.Identifier{Name: "outData"},
+							}},
+						}},
+					},
+					Call: &ast.CallExpression{
+						Callee: &ast.Identifier{Name: "yield"},
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							Properties: []*ast.Property{{
+								Key:   &ast.Identifier{Name: "name"},
+								Value: &ast.StringLiteral{Value: "want"},
+							}},
+						}},
+					},
+				},
+			})
+			script, err := astutil.Format(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			script = strings.Replace(script, `from(bucket: "db/autogen")`, `testing.loadStorage(csv: inData)`, -1)
+
+			results := runQuery(t, script)
+			got, want := results["0"], results["want"]
+			if len(want) == 0 {
+				t.Fatal("expected results are empty")
+			}
+			// Sums differ in the last digits as the points are sorted by time.
+			if !cmp.Equal(want, got, cmpopts.EquateApprox(0, 1e-12)) {
+				t.Errorf("unexpected results -want/+got:\n%s\nquery:\n%s", cmp.Diff(want, got), script)
+			}
+		})
+	}
+}
+
+// runQuery runs the script and returns the tables of each result.
+// The _time column is renamed to time, which is the name used
+// by most of the end-to-end tests.
+func runQuery(t *testing.T, script string) map[string][]*executetest.Table {
+	t.Helper()
+	c := &lang.FluxCompiler{Query: script}
+	program, err := c.Compile(context.Background(), runtime.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := program.Start(context.Background(), &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Done()
+
+	results := make(map[string][]*executetest.Table)
+	for res := range q.Results() {
+		var tables []*executetest.Table
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			table, err := executetest.ConvertTable(tbl)
+			if err != nil {
+				return err
+			}
+			for j := range table.ColMeta {
+				if table.ColMeta[j].Label == "_time" {
+					table.ColMeta[j].Label = "time"
+				}
+			}
+			tables = append(tables, table)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		executetest.NormalizeTables(tables)
+		results[res.Name()] = tables
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
@@ -0,0 +1,53 @@
+package arrowutil
+
+import (
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+// Compact returns an array with the same values as arr that starts at the
+// beginning of its buffers and has no values past its end.
+// The arrow ipc writer does not encode sliced arrays correctly,
+// so arrays must be compacted before they are written with it.
+//
+// If the array is already compact, it is retained and returned.
+// The caller is responsible for releasing the returned array.
+func Compact(arr array.Interface, mem memory.Allocator) array.Interface {
+	if IsCompact(arr) {
+		arr.Retain()
+		return arr
+	}
+	var b array.Builder
+	if _, ok := arr.(*array.Binary); ok {
+		// Flux stores strings in binary arrays with a string
+		// data type, which would create a string builder.
+		b = array.NewBinaryBuilder(mem, arr.DataType().(arrow.BinaryDataType))
+	} else {
+		b = array.NewBuilder(mem, arr.DataType())
+	}
+	defer b.Release()
+	CopyTo(b, arr)
+	return b.NewArray()
+}
+
+// IsCompact reports whether the array starts at the
+// beginning of its buffers and has no values past its end.
+func IsCompact(arr array.Interface) bool {
+	data := arr.Data()
+	if data.Offset() != 0 {
+		return false
+	}
+	switch arr := arr.(type) {
+	case *array.Boolean:
+		return true
+	case *array.Binary:
+		values := data.Buffers()[2]
+		return values == nil || values.Len() <= len(arr.ValueBytes())
+	default:
+		// The remaining types used by flux are all 8 bytes wide
+		// and the writer pads their buffers to a multiple of 64 bytes.
+		values := data.Buffers()[1]
+		return values == nil || values.Len() <= (arr.Len()*8+63)&^63
+	}
+}
@@ -0,0 +1,206 @@
+// Package messages implements a bounded source that reads messages
+// from a message broker and decodes their payloads into tables.
+package messages
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/line"
+	"github.com/influxdata/flux/values"
+)
+
+// Decoders are the names of the decoders that read the message payloads.
+// The first one is the default.
+var Decoders = []string{"lineprotocol", "csv", "line", "json"}
+
+// DefaultTimeout is the default time to wait for the next message.
+const DefaultTimeout = 5 * time.Second
+
+// NewDecoder creates the decoder with the given name.
+// The time provider gives the time of the rows without a timestamp.
+func NewDecoder(name string, tp line.TimeProvider) (flux.ResultDecoder, error) {
+	switch name {
+	case "csv":
+		return csv.NewResultDecoder(csv.ResultDecoderConfig{}), nil
+	case "line":
+		return line.NewResultDecoder(&line.ResultDecoderConfig{
+			Separator:    '\n',
+			TimeProvider: tp,
+		}), nil
+	case "lineprotocol":
+		return line.NewProtocolDecoder(&line.ProtocolDecoderConfig{
+			TimeProvider: tp,
+		}), nil
+	case "json":
+		return line.NewJSONDecoder(&line.JSONDecoderConfig{
+			TimeProvider: tp,
+		}), nil
+	}
+	return nil, errors.Newf(codes.Invalid, "invalid decoder %s, must be one of %v", name, Decoders)
+}
+
+// NowTimeProvider provides wall clock time.
+type NowTimeProvider struct{}
+
+func (NowTimeProvider) CurrentTime() values.Time {
+	return values.ConvertTime(time.Now())
+}
+
+// Message is a message read from a broker.
+type Message struct {
+	Payload []byte
+	// Time is the time the message was produced or,
+	// when the broker does not keep it, received.
+	Time time.Time
+}
+
+// Reader reads the messages of a topic.
+type Reader interface {
+	// ReadMessage reads the next message.
+	// It returns io.EOF when the reader has no more messages to read.
+	ReadMessage(ctx context.Context) (Message, error)
+	// Commit acknowledges the first n messages that were read
+	// once they have been decoded and processed.
+	Commit(ctx context.Context, n int) error
+	io.Closer
+}
+
+// An Opener is a Reader that connects to its broker when the
+// source starts to run rather than when the source is created,
+// so that creating the source does not block on the network.
+// The reader is closed even if Open fails.
+type Opener interface {
+	Open(ctx context.Context) error
+}
+
+// Bounds are the conditions to stop reading messages.
+// The source stops at the first condition that is met.
+type Bounds struct {
+	// Limit is the maximum number of messages to read.
+	// It is unlimited when zero.
+	Limit int64
+	// Stop excludes the messages with a time at or after it.
+	// It is ignored when zero.
+	Stop time.Time
+	// Timeout is the time to wait for the next message
+	// before the source stops reading.
+	// It is DefaultTimeout when zero.
+	Timeout time.Duration
+}
+
+// NewSource creates a source that reads the messages of r within the bounds
+// and decodes them with the decoder. The payloads are decoded together as if
+// they were a single input, each payload ending with a newline.
+func NewSource(r Reader, decoder flux.ResultDecoder, bounds Bounds, dsid execute.DatasetID) execute.Source {
+	if bounds.Timeout <= 0 {
+		bounds.Timeout = DefaultTimeout
+	}
+	return &source{
+		d:       dsid,
+		r:       r,
+		decoder: decoder,
+		bounds:  bounds,
+	}
+}
+
+type source struct {
+	execute.ExecutionNode
+	d       execute.DatasetID
+	r       Reader
+	decoder flux.ResultDecoder
+	bounds  Bounds
+	ts      []execute.Transformation
+}
+
+func (s *source) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *source) Run(ctx context.Context) {
+	err := s.run(ctx)
+	if cerr := s.r.Close(); err == nil && cerr != nil {
+		err = errors.Wrap(cerr, codes.Inherit, "failed to close the message reader")
+	}
+	for _, t := range s.ts {
+		t.Finish(s.d, err)
+	}
+}
+
+func (s *source) run(ctx context.Context) error {
+	if o, ok := s.r.(Opener); ok {
+		if err := o.Open(ctx); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	n, err := s.read(ctx, &buf)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	result, err := s.decoder.Decode(&buf)
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "decode error")
+	}
+	if err := result.Tables().Do(func(tbl flux.Table) error {
+		for _, t := range s.ts {
+			if err := t.Process(s.d, tbl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return s.r.Commit(ctx, n)
+}
+
+// read reads the messages within the bounds into buf
+// and returns the number of messages.
+func (s *source) read(ctx context.Context, buf *bytes.Buffer) (int, error) {
+	n := 0
+	for s.bounds.Limit <= 0 || int64(n) < s.bounds.Limit {
+		m, err := s.next(ctx)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		if !s.bounds.Stop.IsZero() && !m.Time.Before(s.bounds.Stop) {
+			break
+		}
+		buf.Write(m.Payload)
+		if len(m.Payload) == 0 || m.Payload[len(m.Payload)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		n++
+	}
+	return n, nil
+}
+
+// next reads the next message and returns io.EOF
+// when no message arrives within the timeout.
+func (s *source) next(ctx context.Context) (Message, error) {
+	readCtx, cancel := context.WithTimeout(ctx, s.bounds.Timeout)
+	defer cancel()
+	m, err := s.r.ReadMessage(readCtx)
+	if err != nil {
+		if err != io.EOF && ctx.Err() == nil && readCtx.Err() == context.DeadlineExceeded {
+			return Message{}, io.EOF
+		}
+		return Message{}, err
+	}
+	return m, nil
+}
@@ -0,0 +1,227 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// julianDayOfEpoch is the julian day of the unix epoch.
+// It is used to decode INT96 timestamps.
+const julianDayOfEpoch = 2440588
+
+// values holds the decoded values of a page.
+// Physical types are widened so that only
+// one slice is used for each family of types.
+type values struct {
+	bools  []bool
+	ints   []int64
+	floats []float64
+	bytes  [][]byte
+}
+
+func (v *values) len() int {
+	switch {
+	case v.bools != nil:
+		return len(v.bools)
+	case v.ints != nil:
+		return len(v.ints)
+	case v.floats != nil:
+		return len(v.floats)
+	default:
+		return len(v.bytes)
+	}
+}
+
+// decodePlain decodes n values of the physical type t
+// that were encoded with the plain encoding.
+// When unsigned is set, INT32 values are zero extended.
+func decodePlain(data []byte, t Type, n int, unsigned bool) (values, error) {
+	var v values
+	size := 0
+	switch t {
+	case Boolean:
+		size = (n + 7) / 8
+	case Int32, Float:
+		size = 4 * n
+	case Int64, Double:
+		size = 8 * n
+	case Int96:
+		size = 12 * n
+	}
+	if len(data) < size {
+		return v, errors.Newf(codes.Invalid, "page is too short for %d %s values", n, t)
+	}
+
+	switch t {
+	case Boolean:
+		v.bools = make([]bool, n)
+		for i := range v.bools {
+			v.bools[i] = data[i/8]&(1<<uint(i%8)) != 0
+		}
+	case Int32:
+		v.ints = make([]int64, n)
+		for i := range v.ints {
+			u := binary.LittleEndian.Uint32(data[4*i:])
+			if unsigned {
+				v.ints[i] = int64(u)
+			} else {
+				v.ints[i] = int64(int32(u))
+			}
+		}
+	case Int64:
+		v.ints = make([]int64, n)
+		for i := range v.ints {
+			v.ints[i] = int64(binary.LittleEndian.Uint64(data[8*i:]))
+		}
+	case Int96:
+		// INT96 values are legacy timestamps made of the
+		// nanoseconds within the day followed by the julian day.
+		v.ints = make([]int64, n)
+		for i := range v.ints {
+			nanos := int64(binary.LittleEndian.Uint64(data[12*i:]))
+			day := int64(binary.LittleEndian.Uint32(data[12*i+8:]))
+			v.ints[i] = (day-julianDayOfEpoch)*86400e9 + nanos
+		}
+	case Float:
+		v.floats = make([]float64, n)
+		for i := range v.floats {
+			v.floats[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+		}
+	case Double:
+		v.floats = make([]float64, n)
+		for i := range v.floats {
+			v.floats[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+		}
+	case ByteArray:
+		v.bytes = make([][]byte, n)
+		for i := range v.bytes {
+			if len(data) < 4 {
+				return v, errors.New(codes.Invalid, "page is too short for byte array length")
+			}
+			l := binary.LittleEndian.Uint32(data)
+			if uint64(len(data)-4) < uint64(l) {
+				return v, errors.New(codes.Invalid, "page is too short for byte array")
+			}
+			v.bytes[i], data = data[4:4+l], data[4+l:]
+		}
+	default:
+		return v, errors.Newf(codes.Unimplemented, "unsupported physical type %s", t)
+	}
+	return v, nil
+}
+
+// decodeHybrid decodes n values with the given bit width
+// that were encoded with the RLE/bit-packing hybrid encoding.
+func decodeHybrid(data []byte, bitWidth, n int) ([]int32, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, errors.Newf(codes.Invalid, "invalid bit width %d", bitWidth)
+	}
+	out := make([]int32, 0, n)
+	byteWidth := (bitWidth + 7) / 8
+	for len(out) < n {
+		header, sz := binary.Uvarint(data)
+		if sz <= 0 {
+			return nil, errors.New(codes.Invalid, "invalid run header")
+		}
+		data = data[sz:]
+
+		if header&1 == 0 {
+			// A run of repeated values.
+			count := int(header >> 1)
+			if len(data) < byteWidth {
+				return nil, errors.New(codes.Invalid, "run is too short")
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(data[i]) << (8 * uint(i))
+			}
+			data = data[byteWidth:]
+			for i := 0; i < count && len(out) < n; i++ {
+				out = append(out, int32(v))
+			}
+			continue
+		}
+
+		// Groups of 8 values packed with the least significant bit first.
+		count := int(header>>1) * 8
+		size := int(header>>1) * bitWidth
+		if len(data) < size {
+			return nil, errors.New(codes.Invalid, "bit-packed run is too short")
+		}
+		for i := 0; i < count && len(out) < n; i++ {
+			var v uint32
+			for b := 0; b < bitWidth; b++ {
+				bit := i*bitWidth + b
+				if data[bit/8]&(1<<uint(bit%8)) != 0 {
+					v |= 1 << uint(b)
+				}
+			}
+			out = append(out, int32(v))
+		}
+		data = data[size:]
+	}
+	return out, nil
+}
+
+// encodeHybrid encodes the values with the RLE/bit-packing hybrid
+// encoding. Only runs of repeated values are written, which is enough
+// for the definition levels of flat schemas.
+func encodeHybrid(buf []byte, vs []int32, bitWidth int) []byte {
+	byteWidth := (bitWidth + 7) / 8
+	var tmp [binary.MaxVarintLen64]byte
+	for i := 0; i < len(vs); {
+		j := i + 1
+		for j < len(vs) && vs[j] == vs[i] {
+			j++
+		}
+		n := binary.PutUvarint(tmp[:], uint64(j-i)<<1)
+		buf = append(buf, tmp[:n]...)
+		for b := 0; b < byteWidth; b++ {
+			buf = append(buf, byte(uint32(vs[i])>>(8*uint(b))))
+		}
+		i = j
+	}
+	return buf
+}
+
+// bitWidth returns the number of bits needed to store v.
+func bitWidth(v int) int {
+	n := 0
+	for v > 0 {
+		n++
+		v >>= 1
+	}
+	return n
+}
+
+func decompress(codec int32, data []byte, uncompressedSize int) ([]byte, error) {
+	switch codec {
+	case codecUncompressed:
+		return data, nil
+	case codecSnappy:
+		out, err := snappy.Decode(make([]byte, uncompressedSize), data)
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to decompress snappy page")
+		}
+		return out, nil
+	case codecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to decompress gzip page")
+		}
+		out, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to decompress gzip page")
+		}
+		return out, nil
+	default:
+		return nil, errors.Newf(codes.Unimplemented, "unsupported compression codec %d", codec)
+	}
+}
@@ -0,0 +1,671 @@
+package parquet
+
+// The structures in this file hold the parts of the parquet
+// metadata that are used by the reader and the writer.
+// The field ids are the ones in parquet.thrift.
+
+// Type is the physical type of a column.
+type Type int32
+
+const (
+	Boolean           Type = 0
+	Int32             Type = 1
+	Int64             Type = 2
+	Int96             Type = 3
+	Float             Type = 4
+	Double            Type = 5
+	ByteArray         Type = 6
+	FixedLenByteArray Type = 7
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "BOOLEAN"
+	case Int32:
+		return "INT32"
+	case Int64:
+		return "INT64"
+	case Int96:
+		return "INT96"
+	case Float:
+		return "FLOAT"
+	case Double:
+		return "DOUBLE"
+	case ByteArray:
+		return "BYTE_ARRAY"
+	case FixedLenByteArray:
+		return "FIXED_LEN_BYTE_ARRAY"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ConvertedType is the legacy annotation of how a physical type is interpreted.
+type ConvertedType int32
+
+const (
+	ConvertedUTF8            ConvertedType = 0
+	ConvertedEnum            ConvertedType = 4
+	ConvertedDate            ConvertedType = 6
+	ConvertedTimestampMillis ConvertedType = 9
+	ConvertedTimestampMicros ConvertedType = 10
+	ConvertedUint8           ConvertedType = 11
+	ConvertedUint16          ConvertedType = 12
+	ConvertedUint32          ConvertedType = 13
+	ConvertedUint64          ConvertedType = 14
+	ConvertedJSON            ConvertedType = 19
+)
+
+// Repetition is the repetition of a field in the schema.
+type Repetition int32
+
+const (
+	Required Repetition = 0
+	Optional Repetition = 1
+	Repeated Repetition = 2
+)
+
+// TimeUnit is the unit of a timestamp.
+type TimeUnit int
+
+const (
+	Millis TimeUnit = 1
+	Micros TimeUnit = 2
+	Nanos  TimeUnit = 3
+)
+
+// LogicalType is the annotation of how a physical type is interpreted.
+// Only the logical types that are used by flux are represented.
+type LogicalType struct {
+	String bool
+	Enum   bool
+	JSON   bool
+	Date   bool
+
+	// Timestamp is set when the type is a timestamp with this unit.
+	Timestamp TimeUnit
+	// IsAdjustedToUTC is set for timestamps that are relative to UTC.
+	IsAdjustedToUTC bool
+
+	// Integer is set when the type is an integer with a bit width.
+	Integer  bool
+	BitWidth int8
+	IsSigned bool
+}
+
+const (
+	encodingPlain          = 0
+	encodingPlainDict      = 2
+	encodingRLE            = 3
+	encodingBitPacked      = 4
+	encodingRLEDictionary  = 8
+	pageTypeData           = 0
+	pageTypeDictionary     = 2
+	pageTypeDataV2         = 3
+	codecUncompressed      = 0
+	codecSnappy            = 1
+	codecGzip              = 2
+	thriftListElemI32      = typeI32
+	thriftListElemBinary   = typeBinary
+	thriftListElemStruct   = typeStruct
+	fileMagic              = "PAR1"
+	defaultCreatedByString = "flux parquet writer"
+)
+
+type schemaElement struct {
+	Type          *Type
+	TypeLength    int32
+	Repetition    *Repetition
+	Name          string
+	NumChildren   int32
+	ConvertedType *ConvertedType
+	LogicalType   *LogicalType
+}
+
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+type statistics struct {
+	Max, Min           []byte
+	NullCount          *int64
+	MaxValue, MinValue []byte
+}
+
+type columnMetaData struct {
+	Type                  Type
+	Encodings             []int32
+	PathInSchema          []string
+	Codec                 int32
+	NumValues             int64
+	TotalUncompressedSize int64
+	TotalCompressedSize   int64
+	DataPageOffset        int64
+	DictionaryPageOffset  *int64
+	Statistics            *statistics
+}
+
+type columnChunk struct {
+	FilePath   string
+	FileOffset int64
+	MetaData   *columnMetaData
+}
+
+type rowGroup struct {
+	Columns       []columnChunk
+	TotalByteSize int64
+	NumRows       int64
+}
+
+type fileMetaData struct {
+	Version          int32
+	Schema           []schemaElement
+	NumRows          int64
+	RowGroups        []rowGroup
+	KeyValueMetadata []keyValue
+	CreatedBy        string
+}
+
+type dataPageHeader struct {
+	NumValues               int32
+	Encoding                int32
+	DefinitionLevelEncoding int32
+	RepetitionLevelEncoding int32
+}
+
+type dictionaryPageHeader struct {
+	NumValues int32
+	Encoding  int32
+}
+
+type dataPageHeaderV2 struct {
+	NumValues                  int32
+	NumNulls                   int32
+	NumRows                    int32
+	Encoding                   int32
+	DefinitionLevelsByteLength int32
+	RepetitionLevelsByteLength int32
+	IsCompressed               bool
+}
+
+type pageHeader struct {
+	Type                 int32
+	UncompressedPageSize int32
+	CompressedPageSize   int32
+	DataPageHeader       *dataPageHeader
+	DictionaryPageHeader *dictionaryPageHeader
+	DataPageHeaderV2     *dataPageHeaderV2
+}
+
+func readI32List(r *thriftReader) ([]int32, error) {
+	var vs []int32
+	err := r.readList(func(byte) error {
+		v, err := r.readI32()
+		vs = append(vs, v)
+		return err
+	})
+	return vs, err
+}
+
+func readStringList(r *thriftReader) ([]string, error) {
+	var vs []string
+	err := r.readList(func(byte) error {
+		v, err := r.readString()
+		vs = append(vs, v)
+		return err
+	})
+	return vs, err
+}
+
+func (l *LogicalType) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			l.String = true
+		case 4:
+			l.Enum = true
+		case 6:
+			l.Date = true
+		case 8:
+			return true, r.readStruct(func(id int16, typ byte) (bool, error) {
+				switch id {
+				case 1:
+					l.IsAdjustedToUTC = r.readBool()
+					return true, nil
+				case 2:
+					return true, r.readStruct(func(id int16, typ byte) (bool, error) {
+						if id >= 1 && id <= 3 {
+							l.Timestamp = TimeUnit(id)
+						}
+						return false, nil
+					})
+				}
+				return false, nil
+			})
+		case 10:
+			l.Integer = true
+			return true, r.readStruct(func(id int16, typ byte) (bool, error) {
+				switch id {
+				case 1:
+					b, err := r.readByte()
+					l.BitWidth = int8(b)
+					return true, err
+				case 2:
+					l.IsSigned = r.readBool()
+					return true, nil
+				}
+				return false, nil
+			})
+		case 12:
+			l.JSON = true
+		}
+		// The remaining types and the empty
+		// structs of the types above are skipped.
+		return false, nil
+	})
+}
+
+func (l *LogicalType) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		switch {
+		case l.String:
+			w.structField(1, func() {})
+		case l.Timestamp != 0:
+			w.structField(8, func() {
+				w.boolField(1, l.IsAdjustedToUTC)
+				w.structField(2, func() {
+					w.structField(int16(l.Timestamp), func() {})
+				})
+			})
+		case l.Integer:
+			w.structField(10, func() {
+				w.fieldHeader(1, typeByte)
+				w.buf = append(w.buf, byte(l.BitWidth))
+				w.boolField(2, l.IsSigned)
+			})
+		}
+	})
+}
+
+func (s *schemaElement) read(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			v, err := r.readI32()
+			t := Type(v)
+			s.Type = &t
+			return true, err
+		case 2:
+			v, err := r.readI32()
+			s.TypeLength = v
+			return true, err
+		case 3:
+			v, err := r.readI32()
+			rep := Repetition(v)
+			s.Repetition = &rep
+			return true, err
+		case 4:
+			v, err := r.readString()
+			s.Name = v
+			return true, err
+		case 5:
+			v, err := r.readI32()
+			s.NumChildren = v
+			return true, err
+		case 6:
+			v, err := r.readI32()
+			ct := ConvertedType(v)
+			s.ConvertedType = &ct
+			return true, err
+		case 10:
+			s.LogicalType = new(LogicalType)
+			return true, s.LogicalType.read(r)
+		}
+		return false, nil
+	})
+}
+
+func (s *schemaElement) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		if s.Type != nil {
+			w.i32Field(1, int32(*s.Type))
+		}
+		if s.Repetition != nil {
+			w.i32Field(3, int32(*s.Repetition))
+		}
+		w.stringField(4, s.Name)
+		if s.NumChildren > 0 {
+			w.i32Field(5, s.NumChildren)
+		}
+		if s.ConvertedType != nil {
+			w.i32Field(6, int32(*s.ConvertedType))
+		}
+		if s.LogicalType != nil {
+			w.fieldHeader(10, typeStruct)
+			s.LogicalType.write(w)
+		}
+	})
+}
+
+func (s *statistics) read(r *thriftReader) (err error) {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			s.Max, err = r.readBinary()
+		case 2:
+			s.Min, err = r.readBinary()
+		case 3:
+			var v int64
+			v, err = r.readI64()
+			s.NullCount = &v
+		case 5:
+			s.MaxValue, err = r.readBinary()
+		case 6:
+			s.MinValue, err = r.readBinary()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (s *statistics) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		if s.NullCount != nil {
+			w.i64Field(3, *s.NullCount)
+		}
+		if s.MaxValue != nil {
+			w.binaryField(5, s.MaxValue)
+		}
+		if s.MinValue != nil {
+			w.binaryField(6, s.MinValue)
+		}
+	})
+}
+
+func (m *columnMetaData) read(r *thriftReader) (err error) {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			var v int32
+			v, err = r.readI32()
+			m.Type = Type(v)
+		case 2:
+			m.Encodings, err = readI32List(r)
+		case 3:
+			m.PathInSchema, err = readStringList(r)
+		case 4:
+			m.Codec, err = r.readI32()
+		case 5:
+			m.NumValues, err = r.readI64()
+		case 6:
+			m.TotalUncompressedSize, err = r.readI64()
+		case 7:
+			m.TotalCompressedSize, err = r.readI64()
+		case 9:
+			m.DataPageOffset, err = r.readI64()
+		case 11:
+			var v int64
+			v, err = r.readI64()
+			m.DictionaryPageOffset = &v
+		case 12:
+			m.Statistics = new(statistics)
+			err = m.Statistics.read(r)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (m *columnMetaData) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.i32Field(1, int32(m.Type))
+		w.listField(2, thriftListElemI32, len(m.Encodings))
+		for _, e := range m.Encodings {
+			w.writeI64(int64(e))
+		}
+		w.listField(3, thriftListElemBinary, len(m.PathInSchema))
+		for _, p := range m.PathInSchema {
+			w.writeBinary([]byte(p))
+		}
+		w.i32Field(4, m.Codec)
+		w.i64Field(5, m.NumValues)
+		w.i64Field(6, m.TotalUncompressedSize)
+		w.i64Field(7, m.TotalCompressedSize)
+		w.i64Field(9, m.DataPageOffset)
+		if m.DictionaryPageOffset != nil {
+			w.i64Field(11, *m.DictionaryPageOffset)
+		}
+		if m.Statistics != nil {
+			w.fieldHeader(12, typeStruct)
+			m.Statistics.write(w)
+		}
+	})
+}
+
+func (c *columnChunk) read(r *thriftReader) (err error) {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			c.FilePath, err = r.readString()
+		case 2:
+			c.FileOffset, err = r.readI64()
+		case 3:
+			c.MetaData = new(columnMetaData)
+			err = c.MetaData.read(r)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (c *columnChunk) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.i64Field(2, c.FileOffset)
+		w.fieldHeader(3, typeStruct)
+		c.MetaData.write(w)
+	})
+}
+
+func (g *rowGroup) read(r *thriftReader) (err error) {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			err = r.readList(func(byte) error {
+				var c columnChunk
+				if err := c.read(r); err != nil {
+					return err
+				}
+				g.Columns = append(g.Columns, c)
+				return nil
+			})
+		case 2:
+			g.TotalByteSize, err = r.readI64()
+		case 3:
+			g.NumRows, err = r.readI64()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (g *rowGroup) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.listField(1, thriftListElemStruct, len(g.Columns))
+		for i := range g.Columns {
+			g.Columns[i].write(w)
+		}
+		w.i64Field(2, g.TotalByteSize)
+		w.i64Field(3, g.NumRows)
+	})
+}
+
+func (m *fileMetaData) read(r *thriftReader) (err error) {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			m.Version, err = r.readI32()
+		case 2:
+			err = r.readList(func(byte) error {
+				var s schemaElement
+				if err := s.read(r); err != nil {
+					return err
+				}
+				m.Schema = append(m.Schema, s)
+				return nil
+			})
+		case 3:
+			m.NumRows, err = r.readI64()
+		case 4:
+			err = r.readList(func(byte) error {
+				var g rowGroup
+				if err := g.read(r); err != nil {
+					return err
+				}
+				m.RowGroups = append(m.RowGroups, g)
+				return nil
+			})
+		case 5:
+			err = r.readList(func(byte) error {
+				var kv keyValue
+				var err error
+				err = r.readStruct(func(id int16, typ byte) (bool, error) {
+					switch id {
+					case 1:
+						kv.Key, err = r.readString()
+					case 2:
+						kv.Value, err = r.readString()
+					default:
+						return false, nil
+					}
+					return true, err
+				})
+				m.KeyValueMetadata = append(m.KeyValueMetadata, kv)
+				return err
+			})
+		case 6:
+			m.CreatedBy, err = r.readString()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (m *fileMetaData) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.i32Field(1, m.Version)
+		w.listField(2, thriftListElemStruct, len(m.Schema))
+		for i := range m.Schema {
+			m.Schema[i].write(w)
+		}
+		w.i64Field(3, m.NumRows)
+		w.listField(4, thriftListElemStruct, len(m.RowGroups))
+		for i := range m.RowGroups {
+			m.RowGroups[i].write(w)
+		}
+		if len(m.KeyValueMetadata) > 0 {
+			w.listField(5, thriftListElemStruct, len(m.KeyValueMetadata))
+			for _, kv := range m.KeyValueMetadata {
+				w.writeStruct(func() {
+					w.stringField(1, kv.Key)
+					w.stringField(2, kv.Value)
+				})
+			}
+		}
+		w.stringField(6, m.CreatedBy)
+	})
+}
+
+func (h *pageHeader) read(r *thriftReader) (err error) {
+	return r.readStruct(func(id int16, typ byte) (bool, error) {
+		switch id {
+		case 1:
+			h.Type, err = r.readI32()
+		case 2:
+			h.UncompressedPageSize, err = r.readI32()
+		case 3:
+			h.CompressedPageSize, err = r.readI32()
+		case 5:
+			h.DataPageHeader = new(dataPageHeader)
+			err = r.readStruct(func(id int16, typ byte) (bool, error) {
+				p := h.DataPageHeader
+				switch id {
+				case 1:
+					p.NumValues, err = r.readI32()
+				case 2:
+					p.Encoding, err = r.readI32()
+				case 3:
+					p.DefinitionLevelEncoding, err = r.readI32()
+				case 4:
+					p.RepetitionLevelEncoding, err = r.readI32()
+				default:
+					return false, nil
+				}
+				return true, err
+			})
+		case 7:
+			h.DictionaryPageHeader = new(dictionaryPageHeader)
+			err = r.readStruct(func(id int16, typ byte) (bool, error) {
+				p := h.DictionaryPageHeader
+				switch id {
+				case 1:
+					p.NumValues, err = r.readI32()
+				case 2:
+					p.Encoding, err = r.readI32()
+				default:
+					return false, nil
+				}
+				return true, err
+			})
+		case 8:
+			h.DataPageHeaderV2 = &dataPageHeaderV2{IsCompressed: true}
+			err = r.readStruct(func(id int16, typ byte) (bool, error) {
+				p := h.DataPageHeaderV2
+				switch id {
+				case 1:
+					p.NumValues, err = r.readI32()
+				case 2:
+					p.NumNulls, err = r.readI32()
+				case 3:
+					p.NumRows, err = r.readI32()
+				case 4:
+					p.Encoding, err = r.readI32()
+				case 5:
+					p.DefinitionLevelsByteLength, err = r.readI32()
+				case 6:
+					p.RepetitionLevelsByteLength, err = r.readI32()
+				case 7:
+					p.IsCompressed = r.readBool()
+				default:
+					return false, nil
+				}
+				return true, err
+			})
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+}
+
+func (h *pageHeader) write(w *thriftWriter) {
+	w.writeStruct(func() {
+		w.i32Field(1, h.Type)
+		w.i32Field(2, h.UncompressedPageSize)
+		w.i32Field(3, h.CompressedPageSize)
+		if p := h.DataPageHeader; p != nil {
+			w.structField(5, func() {
+				w.i32Field(1, p.NumValues)
+				w.i32Field(2, p.Encoding)
+				w.i32Field(3, p.DefinitionLevelEncoding)
+				w.i32Field(4, p.RepetitionLevelEncoding)
+			})
+		}
+	})
+}
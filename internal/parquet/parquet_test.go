@@ -0,0 +1,322 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/golang/snappy"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+)
+
+func TestWriter_RoundTrip(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	newArray := func(typ flux.ColType, vs ...interface{}) array.Interface {
+		b := arrow.NewBuilder(typ, mem)
+		defer b.Release()
+		for _, v := range vs {
+			if v == nil {
+				b.AppendNull()
+				continue
+			}
+			switch v := v.(type) {
+			case int64:
+				_ = arrow.AppendInt(b, v)
+			case uint64:
+				_ = arrow.AppendUint(b, v)
+			case float64:
+				_ = arrow.AppendFloat(b, v)
+			case string:
+				_ = arrow.AppendString(b, v)
+			case bool:
+				_ = arrow.AppendBool(b, v)
+			}
+		}
+		return b.NewArray()
+	}
+
+	groups := []struct {
+		cols []flux.ColMeta
+		arrs []array.Interface
+	}{
+		{
+			cols: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+				{Label: "ok", Type: flux.TBool},
+			},
+			arrs: []array.Interface{
+				newArray(flux.TInt, int64(30), int64(10), int64(20)),
+				newArray(flux.TFloat, 1.5, nil, -2.0),
+				newArray(flux.TString, "a", "b", nil),
+				newArray(flux.TBool, true, false, nil),
+			},
+		},
+		{
+			// The host column is missing and n is new.
+			cols: []flux.ColMeta{
+				{Label: "n", Type: flux.TUInt},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			arrs: []array.Interface{
+				newArray(flux.TUInt, uint64(1<<63), nil),
+				newArray(flux.TInt, int64(40), int64(50)),
+				newArray(flux.TFloat, nil, nil),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, g := range groups {
+		if err := w.WriteRowGroup(g.cols, g.arrs); err != nil {
+			t.Fatal(err)
+		}
+		for _, arr := range g.arrs {
+			arr.Release()
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cols []flux.ColMeta
+	for _, c := range f.Columns() {
+		cols = append(cols, flux.ColMeta{Label: c.Name, Type: c.Type})
+	}
+	wantCols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+		{Label: "host", Type: flux.TString},
+		{Label: "ok", Type: flux.TBool},
+		{Label: "n", Type: flux.TUInt},
+	}
+	if !cmp.Equal(wantCols, cols) {
+		t.Fatalf("unexpected columns -want/+got:\n%s", cmp.Diff(wantCols, cols))
+	}
+
+	want := [][][]interface{}{
+		{
+			{int64(30), int64(10), int64(20)},
+			{1.5, nil, -2.0},
+			{"a", "b", nil},
+			{true, false, nil},
+			{nil, nil, nil},
+		},
+		{
+			{int64(40), int64(50)},
+			{nil, nil},
+			{nil, nil},
+			{nil, nil},
+			{uint64(1 << 63), nil},
+		},
+	}
+	if got := f.NumRowGroups(); got != len(want) {
+		t.Fatalf("unexpected number of row groups: %d", got)
+	}
+	for rg := range want {
+		got := make([][]interface{}, len(cols))
+		for j := range cols {
+			arr, err := f.ReadColumn(rg, j, mem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[j] = toSlice(arr)
+			arr.Release()
+		}
+		if !cmp.Equal(want[rg], got) {
+			t.Errorf("unexpected values in row group %d -want/+got:\n%s", rg, cmp.Diff(want[rg], got))
+		}
+	}
+
+	if min, max, ok := f.Bounds(0, 0); !ok || min != 10 || max != 30 {
+		t.Errorf("unexpected bounds: %d, %d, %v", min, max, ok)
+	}
+	if _, _, ok := f.Bounds(0, 1); ok {
+		t.Error("expected no bounds for a float column")
+	}
+	if n, ok := f.NullCount(0, 1); !ok || n != 1 {
+		t.Errorf("unexpected null count: %d, %v", n, ok)
+	}
+	if n, ok := f.NullCount(1, 2); !ok || n != 2 {
+		t.Errorf("unexpected null count of a missing column: %d, %v", n, ok)
+	}
+	if size := f.ColumnSize(0, 0); size <= 0 {
+		t.Errorf("unexpected column size: %d", size)
+	}
+}
+
+func toSlice(arr array.Interface) []interface{} {
+	vs := make([]interface{}, arr.Len())
+	for i := range vs {
+		if arr.IsNull(i) {
+			continue
+		}
+		switch a := arr.(type) {
+		case *array.Int64:
+			vs[i] = a.Value(i)
+		case *array.Uint64:
+			vs[i] = a.Value(i)
+		case *array.Float64:
+			vs[i] = a.Value(i)
+		case *array.Binary:
+			vs[i] = a.ValueString(i)
+		case *array.Boolean:
+			vs[i] = a.Value(i)
+		}
+	}
+	return vs
+}
+
+func TestDecodeHybrid(t *testing.T) {
+	// A run of four 2s followed by a bit-packed group
+	// of the values 0 through 7 with a bit width of 3.
+	data := []byte{
+		4 << 1, 2,
+		1<<1 | 1, 0x88, 0xc6, 0xfa,
+	}
+	got, err := decodeHybrid(data, 3, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int32{2, 2, 2, 2, 0, 1, 2, 3, 4, 5, 6, 7}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestOpen_NotParquet(t *testing.T) {
+	data := []byte("this is not a parquet file")
+	if _, err := Open(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// TestOpen_ReferenceFiles reads the files written by pyarrow
+// with testdata/generate.py.
+func TestOpen_ReferenceFiles(t *testing.T) {
+	wantCols := []flux.ColMeta{
+		{Label: "time", Type: flux.TTime},
+		{Label: "value", Type: flux.TFloat},
+		{Label: "host", Type: flux.TString},
+		{Label: "ok", Type: flux.TBool},
+		{Label: "n", Type: flux.TUInt},
+		{Label: "i", Type: flux.TInt},
+	}
+	want := [][]interface{}{
+		{int64(10), int64(20), int64(30), int64(40)},
+		{1.5, nil, -2.0, 4.0},
+		{"a", "b", nil, "a"},
+		{true, nil, false, true},
+		{nil, uint64(2), uint64(1 << 63), uint64(4)},
+		{int64(-1), int64(0), nil, int64(1)},
+	}
+
+	for _, name := range []string{
+		"snappy_plain.parquet",
+		"snappy_dictionary.parquet",
+		"snappy_dictionary_v2.parquet",
+		"uncompressed.parquet",
+	} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+			if os.IsNotExist(err) {
+				t.Skipf("%s has not been generated, run testdata/generate.py", name)
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			f, err := Open(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var cols []flux.ColMeta
+			for _, c := range f.Columns() {
+				cols = append(cols, flux.ColMeta{Label: c.Name, Type: c.Type})
+			}
+			if !cmp.Equal(wantCols, cols) {
+				t.Fatalf("unexpected columns -want/+got:\n%s", cmp.Diff(wantCols, cols))
+			}
+
+			got := make([][]interface{}, len(cols))
+			for rg := 0; rg < f.NumRowGroups(); rg++ {
+				for j := range cols {
+					arr, err := f.ReadColumn(rg, j, mem)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got[j] = append(got[j], toSlice(arr)...)
+					arr.Release()
+				}
+			}
+			if !cmp.Equal(want, got) {
+				t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestReadDataPage_Dictionary(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	// The dictionary holds the plain encoded strings "a" and "b".
+	dict, err := decodePlain([]byte{1, 0, 0, 0, 'a', 1, 0, 0, 0, 'b'}, ByteArray, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The definition levels are prefixed by their length and
+	// the indices of the non-null values by their bit width.
+	page := make([]byte, 4)
+	page = encodeHybrid(page, []int32{1, 1, 0, 1, 1}, 1)
+	binary.LittleEndian.PutUint32(page, uint32(len(page)-4))
+	page = append(page, 1)
+	page = encodeHybrid(page, []int32{1, 0, 1, 1}, 1)
+	compressed := snappy.Encode(nil, page)
+
+	c := &Column{Name: "host", Type: flux.TString, typ: ByteArray, optional: true}
+	h := &pageHeader{
+		Type:                 pageTypeData,
+		UncompressedPageSize: int32(len(page)),
+		CompressedPageSize:   int32(len(compressed)),
+		DataPageHeader: &dataPageHeader{
+			NumValues: 5,
+			Encoding:  encodingRLEDictionary,
+		},
+	}
+	b := newBuilder(c, mem)
+	defer b.Release()
+	if n, err := c.readDataPage(b, h, codecSnappy, compressed, &dict); err != nil {
+		t.Fatal(err)
+	} else if n != 5 {
+		t.Fatalf("unexpected number of values: %d", n)
+	}
+	arr := b.NewArray()
+	defer arr.Release()
+
+	want := []interface{}{"b", "a", nil, "b", "b"}
+	if got := toSlice(arr); !cmp.Equal(want, got) {
+		t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,429 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// Column describes a column of a parquet file
+// and the flux type it is read as.
+type Column struct {
+	Name string
+	Type flux.ColType
+
+	typ      Type
+	optional bool
+	unsigned bool
+	// scale converts integer values into nanoseconds for time columns.
+	scale int64
+}
+
+// File is a parquet file opened for reading.
+// Only flat schemas are supported.
+type File struct {
+	r       io.ReaderAt
+	meta    fileMetaData
+	columns []Column
+}
+
+// Open reads the metadata of a parquet file of the given size.
+func Open(r io.ReaderAt, size int64) (*File, error) {
+	if size < int64(2*len(fileMagic)+4) {
+		return nil, errors.New(codes.Invalid, "file is too small to be a parquet file")
+	}
+	var footer [8]byte
+	if _, err := r.ReadAt(footer[:], size-8); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "failed to read parquet footer")
+	}
+	if string(footer[4:]) != fileMagic {
+		return nil, errors.New(codes.Invalid, "file is not a parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(footer[:4]))
+	if n > size-8-int64(len(fileMagic)) {
+		return nil, errors.Newf(codes.Invalid, "invalid parquet metadata length %d", n)
+	}
+
+	f := &File{r: r}
+	tr := newThriftReader(bufio.NewReader(io.NewSectionReader(r, size-8-n, n)))
+	if err := f.meta.read(tr); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "failed to read parquet metadata")
+	}
+	if err := f.readSchema(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) readSchema() error {
+	schema := f.meta.Schema
+	if len(schema) == 0 {
+		return errors.New(codes.Invalid, "parquet schema is empty")
+	}
+	if int(schema[0].NumChildren) != len(schema)-1 {
+		return errors.New(codes.Unimplemented, "nested parquet schemas are not supported")
+	}
+	f.columns = make([]Column, 0, len(schema)-1)
+	for _, elem := range schema[1:] {
+		if elem.Type == nil || elem.NumChildren > 0 {
+			return errors.Newf(codes.Unimplemented, "nested parquet column %q is not supported", elem.Name)
+		}
+		col := Column{
+			Name:     elem.Name,
+			typ:      *elem.Type,
+			optional: elem.Repetition == nil || *elem.Repetition == Optional,
+		}
+		if elem.Repetition != nil && *elem.Repetition == Repeated {
+			return errors.Newf(codes.Unimplemented, "repeated parquet column %q is not supported", elem.Name)
+		}
+		if err := col.setType(&elem); err != nil {
+			return err
+		}
+		f.columns = append(f.columns, col)
+	}
+	return nil
+}
+
+// setType maps the physical and logical type of a column to a flux type.
+func (c *Column) setType(elem *schemaElement) error {
+	lt := elem.LogicalType
+	if lt == nil {
+		lt = &LogicalType{}
+	}
+	var ct ConvertedType = -1
+	if elem.ConvertedType != nil {
+		ct = *elem.ConvertedType
+	}
+	unsigned := lt.Integer && !lt.IsSigned ||
+		ct >= ConvertedUint8 && ct <= ConvertedUint64
+
+	switch c.typ {
+	case Boolean:
+		c.Type = flux.TBool
+	case Int32:
+		switch {
+		case lt.Date || ct == ConvertedDate:
+			c.Type, c.scale = flux.TTime, 86400e9
+		case unsigned:
+			c.Type, c.unsigned = flux.TUInt, true
+		default:
+			c.Type = flux.TInt
+		}
+	case Int64:
+		switch {
+		case lt.Timestamp != 0:
+			c.Type, c.scale = flux.TTime, unitScale(lt.Timestamp)
+		case ct == ConvertedTimestampMillis:
+			c.Type, c.scale = flux.TTime, unitScale(Millis)
+		case ct == ConvertedTimestampMicros:
+			c.Type, c.scale = flux.TTime, unitScale(Micros)
+		case unsigned:
+			c.Type = flux.TUInt
+		default:
+			c.Type = flux.TInt
+		}
+	case Int96:
+		c.Type, c.scale = flux.TTime, 1
+	case Float, Double:
+		c.Type = flux.TFloat
+	case ByteArray:
+		c.Type = flux.TString
+	default:
+		return errors.Newf(codes.Unimplemented, "parquet column %q has unsupported type %s", c.Name, c.typ)
+	}
+	return nil
+}
+
+func unitScale(u TimeUnit) int64 {
+	switch u {
+	case Millis:
+		return 1e6
+	case Micros:
+		return 1e3
+	default:
+		return 1
+	}
+}
+
+// Columns returns the columns of the file.
+func (f *File) Columns() []Column {
+	return f.columns
+}
+
+// NumRowGroups returns the number of row groups in the file.
+func (f *File) NumRowGroups() int {
+	return len(f.meta.RowGroups)
+}
+
+// NumRows returns the number of rows in a row group.
+func (f *File) NumRows(rg int) int64 {
+	return f.meta.RowGroups[rg].NumRows
+}
+
+// Bounds returns the minimum and maximum value of a time column
+// within a row group as nanoseconds since the epoch.
+// The returned bool is false when the row group has no statistics
+// for the column.
+func (f *File) Bounds(rg, col int) (min, max int64, ok bool) {
+	c := f.columns[col]
+	if c.Type != flux.TTime {
+		return 0, 0, false
+	}
+	md := f.meta.RowGroups[rg].Columns[col].MetaData
+	if md == nil || md.Statistics == nil {
+		return 0, 0, false
+	}
+	lo, hi := md.Statistics.MinValue, md.Statistics.MaxValue
+	if lo == nil || hi == nil {
+		// The deprecated fields use a signed order, which
+		// is correct for all of the time representations.
+		lo, hi = md.Statistics.Min, md.Statistics.Max
+	}
+	if lo == nil || hi == nil {
+		return 0, 0, false
+	}
+	minv, err := decodePlain(lo, c.typ, 1, false)
+	if err != nil {
+		return 0, 0, false
+	}
+	maxv, err := decodePlain(hi, c.typ, 1, false)
+	if err != nil {
+		return 0, 0, false
+	}
+	return minv.ints[0] * c.scale, maxv.ints[0] * c.scale, true
+}
+
+// NullCount returns the number of nulls in a column within a row group.
+// The returned bool is false when the row group has no statistics
+// for the column.
+func (f *File) NullCount(rg, col int) (int64, bool) {
+	md := f.meta.RowGroups[rg].Columns[col].MetaData
+	if md == nil || md.Statistics == nil || md.Statistics.NullCount == nil {
+		return 0, false
+	}
+	return *md.Statistics.NullCount, true
+}
+
+// ColumnSize returns the number of bytes that ReadColumn
+// reads for a column within a row group.
+func (f *File) ColumnSize(rg, col int) int64 {
+	md := f.meta.RowGroups[rg].Columns[col].MetaData
+	if md == nil {
+		return 0
+	}
+	return md.TotalCompressedSize
+}
+
+// ReadColumn reads the values of a column within a row group.
+func (f *File) ReadColumn(rg, col int, mem memory.Allocator) (array.Interface, error) {
+	c := &f.columns[col]
+	chunk := f.meta.RowGroups[rg].Columns[col]
+	md := chunk.MetaData
+	if md == nil || chunk.FilePath != "" {
+		return nil, errors.Newf(codes.Unimplemented, "parquet column %q is stored in an external file", c.Name)
+	}
+
+	start := md.DataPageOffset
+	if md.DictionaryPageOffset != nil && *md.DictionaryPageOffset > 0 && *md.DictionaryPageOffset < start {
+		start = *md.DictionaryPageOffset
+	}
+	data := make([]byte, md.TotalCompressedSize)
+	if _, err := f.r.ReadAt(data, start); err != nil {
+		return nil, errors.Wrapf(err, codes.Invalid, "failed to read parquet column %q", c.Name)
+	}
+
+	b := newBuilder(c, mem)
+	defer b.Release()
+	b.Reserve(int(md.NumValues))
+
+	var dict *values
+	r := bytes.NewReader(data)
+	for read := int64(0); read < md.NumValues; {
+		var h pageHeader
+		if err := h.read(newThriftReader(r)); err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "failed to read page header of parquet column %q", c.Name)
+		}
+		if int64(h.CompressedPageSize) > int64(r.Len()) || h.CompressedPageSize < 0 {
+			return nil, errors.Newf(codes.Invalid, "invalid page size in parquet column %q", c.Name)
+		}
+		page := data[len(data)-r.Len():][:h.CompressedPageSize]
+		_, _ = r.Seek(int64(h.CompressedPageSize), io.SeekCurrent)
+
+		switch h.Type {
+		case pageTypeDictionary:
+			page, err := decompress(md.Codec, page, int(h.UncompressedPageSize))
+			if err != nil {
+				return nil, err
+			}
+			n := 0
+			if h.DictionaryPageHeader != nil {
+				n = int(h.DictionaryPageHeader.NumValues)
+			}
+			v, err := decodePlain(page, c.typ, n, c.unsigned)
+			if err != nil {
+				return nil, err
+			}
+			dict = &v
+		case pageTypeData, pageTypeDataV2:
+			n, err := c.readDataPage(b, &h, md.Codec, page, dict)
+			if err != nil {
+				return nil, errors.Wrapf(err, codes.Inherit, "failed to read parquet column %q", c.Name)
+			}
+			read += int64(n)
+		default:
+			// Index pages are not needed.
+		}
+	}
+	return b.NewArray(), nil
+}
+
+// readDataPage decodes a data page and appends its values to the builder.
+// It returns the number of values in the page including nulls.
+func (c *Column) readDataPage(b array.Builder, h *pageHeader, codec int32, page []byte, dict *values) (int, error) {
+	var (
+		n        int
+		encoding int32
+		defs     []int32
+		err      error
+	)
+	if h.DataPageHeaderV2 != nil {
+		v2 := h.DataPageHeaderV2
+		n, encoding = int(v2.NumValues), v2.Encoding
+		levelsLen := int(v2.DefinitionLevelsByteLength) + int(v2.RepetitionLevelsByteLength)
+		if levelsLen > len(page) || v2.RepetitionLevelsByteLength < 0 || v2.DefinitionLevelsByteLength < 0 {
+			return 0, errors.New(codes.Invalid, "invalid level lengths")
+		}
+		if c.optional {
+			levels := page[v2.RepetitionLevelsByteLength:levelsLen]
+			if defs, err = decodeHybrid(levels, 1, n); err != nil {
+				return 0, err
+			}
+		}
+		page = page[levelsLen:]
+		if v2.IsCompressed {
+			if page, err = decompress(codec, page, int(h.UncompressedPageSize)-levelsLen); err != nil {
+				return 0, err
+			}
+		}
+	} else if h.DataPageHeader != nil {
+		n, encoding = int(h.DataPageHeader.NumValues), h.DataPageHeader.Encoding
+		if page, err = decompress(codec, page, int(h.UncompressedPageSize)); err != nil {
+			return 0, err
+		}
+		if c.optional {
+			if len(page) < 4 {
+				return 0, errors.New(codes.Invalid, "page is too short for definition levels")
+			}
+			l := binary.LittleEndian.Uint32(page)
+			if uint64(len(page)-4) < uint64(l) {
+				return 0, errors.New(codes.Invalid, "page is too short for definition levels")
+			}
+			if defs, err = decodeHybrid(page[4:4+l], 1, n); err != nil {
+				return 0, err
+			}
+			page = page[4+l:]
+		}
+	} else {
+		return 0, errors.New(codes.Invalid, "data page header is missing")
+	}
+
+	nonNull := n
+	if defs != nil {
+		nonNull = 0
+		for _, d := range defs {
+			if d != 0 {
+				nonNull++
+			}
+		}
+	}
+
+	var (
+		vs      values
+		indices []int32
+	)
+	switch encoding {
+	case encodingPlain:
+		if vs, err = decodePlain(page, c.typ, nonNull, c.unsigned); err != nil {
+			return 0, err
+		}
+	case encodingPlainDict, encodingRLEDictionary:
+		if dict == nil {
+			return 0, errors.New(codes.Invalid, "dictionary page is missing")
+		}
+		if len(page) < 1 {
+			return 0, errors.New(codes.Invalid, "page is too short for dictionary indices")
+		}
+		if indices, err = decodeHybrid(page[1:], int(page[0]), nonNull); err != nil {
+			return 0, err
+		}
+		for _, idx := range indices {
+			if idx < 0 || int(idx) >= dict.len() {
+				return 0, errors.Newf(codes.Invalid, "dictionary index %d is out of range", idx)
+			}
+		}
+		vs = *dict
+	case encodingRLE:
+		if c.typ != Boolean {
+			return 0, errors.Newf(codes.Unimplemented, "unsupported encoding %d for type %s", encoding, c.typ)
+		}
+		// Boolean values are prefixed by their length.
+		if len(page) < 4 {
+			return 0, errors.New(codes.Invalid, "page is too short for boolean values")
+		}
+		bits, err := decodeHybrid(page[4:], 1, nonNull)
+		if err != nil {
+			return 0, err
+		}
+		vs.bools = make([]bool, len(bits))
+		for i, bit := range bits {
+			vs.bools[i] = bit != 0
+		}
+	default:
+		return 0, errors.Newf(codes.Unimplemented, "unsupported encoding %d", encoding)
+	}
+
+	next := 0
+	for i := 0; i < n; i++ {
+		if defs != nil && defs[i] == 0 {
+			b.AppendNull()
+			continue
+		}
+		idx := next
+		if indices != nil {
+			idx = int(indices[next])
+		}
+		next++
+		c.appendValue(b, &vs, idx)
+	}
+	return n, nil
+}
+
+func newBuilder(c *Column, mem memory.Allocator) array.Builder {
+	return arrow.NewBuilder(c.Type, mem)
+}
+
+// appendValue appends the value at index i to the builder.
+func (c *Column) appendValue(b array.Builder, vs *values, i int) {
+	switch c.Type {
+	case flux.TInt:
+		b.(*array.Int64Builder).Append(vs.ints[i])
+	case flux.TTime:
+		b.(*array.Int64Builder).Append(vs.ints[i] * c.scale)
+	case flux.TUInt:
+		b.(*array.Uint64Builder).Append(uint64(vs.ints[i]))
+	case flux.TFloat:
+		b.(*array.Float64Builder).Append(vs.floats[i])
+	case flux.TString:
+		b.(*array.BinaryBuilder).Append(vs.bytes[i])
+	case flux.TBool:
+		b.(*array.BooleanBuilder).Append(vs.bools[i])
+	}
+}
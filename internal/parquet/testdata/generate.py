@@ -0,0 +1,41 @@
+#!/usr/bin/env python3
+"""Generates the reference parquet files read by TestOpen_ReferenceFiles.
+
+The files are written with pyarrow so that the reader is tested against
+files produced by another implementation. Run it from this directory:
+
+    pip install pyarrow
+    python3 generate.py
+
+The values must match the ones expected by the test in parquet_test.go.
+"""
+
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+table = pa.table({
+    "time": pa.array([10, 20, 30, 40], type=pa.timestamp("ns", tz="UTC")),
+    "value": pa.array([1.5, None, -2.0, 4.0], type=pa.float64()),
+    "host": pa.array(["a", "b", None, "a"], type=pa.string()),
+    "ok": pa.array([True, None, False, True], type=pa.bool_()),
+    "n": pa.array([None, 2, 1 << 63, 4], type=pa.uint64()),
+    "i": pa.array([-1, 0, None, 1], type=pa.int32()),
+})
+
+# Plain encoded values in snappy compressed pages.
+pq.write_table(table, "snappy_plain.parquet",
+               compression="snappy", use_dictionary=False)
+
+# Dictionary encoded values in version 1 data pages.
+pq.write_table(table, "snappy_dictionary.parquet",
+               compression="snappy", use_dictionary=True,
+               data_page_version="1.0")
+
+# Dictionary encoded values in version 2 data pages.
+pq.write_table(table, "snappy_dictionary_v2.parquet",
+               compression="snappy", use_dictionary=True,
+               data_page_version="2.0")
+
+# Uncompressed pages split into two row groups.
+pq.write_table(table, "uncompressed.parquet",
+               compression="none", row_group_size=2)
@@ -0,0 +1,341 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// Types of the thrift compact protocol.
+const (
+	typeStop         = 0
+	typeBooleanTrue  = 1
+	typeBooleanFalse = 2
+	typeByte         = 3
+	typeI16          = 4
+	typeI32          = 5
+	typeI64          = 6
+	typeDouble       = 7
+	typeBinary       = 8
+	typeList         = 9
+	typeSet          = 10
+	typeMap          = 11
+	typeStruct       = 12
+)
+
+// thriftReader decodes values written with the thrift compact protocol.
+// Only the parts of the protocol used by the parquet metadata are supported.
+type thriftReader struct {
+	r       io.ByteReader
+	lastIDs []int16
+	lastID  int16
+	// boolValue is the value of a boolean field,
+	// which is stored in the field header.
+	boolValue bool
+}
+
+func newThriftReader(r io.ByteReader) *thriftReader {
+	return &thriftReader{r: r}
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+func (r *thriftReader) readVarint() (uint64, error) {
+	v, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	v, err := r.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	v, err := r.readI64()
+	return int32(v), err
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readVarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > math.MaxInt32 {
+		return nil, errors.Newf(codes.Invalid, "invalid binary length %d", n)
+	}
+	b := make([]byte, n)
+	for i := range b {
+		if b[i], err = r.readByte(); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (r *thriftReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+func (r *thriftReader) readBool() bool {
+	return r.boolValue
+}
+
+// structBegin must be called before the fields of a struct are read.
+func (r *thriftReader) structBegin() {
+	r.lastIDs = append(r.lastIDs, r.lastID)
+	r.lastID = 0
+}
+
+// structEnd must be called after the stop field of a struct has been read.
+func (r *thriftReader) structEnd() {
+	r.lastID = r.lastIDs[len(r.lastIDs)-1]
+	r.lastIDs = r.lastIDs[:len(r.lastIDs)-1]
+}
+
+// readFieldHeader reads the header of the next field of a struct.
+// It returns a field type of typeStop when there are no more fields.
+func (r *thriftReader) readFieldHeader() (id int16, typ byte, err error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	typ = b & 0x0f
+	if typ == typeStop {
+		return 0, typ, nil
+	}
+	if delta := int16(b >> 4); delta != 0 {
+		id = r.lastID + delta
+	} else {
+		v, err := r.readI64()
+		if err != nil {
+			return 0, 0, err
+		}
+		id = int16(v)
+	}
+	r.lastID = id
+
+	switch typ {
+	case typeBooleanTrue:
+		r.boolValue = true
+	case typeBooleanFalse:
+		r.boolValue = false
+	}
+	return id, typ, nil
+}
+
+// readListHeader reads the header of a list and
+// returns the type of the elements and the size.
+func (r *thriftReader) readListHeader() (typ byte, size int, err error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	typ, n := b&0x0f, uint64(b>>4)
+	if n == 0x0f {
+		if n, err = r.readVarint(); err != nil {
+			return 0, 0, err
+		}
+	}
+	if n > math.MaxInt32 {
+		return 0, 0, errors.Newf(codes.Invalid, "invalid list size %d", n)
+	}
+	return typ, int(n), nil
+}
+
+// readStruct reads a struct by calling field for each field.
+// The field function must read the value of the field
+// or return false so that it is skipped.
+func (r *thriftReader) readStruct(field func(id int16, typ byte) (bool, error)) error {
+	r.structBegin()
+	for {
+		id, typ, err := r.readFieldHeader()
+		if err != nil {
+			return err
+		} else if typ == typeStop {
+			break
+		}
+		if ok, err := field(id, typ); err != nil {
+			return err
+		} else if !ok {
+			if err := r.skip(typ); err != nil {
+				return err
+			}
+		}
+	}
+	r.structEnd()
+	return nil
+}
+
+// readList reads the header of a list and calls elem for each element.
+func (r *thriftReader) readList(elem func(typ byte) error) error {
+	typ, n, err := r.readListHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := elem(typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip reads a value of the given type and discards it.
+func (r *thriftReader) skip(typ byte) error {
+	switch typ {
+	case typeBooleanTrue, typeBooleanFalse:
+		return nil
+	case typeByte:
+		_, err := r.readByte()
+		return err
+	case typeI16, typeI32, typeI64:
+		_, err := r.readVarint()
+		return err
+	case typeDouble:
+		for i := 0; i < 8; i++ {
+			if _, err := r.readByte(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case typeBinary:
+		_, err := r.readBinary()
+		return err
+	case typeList, typeSet:
+		return r.readList(func(typ byte) error {
+			if typ == typeBooleanTrue || typ == typeBooleanFalse {
+				// Booleans in lists are stored as a byte.
+				_, err := r.readByte()
+				return err
+			}
+			return r.skip(typ)
+		})
+	case typeMap:
+		n, err := r.readVarint()
+		if err != nil || n == 0 {
+			return err
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := r.skip(types >> 4); err != nil {
+				return err
+			}
+			if err := r.skip(types & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case typeStruct:
+		return r.readStruct(func(int16, byte) (bool, error) {
+			return false, nil
+		})
+	default:
+		return errors.Newf(codes.Invalid, "unknown thrift type %d", typ)
+	}
+}
+
+// thriftWriter encodes values with the thrift compact protocol.
+type thriftWriter struct {
+	buf     []byte
+	lastIDs []int16
+	lastID  int16
+}
+
+func (w *thriftWriter) writeVarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	w.buf = append(w.buf, b[:n]...)
+}
+
+func (w *thriftWriter) writeI64(v int64) {
+	w.writeVarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (w *thriftWriter) writeBinary(b []byte) {
+	w.writeVarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.writeI64(int64(id))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.fieldHeader(id, typeI32)
+	w.writeI64(int64(v))
+}
+
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.fieldHeader(id, typeI64)
+	w.writeI64(v)
+}
+
+func (w *thriftWriter) binaryField(id int16, b []byte) {
+	w.fieldHeader(id, typeBinary)
+	w.writeBinary(b)
+}
+
+func (w *thriftWriter) stringField(id int16, s string) {
+	w.binaryField(id, []byte(s))
+}
+
+func (w *thriftWriter) boolField(id int16, v bool) {
+	if v {
+		w.fieldHeader(id, typeBooleanTrue)
+	} else {
+		w.fieldHeader(id, typeBooleanFalse)
+	}
+}
+
+// structField writes a field holding a struct whose fields are written by fields.
+func (w *thriftWriter) structField(id int16, fields func()) {
+	w.fieldHeader(id, typeStruct)
+	w.writeStruct(fields)
+}
+
+// writeStruct writes the fields of a struct followed by the stop field.
+func (w *thriftWriter) writeStruct(fields func()) {
+	w.lastIDs = append(w.lastIDs, w.lastID)
+	w.lastID = 0
+	fields()
+	w.buf = append(w.buf, typeStop)
+	w.lastID = w.lastIDs[len(w.lastIDs)-1]
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+// listField writes a field holding a list of n elements of the given type.
+// The elements must be written by the caller after this returns.
+func (w *thriftWriter) listField(id int16, typ byte, n int) {
+	w.fieldHeader(id, typeList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|typ)
+	} else {
+		w.buf = append(w.buf, 0xf0|typ)
+		w.writeVarint(uint64(n))
+	}
+}
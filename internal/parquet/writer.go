@@ -0,0 +1,361 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/golang/snappy"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// Writer writes flux columns to a parquet file.
+//
+// Each call to WriteRowGroup writes a row group. The schema of the
+// file is the union of the columns of all of the row groups and the
+// columns that are missing from a row group are written as nulls.
+// All of the columns are optional and are written with the plain
+// encoding and snappy compression.
+type Writer struct {
+	w       io.Writer
+	offset  int64
+	columns []Column
+	index   map[string]int
+	groups  []rowGroup
+}
+
+// NewWriter creates a Writer that writes a parquet file to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:     w,
+		index: make(map[string]int),
+	}
+}
+
+func (w *Writer) write(p []byte) error {
+	if w.offset == 0 {
+		n, err := io.WriteString(w.w, fileMagic)
+		w.offset += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return err
+}
+
+// WriteRowGroup writes a row group with the given columns.
+// All of the arrays must have the same length.
+func (w *Writer) WriteRowGroup(cols []flux.ColMeta, arrs []array.Interface) error {
+	n := 0
+	if len(arrs) > 0 {
+		n = arrs[0].Len()
+	}
+	for i, col := range cols {
+		if arrs[i].Len() != n {
+			return errors.Newf(codes.Internal, "column %q has %d values instead of %d", col.Label, arrs[i].Len(), n)
+		}
+		if j, ok := w.index[col.Label]; ok {
+			if w.columns[j].Type != col.Type {
+				return errors.Newf(codes.Invalid, "column %q has type %s and %s", col.Label, w.columns[j].Type, col.Type)
+			}
+			continue
+		}
+		w.index[col.Label] = len(w.columns)
+		w.columns = append(w.columns, Column{
+			Name:     col.Label,
+			Type:     col.Type,
+			typ:      physicalType(col.Type),
+			optional: true,
+		})
+	}
+
+	g := rowGroup{
+		Columns: make([]columnChunk, len(w.columns)),
+		NumRows: int64(n),
+	}
+	for i, col := range cols {
+		j := w.index[col.Label]
+		if g.Columns[j].MetaData != nil {
+			return errors.Newf(codes.Invalid, "duplicate column %q", col.Label)
+		}
+		if err := w.writeChunk(&g.Columns[j], &w.columns[j], arrs[i], n); err != nil {
+			return err
+		}
+		g.TotalByteSize += g.Columns[j].MetaData.TotalUncompressedSize
+	}
+	w.groups = append(w.groups, g)
+	return nil
+}
+
+// Close writes the columns that are missing from the row groups
+// and the metadata of the file. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	for i := range w.groups {
+		g := &w.groups[i]
+		for len(g.Columns) < len(w.columns) {
+			g.Columns = append(g.Columns, columnChunk{})
+		}
+		for j := range g.Columns {
+			if g.Columns[j].MetaData != nil {
+				continue
+			}
+			if err := w.writeChunk(&g.Columns[j], &w.columns[j], nil, int(g.NumRows)); err != nil {
+				return err
+			}
+			g.TotalByteSize += g.Columns[j].MetaData.TotalUncompressedSize
+		}
+	}
+
+	meta := fileMetaData{
+		Version:   1,
+		RowGroups: w.groups,
+		CreatedBy: defaultCreatedByString,
+	}
+	meta.Schema = append(meta.Schema, schemaElement{
+		Name:        "schema",
+		NumChildren: int32(len(w.columns)),
+	})
+	for _, c := range w.columns {
+		meta.Schema = append(meta.Schema, c.schemaElement())
+	}
+	for _, g := range w.groups {
+		meta.NumRows += g.NumRows
+	}
+
+	var tw thriftWriter
+	meta.write(&tw)
+	buf := tw.buf
+	buf = append(buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], uint32(len(tw.buf)))
+	buf = append(buf, fileMagic...)
+	return w.write(buf)
+}
+
+func physicalType(typ flux.ColType) Type {
+	switch typ {
+	case flux.TFloat:
+		return Double
+	case flux.TString:
+		return ByteArray
+	case flux.TBool:
+		return Boolean
+	default:
+		return Int64
+	}
+}
+
+func (c *Column) schemaElement() schemaElement {
+	typ, rep := c.typ, Optional
+	elem := schemaElement{
+		Type:       &typ,
+		Repetition: &rep,
+		Name:       c.Name,
+	}
+	var ct ConvertedType = -1
+	switch c.Type {
+	case flux.TTime:
+		elem.LogicalType = &LogicalType{Timestamp: Nanos, IsAdjustedToUTC: true}
+	case flux.TUInt:
+		elem.LogicalType = &LogicalType{Integer: true, BitWidth: 64}
+		ct = ConvertedUint64
+	case flux.TString:
+		elem.LogicalType = &LogicalType{String: true}
+		ct = ConvertedUTF8
+	}
+	if ct >= 0 {
+		elem.ConvertedType = &ct
+	}
+	return elem
+}
+
+// writeChunk writes the values of a column as a single data page.
+// A nil array is written as n nulls.
+func (w *Writer) writeChunk(chunk *columnChunk, c *Column, arr array.Interface, n int) error {
+	defs := make([]int32, n)
+	nulls := int64(n)
+	if arr != nil {
+		for i := range defs {
+			if arr.IsValid(i) {
+				defs[i] = 1
+			}
+		}
+		nulls = int64(arr.NullN())
+	}
+
+	page := make([]byte, 4)
+	page = encodeHybrid(page, defs, 1)
+	binary.LittleEndian.PutUint32(page, uint32(len(page)-4))
+	var stats statistics
+	if arr != nil {
+		page = encodeValues(page, &stats, arr)
+	}
+	stats.NullCount = &nulls
+	compressed := snappy.Encode(nil, page)
+
+	h := pageHeader{
+		Type:                 pageTypeData,
+		UncompressedPageSize: int32(len(page)),
+		CompressedPageSize:   int32(len(compressed)),
+		DataPageHeader: &dataPageHeader{
+			NumValues:               int32(n),
+			Encoding:                encodingPlain,
+			DefinitionLevelEncoding: encodingRLE,
+			RepetitionLevelEncoding: encodingRLE,
+		},
+	}
+	var tw thriftWriter
+	h.write(&tw)
+
+	if w.offset == 0 {
+		// Write the magic number so the offset is correct.
+		if err := w.write(nil); err != nil {
+			return err
+		}
+	}
+	offset := w.offset
+	if err := w.write(tw.buf); err != nil {
+		return err
+	}
+	if err := w.write(compressed); err != nil {
+		return err
+	}
+
+	chunk.FileOffset = offset
+	chunk.MetaData = &columnMetaData{
+		Type:                  c.typ,
+		Encodings:             []int32{encodingPlain, encodingRLE},
+		PathInSchema:          []string{c.Name},
+		Codec:                 codecSnappy,
+		NumValues:             int64(n),
+		TotalUncompressedSize: int64(len(tw.buf) + len(page)),
+		TotalCompressedSize:   int64(len(tw.buf) + len(compressed)),
+		DataPageOffset:        offset,
+		Statistics:            &stats,
+	}
+	return nil
+}
+
+// encodeValues appends the valid values of the array with the
+// plain encoding and records their minimum and maximum.
+func encodeValues(buf []byte, stats *statistics, arr array.Interface) []byte {
+	var tmp [8]byte
+	switch a := arr.(type) {
+	case *array.Int64:
+		var min, max int64
+		first := true
+		for i := 0; i < a.Len(); i++ {
+			if a.IsNull(i) {
+				continue
+			}
+			v := a.Value(i)
+			binary.LittleEndian.PutUint64(tmp[:], uint64(v))
+			buf = append(buf, tmp[:]...)
+			if first || v < min {
+				min = v
+			}
+			if first || v > max {
+				max = v
+			}
+			first = false
+		}
+		if !first {
+			stats.MinValue = appendUint64(nil, uint64(min))
+			stats.MaxValue = appendUint64(nil, uint64(max))
+		}
+	case *array.Uint64:
+		var min, max uint64
+		first := true
+		for i := 0; i < a.Len(); i++ {
+			if a.IsNull(i) {
+				continue
+			}
+			v := a.Value(i)
+			binary.LittleEndian.PutUint64(tmp[:], v)
+			buf = append(buf, tmp[:]...)
+			if first || v < min {
+				min = v
+			}
+			if first || v > max {
+				max = v
+			}
+			first = false
+		}
+		if !first {
+			stats.MinValue = appendUint64(nil, min)
+			stats.MaxValue = appendUint64(nil, max)
+		}
+	case *array.Float64:
+		min, max := math.Inf(1), math.Inf(-1)
+		for i := 0; i < a.Len(); i++ {
+			if a.IsNull(i) {
+				continue
+			}
+			v := a.Value(i)
+			buf = appendUint64(buf, math.Float64bits(v))
+			// NaN values are excluded from the statistics.
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if min <= max {
+			stats.MinValue = appendUint64(nil, math.Float64bits(min))
+			stats.MaxValue = appendUint64(nil, math.Float64bits(max))
+		}
+	case *array.Binary:
+		var min, max []byte
+		first := true
+		for i := 0; i < a.Len(); i++ {
+			if a.IsNull(i) {
+				continue
+			}
+			v := a.Value(i)
+			binary.LittleEndian.PutUint32(tmp[:4], uint32(len(v)))
+			buf = append(buf, tmp[:4]...)
+			buf = append(buf, v...)
+			if first || string(v) < string(min) {
+				min = v
+			}
+			if first || string(v) > string(max) {
+				max = v
+			}
+			first = false
+		}
+		if !first {
+			stats.MinValue = append([]byte{}, min...)
+			stats.MaxValue = append([]byte{}, max...)
+		}
+	case *array.Boolean:
+		var bits byte
+		n := 0
+		for i := 0; i < a.Len(); i++ {
+			if a.IsNull(i) {
+				continue
+			}
+			if a.Value(i) {
+				bits |= 1 << uint(n%8)
+			}
+			if n++; n%8 == 0 {
+				buf = append(buf, bits)
+				bits = 0
+			}
+		}
+		if n%8 != 0 {
+			buf = append(buf, bits)
+		}
+	}
+	return buf
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
@@ -0,0 +1,141 @@
+package sketch
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+const (
+	// MinPrecision and MaxPrecision bound the number of bits
+	// of a hash that select the register of a HyperLogLog.
+	MinPrecision = 4
+	MaxPrecision = 18
+
+	// DefaultPrecision uses 16384 registers for a
+	// standard error of about 0.8%.
+	DefaultPrecision = 14
+)
+
+// HyperLogLog estimates the number of distinct values it has seen.
+//
+// The first precision bits of the 64 bit hash of a value select one
+// of 2^precision registers and the register keeps the largest number
+// of leading zeros seen in the remaining bits. The standard error of
+// the estimate is 1.04/sqrt(2^precision).
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog returns an empty HyperLogLog with the precision.
+func NewHyperLogLog(precision int) (*HyperLogLog, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, errors.Newf(codes.Invalid, "precision must be between %d and %d, got %d", MinPrecision, MaxPrecision, precision)
+	}
+	return &HyperLogLog{
+		precision: uint8(precision),
+		registers: make([]uint8, 1<<uint(precision)),
+	}, nil
+}
+
+// Precision returns the number of bits that select a register.
+func (h *HyperLogLog) Precision() int {
+	return int(h.precision)
+}
+
+// Reset removes all the values from the HyperLogLog.
+func (h *HyperLogLog) Reset() {
+	for i := range h.registers {
+		h.registers[i] = 0
+	}
+}
+
+// Add adds the value with the 64 bit hash to the HyperLogLog.
+func (h *HyperLogLog) Add(hash uint64) {
+	idx := hash >> (64 - h.precision)
+	// The bit after the remaining bits bounds the number of
+	// leading zeros when all of the remaining bits are zero.
+	w := hash<<h.precision | 1<<(h.precision-1)
+	if rho := uint8(bits.LeadingZeros64(w)) + 1; rho > h.registers[idx] {
+		h.registers[idx] = rho
+	}
+}
+
+// Merge adds the values of o to h. When the precisions differ,
+// the result has the lower of the two precisions.
+func (h *HyperLogLog) Merge(o *HyperLogLog) {
+	if o.precision < h.precision {
+		*h = *h.reduce(o.precision)
+	} else if o.precision > h.precision {
+		o = o.reduce(h.precision)
+	}
+	for i, r := range o.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+}
+
+// reduce returns a copy of the HyperLogLog with a lower precision.
+// The bits of a register index that no longer select the register
+// become the first bits of the hash that the leading zeros are
+// counted in.
+func (h *HyperLogLog) reduce(precision uint8) *HyperLogLog {
+	shift := h.precision - precision
+	r := &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+	for i, v := range h.registers {
+		if v == 0 {
+			continue
+		}
+		rest := uint64(i) & (1<<shift - 1)
+		rho := v + shift
+		if rest != 0 {
+			rho = uint8(bits.LeadingZeros64(rest<<(64-shift))) + 1
+		}
+		if j := i >> shift; rho > r.registers[j] {
+			r.registers[j] = rho
+		}
+	}
+	return r
+}
+
+// Count returns the estimated number of distinct values.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	var (
+		sum   float64
+		zeros int
+	)
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := alpha(len(h.registers)) * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities.
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
+
+// alpha is the bias correction constant for m registers.
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
@@ -0,0 +1,88 @@
+package sketch_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/influxdata/flux/internal/sketch"
+)
+
+func newHyperLogLog(t *testing.T, precision, from, to int) *sketch.HyperLogLog {
+	t.Helper()
+	h, err := sketch.NewHyperLogLog(precision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := from; i < to; i++ {
+		h.Add(xxhash.Sum64String(strconv.Itoa(i)))
+	}
+	return h
+}
+
+// checkCount checks that the estimate is within four standard errors.
+func checkCount(t *testing.T, h *sketch.HyperLogLog, want int) {
+	t.Helper()
+	m := math.Ldexp(1, h.Precision())
+	tolerance := 4 * 1.04 / math.Sqrt(m) * float64(want)
+	if got := h.Count(); math.Abs(float64(got)-float64(want)) > tolerance+1 {
+		t.Errorf("unexpected count with precision %d: want %d±%.0f, got %d", h.Precision(), want, tolerance, got)
+	}
+}
+
+func TestHyperLogLog_Count(t *testing.T) {
+	for _, precision := range []int{sketch.MinPrecision, 10, sketch.DefaultPrecision, sketch.MaxPrecision} {
+		for _, n := range []int{0, 1, 10, 1000, 100000} {
+			checkCount(t, newHyperLogLog(t, precision, 0, n), n)
+		}
+	}
+}
+
+func TestHyperLogLog_Duplicates(t *testing.T) {
+	h := newHyperLogLog(t, sketch.DefaultPrecision, 0, 1000)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 1000; j++ {
+			h.Add(xxhash.Sum64String(strconv.Itoa(j)))
+		}
+	}
+	checkCount(t, h, 1000)
+}
+
+func TestHyperLogLog_Merge(t *testing.T) {
+	h := newHyperLogLog(t, sketch.DefaultPrecision, 0, 60000)
+	h.Merge(newHyperLogLog(t, sketch.DefaultPrecision, 40000, 100000))
+	checkCount(t, h, 100000)
+}
+
+func TestHyperLogLog_MergePrecisions(t *testing.T) {
+	// Merging sketches of different precisions gives the sketch
+	// of the lower precision that has seen all of the values.
+	want := newHyperLogLog(t, 10, 0, 50000)
+
+	for _, order := range []string{"low into high", "high into low"} {
+		low := newHyperLogLog(t, 10, 0, 30000)
+		high := newHyperLogLog(t, 16, 20000, 50000)
+		got := low
+		if order == "low into high" {
+			high.Merge(low)
+			got = high
+		} else {
+			low.Merge(high)
+		}
+		if got.Precision() != 10 {
+			t.Fatalf("%s: unexpected precision: want 10, got %d", order, got.Precision())
+		}
+		if want, got := want.Count(), got.Count(); want != got {
+			t.Errorf("%s: unexpected count: want %d, got %d", order, want, got)
+		}
+	}
+}
+
+func TestNewHyperLogLog_InvalidPrecision(t *testing.T) {
+	for _, precision := range []int{sketch.MinPrecision - 1, sketch.MaxPrecision + 1} {
+		if _, err := sketch.NewHyperLogLog(precision); err == nil {
+			t.Errorf("expected an error for precision %d", precision)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Package sketch implements the probabilistic data structures that
+// aggregates can emit instead of their estimate so that the estimate
+// can be computed later from several merged sketches.
+//
+// Sketches are serialized into strings so that they can be stored
+// in a table column and written to and read from a storage engine.
+package sketch
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/tdigest"
+)
+
+// version is the version of the serialization format.
+const version = 1
+
+// The kinds of sketches.
+const (
+	KindHyperLogLog = "hll"
+	KindTDigest     = "tdigest"
+)
+
+// The tags that identify the kind of a serialized sketch.
+const (
+	tagHyperLogLog = 'H'
+	tagTDigest     = 'T'
+)
+
+// The representations of the registers of a serialized HyperLogLog.
+const (
+	registersDense  = 0
+	registersSparse = 1
+)
+
+// Sketch is a summary of values that can be merged
+// with other sketches of the same kind.
+type Sketch interface {
+	// Kind returns the kind of the sketch.
+	Kind() string
+
+	appendBinary(b []byte) []byte
+}
+
+// Kind returns the kind of the sketch.
+func (h *HyperLogLog) Kind() string {
+	return KindHyperLogLog
+}
+
+func (h *HyperLogLog) appendBinary(b []byte) []byte {
+	b = append(b, tagHyperLogLog, h.precision)
+	nonzero := 0
+	for _, r := range h.registers {
+		if r != 0 {
+			nonzero++
+		}
+	}
+	// A sparse register takes at most 4 bytes.
+	if nonzero*4 >= len(h.registers) {
+		b = append(b, registersDense)
+		return append(b, h.registers...)
+	}
+	b = append(b, registersSparse)
+	b = appendUvarint(b, uint64(nonzero))
+	last := 0
+	for i, r := range h.registers {
+		if r == 0 {
+			continue
+		}
+		b = appendUvarint(b, uint64(i-last))
+		b = append(b, r)
+		last = i
+	}
+	return b
+}
+
+// TDigest is a t-digest together with the quantile it estimates.
+type TDigest struct {
+	Digest      *tdigest.TDigest
+	Compression float64
+	Quantile    float64
+}
+
+// NewTDigest returns an empty t-digest that estimates the quantile q.
+func NewTDigest(compression, q float64) *TDigest {
+	return &TDigest{
+		Digest:      tdigest.NewWithCompression(compression),
+		Compression: compression,
+		Quantile:    q,
+	}
+}
+
+// Kind returns the kind of the sketch.
+func (t *TDigest) Kind() string {
+	return KindTDigest
+}
+
+func (t *TDigest) appendBinary(b []byte) []byte {
+	b = append(b, tagTDigest)
+	b = appendFloat(b, t.Compression)
+	b = appendFloat(b, t.Quantile)
+	centroids := t.Digest.Centroids(nil)
+	b = appendUvarint(b, uint64(len(centroids)))
+	for _, c := range centroids {
+		b = appendFloat(b, c.Mean)
+		b = appendFloat(b, c.Weight)
+	}
+	return b
+}
+
+// Merge adds the values summarized by src to dst.
+// The sketches must be of the same kind.
+func Merge(dst, src Sketch) error {
+	switch dst := dst.(type) {
+	case *HyperLogLog:
+		if src, ok := src.(*HyperLogLog); ok {
+			dst.Merge(src)
+			return nil
+		}
+	case *TDigest:
+		if src, ok := src.(*TDigest); ok {
+			dst.Digest.Merge(src.Digest)
+			return nil
+		}
+	}
+	return errors.Newf(codes.Invalid, "cannot merge a %s sketch into a %s sketch", src.Kind(), dst.Kind())
+}
+
+// Marshal serializes the sketch into a string.
+func Marshal(s Sketch) string {
+	b := s.appendBinary([]byte{version})
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Unmarshal reads a sketch that was serialized with Marshal.
+func Unmarshal(s string) (Sketch, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid sketch")
+	}
+	r := &reader{b: b}
+	if v := r.byte(); v != version {
+		return nil, errors.Newf(codes.Invalid, "invalid sketch: unsupported version %d", v)
+	}
+	var sk Sketch
+	switch tag := r.byte(); tag {
+	case tagHyperLogLog:
+		sk, err = r.hyperLogLog()
+	case tagTDigest:
+		sk, err = r.tdigest()
+	default:
+		return nil, errors.Newf(codes.Invalid, "invalid sketch: unknown kind %q", tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.b) > 0 {
+		return nil, errors.New(codes.Invalid, "invalid sketch: unexpected trailing data")
+	}
+	return sk, nil
+}
+
+// reader reads the fields of a serialized sketch. Once a read
+// fails, the following reads return zero values and err is set.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) fail() {
+	if r.err == nil {
+		r.err = errors.New(codes.Invalid, "invalid sketch: unexpected end of data")
+	}
+	r.b = nil
+}
+
+func (r *reader) byte() byte {
+	if len(r.b) < 1 {
+		r.fail()
+		return 0
+	}
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+func (r *reader) bytes(n int) []byte {
+	if n < 0 || len(r.b) < n {
+		r.fail()
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) float() float64 {
+	b := r.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (r *reader) hyperLogLog() (*HyperLogLog, error) {
+	precision := r.byte()
+	if r.err != nil {
+		return nil, r.err
+	}
+	h, err := NewHyperLogLog(int(precision))
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid sketch")
+	}
+	switch repr := r.byte(); repr {
+	case registersDense:
+		copy(h.registers, r.bytes(len(h.registers)))
+	case registersSparse:
+		n := r.uvarint()
+		if n > uint64(len(h.registers)) {
+			return nil, errors.New(codes.Invalid, "invalid sketch: too many registers")
+		}
+		idx := uint64(0)
+		for i := uint64(0); i < n && r.err == nil; i++ {
+			idx += r.uvarint()
+			v := r.byte()
+			if idx >= uint64(len(h.registers)) {
+				return nil, errors.New(codes.Invalid, "invalid sketch: register out of range")
+			}
+			h.registers[idx] = v
+		}
+	default:
+		return nil, errors.Newf(codes.Invalid, "invalid sketch: unknown register representation %d", repr)
+	}
+	maxRho := uint8(64 - int(h.precision) + 1)
+	for _, v := range h.registers {
+		if v > maxRho {
+			return nil, errors.New(codes.Invalid, "invalid sketch: register out of range")
+		}
+	}
+	return h, nil
+}
+
+func (r *reader) tdigest() (*TDigest, error) {
+	compression := r.float()
+	q := r.float()
+	n := r.uvarint()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !(compression > 0) || math.IsInf(compression, 1) {
+		return nil, errors.Newf(codes.Invalid, "invalid sketch: invalid compression %v", compression)
+	}
+	if !(q >= 0 && q <= 1) {
+		return nil, errors.Newf(codes.Invalid, "invalid sketch: invalid quantile %v", q)
+	}
+	// A centroid takes 16 bytes.
+	if n > uint64(len(r.b)/16) {
+		r.fail()
+		return nil, r.err
+	}
+	t := NewTDigest(compression, q)
+	centroids := make(tdigest.CentroidList, n)
+	for i := range centroids {
+		centroids[i].Mean = r.float()
+		centroids[i].Weight = r.float()
+	}
+	t.Digest.AddCentroidList(centroids)
+	return t, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendFloat(b []byte, v float64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,93 @@
+package sketch_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/influxdata/flux/internal/sketch"
+)
+
+func TestMarshal_HyperLogLog(t *testing.T) {
+	// A sketch with few values uses the sparse representation
+	// and one with many values uses the dense representation.
+	for _, n := range []int{0, 10, 100000} {
+		h := newHyperLogLog(t, sketch.DefaultPrecision, 0, n)
+		s, err := sketch.Unmarshal(sketch.Marshal(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := s.(*sketch.HyperLogLog)
+		if !ok {
+			t.Fatalf("unexpected sketch type %T", s)
+		}
+		if got.Precision() != h.Precision() {
+			t.Errorf("unexpected precision: want %d, got %d", h.Precision(), got.Precision())
+		}
+		if want, got := h.Count(), got.Count(); want != got {
+			t.Errorf("unexpected count: want %d, got %d", want, got)
+		}
+	}
+}
+
+func TestMarshal_TDigest(t *testing.T) {
+	td := sketch.NewTDigest(100, 0.9)
+	for i := 1; i <= 1000; i++ {
+		td.Digest.Add(float64(i), 1)
+	}
+	s, err := sketch.Unmarshal(sketch.Marshal(td))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := s.(*sketch.TDigest)
+	if !ok {
+		t.Fatalf("unexpected sketch type %T", s)
+	}
+	if got.Compression != 100 || got.Quantile != 0.9 {
+		t.Errorf("unexpected parameters: want (100, 0.9), got (%v, %v)", got.Compression, got.Quantile)
+	}
+	if want, got := td.Digest.Quantile(0.9), got.Digest.Quantile(0.9); want != got {
+		t.Errorf("unexpected quantile: want %v, got %v", want, got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b := sketch.NewTDigest(1000, 0.5), sketch.NewTDigest(1000, 0.5)
+	for i := 0; i < 100; i++ {
+		a.Digest.Add(float64(i), 1)
+		b.Digest.Add(float64(i+100), 1)
+	}
+	if err := sketch.Merge(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 200.0, a.Digest.Count(); want != got {
+		t.Errorf("unexpected count: want %v, got %v", want, got)
+	}
+	if err := sketch.Merge(a, newHyperLogLog(t, sketch.DefaultPrecision, 0, 1)); err == nil {
+		t.Error("expected an error when merging sketches of different kinds")
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	valid := sketch.Marshal(newHyperLogLog(t, sketch.MinPrecision, 0, 1000))
+	b, _ := base64.StdEncoding.DecodeString(valid)
+	encode := base64.StdEncoding.EncodeToString
+	for name, s := range map[string]string{
+		"not base64":        "not a sketch!",
+		"empty":             "",
+		"unknown version":   encode([]byte{2, 'H'}),
+		"unknown kind":      encode([]byte{1, 'X'}),
+		"truncated":         encode(b[:len(b)-1]),
+		"trailing data":     encode(append(b[:len(b):len(b)], 0)),
+		"invalid register":  encode(append(b[:len(b)-1:len(b)-1], 255)),
+		"invalid precision": encode([]byte{1, 'H', 30, 0}),
+		"too many centroids": encode([]byte{1, 'T',
+			0, 0, 0, 0, 0, 0, 0x59, 0x40,
+			0, 0, 0, 0, 0, 0, 0xe0, 0x3f,
+			10,
+		}),
+	} {
+		if _, err := sketch.Unmarshal(s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
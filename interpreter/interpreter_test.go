@@ -867,8 +867,8 @@ func TestStack(t *testing.T) {
 			FunctionName: "window",
 			Location: ast.SourceLocation{
 				File:   "universe.flux",
-				Start:  ast.Position{Line: 224, Column: 8},
-				End:    ast.Position{Line: 224, Column: 47},
+				Start:  ast.Position{Line: 266, Column: 8},
+				End:    ast.Position{Line: 266, Column: 47},
 				Source: `window(every: inf, timeColumn: timeDst)`,
 			},
 		},
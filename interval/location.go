@@ -0,0 +1,174 @@
+package interval
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// UTC is the location of Coordinated Universal Time.
+var UTC = Location{Zone: "UTC"}
+
+// Location is a time zone from the tz database with an additional
+// offset applied to its clock. The zero value is UTC.
+type Location struct {
+	// Zone is the IANA name of the time zone, such as Europe/Paris.
+	Zone string `json:"zone"`
+	// Offset is added to the clock of the zone.
+	Offset values.Duration `json:"offset"`
+}
+
+// IsUTC reports whether the clock of the location is the clock of UTC.
+func (l Location) IsUTC() bool {
+	return (l.Zone == "" || l.Zone == "UTC") && l.Offset.IsZero()
+}
+
+// Validate checks that the zone exists and that the offset
+// is a duration that does not use months.
+func (l Location) Validate() error {
+	_, err := l.load()
+	return err
+}
+
+// Time returns t as read on the clock of the location.
+// The location of the returned time is UTC.
+func (l Location) Time(t values.Time) (time.Time, error) {
+	if l.IsUTC() {
+		return t.Time(), nil
+	}
+	z, err := l.load()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return z.clock(t).Time(), nil
+}
+
+var locations sync.Map
+
+// load returns the zone of the location. The zones of the
+// tz database are cached as they are read from the file system.
+func (l Location) load() (*zone, error) {
+	if l.Offset.Months() != 0 {
+		return nil, errors.Newf(codes.Invalid, "location offset %v cannot use months", l.Offset)
+	}
+	name := l.Zone
+	if name == "" {
+		name = "UTC"
+	}
+	loc, ok := locations.Load(name)
+	if !ok {
+		tz, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, errors.Newf(codes.Invalid, "unknown time zone %q", l.Zone)
+		}
+		loc, _ = locations.LoadOrStore(name, tz)
+	}
+	return &zone{
+		loc:    loc.(*time.Location),
+		offset: int64(l.Offset.Duration()),
+	}, nil
+}
+
+// day bounds the time around an instant where the offset of a zone
+// may change. Zones do not change their offset more than once a day.
+const day = int64(24 * time.Hour)
+
+// zone converts between instants and the clock of a location.
+//
+// The clock of a zone is not a monotonic function of time as it goes
+// back when daylight saving time ends. Windows are computed on a clock
+// that stops instead so that they remain ordered: the local times that
+// are repeated belong to their first occurrence and the second occurrence
+// is read as the last instant before the end of the repeated period.
+// The local times that are skipped when daylight saving time starts are
+// read as the instant where the clock changed.
+type zone struct {
+	loc    *time.Location
+	offset int64
+}
+
+// zoneOffset returns the offset of the zone at t in nanoseconds.
+func (z *zone) zoneOffset(t int64) int64 {
+	_, off := time.Unix(0, t).In(z.loc).Zone()
+	return int64(off) * int64(time.Second)
+}
+
+// clock returns the time read on the clock of the zone at t.
+func (z *zone) clock(t values.Time) values.Time {
+	return values.Time(add(add(int64(t), z.zoneOffset(int64(t))), z.offset))
+}
+
+// toLocal returns the time read on the stopped clock of the zone at t.
+func (z *zone) toLocal(t values.Time) values.Time {
+	u := int64(t)
+	off := z.zoneOffset(u)
+	local := add(u, off)
+	if prev := z.zoneOffset(add(u, -day)); prev > off {
+		// The clock went back during the last day. If the clock read the
+		// same time earlier, t is in the second occurrence of the local
+		// times that were repeated.
+		if earlier := local - prev; earlier < u && z.zoneOffset(earlier) == prev {
+			transition := z.transition(earlier, u)
+			local = transition + prev - 1
+		}
+	}
+	return values.Time(add(local, z.offset))
+}
+
+// fromLocal returns the instant where the stopped clock of the zone reads t.
+func (z *zone) fromLocal(t values.Time) values.Time {
+	local := add(int64(t), -z.offset)
+	before := z.zoneOffset(add(local, -day))
+	after := z.zoneOffset(add(local, day))
+	if before == after {
+		return values.Time(add(local, -before))
+	}
+	u1, u2 := add(local, -before), add(local, -after)
+	valid1 := z.zoneOffset(u1) == before
+	valid2 := z.zoneOffset(u2) == after
+	switch {
+	case valid1 && valid2:
+		// The local time is repeated.
+		if u1 < u2 {
+			return values.Time(u1)
+		}
+		return values.Time(u2)
+	case valid1:
+		return values.Time(u1)
+	case valid2:
+		return values.Time(u2)
+	default:
+		// The local time was skipped.
+		return values.Time(z.transition(u2, u1))
+	}
+}
+
+// transition returns the first instant in (lo, hi] where
+// the offset of the zone is the offset at hi.
+func (z *zone) transition(lo, hi int64) int64 {
+	off := z.zoneOffset(hi)
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if z.zoneOffset(mid) == off {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
+
+// add adds the nanoseconds and saturates at the bounds of time.
+func add(t, d int64) int64 {
+	if d > 0 && t > math.MaxInt64-d {
+		return math.MaxInt64
+	}
+	if d < 0 && t < math.MinInt64-d {
+		return math.MinInt64
+	}
+	return t + d
+}
@@ -0,0 +1,174 @@
+package interval_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/interval"
+	"github.com/influxdata/flux/values"
+)
+
+func TestWindowInLocation_GetLatestBounds(t *testing.T) {
+	paris := interval.Location{Zone: "Europe/Paris"}
+	testcases := []struct {
+		name      string
+		every     values.Duration
+		location  interval.Location
+		t         string
+		wantStart string
+		wantStop  string
+	}{
+		{
+			name:      "day",
+			every:     values.ConvertDurationNsecs(24 * time.Hour),
+			location:  paris,
+			t:         "2020-01-15T23:30:00Z",
+			wantStart: "2020-01-15T23:00:00Z",
+			wantStop:  "2020-01-16T23:00:00Z",
+		},
+		{
+			name:      "day when daylight saving time starts",
+			every:     values.ConvertDurationNsecs(24 * time.Hour),
+			location:  paris,
+			t:         "2020-03-29T12:00:00Z",
+			wantStart: "2020-03-28T23:00:00Z",
+			wantStop:  "2020-03-29T22:00:00Z",
+		},
+		{
+			name:      "day when daylight saving time ends",
+			every:     values.ConvertDurationNsecs(24 * time.Hour),
+			location:  paris,
+			t:         "2020-10-25T12:00:00Z",
+			wantStart: "2020-10-24T22:00:00Z",
+			wantStop:  "2020-10-25T23:00:00Z",
+		},
+		{
+			name:      "week",
+			every:     values.ConvertDurationNsecs(7 * 24 * time.Hour),
+			location:  paris,
+			t:         "2020-07-04T12:00:00Z",
+			wantStart: "2020-07-01T22:00:00Z",
+			wantStop:  "2020-07-08T22:00:00Z",
+		},
+		{
+			name:      "month",
+			every:     values.MakeDuration(0, 1, false),
+			location:  paris,
+			t:         "2020-07-15T00:00:00Z",
+			wantStart: "2020-06-30T22:00:00Z",
+			wantStop:  "2020-07-31T22:00:00Z",
+		},
+		{
+			name:      "month that starts in the previous month in UTC",
+			every:     values.MakeDuration(0, 1, false),
+			location:  paris,
+			t:         "2020-06-30T22:30:00Z",
+			wantStart: "2020-06-30T22:00:00Z",
+			wantStop:  "2020-07-31T22:00:00Z",
+		},
+		{
+			name:      "hour in a zone with a half hour offset",
+			every:     values.ConvertDurationNsecs(time.Hour),
+			location:  interval.Location{Zone: "Asia/Kolkata"},
+			t:         "2020-01-01T10:15:00Z",
+			wantStart: "2020-01-01T09:30:00Z",
+			wantStop:  "2020-01-01T10:30:00Z",
+		},
+		{
+			name:      "fixed offset",
+			every:     values.ConvertDurationNsecs(24 * time.Hour),
+			location:  interval.Location{Zone: "UTC", Offset: values.ConvertDurationNsecs(-5 * time.Hour)},
+			t:         "2020-01-01T03:00:00Z",
+			wantStart: "2019-12-31T05:00:00Z",
+			wantStop:  "2020-01-01T05:00:00Z",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := interval.NewWindowInLocation(tc.every, tc.every, values.Duration{}, tc.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := w.GetLatestBounds(mustTime(tc.t))
+			if want, got := mustTime(tc.wantStart), b.Start(); want != got {
+				t.Errorf("unexpected start -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+			if want, got := mustTime(tc.wantStop), b.Stop(); want != got {
+				t.Errorf("unexpected stop -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
+// TestWindowInLocation_Transitions checks that the windows around
+// daylight saving time changes contain the times they are computed
+// for and follow each other without gaps or overlaps.
+func TestWindowInLocation_Transitions(t *testing.T) {
+	for _, every := range []time.Duration{5 * time.Minute, time.Hour, 2 * time.Hour, 24 * time.Hour} {
+		for _, tc := range []struct {
+			zone, start, stop string
+		}{
+			{zone: "Europe/Paris", start: "2020-03-28T20:00:00Z", stop: "2020-03-29T04:00:00Z"},
+			{zone: "Europe/Paris", start: "2020-10-24T20:00:00Z", stop: "2020-10-25T04:00:00Z"},
+			{zone: "America/New_York", start: "2020-11-01T03:00:00Z", stop: "2020-11-01T09:00:00Z"},
+			{zone: "Australia/Lord_Howe", start: "2020-04-04T12:00:00Z", stop: "2020-04-04T18:00:00Z"},
+		} {
+			every, tc := every, tc
+			t.Run(tc.zone+"/"+every.String(), func(t *testing.T) {
+				d := values.ConvertDurationNsecs(every)
+				w, err := interval.NewWindowInLocation(d, d, values.Duration{}, interval.Location{Zone: tc.zone})
+				if err != nil {
+					t.Fatal(err)
+				}
+				stop := mustTime(tc.stop)
+				for ts := mustTime(tc.start); ts < stop; ts += values.Time(time.Minute) {
+					b := w.GetLatestBounds(ts)
+					if !b.Contains(ts) {
+						t.Fatalf("bounds %v do not contain %v", b, ts)
+					}
+					if next := w.NextBounds(b); next.Start() != b.Stop() {
+						t.Fatalf("next bounds %v do not follow %v", next, b)
+					}
+					if prev := w.PrevBounds(b); prev.Stop() != b.Start() {
+						t.Fatalf("previous bounds %v do not precede %v", prev, b)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWindowInLocation_GetOverlappingBounds(t *testing.T) {
+	every := values.ConvertDurationNsecs(24 * time.Hour)
+	w, err := interval.NewWindowInLocation(every, every, values.Duration{}, interval.Location{Zone: "America/New_York"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := w.GetOverlappingBounds(mustTime("2020-03-07T05:00:00Z"), mustTime("2020-03-10T04:00:00Z"))
+	want := []string{
+		"2020-03-09T04:00:00Z", "2020-03-10T04:00:00Z",
+		"2020-03-08T05:00:00Z", "2020-03-09T04:00:00Z",
+		"2020-03-07T05:00:00Z", "2020-03-08T05:00:00Z",
+	}
+	if len(bs) != len(want)/2 {
+		t.Fatalf("unexpected number of bounds: want %d, got %d: %v", len(want)/2, len(bs), bs)
+	}
+	for i, b := range bs {
+		if b.Start() != mustTime(want[2*i]) || b.Stop() != mustTime(want[2*i+1]) {
+			t.Errorf("unexpected bounds %d: want [%s, %s), got %v", i, want[2*i], want[2*i+1], b)
+		}
+	}
+}
+
+func TestNewWindowInLocation_Invalid(t *testing.T) {
+	every := values.ConvertDurationNsecs(time.Hour)
+	for _, loc := range []interval.Location{
+		{Zone: "Mars/Olympus_Mons"},
+		{Zone: "UTC", Offset: values.MakeDuration(0, 1, false)},
+	} {
+		if _, err := interval.NewWindowInLocation(every, every, values.Duration{}, loc); err == nil {
+			t.Errorf("expected an error for location %v", loc)
+		}
+	}
+}
@@ -34,6 +34,9 @@ type Window struct {
 	period     values.Duration
 	zero       values.Time
 	zeroMonths int64
+	// zone is the location of the window when it is not UTC.
+	// The boundaries are then computed on the clock of the zone.
+	zone *zone
 }
 
 // NewWindow creates a window which can be used to determine the boundaries for a given point.
@@ -42,6 +45,14 @@ type Window struct {
 // Each window's length is the start boundary plus the period.
 // Every must not be a mix of months and nanoseconds in order to preserve constant time bounds lookup.
 func NewWindow(every, period, offset values.Duration) (Window, error) {
+	return NewWindowInLocation(every, period, offset, UTC)
+}
+
+// NewWindowInLocation creates a window like NewWindow whose boundaries are
+// computed on the clock of the location. A window of a day starts at midnight
+// in the location and lasts 23 or 25 hours when daylight saving time starts
+// or ends during that day.
+func NewWindowInLocation(every, period, offset values.Duration, location Location) (Window, error) {
 	zero := epoch.Add(offset)
 	w := Window{
 		every:      every,
@@ -52,6 +63,13 @@ func NewWindow(every, period, offset values.Duration) (Window, error) {
 	if err := w.isValid(); err != nil {
 		return Window{}, err
 	}
+	if !location.IsUTC() {
+		z, err := location.load()
+		if err != nil {
+			return Window{}, err
+		}
+		w.zone = z
+	}
 	return w, nil
 }
 
@@ -86,6 +104,14 @@ func (w Window) isValid() error {
 // GetLatestBounds returns the bounds for the latest window bounds that contains the given time t.
 // For underlapping windows that do not contain time t, the window directly before time t will be returned.
 func (w Window) GetLatestBounds(t values.Time) Bounds {
+	if w.zone == nil {
+		return w.getLatestBounds(t)
+	}
+	return w.fromLocal(w.getLatestBounds(w.zone.toLocal(t)))
+}
+
+// getLatestBounds is GetLatestBounds on the clock of the window.
+func (w Window) getLatestBounds(t values.Time) Bounds {
 	// Get the latest index that should contain the time t
 	index := w.lastIndex(t)
 	// Construct the bounds from the index
@@ -118,10 +144,10 @@ func (w Window) GetLatestBounds(t values.Time) Bounds {
 			}
 		}
 		// Now do a direct search
-		next := w.NextBounds(b)
+		next := w.nextBounds(b)
 		for next.Contains(t) {
 			b = next
-			next = w.NextBounds(next)
+			next = w.nextBounds(next)
 		}
 	}
 	return b
@@ -155,6 +181,27 @@ func (w Window) GetOverlappingBounds(start, stop values.Time) []Bounds {
 
 // NextBounds returns the next boundary in sequence from the given boundary.
 func (w Window) NextBounds(b Bounds) Bounds {
+	return w.fromLocal(w.nextBounds(b))
+}
+
+// PrevBounds returns the previous boundary in sequence from the given boundary.
+func (w Window) PrevBounds(b Bounds) Bounds {
+	return w.fromLocal(w.prevBounds(b))
+}
+
+// fromLocal converts bounds on the clock of the window to bounds in time.
+func (w Window) fromLocal(b Bounds) Bounds {
+	if w.zone == nil {
+		return b
+	}
+	b.start = w.zone.fromLocal(b.start)
+	b.stop = w.zone.fromLocal(b.stop)
+	return b
+}
+
+// nextBounds is NextBounds on the clock of the window.
+// Only the index of the bounds is used.
+func (w Window) nextBounds(b Bounds) Bounds {
 	index := b.index + 1
 	start := w.zero.Add(w.every.Mul(index))
 	stop := start.Add(w.period)
@@ -168,8 +215,9 @@ func (w Window) NextBounds(b Bounds) Bounds {
 	}
 }
 
-// PrevBounds returns the previous boundary in sequence from the given boundary.
-func (w Window) PrevBounds(b Bounds) Bounds {
+// prevBounds is PrevBounds on the clock of the window.
+// Only the index of the bounds is used.
+func (w Window) prevBounds(b Bounds) Bounds {
 	index := b.index - 1
 	start := w.zero.Add(w.every.Mul(index))
 	stop := start.Add(w.period)
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"net/http"
+
+	"github.com/influxdata/flux"
+)
+
+const (
+	DialectType       = "json"
+	NDJSONDialectType = "ndjson"
+)
+
+// AddDialectMappings adds the json specific dialect mappings.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	if err := mappings.Add(DialectType, func() flux.Dialect {
+		return &Dialect{}
+	}); err != nil {
+		return err
+	}
+	return mappings.Add(NDJSONDialectType, func() flux.Dialect {
+		return &Dialect{
+			ResultEncoderConfig: ResultEncoderConfig{NewlineDelimited: true},
+		}
+	})
+}
+
+// Dialect describes the output format of queries in JSON.
+type Dialect struct {
+	ResultEncoderConfig
+}
+
+func (d Dialect) SetHeaders(w http.ResponseWriter) {
+	if d.NewlineDelimited {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+func (d Dialect) Encoder() flux.MultiResultEncoder {
+	return NewMultiResultEncoder(d.ResultEncoderConfig)
+}
+
+func (d Dialect) DialectType() flux.DialectType {
+	if d.NewlineDelimited {
+		return NDJSONDialectType
+	}
+	return DialectType
+}
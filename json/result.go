@@ -0,0 +1,1202 @@
+// Package json contains the json result encoders and decoders.
+//
+// Results can be encoded as a single JSON document:
+//
+//	{"results":[{"name":"_result","tables":[{"columns":[...],"rows":[[...],...]}]}]}
+//
+// or as newline-delimited JSON where a line describing the columns
+// of each table comes before a line for each of its rows:
+//
+//	{"result":"_result","table":0,"columns":[...]}
+//	{"result":"_result","table":0,"record":{"_time":"...","_value":1.5}}
+//
+// Each column records its label, its datatype and whether it is part of the
+// group key along with its value in the group key. Errors that happen after
+// the output has started are written as an "error" property of the document
+// or as a line with an "error" property.
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	stringDatatype = "string"
+	timeDatatype   = "dateTime"
+	floatDatatype  = "double"
+	boolDatatype   = "boolean"
+	intDatatype    = "long"
+	uintDatatype   = "unsignedLong"
+)
+
+// column describes a column of a table.
+type column struct {
+	Label    string `json:"label"`
+	Datatype string `json:"datatype"`
+	Group    bool   `json:"group,omitempty"`
+	// GroupValue is the value of the column in the group key.
+	// It is omitted when the value is null.
+	GroupValue interface{} `json:"groupValue,omitempty"`
+}
+
+// line is the layout of a line of newline-delimited JSON.
+type line struct {
+	Result  string                 `json:"result"`
+	Table   int                    `json:"table"`
+	Columns []column               `json:"columns"`
+	Record  map[string]interface{} `json:"record"`
+	Error   string                 `json:"error"`
+}
+
+// ResultEncoderConfig configures the MultiResultEncoder.
+type ResultEncoderConfig struct {
+	// NewlineDelimited writes a JSON object per line
+	// instead of a single JSON document.
+	NewlineDelimited bool
+}
+
+// MultiResultEncoder encodes results as JSON.
+type MultiResultEncoder struct {
+	c ResultEncoderConfig
+}
+
+// NewMultiResultEncoder creates a new encoder with the provided configuration.
+func NewMultiResultEncoder(c ResultEncoderConfig) *MultiResultEncoder {
+	return &MultiResultEncoder{c: c}
+}
+
+type jsonEncoderError struct {
+	err error
+}
+
+func (e *jsonEncoderError) Error() string {
+	return fmt.Sprintf("json encoder error: %s", e.err.Error())
+}
+
+func (e *jsonEncoderError) IsEncoderError() bool {
+	return true
+}
+
+func (e *jsonEncoderError) Unwrap() error {
+	return e.err
+}
+
+func wrapEncodingError(err error) error {
+	if err == nil {
+		return err
+	}
+	return &jsonEncoderError{err: err}
+}
+
+func isEncoderError(err error) bool {
+	encErr, ok := err.(flux.EncoderError)
+	return ok && encErr.IsEncoderError()
+}
+
+type flusher interface {
+	Flush()
+}
+
+// Encode writes the results to w. If an error occurs while processing
+// the results, Encode returns the error if nothing has been written yet.
+// Otherwise the error is written into the output and an error is only
+// returned when the output could not be written.
+func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	lw := flux.NewOutputLimitWriter(w, results)
+	wc := &iocounter.Writer{Writer: lw}
+	var rw resultWriter
+	if e.c.NewlineDelimited {
+		rw = &lineWriter{w: wc}
+	} else {
+		rw = &documentWriter{w: wc}
+	}
+
+	for results.More() {
+		if err := encodeResult(rw, results.Next()); err != nil {
+			// Nothing more can be written once the output limit is exceeded.
+			if err := lw.Err(); err != nil {
+				return wc.Count(), err
+			}
+			// If we have an error that's from encoding or if we have not
+			// yet written any data to the writer, return the error.
+			if isEncoderError(err) || wc.Count() == 0 {
+				return wc.Count(), err
+			}
+			// Otherwise, the error happened during query execution and we
+			// are stuck encoding it.
+			return wc.Count(), wrapEncodingError(rw.writeError(err))
+		}
+		// Flush the writer after each result.
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+	}
+	results.Release()
+
+	if err := results.Err(); err != nil {
+		if wc.Count() == 0 {
+			return 0, err
+		}
+		return wc.Count(), wrapEncodingError(rw.writeError(err))
+	}
+	if err := rw.finish(); err != nil {
+		if err := lw.Err(); err != nil {
+			return wc.Count(), err
+		}
+		return wc.Count(), wrapEncodingError(err)
+	}
+	return wc.Count(), nil
+}
+
+func encodeResult(rw resultWriter, result flux.Result) error {
+	name := result.Name()
+	if err := result.Tables().Do(func(tbl flux.Table) error {
+		if err := rw.startTable(name, tbl.Key(), tbl.Cols()); err != nil {
+			tbl.Done()
+			return wrapEncodingError(err)
+		}
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			for i, n := 0, cr.Len(); i < n; i++ {
+				if err := rw.row(cr, i); err != nil {
+					return wrapEncodingError(err)
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		return wrapEncodingError(rw.endTable())
+	}); err != nil {
+		return err
+	}
+	return wrapEncodingError(rw.endResult(name))
+}
+
+// resultWriter writes the parts of the results as they are read.
+type resultWriter interface {
+	startTable(result string, key flux.GroupKey, cols []flux.ColMeta) error
+	row(cr flux.ColReader, i int) error
+	endTable() error
+	endResult(result string) error
+	writeError(err error) error
+	finish() error
+}
+
+// documentWriter writes the results as a single JSON document.
+// Nothing is written until the first table or result has been read
+// so that errors from the query can still be returned to the caller.
+type documentWriter struct {
+	w   io.Writer
+	buf []byte
+
+	started  bool
+	inResult bool
+	inTable  bool
+	nresults int
+	ntables  int
+	nrows    int
+}
+
+func (d *documentWriter) startResult(result string) {
+	if !d.started {
+		d.buf = append(d.buf, `{"results":[`...)
+		d.started = true
+	}
+	if d.nresults > 0 {
+		d.buf = append(d.buf, ',')
+	}
+	d.buf = append(d.buf, `{"name":`...)
+	d.buf = appendString(d.buf, result)
+	d.buf = append(d.buf, `,"tables":[`...)
+	d.inResult = true
+	d.ntables = 0
+}
+
+func (d *documentWriter) startTable(result string, key flux.GroupKey, cols []flux.ColMeta) error {
+	if !d.inResult {
+		d.startResult(result)
+	}
+	if d.ntables > 0 {
+		d.buf = append(d.buf, ',')
+	}
+	columns, err := json.Marshal(newColumns(key, cols))
+	if err != nil {
+		return err
+	}
+	d.buf = append(d.buf, `{"columns":`...)
+	d.buf = append(d.buf, columns...)
+	d.buf = append(d.buf, `,"rows":[`...)
+	d.inTable = true
+	d.nrows = 0
+	return d.flush()
+}
+
+func (d *documentWriter) row(cr flux.ColReader, i int) error {
+	if d.nrows > 0 {
+		d.buf = append(d.buf, ',')
+	}
+	d.buf = append(d.buf, '[')
+	for j := range cr.Cols() {
+		if j > 0 {
+			d.buf = append(d.buf, ',')
+		}
+		d.buf = appendValue(d.buf, cr, i, j)
+	}
+	d.buf = append(d.buf, ']')
+	d.nrows++
+	return d.flush()
+}
+
+func (d *documentWriter) endTable() error {
+	d.buf = append(d.buf, "]}"...)
+	d.inTable = false
+	d.ntables++
+	return d.flush()
+}
+
+func (d *documentWriter) endResult(result string) error {
+	if !d.inResult {
+		d.startResult(result)
+	}
+	d.buf = append(d.buf, "]}"...)
+	d.inResult = false
+	d.nresults++
+	return d.flush()
+}
+
+func (d *documentWriter) writeError(err error) error {
+	if !d.started {
+		d.buf = append(d.buf, `{"results":[`...)
+		d.started = true
+	}
+	// Close the table and result that were being written
+	// so the document stays valid. The rows that were
+	// written before the error are kept.
+	if d.inTable {
+		d.buf = append(d.buf, "]}"...)
+		d.inTable = false
+	}
+	if d.inResult {
+		d.buf = append(d.buf, "]}"...)
+		d.inResult = false
+	}
+	d.buf = append(d.buf, `],"error":`...)
+	d.buf = appendString(d.buf, err.Error())
+	d.buf = append(d.buf, "}\n"...)
+	return d.flush()
+}
+
+func (d *documentWriter) finish() error {
+	if !d.started {
+		d.buf = append(d.buf, `{"results":[`...)
+		d.started = true
+	}
+	d.buf = append(d.buf, "]}\n"...)
+	return d.flush()
+}
+
+// flush writes the buffer once it is large enough
+// so that every row is not a separate write.
+func (d *documentWriter) flush() error {
+	if len(d.buf) < 4096 && d.inResult {
+		return nil
+	}
+	_, err := d.w.Write(d.buf)
+	d.buf = d.buf[:0]
+	return err
+}
+
+// lineWriter writes the results as newline-delimited JSON.
+type lineWriter struct {
+	w   io.Writer
+	buf []byte
+
+	result  []byte
+	tableID int
+	cols    []flux.ColMeta
+	labels  [][]byte
+}
+
+func (l *lineWriter) startTable(result string, key flux.GroupKey, cols []flux.ColMeta) error {
+	columns, err := json.Marshal(newColumns(key, cols))
+	if err != nil {
+		return err
+	}
+	l.result = appendString(l.result[:0], result)
+	l.cols = cols
+	l.labels = l.labels[:0]
+	for _, c := range cols {
+		l.labels = append(l.labels, appendString(nil, c.Label))
+	}
+
+	l.buf = l.appendPrefix(l.buf)
+	l.buf = append(l.buf, `,"columns":`...)
+	l.buf = append(l.buf, columns...)
+	l.buf = append(l.buf, "}\n"...)
+	return nil
+}
+
+func (l *lineWriter) appendPrefix(buf []byte) []byte {
+	buf = append(buf, `{"result":`...)
+	buf = append(buf, l.result...)
+	buf = append(buf, `,"table":`...)
+	return strconv.AppendInt(buf, int64(l.tableID), 10)
+}
+
+func (l *lineWriter) row(cr flux.ColReader, i int) error {
+	l.buf = l.appendPrefix(l.buf)
+	l.buf = append(l.buf, `,"record":{`...)
+	for j := range l.cols {
+		if j > 0 {
+			l.buf = append(l.buf, ',')
+		}
+		l.buf = append(l.buf, l.labels[j]...)
+		l.buf = append(l.buf, ':')
+		l.buf = appendValue(l.buf, cr, i, j)
+	}
+	l.buf = append(l.buf, "}}\n"...)
+	if len(l.buf) >= 4096 {
+		return l.flush()
+	}
+	return nil
+}
+
+func (l *lineWriter) endTable() error {
+	l.tableID++
+	return l.flush()
+}
+
+func (l *lineWriter) endResult(string) error {
+	l.tableID = 0
+	return nil
+}
+
+func (l *lineWriter) writeError(err error) error {
+	l.buf = append(l.buf, `{"error":`...)
+	l.buf = appendString(l.buf, err.Error())
+	l.buf = append(l.buf, "}\n"...)
+	return l.flush()
+}
+
+func (l *lineWriter) finish() error {
+	return l.flush()
+}
+
+func (l *lineWriter) flush() error {
+	_, err := l.w.Write(l.buf)
+	l.buf = l.buf[:0]
+	return err
+}
+
+func newColumns(key flux.GroupKey, cols []flux.ColMeta) []column {
+	columns := make([]column, len(cols))
+	for j, c := range cols {
+		columns[j] = column{
+			Label:    c.Label,
+			Datatype: datatype(c.Type),
+		}
+		if idx := execute.ColIdx(c.Label, key.Cols()); idx >= 0 {
+			columns[j].Group = true
+			if !key.IsNull(idx) {
+				columns[j].GroupValue = json.RawMessage(appendGroupValue(nil, key.Value(idx)))
+			}
+		}
+	}
+	return columns
+}
+
+func datatype(typ flux.ColType) string {
+	switch typ {
+	case flux.TInt:
+		return intDatatype
+	case flux.TUInt:
+		return uintDatatype
+	case flux.TFloat:
+		return floatDatatype
+	case flux.TString:
+		return stringDatatype
+	case flux.TBool:
+		return boolDatatype
+	case flux.TTime:
+		return timeDatatype
+	default:
+		return typ.String()
+	}
+}
+
+func colType(datatype string) (flux.ColType, error) {
+	switch datatype {
+	case intDatatype:
+		return flux.TInt, nil
+	case uintDatatype:
+		return flux.TUInt, nil
+	case floatDatatype:
+		return flux.TFloat, nil
+	case stringDatatype:
+		return flux.TString, nil
+	case boolDatatype:
+		return flux.TBool, nil
+	case timeDatatype:
+		return flux.TTime, nil
+	default:
+		return flux.TInvalid, errors.Newf(codes.Invalid, "unknown datatype %q", datatype)
+	}
+}
+
+func appendString(buf []byte, s string) []byte {
+	// Marshaling a string does not fail.
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
+
+// appendFloat appends a float as a JSON number.
+// Values that JSON cannot represent are written as strings.
+func appendFloat(buf []byte, f float64) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(buf, `"NaN"`...)
+	case math.IsInf(f, 1):
+		return append(buf, `"+Inf"`...)
+	case math.IsInf(f, -1):
+		return append(buf, `"-Inf"`...)
+	default:
+		return strconv.AppendFloat(buf, f, 'g', -1, 64)
+	}
+}
+
+func appendTime(buf []byte, t values.Time) []byte {
+	buf = append(buf, '"')
+	buf = t.Time().UTC().AppendFormat(buf, time.RFC3339Nano)
+	return append(buf, '"')
+}
+
+func appendValue(buf []byte, cr flux.ColReader, i, j int) []byte {
+	switch typ := cr.Cols()[j].Type; typ {
+	case flux.TInt:
+		if vs := cr.Ints(j); vs.IsValid(i) {
+			return strconv.AppendInt(buf, vs.Value(i), 10)
+		}
+	case flux.TUInt:
+		if vs := cr.UInts(j); vs.IsValid(i) {
+			return strconv.AppendUint(buf, vs.Value(i), 10)
+		}
+	case flux.TFloat:
+		if vs := cr.Floats(j); vs.IsValid(i) {
+			return appendFloat(buf, vs.Value(i))
+		}
+	case flux.TString:
+		if vs := cr.Strings(j); vs.IsValid(i) {
+			return appendString(buf, vs.ValueString(i))
+		}
+	case flux.TBool:
+		if vs := cr.Bools(j); vs.IsValid(i) {
+			return strconv.AppendBool(buf, vs.Value(i))
+		}
+	case flux.TTime:
+		if vs := cr.Times(j); vs.IsValid(i) {
+			return appendTime(buf, values.Time(vs.Value(i)))
+		}
+	}
+	return append(buf, "null"...)
+}
+
+func appendGroupValue(buf []byte, v values.Value) []byte {
+	switch v.Type().Nature() {
+	case semantic.Int:
+		return strconv.AppendInt(buf, v.Int(), 10)
+	case semantic.UInt:
+		return strconv.AppendUint(buf, v.UInt(), 10)
+	case semantic.Float:
+		return appendFloat(buf, v.Float())
+	case semantic.Bool:
+		return strconv.AppendBool(buf, v.Bool())
+	case semantic.Time:
+		return appendTime(buf, v.Time())
+	default:
+		return appendString(buf, v.Str())
+	}
+}
+
+// decodeValue converts a value that was decoded with
+// json.Decoder.UseNumber into a value of the column type.
+func decodeValue(v interface{}, typ flux.ColType) (values.Value, error) {
+	if v == nil {
+		return values.NewNull(flux.SemanticType(typ)), nil
+	}
+	switch typ {
+	case flux.TInt:
+		if n, ok := v.(json.Number); ok {
+			i, err := strconv.ParseInt(string(n), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewInt(i), nil
+		}
+	case flux.TUInt:
+		if n, ok := v.(json.Number); ok {
+			u, err := strconv.ParseUint(string(n), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewUInt(u), nil
+		}
+	case flux.TFloat:
+		switch v := v.(type) {
+		case json.Number:
+			f, err := strconv.ParseFloat(string(v), 64)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewFloat(f), nil
+		case string:
+			switch v {
+			case "NaN":
+				return values.NewFloat(math.NaN()), nil
+			case "+Inf":
+				return values.NewFloat(math.Inf(1)), nil
+			case "-Inf":
+				return values.NewFloat(math.Inf(-1)), nil
+			}
+		}
+	case flux.TString:
+		if s, ok := v.(string); ok {
+			return values.NewString(s), nil
+		}
+	case flux.TBool:
+		if b, ok := v.(bool); ok {
+			return values.NewBool(b), nil
+		}
+	case flux.TTime:
+		if s, ok := v.(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewTime(values.ConvertTime(t)), nil
+		}
+	}
+	return nil, errors.Newf(codes.Invalid, "cannot decode %v as %s", v, datatype(typ))
+}
+
+// ResultDecoderConfig configures the MultiResultDecoder.
+type ResultDecoderConfig struct {
+	// NewlineDelimited reads a JSON object per line
+	// instead of a single JSON document.
+	NewlineDelimited bool
+	// Allocator is the memory allocator that will be used for the decoded tables.
+	// The default is to use an unlimited allocator when this is not set.
+	Allocator *memory.Allocator
+}
+
+// MultiResultDecoder reads the results written by the MultiResultEncoder.
+// The input is decoded as it is read, one table at a time, so the tables
+// of a result must be read before moving on to the next result.
+//
+// A single JSON document is expected to have the layout written by the
+// encoder: the name of a result before its tables and the columns of
+// a table before its rows.
+type MultiResultDecoder struct {
+	c ResultDecoderConfig
+}
+
+// NewMultiResultDecoder creates a new MultiResultDecoder.
+func NewMultiResultDecoder(c ResultDecoderConfig) *MultiResultDecoder {
+	if c.Allocator == nil {
+		c.Allocator = &memory.Allocator{}
+	}
+	return &MultiResultDecoder{c: c}
+}
+
+func (d *MultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	var dec tableDecoder
+	if d.c.NewlineDelimited {
+		dec = &lineDecoder{d: d, br: bufio.NewReader(r)}
+	} else {
+		jd := json.NewDecoder(r)
+		jd.UseNumber()
+		dec = &documentDecoder{d: d, dec: jd}
+	}
+	return &resultIterator{r: r, dec: dec}, nil
+}
+
+// tableDecoder reads the results and tables of the encoded output.
+type tableDecoder interface {
+	// nextResult advances to the next result and returns its name.
+	// Any tables of the current result that were not read are skipped.
+	// It returns io.EOF when there are no more results and
+	// the encoded error when the output ended with an error.
+	nextResult() (string, error)
+	// nextTable decodes the next table of the current result.
+	// It returns io.EOF when the result has no more tables.
+	nextTable() (flux.Table, error)
+}
+
+// documentDecoder decodes the results of a single JSON document.
+type documentDecoder struct {
+	d     *MultiResultDecoder
+	dec   *json.Decoder
+	state documentState
+	err   error
+}
+
+type documentState int
+
+const (
+	// documentStart is the state before the document is opened.
+	documentStart documentState = iota
+	// documentResults is the state inside of the results array
+	// between two results.
+	documentResults
+	// documentTables is the state inside of the tables array
+	// of a result.
+	documentTables
+	// documentEnd is the state after the results array.
+	documentEnd
+)
+
+func (d *documentDecoder) nextResult() (string, error) {
+	name, err := d.readResult()
+	if err != nil && err != io.EOF {
+		d.state = documentEnd
+		if _, ok := err.(*flux.Error); !ok {
+			err = errors.Wrap(err, codes.Invalid, "failed to decode json document")
+		}
+	}
+	return name, err
+}
+
+func (d *documentDecoder) readResult() (string, error) {
+	switch d.state {
+	case documentStart:
+		if err := d.readDelim('{'); err != nil {
+			return "", err
+		}
+		// Read the properties of the document until the results.
+		if found, err := d.readObject("results"); err != nil {
+			return "", err
+		} else if !found {
+			d.state = documentEnd
+			return "", d.documentErr()
+		}
+		if err := d.readDelim('['); err != nil {
+			return "", err
+		}
+	case documentTables:
+		// Skip the tables that were not read and
+		// the rest of the properties of the result.
+		for d.dec.More() {
+			if err := d.skip(); err != nil {
+				return "", err
+			}
+		}
+		if err := d.readDelim(']'); err != nil {
+			return "", err
+		}
+		if _, err := d.readObject(""); err != nil {
+			return "", err
+		}
+	case documentEnd:
+		return "", io.EOF
+	}
+	d.state = documentResults
+
+	if !d.dec.More() {
+		// This is the end of the results array.
+		// Read the rest of the document for an error.
+		d.state = documentEnd
+		if err := d.readDelim(']'); err != nil {
+			return "", err
+		}
+		if _, err := d.readObject(""); err != nil {
+			return "", err
+		}
+		return "", d.documentErr()
+	}
+
+	if err := d.readDelim('{'); err != nil {
+		return "", err
+	}
+	var name string
+	for {
+		key, ok, err := d.readKey()
+		if err != nil {
+			return "", err
+		} else if !ok {
+			// The result does not have any tables.
+			return name, nil
+		}
+		switch key {
+		case "name":
+			if err := d.decode(&name); err != nil {
+				return "", err
+			}
+		case "tables":
+			if err := d.readDelim('['); err != nil {
+				return "", err
+			}
+			d.state = documentTables
+			return name, nil
+		default:
+			if err := d.skip(); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+func (d *documentDecoder) nextTable() (flux.Table, error) {
+	if d.state != documentTables {
+		return nil, io.EOF
+	}
+	tbl, err := d.readTable()
+	if err != nil && err != io.EOF {
+		d.state = documentEnd
+		if _, ok := err.(*flux.Error); !ok {
+			err = errors.Wrap(err, codes.Invalid, "failed to decode json document")
+		}
+	}
+	return tbl, err
+}
+
+func (d *documentDecoder) readTable() (flux.Table, error) {
+	if !d.dec.More() {
+		// This is the end of the tables array.
+		if err := d.readDelim(']'); err != nil {
+			return nil, err
+		}
+		if _, err := d.readObject(""); err != nil {
+			return nil, err
+		}
+		d.state = documentResults
+		return nil, io.EOF
+	}
+
+	if err := d.readDelim('{'); err != nil {
+		return nil, err
+	}
+	var b *execute.ColListTableBuilder
+	defer func() {
+		if b != nil {
+			b.Release()
+		}
+	}()
+	for {
+		key, ok, err := d.readKey()
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+		switch key {
+		case "columns":
+			var columns []column
+			if err := d.decode(&columns); err != nil {
+				return nil, err
+			}
+			if b != nil {
+				b.Release()
+			}
+			if b, err = d.d.newBuilder(columns); err != nil {
+				return nil, err
+			}
+		case "rows":
+			if b == nil {
+				return nil, errors.New(codes.Invalid, "rows found before the columns of the table")
+			}
+			if err := d.readDelim('['); err != nil {
+				return nil, err
+			}
+			for d.dec.More() {
+				var row []interface{}
+				if err := d.decode(&row); err != nil {
+					return nil, err
+				}
+				if err := appendRow(b, row); err != nil {
+					return nil, err
+				}
+			}
+			if err := d.readDelim(']'); err != nil {
+				return nil, err
+			}
+		default:
+			if err := d.skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if b == nil {
+		return nil, errors.New(codes.Invalid, "table has no columns")
+	}
+	return b.Table()
+}
+
+// readObject reads the properties of the current object until it finds
+// the property with the given key or the end of the object.
+// The error property of the document is stored when it is found.
+func (d *documentDecoder) readObject(key string) (bool, error) {
+	for {
+		k, ok, err := d.readKey()
+		if err != nil {
+			return false, err
+		} else if !ok {
+			return false, nil
+		}
+		switch {
+		case key != "" && k == key:
+			return true, nil
+		case k == "error":
+			var msg string
+			if err := d.decode(&msg); err != nil {
+				return false, err
+			}
+			if msg != "" {
+				// TODO: We should determine the correct error code here:
+				//   https://github.com/influxdata/flux/issues/1916
+				d.err = errors.New(codes.Internal, msg)
+			}
+		default:
+			if err := d.skip(); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+// readKey reads the key of the next property of the current object.
+// It returns false at the end of the object.
+func (d *documentDecoder) readKey() (string, bool, error) {
+	tok, err := d.token()
+	if err != nil {
+		return "", false, err
+	}
+	if delim, ok := tok.(json.Delim); ok && delim == '}' {
+		return "", false, nil
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", false, errors.Newf(codes.Invalid, "expected a property, got %v", tok)
+	}
+	return key, true, nil
+}
+
+func (d *documentDecoder) readDelim(want json.Delim) error {
+	tok, err := d.token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return errors.Newf(codes.Invalid, "expected %v, got %v", want, tok)
+	}
+	return nil
+}
+
+func (d *documentDecoder) skip() error {
+	var v json.RawMessage
+	return d.decode(&v)
+}
+
+// token reads the next token of the document.
+// The document cannot end before it is closed,
+// so io.EOF is reported as an unexpected EOF.
+func (d *documentDecoder) token() (json.Token, error) {
+	tok, err := d.dec.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return tok, err
+}
+
+// decode reads the next value of the document into v.
+func (d *documentDecoder) decode(v interface{}) error {
+	err := d.dec.Decode(v)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// documentErr returns the error property of the document
+// or io.EOF when the document did not have an error.
+func (d *documentDecoder) documentErr() error {
+	if d.err != nil {
+		return d.err
+	}
+	return io.EOF
+}
+
+// lineDecoder decodes the results of newline-delimited JSON.
+type lineDecoder struct {
+	d  *MultiResultDecoder
+	br *bufio.Reader
+
+	// peeked is a line that was read but belongs
+	// to the next table or result.
+	peeked *line
+
+	started bool
+	result  string
+	tableID int
+}
+
+// readLine reads the next line that is not empty.
+func (d *lineDecoder) readLine() (*line, error) {
+	if l := d.peeked; l != nil {
+		d.peeked = nil
+		return l, nil
+	}
+	for {
+		data, err := d.br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to read json line")
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var l line
+			if err := dec.Decode(&l); err != nil {
+				return nil, errors.Wrap(err, codes.Invalid, "failed to decode json line")
+			}
+			return &l, nil
+		}
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
+
+// isNextTable reports whether the line starts
+// another table of the current result.
+func (d *lineDecoder) isNextTable(l *line) bool {
+	return d.started && l.Result == d.result && l.Table > d.tableID
+}
+
+func (d *lineDecoder) nextResult() (string, error) {
+	for {
+		l, err := d.readLine()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case l.Error != "":
+			// TODO: We should determine the correct error code here:
+			//   https://github.com/influxdata/flux/issues/1916
+			return "", errors.New(codes.Internal, l.Error)
+		case l.Columns != nil:
+			if d.isNextTable(l) {
+				// Skip a table of the current result that was not read.
+				d.tableID = l.Table
+				continue
+			}
+			d.started = true
+			d.result = l.Result
+			d.tableID = -1
+			d.peeked = l
+			return l.Result, nil
+		default:
+			if !d.started || l.Result != d.result || l.Table != d.tableID {
+				return "", errors.Newf(codes.Invalid, "record for table %d of result %q found before its columns", l.Table, l.Result)
+			}
+		}
+	}
+}
+
+func (d *lineDecoder) nextTable() (flux.Table, error) {
+	l, err := d.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if l.Columns == nil && l.Error == "" {
+		return nil, errors.Newf(codes.Invalid, "record for table %d of result %q found before its columns", l.Table, l.Result)
+	}
+	if l.Error != "" || (d.tableID >= 0 && !d.isNextTable(l)) {
+		// The line belongs to the next result.
+		d.peeked = l
+		return nil, io.EOF
+	}
+	d.tableID = l.Table
+
+	b, err := d.d.newBuilder(l.Columns)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]int, len(l.Columns))
+	for j, c := range l.Columns {
+		labels[c.Label] = j
+	}
+	for {
+		l, err := d.readLine()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			b.Release()
+			return nil, err
+		}
+		if l.Columns != nil || l.Error != "" || l.Result != d.result || l.Table != d.tableID {
+			d.peeked = l
+			break
+		}
+		row := make([]interface{}, len(labels))
+		for label, v := range l.Record {
+			j, ok := labels[label]
+			if !ok {
+				b.Release()
+				return nil, errors.Newf(codes.Invalid, "record has unknown column %q", label)
+			}
+			row[j] = v
+		}
+		if err := appendRow(b, row); err != nil {
+			b.Release()
+			return nil, err
+		}
+	}
+	tbl, err := b.Table()
+	b.Release()
+	return tbl, err
+}
+
+// newBuilder creates a table builder with the columns and group key
+// described by the encoded columns.
+func (d *MultiResultDecoder) newBuilder(columns []column) (*execute.ColListTableBuilder, error) {
+	cols := make([]flux.ColMeta, len(columns))
+	var (
+		keyCols   []flux.ColMeta
+		keyValues []values.Value
+	)
+	for j, c := range columns {
+		typ, err := colType(c.Datatype)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "column %q has invalid datatype", c.Label)
+		}
+		cols[j] = flux.ColMeta{Label: c.Label, Type: typ}
+		if c.Group {
+			v, err := decodeValue(c.GroupValue, typ)
+			if err != nil {
+				return nil, errors.Wrapf(err, codes.Inherit, "invalid group key value for column %q", c.Label)
+			}
+			keyCols = append(keyCols, cols[j])
+			keyValues = append(keyValues, v)
+		}
+	}
+
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(keyCols, keyValues), d.c.Allocator)
+	for _, c := range cols {
+		if _, err := b.AddCol(c); err != nil {
+			b.Release()
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendRow(b *execute.ColListTableBuilder, row []interface{}) error {
+	cols := b.Cols()
+	if len(row) != len(cols) {
+		return errors.Newf(codes.Invalid, "row has %d values, expected %d", len(row), len(cols))
+	}
+	for j, v := range row {
+		value, err := decodeValue(v, cols[j].Type)
+		if err != nil {
+			return errors.Wrapf(err, codes.Inherit, "invalid value for column %q", cols[j].Label)
+		}
+		if err := b.AppendValue(j, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// result is a result whose tables are decoded as they are read.
+type result struct {
+	name string
+	dec  tableDecoder
+	// err is set when decoding the tables fails
+	// and is reported by the result iterator.
+	err *error
+}
+
+func (r *result) Name() string {
+	return r.name
+}
+
+func (r *result) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *result) Do(f func(flux.Table) error) error {
+	for {
+		tbl, err := r.dec.nextTable()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			*r.err = err
+			return err
+		}
+		err = f(tbl)
+		tbl.Done()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// resultIterator iterates through the decoded results.
+type resultIterator struct {
+	r   io.ReadCloser
+	dec tableDecoder
+	cur *result
+	err error
+
+	released bool
+}
+
+func (r *resultIterator) More() bool {
+	if !r.released && r.err == nil {
+		name, err := r.dec.nextResult()
+		if err == nil {
+			r.cur = &result{name: name, dec: r.dec, err: &r.err}
+			return true
+		} else if err != io.EOF {
+			r.err = err
+		}
+	}
+	r.Release()
+	return false
+}
+
+func (r *resultIterator) Next() flux.Result {
+	return r.cur
+}
+
+func (r *resultIterator) Release() {
+	if r.released {
+		return
+	}
+	if err := r.r.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	r.released = true
+}
+
+func (r *resultIterator) Err() error {
+	return r.err
+}
+
+func (r *resultIterator) Statistics() flux.Statistics {
+	return flux.Statistics{}
+}
@@ -0,0 +1,303 @@
+package json_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/json"
+)
+
+func newTables() []*executetest.Table {
+	return []*executetest.Table{
+		{
+			KeyCols: []string{"_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "n", Type: flux.TInt},
+				{Label: "u", Type: flux.TUInt},
+				{Label: "b", Type: flux.TBool},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "cpu", "a", 2.5, int64(math.MaxInt64), uint64(math.MaxUint64), true},
+				{execute.Time(2), "cpu", "a", nil, nil, uint64(2), nil},
+				{execute.Time(3), "cpu", "a", math.NaN(), int64(-3), nil, false},
+				{execute.Time(4), "cpu", "a", math.Inf(-1), int64(4), uint64(4), false},
+			},
+		},
+		{
+			// The value of host in the group key is null.
+			KeyCols:   []string{"_measurement", "host"},
+			KeyValues: []interface{}{"cpu", nil},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "cpu", nil, 1.0},
+			},
+		},
+		{
+			KeyCols:   []string{"_start", "_value"},
+			KeyValues: []interface{}{execute.Time(10), int64(42)},
+			ColMeta: []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_value", Type: flux.TInt},
+			},
+		},
+	}
+}
+
+func encode(t *testing.T, c json.ResultEncoderConfig, results ...flux.Result) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := json.NewMultiResultEncoder(c).Encode(&buf, flux.NewSliceResultIterator(results)); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func decode(t *testing.T, c json.ResultDecoderConfig, buf *bytes.Buffer) ([]*executetest.Result, error) {
+	t.Helper()
+	iter, err := json.NewMultiResultDecoder(c).Decode(ioutil.NopCloser(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []*executetest.Result
+	for iter.More() {
+		res := executetest.ConvertResult(iter.Next())
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		res.Normalize()
+		results = append(results, res)
+	}
+	return results, iter.Err()
+}
+
+func TestMultiResultEncoder_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		newlineDelimited bool
+	}{
+		{name: "document"},
+		{name: "newline delimited", newlineDelimited: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf := encode(t, json.ResultEncoderConfig{NewlineDelimited: tc.newlineDelimited},
+				&executetest.Result{Nm: "_result", Tbls: newTables()},
+				&executetest.Result{Nm: "other", Tbls: newTables()[:1]},
+			)
+			got, err := decode(t, json.ResultDecoderConfig{NewlineDelimited: tc.newlineDelimited}, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []*executetest.Result{
+				{Nm: "_result", Tbls: newTables()},
+				{Nm: "other", Tbls: newTables()[:1]},
+			}
+			for _, r := range want {
+				r.Normalize()
+			}
+			if !cmp.Equal(want, got, cmpopts.EquateNaNs()) {
+				t.Errorf("unexpected results -want/+got:\n%s", cmp.Diff(want, got, cmpopts.EquateNaNs()))
+			}
+		})
+	}
+}
+
+func TestMultiResultEncoder_Format(t *testing.T) {
+	tbl := &executetest.Table{
+		KeyCols: []string{"host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "host", Type: flux.TString},
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(0), "a", 1.5},
+			{execute.Time(1e9), "a", nil},
+		},
+	}
+
+	for _, tc := range []struct {
+		name             string
+		newlineDelimited bool
+		want             string
+	}{
+		{
+			name: "document",
+			want: `{"results":[{"name":"_result","tables":[{"columns":[` +
+				`{"label":"_time","datatype":"dateTime"},` +
+				`{"label":"host","datatype":"string","group":true,"groupValue":"a"},` +
+				`{"label":"_value","datatype":"double"}],` +
+				`"rows":[["1970-01-01T00:00:00Z","a",1.5],["1970-01-01T00:00:01Z","a",null]]}]}]}
+`,
+		},
+		{
+			name:             "newline delimited",
+			newlineDelimited: true,
+			want: `{"result":"_result","table":0,"columns":[` +
+				`{"label":"_time","datatype":"dateTime"},` +
+				`{"label":"host","datatype":"string","group":true,"groupValue":"a"},` +
+				`{"label":"_value","datatype":"double"}]}
+{"result":"_result","table":0,"record":{"_time":"1970-01-01T00:00:00Z","host":"a","_value":1.5}}
+{"result":"_result","table":0,"record":{"_time":"1970-01-01T00:00:01Z","host":"a","_value":null}}
+`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tbl := *tbl
+			buf := encode(t, json.ResultEncoderConfig{NewlineDelimited: tc.newlineDelimited},
+				&executetest.Result{Nm: "_result", Tbls: []*executetest.Table{&tbl}},
+			)
+			if got := buf.String(); got != tc.want {
+				t.Errorf("unexpected output -want/+got:\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestMultiResultEncoder_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		newlineDelimited bool
+	}{
+		{name: "document"},
+		{name: "newline delimited", newlineDelimited: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf := encode(t, json.ResultEncoderConfig{NewlineDelimited: tc.newlineDelimited},
+				&executetest.Result{Nm: "_result", Tbls: newTables()[:1]},
+				&executetest.Result{Nm: "other", Err: errors.New(codes.Internal, "expected error")},
+			)
+			got, err := decode(t, json.ResultDecoderConfig{NewlineDelimited: tc.newlineDelimited}, buf)
+			if err == nil {
+				t.Fatal("expected error")
+			} else if want, got := "expected error", err.Error(); want != got {
+				t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+			}
+
+			want := []*executetest.Result{{Nm: "_result", Tbls: newTables()[:1]}}
+			want[0].Normalize()
+			if !cmp.Equal(want, got, cmpopts.EquateNaNs()) {
+				t.Errorf("unexpected results -want/+got:\n%s", cmp.Diff(want, got, cmpopts.EquateNaNs()))
+			}
+		})
+	}
+}
+
+func TestMultiResultEncoder_ErrorBeforeOutput(t *testing.T) {
+	// Errors are returned instead of encoded when nothing has been written.
+	var buf bytes.Buffer
+	results := flux.NewSliceResultIterator([]flux.Result{
+		&executetest.Result{Nm: "_result", Err: errors.New(codes.Internal, "expected error")},
+	})
+	if _, err := json.NewMultiResultEncoder(json.ResultEncoderConfig{}).Encode(&buf, results); err == nil {
+		t.Fatal("expected error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestMultiResultDecoder_Truncated(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		newlineDelimited bool
+	}{
+		{name: "document"},
+		{name: "newline delimited", newlineDelimited: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf := encode(t, json.ResultEncoderConfig{NewlineDelimited: tc.newlineDelimited},
+				&executetest.Result{Nm: "_result", Tbls: newTables()},
+				&executetest.Result{Nm: "other", Tbls: newTables()[:1]},
+			)
+			// Cut the output in the middle of the second result.
+			// The tables before it are decoded as they are read.
+			n := bytes.LastIndex(buf.Bytes(), []byte(`"other"`)) + len(`"other"`)
+			buf.Truncate(n)
+
+			iter, err := json.NewMultiResultDecoder(json.ResultDecoderConfig{NewlineDelimited: tc.newlineDelimited}).Decode(ioutil.NopCloser(buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []*executetest.Table
+			for iter.More() {
+				if err := iter.Next().Tables().Do(func(tbl flux.Table) error {
+					cb, err := executetest.ConvertTable(tbl)
+					if err != nil {
+						return err
+					}
+					got = append(got, cb)
+					return nil
+				}); err != nil {
+					break
+				}
+			}
+			if iter.Err() == nil {
+				t.Fatal("expected error")
+			}
+
+			want := newTables()
+			executetest.NormalizeTables(want)
+			executetest.NormalizeTables(got)
+			if !cmp.Equal(want, got, cmpopts.EquateNaNs()) {
+				t.Errorf("unexpected tables -want/+got:\n%s", cmp.Diff(want, got, cmpopts.EquateNaNs()))
+			}
+		})
+	}
+}
+
+func TestMultiResultDecoder_SkipTables(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		newlineDelimited bool
+	}{
+		{name: "document"},
+		{name: "newline delimited", newlineDelimited: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf := encode(t, json.ResultEncoderConfig{NewlineDelimited: tc.newlineDelimited},
+				&executetest.Result{Nm: "_result", Tbls: newTables()},
+				&executetest.Result{Nm: "other", Tbls: newTables()[:1]},
+			)
+			iter, err := json.NewMultiResultDecoder(json.ResultDecoderConfig{NewlineDelimited: tc.newlineDelimited}).Decode(ioutil.NopCloser(buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The tables of a result that are not read are skipped.
+			var got []string
+			for iter.More() {
+				got = append(got, iter.Next().Name())
+			}
+			if err := iter.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"_result", "other"}; !cmp.Equal(want, got) {
+				t.Errorf("unexpected results -want/+got:\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
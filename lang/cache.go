@@ -0,0 +1,483 @@
+package lang
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	arrowarray "github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/arrowutil"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+)
+
+const (
+	// ResultCacheHitsKey is the metadata key that counts
+	// the queries that were answered by the result cache.
+	ResultCacheHitsKey = "flux/result-cache-hits"
+	// ResultCacheMissesKey is the metadata key that counts
+	// the queries that could have been answered by the result
+	// cache but were executed.
+	ResultCacheMissesKey = "flux/result-cache-misses"
+)
+
+// ResultCache stores the results of programs so that a program
+// with the same plan can be answered without being executed.
+//
+// Programs are identified by their plan. Times that are relative
+// to now are resolved first, so programs that only differ by now
+// share the same key when the time ranges they read are the same.
+// Sources are assumed to return the same data for the same plan.
+// Plans with side effects are never cached.
+//
+// Implementations must be safe for concurrent use.
+type ResultCache interface {
+	// Get returns the results stored under key.
+	// The results are retained for the caller
+	// which must release them when it is done.
+	Get(key string) (*CachedResults, bool)
+
+	// Put stores the results under key.
+	// The cache must retain the results it keeps
+	// and release them once they are removed.
+	Put(key string, results *CachedResults)
+
+	// MaxSize returns the size in bytes of the largest results
+	// the cache can store. The results of a query stop being
+	// recorded once they are larger. A size of zero or less
+	// means the results are not bounded.
+	MaxSize() int64
+}
+
+// WithResultCache sets the cache used to store and retrieve
+// the results of the program.
+func WithResultCache(cache ResultCache) CompileOption {
+	return func(o *compileOptions) {
+		o.resultCache = cache
+	}
+}
+
+// CachedResults are the results of a program stored in a ResultCache.
+// The tables are copied into memory owned by the CachedResults so they
+// are independent of the query that produced them.
+type CachedResults struct {
+	refCount int32
+	alloc    *memory.Allocator
+	results  []*cachedResult
+}
+
+type cachedResult struct {
+	name   string
+	tables []*cachedTable
+}
+
+type cachedTable struct {
+	key     flux.GroupKey
+	cols    []flux.ColMeta
+	buffers []*arrow.TableBuffer
+}
+
+// Size returns the number of bytes used by the results.
+func (r *CachedResults) Size() int64 {
+	return r.alloc.Allocated()
+}
+
+// Retain increases the reference count of the results.
+func (r *CachedResults) Retain() {
+	atomic.AddInt32(&r.refCount, 1)
+}
+
+// Release decreases the reference count of the results
+// and frees the memory they use when it reaches zero.
+func (r *CachedResults) Release() {
+	if atomic.AddInt32(&r.refCount, -1) != 0 {
+		return
+	}
+	for _, res := range r.results {
+		for _, tbl := range res.tables {
+			for _, buf := range tbl.buffers {
+				buf.Release()
+			}
+		}
+	}
+	r.results = nil
+}
+
+// resultMap returns the results that read from the cached tables.
+// The results must be retained while they are read.
+func (r *CachedResults) resultMap() map[string]flux.Result {
+	m := make(map[string]flux.Result, len(r.results))
+	for _, res := range r.results {
+		m[res.name] = res
+	}
+	return m
+}
+
+func (r *cachedResult) Name() string {
+	return r.name
+}
+
+func (r *cachedResult) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *cachedResult) Do(f func(flux.Table) error) error {
+	for _, tbl := range r.tables {
+		buffers := make([]flux.ColReader, len(tbl.buffers))
+		for i, buf := range tbl.buffers {
+			buf.Retain()
+			buffers[i] = buf
+		}
+		if err := f(&table.BufferedTable{
+			GroupKey: tbl.key,
+			Columns:  tbl.cols,
+			Buffers:  buffers,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resultRecorder copies the tables of the results of a query
+// as they are read so they can be stored in a ResultCache.
+// The results can only be stored if every table of every
+// result was read without an error.
+//
+// The copies are allocated from the query allocator so they count
+// against the memory limit of the query while it runs. They are
+// detached from it once the query is done, so the cache owns the
+// memory of the results it stores.
+type resultRecorder struct {
+	mem     *cacheAllocator
+	alloc   *memory.Allocator
+	maxSize int64
+
+	mu      sync.Mutex
+	results []*cachedResult
+	tables  []*recordingTable
+	pending int
+	failed  bool
+}
+
+// newResultRecorder wraps the results so they are recorded when read.
+// Recording stops once the copies use more than maxSize bytes.
+func newResultRecorder(results map[string]flux.Result, alloc *memory.Allocator, maxSize int64) (*resultRecorder, map[string]flux.Result) {
+	mem := &cacheAllocator{alloc: alloc}
+	r := &resultRecorder{
+		mem:     mem,
+		alloc:   &memory.Allocator{Allocator: mem},
+		maxSize: maxSize,
+		pending: len(results),
+	}
+	wrapped := make(map[string]flux.Result, len(results))
+	for name, res := range results {
+		cr := &cachedResult{name: name}
+		r.results = append(r.results, cr)
+		wrapped[name] = &recordingResult{
+			Result:   res,
+			recorder: r,
+			result:   cr,
+		}
+	}
+	return r, wrapped
+}
+
+func (r *resultRecorder) fail() {
+	r.mu.Lock()
+	r.stop()
+	r.mu.Unlock()
+}
+
+// stop stops recording and releases the tables recorded so far.
+// The lock must be held.
+func (r *resultRecorder) stop() {
+	if r.failed {
+		return
+	}
+	r.failed = true
+	for _, res := range r.results {
+		for _, tbl := range res.tables {
+			for _, buf := range tbl.buffers {
+				buf.Release()
+			}
+			tbl.buffers = nil
+		}
+	}
+}
+
+func (r *resultRecorder) recording() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.failed
+}
+
+// record copies the column reader into the recorded table.
+func (r *resultRecorder) record(cr flux.ColReader, tbl *cachedTable) {
+	if !r.recording() {
+		return
+	}
+	buf, err := copyBuffer(cr, r.alloc)
+	if err != nil {
+		r.fail()
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed {
+		buf.Release()
+		return
+	}
+	tbl.buffers = append(tbl.buffers, buf)
+	if r.maxSize > 0 && r.alloc.Allocated() > r.maxSize {
+		// The results are too large to be cached.
+		r.stop()
+	}
+}
+
+// finish stores the recorded results in the cache when they are complete.
+func (r *resultRecorder) finish(err error, cache ResultCache, key string) {
+	r.mu.Lock()
+	complete := err == nil && !r.failed && r.pending == 0
+	for _, t := range r.tables {
+		if atomic.LoadInt32(&t.read) == 0 {
+			// The table may still be read after the query is done.
+			complete = false
+		}
+	}
+	if !complete {
+		r.stop()
+	}
+	r.mu.Unlock()
+
+	results := &CachedResults{
+		refCount: 1,
+		alloc:    r.alloc,
+		results:  r.results,
+	}
+	if complete {
+		r.mem.detach(r.alloc.Allocated())
+		cache.Put(key, results)
+	}
+	results.Release()
+}
+
+type recordingResult struct {
+	flux.Result
+	recorder *resultRecorder
+	result   *cachedResult
+}
+
+func (r *recordingResult) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *recordingResult) Do(f func(flux.Table) error) error {
+	err := r.Result.Tables().Do(func(tbl flux.Table) error {
+		ct := &cachedTable{
+			key:  tbl.Key(),
+			cols: tbl.Cols(),
+		}
+		rt := &recordingTable{
+			Table:    tbl,
+			recorder: r.recorder,
+			table:    ct,
+		}
+		r.recorder.mu.Lock()
+		r.recorder.tables = append(r.recorder.tables, rt)
+		r.result.tables = append(r.result.tables, ct)
+		r.recorder.mu.Unlock()
+		return f(rt)
+	})
+
+	r.recorder.mu.Lock()
+	if err != nil {
+		r.recorder.stop()
+	} else {
+		r.recorder.pending--
+	}
+	r.recorder.mu.Unlock()
+	return err
+}
+
+type recordingTable struct {
+	flux.Table
+	recorder *resultRecorder
+	table    *cachedTable
+	// read is set once the table has been read or released.
+	read int32
+}
+
+func (t *recordingTable) Do(f func(flux.ColReader) error) error {
+	defer atomic.StoreInt32(&t.read, 1)
+	err := t.Table.Do(func(cr flux.ColReader) error {
+		t.recorder.record(cr, t.table)
+		return f(cr)
+	})
+	if err != nil {
+		t.recorder.fail()
+	}
+	return err
+}
+
+func (t *recordingTable) Done() {
+	if atomic.LoadInt32(&t.read) == 0 && !t.Table.Empty() {
+		// The table was not read so it cannot be recorded.
+		t.recorder.fail()
+	}
+	atomic.StoreInt32(&t.read, 1)
+	t.Table.Done()
+}
+
+// copyBuffer copies the column reader into memory from the allocator.
+// The copy fails if the allocator exceeds its limit.
+func copyBuffer(cr flux.ColReader, mem *memory.Allocator) (buf *arrow.TableBuffer, err error) {
+	buf = &arrow.TableBuffer{
+		GroupKey: cr.Key(),
+		Columns:  cr.Cols(),
+		Values:   make([]arrowarray.Interface, len(cr.Cols())),
+	}
+	var b arrowarray.Builder
+	defer func() {
+		if e := recover(); e != nil {
+			if b != nil {
+				b.Release()
+			}
+			for _, arr := range buf.Values {
+				if arr != nil {
+					arr.Release()
+				}
+			}
+			buf, err = nil, errors.Newf(codes.ResourceExhausted, "cannot copy table for the result cache: %v", e)
+		}
+	}()
+	for j, c := range cr.Cols() {
+		b = arrow.NewBuilder(c.Type, mem)
+		arrowutil.CopyTo(b, table.Values(cr, j))
+		buf.Values[j] = b.NewArray()
+		b.Release()
+		b = nil
+	}
+	return buf, nil
+}
+
+// cacheAllocator allocates the recorded results from the query
+// allocator until it is detached. The memory it frees afterwards
+// is no longer accounted for in the query allocator.
+type cacheAllocator struct {
+	alloc *memory.Allocator
+
+	mu       sync.Mutex
+	detached bool
+}
+
+func (a *cacheAllocator) Allocate(size int) []byte {
+	return a.alloc.Allocate(size)
+}
+
+func (a *cacheAllocator) Reallocate(size int, b []byte) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.detached {
+		return memory.DefaultAllocator.Reallocate(size, b)
+	}
+	return a.alloc.Reallocate(size, b)
+}
+
+func (a *cacheAllocator) Free(b []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.detached {
+		memory.DefaultAllocator.Free(b)
+		return
+	}
+	a.alloc.Free(b)
+}
+
+// detach removes the size bytes that are still in use
+// from the accounting of the query allocator.
+func (a *cacheAllocator) detach(size int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_ = a.alloc.Account(-int(size))
+	a.detached = true
+}
+
+// NewMemoryResultCache creates a ResultCache that keeps the results in
+// memory and evicts the least recently used results when the results
+// use more than maxSize bytes.
+func NewMemoryResultCache(maxSize int64) ResultCache {
+	return &memoryResultCache{
+		maxSize: maxSize,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+type memoryResultCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type memoryResultCacheEntry struct {
+	key     string
+	results *CachedResults
+	size    int64
+}
+
+func (c *memoryResultCache) Get(key string) (*CachedResults, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	results := e.Value.(*memoryResultCacheEntry).results
+	results.Retain()
+	return results, true
+}
+
+func (c *memoryResultCache) MaxSize() int64 {
+	return c.maxSize
+}
+
+func (c *memoryResultCache) Put(key string, results *CachedResults) {
+	size := results.Size()
+	if size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	for c.size+size > c.maxSize {
+		c.remove(c.ll.Back())
+	}
+	results.Retain()
+	c.entries[key] = c.ll.PushFront(&memoryResultCacheEntry{
+		key:     key,
+		results: results,
+		size:    size,
+	})
+	c.size += size
+}
+
+func (c *memoryResultCache) remove(e *list.Element) {
+	entry := c.ll.Remove(e).(*memoryResultCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	entry.results.Release()
+}
@@ -0,0 +1,280 @@
+package lang
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// nondeterministicPackages are the packages whose functions
+// may return a different value each time they are called.
+var nondeterministicPackages = map[string]bool{
+	"system": true,
+}
+
+var (
+	fluxTimeType         = reflect.TypeOf(flux.Time{})
+	fluxBoundsType       = reflect.TypeOf(flux.Bounds{})
+	timeType             = reflect.TypeOf(time.Time{})
+	resolvedFunctionType = reflect.TypeOf(interpreter.ResolvedFunction{})
+	valueType            = reflect.TypeOf((*values.Value)(nil)).Elem()
+	semanticNodeType     = reflect.TypeOf((*semantic.Node)(nil)).Elem()
+)
+
+// planKey returns the key that identifies the results of the plan.
+// Times relative to now are resolved using the now of the plan.
+// It returns false if the results of the plan cannot be cached.
+func planKey(ps *plan.Spec) (string, bool) {
+	var nodes []plan.Node
+	_ = ps.BottomUpWalk(func(node plan.Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID() < nodes[j].ID()
+	})
+
+	h := sha256.New()
+	e := &keyEncoder{
+		h:    h,
+		now:  ps.Now,
+		seen: make(map[uintptr]bool),
+	}
+	for _, node := range nodes {
+		spec := node.ProcedureSpec()
+		if plan.HasSideEffect(spec) {
+			return "", false
+		}
+		fmt.Fprintf(h, "node %s %s\n", node.ID(), spec.Kind())
+		for _, pred := range node.Predecessors() {
+			fmt.Fprintf(h, "predecessor %s\n", pred.ID())
+		}
+		if b := node.Bounds(); b != nil {
+			fmt.Fprintf(h, "bounds %d %d\n", b.Start, b.Stop)
+		}
+		if !e.encode(reflect.ValueOf(spec)) {
+			return "", false
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// keyEncoder writes the contents of a procedure spec to a hash.
+type keyEncoder struct {
+	h    hash.Hash
+	now  time.Time
+	seen map[uintptr]bool
+}
+
+// encode writes v to the hash and reports whether
+// v can be part of the key of a cached result.
+func (e *keyEncoder) encode(v reflect.Value) bool {
+	if !v.IsValid() {
+		fmt.Fprint(e.h, "nil;")
+		return true
+	}
+
+	switch v.Type() {
+	case fluxTimeType, fluxBoundsType, timeType, resolvedFunctionType:
+		if !v.CanInterface() {
+			return false
+		}
+		switch x := v.Interface().(type) {
+		case flux.Time:
+			fmt.Fprintf(e.h, "time %d;", x.Time(e.now).UnixNano())
+		case flux.Bounds:
+			// The now of the bounds is ignored as
+			// the times are resolved with it.
+			now := x.Now
+			if now.IsZero() {
+				now = e.now
+			}
+			fmt.Fprintf(e.h, "bounds %d %d;", x.Start.Time(now).UnixNano(), x.Stop.Time(now).UnixNano())
+		case time.Time:
+			fmt.Fprintf(e.h, "time %d;", x.UnixNano())
+		case interpreter.ResolvedFunction:
+			return e.encodeFunction(x)
+		}
+		return true
+	}
+	if v.Kind() != reflect.Interface && v.Type().Implements(valueType) && v.CanInterface() {
+		return e.encodeValue(v.Interface().(values.Value))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			fmt.Fprint(e.h, "nil;")
+			return true
+		}
+		if e.seen[v.Pointer()] {
+			fmt.Fprint(e.h, "cycle;")
+			return true
+		}
+		e.seen[v.Pointer()] = true
+		defer delete(e.seen, v.Pointer())
+		if v.Type().Implements(semanticNodeType) && v.CanInterface() {
+			fmt.Fprintf(e.h, "%v;", semantic.Formatted(v.Interface().(semantic.Node)))
+			return true
+		}
+		return e.encode(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			fmt.Fprint(e.h, "nil;")
+			return true
+		}
+		fmt.Fprintf(e.h, "%s:", v.Elem().Type())
+		return e.encode(v.Elem())
+	case reflect.Struct:
+		fmt.Fprintf(e.h, "%s{", v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(e.h, "%s:", v.Type().Field(i).Name)
+			if !e.encode(v.Field(i)) {
+				return false
+			}
+		}
+		fmt.Fprint(e.h, "}")
+		return true
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(e.h, "[%d:", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if !e.encode(v.Index(i)) {
+				return false
+			}
+		}
+		fmt.Fprint(e.h, "]")
+		return true
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		fmt.Fprintf(e.h, "map[%d:", len(keys))
+		for _, k := range keys {
+			if !e.encode(k) || !e.encode(v.MapIndex(k)) {
+				return false
+			}
+		}
+		fmt.Fprint(e.h, "]")
+		return true
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		fmt.Fprintf(e.h, "%q;", fmt.Sprint(v))
+		return true
+	default:
+		// Functions and channels cannot be compared.
+		return false
+	}
+}
+
+// encodeValue writes a Flux value to the hash.
+func (e *keyEncoder) encodeValue(v values.Value) bool {
+	if v.IsNull() {
+		fmt.Fprint(e.h, "null;")
+		return true
+	}
+	switch n := v.Type().Nature(); n {
+	case semantic.String, semantic.Bytes, semantic.Int, semantic.UInt, semantic.Float,
+		semantic.Bool, semantic.Time, semantic.Duration, semantic.Regexp:
+		fmt.Fprintf(e.h, "%s %q;", n, values.DisplayString(v))
+		return true
+	case semantic.Array:
+		arr := v.Array()
+		ok := true
+		fmt.Fprintf(e.h, "[%d:", arr.Len())
+		arr.Range(func(i int, v values.Value) {
+			ok = ok && e.encodeValue(v)
+		})
+		fmt.Fprint(e.h, "]")
+		return ok
+	case semantic.Object:
+		obj := v.Object()
+		ok := true
+		fmt.Fprint(e.h, "{")
+		obj.Range(func(name string, v values.Value) {
+			fmt.Fprintf(e.h, "%q:", name)
+			ok = ok && e.encodeValue(v)
+		})
+		fmt.Fprint(e.h, "}")
+		return ok
+	case semantic.Dictionary:
+		dict := v.Dict()
+		ok := true
+		fmt.Fprintf(e.h, "dict[%d:", dict.Len())
+		dict.Range(func(key, value values.Value) {
+			ok = ok && e.encodeValue(key) && e.encodeValue(value)
+		})
+		fmt.Fprint(e.h, "]")
+		return ok
+	default:
+		return false
+	}
+}
+
+// encodeFunction writes a function to the hash.
+//
+// The values that the function references in its scope have
+// already been substituted into the function when possible.
+// The remaining references are to builtin values which are
+// deterministic unless they read the current time or have
+// side effects.
+func (e *keyEncoder) encodeFunction(fn interpreter.ResolvedFunction) bool {
+	if fn.Fn == nil {
+		fmt.Fprint(e.h, "nil;")
+		return true
+	}
+	fmt.Fprintf(e.h, "fn %v;", semantic.Formatted(fn.Fn))
+	if fn.Scope == nil {
+		return true
+	}
+
+	ok := true
+	semantic.Walk(semantic.CreateVisitor(func(node semantic.Node) {
+		if !ok {
+			return
+		}
+		switch node := node.(type) {
+		case *semantic.IdentifierExpression:
+			if node.Name == interpreter.NowOption {
+				ok = false
+				return
+			}
+			v, found := fn.Scope.Lookup(node.Name)
+			if !found {
+				return
+			}
+			if pkg, isPkg := v.(values.Package); isPkg {
+				ok = !nondeterministicPackages[pkg.Path()]
+			} else if v.Type().Nature() == semantic.Function {
+				ok = !v.Function().HasSideEffect()
+			}
+		case *semantic.MemberExpression:
+			id, isID := node.Object.(*semantic.IdentifierExpression)
+			if !isID {
+				return
+			}
+			v, found := fn.Scope.Lookup(id.Name)
+			if !found {
+				return
+			}
+			if pkg, isPkg := v.(values.Package); isPkg {
+				if member, found := pkg.Get(node.Property); found && member.Type().Nature() == semantic.Function {
+					ok = !member.Function().HasSideEffect()
+				}
+			}
+		}
+	}), fn.Fn)
+	return ok
+}
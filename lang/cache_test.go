@@ -0,0 +1,221 @@
+package lang_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/metadata"
+	"github.com/influxdata/flux/runtime"
+)
+
+const cacheTestData = `
+import "array"
+
+data = array.from(rows: [
+	{_time: 2020-01-01T00:00:00Z, _value: 1.0},
+	{_time: 2020-01-01T00:30:00Z, _value: 2.0},
+	{_time: 2020-01-01T01:00:00Z, _value: 3.0},
+])
+`
+
+func runCachedQuery(t *testing.T, cache lang.ResultCache, src string, now time.Time) ([]*executetest.Table, metadata.Metadata) {
+	t.Helper()
+
+	program, err := lang.Compile(src, runtime.Default, now, lang.WithResultCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	alloc := &memory.Allocator{}
+	q, err := program.Start(ctx, alloc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tables []*executetest.Table
+	for res := range q.Results() {
+		tables = append(tables, getTablesFromResultOrFail(t, res)...)
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := alloc.Allocated(); got != 0 {
+		t.Errorf("query leaked %d bytes", got)
+	}
+	return tables, q.Statistics().Metadata
+}
+
+func cacheCounts(md metadata.Metadata) (hits, misses int) {
+	if v, ok := md[lang.ResultCacheHitsKey]; ok {
+		hits = v[0].(int)
+	}
+	if v, ok := md[lang.ResultCacheMissesKey]; ok {
+		misses = v[0].(int)
+	}
+	return hits, misses
+}
+
+func TestResultCache(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		src  string
+		// nows are the values of now for each run of the query.
+		nows []time.Time
+		// want are the hits and misses expected for each run.
+		want [][2]int
+	}{
+		{
+			name: "absolute range",
+			src:  cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-01T01:00:00Z)`,
+			nows: []time.Time{now, now.Add(time.Minute), now.Add(time.Hour)},
+			want: [][2]int{{0, 1}, {1, 0}, {1, 0}},
+		},
+		{
+			name: "relative range",
+			src:  cacheTestData + `data |> range(start: -24h)`,
+			nows: []time.Time{now, now, now.Add(time.Minute)},
+			want: [][2]int{{0, 1}, {1, 0}, {0, 1}},
+		},
+		{
+			name: "deterministic function",
+			src:  cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-02T00:00:00Z) |> map(fn: (r) => ({r with _value: r._value * 2.0}))`,
+			nows: []time.Time{now, now.Add(time.Minute)},
+			want: [][2]int{{0, 1}, {1, 0}},
+		},
+		{
+			name: "function reads now",
+			src:  cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z) |> map(fn: (r) => ({r with t: now()}))`,
+			nows: []time.Time{now, now},
+			want: [][2]int{{0, 0}, {0, 0}},
+		},
+		{
+			name: "function reads system time",
+			src: `import "system"
+` + cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z) |> map(fn: (r) => ({r with t: system.time()}))`,
+			nows: []time.Time{now, now},
+			want: [][2]int{{0, 0}, {0, 0}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cache := lang.NewMemoryResultCache(1 << 20)
+			var first []*executetest.Table
+			for i, now := range tc.nows {
+				tables, md := runCachedQuery(t, cache, tc.src, now)
+				if hits, misses := cacheCounts(md); hits != tc.want[i][0] || misses != tc.want[i][1] {
+					t.Errorf("run %d: unexpected hits/misses -want/+got:\n\t- %v\n\t+ %v", i, tc.want[i], [2]int{hits, misses})
+				}
+				if len(tables) == 0 {
+					t.Fatalf("run %d: expected tables", i)
+				}
+				if i == 0 {
+					first = tables
+				} else if tc.want[i][0] > 0 {
+					if !cmp.Equal(first, tables) {
+						t.Errorf("run %d: cached tables differ -want/+got:\n%s", i, cmp.Diff(first, tables))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestResultCache_Eviction(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	queries := []string{
+		cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-01T00:10:00Z)`,
+		cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-01T00:20:00Z)`,
+	}
+
+	// Find the size of the results of a single query
+	// so the cache can be made to fit exactly one.
+	sizer := &sizingCache{}
+	runCachedQuery(t, sizer, queries[0], now)
+	if sizer.size == 0 {
+		t.Fatal("expected results to be stored")
+	}
+
+	cache := lang.NewMemoryResultCache(sizer.size)
+	for i, want := range [][2]int{
+		// The second query evicts the first.
+		{0, 1}, {0, 1}, {0, 1}, {0, 1},
+	} {
+		_, md := runCachedQuery(t, cache, queries[i%2], now)
+		if hits, misses := cacheCounts(md); hits != want[0] || misses != want[1] {
+			t.Errorf("run %d: unexpected hits/misses -want/+got:\n\t- %v\n\t+ %v", i, want, [2]int{hits, misses})
+		}
+	}
+	if _, md := runCachedQuery(t, cache, queries[1], now); md[lang.ResultCacheHitsKey] == nil {
+		t.Error("expected the most recent query to be cached")
+	}
+
+	// Results that do not fit are never stored.
+	cache = lang.NewMemoryResultCache(sizer.size - 1)
+	for i := 0; i < 2; i++ {
+		_, md := runCachedQuery(t, cache, queries[0], now)
+		if hits, _ := cacheCounts(md); hits != 0 {
+			t.Errorf("run %d: unexpected cache hit", i)
+		}
+	}
+}
+
+// sizingCache records the size of the results it is given.
+type sizingCache struct {
+	size int64
+}
+
+func (c *sizingCache) Get(key string) (*lang.CachedResults, bool) {
+	return nil, false
+}
+
+func (c *sizingCache) Put(key string, results *lang.CachedResults) {
+	c.size = results.Size()
+}
+
+func (c *sizingCache) MaxSize() int64 {
+	return 0
+}
+
+func TestResultCache_MaxSize(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	src := cacheTestData + `data |> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-01T01:00:00Z)`
+
+	sizer := &sizingCache{}
+	runCachedQuery(t, sizer, src, now)
+	if sizer.size == 0 {
+		t.Fatal("expected results to be stored")
+	}
+
+	// The results stop being recorded once they are larger
+	// than the cache can store, so they are never given to it.
+	cache := &boundedCache{t: t, maxSize: sizer.size - 1}
+	if _, md := runCachedQuery(t, cache, src, now); md[lang.ResultCacheMissesKey] == nil {
+		t.Error("expected a cache miss")
+	}
+}
+
+// boundedCache fails the test if it is given results
+// that are larger than its max size.
+type boundedCache struct {
+	t       *testing.T
+	maxSize int64
+}
+
+func (c *boundedCache) Get(key string) (*lang.CachedResults, bool) {
+	return nil, false
+}
+
+func (c *boundedCache) Put(key string, results *lang.CachedResults) {
+	c.t.Errorf("unexpected results of %d bytes stored in a cache of %d bytes", results.Size(), c.maxSize)
+}
+
+func (c *boundedCache) MaxSize() int64 {
+	return c.maxSize
+}
@@ -28,6 +28,10 @@ const (
 	ASTCompilerType  = "ast"
 )
 
+// locationOption is the option of the universe package that
+// holds the default location of windows and dates.
+const locationOption = "location"
+
 // AddCompilerMappings adds the Flux specific compiler mappings.
 func AddCompilerMappings(mappings flux.CompilerMappings) error {
 	if err := mappings.Add(FluxCompilerType, func() flux.Compiler {
@@ -53,6 +57,12 @@ type compileOptions struct {
 
 	extern flux.ASTHandle
 
+	resultCache ResultCache
+
+	continuous bool
+
+	limits flux.QueryLimits
+
 	planOptions struct {
 		logical  []plan.LogicalOption
 		physical []plan.PhysicalOption
@@ -75,6 +85,25 @@ func WithExtern(extern flux.ASTHandle) CompileOption {
 	}
 }
 
+// WithContinuousExecution executes the program continuously.
+// The results of the query are the panes of its yields that are emitted
+// as the sources advance the watermark, and the query produces results
+// until it is cancelled or all of its sources finish.
+func WithContinuousExecution() CompileOption {
+	return func(o *compileOptions) {
+		o.continuous = true
+	}
+}
+
+// WithQueryLimits limits the resources the program may use when it executes.
+// The limits of the dependencies of the query, if any, still apply,
+// so the lower of each limit is the one that is enforced.
+func WithQueryLimits(l flux.QueryLimits) CompileOption {
+	return func(o *compileOptions) {
+		o.limits = l
+	}
+}
+
 func defaultOptions() *compileOptions {
 	o := new(compileOptions)
 	return o
@@ -154,6 +183,9 @@ func buildPlan(ctx context.Context, spec *flux.Spec, opts *compileOptions) (*pla
 	pb.AddLogicalOptions(lopts...)
 	pb.AddPhysicalOptions(popts...)
 
+	if opts.continuous {
+		ctx = execute.WithContinuousExecution(ctx)
+	}
 	ps, err := pb.Build().Plan(ctx, spec)
 	if err != nil {
 		return nil, err
@@ -294,6 +326,15 @@ func (p *Program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Quer
 		q.stats.Metadata.AddAll(deps.Metadata)
 	}
 
+	q.limits = flux.GetQueryLimits(cctx)
+	if p.opts != nil {
+		q.limits = q.limits.Merge(p.opts.limits)
+	}
+	if !q.limits.IsZero() {
+		cctx = flux.WithQueryLimits(cctx, q.limits)
+		q.ctx = cctx
+	}
+
 	if traceID, sampled, found := jaeger.InfoFromSpan(s); found {
 		q.stats.Metadata.Add("tracing/id", traceID)
 		q.stats.Metadata.Add("tracing/sampled", sampled)
@@ -302,6 +343,46 @@ func (p *Program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Quer
 	q.stats.Metadata.Add("flux/query-plan",
 		fmt.Sprintf("%v", plan.Formatted(p.PlanSpec, plan.WithDetails())))
 
+	if p.opts != nil && p.opts.continuous {
+		e, ok := execute.NewExecutor(p.Logger).(execute.ContinuousExecutor)
+		if !ok {
+			s.Finish()
+			return nil, errors.New(codes.Unimplemented, "the executor does not support continuous execution")
+		}
+		panes, md, err := e.ExecuteContinuous(cctx, p.PlanSpec, q.alloc)
+		if err != nil {
+			s.Finish()
+			return nil, err
+		}
+		q.wg.Add(2)
+		go p.processPanes(cctx, q, panes)
+		go p.readMetadata(q, md)
+		return q, nil
+	}
+
+	var (
+		cache ResultCache
+		key   string
+	)
+	if p.opts != nil && p.opts.resultCache != nil {
+		if k, ok := planKey(p.PlanSpec); ok {
+			cache, key = p.opts.resultCache, k
+		}
+	}
+	if cache != nil {
+		if cached, ok := cache.Get(key); ok {
+			// Serve the results from the cache without executing the plan.
+			q.stats.Metadata.Add(ResultCacheHitsKey, 1)
+			q.onDone = func(error) {
+				cached.Release()
+			}
+			q.wg.Add(1)
+			go p.processResults(cctx, q, cached.resultMap())
+			return q, nil
+		}
+		q.stats.Metadata.Add(ResultCacheMissesKey, 1)
+	}
+
 	e := execute.NewExecutor(p.Logger)
 	resultMap, md, err := e.Execute(cctx, p.PlanSpec, q.alloc)
 	if err != nil {
@@ -309,6 +390,16 @@ func (p *Program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Quer
 		return nil, err
 	}
 
+	if cache != nil {
+		// Record the results as they are read and
+		// store them once the query is done.
+		var recorder *resultRecorder
+		recorder, resultMap = newResultRecorder(resultMap, q.alloc, cache.MaxSize())
+		q.onDone = func(err error) {
+			recorder.finish(err, cache, key)
+		}
+	}
+
 	// There was no error so send the results downstream.
 	q.wg.Add(1)
 	go p.processResults(cctx, q, resultMap)
@@ -334,6 +425,24 @@ func (p *Program) processResults(ctx context.Context, q *query, resultMap map[st
 	}
 }
 
+// processPanes sends the panes of a continuous query downstream.
+// Cancelling the query is how it normally ends, so it is not an error.
+func (p *Program) processPanes(ctx context.Context, q *query, panes <-chan flux.Result) {
+	defer q.wg.Done()
+	defer close(q.results)
+
+	for res := range panes {
+		select {
+		case q.results <- res:
+		case <-ctx.Done():
+			// Drain the panes so the execution can shut down.
+			for range panes {
+			}
+			return
+		}
+	}
+}
+
 func (p *Program) readMetadata(q *query, metaCh <-chan metadata.Metadata) {
 	defer q.wg.Done()
 	for md := range metaCh {
@@ -443,7 +552,7 @@ func (p *AstProgram) getSpec(ctx context.Context, alloc *memory.Allocator) (*flu
 	// TODO(jsternberg): Personal note, I don't like how now interacts with
 	// the runtime and flux code in so many places. We should evaluate how
 	// now is used and see if we can improve how now interacts with the system.
-	var nowOpt values.Value
+	var nowOpt, locationOpt values.Value
 	sideEffects, scope, err := p.Runtime.Eval(cctx, ast, &ExecOptsConfig{},
 		flux.SetNowOption(p.Now),
 		func(r flux.Runtime, scope values.Scope) {
@@ -451,6 +560,9 @@ func (p *AstProgram) getSpec(ctx context.Context, alloc *memory.Allocator) (*flu
 			if _, ok := nowOpt.(*values.Option); !ok {
 				panic("now must be an option")
 			}
+			// Capture the location option in the same way to read the
+			// value the query gives it once the query is evaluated.
+			locationOpt, _ = scope.Lookup(locationOption)
 		},
 	)
 	if err != nil {
@@ -469,6 +581,20 @@ func (p *AstProgram) getSpec(ctx context.Context, alloc *memory.Allocator) (*flu
 	if err != nil {
 		return nil, nil, errors.Wrap(err, codes.Inherit, "error in query specification while starting program")
 	}
+	if locationOpt != nil {
+		location, err := execute.LocationFromValue(locationOpt)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, codes.Inherit, "error in evaluating location option while starting program")
+		}
+		// Windows read the location from the spec while the query is planned
+		// and date functions read it from the dependencies when they run.
+		sp.Location = location
+		if execute.HaveExecutionDependencies(ctx) {
+			if deps := execute.GetExecutionDependencies(ctx); deps.Location != nil {
+				*deps.Location = location
+			}
+		}
+	}
 	return sp, scope, nil
 }
 
@@ -531,9 +657,7 @@ func (p *AstProgram) updateOpts(scope values.Scope) error {
 	if lo != nil {
 		p.opts.planOptions.logical = append(p.opts.planOptions.logical, lo)
 	}
-	if po != nil {
-		p.opts.planOptions.physical = append(p.opts.planOptions.physical, po)
-	}
+	p.opts.planOptions.physical = append(p.opts.planOptions.physical, po...)
 	return nil
 }
 
@@ -554,7 +678,7 @@ func getPackageFromScope(pkgName string, scope values.Scope) (values.Package, bo
 	return foundPkg, found
 }
 
-func getPlanOptions(plannerPkg values.Package) (plan.LogicalOption, plan.PhysicalOption, error) {
+func getPlanOptions(plannerPkg values.Package) (plan.LogicalOption, []plan.PhysicalOption, error) {
 	if plannerPkg.Type().Nature() != semantic.Object {
 		// No import for planner, this is useless.
 		return nil, nil, nil
@@ -568,7 +692,13 @@ func getPlanOptions(plannerPkg values.Package) (plan.LogicalOption, plan.Physica
 	if err != nil {
 		return nil, nil, err
 	}
-	return plan.RemoveLogicalRules(ls...), plan.RemovePhysicalRules(ps...), nil
+	popts := []plan.PhysicalOption{plan.RemovePhysicalRules(ps...)}
+	if v, ok := plannerPkg.Object().Get("parallelism"); ok && v.Type().Nature() == semantic.Int {
+		if n := v.Int(); n > 1 {
+			popts = append(popts, plan.WithParallelism(int(n)))
+		}
+	}
+	return plan.RemoveLogicalRules(ls...), popts, nil
 }
 
 func getOptionValues(pkg values.Object, optionName string) ([]string, error) {
@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
 	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/interval"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/mock"
@@ -29,6 +30,7 @@ import (
 	"github.com/influxdata/flux/stdlib/csv"
 	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
 	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
 )
@@ -852,6 +854,114 @@ option planner.disableLogicalRules = ["removeCountRule"]`},
 	}
 }
 
+func TestCompileOptions_Parallelism(t *testing.T) {
+	nowFn := func() time.Time {
+		return parser.MustParseTime("2018-10-10T00:00:00Z").Value
+	}
+	astPkg, err := runtime.Parse(`
+import "planner"
+
+option planner.parallelism = 4
+option planner.disablePhysicalRules = ["influxdata/influxdb.MergeRemoteFilterRule"]
+
+from(bucket: "bkt") |> range(start: 0) |> filter(fn: (r) => r._value > 0)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	program := lang.CompileAST(astPkg, runtime.Default, nowFn())
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	if _, err := program.Start(ctx, &memory.Allocator{}); err != nil {
+		t.Fatalf("failed to start program: %v", err)
+	}
+
+	got := make(map[plan.ProcedureKind]int)
+	if err := program.PlanSpec.BottomUpWalk(func(node plan.Node) error {
+		got[node.Kind()] = node.(*plan.PhysicalPlanNode).Parallelism
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got[universe.FilterKind] != 4 {
+		t.Errorf("expected filter to have a parallelism of 4, got %d", got[universe.FilterKind])
+	}
+	if got[universe.YieldKind] != 0 {
+		t.Errorf("expected yield to have no parallelism, got %d", got[universe.YieldKind])
+	}
+	if got, want := program.PlanSpec.Resources.ConcurrencyQuota, 4; got != want {
+		t.Errorf("unexpected concurrency quota: want %d got %d", want, got)
+	}
+}
+
+func TestCompileOptions_Location(t *testing.T) {
+	astPkg, err := runtime.Parse(`
+import "array"
+import "date"
+import "timezone"
+
+option location = timezone.location(name: "Europe/Paris")
+
+array.from(rows: [{_time: 2020-10-25T12:00:00Z, _value: 1}])
+	|> range(start: 2020-10-24T00:00:00Z, stop: 2020-10-27T00:00:00Z)
+	|> window(every: 1d)
+	|> map(fn: (r) => ({r with hour: date.hour(t: r._start), day: date.truncate(t: r._time, unit: 1d)}))
+	|> keep(columns: ["_start", "_stop", "hour", "day"])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	program := lang.CompileAST(astPkg, runtime.Default, time.Now())
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	q, err := program.Start(ctx, &memory.Allocator{})
+	if err != nil {
+		t.Fatalf("failed to start program: %v", err)
+	}
+	defer q.Done()
+
+	// The window is planned with the location of the option.
+	if err := program.PlanSpec.BottomUpWalk(func(node plan.Node) error {
+		if spec, ok := node.ProcedureSpec().(*universe.WindowProcedureSpec); ok {
+			if want, got := (interval.Location{Zone: "Europe/Paris"}), spec.Window.Location; want != got {
+				t.Errorf("unexpected window location -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*executetest.Table
+	for r := range q.Results() {
+		got = append(got, getTablesFromResultOrFail(t, r)...)
+	}
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*executetest.Table{{
+		KeyCols: []string{"_start", "_stop"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_start", Type: flux.TTime},
+			{Label: "_stop", Type: flux.TTime},
+			{Label: "day", Type: flux.TTime},
+			{Label: "hour", Type: flux.TInt},
+		},
+		Data: [][]interface{}{
+			{
+				values.ConvertTime(parser.MustParseTime("2020-10-24T22:00:00Z").Value),
+				values.ConvertTime(parser.MustParseTime("2020-10-25T23:00:00Z").Value),
+				values.ConvertTime(parser.MustParseTime("2020-10-24T22:00:00Z").Value),
+				int64(0),
+			},
+		},
+	}}
+	executetest.NormalizeTables(want)
+	executetest.NormalizeTables(got)
+	if !cmp.Equal(want, got) {
+		t.Fatalf("unexpected tables -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
 func TestQueryTracing(t *testing.T) {
 	// temporarily install a mock tracer to see which spans are created.
 	oldTracer := opentracing.GlobalTracer()
@@ -0,0 +1,101 @@
+package lang_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/runtime"
+)
+
+func TestQueryLimits(t *testing.T) {
+	const src = `
+import "array"
+
+array.from(rows: [
+	{_time: 2020-01-01T00:00:00Z, _value: 1.0},
+	{_time: 2020-01-01T00:30:00Z, _value: 2.0},
+	{_time: 2020-01-01T01:00:00Z, _value: 3.0},
+])
+`
+	for _, tc := range []struct {
+		name    string
+		deps    flux.QueryLimits
+		program flux.QueryLimits
+		wantErr string
+	}{
+		{
+			name:    "within limits",
+			deps:    flux.QueryLimits{MaxRowsRead: 3, MaxOutputBytes: 1 << 10},
+			program: flux.QueryLimits{MaxSeries: 1, MaxRuntime: time.Minute},
+		},
+		{
+			name:    "program limits",
+			program: flux.QueryLimits{MaxRowsRead: 2},
+			wantErr: "query exceeded the max rows read limit of 2",
+		},
+		{
+			name:    "dependency limits",
+			deps:    flux.QueryLimits{MaxRowsRead: 2},
+			wantErr: "query exceeded the max rows read limit of 2",
+		},
+		{
+			name:    "lower limit applies",
+			deps:    flux.QueryLimits{MaxRowsRead: 2},
+			program: flux.QueryLimits{MaxRowsRead: 100},
+			wantErr: "query exceeded the max rows read limit of 2",
+		},
+		{
+			name:    "max output bytes",
+			program: flux.QueryLimits{MaxOutputBytes: 64},
+			wantErr: "query exceeded the output bytes limit of 64",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := lang.Compile(src, runtime.Default, time.Now(), lang.WithQueryLimits(tc.program))
+			if err != nil {
+				t.Fatal(err)
+			}
+			deps := dependenciestest.Default()
+			deps.Deps.QueryLimits = tc.deps
+			ctx := deps.Inject(context.Background())
+			q, err := program.Start(ctx, &memory.Allocator{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			enc := csv.NewMultiResultEncoder(csv.DefaultEncoderConfig())
+			_, err = enc.Encode(&buf, flux.NewResultIteratorFromQuery(q))
+			q.Done()
+			if err == nil {
+				err = q.Err()
+			}
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := err.Error(); !strings.Contains(got, tc.wantErr) {
+				t.Errorf("unexpected error: want %q got %q", tc.wantErr, got)
+			}
+			if got := errors.Code(err); got != codes.ResourceExhausted {
+				t.Errorf("unexpected error code: want %v got %v", codes.ResourceExhausted, got)
+			}
+		})
+	}
+}
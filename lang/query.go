@@ -20,12 +20,24 @@ type query struct {
 	cancel  func()
 	err     error
 	wg      sync.WaitGroup
+
+	// onDone is called with the error of the
+	// query once the query is done, if set.
+	onDone func(err error)
+
+	// limits are the limits of the query.
+	limits flux.QueryLimits
 }
 
 func (q *query) Results() <-chan flux.Result {
 	return q.results
 }
 
+// QueryLimits implements flux.QueryLimiter.
+func (q *query) QueryLimits() flux.QueryLimits {
+	return q.limits
+}
+
 func (q *query) Done() {
 	q.cancel()
 	q.wg.Wait()
@@ -43,6 +55,10 @@ func (q *query) Done() {
 		// If the testing framework was configured, verify all expectations.
 		q.err = testing.Check(q.ctx)
 	}
+	if q.onDone != nil {
+		q.onDone(q.err)
+		q.onDone = nil
+	}
 }
 
 func (q *query) Cancel() {
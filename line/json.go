@@ -0,0 +1,196 @@
+package line
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+)
+
+// JSONDecoderConfig is the configuration for a JSON decoder.
+type JSONDecoderConfig struct {
+	// Allocator is the allocator used for the table.
+	// A new allocator is used when this is nil.
+	Allocator *memory.Allocator
+	// TimeProvider gives the time of objects without a _time key.
+	// It defaults to the wall clock time.
+	TimeProvider TimeProvider
+}
+
+// JSONDecoder decodes a stream of JSON objects from a reader into a flux.Result.
+// The objects may be separated by whitespace, such as newline-delimited JSON,
+// and an array of objects is read as its elements.
+//
+// All of the objects are put into a single table with an empty group key.
+// The table has a `_time` column followed by a column for each of the keys
+// of the objects, in sorted order. Numbers are read as floats and nested
+// objects and arrays are kept as JSON strings. A key that is missing in
+// an object or that has a null value is null in the table. The `_time` key
+// must be an RFC3339 time string.
+type JSONDecoder struct {
+	reader io.Reader
+	config *JSONDecoderConfig
+}
+
+// NewJSONDecoder creates a new JSON decoder from config.
+func NewJSONDecoder(config *JSONDecoderConfig) *JSONDecoder {
+	return &JSONDecoder{config: config}
+}
+
+func (d *JSONDecoder) Decode(r io.Reader) (flux.Result, error) {
+	d.reader = r
+	return d, nil
+}
+
+func (*JSONDecoder) Name() string {
+	return "_result"
+}
+
+func (d *JSONDecoder) Tables() flux.TableIterator {
+	return d
+}
+
+func (d *JSONDecoder) Do(f func(flux.Table) error) error {
+	alloc := d.config.Allocator
+	if alloc == nil {
+		alloc = &memory.Allocator{}
+	}
+
+	var (
+		rows  []map[string]values.Value
+		types = make(map[string]flux.ColType)
+	)
+	add := func(obj map[string]json.RawMessage) error {
+		row := make(map[string]values.Value, len(obj))
+		for k, raw := range obj {
+			v, err := jsonValue(k, raw)
+			if err != nil {
+				return err
+			} else if v == nil {
+				continue
+			}
+			typ := flux.ColumnType(v.Type())
+			if t, ok := types[k]; !ok {
+				types[k] = typ
+			} else if t != typ {
+				return errors.Newf(codes.Invalid, "key %q has type %s and %s", k, t, typ)
+			}
+			row[k] = v
+		}
+		if _, ok := row[execute.DefaultTimeColLabel]; !ok {
+			row[execute.DefaultTimeColLabel] = d.currentTime()
+			types[execute.DefaultTimeColLabel] = flux.TTime
+		}
+		rows = append(rows, row)
+		return nil
+	}
+
+	dec := json.NewDecoder(d.reader)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, codes.Invalid, "failed to decode JSON")
+		}
+
+		var objs []map[string]json.RawMessage
+		if len(raw) > 0 && raw[0] == '[' {
+			if err := json.Unmarshal(raw, &objs); err != nil {
+				return errors.Wrap(err, codes.Invalid, "expected an array of JSON objects")
+			}
+		} else {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+				return errors.Newf(codes.Invalid, "expected a JSON object, got %s", raw)
+			}
+			objs = append(objs, obj)
+		}
+		for _, obj := range objs {
+			if err := add(obj); err != nil {
+				return err
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	labels := make([]string, 0, len(types))
+	for k := range types {
+		if k != execute.DefaultTimeColLabel {
+			labels = append(labels, k)
+		}
+	}
+	sort.Strings(labels)
+	labels = append([]string{execute.DefaultTimeColLabel}, labels...)
+
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), alloc)
+	for _, label := range labels {
+		if _, err := b.AddCol(flux.ColMeta{Label: label, Type: types[label]}); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		for j, label := range labels {
+			v, ok := row[label]
+			if !ok {
+				v = values.NewNull(flux.SemanticType(types[label]))
+			}
+			if err := b.AppendValue(j, v); err != nil {
+				return err
+			}
+		}
+	}
+	tbl, err := b.Table()
+	if err != nil {
+		return err
+	}
+	return f(tbl)
+}
+
+func (d *JSONDecoder) currentTime() values.Value {
+	if tp := d.config.TimeProvider; tp != nil {
+		return values.NewTime(tp.CurrentTime())
+	}
+	return values.NewTime(values.ConvertTime(time.Now()))
+}
+
+// jsonValue converts a JSON value into a Flux value.
+// It returns nil for a null value.
+func jsonValue(key string, raw json.RawMessage) (values.Value, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, errors.Wrapf(err, codes.Invalid, "invalid value of key %q", key)
+	}
+	if key == execute.DefaultTimeColLabel {
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Newf(codes.Invalid, "key %q must be an RFC3339 time string, got %s", key, raw)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "invalid time of key %q", key)
+		}
+		return values.NewTime(values.ConvertTime(t)), nil
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return values.NewFloat(v), nil
+	case string:
+		return values.NewString(v), nil
+	case bool:
+		return values.NewBool(v), nil
+	default:
+		return values.NewString(string(raw)), nil
+	}
+}
@@ -0,0 +1,122 @@
+package line_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/line"
+	"github.com/influxdata/flux/mock"
+)
+
+func TestJSONDecoder(t *testing.T) {
+	tcs := []struct {
+		name    string
+		input   string
+		want    []*executetest.Table
+		wantErr string
+	}{
+		{
+			name: "objects",
+			input: `{"_time": "1970-01-01T00:00:00.000000010Z", "host": "a", "v": 1, "ok": true}
+{"_time": "1970-01-01T00:00:00.000000020Z", "host": "b", "v": 2.5, "tags": ["x"], "extra": null}
+`,
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "ok", Type: flux.TBool},
+					{Label: "tags", Type: flux.TString},
+					{Label: "v", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(10), "a", true, nil, 1.0},
+					{execute.Time(20), "b", nil, `["x"]`, 2.5},
+				},
+			}},
+		},
+		{
+			name:  "array and missing time",
+			input: `[{"v": 1}, {"v": 2}] {"v": 3}`,
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "v", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(1), 2.0},
+					{execute.Time(2), 3.0},
+				},
+			}},
+		},
+		{
+			name:  "empty",
+			input: "\n",
+		},
+		{
+			name:    "type conflict",
+			input:   `{"v": 1} {"v": "1"}`,
+			wantErr: `key "v" has type float and string`,
+		},
+		{
+			name:    "invalid time",
+			input:   `{"_time": 10}`,
+			wantErr: `key "_time" must be an RFC3339 time string`,
+		},
+		{
+			name:    "not an object",
+			input:   `1`,
+			wantErr: "expected a JSON object",
+		},
+		{
+			name:    "invalid",
+			input:   `{"v": `,
+			wantErr: "failed to decode JSON",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			decoder := line.NewJSONDecoder(&line.JSONDecoderConfig{
+				TimeProvider: &mock.AscendingTimeProvider{},
+			})
+			r, err := decoder.Decode(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*executetest.Table
+			err = r.Tables().Do(func(table flux.Table) error {
+				ct, err := executetest.ConvertTable(table)
+				if err != nil {
+					return err
+				}
+				got = append(got, ct)
+				return nil
+			})
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if !strings.HasPrefix(err.Error(), tc.wantErr) {
+					t.Fatalf("unexpected error -want/+got:\n\t- %s\n\t+ %s", tc.wantErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(tc.want)
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
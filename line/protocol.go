@@ -0,0 +1,277 @@
+package line
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+	protocol "github.com/influxdata/line-protocol"
+)
+
+const (
+	measurementColLabel = "_measurement"
+	fieldColLabel       = "_field"
+)
+
+// DefaultProtocolBatchSize is the default number of points
+// the protocol decoder reads before it outputs its tables.
+const DefaultProtocolBatchSize = 1000
+
+// ProtocolDecoderConfig is the configuration for a line protocol decoder.
+type ProtocolDecoderConfig struct {
+	// Allocator is the allocator used for the tables.
+	// A new allocator is used when this is nil.
+	Allocator *memory.Allocator
+	// Precision is the unit of the timestamps in the input.
+	// It defaults to nanoseconds.
+	Precision time.Duration
+	// TimeProvider gives the time of points without a timestamp.
+	// It defaults to the wall clock time.
+	TimeProvider TimeProvider
+	// BatchSize is the number of points that are read before the
+	// tables are output. It defaults to DefaultProtocolBatchSize.
+	BatchSize int
+}
+
+// ProtocolDecoder decodes InfluxDB line protocol from a reader into a flux.Result.
+// Each field of each series is put into its own table with the schema
+// `_time`, `_value`, `_field`, `_measurement` followed by the tag keys.
+// The group key is made of the measurement, the tags and the field.
+//
+// The input is decoded in batches of points so that a stream that does
+// not end, such as a socket, produces tables as it is read. The tables of
+// a batch are output in the order their first point was read, so a series
+// that spans more than one batch is output as more than one table.
+type ProtocolDecoder struct {
+	reader io.Reader
+	config *ProtocolDecoderConfig
+}
+
+// NewProtocolDecoder creates a new line protocol decoder from config.
+func NewProtocolDecoder(config *ProtocolDecoderConfig) *ProtocolDecoder {
+	return &ProtocolDecoder{config: config}
+}
+
+func (d *ProtocolDecoder) Decode(r io.Reader) (flux.Result, error) {
+	d.reader = r
+	return d, nil
+}
+
+func (*ProtocolDecoder) Name() string {
+	return "_result"
+}
+
+func (d *ProtocolDecoder) Tables() flux.TableIterator {
+	return d
+}
+
+// protocolTable holds the points of a single table while the input is read.
+type protocolTable struct {
+	key    flux.GroupKey
+	typ    flux.ColType
+	times  []values.Time
+	values []values.Value
+}
+
+func (d *ProtocolDecoder) Do(f func(flux.Table) error) error {
+	alloc := d.config.Allocator
+	if alloc == nil {
+		alloc = &memory.Allocator{}
+	}
+
+	parser := protocol.NewStreamParser(lineReader{r: bufio.NewReader(d.reader)})
+	if d.config.Precision > 0 {
+		parser.SetTimePrecision(d.config.Precision)
+	}
+	if tp := d.config.TimeProvider; tp != nil {
+		parser.SetTimeFunc(func() time.Time {
+			return tp.CurrentTime().Time()
+		})
+	}
+
+	batchSize := d.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultProtocolBatchSize
+	}
+
+	var (
+		tables []*protocolTable
+		lookup = make(map[string]*protocolTable)
+		// types holds the type of every field that was read
+		// so that it is the same in all of the batches.
+		types = make(map[string]flux.ColType)
+		n     int
+	)
+	flush := func() error {
+		for _, t := range tables {
+			tbl, err := t.table(alloc)
+			if err != nil {
+				return err
+			}
+			if err := f(tbl); err != nil {
+				return err
+			}
+		}
+		tables, n = nil, 0
+		lookup = make(map[string]*protocolTable)
+		return nil
+	}
+
+	for {
+		m, err := parser.Next()
+		if err == protocol.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, codes.Invalid, "failed to parse line protocol")
+		}
+		for _, tag := range m.TagList() {
+			if isReservedLabel(tag.Key) {
+				return errors.Newf(codes.Invalid, "measurement %q has a tag with the reserved key %q", m.Name(), tag.Key)
+			}
+		}
+
+		ts := values.ConvertTime(m.Time())
+		for _, field := range m.FieldList() {
+			v := values.New(field.Value)
+			typ := flux.ColumnType(v.Type())
+			id := seriesID(m, field.Key)
+			if want, ok := types[id]; !ok {
+				types[id] = typ
+			} else if typ != want {
+				return errors.Newf(codes.Invalid, "field %q of measurement %q has type %s and %s", field.Key, m.Name(), want, typ)
+			}
+			t, ok := lookup[id]
+			if !ok {
+				t = &protocolTable{
+					key: protocolGroupKey(m, field.Key),
+					typ: typ,
+				}
+				lookup[id] = t
+				tables = append(tables, t)
+			}
+			t.times = append(t.times, ts)
+			t.values = append(t.values, v)
+			n++
+		}
+		if n >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// lineReader returns at most one line from each call to Read.
+// The stream parser reads more input before it parses the input it
+// already has, so this keeps it from waiting for the next line when
+// it has already read a complete point.
+type lineReader struct {
+	r *bufio.Reader
+}
+
+func (r lineReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		p[n] = b
+		n++
+		if b == '\n' {
+			break
+		}
+	}
+	return n, nil
+}
+
+// isReservedLabel reports whether a tag key would
+// collide with one of the columns of the tables.
+func isReservedLabel(key string) bool {
+	switch key {
+	case execute.DefaultTimeColLabel, execute.DefaultValueColLabel, fieldColLabel, measurementColLabel:
+		return true
+	}
+	return false
+}
+
+func (t *protocolTable) table(alloc *memory.Allocator) (flux.Table, error) {
+	b := execute.NewColListTableBuilder(t.key, alloc)
+	if _, err := b.AddCol(flux.ColMeta{Label: execute.DefaultTimeColLabel, Type: flux.TTime}); err != nil {
+		return nil, err
+	}
+	if _, err := b.AddCol(flux.ColMeta{Label: execute.DefaultValueColLabel, Type: t.typ}); err != nil {
+		return nil, err
+	}
+	if err := execute.AddTableKeyCols(t.key, b); err != nil {
+		return nil, err
+	}
+	for i := range t.times {
+		if err := b.AppendTime(0, t.times[i]); err != nil {
+			return nil, err
+		}
+		if err := b.AppendValue(1, t.values[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := execute.AppendKeyValuesN(t.key, b, len(t.times)); err != nil {
+		return nil, err
+	}
+	return b.Table()
+}
+
+// seriesID returns a string that identifies the table of a field.
+func seriesID(m protocol.Metric, field string) string {
+	var sb strings.Builder
+	sb.WriteString(m.Name())
+	for _, tag := range m.TagList() {
+		sb.WriteByte(0)
+		sb.WriteString(tag.Key)
+		sb.WriteByte(0)
+		sb.WriteString(tag.Value)
+	}
+	sb.WriteByte(1)
+	sb.WriteString(field)
+	return sb.String()
+}
+
+func protocolGroupKey(m protocol.Metric, field string) flux.GroupKey {
+	tags := m.TagList()
+	cols := make([]flux.ColMeta, 0, len(tags)+2)
+	vs := make([]values.Value, 0, len(tags)+2)
+	cols = append(cols,
+		flux.ColMeta{Label: fieldColLabel, Type: flux.TString},
+		flux.ColMeta{Label: measurementColLabel, Type: flux.TString},
+	)
+	vs = append(vs, values.NewString(field), values.NewString(m.Name()))
+	for _, tag := range tags {
+		cols = append(cols, flux.ColMeta{Label: tag.Key, Type: flux.TString})
+		vs = append(vs, values.NewString(tag.Value))
+	}
+	// The tags are sorted by key, but a tag may sort before
+	// the field and measurement, so sort all of the columns.
+	sort.Sort(groupKeyCols{cols: cols, values: vs})
+	return execute.NewGroupKey(cols, vs)
+}
+
+// groupKeyCols sorts the columns of a group key by label.
+type groupKeyCols struct {
+	cols   []flux.ColMeta
+	values []values.Value
+}
+
+func (k groupKeyCols) Len() int           { return len(k.cols) }
+func (k groupKeyCols) Less(i, j int) bool { return k.cols[i].Label < k.cols[j].Label }
+func (k groupKeyCols) Swap(i, j int) {
+	k.cols[i], k.cols[j] = k.cols[j], k.cols[i]
+	k.values[i], k.values[j] = k.values[j], k.values[i]
+}
@@ -0,0 +1,285 @@
+package line_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/line"
+	"github.com/influxdata/flux/mock"
+)
+
+func TestProtocolDecoder(t *testing.T) {
+	tcs := []struct {
+		name      string
+		precision time.Duration
+		batchSize int
+		input     string
+		want      []*executetest.Table
+		wantErr   string
+	}{
+		{
+			name: "series",
+			input: `cpu,host=a,region=west usage_user=1.5,usage_idle=90i 10
+cpu,region=west,host=a usage_user=2.5 20
+cpu,host=b usage_user=0.5 10
+mem used=1u,ok=true,label="x y" 30
+`,
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"_field", "_measurement", "host", "region"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "host", Type: flux.TString},
+						{Label: "region", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(10), 1.5, "usage_user", "cpu", "a", "west"},
+						{execute.Time(20), 2.5, "usage_user", "cpu", "a", "west"},
+					},
+				},
+				{
+					KeyCols: []string{"_field", "_measurement", "host", "region"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TInt},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "host", Type: flux.TString},
+						{Label: "region", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(10), int64(90), "usage_idle", "cpu", "a", "west"},
+					},
+				},
+				{
+					KeyCols: []string{"_field", "_measurement", "host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "host", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(10), 0.5, "usage_user", "cpu", "b"},
+					},
+				},
+				{
+					KeyCols: []string{"_field", "_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TUInt},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(30), uint64(1), "used", "mem"},
+					},
+				},
+				{
+					KeyCols: []string{"_field", "_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TBool},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(30), true, "ok", "mem"},
+					},
+				},
+				{
+					KeyCols: []string{"_field", "_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(30), "x y", "label", "mem"},
+					},
+				},
+			},
+		},
+		{
+			name:      "precision",
+			precision: time.Second,
+			input:     "cpu v=1 2\n",
+			want: []*executetest.Table{{
+				KeyCols: []string{"_field", "_measurement"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "_field", Type: flux.TString},
+					{Label: "_measurement", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2e9), 1.0, "v", "cpu"},
+				},
+			}},
+		},
+		{
+			name:  "missing timestamp",
+			input: "cpu v=1\ncpu v=2\n",
+			want: []*executetest.Table{{
+				KeyCols: []string{"_field", "_measurement"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "_field", Type: flux.TString},
+					{Label: "_measurement", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0, "v", "cpu"},
+					{execute.Time(1), 2.0, "v", "cpu"},
+				},
+			}},
+		},
+		{
+			name:    "type conflict",
+			input:   "cpu v=1 1\ncpu v=2i 2\n",
+			wantErr: `field "v" of measurement "cpu" has type float and int`,
+		},
+		{
+			name:    "invalid",
+			input:   "cpu 1\n",
+			wantErr: "failed to parse line protocol",
+		},
+		{
+			name:    "reserved tag",
+			input:   "cpu,_field=a v=1 1\n",
+			wantErr: `measurement "cpu" has a tag with the reserved key "_field"`,
+		},
+		{
+			name:      "type conflict across batches",
+			batchSize: 1,
+			input:     "cpu v=1 1\nmem v=1 1\ncpu v=2i 2\n",
+			wantErr:   `field "v" of measurement "cpu" has type float and int`,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			decoder := line.NewProtocolDecoder(&line.ProtocolDecoderConfig{
+				Precision:    tc.precision,
+				TimeProvider: &mock.AscendingTimeProvider{},
+				BatchSize:    tc.batchSize,
+			})
+			r, err := decoder.Decode(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*executetest.Table
+			err = r.Tables().Do(func(table flux.Table) error {
+				ct, err := executetest.ConvertTable(table)
+				if err != nil {
+					return err
+				}
+				got = append(got, ct)
+				return nil
+			})
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				} else if !strings.HasPrefix(err.Error(), tc.wantErr) {
+					t.Fatalf("unexpected error -want/+got:\n\t- %s\n\t+ %s", tc.wantErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(tc.want)
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestProtocolDecoder_Stream(t *testing.T) {
+	// The input is only closed after the tables
+	// of the first batch have been read.
+	pr, pw := io.Pipe()
+	read := make(chan struct{})
+	go func() {
+		_, _ = io.WriteString(pw, "cpu,Host=a v=1 1\ncpu,Host=a v=2 2\ncpu,Host=a v=3 3\n")
+		<-read
+		_ = pw.Close()
+	}()
+
+	decoder := line.NewProtocolDecoder(&line.ProtocolDecoderConfig{
+		BatchSize: 2,
+	})
+	r, err := decoder.Decode(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*executetest.Table
+	if err := r.Tables().Do(func(table flux.Table) error {
+		// The group key columns are sorted by label.
+		var labels []string
+		for _, c := range table.Key().Cols() {
+			labels = append(labels, c.Label)
+		}
+		if want := []string{"Host", "_field", "_measurement"}; !cmp.Equal(want, labels) {
+			t.Errorf("unexpected group key columns -want/+got:\n%s", cmp.Diff(want, labels))
+		}
+
+		ct, err := executetest.ConvertTable(table)
+		if err != nil {
+			return err
+		}
+		got = append(got, ct)
+		if len(got) == 1 {
+			close(read)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	newTable := func(rows ...[]interface{}) *executetest.Table {
+		return &executetest.Table{
+			KeyCols: []string{"Host", "_field", "_measurement"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "Host", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+			},
+			Data: rows,
+		}
+	}
+	want := []*executetest.Table{
+		newTable(
+			[]interface{}{execute.Time(1), 1.0, "a", "v", "cpu"},
+			[]interface{}{execute.Time(2), 2.0, "a", "v", "cpu"},
+		),
+		newTable(
+			[]interface{}{execute.Time(3), 3.0, "a", "v", "cpu"},
+		),
+	}
+	executetest.NormalizeTables(got)
+	executetest.NormalizeTables(want)
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(want, got))
+	}
+}
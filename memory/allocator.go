@@ -41,6 +41,9 @@ type Allocator struct {
 	// Allocator is the underlying memory allocator used to
 	// allocate and free memory.
 	// If this is unset, the DefaultAllocator is used.
+	//
+	// If this is also an *Allocator, the memory that is
+	// manually accounted for is also accounted for in it.
 	Allocator memory.Allocator
 }
 
@@ -75,9 +78,12 @@ func (a *Allocator) Reallocate(size int, b []byte) []byte {
 		return DefaultAllocator.Reallocate(size, b)
 	}
 
-	sizediff := size - cap(b)
-	if err := a.Account(sizediff); err != nil {
-		panic(err)
+	// The underlying allocator accounts for the reallocation
+	// so only the memory of this allocator is counted.
+	if sizediff := size - cap(b); sizediff != 0 {
+		if err := a.count(sizediff); err != nil {
+			panic(err)
+		}
 	}
 
 	alloc := a.allocator()
@@ -91,6 +97,11 @@ func (a *Allocator) Account(size int) error {
 	if size == 0 {
 		return nil
 	}
+	if parent, ok := a.Allocator.(*Allocator); ok && parent != nil {
+		if err := parent.Account(size); err != nil {
+			return err
+		}
+	}
 	return a.count(size)
 }
 
@@ -108,6 +108,40 @@ func TestAllocator_MaxAfterFree(t *testing.T) {
 	}
 }
 
+func TestAllocator_Nested(t *testing.T) {
+	mem := arrowmemory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	parent := &memory.Allocator{Allocator: mem}
+	allocator := &memory.Allocator{Allocator: parent}
+
+	b := allocator.Allocate(64)
+	b = allocator.Reallocate(128, b)
+	if err := allocator.Account(32); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mem.AssertSize(t, 128)
+	for _, a := range []*memory.Allocator{parent, allocator} {
+		if want, got := int64(160), a.Allocated(); want != got {
+			t.Fatalf("unexpected allocated count -want/+got\n\t- %d\n\t+ %d", want, got)
+		}
+		if want, got := int64(160), a.MaxAllocated(); want != got {
+			t.Fatalf("unexpected max allocated count -want/+got\n\t- %d\n\t+ %d", want, got)
+		}
+	}
+
+	allocator.Free(b)
+	_ = allocator.Account(-32)
+
+	mem.AssertSize(t, 0)
+	for _, a := range []*memory.Allocator{parent, allocator} {
+		if want, got := int64(0), a.Allocated(); want != got {
+			t.Fatalf("unexpected allocated count -want/+got\n\t- %d\n\t+ %d", want, got)
+		}
+	}
+}
+
 func TestAllocator_Limit(t *testing.T) {
 	maxLimit := int64(64)
 	allocator := &memory.Allocator{Limit: &maxLimit}
@@ -1,10 +1,27 @@
 package plan
 
+// Statistics are estimates of the data produced by a plan node.
+// A zero value means that nothing is known about the data.
+// Estimates start at the sources, so they are only known for the
+// parts of a plan that read from a source that implements Cost,
+// such as array.from and parquet.from.
 type Statistics struct {
-	Cardinality      int64
+	// Cardinality is the estimated number of rows.
+	Cardinality int64
+	// GroupCardinality is the estimated number of tables.
 	GroupCardinality int64
 }
 
+// CombineStatistics returns the statistics of the union of the inputs.
+func CombineStatistics(inStats []Statistics) Statistics {
+	var stats Statistics
+	for _, s := range inStats {
+		stats.Cardinality += s.Cardinality
+		stats.GroupCardinality += s.GroupCardinality
+	}
+	return stats
+}
+
 // Cost stores various dimensions of the cost of a query plan
 type Cost struct {
 	Disk int64
@@ -25,9 +42,151 @@ func Add(a Cost, b Cost) Cost {
 	}
 }
 
+// Total returns a single value for the cost so that
+// costs can be compared. Every dimension has the same weight.
+func (c Cost) Total() int64 {
+	return c.Disk + c.CPU + c.GPU + c.MEM + c.NET
+}
+
+// DefaultCost can be embedded in procedure specs that do not know
+// how they change the data. The output statistics are the combined
+// statistics of the inputs and the cost is one unit of CPU per input row.
 type DefaultCost struct {
 }
 
 func (c DefaultCost) Cost(inStats []Statistics) (Cost, Statistics) {
-	return Cost{}, Statistics{}
+	stats := CombineStatistics(inStats)
+	return Cost{CPU: stats.Cardinality}, stats
+}
+
+// ComputeCost computes the estimated statistics and cost
+// of a physical plan node from the statistics of its predecessors.
+// It must be called on the predecessors of a node before the node itself.
+func ComputeCost(node Node) error {
+	ppn, ok := node.(*PhysicalPlanNode)
+	if !ok {
+		return nil
+	}
+
+	inStats := make([]Statistics, len(node.Predecessors()))
+	for i, pred := range node.Predecessors() {
+		if pred, ok := pred.(*PhysicalPlanNode); ok {
+			inStats[i] = pred.Statistics
+		}
+	}
+	ppn.SelfCost, ppn.Statistics = ppn.Cost(inStats)
+	return nil
+}
+
+// EstimateCost estimates the total cost of the node and all of its
+// predecessors, along with the statistics of the output of the node.
+// Nodes that are shared by more than one path are only counted once.
+func EstimateCost(node Node) (Cost, Statistics) {
+	e := newCostEstimator()
+	stats := e.estimate(node)
+	return e.cost, stats
+}
+
+// coster is implemented by procedure specs that can estimate their cost.
+type coster interface {
+	Cost(inStats []Statistics) (Cost, Statistics)
+}
+
+type costEstimator struct {
+	stats map[Node]Statistics
+	cost  Cost
+}
+
+func newCostEstimator() *costEstimator {
+	return &costEstimator{
+		stats: make(map[Node]Statistics),
+	}
+}
+
+func (e *costEstimator) estimate(node Node) Statistics {
+	if stats, ok := e.stats[node]; ok {
+		return stats
+	}
+
+	inStats := make([]Statistics, len(node.Predecessors()))
+	for i, pred := range node.Predecessors() {
+		inStats[i] = e.estimate(pred)
+	}
+
+	var (
+		cost  Cost
+		stats Statistics
+	)
+	if c, ok := node.ProcedureSpec().(coster); ok {
+		cost, stats = c.Cost(inStats)
+	} else {
+		cost, stats = DefaultCost{}.Cost(inStats)
+	}
+	e.cost = Add(e.cost, cost)
+	e.stats[node] = stats
+	return stats
+}
+
+// exclude estimates the statistics of the nodes without adding
+// their cost, so they are not counted by later estimates.
+func (e *costEstimator) exclude(nodes []Node) {
+	cost := e.cost
+	for _, node := range nodes {
+		e.estimate(node)
+	}
+	e.cost = cost
+}
+
+// sharedPredecessors returns the nodes that the node depends on
+// that are also used by the rest of the plan. They stay in the plan
+// however the node is rewritten, so their cost is not counted when
+// the alternatives of a node are compared.
+func sharedPredecessors(node Node) []Node {
+	owned := map[Node]bool{node: true}
+	nodes := []Node{node}
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(nodes); i++ {
+			for _, pred := range nodes[i].Predecessors() {
+				if owned[pred] || !allOwned(pred.Successors(), owned) {
+					continue
+				}
+				owned[pred] = true
+				nodes = append(nodes, pred)
+				changed = true
+			}
+		}
+	}
+
+	var shared []Node
+	seen := make(map[Node]bool)
+	for _, n := range nodes {
+		for _, pred := range n.Predecessors() {
+			if !owned[pred] && !seen[pred] {
+				seen[pred] = true
+				shared = append(shared, pred)
+			}
+		}
+	}
+	return shared
+}
+
+func allOwned(nodes []Node, owned map[Node]bool) bool {
+	for _, n := range nodes {
+		if !owned[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateAlternative estimates the total cost of the plan fragment
+// that results from rewriting a node with the alternative.
+func (e *costEstimator) estimateAlternative(alt Alternative) Cost {
+	inStats := make([]Statistics, len(alt.Predecessors))
+	for i, pred := range alt.Predecessors {
+		inStats[i] = e.estimate(pred)
+	}
+	cost, _ := alt.Spec.Cost(inStats)
+	return Add(e.cost, cost)
 }
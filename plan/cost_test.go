@@ -0,0 +1,324 @@
+package plan_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+)
+
+// costSpec is a procedure spec with a fixed cost. When stats is nil, the
+// output statistics are the combined statistics of the inputs.
+type costSpec struct {
+	kind  plan.ProcedureKind
+	cost  plan.Cost
+	stats *plan.Statistics
+}
+
+func (s *costSpec) Kind() plan.ProcedureKind {
+	return s.kind
+}
+
+func (s *costSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func (s *costSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	if s.stats != nil {
+		return s.cost, *s.stats
+	}
+	return s.cost, plan.CombineStatistics(inStats)
+}
+
+// pushDownRule merges an aggregate into its source. The merged
+// source has a fixed cost so tests can choose whether it is cheaper.
+type pushDownRule struct {
+	cost plan.Cost
+}
+
+func (pushDownRule) Name() string {
+	return "pushDownRule"
+}
+
+func (pushDownRule) Pattern() plan.Pattern {
+	return plan.Pat("aggregate", plan.Pat("source"))
+}
+
+func (r pushDownRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	n, err := r.merge(node)
+	if err != nil {
+		return nil, false, err
+	}
+	return n, true, nil
+}
+
+func (r pushDownRule) Alternatives(ctx context.Context, node plan.Node) ([]plan.Alternative, error) {
+	return []plan.Alternative{{
+		Spec:         r.spec(),
+		Predecessors: node.Predecessors()[0].Predecessors(),
+		Rewrite: func(ctx context.Context, node plan.Node) (plan.Node, error) {
+			return r.merge(node)
+		},
+	}}, nil
+}
+
+func (r pushDownRule) spec() *costSpec {
+	return &costSpec{
+		kind:  "pushdown",
+		cost:  r.cost,
+		stats: &plan.Statistics{Cardinality: 10, GroupCardinality: 10},
+	}
+}
+
+func (r pushDownRule) merge(node plan.Node) (plan.Node, error) {
+	return plan.MergeToPhysicalNode(node, node.Predecessors()[0], r.spec())
+}
+
+func newSourceAggregatePlan() *plantest.PlanSpec {
+	return &plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("source", &costSpec{
+				kind:  "source",
+				cost:  plan.Cost{Disk: 1000},
+				stats: &plan.Statistics{Cardinality: 1000, GroupCardinality: 10},
+			}),
+			plan.CreatePhysicalNode("aggregate", &costSpec{
+				kind:  "aggregate",
+				cost:  plan.Cost{CPU: 1000},
+				stats: &plan.Statistics{Cardinality: 10, GroupCardinality: 10},
+			}),
+		},
+		Edges: [][2]int{
+			{0, 1},
+		},
+	}
+}
+
+func TestDefaultCost(t *testing.T) {
+	cost, stats := plan.DefaultCost{}.Cost([]plan.Statistics{
+		{Cardinality: 10, GroupCardinality: 2},
+		{Cardinality: 5, GroupCardinality: 1},
+	})
+	if want := (plan.Cost{CPU: 15}); !cmp.Equal(want, cost) {
+		t.Errorf("unexpected cost -want/+got:\n%s", cmp.Diff(want, cost))
+	}
+	if want := (plan.Statistics{Cardinality: 15, GroupCardinality: 3}); !cmp.Equal(want, stats) {
+		t.Errorf("unexpected statistics -want/+got:\n%s", cmp.Diff(want, stats))
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	// The source is shared by both branches,
+	// but its cost should only be counted once.
+	//
+	//      source
+	//      /    \
+	//     a      b
+	//      \    /
+	//      union
+	spec := &plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("source", &costSpec{
+				kind:  "source",
+				cost:  plan.Cost{Disk: 100},
+				stats: &plan.Statistics{Cardinality: 100, GroupCardinality: 10},
+			}),
+			plantest.CreatePhysicalMockNode("a"),
+			plantest.CreatePhysicalMockNode("b"),
+			plantest.CreatePhysicalMockNode("union"),
+		},
+		Edges: [][2]int{
+			{0, 1},
+			{0, 2},
+			{1, 3},
+			{2, 3},
+		},
+	}
+	ps := plantest.CreatePlanSpec(spec)
+
+	var root plan.Node
+	for r := range ps.Roots {
+		root = r
+	}
+	cost, stats := plan.EstimateCost(root)
+	if want := (plan.Cost{Disk: 100, CPU: 400}); !cmp.Equal(want, cost) {
+		t.Errorf("unexpected cost -want/+got:\n%s", cmp.Diff(want, cost))
+	}
+	if want := (plan.Statistics{Cardinality: 200, GroupCardinality: 20}); !cmp.Equal(want, stats) {
+		t.Errorf("unexpected statistics -want/+got:\n%s", cmp.Diff(want, stats))
+	}
+}
+
+func TestPhysicalPlanner_CostBasedRule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cost     plan.Cost
+		options  []plan.PhysicalOption
+		wantRoot plan.NodeID
+	}{
+		{
+			name:     "cheaper alternative",
+			cost:     plan.Cost{Disk: 100},
+			wantRoot: "merged_source_aggregate",
+		},
+		{
+			name:     "same cost",
+			cost:     plan.Cost{Disk: 2000},
+			wantRoot: "merged_source_aggregate",
+		},
+		{
+			name:     "more expensive alternative",
+			cost:     plan.Cost{Disk: 5000},
+			wantRoot: "aggregate",
+		},
+		{
+			name:     "cost-based planning disabled",
+			cost:     plan.Cost{Disk: 5000},
+			options:  []plan.PhysicalOption{plan.DisableCostBasedPlanning()},
+			wantRoot: "merged_source_aggregate",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			options := append([]plan.PhysicalOption{
+				plan.OnlyPhysicalRules(pushDownRule{cost: tc.cost}),
+			}, tc.options...)
+			planner := plan.NewPhysicalPlanner(options...)
+			ps, err := planner.Plan(context.Background(), plantest.CreatePlanSpec(newSourceAggregatePlan()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(ps.Roots) != 1 {
+				t.Fatalf("expected one root, got %d", len(ps.Roots))
+			}
+			for root := range ps.Roots {
+				if got := root.ID(); got != tc.wantRoot {
+					t.Fatalf("unexpected root -want/+got:\n\t- %s\n\t+ %s", tc.wantRoot, got)
+				}
+
+				// The planner should have recorded the estimates on the root.
+				ppn := root.(*plan.PhysicalPlanNode)
+				if want := (plan.Statistics{Cardinality: 10, GroupCardinality: 10}); !cmp.Equal(want, ppn.Statistics) {
+					t.Errorf("unexpected statistics -want/+got:\n%s", cmp.Diff(want, ppn.Statistics))
+				}
+			}
+		})
+	}
+}
+
+// sharedPushDownRule replaces an aggregate of a filter with a new source
+// and leaves the source of the filter in the plan for its other successors.
+type sharedPushDownRule struct {
+	pushDownRule
+}
+
+func (sharedPushDownRule) Name() string {
+	return "sharedPushDownRule"
+}
+
+// Pattern matches any predecessor because a pattern cannot
+// match a filter whose predecessor has other successors.
+func (sharedPushDownRule) Pattern() plan.Pattern {
+	return plan.Pat("aggregate", plan.Any())
+}
+
+func (r sharedPushDownRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	if node.Predecessors()[0].Kind() != "filter" {
+		return node, false, nil
+	}
+	return r.replace(node), true, nil
+}
+
+func (r sharedPushDownRule) Alternatives(ctx context.Context, node plan.Node) ([]plan.Alternative, error) {
+	if node.Predecessors()[0].Kind() != "filter" {
+		return nil, nil
+	}
+	return []plan.Alternative{{
+		Spec: r.spec(),
+		Rewrite: func(ctx context.Context, node plan.Node) (plan.Node, error) {
+			return r.replace(node), nil
+		},
+	}}, nil
+}
+
+func (r sharedPushDownRule) replace(node plan.Node) plan.Node {
+	filter := node.Predecessors()[0]
+	source := filter.Predecessors()[0]
+	var succs []plan.Node
+	for _, succ := range source.Successors() {
+		if succ != filter {
+			succs = append(succs, succ)
+		}
+	}
+	source.ClearSuccessors()
+	source.AddSuccessors(succs...)
+	return plan.CreatePhysicalNode("pushdown", r.spec())
+}
+
+func TestPhysicalPlanner_CostBasedRule_SharedSource(t *testing.T) {
+	// The source is also read by the other root, so it is read either
+	// way and only the cost of the filter and the aggregate is saved.
+	//
+	//      source
+	//      /    \
+	//   filter  other
+	//     |
+	//   aggregate
+	testCases := []struct {
+		name      string
+		cost      plan.Cost
+		wantRoots []plan.NodeID
+	}{
+		{
+			name:      "cheaper alternative",
+			cost:      plan.Cost{Disk: 1500},
+			wantRoots: []plan.NodeID{"other", "pushdown"},
+		},
+		{
+			name:      "more expensive alternative",
+			cost:      plan.Cost{Disk: 2500},
+			wantRoots: []plan.NodeID{"aggregate", "other"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			spec := newSourceAggregatePlan()
+			spec.Nodes = append(spec.Nodes,
+				plan.CreatePhysicalNode("filter", &costSpec{
+					kind: "filter",
+					cost: plan.Cost{CPU: 1000},
+				}),
+				plantest.CreatePhysicalMockNode("other"),
+			)
+			spec.Edges = [][2]int{
+				{0, 2},
+				{2, 1},
+				{0, 3},
+			}
+
+			planner := plan.NewPhysicalPlanner(
+				plan.OnlyPhysicalRules(sharedPushDownRule{pushDownRule{cost: tc.cost}}),
+			)
+			ps, err := planner.Plan(context.Background(), plantest.CreatePlanSpec(spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []plan.NodeID
+			for root := range ps.Roots {
+				got = append(got, root.ID())
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if !cmp.Equal(tc.wantRoots, got) {
+				t.Errorf("unexpected roots -want/+got:\n%s", cmp.Diff(tc.wantRoots, got))
+			}
+		})
+	}
+}
@@ -29,6 +29,16 @@ func WithDetails() FormatOption {
 	}
 }
 
+// WithEstimates returns a FormatOption that adds the estimated statistics
+// and the estimated cost of each physical node to a formatted plan.
+// The estimates are the ones stored in the nodes by the physical planner
+// and the cost does not include the cost of the predecessors of the node.
+func WithEstimates() FormatOption {
+	return func(f *formatter) {
+		f.withEstimates = true
+	}
+}
+
 // Detailer provides an optional interface that ProcedureSpecs can implement.
 // Implementors of this interface will have their details appear in the
 // formatted output for a plan if the WithDetails() option is set.
@@ -37,8 +47,9 @@ type Detailer interface {
 }
 
 type formatter struct {
-	withDetails bool
-	p           *Spec
+	withDetails   bool
+	withEstimates bool
+	p             *Spec
 }
 
 func (f formatter) Format(fs fmt.State, c rune) {
@@ -62,6 +73,13 @@ func (f formatter) Format(fs fmt.State, c rune) {
 					_, _ = fmt.Fprintf(fs, "  // %s\n", line)
 				}
 			}
+			if ppn, ok := pn.(*PhysicalPlanNode); ok && ppn.Parallelism > 1 {
+				_, _ = fmt.Fprintf(fs, "  // parallelism: %d\n", ppn.Parallelism)
+			}
+		}
+		if ppn, ok := pn.(*PhysicalPlanNode); ok && f.withEstimates {
+			stats := ppn.Statistics
+			_, _ = fmt.Fprintf(fs, "  // cardinality: %d, groups: %d, cost: %d\n", stats.Cardinality, stats.GroupCardinality, ppn.SelfCost.Total())
 		}
 		for _, pred := range pn.Predecessors() {
 			edges = append(edges, fmt.Sprintf("  %v -> %v", pred.ID(), pn.ID()))
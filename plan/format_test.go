@@ -9,8 +9,11 @@ import (
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/array"
 	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
 	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
 )
 
 func TestFormatted(t *testing.T) {
@@ -65,3 +68,42 @@ func TestFormatted(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatted_WithEstimates(t *testing.T) {
+	rows := make([]values.Value, 10)
+	for i := range rows {
+		rows[i] = values.NewInt(int64(i))
+	}
+	fromSpec := &array.FromProcedureSpec{
+		Rows: values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicInt), rows),
+	}
+	limitSpec := &universe.LimitProcedureSpec{N: 3}
+
+	ps := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("from", fromSpec),
+			plan.CreatePhysicalNode("limit", limitSpec),
+		},
+		Edges: [][2]int{
+			{0, 1},
+		},
+	})
+
+	if err := ps.BottomUpWalk(plan.ComputeCost); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph {
+  from
+  // cardinality: 10, groups: 1, cost: 10
+  limit
+  // cardinality: 3, groups: 1, cost: 10
+
+  from -> limit
+}
+`
+	got := fmt.Sprintf("%v", plan.Formatted(ps, plan.WithEstimates()))
+	if want != got {
+		t.Fatalf("unexpected output: -want/+got:\n%v", diff.LineDiff(want, got))
+	}
+}
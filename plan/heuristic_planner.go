@@ -12,6 +12,10 @@ import (
 type heuristicPlanner struct {
 	rules         map[ProcedureKind][]Rule
 	disabledRules map[string]bool
+
+	// costBased causes the planner to choose between the
+	// alternatives of a CostBasedRule by their estimated cost.
+	costBased bool
 }
 
 func newHeuristicPlanner() *heuristicPlanner {
@@ -50,7 +54,7 @@ func (p *heuristicPlanner) matchRules(ctx context.Context, node Node) (Node, boo
 			continue
 		}
 		if rule.Pattern().Match(node) {
-			newNode, changed, err := rule.Rewrite(ctx, node)
+			newNode, changed, err := p.rewrite(ctx, rule, node)
 			if err != nil {
 				return nil, false, err
 			} else if changed {
@@ -66,7 +70,7 @@ func (p *heuristicPlanner) matchRules(ctx context.Context, node Node) (Node, boo
 			continue
 		}
 		if rule.Pattern().Match(node) {
-			newNode, changed, err := rule.Rewrite(ctx, node)
+			newNode, changed, err := p.rewrite(ctx, rule, node)
 			if err != nil {
 				return nil, false, err
 			} else if changed {
@@ -80,6 +84,47 @@ func (p *heuristicPlanner) matchRules(ctx context.Context, node Node) (Node, boo
 	return node, anyChanged, nil
 }
 
+// rewrite applies the rule to the node. If the rule is a CostBasedRule
+// and cost-based planning is enabled, the cheapest alternative is used.
+func (p *heuristicPlanner) rewrite(ctx context.Context, rule Rule, node Node) (Node, bool, error) {
+	cbr, ok := rule.(CostBasedRule)
+	if !ok || !p.costBased {
+		return rule.Rewrite(ctx, node)
+	}
+
+	alternatives, err := cbr.Alternatives(ctx, node)
+	if err != nil {
+		return nil, false, err
+	} else if len(alternatives) == 0 {
+		return node, false, nil
+	}
+
+	// The nodes that are also used by the rest of the plan
+	// are paid for whichever way the node is rewritten.
+	shared := sharedPredecessors(node)
+	best, bestCost := -1, int64(0)
+	for i, alt := range alternatives {
+		e := newCostEstimator()
+		e.exclude(shared)
+		cost := e.estimateAlternative(alt).Total()
+		if best < 0 || cost < bestCost {
+			best, bestCost = i, cost
+		}
+	}
+
+	e := newCostEstimator()
+	e.exclude(shared)
+	e.estimate(node)
+	if e.cost.Total() < bestCost {
+		return node, false, nil
+	}
+	newNode, err := alternatives[best].Rewrite(ctx, node)
+	if err != nil {
+		return nil, false, err
+	}
+	return newNode, true, nil
+}
+
 // Plan is a fixed-point query planning algorithm.
 // It traverses the DAG depth-first, attempting to apply rewrite rules at each node.
 // Traversal is repeated until a pass over the DAG results in no changes with the given rule set.
@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/interval"
 )
 
 // LogicalPlanner translates a flux.Spec into a plan.Spec and applies any
@@ -116,13 +117,18 @@ func (l *logicalPlanner) Plan(ctx context.Context, logicalPlan *Spec) (*Spec, er
 }
 
 type administration struct {
-	now time.Time
+	now      time.Time
+	location interval.Location
 }
 
 func (a administration) Now() time.Time {
 	return a.now
 }
 
+func (a administration) Location() interval.Location {
+	return a.location
+}
+
 // LogicalNode consists of the input and output edges and a procedure spec
 // that describes what the node does.
 type LogicalNode struct {
@@ -169,7 +175,7 @@ func (lpn *LogicalNode) ShallowCopy() Node {
 // createLogicalPlan creates a logical query plan from a flux spec
 func createLogicalPlan(spec *flux.Spec) (*Spec, error) {
 	nodes := make(map[flux.OperationID]Node, len(spec.Operations))
-	admin := administration{now: spec.Now}
+	admin := administration{now: spec.Now, location: spec.Location}
 
 	plan := NewPlanSpec()
 	plan.Resources = spec.Resources
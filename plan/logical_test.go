@@ -84,6 +84,7 @@ func TestPlan_LogicalPlanFromSpec(t *testing.T) {
 		joinSpec = &universe.MergeJoinProcedureSpec{
 			TableNames: []string{"a", "b"},
 			On:         []string{"_time"},
+			Method:     "inner",
 		}
 		toKafkaSpec = &kafka.ToKafkaProcedureSpec{
 			Spec: &toKafkaOpSpec,
@@ -32,6 +32,7 @@ func NewPhysicalPlanner(options ...PhysicalOption) PhysicalPlanner {
 	pp.addRules(rules...)
 
 	pp.addRules(physicalConverterRule{})
+	pp.costBased = true
 
 	// Options may add or remove rules, so process them after we've
 	// added registered rules.
@@ -53,11 +54,23 @@ func (pp *physicalPlanner) Plan(ctx context.Context, spec *Spec) (*Spec, error)
 		return nil, err
 	}
 
+	// Estimate the statistics and cost of each node in the plan
+	if err := transformedSpec.BottomUpWalk(ComputeCost); err != nil {
+		return nil, err
+	}
+
 	// Set all default and/or registered trigger specs
 	if err := transformedSpec.TopDownWalk(SetTriggerSpec); err != nil {
 		return nil, err
 	}
 
+	// Mark the nodes that can process their input in parallel
+	if pp.parallelism > 1 {
+		if err := transformedSpec.TopDownWalk(pp.setParallelism); err != nil {
+			return nil, err
+		}
+	}
+
 	// Ensure that the plan is valid
 	if !pp.disableValidation {
 		err := transformedSpec.CheckIntegrity()
@@ -79,6 +92,9 @@ func (pp *physicalPlanner) Plan(ctx context.Context, spec *Spec) (*Spec, error)
 	// Update concurrency quota
 	if transformedSpec.Resources.ConcurrencyQuota == 0 {
 		transformedSpec.Resources.ConcurrencyQuota = len(transformedSpec.Roots)
+		if pp.parallelism > transformedSpec.Resources.ConcurrencyQuota {
+			transformedSpec.Resources.ConcurrencyQuota = pp.parallelism
+		}
 	}
 
 	return transformedSpec, nil
@@ -101,10 +117,24 @@ func validatePhysicalPlan(plan *Spec) error {
 	return err
 }
 
+// setParallelism sets the parallelism of a node whose procedure
+// can be partitioned and that has a single predecessor.
+func (pp *physicalPlanner) setParallelism(node Node) error {
+	ppn, ok := node.(*PhysicalPlanNode)
+	if !ok || len(ppn.Predecessors()) != 1 {
+		return nil
+	}
+	if _, ok := ppn.Spec.(PartitionParallelProcedureSpec); ok {
+		ppn.Parallelism = pp.parallelism
+	}
+	return nil
+}
+
 type physicalPlanner struct {
 	*heuristicPlanner
 	defaultMemoryLimit int64
 	disableValidation  bool
+	parallelism        int
 }
 
 // PhysicalOption is an option to configure the behavior of the physical plan.
@@ -126,6 +156,16 @@ func WithDefaultMemoryLimit(memBytes int64) PhysicalOption {
 	})
 }
 
+// WithParallelism sets the number of copies of a partition parallel transformation
+// that process its input in parallel. Each group key is processed by one of the copies.
+// The concurrency quota of the plan is raised to the parallelism unless the query
+// spec sets it explicitly. A parallelism of one or less disables parallel processing.
+func WithParallelism(n int) PhysicalOption {
+	return physicalOption(func(p *physicalPlanner) {
+		p.parallelism = n
+	})
+}
+
 // OnlyPhysicalRules produces a physical plan option that forces only a particular set of rules to be applied.
 func OnlyPhysicalRules(rules ...Rule) PhysicalOption {
 	return physicalOption(func(pp *physicalPlanner) {
@@ -150,6 +190,14 @@ func DisableValidation() PhysicalOption {
 	})
 }
 
+// DisableCostBasedPlanning causes the physical planner to ignore cost estimates
+// and rewrite nodes matched by a CostBasedRule with the rule's Rewrite method.
+func DisableCostBasedPlanning() PhysicalOption {
+	return physicalOption(func(p *physicalPlanner) {
+		p.costBased = false
+	})
+}
+
 // physicalConverterRule rewrites logical nodes that have a ProcedureSpec that implements
 // PhysicalProcedureSpec as a physical node.  For operations that have a 1:1 relationship
 // between their physical and logical operations, this is the default behavior.
@@ -189,6 +237,15 @@ func (physicalConverterRule) Rewrite(ctx context.Context, pn Node) (Node, bool,
 	return &newNode, true, nil
 }
 
+// PartitionParallelProcedureSpec is implemented by the procedures of transformations
+// that process each of their input tables independently of the others and keep
+// their group keys. The input of such a transformation may be partitioned by group
+// key across copies of the transformation that run in parallel. The copies cannot
+// produce tables with the same group key, so their tables are sent on as they are.
+type PartitionParallelProcedureSpec interface {
+	PartitionParallel()
+}
+
 // PhysicalProcedureSpec is similar to its logical counterpart but must provide a method to determine cost.
 type PhysicalProcedureSpec interface {
 	Kind() ProcedureKind
@@ -208,11 +265,22 @@ type PhysicalPlanNode struct {
 	// sends its tables to downstream operators
 	TriggerSpec TriggerSpec
 
+	// Parallelism is the number of copies of the transformation
+	// that process the input of the node partitioned by group key.
+	// The transformation is not copied when it is one or less.
+	Parallelism int
+
 	// The attributes required from inputs to this node
 	RequiredAttrs []PhysicalAttributes
 
 	// The attributes provided to consumers of this node's output
 	OutputAttrs PhysicalAttributes
+
+	// The estimated statistics of this node's output and the estimated
+	// cost of this node, not including the cost of its predecessors.
+	// These are set by the physical planner.
+	Statistics Statistics
+	SelfCost   Cost
 }
 
 // ID returns a human-readable id for this plan node.
@@ -92,3 +92,57 @@ func TestPhysicalIntegrityCheckOption(t *testing.T) {
 		t.Fatal("unexpected pass")
 	}
 }
+
+// partitionParallelSpec is a mock procedure that can be partitioned by group key.
+type partitionParallelSpec struct {
+	plantest.MockProcedureSpec
+}
+
+func (partitionParallelSpec) Copy() plan.ProcedureSpec {
+	return partitionParallelSpec{}
+}
+
+func (partitionParallelSpec) PartitionParallel() {}
+
+func TestPhysicalParallelismOption(t *testing.T) {
+	spec := &plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plantest.CreatePhysicalMockNode("0"),
+			plan.CreatePhysicalNode("1", partitionParallelSpec{}),
+			plantest.CreatePhysicalMockNode("2"),
+			plantest.CreatePhysicalMockNode("3"),
+			plan.CreatePhysicalNode("4", partitionParallelSpec{}),
+		},
+		// Node 4 has two predecessors so its input cannot be partitioned.
+		Edges: [][2]int{
+			{0, 1},
+			{1, 2},
+			{0, 3},
+			{2, 4},
+			{3, 4},
+		},
+	}
+
+	thePlanner := plan.NewPhysicalPlanner(plan.WithParallelism(4))
+	outputPlan, err := thePlanner.Plan(context.Background(), plantest.CreatePlanSpec(spec))
+	if err != nil {
+		t.Fatalf("Physical planning failed: %v", err)
+	}
+
+	want := map[plan.NodeID]int{"1": 4}
+	got := make(map[plan.NodeID]int)
+	if err := outputPlan.BottomUpWalk(func(node plan.Node) error {
+		if n := node.(*plan.PhysicalPlanNode).Parallelism; n > 0 {
+			got[node.ID()] = n
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) || got["1"] != want["1"] {
+		t.Errorf("unexpected parallelism: want %v got %v", want, got)
+	}
+	if got, want := outputPlan.Resources.ConcurrencyQuota, 4; got != want {
+		t.Errorf("unexpected concurrency quota: want %d got %d", want, got)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/interval"
 )
 
 type Administration interface {
 	Now() time.Time
+	// Location returns the default location of the query.
+	Location() interval.Location
 }
 
 // CreateProcedureSpec creates a ProcedureSpec from an OperationSpec and Administration
@@ -15,3 +15,38 @@ type Rule interface {
 	// The boolean return value should be true if anything changed during the rewrite.
 	Rewrite(context.Context, Node) (Node, bool, error)
 }
+
+// CostBasedRule is a Rule that can rewrite a node in more than one way.
+// Instead of calling Rewrite, the physical planner estimates the cost
+// of each alternative and rewrites the node with the cheapest one.
+// The node is left as it is when that is cheaper than every alternative.
+//
+// Rewrite should apply the alternative the rule would choose without
+// any estimates. It is used when cost-based planning is disabled.
+//
+// Nodes that the matched node depends on and that are also used by
+// the rest of the plan are not counted when the costs are compared,
+// since they stay in the plan however the node is rewritten.
+// parquet.PushDownCountRule is one such rule.
+type CostBasedRule interface {
+	Rule
+
+	// Alternatives returns the possible rewrites of the node.
+	// When two alternatives have the same cost, the first one is chosen.
+	Alternatives(ctx context.Context, node Node) ([]Alternative, error)
+}
+
+// Alternative is a possible rewrite of a node proposed by a CostBasedRule.
+type Alternative struct {
+	// Spec is the procedure spec of the node that replaces
+	// the matched node. It is used to estimate the cost.
+	Spec PhysicalProcedureSpec
+
+	// Predecessors are the nodes that will be the
+	// predecessors of the node that replaces the matched node.
+	Predecessors []Node
+
+	// Rewrite replaces the matched node with the alternative
+	// and returns the new root of the sub tree.
+	Rewrite func(ctx context.Context, node Node) (Node, error)
+}
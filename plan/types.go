@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/interval"
 	"github.com/influxdata/flux/interpreter"
 )
 
@@ -340,4 +341,6 @@ type WindowSpec struct {
 	Every  flux.Duration
 	Period flux.Duration
 	Offset flux.Duration
+	// Location is the location whose clock the window follows.
+	Location interval.Location
 }
@@ -0,0 +1,105 @@
+package flux
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// QueryLimits are the limits of the resources a query may use
+// other than memory, which is limited by the allocator of the query.
+// A zero value for any of the limits means it is unlimited.
+// A query that exceeds one of the limits fails with a
+// codes.ResourceExhausted error that names the limit.
+type QueryLimits struct {
+	// MaxRowsRead is the number of rows the sources of the query may read.
+	MaxRowsRead int64 `json:"max_rows_read"`
+	// MaxOutputBytes is the number of bytes the result encoder
+	// may write when it encodes the results of the query.
+	MaxOutputBytes int64 `json:"max_output_bytes"`
+	// MaxRuntime is how long the query may execute for.
+	MaxRuntime time.Duration `json:"max_runtime"`
+	// MaxSeries is the number of distinct group keys the sources of the query may read.
+	MaxSeries int64 `json:"max_series"`
+}
+
+// IsZero reports whether none of the limits are set.
+func (l QueryLimits) IsZero() bool {
+	return l == QueryLimits{}
+}
+
+// Merge returns the lower of each of the limits of l and o.
+func (l QueryLimits) Merge(o QueryLimits) QueryLimits {
+	return QueryLimits{
+		MaxRowsRead:    minLimit(l.MaxRowsRead, o.MaxRowsRead),
+		MaxOutputBytes: minLimit(l.MaxOutputBytes, o.MaxOutputBytes),
+		MaxRuntime:     time.Duration(minLimit(int64(l.MaxRuntime), int64(o.MaxRuntime))),
+		MaxSeries:      minLimit(l.MaxSeries, o.MaxSeries),
+	}
+}
+
+func minLimit(a, b int64) int64 {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// WithQueryLimits returns a context with the query limits.
+func WithQueryLimits(ctx context.Context, l QueryLimits) context.Context {
+	return context.WithValue(ctx, queryLimitsKey, l)
+}
+
+// GetQueryLimits returns the query limits of the context.
+// There are no limits if the context has none.
+func GetQueryLimits(ctx context.Context) QueryLimits {
+	l, _ := ctx.Value(queryLimitsKey).(QueryLimits)
+	return l
+}
+
+// QueryLimiter is implemented by queries and result
+// iterators that report the limits of their query.
+type QueryLimiter interface {
+	QueryLimits() QueryLimits
+}
+
+// OutputLimitWriter is a writer that fails once writing to it would
+// exceed the output bytes limit of the query that produced the results.
+type OutputLimitWriter struct {
+	w     io.Writer
+	limit int64
+	n     int64
+	err   error
+}
+
+// NewOutputLimitWriter creates a writer that writes to w until the output
+// bytes limit of the results is exceeded. Results that do not report any
+// limits may be written to w without limit.
+func NewOutputLimitWriter(w io.Writer, results ResultIterator) *OutputLimitWriter {
+	lw := &OutputLimitWriter{w: w}
+	if l, ok := results.(QueryLimiter); ok {
+		lw.limit = l.QueryLimits().MaxOutputBytes
+	}
+	return lw
+}
+
+func (w *OutputLimitWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.limit > 0 && w.n+int64(len(p)) > w.limit {
+		w.err = errors.Newf(codes.ResourceExhausted, "query exceeded the output bytes limit of %d", w.limit)
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Err returns the error of the writer once the limit has been exceeded.
+func (w *OutputLimitWriter) Err() error {
+	return w.err
+}
@@ -0,0 +1,75 @@
+package flux_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestQueryLimits_Merge(t *testing.T) {
+	l := flux.QueryLimits{
+		MaxRowsRead: 10,
+		MaxRuntime:  time.Minute,
+	}
+	o := flux.QueryLimits{
+		MaxRowsRead:    100,
+		MaxOutputBytes: 1024,
+		MaxRuntime:     time.Second,
+	}
+	want := flux.QueryLimits{
+		MaxRowsRead:    10,
+		MaxOutputBytes: 1024,
+		MaxRuntime:     time.Second,
+	}
+	if got := l.Merge(o); got != want {
+		t.Errorf("unexpected limits: want %+v got %+v", want, got)
+	}
+	if got := o.Merge(l); got != want {
+		t.Errorf("unexpected limits: want %+v got %+v", want, got)
+	}
+	if got := (flux.QueryLimits{}).Merge(flux.QueryLimits{}); !got.IsZero() {
+		t.Errorf("expected no limits, got %+v", got)
+	}
+}
+
+// limitedResults is a result iterator with query limits.
+type limitedResults struct {
+	flux.ResultIterator
+	limits flux.QueryLimits
+}
+
+func (r limitedResults) QueryLimits() flux.QueryLimits {
+	return r.limits
+}
+
+func TestOutputLimitWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := flux.NewOutputLimitWriter(&buf, limitedResults{
+		limits: flux.QueryLimits{MaxOutputBytes: 8},
+	})
+	if _, err := w.Write([]byte("12345")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("6789")); err == nil {
+		t.Fatal("expected an error")
+	} else if got := errors.Code(err); got != codes.ResourceExhausted {
+		t.Errorf("unexpected error code: want %v got %v", codes.ResourceExhausted, got)
+	}
+	if w.Err() == nil {
+		t.Error("expected the writer to report the error")
+	}
+	if got, want := buf.String(), "12345"; got != want {
+		t.Errorf("unexpected output: want %q got %q", want, got)
+	}
+
+	// Results without limits are written without limit.
+	buf.Reset()
+	w = flux.NewOutputLimitWriter(&buf, flux.NewMapResultIterator(nil))
+	if _, err := w.Write(make([]byte, 1<<10)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -286,13 +286,19 @@ type flusher interface {
 // by the Delimiter. If an error occurs while processing the ResultIterator or is returned from
 // the underlying Encoder, Encode will return the error if nothing has yet been written to the
 // Writer. If something has been written to the Writer, then an error will only be returned
-// when the error is an EncoderError.
+// when the error is an EncoderError. If the results have an output bytes limit, Encode fails
+// once writing the results would exceed it.
 func (e *DelimitedMultiResultEncoder) Encode(w io.Writer, results ResultIterator) (int64, error) {
-	wc := &iocounter.Writer{Writer: w}
+	lw := NewOutputLimitWriter(w, results)
+	wc := &iocounter.Writer{Writer: lw}
 
 	for results.More() {
 		result := results.Next()
 		if _, err := e.Encoder.Encode(wc, result); err != nil {
+			// Nothing more can be written once the output limit is exceeded.
+			if err := lw.Err(); err != nil {
+				return wc.Count(), err
+			}
 			// If we have an error that's from encoding or if we have not
 			// yet written any data to the writer, return the error.
 			if isEncoderError(err) || wc.Count() == 0 {
@@ -105,6 +105,13 @@ func (r *queryResultIterator) Statistics() Statistics {
 	return r.query.Statistics()
 }
 
+func (r *queryResultIterator) QueryLimits() QueryLimits {
+	if l, ok := r.query.(QueryLimiter); ok {
+		return l.QueryLimits()
+	}
+	return QueryLimits{}
+}
+
 type mapResultIterator struct {
 	results map[string]Result
 	order   []string
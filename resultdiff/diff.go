@@ -0,0 +1,538 @@
+// Package resultdiff compares the results of two Flux queries.
+//
+// The results are aligned by name and the tables of a result by group key.
+// The rows of two tables are matched by the values of the columns that are not
+// numeric and that both tables have, such as the time and the tags of a row.
+// Matched rows whose numeric values differ by more than the tolerance of the
+// comparison, or that differ in the columns only one of the tables has, are
+// reported as changed. The rows that are not matched are reported as added
+// or removed.
+package resultdiff
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// Options configure how the values of two results are compared.
+type Options struct {
+	// AbsTolerance is the largest absolute difference
+	// between two numeric values that are equal.
+	AbsTolerance float64
+	// RelTolerance is the largest difference between two numeric values
+	// that are equal, relative to the larger of their magnitudes.
+	RelTolerance float64
+}
+
+// Programs starts both programs, reads all of their results and compares them.
+// The results of want are the expected ones, so the rows that are only in got
+// are the added rows.
+func Programs(ctx context.Context, want, got flux.Program, alloc *memory.Allocator, opts Options) (*Report, error) {
+	wantResults, err := run(ctx, want, alloc)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Inherit, "failed to run the first program")
+	}
+	gotResults, err := run(ctx, got, alloc)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Inherit, "failed to run the second program")
+	}
+	return compare(wantResults, gotResults, opts), nil
+}
+
+// Results reads both result iterators and compares their results.
+// Both of the iterators are released.
+func Results(want, got flux.ResultIterator, opts Options) (*Report, error) {
+	wantResults, err := readResults(want)
+	if err != nil {
+		return nil, err
+	}
+	gotResults, err := readResults(got)
+	if err != nil {
+		return nil, err
+	}
+	return compare(wantResults, gotResults, opts), nil
+}
+
+func run(ctx context.Context, p flux.Program, alloc *memory.Allocator) (map[string][]*table, error) {
+	q, err := p.Start(ctx, alloc)
+	if err != nil {
+		return nil, err
+	}
+	return readResults(flux.NewResultIteratorFromQuery(q))
+}
+
+// readResults reads the tables of all of the results.
+func readResults(results flux.ResultIterator) (map[string][]*table, error) {
+	defer results.Release()
+
+	tables := make(map[string][]*table)
+	for results.More() {
+		res := results.Next()
+		// A result may be empty, and it still exists.
+		tables[res.Name()] = tables[res.Name()]
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			t, err := readTable(tbl)
+			if err != nil {
+				return err
+			}
+			tables[res.Name()] = append(tables[res.Name()], t)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	results.Release()
+	if err := results.Err(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// table is a table that was read into memory.
+// Its columns are sorted by label.
+type table struct {
+	key  string
+	cols []flux.ColMeta
+	rows [][]values.Value
+}
+
+func readTable(tbl flux.Table) (*table, error) {
+	// Sort the columns by label so that the same
+	// columns of two tables are in the same order.
+	order := make([]int, len(tbl.Cols()))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return tbl.Cols()[order[i]].Label < tbl.Cols()[order[j]].Label
+	})
+
+	t := &table{
+		key:  formatKey(tbl.Key()),
+		cols: make([]flux.ColMeta, len(order)),
+	}
+	for i, j := range order {
+		t.cols[i] = tbl.Cols()[j]
+	}
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i, n := 0, cr.Len(); i < n; i++ {
+			row := make([]values.Value, len(order))
+			for k, j := range order {
+				row[k] = execute.ValueForRow(cr, i, j)
+			}
+			t.rows = append(t.rows, row)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// formatKey formats a group key with its columns sorted by label,
+// so the keys of two tables are equal regardless of the order
+// of their columns.
+func formatKey(key flux.GroupKey) string {
+	parts := make([]string, len(key.Cols()))
+	for j, c := range key.Cols() {
+		parts[j] = c.Label + "=" + formatValue(key.Value(j))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func formatValue(v values.Value) string {
+	if v.IsNull() {
+		return "null"
+	}
+	switch v.Type().Nature() {
+	case semantic.String:
+		return fmt.Sprintf("%q", v.Str())
+	case semantic.Time:
+		return v.Time().Time().UTC().Format("2006-01-02T15:04:05.999999999Z")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func compare(want, got map[string][]*table, opts Options) *Report {
+	names := make(map[string]bool)
+	for name := range want {
+		names[name] = true
+	}
+	for name := range got {
+		names[name] = true
+	}
+
+	r := &Report{}
+	for _, name := range sortedKeys(names) {
+		wantTables, inWant := want[name]
+		gotTables, inGot := got[name]
+		switch {
+		case !inGot:
+			r.Results = append(r.Results, ResultDiff{Name: name, Status: Removed, Tables: allTables(Removed, wantTables)})
+		case !inWant:
+			r.Results = append(r.Results, ResultDiff{Name: name, Status: Added, Tables: allTables(Added, gotTables)})
+		default:
+			if d := compareResult(name, wantTables, gotTables, opts); d.Status != Equal {
+				r.Results = append(r.Results, d)
+			}
+		}
+	}
+	return r
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func allTables(status Status, tables []*table) []TableDiff {
+	diffs := make([]TableDiff, len(tables))
+	for i, t := range tables {
+		diffs[i] = TableDiff{Key: t.key, Status: status}
+		kind := RowAdded
+		if status == Removed {
+			kind = RowRemoved
+		}
+		for _, row := range t.rows {
+			diffs[i].Rows = append(diffs[i].Rows, newRowDiff(kind, t.cols, row, nil))
+		}
+	}
+	return diffs
+}
+
+// mergeTables merges the tables of a result that have the same group key,
+// which a result may have when it is produced by more than one operation.
+func mergeTables(tables []*table) map[string]*table {
+	m := make(map[string]*table, len(tables))
+	for _, t := range tables {
+		prev, ok := m[t.key]
+		if !ok {
+			m[t.key] = t
+			continue
+		}
+		cols, wantIdx, gotIdx := unionCols(prev.cols, t.cols)
+		merged := &table{key: t.key, cols: cols}
+		merged.rows = append(merged.rows, alignRows(prev.rows, wantIdx, len(cols))...)
+		merged.rows = append(merged.rows, alignRows(t.rows, gotIdx, len(cols))...)
+		m[t.key] = merged
+	}
+	return m
+}
+
+func compareResult(name string, wantTables, gotTables []*table, opts Options) ResultDiff {
+	d := ResultDiff{Name: name, Status: Equal}
+	want, got := mergeTables(wantTables), mergeTables(gotTables)
+	keys := make(map[string]bool)
+	for k := range want {
+		keys[k] = true
+	}
+	for k := range got {
+		keys[k] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		w, inWant := want[key]
+		g, inGot := got[key]
+		var td TableDiff
+		switch {
+		case !inGot:
+			td = allTables(Removed, []*table{w})[0]
+		case !inWant:
+			td = allTables(Added, []*table{g})[0]
+		default:
+			td = compareTable(w, g, opts)
+		}
+		if td.Status != Equal {
+			d.Status = Changed
+			d.Tables = append(d.Tables, td)
+		}
+	}
+	return d
+}
+
+// unionCols returns the union of the columns of two tables
+// and the index in the union of each of their columns.
+func unionCols(want, got []flux.ColMeta) ([]flux.ColMeta, []int, []int) {
+	var cols []flux.ColMeta
+	index := func(c flux.ColMeta) int {
+		for j, col := range cols {
+			if col == c {
+				return j
+			}
+		}
+		cols = append(cols, c)
+		return len(cols) - 1
+	}
+	wantIdx := make([]int, len(want))
+	for j, c := range want {
+		wantIdx[j] = index(c)
+	}
+	gotIdx := make([]int, len(got))
+	for j, c := range got {
+		gotIdx[j] = index(c)
+	}
+
+	// Keep the union sorted by label and map the indices to the sorted columns.
+	order := make([]int, len(cols))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return cols[order[i]].Label < cols[order[j]].Label
+	})
+	sorted := make([]flux.ColMeta, len(cols))
+	position := make([]int, len(cols))
+	for i, j := range order {
+		sorted[i] = cols[j]
+		position[j] = i
+	}
+	for j := range wantIdx {
+		wantIdx[j] = position[wantIdx[j]]
+	}
+	for j := range gotIdx {
+		gotIdx[j] = position[gotIdx[j]]
+	}
+	return sorted, wantIdx, gotIdx
+}
+
+// alignRows moves the values of the rows to the index of their columns
+// in the union of columns. The columns a row does not have are null.
+func alignRows(rows [][]values.Value, idx []int, n int) [][]values.Value {
+	aligned := make([][]values.Value, len(rows))
+	for i, row := range rows {
+		aligned[i] = make([]values.Value, n)
+		for j := range aligned[i] {
+			aligned[i][j] = values.Null
+		}
+		for j, v := range row {
+			aligned[i][idx[j]] = v
+		}
+	}
+	return aligned
+}
+
+func compareTable(want, got *table, opts Options) TableDiff {
+	d := TableDiff{Key: want.key, Status: Equal}
+	cols, wantIdx, gotIdx := unionCols(want.cols, got.cols)
+	if len(cols) != len(want.cols) || len(cols) != len(got.cols) {
+		d.Status = Changed
+		d.WantCols, d.GotCols = want.cols, got.cols
+	}
+	wantRows := alignRows(want.rows, wantIdx, len(cols))
+	gotRows := alignRows(got.rows, gotIdx, len(cols))
+
+	// Match the rows by the values of the columns that are not numeric
+	// and that both of the tables have.
+	inWant, inGot := make([]bool, len(cols)), make([]bool, len(cols))
+	for _, j := range wantIdx {
+		inWant[j] = true
+	}
+	for _, j := range gotIdx {
+		inGot[j] = true
+	}
+	isIdentity := make([]bool, len(cols))
+	for j, c := range cols {
+		isNumeric := c.Type == flux.TFloat || c.Type == flux.TInt || c.Type == flux.TUInt
+		isIdentity[j] = !isNumeric && inWant[j] && inGot[j]
+	}
+	identity := func(row []values.Value) string {
+		var sb strings.Builder
+		for j, v := range row {
+			if isIdentity[j] {
+				sb.WriteString(formatValue(v))
+				sb.WriteByte(0)
+			}
+		}
+		return sb.String()
+	}
+	groups := make(map[string]*rowGroup)
+	var order []*rowGroup
+	group := func(row []values.Value) *rowGroup {
+		id := identity(row)
+		g, ok := groups[id]
+		if !ok {
+			g = &rowGroup{first: row}
+			groups[id] = g
+			order = append(order, g)
+		}
+		return g
+	}
+	for _, row := range wantRows {
+		g := group(row)
+		g.want = append(g.want, row)
+	}
+	for _, row := range gotRows {
+		g := group(row)
+		g.got = append(g.got, row)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return compareRows(order[i].first, order[j].first) < 0
+	})
+
+	for _, g := range order {
+		sortRows(g.want)
+		sortRows(g.got)
+		n := len(g.want)
+		if len(g.got) > n {
+			n = len(g.got)
+		}
+		for i := 0; i < n; i++ {
+			switch {
+			case i >= len(g.got):
+				d.Rows = append(d.Rows, newRowDiff(RowRemoved, cols, g.want[i], nil))
+			case i >= len(g.want):
+				d.Rows = append(d.Rows, newRowDiff(RowAdded, cols, g.got[i], nil))
+			default:
+				if changed := changedCols(cols, g.want[i], g.got[i], opts); len(changed) > 0 {
+					rd := newRowDiff(RowChanged, cols, g.want[i], g.got[i])
+					rd.Changed = changed
+					d.Rows = append(d.Rows, rd)
+				}
+			}
+		}
+	}
+	if len(d.Rows) > 0 {
+		d.Status = Changed
+	}
+	return d
+}
+
+type rowGroup struct {
+	first     []values.Value
+	want, got [][]values.Value
+}
+
+func sortRows(rows [][]values.Value) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compareRows(rows[i], rows[j]) < 0
+	})
+}
+
+func compareRows(a, b []values.Value) int {
+	for j := range a {
+		if j >= len(b) {
+			return 1
+		}
+		if c := compareValues(a[j], b[j]); c != 0 {
+			return c
+		}
+	}
+	if len(a) < len(b) {
+		return -1
+	}
+	return 0
+}
+
+// compareValues orders two values. Nulls come first
+// and values of different types are ordered by type.
+func compareValues(a, b values.Value) int {
+	switch {
+	case a.IsNull() && b.IsNull():
+		return 0
+	case a.IsNull():
+		return -1
+	case b.IsNull():
+		return 1
+	}
+	an, bn := a.Type().Nature(), b.Type().Nature()
+	if an != bn {
+		return compareOrdered(float64(an), float64(bn))
+	}
+	switch an {
+	case semantic.Int:
+		return compareOrdered(float64(a.Int()), float64(b.Int()))
+	case semantic.UInt:
+		return compareOrdered(float64(a.UInt()), float64(b.UInt()))
+	case semantic.Float:
+		return compareOrdered(a.Float(), b.Float())
+	case semantic.Time:
+		return compareOrdered(float64(a.Time()), float64(b.Time()))
+	case semantic.Bool:
+		if a.Bool() == b.Bool() {
+			return 0
+		} else if !a.Bool() {
+			return -1
+		}
+		return 1
+	case semantic.String:
+		return strings.Compare(a.Str(), b.Str())
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// changedCols returns the labels of the columns whose values
+// differ between the rows, after applying the tolerance.
+func changedCols(cols []flux.ColMeta, want, got []values.Value, opts Options) []string {
+	var changed []string
+	for j, c := range cols {
+		if !equalValues(want[j], got[j], opts) {
+			changed = append(changed, c.Label)
+		}
+	}
+	return changed
+}
+
+func equalValues(a, b values.Value, opts Options) bool {
+	if a.IsNull() || b.IsNull() {
+		return a.IsNull() && b.IsNull()
+	}
+	if a.Type().Nature() != b.Type().Nature() {
+		return false
+	}
+	var x, y float64
+	switch a.Type().Nature() {
+	case semantic.Float:
+		x, y = a.Float(), b.Float()
+		if math.IsNaN(x) || math.IsNaN(y) {
+			return math.IsNaN(x) && math.IsNaN(y)
+		}
+	case semantic.Int:
+		if a.Int() == b.Int() {
+			return true
+		}
+		x, y = float64(a.Int()), float64(b.Int())
+	case semantic.UInt:
+		if a.UInt() == b.UInt() {
+			return true
+		}
+		x, y = float64(a.UInt()), float64(b.UInt())
+	default:
+		return a.Equal(b)
+	}
+	if x == y {
+		return true
+	}
+	diff := math.Abs(x - y)
+	if diff <= opts.AbsTolerance {
+		return true
+	}
+	return diff <= opts.RelTolerance*math.Max(math.Abs(x), math.Abs(y))
+}
@@ -0,0 +1,193 @@
+package resultdiff_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/resultdiff"
+	"github.com/influxdata/flux/runtime"
+)
+
+const baseScript = `
+import "array"
+
+array.from(rows: [
+	{_time: 2020-01-01T00:00:00Z, host: "a", _value: 1.0},
+	{_time: 2020-01-01T00:01:00Z, host: "a", _value: 2.0},
+	{_time: 2020-01-01T00:00:00Z, host: "b", _value: 3.0},
+])
+	|> group(columns: ["host"])
+`
+
+func diffScripts(t *testing.T, want, got string, opts resultdiff.Options) *resultdiff.Report {
+	t.Helper()
+	now := time.Now()
+	compile := func(script string) *lang.AstProgram {
+		p, err := lang.Compile(script, runtime.Default, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	ctx := dependenciestest.Default().Inject(context.Background())
+	r, err := resultdiff.Programs(ctx, compile(want), compile(got), &memory.Allocator{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestPrograms(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		got  string
+		opts resultdiff.Options
+		// want are the lines of the report.
+		want []string
+	}{
+		{
+			name: "equal",
+			got:  baseScript,
+			want: []string{"results are equal"},
+		},
+		{
+			name: "equal in a different order",
+			got: `
+import "array"
+
+array.from(rows: [
+	{host: "b", _value: 3.0, _time: 2020-01-01T00:00:00Z},
+	{host: "a", _value: 2.0, _time: 2020-01-01T00:01:00Z},
+	{host: "a", _value: 1.0, _time: 2020-01-01T00:00:00Z},
+])
+	|> group(columns: ["host"])
+`,
+			want: []string{"results are equal"},
+		},
+		{
+			name: "changed rows",
+			got:  baseScript + `|> map(fn: (r) => ({r with _value: r._value * 1.01}))`,
+			want: []string{
+				`result "_result"`,
+				`table host="a"`,
+				`  ~ _time=2020-01-01T00:00:00Z,_value=1->1.01,host="a"`,
+				`  ~ _time=2020-01-01T00:01:00Z,_value=2->2.02,host="a"`,
+				`table host="b"`,
+				`  ~ _time=2020-01-01T00:00:00Z,_value=3->3.0300000000000002,host="b"`,
+				`0 rows added, 0 removed, 3 changed`,
+			},
+		},
+		{
+			name: "relative tolerance",
+			got:  baseScript + `|> map(fn: (r) => ({r with _value: r._value * 1.01}))`,
+			opts: resultdiff.Options{RelTolerance: 0.02},
+			want: []string{"results are equal"},
+		},
+		{
+			name: "absolute tolerance",
+			got:  baseScript + `|> map(fn: (r) => ({r with _value: r._value * 1.01}))`,
+			opts: resultdiff.Options{AbsTolerance: 0.025},
+			want: []string{
+				`result "_result"`,
+				`table host="b"`,
+				`  ~ _time=2020-01-01T00:00:00Z,_value=3->3.0300000000000002,host="b"`,
+				`0 rows added, 0 removed, 1 changed`,
+			},
+		},
+		{
+			name: "added and removed rows and tables",
+			got: `
+import "array"
+
+array.from(rows: [
+	{_time: 2020-01-01T00:00:00Z, host: "a", _value: 1.0},
+	{_time: 2020-01-01T00:02:00Z, host: "a", _value: 2.0},
+	{_time: 2020-01-01T00:00:00Z, host: "c", _value: 3.0},
+])
+	|> group(columns: ["host"])
+`,
+			want: []string{
+				`result "_result"`,
+				`table host="a"`,
+				`  - _time=2020-01-01T00:01:00Z,_value=2,host="a"`,
+				`  + _time=2020-01-01T00:02:00Z,_value=2,host="a"`,
+				`- table host="b"`,
+				`  - _time=2020-01-01T00:00:00Z,_value=3,host="b"`,
+				`+ table host="c"`,
+				`  + _time=2020-01-01T00:00:00Z,_value=3,host="c"`,
+				`2 rows added, 2 removed, 0 changed`,
+			},
+		},
+		{
+			name: "columns",
+			got:  baseScript + `|> set(key: "region", value: "west")`,
+			want: []string{
+				`result "_result"`,
+				`table host="a"`,
+				`  - columns _time:time,_value:float,host:string`,
+				`  + columns _time:time,_value:float,host:string,region:string`,
+				`  ~ _time=2020-01-01T00:00:00Z,_value=1,host="a",region=null->"west"`,
+				`  ~ _time=2020-01-01T00:01:00Z,_value=2,host="a",region=null->"west"`,
+				`table host="b"`,
+				`  - columns _time:time,_value:float,host:string`,
+				`  + columns _time:time,_value:float,host:string,region:string`,
+				`  ~ _time=2020-01-01T00:00:00Z,_value=3,host="b",region=null->"west"`,
+				`0 rows added, 0 removed, 3 changed`,
+			},
+		},
+		{
+			name: "results",
+			got:  baseScript + `|> yield(name: "other")`,
+			want: []string{
+				`- result "_result"`,
+				`- table host="a"`,
+				`  - _time=2020-01-01T00:00:00Z,_value=1,host="a"`,
+				`  - _time=2020-01-01T00:01:00Z,_value=2,host="a"`,
+				`- table host="b"`,
+				`  - _time=2020-01-01T00:00:00Z,_value=3,host="b"`,
+				`+ result "other"`,
+				`+ table host="a"`,
+				`  + _time=2020-01-01T00:00:00Z,_value=1,host="a"`,
+				`  + _time=2020-01-01T00:01:00Z,_value=2,host="a"`,
+				`+ table host="b"`,
+				`  + _time=2020-01-01T00:00:00Z,_value=3,host="b"`,
+				`3 rows added, 3 removed, 0 changed`,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := diffScripts(t, baseScript, tc.got, tc.opts)
+			got := strings.Split(strings.TrimSuffix(r.String(), "\n"), "\n")
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("unexpected report:\nwant:\n%s\ngot:\n%s", strings.Join(tc.want, "\n"), strings.Join(got, "\n"))
+			}
+			if wantEqual := len(tc.want) == 1; r.Equal() != wantEqual {
+				t.Errorf("unexpected equality: want %v got %v", wantEqual, r.Equal())
+			}
+		})
+	}
+}
+
+func TestPrograms_Error(t *testing.T) {
+	now := time.Now()
+	want, err := lang.Compile(baseScript, runtime.Default, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := lang.Compile(`import "array" array.from(rows: [{v: 1}]) |> map(fn: (r) => ({v: die(msg: "boom")}))`, runtime.Default, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := dependenciestest.Default().Inject(context.Background())
+	if _, err := resultdiff.Programs(ctx, want, got, &memory.Allocator{}, resultdiff.Options{}); err == nil {
+		t.Fatal("expected an error")
+	} else if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
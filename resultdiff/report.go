@@ -0,0 +1,228 @@
+package resultdiff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/values"
+)
+
+// Status is how a result or a table differs between two queries.
+type Status int
+
+const (
+	Equal Status = iota
+	Added
+	Removed
+	Changed
+)
+
+func (s Status) String() string {
+	switch s {
+	case Equal:
+		return "equal"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// RowKind is how a row differs between two tables.
+type RowKind int
+
+const (
+	RowAdded RowKind = iota
+	RowRemoved
+	RowChanged
+)
+
+// Report is the difference between the results of two queries.
+// It only has the results that differ.
+type Report struct {
+	Results []ResultDiff
+}
+
+// Equal reports whether the results of the queries are equal.
+func (r *Report) Equal() bool {
+	return len(r.Results) == 0
+}
+
+// ResultDiff is the difference between two results with the same name.
+// It only has the tables that differ.
+type ResultDiff struct {
+	Name   string
+	Status Status
+	Tables []TableDiff
+}
+
+// TableDiff is the difference between two tables with the same group key.
+type TableDiff struct {
+	// Key is the group key of the table with its columns sorted by label.
+	Key    string
+	Status Status
+	// WantCols and GotCols are the columns of the tables
+	// when the tables do not have the same columns.
+	WantCols, GotCols []flux.ColMeta
+	Rows              []RowDiff
+}
+
+// RowDiff is a row that was added or removed, or that changed.
+type RowDiff struct {
+	Kind RowKind
+	// Cols are the columns of the row.
+	Cols []flux.ColMeta
+	// Want is the removed row or the expected values of a changed row.
+	Want []values.Value
+	// Got is the added row or the values a changed row has instead.
+	Got []values.Value
+	// Changed are the labels of the columns of a changed row that differ.
+	Changed []string
+}
+
+func newRowDiff(kind RowKind, cols []flux.ColMeta, row, other []values.Value) RowDiff {
+	d := RowDiff{Kind: kind, Cols: cols}
+	switch kind {
+	case RowAdded:
+		d.Got = row
+	case RowRemoved:
+		d.Want = row
+	case RowChanged:
+		d.Want, d.Got = row, other
+	}
+	return d
+}
+
+// Count returns the number of rows that were added, removed and changed.
+func (r *Report) Count() (added, removed, changed int) {
+	for _, res := range r.Results {
+		for _, t := range res.Tables {
+			for _, row := range t.Rows {
+				switch row.Kind {
+				case RowAdded:
+					added++
+				case RowRemoved:
+					removed++
+				case RowChanged:
+					changed++
+				}
+			}
+		}
+	}
+	return added, removed, changed
+}
+
+// String formats the report like WriteTo.
+func (r *Report) String() string {
+	var sb strings.Builder
+	_, _ = r.WriteTo(&sb)
+	return sb.String()
+}
+
+// WriteTo writes the report in a readable format. The rows that were removed
+// are prefixed with -, the ones that were added with + and the changed ones
+// with ~ followed by the values of the columns that differ.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	ew := &errWriter{w: w}
+	for _, res := range r.Results {
+		switch res.Status {
+		case Added:
+			ew.printf("+ result %q\n", res.Name)
+		case Removed:
+			ew.printf("- result %q\n", res.Name)
+		default:
+			ew.printf("result %q\n", res.Name)
+		}
+		for _, t := range res.Tables {
+			key := t.Key
+			if key == "" {
+				key = "(none)"
+			}
+			switch t.Status {
+			case Added:
+				ew.printf("+ table %s\n", key)
+			case Removed:
+				ew.printf("- table %s\n", key)
+			default:
+				ew.printf("table %s\n", key)
+			}
+			if t.WantCols != nil || t.GotCols != nil {
+				ew.printf("  - columns %s\n", formatCols(t.WantCols))
+				ew.printf("  + columns %s\n", formatCols(t.GotCols))
+			}
+			for _, row := range t.Rows {
+				switch row.Kind {
+				case RowAdded:
+					ew.printf("  + %s\n", formatRow(row.Cols, row.Got))
+				case RowRemoved:
+					ew.printf("  - %s\n", formatRow(row.Cols, row.Want))
+				case RowChanged:
+					ew.printf("  ~ %s\n", formatChange(row))
+				}
+			}
+		}
+	}
+	if r.Equal() {
+		ew.printf("results are equal\n")
+	} else {
+		added, removed, changed := r.Count()
+		ew.printf("%d rows added, %d removed, %d changed\n", added, removed, changed)
+	}
+	return ew.n, ew.err
+}
+
+func formatCols(cols []flux.ColMeta) string {
+	parts := make([]string, len(cols))
+	for j, c := range cols {
+		parts[j] = c.Label + ":" + c.Type.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatRow(cols []flux.ColMeta, row []values.Value) string {
+	parts := make([]string, 0, len(cols))
+	for j, c := range cols {
+		parts = append(parts, c.Label+"="+formatValue(row[j]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatChange formats the columns of a changed row that are
+// equal once and the ones that differ with both of their values.
+func formatChange(row RowDiff) string {
+	changed := make(map[string]bool, len(row.Changed))
+	for _, label := range row.Changed {
+		changed[label] = true
+	}
+	parts := make([]string, 0, len(row.Cols))
+	for j, c := range row.Cols {
+		if changed[c.Label] {
+			parts = append(parts, fmt.Sprintf("%s=%s->%s", c.Label, formatValue(row.Want[j]), formatValue(row.Got[j])))
+		} else {
+			parts = append(parts, c.Label+"="+formatValue(row.Want[j]))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// errWriter writes until the first error.
+type errWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *errWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.n += int64(n)
+	w.err = err
+}
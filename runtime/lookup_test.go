@@ -46,13 +46,13 @@ func TestLookupComplexTypes(t *testing.T) {
 			path: "date",
 			id:   "nanosecond",
 			name: "lookup date.nanosecond",
-			want: "(t: A) => int",
+			want: "(?location: {offset: duration, zone: string}, t: A) => int",
 		},
 		{
 			path: "date",
 			id:   "truncate",
 			name: "lookup date.truncate",
-			want: "(t: A, unit: duration) => time",
+			want: "(?location: {offset: duration, zone: string}, t: A, unit: duration) => time",
 		},
 		{
 			path: "experimental/bigtable",
@@ -5,6 +5,7 @@ import (
 
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interval"
 )
 
 // Spec specifies a query.
@@ -13,6 +14,7 @@ type Spec struct {
 	Edges      []Edge             `json:"edges"`
 	Resources  ResourceManagement `json:"resources"`
 	Now        time.Time          `json:"now"`
+	Location   interval.Location  `json:"location"`
 
 	sorted   []*Operation
 	children map[OperationID][]*Operation
@@ -80,6 +80,17 @@ func (s *FromProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// Cost reports the number of rows in the array
+// since they are all known when the query is planned.
+func (s *FromProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	n := int64(s.Rows.Len())
+	stats := plan.Statistics{Cardinality: n}
+	if n > 0 {
+		stats.GroupCardinality = 1
+	}
+	return plan.Cost{CPU: n}, stats
+}
+
 func createFromSource(ps plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
 	spec := ps.(*FromProcedureSpec)
 	return &tableSource{
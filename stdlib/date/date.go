@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interval"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
@@ -32,14 +33,22 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Second())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Second())), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					second := nowTime.Add(v1.Duration().Duration()).Second()
+					second := t.Second()
 					return values.NewInt(int64(second)), nil
 				}
 
@@ -60,13 +69,21 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Minute())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Minute())), nil
 				}
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					minute := nowTime.Add(v1.Duration().Duration()).Minute()
+					minute := t.Minute()
 					return values.NewInt(int64(minute)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -86,14 +103,22 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Hour())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Hour())), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					hour := nowTime.Add(v1.Duration().Duration()).Hour()
+					hour := t.Hour()
 					return values.NewInt(int64(hour)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -113,13 +138,21 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Weekday())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Weekday())), nil
 				}
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					weekDay := nowTime.Add(v1.Duration().Duration()).Weekday()
+					weekDay := t.Weekday()
 					return values.NewInt(int64(weekDay)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -139,13 +172,21 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Day())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Day())), nil
 				}
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					day := nowTime.Add(v1.Duration().Duration()).Day()
+					day := t.Day()
 					return values.NewInt(int64(day)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -165,14 +206,22 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().YearDay())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.YearDay())), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					yearDay := nowTime.Add(v1.Duration().Duration()).YearDay()
+					yearDay := t.YearDay()
 					return values.NewInt(int64(yearDay)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -192,14 +241,22 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Month())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Month())), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					month := nowTime.Add(v1.Duration().Duration()).Month()
+					month := t.Month()
 					return values.NewInt(int64(month)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -219,14 +276,22 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Year())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Year())), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					year := nowTime.Add(v1.Duration().Duration()).Year()
+					year := t.Year()
 					return values.NewInt(int64(year)), nil
 				}
 
@@ -247,15 +312,23 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					_, week := v1.Time().Time().ISOWeek()
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					_, week := t.ISOWeek()
 					return values.NewInt(int64(week)), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					_, week := nowTime.Add(v1.Duration().Duration()).ISOWeek()
+					_, week := t.ISOWeek()
 					return values.NewInt(int64(week)), nil
 				}
 
@@ -276,15 +349,23 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					month := v1.Time().Time().Month()
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					month := t.Month()
 					return values.NewInt(int64(math.Ceil(float64(month) / 3.0))), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					month := nowTime.Add(v1.Duration().Duration()).Month()
+					month := t.Month()
 					return values.NewInt(int64(math.Ceil(float64(month) / 3.0))), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -304,15 +385,23 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					millisecond := int64(time.Nanosecond) * int64(v1.Time().Time().Nanosecond()) / int64(time.Millisecond)
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					millisecond := int64(time.Nanosecond) * int64(t.Nanosecond()) / int64(time.Millisecond)
 					return values.NewInt(millisecond), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					return values.NewInt(int64(nowTime.Add(v1.Duration().Duration()).Nanosecond()) / int64(time.Millisecond)), nil
+					return values.NewInt(int64(t.Nanosecond()) / int64(time.Millisecond)), nil
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
 			}, false,
@@ -331,15 +420,23 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					microsecond := int64(time.Nanosecond) * int64(v1.Time().Time().Nanosecond()) / int64(time.Microsecond)
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					microsecond := int64(time.Nanosecond) * int64(t.Nanosecond()) / int64(time.Microsecond)
 					return values.NewInt(microsecond), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					return values.NewInt(int64(nowTime.Add(v1.Duration().Duration()).Nanosecond()) / int64(time.Microsecond)), nil
+					return values.NewInt(int64(t.Nanosecond()) / int64(time.Microsecond)), nil
 				}
 
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -359,14 +456,22 @@ func init() {
 				}
 
 				if v1.Type().Nature() == semantic.Time {
-					return values.NewInt(int64(v1.Time().Time().Nanosecond())), nil
+					t, err := clock(ctx, args, v1.Time())
+					if err != nil {
+						return nil, err
+					}
+					return values.NewInt(int64(t.Nanosecond())), nil
 				}
 
 				if v1.Type().Nature() == semantic.Duration {
 					deps := execute.GetExecutionDependencies(ctx)
 					nowTime := *deps.Now
+					t, err := clock(ctx, args, values.ConvertTime(nowTime.Add(v1.Duration().Duration())))
+					if err != nil {
+						return nil, err
+					}
 
-					return values.NewInt(int64(nowTime.Add(v1.Duration().Duration()).Nanosecond())), nil
+					return values.NewInt(int64(t.Nanosecond())), nil
 				}
 
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot convert argument t of type %v to time", v1.Type().Nature()))
@@ -391,18 +496,22 @@ func init() {
 				}
 
 				if values.IsTimeable(v) && u.Type().Nature() == semantic.Duration {
+					location, err := getLocation(ctx, args)
+					if err != nil {
+						return nil, err
+					}
 					if v.Type().Nature() == semantic.Time {
-						w, err := execute.NewWindow(u.Duration(), u.Duration(), execute.Duration{})
+						w, err := interval.NewWindowInLocation(u.Duration(), u.Duration(), execute.Duration{}, location)
 						if err != nil {
 							return nil, err
 						}
-						b := w.GetEarliestBounds(v.Time())
-						return values.NewTime(b.Start), nil
+						b := w.GetLatestBounds(v.Time())
+						return values.NewTime(b.Start()), nil
 					}
 
 					if v.Type().Nature() == semantic.Duration {
 
-						w, err := execute.NewWindow(u.Duration(), u.Duration(), execute.Duration{})
+						w, err := interval.NewWindowInLocation(u.Duration(), u.Duration(), execute.Duration{}, location)
 						if err != nil {
 							return nil, err
 						}
@@ -410,8 +519,8 @@ func init() {
 						deps := execute.GetExecutionDependencies(ctx)
 						nowTime := *deps.Now
 
-						b := w.GetEarliestBounds(values.ConvertTime(nowTime.Add(v.Duration().Duration())))
-						return values.NewTime(b.Start), nil
+						b := w.GetLatestBounds(values.ConvertTime(nowTime.Add(v.Duration().Duration())))
+						return values.NewTime(b.Start()), nil
 					}
 				}
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot truncate argument t of type %v to unit %v", v.Type().Nature(), u))
@@ -434,3 +543,21 @@ func init() {
 	runtime.RegisterPackageValue("date", "nanosecond", SpecialFns["nanosecond"])
 	runtime.RegisterPackageValue("date", "truncate", SpecialFns["truncate"])
 }
+
+// getLocation returns the location argument or
+// the default location of the query.
+func getLocation(ctx context.Context, args values.Object) (interval.Location, error) {
+	if v, ok := args.Get("location"); ok && v != nil && !v.IsNull() {
+		return execute.LocationFromValue(v)
+	}
+	return execute.DefaultLocation(ctx), nil
+}
+
+// clock returns the time read on the clock of the location at t.
+func clock(ctx context.Context, args values.Object, t values.Time) (time.Time, error) {
+	location, err := getLocation(ctx, args)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return location.Time(t)
+}
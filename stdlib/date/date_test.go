@@ -221,3 +221,119 @@ func TestTruncateNilErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestLocation(t *testing.T) {
+	paris := values.NewObjectWithValues(map[string]values.Value{
+		"zone":   values.NewString("Europe/Paris"),
+		"offset": values.NewDuration(values.ConvertDurationNsecs(0)),
+	})
+	testCases := []struct {
+		name string
+		time string
+		want int64
+	}{
+		{name: "hour", time: "2020-10-24T22:30:00.000000000Z", want: 0},
+		{name: "hour", time: "2020-10-25T00:30:00.000000000Z", want: 2},
+		{name: "hour", time: "2020-10-25T01:30:00.000000000Z", want: 2},
+		{name: "monthDay", time: "2020-06-30T22:30:00.000000000Z", want: 1},
+		{name: "month", time: "2020-06-30T22:30:00.000000000Z", want: 7},
+		{name: "weekDay", time: "2020-03-28T23:30:00.000000000Z", want: 0},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name+"/"+tc.time, func(t *testing.T) {
+			fluxFn := SpecialFns[tc.name]
+			time, err := values.ParseTime(tc.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fluxArg := values.NewObjectWithValues(map[string]values.Value{"t": values.NewTime(time), "location": paris})
+			got, err := fluxFn.Call(dependenciestest.Default().Inject(context.Background()), fluxArg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want != got.Int() {
+				t.Errorf("input %v: expected %v, got %v", time, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTruncateLocation(t *testing.T) {
+	testCases := []struct {
+		name string
+		time string
+		unit string
+		zone string
+		want string
+	}{
+		{
+			name: "day",
+			time: "2020-03-29T12:00:00.000000000Z",
+			unit: "1d",
+			zone: "Europe/Paris",
+			want: "2020-03-28T23:00:00.000000000Z",
+		},
+		{
+			name: "month",
+			time: "2020-11-15T12:00:00.000000000Z",
+			unit: "1mo",
+			zone: "America/New_York",
+			want: "2020-11-01T04:00:00.000000000Z",
+		},
+		{
+			name: "default location",
+			time: "2020-03-29T12:00:00.000000000Z",
+			unit: "1d",
+			want: "2020-03-29T00:00:00.000000000Z",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			time, err := values.ParseTime(tc.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			unit, err := values.ParseDuration(tc.unit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := map[string]values.Value{"t": values.NewTime(time), "unit": values.NewDuration(unit)}
+			if tc.zone != "" {
+				args["location"] = values.NewObjectWithValues(map[string]values.Value{
+					"zone":   values.NewString(tc.zone),
+					"offset": values.NewDuration(values.ConvertDurationNsecs(0)),
+				})
+			}
+			got, err := SpecialFns["truncate"].Call(dependenciestest.Default().Inject(context.Background()), values.NewObjectWithValues(args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			wanted, err := values.ParseTime(tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wanted != got.Time() {
+				t.Errorf("input %v: expected %v, got %v", time, wanted, got.Time())
+			}
+		})
+	}
+}
+
+func TestUnknownLocation(t *testing.T) {
+	time, err := values.ParseTime("2020-03-29T12:00:00.000000000Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := values.NewObjectWithValues(map[string]values.Value{
+		"t": values.NewTime(time),
+		"location": values.NewObjectWithValues(map[string]values.Value{
+			"zone":   values.NewString("Europe/Atlantis"),
+			"offset": values.NewDuration(values.ConvertDurationNsecs(0)),
+		}),
+	})
+	if _, err := SpecialFns["hour"].Call(dependenciestest.Default().Inject(context.Background()), args); err == nil {
+		t.Fatal("expected an error for an unknown time zone")
+	}
+}
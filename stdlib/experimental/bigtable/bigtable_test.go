@@ -24,6 +24,7 @@ import (
 	_ "github.com/influxdata/flux/stdlib/regexp"
 	_ "github.com/influxdata/flux/stdlib/strings"
 	_ "github.com/influxdata/flux/stdlib/system"
+	_ "github.com/influxdata/flux/stdlib/timezone"
 )
 
 func init() {
@@ -0,0 +1,283 @@
+package mqtt
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/messages"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const FromMQTTKind = "fromMQTT"
+
+type FromMQTTOpSpec struct {
+	Broker   string        `json:"broker"`
+	Topic    string        `json:"topic"`
+	QoS      int           `json:"qos"`
+	ClientID string        `json:"clientid"`
+	Username string        `json:"username"`
+	Password string        `json:"password"`
+	Stop     flux.Time     `json:"stop"`
+	Limit    int64         `json:"limit"`
+	Timeout  time.Duration `json:"timeout"`
+	Decoder  string        `json:"decoder"`
+}
+
+func init() {
+	fromMQTTSignature := runtime.MustLookupBuiltinType("experimental/mqtt", "from")
+
+	runtime.RegisterPackageValue("experimental/mqtt", "from", flux.MustValue(flux.FunctionValue(FromMQTTKind, createFromMQTTOpSpec, fromMQTTSignature)))
+	flux.RegisterOpSpec(FromMQTTKind, func() flux.OperationSpec { return &FromMQTTOpSpec{} })
+	plan.RegisterProcedureSpec(FromMQTTKind, newFromMQTTProcedure, FromMQTTKind)
+	execute.RegisterSource(FromMQTTKind, createFromMQTTSource)
+}
+
+// ReadArgs loads a flux.Arguments into FromMQTTOpSpec. It sets several default values.
+func (o *FromMQTTOpSpec) ReadArgs(args flux.Arguments) error {
+	var err error
+	if o.Broker, err = args.GetRequiredString("broker"); err != nil {
+		return err
+	}
+	u, err := url.ParseRequestURI(o.Broker)
+	if err != nil {
+		return errors.Wrap(err, codes.Invalid, "invalid broker url")
+	}
+	if !(u.Scheme == "tcp" || u.Scheme == "ws" || u.Scheme == "tls") {
+		return errors.Newf(codes.Invalid, "scheme must be tcp or ws or tls but was %s", u.Scheme)
+	}
+
+	if o.Topic, err = args.GetRequiredString("topic"); err != nil {
+		return err
+	}
+	if len(o.Topic) == 0 {
+		return errors.New(codes.Invalid, "invalid topic name")
+	}
+
+	if qos, ok, err := args.GetInt("qos"); err != nil {
+		return err
+	} else if ok {
+		if qos < 0 || qos > 2 {
+			return errors.Newf(codes.Invalid, "qos must be 0, 1 or 2, got %d", qos)
+		}
+		o.QoS = int(qos)
+	}
+
+	var ok bool
+	o.ClientID, ok, err = args.GetString("clientid")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		o.ClientID = "flux-mqtt"
+	}
+
+	o.Username, ok, err = args.GetString("username")
+	if err != nil {
+		return err
+	}
+	if ok {
+		o.Password, ok, err = args.GetString("password")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Newf(codes.Invalid, "password required with username %s", o.Username)
+		}
+	}
+
+	if stop, ok, err := args.GetTime("stop"); err != nil {
+		return err
+	} else if ok {
+		o.Stop = stop
+	}
+
+	if o.Limit, _, err = args.GetInt("limit"); err != nil {
+		return err
+	} else if o.Limit < 0 {
+		return errors.Newf(codes.Invalid, "limit must be non-negative, got %d", o.Limit)
+	}
+
+	if timeout, ok, err := args.GetDuration("timeout"); err != nil {
+		return err
+	} else if ok {
+		if o.Timeout = timeout.Duration(); o.Timeout <= 0 {
+			return errors.Newf(codes.Invalid, "timeout must be positive, got %v", o.Timeout)
+		}
+	}
+
+	o.Decoder, ok, err = args.GetString("decoder")
+	if err != nil {
+		return err
+	} else if !ok {
+		o.Decoder = messages.Decoders[0]
+	}
+	if _, err := messages.NewDecoder(o.Decoder, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func createFromMQTTOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	s := new(FromMQTTOpSpec)
+	if err := s.ReadArgs(args); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (FromMQTTOpSpec) Kind() flux.OperationKind {
+	return FromMQTTKind
+}
+
+type FromMQTTProcedureSpec struct {
+	plan.DefaultCost
+	Spec *FromMQTTOpSpec
+	Stop time.Time
+}
+
+func (o *FromMQTTProcedureSpec) Kind() plan.ProcedureKind {
+	return FromMQTTKind
+}
+
+func (o *FromMQTTProcedureSpec) Copy() plan.ProcedureSpec {
+	s := *o.Spec
+	return &FromMQTTProcedureSpec{
+		Spec: &s,
+		Stop: o.Stop,
+	}
+}
+
+func newFromMQTTProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromMQTTOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	ps := &FromMQTTProcedureSpec{Spec: spec}
+	if !spec.Stop.IsZero() {
+		ps.Stop = spec.Stop.Time(pa.Now())
+	}
+	return ps, nil
+}
+
+func createFromMQTTSource(s plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := s.(*FromMQTTProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", s)
+	}
+	return CreateFromMQTTSource(spec, dsid, a)
+}
+
+// CreateFromMQTTSource creates a source that subscribes to the topic of the spec.
+// The source connects to the broker once it runs.
+func CreateFromMQTTSource(spec *FromMQTTProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	u, err := url.Parse(spec.Spec.Broker)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid broker url")
+	}
+	deps := flux.GetDependencies(a.Context())
+	validator, err := deps.URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(u); err != nil {
+		return nil, errors.Newf(codes.Invalid, "mqtt broker url did not pass validation: %v", err)
+	}
+
+	decoder, err := messages.NewDecoder(spec.Spec.Decoder, messages.NowTimeProvider{})
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := spec.Spec.Timeout
+	if timeout <= 0 {
+		timeout = messages.DefaultTimeout
+	}
+	opts := MQTT.NewClientOptions().AddBroker(spec.Spec.Broker)
+	opts.SetClientID(spec.Spec.ClientID)
+	opts.SetConnectTimeout(timeout)
+	opts.SetAutoReconnect(false)
+	if spec.Spec.Username != "" {
+		opts.SetUsername(spec.Spec.Username)
+		opts.SetPassword(spec.Spec.Password)
+	}
+
+	r := &mqttMessageReader{
+		client:  MQTT.NewClient(opts),
+		broker:  spec.Spec.Broker,
+		topic:   spec.Spec.Topic,
+		qos:     byte(spec.Spec.QoS),
+		timeout: timeout,
+		msgs:    make(chan messages.Message, 100),
+		done:    make(chan struct{}),
+	}
+	return messages.NewSource(r, decoder, messages.Bounds{
+		Limit:   spec.Spec.Limit,
+		Stop:    spec.Stop,
+		Timeout: timeout,
+	}, dsid), nil
+}
+
+// mqttMessageReader reads the messages that the subscription receives.
+// MQTT does not keep the time a message was published,
+// so the time of a message is the time it was received.
+type mqttMessageReader struct {
+	client  MQTT.Client
+	broker  string
+	topic   string
+	qos     byte
+	timeout time.Duration
+	msgs    chan messages.Message
+	done    chan struct{}
+}
+
+// Open connects to the broker and subscribes to the topic.
+func (r *mqttMessageReader) Open(ctx context.Context) error {
+	if token := r.client.Connect(); !token.WaitTimeout(r.timeout) {
+		return errors.Newf(codes.Unavailable, "timed out connecting to mqtt broker %s", r.broker)
+	} else if err := token.Error(); err != nil {
+		return errors.Wrap(err, codes.Unavailable, "failed to connect to mqtt broker")
+	}
+	if token := r.client.Subscribe(r.topic, r.qos, r.receive); !token.WaitTimeout(r.timeout) {
+		return errors.Newf(codes.Unavailable, "timed out subscribing to mqtt topic %s", r.topic)
+	} else if err := token.Error(); err != nil {
+		return errors.Wrap(err, codes.Unavailable, "failed to subscribe to mqtt topic")
+	}
+	return nil
+}
+
+func (r *mqttMessageReader) receive(_ MQTT.Client, msg MQTT.Message) {
+	m := messages.Message{
+		Payload: msg.Payload(),
+		Time:    time.Now(),
+	}
+	select {
+	case r.msgs <- m:
+	case <-r.done:
+	}
+}
+
+func (r *mqttMessageReader) ReadMessage(ctx context.Context) (messages.Message, error) {
+	select {
+	case m := <-r.msgs:
+		return m, nil
+	case <-ctx.Done():
+		return messages.Message{}, ctx.Err()
+	}
+}
+
+func (r *mqttMessageReader) Commit(ctx context.Context, n int) error {
+	return nil
+}
+
+func (r *mqttMessageReader) Close() error {
+	close(r.done)
+	r.client.Disconnect(250)
+	return nil
+}
@@ -0,0 +1,347 @@
+package mqtt_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/experimental/mqtt"
+)
+
+func TestFromMQTT_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "from with defaults",
+			Raw: `
+import "experimental/mqtt"
+mqtt.from(broker: "tcp://localhost:1883", topic: "sensors/#")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "fromMQTT0",
+						Spec: &mqtt.FromMQTTOpSpec{
+							Broker:   "tcp://localhost:1883",
+							Topic:    "sensors/#",
+							ClientID: "flux-mqtt",
+							Decoder:  "lineprotocol",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "from with options",
+			Raw: `
+import "experimental/mqtt"
+mqtt.from(broker: "tcp://localhost:1883", topic: "t", qos: 1, clientid: "c", username: "u", password: "p", limit: 10, timeout: 1s, decoder: "json")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "fromMQTT0",
+						Spec: &mqtt.FromMQTTOpSpec{
+							Broker:   "tcp://localhost:1883",
+							Topic:    "t",
+							QoS:      1,
+							ClientID: "c",
+							Username: "u",
+							Password: "p",
+							Limit:    10,
+							Timeout:  time.Second,
+							Decoder:  "json",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:    "invalid scheme",
+			Raw:     `import "experimental/mqtt" mqtt.from(broker: "http://localhost:1883", topic: "t")`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid qos",
+			Raw:     `import "experimental/mqtt" mqtt.from(broker: "tcp://localhost:1883", topic: "t", qos: 3)`,
+			WantErr: true,
+		},
+		{
+			Name:    "username without password",
+			Raw:     `import "experimental/mqtt" mqtt.from(broker: "tcp://localhost:1883", topic: "t", username: "u")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+// fakeBroker is an MQTT broker that accepts any client and publishes
+// its messages with QoS 0 to each client once it subscribes.
+type fakeBroker struct {
+	l        net.Listener
+	messages map[string][][]byte
+
+	mu        sync.Mutex
+	clientIDs []string
+}
+
+func newFakeBroker(t *testing.T, messages map[string][][]byte) *fakeBroker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{l: l, messages: messages}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) URL() string {
+	return "tcp://" + b.l.Addr().String()
+}
+
+func (b *fakeBroker) Close() {
+	_ = b.l.Close()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		typ, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch typ >> 4 {
+		case 1: // CONNECT
+			// The protocol name, level, flags and keep alive come before the client id.
+			nameLen := int(binary.BigEndian.Uint16(body))
+			payload := body[2+nameLen+4:]
+			idLen := int(binary.BigEndian.Uint16(payload))
+			b.mu.Lock()
+			b.clientIDs = append(b.clientIDs, string(payload[2:2+idLen]))
+			b.mu.Unlock()
+			writePacket(conn, 0x20, []byte{0, 0})
+		case 8: // SUBSCRIBE
+			var topics []string
+			for rest := body[2:]; len(rest) > 0; {
+				n := int(binary.BigEndian.Uint16(rest))
+				topics = append(topics, string(rest[2:2+n]))
+				rest = rest[2+n+1:]
+			}
+			suback := append([]byte{}, body[:2]...)
+			for range topics {
+				suback = append(suback, 0)
+			}
+			writePacket(conn, 0x90, suback)
+			for _, topic := range topics {
+				for _, msg := range b.messages[topic] {
+					publish := make([]byte, 2, 2+len(topic)+len(msg))
+					binary.BigEndian.PutUint16(publish, uint16(len(topic)))
+					publish = append(publish, topic...)
+					publish = append(publish, msg...)
+					writePacket(conn, 0x30, publish)
+				}
+			}
+		case 10: // UNSUBSCRIBE
+			writePacket(conn, 0xB0, body[:2])
+		case 12: // PINGREQ
+			writePacket(conn, 0xD0, nil)
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var n, shift uint
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= uint(c&0x7f) << shift
+		if c&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return typ, body, nil
+}
+
+func writePacket(w io.Writer, typ byte, body []byte) {
+	packet := []byte{typ}
+	n := len(body)
+	for {
+		c := byte(n & 0x7f)
+		if n >>= 7; n > 0 {
+			c |= 0x80
+		}
+		packet = append(packet, c)
+		if n == 0 {
+			break
+		}
+	}
+	_, _ = w.Write(append(packet, body...))
+}
+
+func TestFromMQTT_Run(t *testing.T) {
+	b := newFakeBroker(t, map[string][][]byte{
+		"cpu": {
+			[]byte("cpu,host=a v=1 10"),
+			[]byte("cpu,host=a v=2 20\ncpu,host=b v=3 20"),
+			[]byte("cpu,host=a v=4 30"),
+		},
+		"sensors": {
+			[]byte(`{"_time": "1970-01-01T00:00:00.000000010Z", "device": "d1", "temp": 21.5}`),
+			[]byte(`{"_time": "1970-01-01T00:00:00.000000020Z", "device": "d2", "temp": 19}`),
+		},
+	})
+	defer b.Close()
+
+	cpu := func(host string, rows ...[]interface{}) *executetest.Table {
+		return &executetest.Table{
+			KeyCols: []string{"_field", "_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: rows,
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		spec *mqtt.FromMQTTOpSpec
+		want []*executetest.Table
+	}{
+		{
+			name: "until timeout",
+			spec: &mqtt.FromMQTTOpSpec{Topic: "cpu", Decoder: "lineprotocol"},
+			want: []*executetest.Table{
+				cpu("a",
+					[]interface{}{execute.Time(10), 1.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(20), 2.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(30), 4.0, "v", "cpu", "a"},
+				),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+		},
+		{
+			name: "limit",
+			spec: &mqtt.FromMQTTOpSpec{Topic: "cpu", Limit: 1, Decoder: "lineprotocol"},
+			want: []*executetest.Table{
+				cpu("a", []interface{}{execute.Time(10), 1.0, "v", "cpu", "a"}),
+			},
+		},
+		{
+			name: "json",
+			spec: &mqtt.FromMQTTOpSpec{Topic: "sensors", Decoder: "json"},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "device", Type: flux.TString},
+					{Label: "temp", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(10), "d1", 21.5},
+					{execute.Time(20), "d2", 19.0},
+				},
+			}},
+		},
+		{
+			name: "no messages",
+			spec: &mqtt.FromMQTTOpSpec{Topic: "mem", Decoder: "lineprotocol"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.Broker = b.URL()
+			tc.spec.ClientID = "flux-test"
+			tc.spec.Timeout = 100 * time.Millisecond
+
+			ctx := dependenciestest.Default().Inject(context.Background())
+			executetest.RunSourceHelper(t, tc.want, nil, func(id execute.DatasetID) execute.Source {
+				s, err := mqtt.CreateFromMQTTSource(&mqtt.FromMQTTProcedureSpec{Spec: tc.spec}, id, mock.AdministrationWithContext(ctx))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return s
+			})
+		})
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range b.clientIDs {
+		if id != "flux-test" {
+			t.Errorf("unexpected client id %q", id)
+		}
+	}
+}
+
+func TestFromMQTT_ConnectError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	// The source is created without connecting to the broker
+	// and fails once it runs.
+	ctx := dependenciestest.Default().Inject(context.Background())
+	s, err := mqtt.CreateFromMQTTSource(&mqtt.FromMQTTProcedureSpec{
+		Spec: &mqtt.FromMQTTOpSpec{
+			Broker:   "tcp://" + addr,
+			Topic:    "cpu",
+			ClientID: "flux-test",
+			Timeout:  100 * time.Millisecond,
+			Decoder:  "lineprotocol",
+		},
+	}, executetest.RandomDatasetID(), mock.AdministrationWithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := executetest.NewDataStore()
+	s.AddTransformation(store)
+	s.Run(ctx)
+	if err := store.Err(); err == nil {
+		t.Fatal("expected error")
+	} else if code := errors.Code(err); code != codes.Unavailable {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v\n%s", codes.Unavailable, code, err)
+	}
+}
@@ -0,0 +1,147 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// openMetricsType is the media type of the OpenMetrics text format.
+const openMetricsType = "application/openmetrics-text"
+
+// acceptHeader prefers the OpenMetrics text format over the
+// Prometheus text format when scraping an endpoint.
+const acceptHeader = openMetricsType + ";version=1.0.0,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+
+// openMetricsToText rewrites an OpenMetrics text exposition into the
+// Prometheus text format so that it can be read by the text parser.
+//
+// Counters are exposed with their _total samples, the _created samples
+// and the exemplars are dropped, timestamps are converted from seconds
+// to milliseconds and the types the Prometheus format does not have are
+// exposed as gauges or untyped metrics. Reading stops at the # EOF line.
+func openMetricsToText(r io.Reader) (io.Reader, error) {
+	var (
+		out     bytes.Buffer
+		scanner = bufio.NewScanner(r)
+		// created holds the names of the _created samples to drop.
+		created = make(map[string]bool)
+	)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "# EOF" {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			if line = openMetricsDescriptor(line, created); line != "" {
+				out.WriteString(line)
+				out.WriteByte('\n')
+			}
+			continue
+		}
+		if line = openMetricsSample(line, created); line != "" {
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// openMetricsDescriptor rewrites a # TYPE, # HELP or # UNIT line.
+// It returns an empty string when the line is dropped.
+func openMetricsDescriptor(line string, created map[string]bool) string {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) < 3 {
+		return line
+	}
+	name := fields[2]
+	switch fields[1] {
+	case "UNIT", "HELP":
+		// Units and help texts are not part of the tables and the
+		// HELP line of a counter does not name its _total samples.
+		return ""
+	case "TYPE":
+		if len(fields) < 4 {
+			return line
+		}
+		typ := fields[3]
+		switch typ {
+		case "counter":
+			created[name+"_created"] = true
+			return "# TYPE " + name + "_total counter"
+		case "summary", "histogram":
+			created[name+"_created"] = true
+			return line
+		case "gauge":
+			return line
+		case "info":
+			return "# TYPE " + name + "_info gauge"
+		case "stateset":
+			return "# TYPE " + name + " gauge"
+		default:
+			// unknown and gaugehistogram samples are read as untyped metrics.
+			return ""
+		}
+	}
+	return line
+}
+
+// openMetricsSample rewrites a sample line. It returns an
+// empty string when the sample is dropped.
+func openMetricsSample(line string, created map[string]bool) string {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return ""
+	}
+	end := strings.IndexAny(line, "{ ")
+	if end < 0 {
+		return line
+	}
+	if created[line[:end]] {
+		return ""
+	}
+	if line[end] == '{' {
+		end = labelsEnd(line, end)
+		if end < 0 {
+			// Leave the line to the text parser to report.
+			return line
+		}
+	}
+	name := line[:end]
+	rest := line[end:]
+	// Drop the exemplar.
+	if i := strings.Index(rest, " # "); i >= 0 {
+		rest = rest[:i]
+	}
+	parts := strings.Fields(rest)
+	if len(parts) == 2 {
+		// OpenMetrics timestamps are in seconds.
+		if ts, err := strconv.ParseFloat(parts[1], 64); err == nil {
+			parts[1] = strconv.FormatInt(int64(ts*1000), 10)
+		}
+	}
+	return name + " " + strings.Join(parts, " ")
+}
+
+// labelsEnd returns the index after the closing brace of the
+// label set that starts at i or -1 if it is not closed.
+func labelsEnd(line string, i int) int {
+	inQuotes := false
+	for j := i + 1; j < len(line); j++ {
+		switch c := line[j]; {
+		case c == '\\' && inQuotes:
+			j++
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '}' && !inQuotes:
+			return j + 1
+		}
+	}
+	return -1
+}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	flux "github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/dependencies/dependenciestest"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
@@ -229,7 +233,9 @@ func testSourceDecoder(p *PrometheusIterator, t *testing.T) *executetest.Result
 	results := &executetest.Result{}
 	runOnce := true
 
-	ctx := dependenciestest.Default().Inject(context.Background())
+	deps := dependenciestest.Default()
+	deps.Deps.HTTPClient = http.DefaultClient
+	ctx := deps.Inject(context.Background())
 	err := p.Connect(ctx)
 	if err != nil {
 		t.Fatal(err)
@@ -268,3 +274,222 @@ func testSourceDecoder(p *PrometheusIterator, t *testing.T) *executetest.Result
 	}
 	return results
 }
+
+// scrapeRows scrapes with the spec and formats each metric as
+// url|field|tags|value with the tags sorted by name.
+func scrapeRows(t *testing.T, spec *ScrapePrometheusProcedureSpec) []string {
+	t.Helper()
+	admin := &mock.Administration{}
+	p := &PrometheusIterator{
+		NowFn:          func() time.Time { return time.Unix(0, 0) },
+		spec:           spec,
+		administration: admin,
+		cache:          execute.NewTableBuilderCache(admin.Allocator()),
+	}
+	results := testSourceDecoder(p, t)
+
+	var rows []string
+	for _, tbl := range results.Tbls {
+		for _, row := range tbl.Data {
+			var field, url string
+			var value float64
+			var tags []string
+			for j, col := range tbl.ColMeta {
+				switch col.Label {
+				case "_time", "_measurement":
+				case "_value":
+					value = row[j].(float64)
+				case "_field":
+					field = row[j].(string)
+				case "url":
+					url = row[j].(string)
+				default:
+					tags = append(tags, col.Label+"="+row[j].(string))
+				}
+			}
+			sort.Strings(tags)
+			rows = append(rows, fmt.Sprintf("%s|%s|%s|%v", url, field, strings.Join(tags, ","), value))
+		}
+	}
+	sort.Strings(rows)
+	return rows
+}
+
+func TestScrapeTargets(t *testing.T) {
+	handler := func(value string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer mytoken" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Header.Get("X-Scrape") != "flux" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintln(w, "# TYPE up gauge\nup "+value)
+		}
+	}
+	a := httptest.NewServer(handler("1"))
+	defer a.Close()
+	b := httptest.NewServer(handler("0"))
+	defer b.Close()
+
+	spec := &ScrapePrometheusProcedureSpec{
+		Targets: []Target{
+			{URL: a.URL, Labels: map[string]string{"job": "a"}},
+			{URL: b.URL, Labels: map[string]string{"job": "b"}},
+		},
+		Headers:     map[string]string{"X-Scrape": "flux"},
+		BearerToken: "mytoken",
+	}
+	want := []string{
+		a.URL + "|up|job=a|1",
+		b.URL + "|up|job=b|0",
+	}
+	sort.Strings(want)
+	if got := scrapeRows(t, spec); !cmp.Equal(want, got) {
+		t.Fatalf("unexpected rows -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestScrapeBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, "# TYPE up gauge\nup 1")
+	}))
+	defer ts.Close()
+
+	spec := &ScrapePrometheusProcedureSpec{URL: ts.URL, Username: "user", Password: "pass"}
+	want := []string{ts.URL + "|up||1"}
+	if got := scrapeRows(t, spec); !cmp.Equal(want, got) {
+		t.Fatalf("unexpected rows -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	spec = &ScrapePrometheusProcedureSpec{URL: ts.URL}
+	admin := &mock.Administration{}
+	p := &PrometheusIterator{spec: spec, administration: admin}
+	deps := dependenciestest.Default()
+	deps.Deps.HTTPClient = http.DefaultClient
+	err := p.Connect(deps.Inject(context.Background()))
+	if err == nil {
+		t.Fatal("expected an error when scraping without credentials")
+	}
+	if want, got := codes.Unavailable, flux.ErrorCode(err); want != got {
+		t.Fatalf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestScrapeOpenMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Accept"), openMetricsType) {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", openMetricsType+"; version=1.0.0; charset=utf-8")
+		fmt.Fprint(w, `# TYPE http_requests counter
+# UNIT http_requests requests
+# HELP http_requests Number of requests.
+http_requests_total{code="200"} 10 # {trace_id="abc"} 1.0 1520879607.789
+http_requests_created{code="200"} 1520879600.0
+# TYPE build info
+build_info{version="1.0"} 1
+# TYPE temperature gauge
+temperature{room="a # b"} 21.5 1520879607.5
+# EOF
+ignored 1
+`)
+	}))
+	defer ts.Close()
+
+	spec := &ScrapePrometheusProcedureSpec{URL: ts.URL}
+	admin := &mock.Administration{}
+	p := &PrometheusIterator{
+		NowFn:          func() time.Time { return time.Unix(0, 0) },
+		spec:           spec,
+		administration: admin,
+		cache:          execute.NewTableBuilderCache(admin.Allocator()),
+	}
+	results := testSourceDecoder(p, t)
+
+	got := make(map[string]time.Time)
+	for _, tbl := range results.Tbls {
+		got[tbl.GroupKey.LabelValue("_field").Str()] = tbl.Data[0][0].(values.Time).Time()
+	}
+	want := map[string]time.Time{
+		"http_requests_total": time.Unix(0, 0),
+		"build_info":          time.Unix(0, 0),
+		"temperature":         time.Unix(1520879607, 500*int64(time.Millisecond)),
+	}
+	if !cmp.Equal(want, got) {
+		t.Fatalf("unexpected metrics -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	wantRows := []string{
+		ts.URL + "|build_info|version=1.0|1",
+		ts.URL + "|http_requests_total|code=200|10",
+		ts.URL + "|temperature|room=a # b|21.5",
+	}
+	if got := scrapeRows(t, spec); !cmp.Equal(wantRows, got) {
+		t.Fatalf("unexpected rows -want/+got:\n%s", cmp.Diff(wantRows, got))
+	}
+}
+
+func TestScrapeRelabel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `
+		# TYPE go_goroutines gauge
+		go_goroutines{instance="a",pod="p1"} 10
+		# TYPE go_threads gauge
+		go_threads{instance="a",pod="p1"} 5
+		# TYPE process_open_fds gauge
+		process_open_fds{instance="a",pod="p1"} 7
+		process_open_fds{instance="b",pod="p2"} 8
+		`)
+	}))
+	defer ts.Close()
+
+	rules := []RelabelConfig{
+		{Action: RelabelDrop, SourceLabels: []string{"instance"}, Regex: "b"},
+		{Action: RelabelReplace, SourceLabels: []string{"__name__"}, Regex: "go_(.*)", TargetLabel: "__name__", Replacement: "runtime_$1"},
+		{Action: RelabelReplace, SourceLabels: []string{"instance", "pod"}, Separator: "/", Regex: "(.*)", TargetLabel: "node", Replacement: "$1"},
+		{Action: RelabelLabelDrop, Regex: "instance|pod"},
+	}
+	spec := &ScrapePrometheusProcedureSpec{
+		URL:     ts.URL,
+		Metrics: []string{"go_goroutines", "process_open_fds"},
+		Relabel: rules,
+	}
+	want := []string{
+		ts.URL + "|process_open_fds|node=a/p1|7",
+		ts.URL + "|runtime_goroutines|node=a/p1|10",
+	}
+	if got := scrapeRows(t, spec); !cmp.Equal(want, got) {
+		t.Fatalf("unexpected rows -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestRelabelConfig_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		c    RelabelConfig
+	}{
+		{name: "unknown action", c: RelabelConfig{Action: "hashmod", Regex: ".*"}},
+		{name: "replace without target", c: RelabelConfig{Action: RelabelReplace, Regex: ".*"}},
+		{name: "keep without source", c: RelabelConfig{Action: RelabelKeep, Regex: ".*"}},
+		{name: "bad regex", c: RelabelConfig{Action: RelabelLabelDrop, Regex: "("}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.c.compile()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+				t.Fatalf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package prometheus
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// metricNameLabel is the label that holds the metric name while relabeling.
+const metricNameLabel = "__name__"
+
+// Relabel actions, named like the actions of a Prometheus relabel_config.
+const (
+	RelabelReplace   = "replace"
+	RelabelKeep      = "keep"
+	RelabelDrop      = "drop"
+	RelabelLabelMap  = "labelmap"
+	RelabelLabelDrop = "labeldrop"
+	RelabelLabelKeep = "labelkeep"
+)
+
+// RelabelConfig is a rule that rewrites the labels of a metric
+// or decides whether the metric is kept.
+type RelabelConfig struct {
+	Action       string   `json:"action"`
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	Separator    string   `json:"separator"`
+	Regex        string   `json:"regex"`
+	TargetLabel  string   `json:"targetLabel,omitempty"`
+	Replacement  string   `json:"replacement"`
+
+	regex *regexp.Regexp
+}
+
+// compile validates the rule and compiles its anchored regular expression.
+func (c *RelabelConfig) compile() error {
+	switch c.Action {
+	case RelabelReplace:
+		if c.TargetLabel == "" {
+			return errors.New(codes.Invalid, "relabel action \"replace\" requires a targetLabel")
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(c.SourceLabels) == 0 {
+			return errors.Newf(codes.Invalid, "relabel action %q requires sourceLabels", c.Action)
+		}
+	case RelabelLabelMap, RelabelLabelDrop, RelabelLabelKeep:
+	default:
+		return errors.Newf(codes.Invalid, "unknown relabel action %q", c.Action)
+	}
+	re, err := regexp.Compile("^(?:" + c.Regex + ")$")
+	if err != nil {
+		return errors.Wrapf(err, codes.Invalid, "invalid relabel regex %q", c.Regex)
+	}
+	c.regex = re
+	return nil
+}
+
+// apply applies the rule to the labels and reports whether the metric is kept.
+func (c *RelabelConfig) apply(labels map[string]string) bool {
+	switch c.Action {
+	case RelabelKeep, RelabelDrop:
+		matched := c.regex.MatchString(c.sourceValue(labels))
+		return matched == (c.Action == RelabelKeep)
+	case RelabelReplace:
+		val := c.sourceValue(labels)
+		match := c.regex.FindStringSubmatchIndex(val)
+		if match == nil {
+			return true
+		}
+		target := string(c.regex.ExpandString(nil, c.TargetLabel, val, match))
+		res := string(c.regex.ExpandString(nil, c.Replacement, val, match))
+		if res == "" {
+			delete(labels, target)
+		} else {
+			labels[target] = res
+		}
+	case RelabelLabelMap:
+		mapped := make(map[string]string)
+		for name, val := range labels {
+			if c.regex.MatchString(name) {
+				mapped[c.regex.ReplaceAllString(name, c.Replacement)] = val
+			}
+		}
+		for name, val := range mapped {
+			labels[name] = val
+		}
+	case RelabelLabelDrop, RelabelLabelKeep:
+		for name := range labels {
+			if name == metricNameLabel {
+				continue
+			}
+			if c.regex.MatchString(name) == (c.Action == RelabelLabelDrop) {
+				delete(labels, name)
+			}
+		}
+	}
+	return true
+}
+
+func (c *RelabelConfig) sourceValue(labels map[string]string) string {
+	vals := make([]string, len(c.SourceLabels))
+	for i, name := range c.SourceLabels {
+		vals[i] = labels[name]
+	}
+	return strings.Join(vals, c.Separator)
+}
+
+// newRelabelConfig reads a rule from a record built with relabel.
+func newRelabelConfig(obj values.Object) (RelabelConfig, error) {
+	var c RelabelConfig
+	str := func(name string) string {
+		if v, ok := obj.Get(name); ok && !v.IsNull() {
+			return v.Str()
+		}
+		return ""
+	}
+	c.Action = str("action")
+	c.Separator = str("separator")
+	c.Regex = str("regex")
+	c.TargetLabel = str("targetLabel")
+	c.Replacement = str("replacement")
+	if v, ok := obj.Get("sourceLabels"); ok && !v.IsNull() {
+		arr := v.Array()
+		c.SourceLabels = make([]string, arr.Len())
+		arr.Range(func(i int, v values.Value) {
+			c.SourceLabels[i] = v.Str()
+		})
+	}
+	if err := c.compile(); err != nil {
+		return RelabelConfig{}, err
+	}
+	return c, nil
+}
+
+// relabel applies the rules to the metric and reports whether it is kept.
+// The rules see the metric name as the __name__ label and labels that
+// start with __ are removed once all the rules have been applied.
+func relabel(met *Metric, rules []RelabelConfig) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	labels := make(map[string]string, len(met.Tags)+1)
+	for k, v := range met.Tags {
+		labels[k] = v
+	}
+	labels[metricNameLabel] = met.Field
+	for i := range rules {
+		if !rules[i].apply(labels) {
+			return false
+		}
+	}
+	name := labels[metricNameLabel]
+	if name == "" {
+		return false
+	}
+	if name != met.Field {
+		// The value of a metric is stored under its name. The map may be
+		// shared with other metrics of the same family so it is replaced.
+		typeVal := make(map[string]interface{}, 1)
+		if v, ok := met.TypeVal[met.Field]; ok {
+			typeVal[name] = v
+		}
+		met.TypeVal = typeVal
+		met.Field = name
+	}
+	for k := range labels {
+		if strings.HasPrefix(k, "__") {
+			delete(labels, k)
+		}
+	}
+	met.Tags = labels
+	return true
+}
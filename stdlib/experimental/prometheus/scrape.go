@@ -18,9 +18,12 @@ import (
 	// Flux packages
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	fluxurl "github.com/influxdata/flux/dependencies/url"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
 
 	// Prometheus packages
@@ -31,7 +34,22 @@ import (
 const ScrapePrometheusKind = "scrapePrometheus"
 
 type ScrapePrometheusOpSpec struct {
-	URL string `json:"token,omitempty"`
+	URL         string            `json:"token,omitempty"`
+	Targets     []Target          `json:"targets,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	BearerToken string            `json:"bearerToken,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Password    string            `json:"password,omitempty"`
+	Timeout     flux.Duration     `json:"timeout,omitempty"`
+	Metrics     []string          `json:"metrics,omitempty"`
+	Relabel     []RelabelConfig   `json:"relabel,omitempty"`
+}
+
+// Target is an endpoint to scrape with the labels
+// that are added to each of its metrics.
+type Target struct {
+	URL    string            `json:"url"`
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 func init() {
@@ -45,14 +63,141 @@ func init() {
 func createScrapePrometheusOpSpec(args flux.Arguments, administration *flux.Administration) (flux.OperationSpec, error) {
 	spec := new(ScrapePrometheusOpSpec)
 
-	if url, err := args.GetRequiredString("url"); err != nil {
+	if url, ok, err := args.GetString("url"); err != nil {
 		return nil, err
-	} else {
+	} else if ok {
 		spec.URL = url
 	}
+
+	if targets, ok, err := args.GetArray("targets", semantic.Object); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Targets = make([]Target, 0, targets.Len())
+		var rangeErr error
+		targets.Range(func(i int, v values.Value) {
+			if rangeErr != nil {
+				return
+			}
+			t, err := newTarget(v.Object())
+			if err != nil {
+				rangeErr = err
+				return
+			}
+			spec.Targets = append(spec.Targets, t)
+		})
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+	}
+	if spec.URL == "" && len(spec.Targets) == 0 {
+		return nil, errors.New(codes.Invalid, "scrape requires a url or targets")
+	}
+
+	if headers, ok, err := args.GetObject("headers"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Headers = make(map[string]string, headers.Len())
+		var rangeErr error
+		headers.Range(func(k string, v values.Value) {
+			if v.Type().Nature() != semantic.String {
+				rangeErr = errors.Newf(codes.Invalid, "header value %q must be a string", k)
+				return
+			}
+			spec.Headers[k] = v.Str()
+		})
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+	}
+
+	if token, ok, err := args.GetString("bearerToken"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.BearerToken = token
+	}
+	if username, ok, err := args.GetString("username"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Username = username
+	}
+	if password, ok, err := args.GetString("password"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Password = password
+	}
+	if spec.BearerToken != "" && (spec.Username != "" || spec.Password != "") {
+		return nil, errors.New(codes.Invalid, "scrape accepts either a bearerToken or a username and password")
+	}
+
+	if timeout, ok, err := args.GetDuration("timeout"); err != nil {
+		return nil, err
+	} else if ok {
+		if timeout.IsNegative() || !timeout.NanoOnly() {
+			return nil, errors.Newf(codes.Invalid, "timeout must be a positive duration, got %v", timeout)
+		}
+		spec.Timeout = timeout
+	}
+
+	if metrics, ok, err := args.GetArray("metrics", semantic.String); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Metrics = make([]string, metrics.Len())
+		metrics.Range(func(i int, v values.Value) {
+			spec.Metrics[i] = v.Str()
+		})
+	}
+
+	if rules, ok, err := args.GetArray("relabel", semantic.Object); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Relabel = make([]RelabelConfig, 0, rules.Len())
+		var rangeErr error
+		rules.Range(func(i int, v values.Value) {
+			if rangeErr != nil {
+				return
+			}
+			c, err := newRelabelConfig(v.Object())
+			if err != nil {
+				rangeErr = err
+				return
+			}
+			spec.Relabel = append(spec.Relabel, c)
+		})
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+	}
 	return spec, nil
 }
 
+// newTarget reads a target from a record with a url
+// and any number of labels.
+func newTarget(obj values.Object) (Target, error) {
+	t := Target{Labels: make(map[string]string)}
+	var err error
+	obj.Range(func(k string, v values.Value) {
+		if err != nil {
+			return
+		}
+		if v.Type().Nature() != semantic.String {
+			err = errors.Newf(codes.Invalid, "target label %q must be a string", k)
+			return
+		}
+		if k == "url" {
+			t.URL = v.Str()
+		} else {
+			t.Labels[k] = v.Str()
+		}
+	})
+	if err != nil {
+		return Target{}, err
+	}
+	if t.URL == "" {
+		return Target{}, errors.New(codes.Invalid, "scrape target requires a url")
+	}
+	return t, nil
+}
+
 func newScrapePrometheusOp() flux.OperationSpec {
 	return new(ScrapePrometheusOpSpec)
 }
@@ -63,7 +208,15 @@ func (s *ScrapePrometheusOpSpec) Kind() flux.OperationKind {
 
 type ScrapePrometheusProcedureSpec struct {
 	plan.DefaultCost
-	URL string
+	URL         string
+	Targets     []Target
+	Headers     map[string]string
+	BearerToken string
+	Username    string
+	Password    string
+	Timeout     time.Duration
+	Metrics     []string
+	Relabel     []RelabelConfig
 }
 
 func newScrapePrometheusProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -73,7 +226,15 @@ func newScrapePrometheusProcedure(qs flux.OperationSpec, pa plan.Administration)
 	}
 
 	return &ScrapePrometheusProcedureSpec{
-		URL: spec.URL,
+		URL:         spec.URL,
+		Targets:     spec.Targets,
+		Headers:     spec.Headers,
+		BearerToken: spec.BearerToken,
+		Username:    spec.Username,
+		Password:    spec.Password,
+		Timeout:     spec.Timeout.Duration(),
+		Metrics:     spec.Metrics,
+		Relabel:     spec.Relabel,
 	}, nil
 }
 
@@ -83,10 +244,45 @@ func (s *ScrapePrometheusProcedureSpec) Kind() plan.ProcedureKind {
 
 func (s *ScrapePrometheusProcedureSpec) Copy() plan.ProcedureSpec {
 	ns := new(ScrapePrometheusProcedureSpec)
-	ns.URL = s.URL
+	*ns = *s
+	if s.Targets != nil {
+		ns.Targets = make([]Target, len(s.Targets))
+		for i, t := range s.Targets {
+			ns.Targets[i] = Target{URL: t.URL, Labels: make(map[string]string, len(t.Labels))}
+			for k, v := range t.Labels {
+				ns.Targets[i].Labels[k] = v
+			}
+		}
+	}
+	if s.Headers != nil {
+		ns.Headers = make(map[string]string, len(s.Headers))
+		for k, v := range s.Headers {
+			ns.Headers[k] = v
+		}
+	}
+	if s.Metrics != nil {
+		ns.Metrics = make([]string, len(s.Metrics))
+		copy(ns.Metrics, s.Metrics)
+	}
+	if s.Relabel != nil {
+		ns.Relabel = make([]RelabelConfig, len(s.Relabel))
+		for i, c := range s.Relabel {
+			c.SourceLabels = append([]string(nil), c.SourceLabels...)
+			ns.Relabel[i] = c
+		}
+	}
 	return ns
 }
 
+// targets returns the endpoints to scrape, starting with url when it is set.
+func (s *ScrapePrometheusProcedureSpec) targets() []Target {
+	targets := make([]Target, 0, len(s.Targets)+1)
+	if s.URL != "" {
+		targets = append(targets, Target{URL: s.URL})
+	}
+	return append(targets, s.Targets...)
+}
+
 func createScrapePrometheusSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
 	spec, ok := prSpec.(*ScrapePrometheusProcedureSpec)
 	if !ok {
@@ -113,8 +309,8 @@ type PrometheusIterator struct {
 
 	metrics []Metric // Slice of metrics to convert to tables
 	i       int
-	url     string // Store user defined url
-	resp    *http.Response
+	names   map[string]bool // Metric families to keep, all of them when empty
+	relabel []RelabelConfig
 	now     time.Time
 }
 
@@ -125,55 +321,126 @@ type Metric struct {
 	TypeVal   map[string]interface{} // key is metric type; val is metric value
 	Timestamp time.Time
 	Type      string // Prometheus metric type
+	URL       string // url of the scraped target
 }
 
-// This implementation of Connect takes in a user defined url, validates the url
-// and gets an http response. It then calls parse to parse the body into a list
-// Metrics or returns and error if not given a valid prometheus metric endpoint.
+// This implementation of Connect scrapes each target in turn. It validates
+// the url of the target and gets an http response with the http client
+// dependency. It then calls parse to parse the body into a list of Metrics
+// or returns an error if a target is not a valid prometheus metric endpoint.
 func (p *PrometheusIterator) Connect(ctx context.Context) error {
-	p.url = p.spec.URL // Attach url to Prometheus Iterator
-
 	if p.NowFn != nil {
 		p.now = p.NowFn()
 	} else {
 		p.now = time.Now()
 	}
 
-	u, err := url.Parse(p.url)
-	if err != nil {
-		return err
+	if len(p.spec.Metrics) > 0 {
+		p.names = make(map[string]bool, len(p.spec.Metrics))
+		for _, name := range p.spec.Metrics {
+			p.names[name] = true
+		}
+	}
+	p.relabel = make([]RelabelConfig, len(p.spec.Relabel))
+	for i, c := range p.spec.Relabel {
+		if err := c.compile(); err != nil {
+			return err
+		}
+		p.relabel[i] = c
 	}
 
-	// Validate url
 	deps := flux.GetDependencies(ctx)
 	validator, err := deps.URLValidator()
 	if err != nil {
 		return err
 	}
+	client, err := deps.HTTPClient()
+	if err != nil {
+		return errors.Wrap(err, codes.Aborted, "missing client in prometheus.scrape")
+	}
+
+	p.metrics = make([]Metric, 0)
+	for _, target := range p.spec.targets() {
+		if err := p.scrape(ctx, client, validator, target); err != nil {
+			return errors.Wrapf(err, codes.Inherit, "failed to scrape %q", target.URL)
+		}
+	}
+	return nil
+}
+
+// scrape gets the metrics of a target and appends them to p.metrics
+// with the labels of the target, once they have been relabeled.
+func (p *PrometheusIterator) scrape(ctx context.Context, client fluxhttp.Client, validator fluxurl.Validator, target Target) error {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return err
+	}
+
+	// Validate url
 	if err := validator.Validate(u); err != nil {
 		return err
 	}
 
+	req, err := http.NewRequest("GET", target.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", acceptHeader)
+	for k, v := range p.spec.Headers {
+		req.Header.Set(k, v)
+	}
+	if p.spec.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.spec.BearerToken)
+	} else if p.spec.Username != "" || p.spec.Password != "" {
+		req.SetBasicAuth(p.spec.Username, p.spec.Password)
+	}
+	if p.spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.spec.Timeout)
+		defer cancel()
+	}
+
 	// Get response
-	resp, err := http.Get(p.url)
+	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
-	p.resp = resp
 	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Newf(codes.Unavailable, "unexpected status %s", resp.Status)
+	}
 
 	// Parse the response body into list of Metrics
-	err = p.parse(resp.Body, resp.Header)
-	if err != nil {
+	start := len(p.metrics)
+	if err := p.parse(resp.Body, resp.Header); err != nil {
 		return err
 	}
 
+	scraped := p.metrics[start:]
+	p.metrics = p.metrics[:start]
+	for _, met := range scraped {
+		met.URL = target.URL
+		if len(target.Labels) > 0 {
+			// The tags may be shared with the other metrics of a family.
+			tags := make(map[string]string, len(met.Tags)+len(target.Labels))
+			for k, v := range met.Tags {
+				tags[k] = v
+			}
+			for k, v := range target.Labels {
+				tags[k] = v
+			}
+			met.Tags = tags
+		}
+		if relabel(&met, p.relabel) {
+			p.metrics = append(p.metrics, met)
+		}
+	}
 	return nil
 }
 
 // parse will take in an http header, and read the body of an http response. It looks for prometheus
 // Metrics and calls either makeQuantiles, makeBuckets or getNameandValue depending on each Metric
-// type. It produces a list of type Metric and stores them in p.metrics.
+// type. It produces a list of type Metric and appends them to p.metrics.
 func (p *PrometheusIterator) parse(reader io.Reader, header http.Header) (err error) {
 	var parser expfmt.TextParser
 
@@ -197,15 +464,21 @@ func (p *PrometheusIterator) parse(reader io.Reader, header http.Header) (err er
 			metricFamilies[mf.GetName()] = mf
 		}
 	} else {
+		if mediatype == openMetricsType {
+			if reader, err = openMetricsToText(reader); err != nil {
+				return errors.Newf(codes.Internal, "reading openmetrics format failed: %s", err)
+			}
+		}
 		metricFamilies, err = parser.TextToMetricFamilies(reader)
 		if err != nil {
 			return errors.Newf(codes.Internal, "reading text format failed: %s", err)
 		}
 	}
-	p.metrics = make([]Metric, 0)
-
 	// Read metrics
 	for field, family := range metricFamilies {
+		if len(p.names) > 0 && !p.names[field] {
+			continue
+		}
 		for _, metr := range family.Metric {
 			// Read tags
 			tags := makeLabels(metr)
@@ -326,7 +599,7 @@ func (p *PrometheusIterator) Decode(ctx context.Context) (table flux.Table, err
 	builder.AppendValue(1, values.New(val))
 	builder.AppendValue(2, values.New("prometheus"))
 	builder.AppendValue(3, values.New(met.Field))
-	builder.AppendValue(4, values.New(p.url))
+	builder.AppendValue(4, values.New(met.URL))
 
 	// Add tag values
 	for name, tagVal := range met.Tags {
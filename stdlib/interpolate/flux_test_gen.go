@@ -1497,6 +1497,7739 @@ var FluxTestPackages = []*ast.Package{&ast.Package{
 				Name: "interpolate_test",
 			},
 		},
+	}, &ast.File{
+		BaseNode: ast.BaseNode{
+			Comments: nil,
+			Errors:   nil,
+			Loc: &ast.SourceLocation{
+				End: ast.Position{
+					Column: 133,
+					Line:   35,
+				},
+				File:   "max_gap_test.flux",
+				Source: "package interpolate_test\n\n\nimport \"testing\"\nimport \"interpolate\"\n\ninData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n\"\noutData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,level\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:07:00Z,_m,FF,10\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n\"\ninterpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> drop(columns: [\"_start\", \"_stop\"])\n    |> rename(columns: {_value: \"level\"})\n    |> interpolate.linear(every: 1m, column: \"level\", maxGap: 2m)\n\ntest interpolate_max_gap = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+				Start: ast.Position{
+					Column: 1,
+					Line:   1,
+				},
+			},
+		},
+		Body: []ast.Statement{&ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   16,
+					},
+					File:   "max_gap_test.flux",
+					Source: "inData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   7,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 7,
+							Line:   7,
+						},
+						File:   "max_gap_test.flux",
+						Source: "inData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   7,
+						},
+					},
+				},
+				Name: "inData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   16,
+						},
+						File:   "max_gap_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n\"",
+						Start: ast.Position{
+							Column: 10,
+							Line:   7,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   28,
+					},
+					File:   "max_gap_test.flux",
+					Source: "outData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,level\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:07:00Z,_m,FF,10\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   17,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 8,
+							Line:   17,
+						},
+						File:   "max_gap_test.flux",
+						Source: "outData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   17,
+						},
+					},
+				},
+				Name: "outData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   28,
+						},
+						File:   "max_gap_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,level\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:07:00Z,_m,FF,10\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n\"",
+						Start: ast.Position{
+							Column: 11,
+							Line:   17,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,long\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,level\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,5\n,,0,2014-01-01T01:06:00Z,_m,FF,9\n,,0,2014-01-01T01:07:00Z,_m,FF,10\n,,0,2014-01-01T01:08:00Z,_m,FF,10\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 66,
+						Line:   33,
+					},
+					File:   "max_gap_test.flux",
+					Source: "interpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> drop(columns: [\"_start\", \"_stop\"])\n    |> rename(columns: {_value: \"level\"})\n    |> interpolate.linear(every: 1m, column: \"level\", maxGap: 2m)",
+					Start: ast.Position{
+						Column: 1,
+						Line:   29,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 14,
+							Line:   29,
+						},
+						File:   "max_gap_test.flux",
+						Source: "interpolateFn",
+						Start: ast.Position{
+							Column: 1,
+							Line:   29,
+						},
+					},
+				},
+				Name: "interpolateFn",
+			},
+			Init: &ast.FunctionExpression{
+				Arrow: nil,
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 66,
+							Line:   33,
+						},
+						File:   "max_gap_test.flux",
+						Source: "(table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> drop(columns: [\"_start\", \"_stop\"])\n    |> rename(columns: {_value: \"level\"})\n    |> interpolate.linear(every: 1m, column: \"level\", maxGap: 2m)",
+						Start: ast.Position{
+							Column: 17,
+							Line:   29,
+						},
+					},
+				},
+				Body: &ast.PipeExpression{
+					Argument: &ast.PipeExpression{
+						Argument: &ast.PipeExpression{
+							Argument: &ast.PipeExpression{
+								Argument: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 36,
+												Line:   29,
+											},
+											File:   "max_gap_test.flux",
+											Source: "table",
+											Start: ast.Position{
+												Column: 31,
+												Line:   29,
+											},
+										},
+									},
+									Name: "table",
+								},
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 70,
+											Line:   30,
+										},
+										File:   "max_gap_test.flux",
+										Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+										Start: ast.Position{
+											Column: 31,
+											Line:   29,
+										},
+									},
+								},
+								Call: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 69,
+													Line:   30,
+												},
+												File:   "max_gap_test.flux",
+												Source: "start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z",
+												Start: ast.Position{
+													Column: 14,
+													Line:   30,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 41,
+														Line:   30,
+													},
+													File:   "max_gap_test.flux",
+													Source: "start: 2014-01-01T01:00:00Z",
+													Start: ast.Position{
+														Column: 14,
+														Line:   30,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 19,
+															Line:   30,
+														},
+														File:   "max_gap_test.flux",
+														Source: "start",
+														Start: ast.Position{
+															Column: 14,
+															Line:   30,
+														},
+													},
+												},
+												Name: "start",
+											},
+											Separator: nil,
+											Value: &ast.DateTimeLiteral{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 41,
+															Line:   30,
+														},
+														File:   "max_gap_test.flux",
+														Source: "2014-01-01T01:00:00Z",
+														Start: ast.Position{
+															Column: 21,
+															Line:   30,
+														},
+													},
+												},
+												Value: parser.MustParseTime("2014-01-01T01:00:00Z"),
+											},
+										}, &ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 69,
+														Line:   30,
+													},
+													File:   "max_gap_test.flux",
+													Source: "stop: 2014-01-01T02:00:00Z",
+													Start: ast.Position{
+														Column: 43,
+														Line:   30,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 47,
+															Line:   30,
+														},
+														File:   "max_gap_test.flux",
+														Source: "stop",
+														Start: ast.Position{
+															Column: 43,
+															Line:   30,
+														},
+													},
+												},
+												Name: "stop",
+											},
+											Separator: nil,
+											Value: &ast.DateTimeLiteral{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 69,
+															Line:   30,
+														},
+														File:   "max_gap_test.flux",
+														Source: "2014-01-01T02:00:00Z",
+														Start: ast.Position{
+															Column: 49,
+															Line:   30,
+														},
+													},
+												},
+												Value: parser.MustParseTime("2014-01-01T02:00:00Z"),
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 70,
+												Line:   30,
+											},
+											File:   "max_gap_test.flux",
+											Source: "range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+											Start: ast.Position{
+												Column: 8,
+												Line:   30,
+											},
+										},
+									},
+									Callee: &ast.Identifier{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 13,
+													Line:   30,
+												},
+												File:   "max_gap_test.flux",
+												Source: "range",
+												Start: ast.Position{
+													Column: 8,
+													Line:   30,
+												},
+											},
+										},
+										Name: "range",
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 42,
+										Line:   31,
+									},
+									File:   "max_gap_test.flux",
+									Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+									Start: ast.Position{
+										Column: 31,
+										Line:   29,
+									},
+								},
+							},
+							Call: &ast.CallExpression{
+								Arguments: []ast.Expression{&ast.ObjectExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   31,
+											},
+											File:   "max_gap_test.flux",
+											Source: "columns: [\"_start\", \"_stop\"]",
+											Start: ast.Position{
+												Column: 13,
+												Line:   31,
+											},
+										},
+									},
+									Lbrace: nil,
+									Properties: []*ast.Property{&ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 41,
+													Line:   31,
+												},
+												File:   "max_gap_test.flux",
+												Source: "columns: [\"_start\", \"_stop\"]",
+												Start: ast.Position{
+													Column: 13,
+													Line:   31,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 20,
+														Line:   31,
+													},
+													File:   "max_gap_test.flux",
+													Source: "columns",
+													Start: ast.Position{
+														Column: 13,
+														Line:   31,
+													},
+												},
+											},
+											Name: "columns",
+										},
+										Separator: nil,
+										Value: &ast.ArrayExpression{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 41,
+														Line:   31,
+													},
+													File:   "max_gap_test.flux",
+													Source: "[\"_start\", \"_stop\"]",
+													Start: ast.Position{
+														Column: 22,
+														Line:   31,
+													},
+												},
+											},
+											Elements: []ast.Expression{&ast.StringLiteral{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 31,
+															Line:   31,
+														},
+														File:   "max_gap_test.flux",
+														Source: "\"_start\"",
+														Start: ast.Position{
+															Column: 23,
+															Line:   31,
+														},
+													},
+												},
+												Value: "_start",
+											}, &ast.StringLiteral{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 40,
+															Line:   31,
+														},
+														File:   "max_gap_test.flux",
+														Source: "\"_stop\"",
+														Start: ast.Position{
+															Column: 33,
+															Line:   31,
+														},
+													},
+												},
+												Value: "_stop",
+											}},
+											Lbrack: nil,
+											Rbrack: nil,
+										},
+									}},
+									Rbrace: nil,
+									With:   nil,
+								}},
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 42,
+											Line:   31,
+										},
+										File:   "max_gap_test.flux",
+										Source: "drop(columns: [\"_start\", \"_stop\"])",
+										Start: ast.Position{
+											Column: 8,
+											Line:   31,
+										},
+									},
+								},
+								Callee: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 12,
+												Line:   31,
+											},
+											File:   "max_gap_test.flux",
+											Source: "drop",
+											Start: ast.Position{
+												Column: 8,
+												Line:   31,
+											},
+										},
+									},
+									Name: "drop",
+								},
+								Lparen: nil,
+								Rparen: nil,
+							},
+						},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 42,
+									Line:   32,
+								},
+								File:   "max_gap_test.flux",
+								Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> drop(columns: [\"_start\", \"_stop\"])\n    |> rename(columns: {_value: \"level\"})",
+								Start: ast.Position{
+									Column: 31,
+									Line:   29,
+								},
+							},
+						},
+						Call: &ast.CallExpression{
+							Arguments: []ast.Expression{&ast.ObjectExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 41,
+											Line:   32,
+										},
+										File:   "max_gap_test.flux",
+										Source: "columns: {_value: \"level\"}",
+										Start: ast.Position{
+											Column: 15,
+											Line:   32,
+										},
+									},
+								},
+								Lbrace: nil,
+								Properties: []*ast.Property{&ast.Property{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   32,
+											},
+											File:   "max_gap_test.flux",
+											Source: "columns: {_value: \"level\"}",
+											Start: ast.Position{
+												Column: 15,
+												Line:   32,
+											},
+										},
+									},
+									Comma: nil,
+									Key: &ast.Identifier{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 22,
+													Line:   32,
+												},
+												File:   "max_gap_test.flux",
+												Source: "columns",
+												Start: ast.Position{
+													Column: 15,
+													Line:   32,
+												},
+											},
+										},
+										Name: "columns",
+									},
+									Separator: nil,
+									Value: &ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 41,
+													Line:   32,
+												},
+												File:   "max_gap_test.flux",
+												Source: "{_value: \"level\"}",
+												Start: ast.Position{
+													Column: 24,
+													Line:   32,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 40,
+														Line:   32,
+													},
+													File:   "max_gap_test.flux",
+													Source: "_value: \"level\"",
+													Start: ast.Position{
+														Column: 25,
+														Line:   32,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 31,
+															Line:   32,
+														},
+														File:   "max_gap_test.flux",
+														Source: "_value",
+														Start: ast.Position{
+															Column: 25,
+															Line:   32,
+														},
+													},
+												},
+												Name: "_value",
+											},
+											Separator: nil,
+											Value: &ast.StringLiteral{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 40,
+															Line:   32,
+														},
+														File:   "max_gap_test.flux",
+														Source: "\"level\"",
+														Start: ast.Position{
+															Column: 33,
+															Line:   32,
+														},
+													},
+												},
+												Value: "level",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									},
+								}},
+								Rbrace: nil,
+								With:   nil,
+							}},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 42,
+										Line:   32,
+									},
+									File:   "max_gap_test.flux",
+									Source: "rename(columns: {_value: \"level\"})",
+									Start: ast.Position{
+										Column: 8,
+										Line:   32,
+									},
+								},
+							},
+							Callee: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 14,
+											Line:   32,
+										},
+										File:   "max_gap_test.flux",
+										Source: "rename",
+										Start: ast.Position{
+											Column: 8,
+											Line:   32,
+										},
+									},
+								},
+								Name: "rename",
+							},
+							Lparen: nil,
+							Rparen: nil,
+						},
+					},
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 66,
+								Line:   33,
+							},
+							File:   "max_gap_test.flux",
+							Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> drop(columns: [\"_start\", \"_stop\"])\n    |> rename(columns: {_value: \"level\"})\n    |> interpolate.linear(every: 1m, column: \"level\", maxGap: 2m)",
+							Start: ast.Position{
+								Column: 31,
+								Line:   29,
+							},
+						},
+					},
+					Call: &ast.CallExpression{
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 65,
+										Line:   33,
+									},
+									File:   "max_gap_test.flux",
+									Source: "every: 1m, column: \"level\", maxGap: 2m",
+									Start: ast.Position{
+										Column: 27,
+										Line:   33,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 36,
+											Line:   33,
+										},
+										File:   "max_gap_test.flux",
+										Source: "every: 1m",
+										Start: ast.Position{
+											Column: 27,
+											Line:   33,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 32,
+												Line:   33,
+											},
+											File:   "max_gap_test.flux",
+											Source: "every",
+											Start: ast.Position{
+												Column: 27,
+												Line:   33,
+											},
+										},
+									},
+									Name: "every",
+								},
+								Separator: nil,
+								Value: &ast.DurationLiteral{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 36,
+												Line:   33,
+											},
+											File:   "max_gap_test.flux",
+											Source: "1m",
+											Start: ast.Position{
+												Column: 34,
+												Line:   33,
+											},
+										},
+									},
+									Values: []ast.Duration{ast.Duration{
+										Magnitude: int64(1),
+										Unit:      "m",
+									}},
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 53,
+											Line:   33,
+										},
+										File:   "max_gap_test.flux",
+										Source: "column: \"level\"",
+										Start: ast.Position{
+											Column: 38,
+											Line:   33,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 44,
+												Line:   33,
+											},
+											File:   "max_gap_test.flux",
+											Source: "column",
+											Start: ast.Position{
+												Column: 38,
+												Line:   33,
+											},
+										},
+									},
+									Name: "column",
+								},
+								Separator: nil,
+								Value: &ast.StringLiteral{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 53,
+												Line:   33,
+											},
+											File:   "max_gap_test.flux",
+											Source: "\"level\"",
+											Start: ast.Position{
+												Column: 46,
+												Line:   33,
+											},
+										},
+									},
+									Value: "level",
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 65,
+											Line:   33,
+										},
+										File:   "max_gap_test.flux",
+										Source: "maxGap: 2m",
+										Start: ast.Position{
+											Column: 55,
+											Line:   33,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 61,
+												Line:   33,
+											},
+											File:   "max_gap_test.flux",
+											Source: "maxGap",
+											Start: ast.Position{
+												Column: 55,
+												Line:   33,
+											},
+										},
+									},
+									Name: "maxGap",
+								},
+								Separator: nil,
+								Value: &ast.DurationLiteral{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 65,
+												Line:   33,
+											},
+											File:   "max_gap_test.flux",
+											Source: "2m",
+											Start: ast.Position{
+												Column: 63,
+												Line:   33,
+											},
+										},
+									},
+									Values: []ast.Duration{ast.Duration{
+										Magnitude: int64(2),
+										Unit:      "m",
+									}},
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						}},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 66,
+									Line:   33,
+								},
+								File:   "max_gap_test.flux",
+								Source: "interpolate.linear(every: 1m, column: \"level\", maxGap: 2m)",
+								Start: ast.Position{
+									Column: 8,
+									Line:   33,
+								},
+							},
+						},
+						Callee: &ast.MemberExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 26,
+										Line:   33,
+									},
+									File:   "max_gap_test.flux",
+									Source: "interpolate.linear",
+									Start: ast.Position{
+										Column: 8,
+										Line:   33,
+									},
+								},
+							},
+							Lbrack: nil,
+							Object: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 19,
+											Line:   33,
+										},
+										File:   "max_gap_test.flux",
+										Source: "interpolate",
+										Start: ast.Position{
+											Column: 8,
+											Line:   33,
+										},
+									},
+								},
+								Name: "interpolate",
+							},
+							Property: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 26,
+											Line:   33,
+										},
+										File:   "max_gap_test.flux",
+										Source: "linear",
+										Start: ast.Position{
+											Column: 20,
+											Line:   33,
+										},
+									},
+								},
+								Name: "linear",
+							},
+							Rbrack: nil,
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+				},
+				Lparen: nil,
+				Params: []*ast.Property{&ast.Property{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   29,
+							},
+							File:   "max_gap_test.flux",
+							Source: "table=<-",
+							Start: ast.Position{
+								Column: 18,
+								Line:   29,
+							},
+						},
+					},
+					Comma: nil,
+					Key: &ast.Identifier{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 23,
+									Line:   29,
+								},
+								File:   "max_gap_test.flux",
+								Source: "table",
+								Start: ast.Position{
+									Column: 18,
+									Line:   29,
+								},
+							},
+						},
+						Name: "table",
+					},
+					Separator: nil,
+					Value: &ast.PipeLiteral{BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   29,
+							},
+							File:   "max_gap_test.flux",
+							Source: "<-",
+							Start: ast.Position{
+								Column: 24,
+								Line:   29,
+							},
+						},
+					}},
+				}},
+				Rparan: nil,
+			},
+		}, &ast.TestStatement{
+			Assignment: &ast.VariableAssignment{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 133,
+							Line:   35,
+						},
+						File:   "max_gap_test.flux",
+						Source: "interpolate_max_gap = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+						Start: ast.Position{
+							Column: 6,
+							Line:   35,
+						},
+					},
+				},
+				ID: &ast.Identifier{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 25,
+								Line:   35,
+							},
+							File:   "max_gap_test.flux",
+							Source: "interpolate_max_gap",
+							Start: ast.Position{
+								Column: 6,
+								Line:   35,
+							},
+						},
+					},
+					Name: "interpolate_max_gap",
+				},
+				Init: &ast.FunctionExpression{
+					Arrow: nil,
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 133,
+								Line:   35,
+							},
+							File:   "max_gap_test.flux",
+							Source: "() => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+							Start: ast.Position{
+								Column: 28,
+								Line:   35,
+							},
+						},
+					},
+					Body: &ast.ParenExpression{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 133,
+									Line:   35,
+								},
+								File:   "max_gap_test.flux",
+								Source: "({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+								Start: ast.Position{
+									Column: 34,
+									Line:   35,
+								},
+							},
+						},
+						Expression: &ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 132,
+										Line:   35,
+									},
+									File:   "max_gap_test.flux",
+									Source: "{input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn}",
+									Start: ast.Position{
+										Column: 35,
+										Line:   35,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 75,
+											Line:   35,
+										},
+										File:   "max_gap_test.flux",
+										Source: "input: testing.loadStorage(csv: inData)",
+										Start: ast.Position{
+											Column: 36,
+											Line:   35,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   35,
+											},
+											File:   "max_gap_test.flux",
+											Source: "input",
+											Start: ast.Position{
+												Column: 36,
+												Line:   35,
+											},
+										},
+									},
+									Name: "input",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 74,
+													Line:   35,
+												},
+												File:   "max_gap_test.flux",
+												Source: "csv: inData",
+												Start: ast.Position{
+													Column: 63,
+													Line:   35,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 74,
+														Line:   35,
+													},
+													File:   "max_gap_test.flux",
+													Source: "csv: inData",
+													Start: ast.Position{
+														Column: 63,
+														Line:   35,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 66,
+															Line:   35,
+														},
+														File:   "max_gap_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 63,
+															Line:   35,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 74,
+															Line:   35,
+														},
+														File:   "max_gap_test.flux",
+														Source: "inData",
+														Start: ast.Position{
+															Column: 68,
+															Line:   35,
+														},
+													},
+												},
+												Name: "inData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 75,
+												Line:   35,
+											},
+											File:   "max_gap_test.flux",
+											Source: "testing.loadStorage(csv: inData)",
+											Start: ast.Position{
+												Column: 43,
+												Line:   35,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 62,
+													Line:   35,
+												},
+												File:   "max_gap_test.flux",
+												Source: "testing.loadStorage",
+												Start: ast.Position{
+													Column: 43,
+													Line:   35,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 50,
+														Line:   35,
+													},
+													File:   "max_gap_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 43,
+														Line:   35,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 62,
+														Line:   35,
+													},
+													File:   "max_gap_test.flux",
+													Source: "loadStorage",
+													Start: ast.Position{
+														Column: 51,
+														Line:   35,
+													},
+												},
+											},
+											Name: "loadStorage",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 112,
+											Line:   35,
+										},
+										File:   "max_gap_test.flux",
+										Source: "want: testing.loadMem(csv: outData)",
+										Start: ast.Position{
+											Column: 77,
+											Line:   35,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 81,
+												Line:   35,
+											},
+											File:   "max_gap_test.flux",
+											Source: "want",
+											Start: ast.Position{
+												Column: 77,
+												Line:   35,
+											},
+										},
+									},
+									Name: "want",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 111,
+													Line:   35,
+												},
+												File:   "max_gap_test.flux",
+												Source: "csv: outData",
+												Start: ast.Position{
+													Column: 99,
+													Line:   35,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 111,
+														Line:   35,
+													},
+													File:   "max_gap_test.flux",
+													Source: "csv: outData",
+													Start: ast.Position{
+														Column: 99,
+														Line:   35,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 102,
+															Line:   35,
+														},
+														File:   "max_gap_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 99,
+															Line:   35,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 111,
+															Line:   35,
+														},
+														File:   "max_gap_test.flux",
+														Source: "outData",
+														Start: ast.Position{
+															Column: 104,
+															Line:   35,
+														},
+													},
+												},
+												Name: "outData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 112,
+												Line:   35,
+											},
+											File:   "max_gap_test.flux",
+											Source: "testing.loadMem(csv: outData)",
+											Start: ast.Position{
+												Column: 83,
+												Line:   35,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 98,
+													Line:   35,
+												},
+												File:   "max_gap_test.flux",
+												Source: "testing.loadMem",
+												Start: ast.Position{
+													Column: 83,
+													Line:   35,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 90,
+														Line:   35,
+													},
+													File:   "max_gap_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 83,
+														Line:   35,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 98,
+														Line:   35,
+													},
+													File:   "max_gap_test.flux",
+													Source: "loadMem",
+													Start: ast.Position{
+														Column: 91,
+														Line:   35,
+													},
+												},
+											},
+											Name: "loadMem",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 131,
+											Line:   35,
+										},
+										File:   "max_gap_test.flux",
+										Source: "fn: interpolateFn",
+										Start: ast.Position{
+											Column: 114,
+											Line:   35,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 116,
+												Line:   35,
+											},
+											File:   "max_gap_test.flux",
+											Source: "fn",
+											Start: ast.Position{
+												Column: 114,
+												Line:   35,
+											},
+										},
+									},
+									Name: "fn",
+								},
+								Separator: nil,
+								Value: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 131,
+												Line:   35,
+											},
+											File:   "max_gap_test.flux",
+											Source: "interpolateFn",
+											Start: ast.Position{
+												Column: 118,
+												Line:   35,
+											},
+										},
+									},
+									Name: "interpolateFn",
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+					Lparen: nil,
+					Params: []*ast.Property{},
+					Rparan: nil,
+				},
+			},
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 133,
+						Line:   35,
+					},
+					File:   "max_gap_test.flux",
+					Source: "test interpolate_max_gap = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+					Start: ast.Position{
+						Column: 1,
+						Line:   35,
+					},
+				},
+			},
+		}},
+		Eof: nil,
+		Imports: []*ast.ImportDeclaration{&ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 17,
+						Line:   4,
+					},
+					File:   "max_gap_test.flux",
+					Source: "import \"testing\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   4,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 17,
+							Line:   4,
+						},
+						File:   "max_gap_test.flux",
+						Source: "\"testing\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   4,
+						},
+					},
+				},
+				Value: "testing",
+			},
+		}, &ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 21,
+						Line:   5,
+					},
+					File:   "max_gap_test.flux",
+					Source: "import \"interpolate\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   5,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 21,
+							Line:   5,
+						},
+						File:   "max_gap_test.flux",
+						Source: "\"interpolate\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   5,
+						},
+					},
+				},
+				Value: "interpolate",
+			},
+		}},
+		Metadata: "parser-type=rust",
+		Name:     "max_gap_test.flux",
+		Package: &ast.PackageClause{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 25,
+						Line:   1,
+					},
+					File:   "max_gap_test.flux",
+					Source: "package interpolate_test",
+					Start: ast.Position{
+						Column: 1,
+						Line:   1,
+					},
+				},
+			},
+			Name: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 25,
+							Line:   1,
+						},
+						File:   "max_gap_test.flux",
+						Source: "interpolate_test",
+						Start: ast.Position{
+							Column: 9,
+							Line:   1,
+						},
+					},
+				},
+				Name: "interpolate_test",
+			},
+		},
+	}, &ast.File{
+		BaseNode: ast.BaseNode{
+			Comments: nil,
+			Errors:   nil,
+			Loc: &ast.SourceLocation{
+				End: ast.Position{
+					Column: 133,
+					Line:   37,
+				},
+				File:   "nearest_test.flux",
+				Source: "package interpolate_test\n\n\nimport \"testing\"\nimport \"interpolate\"\n\ninData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"\noutData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"\ninterpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.nearest(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])\n\ntest interpolate_nearest = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+				Start: ast.Position{
+					Column: 1,
+					Line:   1,
+				},
+			},
+		},
+		Body: []ast.Statement{&ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   16,
+					},
+					File:   "nearest_test.flux",
+					Source: "inData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   7,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 7,
+							Line:   7,
+						},
+						File:   "nearest_test.flux",
+						Source: "inData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   7,
+						},
+					},
+				},
+				Name: "inData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   16,
+						},
+						File:   "nearest_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+						Start: ast.Position{
+							Column: 10,
+							Line:   7,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   31,
+					},
+					File:   "nearest_test.flux",
+					Source: "outData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   17,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 8,
+							Line:   17,
+						},
+						File:   "nearest_test.flux",
+						Source: "outData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   17,
+						},
+					},
+				},
+				Name: "outData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   31,
+						},
+						File:   "nearest_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+						Start: ast.Position{
+							Column: 11,
+							Line:   17,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 42,
+						Line:   35,
+					},
+					File:   "nearest_test.flux",
+					Source: "interpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.nearest(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+					Start: ast.Position{
+						Column: 1,
+						Line:   32,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 14,
+							Line:   32,
+						},
+						File:   "nearest_test.flux",
+						Source: "interpolateFn",
+						Start: ast.Position{
+							Column: 1,
+							Line:   32,
+						},
+					},
+				},
+				Name: "interpolateFn",
+			},
+			Init: &ast.FunctionExpression{
+				Arrow: nil,
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 42,
+							Line:   35,
+						},
+						File:   "nearest_test.flux",
+						Source: "(table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.nearest(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+						Start: ast.Position{
+							Column: 17,
+							Line:   32,
+						},
+					},
+				},
+				Body: &ast.PipeExpression{
+					Argument: &ast.PipeExpression{
+						Argument: &ast.PipeExpression{
+							Argument: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 36,
+											Line:   32,
+										},
+										File:   "nearest_test.flux",
+										Source: "table",
+										Start: ast.Position{
+											Column: 31,
+											Line:   32,
+										},
+									},
+								},
+								Name: "table",
+							},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 70,
+										Line:   33,
+									},
+									File:   "nearest_test.flux",
+									Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+									Start: ast.Position{
+										Column: 31,
+										Line:   32,
+									},
+								},
+							},
+							Call: &ast.CallExpression{
+								Arguments: []ast.Expression{&ast.ObjectExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 69,
+												Line:   33,
+											},
+											File:   "nearest_test.flux",
+											Source: "start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z",
+											Start: ast.Position{
+												Column: 14,
+												Line:   33,
+											},
+										},
+									},
+									Lbrace: nil,
+									Properties: []*ast.Property{&ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 41,
+													Line:   33,
+												},
+												File:   "nearest_test.flux",
+												Source: "start: 2014-01-01T01:00:00Z",
+												Start: ast.Position{
+													Column: 14,
+													Line:   33,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 19,
+														Line:   33,
+													},
+													File:   "nearest_test.flux",
+													Source: "start",
+													Start: ast.Position{
+														Column: 14,
+														Line:   33,
+													},
+												},
+											},
+											Name: "start",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 41,
+														Line:   33,
+													},
+													File:   "nearest_test.flux",
+													Source: "2014-01-01T01:00:00Z",
+													Start: ast.Position{
+														Column: 21,
+														Line:   33,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T01:00:00Z"),
+										},
+									}, &ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 69,
+													Line:   33,
+												},
+												File:   "nearest_test.flux",
+												Source: "stop: 2014-01-01T02:00:00Z",
+												Start: ast.Position{
+													Column: 43,
+													Line:   33,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 47,
+														Line:   33,
+													},
+													File:   "nearest_test.flux",
+													Source: "stop",
+													Start: ast.Position{
+														Column: 43,
+														Line:   33,
+													},
+												},
+											},
+											Name: "stop",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 69,
+														Line:   33,
+													},
+													File:   "nearest_test.flux",
+													Source: "2014-01-01T02:00:00Z",
+													Start: ast.Position{
+														Column: 49,
+														Line:   33,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T02:00:00Z"),
+										},
+									}},
+									Rbrace: nil,
+									With:   nil,
+								}},
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 70,
+											Line:   33,
+										},
+										File:   "nearest_test.flux",
+										Source: "range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+										Start: ast.Position{
+											Column: 8,
+											Line:   33,
+										},
+									},
+								},
+								Callee: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 13,
+												Line:   33,
+											},
+											File:   "nearest_test.flux",
+											Source: "range",
+											Start: ast.Position{
+												Column: 8,
+												Line:   33,
+											},
+										},
+									},
+									Name: "range",
+								},
+								Lparen: nil,
+								Rparen: nil,
+							},
+						},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 38,
+									Line:   34,
+								},
+								File:   "nearest_test.flux",
+								Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.nearest(every: 1m)",
+								Start: ast.Position{
+									Column: 31,
+									Line:   32,
+								},
+							},
+						},
+						Call: &ast.CallExpression{
+							Arguments: []ast.Expression{&ast.ObjectExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 37,
+											Line:   34,
+										},
+										File:   "nearest_test.flux",
+										Source: "every: 1m",
+										Start: ast.Position{
+											Column: 28,
+											Line:   34,
+										},
+									},
+								},
+								Lbrace: nil,
+								Properties: []*ast.Property{&ast.Property{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 37,
+												Line:   34,
+											},
+											File:   "nearest_test.flux",
+											Source: "every: 1m",
+											Start: ast.Position{
+												Column: 28,
+												Line:   34,
+											},
+										},
+									},
+									Comma: nil,
+									Key: &ast.Identifier{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 33,
+													Line:   34,
+												},
+												File:   "nearest_test.flux",
+												Source: "every",
+												Start: ast.Position{
+													Column: 28,
+													Line:   34,
+												},
+											},
+										},
+										Name: "every",
+									},
+									Separator: nil,
+									Value: &ast.DurationLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 37,
+													Line:   34,
+												},
+												File:   "nearest_test.flux",
+												Source: "1m",
+												Start: ast.Position{
+													Column: 35,
+													Line:   34,
+												},
+											},
+										},
+										Values: []ast.Duration{ast.Duration{
+											Magnitude: int64(1),
+											Unit:      "m",
+										}},
+									},
+								}},
+								Rbrace: nil,
+								With:   nil,
+							}},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 38,
+										Line:   34,
+									},
+									File:   "nearest_test.flux",
+									Source: "interpolate.nearest(every: 1m)",
+									Start: ast.Position{
+										Column: 8,
+										Line:   34,
+									},
+								},
+							},
+							Callee: &ast.MemberExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 27,
+											Line:   34,
+										},
+										File:   "nearest_test.flux",
+										Source: "interpolate.nearest",
+										Start: ast.Position{
+											Column: 8,
+											Line:   34,
+										},
+									},
+								},
+								Lbrack: nil,
+								Object: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 19,
+												Line:   34,
+											},
+											File:   "nearest_test.flux",
+											Source: "interpolate",
+											Start: ast.Position{
+												Column: 8,
+												Line:   34,
+											},
+										},
+									},
+									Name: "interpolate",
+								},
+								Property: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 27,
+												Line:   34,
+											},
+											File:   "nearest_test.flux",
+											Source: "nearest",
+											Start: ast.Position{
+												Column: 20,
+												Line:   34,
+											},
+										},
+									},
+									Name: "nearest",
+								},
+								Rbrack: nil,
+							},
+							Lparen: nil,
+							Rparen: nil,
+						},
+					},
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 42,
+								Line:   35,
+							},
+							File:   "nearest_test.flux",
+							Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.nearest(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+							Start: ast.Position{
+								Column: 31,
+								Line:   32,
+							},
+						},
+					},
+					Call: &ast.CallExpression{
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 41,
+										Line:   35,
+									},
+									File:   "nearest_test.flux",
+									Source: "columns: [\"_start\", \"_stop\"]",
+									Start: ast.Position{
+										Column: 13,
+										Line:   35,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 41,
+											Line:   35,
+										},
+										File:   "nearest_test.flux",
+										Source: "columns: [\"_start\", \"_stop\"]",
+										Start: ast.Position{
+											Column: 13,
+											Line:   35,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 20,
+												Line:   35,
+											},
+											File:   "nearest_test.flux",
+											Source: "columns",
+											Start: ast.Position{
+												Column: 13,
+												Line:   35,
+											},
+										},
+									},
+									Name: "columns",
+								},
+								Separator: nil,
+								Value: &ast.ArrayExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   35,
+											},
+											File:   "nearest_test.flux",
+											Source: "[\"_start\", \"_stop\"]",
+											Start: ast.Position{
+												Column: 22,
+												Line:   35,
+											},
+										},
+									},
+									Elements: []ast.Expression{&ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 31,
+													Line:   35,
+												},
+												File:   "nearest_test.flux",
+												Source: "\"_start\"",
+												Start: ast.Position{
+													Column: 23,
+													Line:   35,
+												},
+											},
+										},
+										Value: "_start",
+									}, &ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 40,
+													Line:   35,
+												},
+												File:   "nearest_test.flux",
+												Source: "\"_stop\"",
+												Start: ast.Position{
+													Column: 33,
+													Line:   35,
+												},
+											},
+										},
+										Value: "_stop",
+									}},
+									Lbrack: nil,
+									Rbrack: nil,
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						}},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 42,
+									Line:   35,
+								},
+								File:   "nearest_test.flux",
+								Source: "drop(columns: [\"_start\", \"_stop\"])",
+								Start: ast.Position{
+									Column: 8,
+									Line:   35,
+								},
+							},
+						},
+						Callee: &ast.Identifier{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 12,
+										Line:   35,
+									},
+									File:   "nearest_test.flux",
+									Source: "drop",
+									Start: ast.Position{
+										Column: 8,
+										Line:   35,
+									},
+								},
+							},
+							Name: "drop",
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+				},
+				Lparen: nil,
+				Params: []*ast.Property{&ast.Property{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   32,
+							},
+							File:   "nearest_test.flux",
+							Source: "table=<-",
+							Start: ast.Position{
+								Column: 18,
+								Line:   32,
+							},
+						},
+					},
+					Comma: nil,
+					Key: &ast.Identifier{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 23,
+									Line:   32,
+								},
+								File:   "nearest_test.flux",
+								Source: "table",
+								Start: ast.Position{
+									Column: 18,
+									Line:   32,
+								},
+							},
+						},
+						Name: "table",
+					},
+					Separator: nil,
+					Value: &ast.PipeLiteral{BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   32,
+							},
+							File:   "nearest_test.flux",
+							Source: "<-",
+							Start: ast.Position{
+								Column: 24,
+								Line:   32,
+							},
+						},
+					}},
+				}},
+				Rparan: nil,
+			},
+		}, &ast.TestStatement{
+			Assignment: &ast.VariableAssignment{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 133,
+							Line:   37,
+						},
+						File:   "nearest_test.flux",
+						Source: "interpolate_nearest = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+						Start: ast.Position{
+							Column: 6,
+							Line:   37,
+						},
+					},
+				},
+				ID: &ast.Identifier{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 25,
+								Line:   37,
+							},
+							File:   "nearest_test.flux",
+							Source: "interpolate_nearest",
+							Start: ast.Position{
+								Column: 6,
+								Line:   37,
+							},
+						},
+					},
+					Name: "interpolate_nearest",
+				},
+				Init: &ast.FunctionExpression{
+					Arrow: nil,
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 133,
+								Line:   37,
+							},
+							File:   "nearest_test.flux",
+							Source: "() => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+							Start: ast.Position{
+								Column: 28,
+								Line:   37,
+							},
+						},
+					},
+					Body: &ast.ParenExpression{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 133,
+									Line:   37,
+								},
+								File:   "nearest_test.flux",
+								Source: "({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+								Start: ast.Position{
+									Column: 34,
+									Line:   37,
+								},
+							},
+						},
+						Expression: &ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 132,
+										Line:   37,
+									},
+									File:   "nearest_test.flux",
+									Source: "{input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn}",
+									Start: ast.Position{
+										Column: 35,
+										Line:   37,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 75,
+											Line:   37,
+										},
+										File:   "nearest_test.flux",
+										Source: "input: testing.loadStorage(csv: inData)",
+										Start: ast.Position{
+											Column: 36,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   37,
+											},
+											File:   "nearest_test.flux",
+											Source: "input",
+											Start: ast.Position{
+												Column: 36,
+												Line:   37,
+											},
+										},
+									},
+									Name: "input",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 74,
+													Line:   37,
+												},
+												File:   "nearest_test.flux",
+												Source: "csv: inData",
+												Start: ast.Position{
+													Column: 63,
+													Line:   37,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 74,
+														Line:   37,
+													},
+													File:   "nearest_test.flux",
+													Source: "csv: inData",
+													Start: ast.Position{
+														Column: 63,
+														Line:   37,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 66,
+															Line:   37,
+														},
+														File:   "nearest_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 63,
+															Line:   37,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 74,
+															Line:   37,
+														},
+														File:   "nearest_test.flux",
+														Source: "inData",
+														Start: ast.Position{
+															Column: 68,
+															Line:   37,
+														},
+													},
+												},
+												Name: "inData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 75,
+												Line:   37,
+											},
+											File:   "nearest_test.flux",
+											Source: "testing.loadStorage(csv: inData)",
+											Start: ast.Position{
+												Column: 43,
+												Line:   37,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 62,
+													Line:   37,
+												},
+												File:   "nearest_test.flux",
+												Source: "testing.loadStorage",
+												Start: ast.Position{
+													Column: 43,
+													Line:   37,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 50,
+														Line:   37,
+													},
+													File:   "nearest_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 43,
+														Line:   37,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 62,
+														Line:   37,
+													},
+													File:   "nearest_test.flux",
+													Source: "loadStorage",
+													Start: ast.Position{
+														Column: 51,
+														Line:   37,
+													},
+												},
+											},
+											Name: "loadStorage",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 112,
+											Line:   37,
+										},
+										File:   "nearest_test.flux",
+										Source: "want: testing.loadMem(csv: outData)",
+										Start: ast.Position{
+											Column: 77,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 81,
+												Line:   37,
+											},
+											File:   "nearest_test.flux",
+											Source: "want",
+											Start: ast.Position{
+												Column: 77,
+												Line:   37,
+											},
+										},
+									},
+									Name: "want",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 111,
+													Line:   37,
+												},
+												File:   "nearest_test.flux",
+												Source: "csv: outData",
+												Start: ast.Position{
+													Column: 99,
+													Line:   37,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 111,
+														Line:   37,
+													},
+													File:   "nearest_test.flux",
+													Source: "csv: outData",
+													Start: ast.Position{
+														Column: 99,
+														Line:   37,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 102,
+															Line:   37,
+														},
+														File:   "nearest_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 99,
+															Line:   37,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 111,
+															Line:   37,
+														},
+														File:   "nearest_test.flux",
+														Source: "outData",
+														Start: ast.Position{
+															Column: 104,
+															Line:   37,
+														},
+													},
+												},
+												Name: "outData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 112,
+												Line:   37,
+											},
+											File:   "nearest_test.flux",
+											Source: "testing.loadMem(csv: outData)",
+											Start: ast.Position{
+												Column: 83,
+												Line:   37,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 98,
+													Line:   37,
+												},
+												File:   "nearest_test.flux",
+												Source: "testing.loadMem",
+												Start: ast.Position{
+													Column: 83,
+													Line:   37,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 90,
+														Line:   37,
+													},
+													File:   "nearest_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 83,
+														Line:   37,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 98,
+														Line:   37,
+													},
+													File:   "nearest_test.flux",
+													Source: "loadMem",
+													Start: ast.Position{
+														Column: 91,
+														Line:   37,
+													},
+												},
+											},
+											Name: "loadMem",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 131,
+											Line:   37,
+										},
+										File:   "nearest_test.flux",
+										Source: "fn: interpolateFn",
+										Start: ast.Position{
+											Column: 114,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 116,
+												Line:   37,
+											},
+											File:   "nearest_test.flux",
+											Source: "fn",
+											Start: ast.Position{
+												Column: 114,
+												Line:   37,
+											},
+										},
+									},
+									Name: "fn",
+								},
+								Separator: nil,
+								Value: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 131,
+												Line:   37,
+											},
+											File:   "nearest_test.flux",
+											Source: "interpolateFn",
+											Start: ast.Position{
+												Column: 118,
+												Line:   37,
+											},
+										},
+									},
+									Name: "interpolateFn",
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+					Lparen: nil,
+					Params: []*ast.Property{},
+					Rparan: nil,
+				},
+			},
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 133,
+						Line:   37,
+					},
+					File:   "nearest_test.flux",
+					Source: "test interpolate_nearest = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+					Start: ast.Position{
+						Column: 1,
+						Line:   37,
+					},
+				},
+			},
+		}},
+		Eof: nil,
+		Imports: []*ast.ImportDeclaration{&ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 17,
+						Line:   4,
+					},
+					File:   "nearest_test.flux",
+					Source: "import \"testing\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   4,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 17,
+							Line:   4,
+						},
+						File:   "nearest_test.flux",
+						Source: "\"testing\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   4,
+						},
+					},
+				},
+				Value: "testing",
+			},
+		}, &ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 21,
+						Line:   5,
+					},
+					File:   "nearest_test.flux",
+					Source: "import \"interpolate\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   5,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 21,
+							Line:   5,
+						},
+						File:   "nearest_test.flux",
+						Source: "\"interpolate\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   5,
+						},
+					},
+				},
+				Value: "interpolate",
+			},
+		}},
+		Metadata: "parser-type=rust",
+		Name:     "nearest_test.flux",
+		Package: &ast.PackageClause{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 25,
+						Line:   1,
+					},
+					File:   "nearest_test.flux",
+					Source: "package interpolate_test",
+					Start: ast.Position{
+						Column: 1,
+						Line:   1,
+					},
+				},
+			},
+			Name: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 25,
+							Line:   1,
+						},
+						File:   "nearest_test.flux",
+						Source: "interpolate_test",
+						Start: ast.Position{
+							Column: 9,
+							Line:   1,
+						},
+					},
+				},
+				Name: "interpolate_test",
+			},
+		},
+	}, &ast.File{
+		BaseNode: ast.BaseNode{
+			Comments: nil,
+			Errors:   nil,
+			Loc: &ast.SourceLocation{
+				End: ast.Position{
+					Column: 130,
+					Line:   37,
+				},
+				File:   "next_test.flux",
+				Source: "package interpolate_test\n\n\nimport \"testing\"\nimport \"interpolate\"\n\ninData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"\noutData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,8\n,,0,2014-01-01T01:06:00Z,_m,FF,8\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"\ninterpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.next(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])\n\ntest interpolate_next = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+				Start: ast.Position{
+					Column: 1,
+					Line:   1,
+				},
+			},
+		},
+		Body: []ast.Statement{&ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   16,
+					},
+					File:   "next_test.flux",
+					Source: "inData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   7,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 7,
+							Line:   7,
+						},
+						File:   "next_test.flux",
+						Source: "inData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   7,
+						},
+					},
+				},
+				Name: "inData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   16,
+						},
+						File:   "next_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+						Start: ast.Position{
+							Column: 10,
+							Line:   7,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   31,
+					},
+					File:   "next_test.flux",
+					Source: "outData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,8\n,,0,2014-01-01T01:06:00Z,_m,FF,8\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   17,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 8,
+							Line:   17,
+						},
+						File:   "next_test.flux",
+						Source: "outData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   17,
+						},
+					},
+				},
+				Name: "outData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   31,
+						},
+						File:   "next_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,8\n,,0,2014-01-01T01:06:00Z,_m,FF,8\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+						Start: ast.Position{
+							Column: 11,
+							Line:   17,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,3\n,,0,2014-01-01T01:02:00Z,_m,FF,3\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,8\n,,0,2014-01-01T01:06:00Z,_m,FF,8\n,,0,2014-01-01T01:07:00Z,_m,FF,8\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 42,
+						Line:   35,
+					},
+					File:   "next_test.flux",
+					Source: "interpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.next(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+					Start: ast.Position{
+						Column: 1,
+						Line:   32,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 14,
+							Line:   32,
+						},
+						File:   "next_test.flux",
+						Source: "interpolateFn",
+						Start: ast.Position{
+							Column: 1,
+							Line:   32,
+						},
+					},
+				},
+				Name: "interpolateFn",
+			},
+			Init: &ast.FunctionExpression{
+				Arrow: nil,
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 42,
+							Line:   35,
+						},
+						File:   "next_test.flux",
+						Source: "(table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.next(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+						Start: ast.Position{
+							Column: 17,
+							Line:   32,
+						},
+					},
+				},
+				Body: &ast.PipeExpression{
+					Argument: &ast.PipeExpression{
+						Argument: &ast.PipeExpression{
+							Argument: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 36,
+											Line:   32,
+										},
+										File:   "next_test.flux",
+										Source: "table",
+										Start: ast.Position{
+											Column: 31,
+											Line:   32,
+										},
+									},
+								},
+								Name: "table",
+							},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 70,
+										Line:   33,
+									},
+									File:   "next_test.flux",
+									Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+									Start: ast.Position{
+										Column: 31,
+										Line:   32,
+									},
+								},
+							},
+							Call: &ast.CallExpression{
+								Arguments: []ast.Expression{&ast.ObjectExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 69,
+												Line:   33,
+											},
+											File:   "next_test.flux",
+											Source: "start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z",
+											Start: ast.Position{
+												Column: 14,
+												Line:   33,
+											},
+										},
+									},
+									Lbrace: nil,
+									Properties: []*ast.Property{&ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 41,
+													Line:   33,
+												},
+												File:   "next_test.flux",
+												Source: "start: 2014-01-01T01:00:00Z",
+												Start: ast.Position{
+													Column: 14,
+													Line:   33,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 19,
+														Line:   33,
+													},
+													File:   "next_test.flux",
+													Source: "start",
+													Start: ast.Position{
+														Column: 14,
+														Line:   33,
+													},
+												},
+											},
+											Name: "start",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 41,
+														Line:   33,
+													},
+													File:   "next_test.flux",
+													Source: "2014-01-01T01:00:00Z",
+													Start: ast.Position{
+														Column: 21,
+														Line:   33,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T01:00:00Z"),
+										},
+									}, &ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 69,
+													Line:   33,
+												},
+												File:   "next_test.flux",
+												Source: "stop: 2014-01-01T02:00:00Z",
+												Start: ast.Position{
+													Column: 43,
+													Line:   33,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 47,
+														Line:   33,
+													},
+													File:   "next_test.flux",
+													Source: "stop",
+													Start: ast.Position{
+														Column: 43,
+														Line:   33,
+													},
+												},
+											},
+											Name: "stop",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 69,
+														Line:   33,
+													},
+													File:   "next_test.flux",
+													Source: "2014-01-01T02:00:00Z",
+													Start: ast.Position{
+														Column: 49,
+														Line:   33,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T02:00:00Z"),
+										},
+									}},
+									Rbrace: nil,
+									With:   nil,
+								}},
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 70,
+											Line:   33,
+										},
+										File:   "next_test.flux",
+										Source: "range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+										Start: ast.Position{
+											Column: 8,
+											Line:   33,
+										},
+									},
+								},
+								Callee: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 13,
+												Line:   33,
+											},
+											File:   "next_test.flux",
+											Source: "range",
+											Start: ast.Position{
+												Column: 8,
+												Line:   33,
+											},
+										},
+									},
+									Name: "range",
+								},
+								Lparen: nil,
+								Rparen: nil,
+							},
+						},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 35,
+									Line:   34,
+								},
+								File:   "next_test.flux",
+								Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.next(every: 1m)",
+								Start: ast.Position{
+									Column: 31,
+									Line:   32,
+								},
+							},
+						},
+						Call: &ast.CallExpression{
+							Arguments: []ast.Expression{&ast.ObjectExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 34,
+											Line:   34,
+										},
+										File:   "next_test.flux",
+										Source: "every: 1m",
+										Start: ast.Position{
+											Column: 25,
+											Line:   34,
+										},
+									},
+								},
+								Lbrace: nil,
+								Properties: []*ast.Property{&ast.Property{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 34,
+												Line:   34,
+											},
+											File:   "next_test.flux",
+											Source: "every: 1m",
+											Start: ast.Position{
+												Column: 25,
+												Line:   34,
+											},
+										},
+									},
+									Comma: nil,
+									Key: &ast.Identifier{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 30,
+													Line:   34,
+												},
+												File:   "next_test.flux",
+												Source: "every",
+												Start: ast.Position{
+													Column: 25,
+													Line:   34,
+												},
+											},
+										},
+										Name: "every",
+									},
+									Separator: nil,
+									Value: &ast.DurationLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 34,
+													Line:   34,
+												},
+												File:   "next_test.flux",
+												Source: "1m",
+												Start: ast.Position{
+													Column: 32,
+													Line:   34,
+												},
+											},
+										},
+										Values: []ast.Duration{ast.Duration{
+											Magnitude: int64(1),
+											Unit:      "m",
+										}},
+									},
+								}},
+								Rbrace: nil,
+								With:   nil,
+							}},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 35,
+										Line:   34,
+									},
+									File:   "next_test.flux",
+									Source: "interpolate.next(every: 1m)",
+									Start: ast.Position{
+										Column: 8,
+										Line:   34,
+									},
+								},
+							},
+							Callee: &ast.MemberExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 24,
+											Line:   34,
+										},
+										File:   "next_test.flux",
+										Source: "interpolate.next",
+										Start: ast.Position{
+											Column: 8,
+											Line:   34,
+										},
+									},
+								},
+								Lbrack: nil,
+								Object: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 19,
+												Line:   34,
+											},
+											File:   "next_test.flux",
+											Source: "interpolate",
+											Start: ast.Position{
+												Column: 8,
+												Line:   34,
+											},
+										},
+									},
+									Name: "interpolate",
+								},
+								Property: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 24,
+												Line:   34,
+											},
+											File:   "next_test.flux",
+											Source: "next",
+											Start: ast.Position{
+												Column: 20,
+												Line:   34,
+											},
+										},
+									},
+									Name: "next",
+								},
+								Rbrack: nil,
+							},
+							Lparen: nil,
+							Rparen: nil,
+						},
+					},
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 42,
+								Line:   35,
+							},
+							File:   "next_test.flux",
+							Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.next(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+							Start: ast.Position{
+								Column: 31,
+								Line:   32,
+							},
+						},
+					},
+					Call: &ast.CallExpression{
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 41,
+										Line:   35,
+									},
+									File:   "next_test.flux",
+									Source: "columns: [\"_start\", \"_stop\"]",
+									Start: ast.Position{
+										Column: 13,
+										Line:   35,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 41,
+											Line:   35,
+										},
+										File:   "next_test.flux",
+										Source: "columns: [\"_start\", \"_stop\"]",
+										Start: ast.Position{
+											Column: 13,
+											Line:   35,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 20,
+												Line:   35,
+											},
+											File:   "next_test.flux",
+											Source: "columns",
+											Start: ast.Position{
+												Column: 13,
+												Line:   35,
+											},
+										},
+									},
+									Name: "columns",
+								},
+								Separator: nil,
+								Value: &ast.ArrayExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   35,
+											},
+											File:   "next_test.flux",
+											Source: "[\"_start\", \"_stop\"]",
+											Start: ast.Position{
+												Column: 22,
+												Line:   35,
+											},
+										},
+									},
+									Elements: []ast.Expression{&ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 31,
+													Line:   35,
+												},
+												File:   "next_test.flux",
+												Source: "\"_start\"",
+												Start: ast.Position{
+													Column: 23,
+													Line:   35,
+												},
+											},
+										},
+										Value: "_start",
+									}, &ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 40,
+													Line:   35,
+												},
+												File:   "next_test.flux",
+												Source: "\"_stop\"",
+												Start: ast.Position{
+													Column: 33,
+													Line:   35,
+												},
+											},
+										},
+										Value: "_stop",
+									}},
+									Lbrack: nil,
+									Rbrack: nil,
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						}},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 42,
+									Line:   35,
+								},
+								File:   "next_test.flux",
+								Source: "drop(columns: [\"_start\", \"_stop\"])",
+								Start: ast.Position{
+									Column: 8,
+									Line:   35,
+								},
+							},
+						},
+						Callee: &ast.Identifier{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 12,
+										Line:   35,
+									},
+									File:   "next_test.flux",
+									Source: "drop",
+									Start: ast.Position{
+										Column: 8,
+										Line:   35,
+									},
+								},
+							},
+							Name: "drop",
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+				},
+				Lparen: nil,
+				Params: []*ast.Property{&ast.Property{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   32,
+							},
+							File:   "next_test.flux",
+							Source: "table=<-",
+							Start: ast.Position{
+								Column: 18,
+								Line:   32,
+							},
+						},
+					},
+					Comma: nil,
+					Key: &ast.Identifier{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 23,
+									Line:   32,
+								},
+								File:   "next_test.flux",
+								Source: "table",
+								Start: ast.Position{
+									Column: 18,
+									Line:   32,
+								},
+							},
+						},
+						Name: "table",
+					},
+					Separator: nil,
+					Value: &ast.PipeLiteral{BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   32,
+							},
+							File:   "next_test.flux",
+							Source: "<-",
+							Start: ast.Position{
+								Column: 24,
+								Line:   32,
+							},
+						},
+					}},
+				}},
+				Rparan: nil,
+			},
+		}, &ast.TestStatement{
+			Assignment: &ast.VariableAssignment{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 130,
+							Line:   37,
+						},
+						File:   "next_test.flux",
+						Source: "interpolate_next = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+						Start: ast.Position{
+							Column: 6,
+							Line:   37,
+						},
+					},
+				},
+				ID: &ast.Identifier{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 22,
+								Line:   37,
+							},
+							File:   "next_test.flux",
+							Source: "interpolate_next",
+							Start: ast.Position{
+								Column: 6,
+								Line:   37,
+							},
+						},
+					},
+					Name: "interpolate_next",
+				},
+				Init: &ast.FunctionExpression{
+					Arrow: nil,
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 130,
+								Line:   37,
+							},
+							File:   "next_test.flux",
+							Source: "() => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+							Start: ast.Position{
+								Column: 25,
+								Line:   37,
+							},
+						},
+					},
+					Body: &ast.ParenExpression{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 130,
+									Line:   37,
+								},
+								File:   "next_test.flux",
+								Source: "({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+								Start: ast.Position{
+									Column: 31,
+									Line:   37,
+								},
+							},
+						},
+						Expression: &ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 129,
+										Line:   37,
+									},
+									File:   "next_test.flux",
+									Source: "{input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn}",
+									Start: ast.Position{
+										Column: 32,
+										Line:   37,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 72,
+											Line:   37,
+										},
+										File:   "next_test.flux",
+										Source: "input: testing.loadStorage(csv: inData)",
+										Start: ast.Position{
+											Column: 33,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 38,
+												Line:   37,
+											},
+											File:   "next_test.flux",
+											Source: "input",
+											Start: ast.Position{
+												Column: 33,
+												Line:   37,
+											},
+										},
+									},
+									Name: "input",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 71,
+													Line:   37,
+												},
+												File:   "next_test.flux",
+												Source: "csv: inData",
+												Start: ast.Position{
+													Column: 60,
+													Line:   37,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 71,
+														Line:   37,
+													},
+													File:   "next_test.flux",
+													Source: "csv: inData",
+													Start: ast.Position{
+														Column: 60,
+														Line:   37,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 63,
+															Line:   37,
+														},
+														File:   "next_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 60,
+															Line:   37,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 71,
+															Line:   37,
+														},
+														File:   "next_test.flux",
+														Source: "inData",
+														Start: ast.Position{
+															Column: 65,
+															Line:   37,
+														},
+													},
+												},
+												Name: "inData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 72,
+												Line:   37,
+											},
+											File:   "next_test.flux",
+											Source: "testing.loadStorage(csv: inData)",
+											Start: ast.Position{
+												Column: 40,
+												Line:   37,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 59,
+													Line:   37,
+												},
+												File:   "next_test.flux",
+												Source: "testing.loadStorage",
+												Start: ast.Position{
+													Column: 40,
+													Line:   37,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 47,
+														Line:   37,
+													},
+													File:   "next_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 40,
+														Line:   37,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 59,
+														Line:   37,
+													},
+													File:   "next_test.flux",
+													Source: "loadStorage",
+													Start: ast.Position{
+														Column: 48,
+														Line:   37,
+													},
+												},
+											},
+											Name: "loadStorage",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 109,
+											Line:   37,
+										},
+										File:   "next_test.flux",
+										Source: "want: testing.loadMem(csv: outData)",
+										Start: ast.Position{
+											Column: 74,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 78,
+												Line:   37,
+											},
+											File:   "next_test.flux",
+											Source: "want",
+											Start: ast.Position{
+												Column: 74,
+												Line:   37,
+											},
+										},
+									},
+									Name: "want",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 108,
+													Line:   37,
+												},
+												File:   "next_test.flux",
+												Source: "csv: outData",
+												Start: ast.Position{
+													Column: 96,
+													Line:   37,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 108,
+														Line:   37,
+													},
+													File:   "next_test.flux",
+													Source: "csv: outData",
+													Start: ast.Position{
+														Column: 96,
+														Line:   37,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 99,
+															Line:   37,
+														},
+														File:   "next_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 96,
+															Line:   37,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 108,
+															Line:   37,
+														},
+														File:   "next_test.flux",
+														Source: "outData",
+														Start: ast.Position{
+															Column: 101,
+															Line:   37,
+														},
+													},
+												},
+												Name: "outData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 109,
+												Line:   37,
+											},
+											File:   "next_test.flux",
+											Source: "testing.loadMem(csv: outData)",
+											Start: ast.Position{
+												Column: 80,
+												Line:   37,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 95,
+													Line:   37,
+												},
+												File:   "next_test.flux",
+												Source: "testing.loadMem",
+												Start: ast.Position{
+													Column: 80,
+													Line:   37,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 87,
+														Line:   37,
+													},
+													File:   "next_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 80,
+														Line:   37,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 95,
+														Line:   37,
+													},
+													File:   "next_test.flux",
+													Source: "loadMem",
+													Start: ast.Position{
+														Column: 88,
+														Line:   37,
+													},
+												},
+											},
+											Name: "loadMem",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 128,
+											Line:   37,
+										},
+										File:   "next_test.flux",
+										Source: "fn: interpolateFn",
+										Start: ast.Position{
+											Column: 111,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 113,
+												Line:   37,
+											},
+											File:   "next_test.flux",
+											Source: "fn",
+											Start: ast.Position{
+												Column: 111,
+												Line:   37,
+											},
+										},
+									},
+									Name: "fn",
+								},
+								Separator: nil,
+								Value: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 128,
+												Line:   37,
+											},
+											File:   "next_test.flux",
+											Source: "interpolateFn",
+											Start: ast.Position{
+												Column: 115,
+												Line:   37,
+											},
+										},
+									},
+									Name: "interpolateFn",
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+					Lparen: nil,
+					Params: []*ast.Property{},
+					Rparan: nil,
+				},
+			},
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 130,
+						Line:   37,
+					},
+					File:   "next_test.flux",
+					Source: "test interpolate_next = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+					Start: ast.Position{
+						Column: 1,
+						Line:   37,
+					},
+				},
+			},
+		}},
+		Eof: nil,
+		Imports: []*ast.ImportDeclaration{&ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 17,
+						Line:   4,
+					},
+					File:   "next_test.flux",
+					Source: "import \"testing\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   4,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 17,
+							Line:   4,
+						},
+						File:   "next_test.flux",
+						Source: "\"testing\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   4,
+						},
+					},
+				},
+				Value: "testing",
+			},
+		}, &ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 21,
+						Line:   5,
+					},
+					File:   "next_test.flux",
+					Source: "import \"interpolate\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   5,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 21,
+							Line:   5,
+						},
+						File:   "next_test.flux",
+						Source: "\"interpolate\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   5,
+						},
+					},
+				},
+				Value: "interpolate",
+			},
+		}},
+		Metadata: "parser-type=rust",
+		Name:     "next_test.flux",
+		Package: &ast.PackageClause{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 25,
+						Line:   1,
+					},
+					File:   "next_test.flux",
+					Source: "package interpolate_test",
+					Start: ast.Position{
+						Column: 1,
+						Line:   1,
+					},
+				},
+			},
+			Name: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 25,
+							Line:   1,
+						},
+						File:   "next_test.flux",
+						Source: "interpolate_test",
+						Start: ast.Position{
+							Column: 9,
+							Line:   1,
+						},
+					},
+				},
+				Name: "interpolate_test",
+			},
+		},
+	}, &ast.File{
+		BaseNode: ast.BaseNode{
+			Comments: nil,
+			Errors:   nil,
+			Loc: &ast.SourceLocation{
+				End: ast.Position{
+					Column: 134,
+					Line:   37,
+				},
+				File:   "previous_test.flux",
+				Source: "package interpolate_test\n\n\nimport \"testing\"\nimport \"interpolate\"\n\ninData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"\noutData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"\ninterpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.previous(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])\n\ntest interpolate_previous = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+				Start: ast.Position{
+					Column: 1,
+					Line:   1,
+				},
+			},
+		},
+		Body: []ast.Statement{&ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   16,
+					},
+					File:   "previous_test.flux",
+					Source: "inData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   7,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 7,
+							Line:   7,
+						},
+						File:   "previous_test.flux",
+						Source: "inData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   7,
+						},
+					},
+				},
+				Name: "inData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   16,
+						},
+						File:   "previous_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+						Start: ast.Position{
+							Column: 10,
+							Line:   7,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   31,
+					},
+					File:   "previous_test.flux",
+					Source: "outData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   17,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 8,
+							Line:   17,
+						},
+						File:   "previous_test.flux",
+						Source: "outData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   17,
+						},
+					},
+				},
+				Name: "outData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   31,
+						},
+						File:   "previous_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n\"",
+						Start: ast.Position{
+							Column: 11,
+							Line:   17,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,0\n,,0,2014-01-01T01:03:00Z,_m,FF,3\n,,0,2014-01-01T01:04:00Z,_m,FF,4\n,,0,2014-01-01T01:05:00Z,_m,FF,4\n,,0,2014-01-01T01:06:00Z,_m,FF,4\n,,0,2014-01-01T01:07:00Z,_m,FF,4\n,,0,2014-01-01T01:08:00Z,_m,FF,8\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 42,
+						Line:   35,
+					},
+					File:   "previous_test.flux",
+					Source: "interpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.previous(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+					Start: ast.Position{
+						Column: 1,
+						Line:   32,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 14,
+							Line:   32,
+						},
+						File:   "previous_test.flux",
+						Source: "interpolateFn",
+						Start: ast.Position{
+							Column: 1,
+							Line:   32,
+						},
+					},
+				},
+				Name: "interpolateFn",
+			},
+			Init: &ast.FunctionExpression{
+				Arrow: nil,
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 42,
+							Line:   35,
+						},
+						File:   "previous_test.flux",
+						Source: "(table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.previous(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+						Start: ast.Position{
+							Column: 17,
+							Line:   32,
+						},
+					},
+				},
+				Body: &ast.PipeExpression{
+					Argument: &ast.PipeExpression{
+						Argument: &ast.PipeExpression{
+							Argument: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 36,
+											Line:   32,
+										},
+										File:   "previous_test.flux",
+										Source: "table",
+										Start: ast.Position{
+											Column: 31,
+											Line:   32,
+										},
+									},
+								},
+								Name: "table",
+							},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 70,
+										Line:   33,
+									},
+									File:   "previous_test.flux",
+									Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+									Start: ast.Position{
+										Column: 31,
+										Line:   32,
+									},
+								},
+							},
+							Call: &ast.CallExpression{
+								Arguments: []ast.Expression{&ast.ObjectExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 69,
+												Line:   33,
+											},
+											File:   "previous_test.flux",
+											Source: "start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z",
+											Start: ast.Position{
+												Column: 14,
+												Line:   33,
+											},
+										},
+									},
+									Lbrace: nil,
+									Properties: []*ast.Property{&ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 41,
+													Line:   33,
+												},
+												File:   "previous_test.flux",
+												Source: "start: 2014-01-01T01:00:00Z",
+												Start: ast.Position{
+													Column: 14,
+													Line:   33,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 19,
+														Line:   33,
+													},
+													File:   "previous_test.flux",
+													Source: "start",
+													Start: ast.Position{
+														Column: 14,
+														Line:   33,
+													},
+												},
+											},
+											Name: "start",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 41,
+														Line:   33,
+													},
+													File:   "previous_test.flux",
+													Source: "2014-01-01T01:00:00Z",
+													Start: ast.Position{
+														Column: 21,
+														Line:   33,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T01:00:00Z"),
+										},
+									}, &ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 69,
+													Line:   33,
+												},
+												File:   "previous_test.flux",
+												Source: "stop: 2014-01-01T02:00:00Z",
+												Start: ast.Position{
+													Column: 43,
+													Line:   33,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 47,
+														Line:   33,
+													},
+													File:   "previous_test.flux",
+													Source: "stop",
+													Start: ast.Position{
+														Column: 43,
+														Line:   33,
+													},
+												},
+											},
+											Name: "stop",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 69,
+														Line:   33,
+													},
+													File:   "previous_test.flux",
+													Source: "2014-01-01T02:00:00Z",
+													Start: ast.Position{
+														Column: 49,
+														Line:   33,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T02:00:00Z"),
+										},
+									}},
+									Rbrace: nil,
+									With:   nil,
+								}},
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 70,
+											Line:   33,
+										},
+										File:   "previous_test.flux",
+										Source: "range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+										Start: ast.Position{
+											Column: 8,
+											Line:   33,
+										},
+									},
+								},
+								Callee: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 13,
+												Line:   33,
+											},
+											File:   "previous_test.flux",
+											Source: "range",
+											Start: ast.Position{
+												Column: 8,
+												Line:   33,
+											},
+										},
+									},
+									Name: "range",
+								},
+								Lparen: nil,
+								Rparen: nil,
+							},
+						},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 39,
+									Line:   34,
+								},
+								File:   "previous_test.flux",
+								Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.previous(every: 1m)",
+								Start: ast.Position{
+									Column: 31,
+									Line:   32,
+								},
+							},
+						},
+						Call: &ast.CallExpression{
+							Arguments: []ast.Expression{&ast.ObjectExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 38,
+											Line:   34,
+										},
+										File:   "previous_test.flux",
+										Source: "every: 1m",
+										Start: ast.Position{
+											Column: 29,
+											Line:   34,
+										},
+									},
+								},
+								Lbrace: nil,
+								Properties: []*ast.Property{&ast.Property{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 38,
+												Line:   34,
+											},
+											File:   "previous_test.flux",
+											Source: "every: 1m",
+											Start: ast.Position{
+												Column: 29,
+												Line:   34,
+											},
+										},
+									},
+									Comma: nil,
+									Key: &ast.Identifier{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 34,
+													Line:   34,
+												},
+												File:   "previous_test.flux",
+												Source: "every",
+												Start: ast.Position{
+													Column: 29,
+													Line:   34,
+												},
+											},
+										},
+										Name: "every",
+									},
+									Separator: nil,
+									Value: &ast.DurationLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 38,
+													Line:   34,
+												},
+												File:   "previous_test.flux",
+												Source: "1m",
+												Start: ast.Position{
+													Column: 36,
+													Line:   34,
+												},
+											},
+										},
+										Values: []ast.Duration{ast.Duration{
+											Magnitude: int64(1),
+											Unit:      "m",
+										}},
+									},
+								}},
+								Rbrace: nil,
+								With:   nil,
+							}},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 39,
+										Line:   34,
+									},
+									File:   "previous_test.flux",
+									Source: "interpolate.previous(every: 1m)",
+									Start: ast.Position{
+										Column: 8,
+										Line:   34,
+									},
+								},
+							},
+							Callee: &ast.MemberExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 28,
+											Line:   34,
+										},
+										File:   "previous_test.flux",
+										Source: "interpolate.previous",
+										Start: ast.Position{
+											Column: 8,
+											Line:   34,
+										},
+									},
+								},
+								Lbrack: nil,
+								Object: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 19,
+												Line:   34,
+											},
+											File:   "previous_test.flux",
+											Source: "interpolate",
+											Start: ast.Position{
+												Column: 8,
+												Line:   34,
+											},
+										},
+									},
+									Name: "interpolate",
+								},
+								Property: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 28,
+												Line:   34,
+											},
+											File:   "previous_test.flux",
+											Source: "previous",
+											Start: ast.Position{
+												Column: 20,
+												Line:   34,
+											},
+										},
+									},
+									Name: "previous",
+								},
+								Rbrack: nil,
+							},
+							Lparen: nil,
+							Rparen: nil,
+						},
+					},
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 42,
+								Line:   35,
+							},
+							File:   "previous_test.flux",
+							Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.previous(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+							Start: ast.Position{
+								Column: 31,
+								Line:   32,
+							},
+						},
+					},
+					Call: &ast.CallExpression{
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 41,
+										Line:   35,
+									},
+									File:   "previous_test.flux",
+									Source: "columns: [\"_start\", \"_stop\"]",
+									Start: ast.Position{
+										Column: 13,
+										Line:   35,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 41,
+											Line:   35,
+										},
+										File:   "previous_test.flux",
+										Source: "columns: [\"_start\", \"_stop\"]",
+										Start: ast.Position{
+											Column: 13,
+											Line:   35,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 20,
+												Line:   35,
+											},
+											File:   "previous_test.flux",
+											Source: "columns",
+											Start: ast.Position{
+												Column: 13,
+												Line:   35,
+											},
+										},
+									},
+									Name: "columns",
+								},
+								Separator: nil,
+								Value: &ast.ArrayExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   35,
+											},
+											File:   "previous_test.flux",
+											Source: "[\"_start\", \"_stop\"]",
+											Start: ast.Position{
+												Column: 22,
+												Line:   35,
+											},
+										},
+									},
+									Elements: []ast.Expression{&ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 31,
+													Line:   35,
+												},
+												File:   "previous_test.flux",
+												Source: "\"_start\"",
+												Start: ast.Position{
+													Column: 23,
+													Line:   35,
+												},
+											},
+										},
+										Value: "_start",
+									}, &ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 40,
+													Line:   35,
+												},
+												File:   "previous_test.flux",
+												Source: "\"_stop\"",
+												Start: ast.Position{
+													Column: 33,
+													Line:   35,
+												},
+											},
+										},
+										Value: "_stop",
+									}},
+									Lbrack: nil,
+									Rbrack: nil,
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						}},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 42,
+									Line:   35,
+								},
+								File:   "previous_test.flux",
+								Source: "drop(columns: [\"_start\", \"_stop\"])",
+								Start: ast.Position{
+									Column: 8,
+									Line:   35,
+								},
+							},
+						},
+						Callee: &ast.Identifier{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 12,
+										Line:   35,
+									},
+									File:   "previous_test.flux",
+									Source: "drop",
+									Start: ast.Position{
+										Column: 8,
+										Line:   35,
+									},
+								},
+							},
+							Name: "drop",
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+				},
+				Lparen: nil,
+				Params: []*ast.Property{&ast.Property{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   32,
+							},
+							File:   "previous_test.flux",
+							Source: "table=<-",
+							Start: ast.Position{
+								Column: 18,
+								Line:   32,
+							},
+						},
+					},
+					Comma: nil,
+					Key: &ast.Identifier{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 23,
+									Line:   32,
+								},
+								File:   "previous_test.flux",
+								Source: "table",
+								Start: ast.Position{
+									Column: 18,
+									Line:   32,
+								},
+							},
+						},
+						Name: "table",
+					},
+					Separator: nil,
+					Value: &ast.PipeLiteral{BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   32,
+							},
+							File:   "previous_test.flux",
+							Source: "<-",
+							Start: ast.Position{
+								Column: 24,
+								Line:   32,
+							},
+						},
+					}},
+				}},
+				Rparan: nil,
+			},
+		}, &ast.TestStatement{
+			Assignment: &ast.VariableAssignment{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 134,
+							Line:   37,
+						},
+						File:   "previous_test.flux",
+						Source: "interpolate_previous = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+						Start: ast.Position{
+							Column: 6,
+							Line:   37,
+						},
+					},
+				},
+				ID: &ast.Identifier{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   37,
+							},
+							File:   "previous_test.flux",
+							Source: "interpolate_previous",
+							Start: ast.Position{
+								Column: 6,
+								Line:   37,
+							},
+						},
+					},
+					Name: "interpolate_previous",
+				},
+				Init: &ast.FunctionExpression{
+					Arrow: nil,
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 134,
+								Line:   37,
+							},
+							File:   "previous_test.flux",
+							Source: "() => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+							Start: ast.Position{
+								Column: 29,
+								Line:   37,
+							},
+						},
+					},
+					Body: &ast.ParenExpression{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 134,
+									Line:   37,
+								},
+								File:   "previous_test.flux",
+								Source: "({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+								Start: ast.Position{
+									Column: 35,
+									Line:   37,
+								},
+							},
+						},
+						Expression: &ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 133,
+										Line:   37,
+									},
+									File:   "previous_test.flux",
+									Source: "{input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn}",
+									Start: ast.Position{
+										Column: 36,
+										Line:   37,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 76,
+											Line:   37,
+										},
+										File:   "previous_test.flux",
+										Source: "input: testing.loadStorage(csv: inData)",
+										Start: ast.Position{
+											Column: 37,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 42,
+												Line:   37,
+											},
+											File:   "previous_test.flux",
+											Source: "input",
+											Start: ast.Position{
+												Column: 37,
+												Line:   37,
+											},
+										},
+									},
+									Name: "input",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 75,
+													Line:   37,
+												},
+												File:   "previous_test.flux",
+												Source: "csv: inData",
+												Start: ast.Position{
+													Column: 64,
+													Line:   37,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 75,
+														Line:   37,
+													},
+													File:   "previous_test.flux",
+													Source: "csv: inData",
+													Start: ast.Position{
+														Column: 64,
+														Line:   37,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 67,
+															Line:   37,
+														},
+														File:   "previous_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 64,
+															Line:   37,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 75,
+															Line:   37,
+														},
+														File:   "previous_test.flux",
+														Source: "inData",
+														Start: ast.Position{
+															Column: 69,
+															Line:   37,
+														},
+													},
+												},
+												Name: "inData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 76,
+												Line:   37,
+											},
+											File:   "previous_test.flux",
+											Source: "testing.loadStorage(csv: inData)",
+											Start: ast.Position{
+												Column: 44,
+												Line:   37,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 63,
+													Line:   37,
+												},
+												File:   "previous_test.flux",
+												Source: "testing.loadStorage",
+												Start: ast.Position{
+													Column: 44,
+													Line:   37,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 51,
+														Line:   37,
+													},
+													File:   "previous_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 44,
+														Line:   37,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 63,
+														Line:   37,
+													},
+													File:   "previous_test.flux",
+													Source: "loadStorage",
+													Start: ast.Position{
+														Column: 52,
+														Line:   37,
+													},
+												},
+											},
+											Name: "loadStorage",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 113,
+											Line:   37,
+										},
+										File:   "previous_test.flux",
+										Source: "want: testing.loadMem(csv: outData)",
+										Start: ast.Position{
+											Column: 78,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 82,
+												Line:   37,
+											},
+											File:   "previous_test.flux",
+											Source: "want",
+											Start: ast.Position{
+												Column: 78,
+												Line:   37,
+											},
+										},
+									},
+									Name: "want",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 112,
+													Line:   37,
+												},
+												File:   "previous_test.flux",
+												Source: "csv: outData",
+												Start: ast.Position{
+													Column: 100,
+													Line:   37,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 112,
+														Line:   37,
+													},
+													File:   "previous_test.flux",
+													Source: "csv: outData",
+													Start: ast.Position{
+														Column: 100,
+														Line:   37,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 103,
+															Line:   37,
+														},
+														File:   "previous_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 100,
+															Line:   37,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 112,
+															Line:   37,
+														},
+														File:   "previous_test.flux",
+														Source: "outData",
+														Start: ast.Position{
+															Column: 105,
+															Line:   37,
+														},
+													},
+												},
+												Name: "outData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 113,
+												Line:   37,
+											},
+											File:   "previous_test.flux",
+											Source: "testing.loadMem(csv: outData)",
+											Start: ast.Position{
+												Column: 84,
+												Line:   37,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 99,
+													Line:   37,
+												},
+												File:   "previous_test.flux",
+												Source: "testing.loadMem",
+												Start: ast.Position{
+													Column: 84,
+													Line:   37,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 91,
+														Line:   37,
+													},
+													File:   "previous_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 84,
+														Line:   37,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 99,
+														Line:   37,
+													},
+													File:   "previous_test.flux",
+													Source: "loadMem",
+													Start: ast.Position{
+														Column: 92,
+														Line:   37,
+													},
+												},
+											},
+											Name: "loadMem",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 132,
+											Line:   37,
+										},
+										File:   "previous_test.flux",
+										Source: "fn: interpolateFn",
+										Start: ast.Position{
+											Column: 115,
+											Line:   37,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 117,
+												Line:   37,
+											},
+											File:   "previous_test.flux",
+											Source: "fn",
+											Start: ast.Position{
+												Column: 115,
+												Line:   37,
+											},
+										},
+									},
+									Name: "fn",
+								},
+								Separator: nil,
+								Value: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 132,
+												Line:   37,
+											},
+											File:   "previous_test.flux",
+											Source: "interpolateFn",
+											Start: ast.Position{
+												Column: 119,
+												Line:   37,
+											},
+										},
+									},
+									Name: "interpolateFn",
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+					Lparen: nil,
+					Params: []*ast.Property{},
+					Rparan: nil,
+				},
+			},
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 134,
+						Line:   37,
+					},
+					File:   "previous_test.flux",
+					Source: "test interpolate_previous = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+					Start: ast.Position{
+						Column: 1,
+						Line:   37,
+					},
+				},
+			},
+		}},
+		Eof: nil,
+		Imports: []*ast.ImportDeclaration{&ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 17,
+						Line:   4,
+					},
+					File:   "previous_test.flux",
+					Source: "import \"testing\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   4,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 17,
+							Line:   4,
+						},
+						File:   "previous_test.flux",
+						Source: "\"testing\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   4,
+						},
+					},
+				},
+				Value: "testing",
+			},
+		}, &ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 21,
+						Line:   5,
+					},
+					File:   "previous_test.flux",
+					Source: "import \"interpolate\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   5,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 21,
+							Line:   5,
+						},
+						File:   "previous_test.flux",
+						Source: "\"interpolate\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   5,
+						},
+					},
+				},
+				Value: "interpolate",
+			},
+		}},
+		Metadata: "parser-type=rust",
+		Name:     "previous_test.flux",
+		Package: &ast.PackageClause{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 25,
+						Line:   1,
+					},
+					File:   "previous_test.flux",
+					Source: "package interpolate_test",
+					Start: ast.Position{
+						Column: 1,
+						Line:   1,
+					},
+				},
+			},
+			Name: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 25,
+							Line:   1,
+						},
+						File:   "previous_test.flux",
+						Source: "interpolate_test",
+						Start: ast.Position{
+							Column: 9,
+							Line:   1,
+						},
+					},
+				},
+				Name: "interpolate_test",
+			},
+		},
+	}, &ast.File{
+		BaseNode: ast.BaseNode{
+			Comments: nil,
+			Errors:   nil,
+			Loc: &ast.SourceLocation{
+				End: ast.Position{
+					Column: 132,
+					Line:   38,
+				},
+				File:   "spline_test.flux",
+				Source: "package interpolate_test\n\n\nimport \"testing\"\nimport \"interpolate\"\n\ninData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n\"\noutData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,1.375\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:03:00Z,_m,FF,1.375\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:01:00Z,_m,QQ,2\n,,1,2014-01-01T01:02:00Z,_m,QQ,3\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n\"\ninterpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.spline(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])\n\ntest interpolate_spline = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+				Start: ast.Position{
+					Column: 1,
+					Line:   1,
+				},
+			},
+		},
+		Body: []ast.Statement{&ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   17,
+					},
+					File:   "spline_test.flux",
+					Source: "inData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   7,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 7,
+							Line:   7,
+						},
+						File:   "spline_test.flux",
+						Source: "inData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   7,
+						},
+					},
+				},
+				Name: "inData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   17,
+						},
+						File:   "spline_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n\"",
+						Start: ast.Position{
+							Column: 10,
+							Line:   7,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 2,
+						Line:   32,
+					},
+					File:   "spline_test.flux",
+					Source: "outData = \"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,1.375\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:03:00Z,_m,FF,1.375\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:01:00Z,_m,QQ,2\n,,1,2014-01-01T01:02:00Z,_m,QQ,3\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   18,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 8,
+							Line:   18,
+						},
+						File:   "spline_test.flux",
+						Source: "outData",
+						Start: ast.Position{
+							Column: 1,
+							Line:   18,
+						},
+					},
+				},
+				Name: "outData",
+			},
+			Init: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 2,
+							Line:   32,
+						},
+						File:   "spline_test.flux",
+						Source: "\"\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,1.375\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:03:00Z,_m,FF,1.375\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:01:00Z,_m,QQ,2\n,,1,2014-01-01T01:02:00Z,_m,QQ,3\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n\"",
+						Start: ast.Position{
+							Column: 11,
+							Line:   18,
+						},
+					},
+				},
+				Value: "\n#datatype,string,long,dateTime:RFC3339,string,string,double\n#group,false,false,false,true,true,false\n#default,_result,,,,,\n,result,table,_time,_measurement,_field,_value\n,,0,2014-01-01T01:00:00Z,_m,FF,0\n,,0,2014-01-01T01:01:00Z,_m,FF,1.375\n,,0,2014-01-01T01:02:00Z,_m,FF,2\n,,0,2014-01-01T01:03:00Z,_m,FF,1.375\n,,0,2014-01-01T01:04:00Z,_m,FF,0\n,,1,2014-01-01T01:00:00Z,_m,QQ,1\n,,1,2014-01-01T01:01:00Z,_m,QQ,2\n,,1,2014-01-01T01:02:00Z,_m,QQ,3\n,,1,2014-01-01T01:03:00Z,_m,QQ,4\n",
+			},
+		}, &ast.VariableAssignment{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 42,
+						Line:   36,
+					},
+					File:   "spline_test.flux",
+					Source: "interpolateFn = (table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.spline(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+					Start: ast.Position{
+						Column: 1,
+						Line:   33,
+					},
+				},
+			},
+			ID: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 14,
+							Line:   33,
+						},
+						File:   "spline_test.flux",
+						Source: "interpolateFn",
+						Start: ast.Position{
+							Column: 1,
+							Line:   33,
+						},
+					},
+				},
+				Name: "interpolateFn",
+			},
+			Init: &ast.FunctionExpression{
+				Arrow: nil,
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 42,
+							Line:   36,
+						},
+						File:   "spline_test.flux",
+						Source: "(table=<-) => table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.spline(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+						Start: ast.Position{
+							Column: 17,
+							Line:   33,
+						},
+					},
+				},
+				Body: &ast.PipeExpression{
+					Argument: &ast.PipeExpression{
+						Argument: &ast.PipeExpression{
+							Argument: &ast.Identifier{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 36,
+											Line:   33,
+										},
+										File:   "spline_test.flux",
+										Source: "table",
+										Start: ast.Position{
+											Column: 31,
+											Line:   33,
+										},
+									},
+								},
+								Name: "table",
+							},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 70,
+										Line:   34,
+									},
+									File:   "spline_test.flux",
+									Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+									Start: ast.Position{
+										Column: 31,
+										Line:   33,
+									},
+								},
+							},
+							Call: &ast.CallExpression{
+								Arguments: []ast.Expression{&ast.ObjectExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 69,
+												Line:   34,
+											},
+											File:   "spline_test.flux",
+											Source: "start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z",
+											Start: ast.Position{
+												Column: 14,
+												Line:   34,
+											},
+										},
+									},
+									Lbrace: nil,
+									Properties: []*ast.Property{&ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 41,
+													Line:   34,
+												},
+												File:   "spline_test.flux",
+												Source: "start: 2014-01-01T01:00:00Z",
+												Start: ast.Position{
+													Column: 14,
+													Line:   34,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 19,
+														Line:   34,
+													},
+													File:   "spline_test.flux",
+													Source: "start",
+													Start: ast.Position{
+														Column: 14,
+														Line:   34,
+													},
+												},
+											},
+											Name: "start",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 41,
+														Line:   34,
+													},
+													File:   "spline_test.flux",
+													Source: "2014-01-01T01:00:00Z",
+													Start: ast.Position{
+														Column: 21,
+														Line:   34,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T01:00:00Z"),
+										},
+									}, &ast.Property{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 69,
+													Line:   34,
+												},
+												File:   "spline_test.flux",
+												Source: "stop: 2014-01-01T02:00:00Z",
+												Start: ast.Position{
+													Column: 43,
+													Line:   34,
+												},
+											},
+										},
+										Comma: nil,
+										Key: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 47,
+														Line:   34,
+													},
+													File:   "spline_test.flux",
+													Source: "stop",
+													Start: ast.Position{
+														Column: 43,
+														Line:   34,
+													},
+												},
+											},
+											Name: "stop",
+										},
+										Separator: nil,
+										Value: &ast.DateTimeLiteral{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 69,
+														Line:   34,
+													},
+													File:   "spline_test.flux",
+													Source: "2014-01-01T02:00:00Z",
+													Start: ast.Position{
+														Column: 49,
+														Line:   34,
+													},
+												},
+											},
+											Value: parser.MustParseTime("2014-01-01T02:00:00Z"),
+										},
+									}},
+									Rbrace: nil,
+									With:   nil,
+								}},
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 70,
+											Line:   34,
+										},
+										File:   "spline_test.flux",
+										Source: "range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)",
+										Start: ast.Position{
+											Column: 8,
+											Line:   34,
+										},
+									},
+								},
+								Callee: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 13,
+												Line:   34,
+											},
+											File:   "spline_test.flux",
+											Source: "range",
+											Start: ast.Position{
+												Column: 8,
+												Line:   34,
+											},
+										},
+									},
+									Name: "range",
+								},
+								Lparen: nil,
+								Rparen: nil,
+							},
+						},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 37,
+									Line:   35,
+								},
+								File:   "spline_test.flux",
+								Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.spline(every: 1m)",
+								Start: ast.Position{
+									Column: 31,
+									Line:   33,
+								},
+							},
+						},
+						Call: &ast.CallExpression{
+							Arguments: []ast.Expression{&ast.ObjectExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 36,
+											Line:   35,
+										},
+										File:   "spline_test.flux",
+										Source: "every: 1m",
+										Start: ast.Position{
+											Column: 27,
+											Line:   35,
+										},
+									},
+								},
+								Lbrace: nil,
+								Properties: []*ast.Property{&ast.Property{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 36,
+												Line:   35,
+											},
+											File:   "spline_test.flux",
+											Source: "every: 1m",
+											Start: ast.Position{
+												Column: 27,
+												Line:   35,
+											},
+										},
+									},
+									Comma: nil,
+									Key: &ast.Identifier{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 32,
+													Line:   35,
+												},
+												File:   "spline_test.flux",
+												Source: "every",
+												Start: ast.Position{
+													Column: 27,
+													Line:   35,
+												},
+											},
+										},
+										Name: "every",
+									},
+									Separator: nil,
+									Value: &ast.DurationLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 36,
+													Line:   35,
+												},
+												File:   "spline_test.flux",
+												Source: "1m",
+												Start: ast.Position{
+													Column: 34,
+													Line:   35,
+												},
+											},
+										},
+										Values: []ast.Duration{ast.Duration{
+											Magnitude: int64(1),
+											Unit:      "m",
+										}},
+									},
+								}},
+								Rbrace: nil,
+								With:   nil,
+							}},
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 37,
+										Line:   35,
+									},
+									File:   "spline_test.flux",
+									Source: "interpolate.spline(every: 1m)",
+									Start: ast.Position{
+										Column: 8,
+										Line:   35,
+									},
+								},
+							},
+							Callee: &ast.MemberExpression{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 26,
+											Line:   35,
+										},
+										File:   "spline_test.flux",
+										Source: "interpolate.spline",
+										Start: ast.Position{
+											Column: 8,
+											Line:   35,
+										},
+									},
+								},
+								Lbrack: nil,
+								Object: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 19,
+												Line:   35,
+											},
+											File:   "spline_test.flux",
+											Source: "interpolate",
+											Start: ast.Position{
+												Column: 8,
+												Line:   35,
+											},
+										},
+									},
+									Name: "interpolate",
+								},
+								Property: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 26,
+												Line:   35,
+											},
+											File:   "spline_test.flux",
+											Source: "spline",
+											Start: ast.Position{
+												Column: 20,
+												Line:   35,
+											},
+										},
+									},
+									Name: "spline",
+								},
+								Rbrack: nil,
+							},
+							Lparen: nil,
+							Rparen: nil,
+						},
+					},
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 42,
+								Line:   36,
+							},
+							File:   "spline_test.flux",
+							Source: "table\n    |> range(start: 2014-01-01T01:00:00Z, stop: 2014-01-01T02:00:00Z)\n    |> interpolate.spline(every: 1m)\n    |> drop(columns: [\"_start\", \"_stop\"])",
+							Start: ast.Position{
+								Column: 31,
+								Line:   33,
+							},
+						},
+					},
+					Call: &ast.CallExpression{
+						Arguments: []ast.Expression{&ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 41,
+										Line:   36,
+									},
+									File:   "spline_test.flux",
+									Source: "columns: [\"_start\", \"_stop\"]",
+									Start: ast.Position{
+										Column: 13,
+										Line:   36,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 41,
+											Line:   36,
+										},
+										File:   "spline_test.flux",
+										Source: "columns: [\"_start\", \"_stop\"]",
+										Start: ast.Position{
+											Column: 13,
+											Line:   36,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 20,
+												Line:   36,
+											},
+											File:   "spline_test.flux",
+											Source: "columns",
+											Start: ast.Position{
+												Column: 13,
+												Line:   36,
+											},
+										},
+									},
+									Name: "columns",
+								},
+								Separator: nil,
+								Value: &ast.ArrayExpression{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 41,
+												Line:   36,
+											},
+											File:   "spline_test.flux",
+											Source: "[\"_start\", \"_stop\"]",
+											Start: ast.Position{
+												Column: 22,
+												Line:   36,
+											},
+										},
+									},
+									Elements: []ast.Expression{&ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 31,
+													Line:   36,
+												},
+												File:   "spline_test.flux",
+												Source: "\"_start\"",
+												Start: ast.Position{
+													Column: 23,
+													Line:   36,
+												},
+											},
+										},
+										Value: "_start",
+									}, &ast.StringLiteral{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 40,
+													Line:   36,
+												},
+												File:   "spline_test.flux",
+												Source: "\"_stop\"",
+												Start: ast.Position{
+													Column: 33,
+													Line:   36,
+												},
+											},
+										},
+										Value: "_stop",
+									}},
+									Lbrack: nil,
+									Rbrack: nil,
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						}},
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 42,
+									Line:   36,
+								},
+								File:   "spline_test.flux",
+								Source: "drop(columns: [\"_start\", \"_stop\"])",
+								Start: ast.Position{
+									Column: 8,
+									Line:   36,
+								},
+							},
+						},
+						Callee: &ast.Identifier{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 12,
+										Line:   36,
+									},
+									File:   "spline_test.flux",
+									Source: "drop",
+									Start: ast.Position{
+										Column: 8,
+										Line:   36,
+									},
+								},
+							},
+							Name: "drop",
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+				},
+				Lparen: nil,
+				Params: []*ast.Property{&ast.Property{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   33,
+							},
+							File:   "spline_test.flux",
+							Source: "table=<-",
+							Start: ast.Position{
+								Column: 18,
+								Line:   33,
+							},
+						},
+					},
+					Comma: nil,
+					Key: &ast.Identifier{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 23,
+									Line:   33,
+								},
+								File:   "spline_test.flux",
+								Source: "table",
+								Start: ast.Position{
+									Column: 18,
+									Line:   33,
+								},
+							},
+						},
+						Name: "table",
+					},
+					Separator: nil,
+					Value: &ast.PipeLiteral{BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 26,
+								Line:   33,
+							},
+							File:   "spline_test.flux",
+							Source: "<-",
+							Start: ast.Position{
+								Column: 24,
+								Line:   33,
+							},
+						},
+					}},
+				}},
+				Rparan: nil,
+			},
+		}, &ast.TestStatement{
+			Assignment: &ast.VariableAssignment{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 132,
+							Line:   38,
+						},
+						File:   "spline_test.flux",
+						Source: "interpolate_spline = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+						Start: ast.Position{
+							Column: 6,
+							Line:   38,
+						},
+					},
+				},
+				ID: &ast.Identifier{
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 24,
+								Line:   38,
+							},
+							File:   "spline_test.flux",
+							Source: "interpolate_spline",
+							Start: ast.Position{
+								Column: 6,
+								Line:   38,
+							},
+						},
+					},
+					Name: "interpolate_spline",
+				},
+				Init: &ast.FunctionExpression{
+					Arrow: nil,
+					BaseNode: ast.BaseNode{
+						Comments: nil,
+						Errors:   nil,
+						Loc: &ast.SourceLocation{
+							End: ast.Position{
+								Column: 132,
+								Line:   38,
+							},
+							File:   "spline_test.flux",
+							Source: "() => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+							Start: ast.Position{
+								Column: 27,
+								Line:   38,
+							},
+						},
+					},
+					Body: &ast.ParenExpression{
+						BaseNode: ast.BaseNode{
+							Comments: nil,
+							Errors:   nil,
+							Loc: &ast.SourceLocation{
+								End: ast.Position{
+									Column: 132,
+									Line:   38,
+								},
+								File:   "spline_test.flux",
+								Source: "({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+								Start: ast.Position{
+									Column: 33,
+									Line:   38,
+								},
+							},
+						},
+						Expression: &ast.ObjectExpression{
+							BaseNode: ast.BaseNode{
+								Comments: nil,
+								Errors:   nil,
+								Loc: &ast.SourceLocation{
+									End: ast.Position{
+										Column: 131,
+										Line:   38,
+									},
+									File:   "spline_test.flux",
+									Source: "{input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn}",
+									Start: ast.Position{
+										Column: 34,
+										Line:   38,
+									},
+								},
+							},
+							Lbrace: nil,
+							Properties: []*ast.Property{&ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 74,
+											Line:   38,
+										},
+										File:   "spline_test.flux",
+										Source: "input: testing.loadStorage(csv: inData)",
+										Start: ast.Position{
+											Column: 35,
+											Line:   38,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 40,
+												Line:   38,
+											},
+											File:   "spline_test.flux",
+											Source: "input",
+											Start: ast.Position{
+												Column: 35,
+												Line:   38,
+											},
+										},
+									},
+									Name: "input",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 73,
+													Line:   38,
+												},
+												File:   "spline_test.flux",
+												Source: "csv: inData",
+												Start: ast.Position{
+													Column: 62,
+													Line:   38,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 73,
+														Line:   38,
+													},
+													File:   "spline_test.flux",
+													Source: "csv: inData",
+													Start: ast.Position{
+														Column: 62,
+														Line:   38,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 65,
+															Line:   38,
+														},
+														File:   "spline_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 62,
+															Line:   38,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 73,
+															Line:   38,
+														},
+														File:   "spline_test.flux",
+														Source: "inData",
+														Start: ast.Position{
+															Column: 67,
+															Line:   38,
+														},
+													},
+												},
+												Name: "inData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 74,
+												Line:   38,
+											},
+											File:   "spline_test.flux",
+											Source: "testing.loadStorage(csv: inData)",
+											Start: ast.Position{
+												Column: 42,
+												Line:   38,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 61,
+													Line:   38,
+												},
+												File:   "spline_test.flux",
+												Source: "testing.loadStorage",
+												Start: ast.Position{
+													Column: 42,
+													Line:   38,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 49,
+														Line:   38,
+													},
+													File:   "spline_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 42,
+														Line:   38,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 61,
+														Line:   38,
+													},
+													File:   "spline_test.flux",
+													Source: "loadStorage",
+													Start: ast.Position{
+														Column: 50,
+														Line:   38,
+													},
+												},
+											},
+											Name: "loadStorage",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 111,
+											Line:   38,
+										},
+										File:   "spline_test.flux",
+										Source: "want: testing.loadMem(csv: outData)",
+										Start: ast.Position{
+											Column: 76,
+											Line:   38,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 80,
+												Line:   38,
+											},
+											File:   "spline_test.flux",
+											Source: "want",
+											Start: ast.Position{
+												Column: 76,
+												Line:   38,
+											},
+										},
+									},
+									Name: "want",
+								},
+								Separator: nil,
+								Value: &ast.CallExpression{
+									Arguments: []ast.Expression{&ast.ObjectExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 110,
+													Line:   38,
+												},
+												File:   "spline_test.flux",
+												Source: "csv: outData",
+												Start: ast.Position{
+													Column: 98,
+													Line:   38,
+												},
+											},
+										},
+										Lbrace: nil,
+										Properties: []*ast.Property{&ast.Property{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 110,
+														Line:   38,
+													},
+													File:   "spline_test.flux",
+													Source: "csv: outData",
+													Start: ast.Position{
+														Column: 98,
+														Line:   38,
+													},
+												},
+											},
+											Comma: nil,
+											Key: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 101,
+															Line:   38,
+														},
+														File:   "spline_test.flux",
+														Source: "csv",
+														Start: ast.Position{
+															Column: 98,
+															Line:   38,
+														},
+													},
+												},
+												Name: "csv",
+											},
+											Separator: nil,
+											Value: &ast.Identifier{
+												BaseNode: ast.BaseNode{
+													Comments: nil,
+													Errors:   nil,
+													Loc: &ast.SourceLocation{
+														End: ast.Position{
+															Column: 110,
+															Line:   38,
+														},
+														File:   "spline_test.flux",
+														Source: "outData",
+														Start: ast.Position{
+															Column: 103,
+															Line:   38,
+														},
+													},
+												},
+												Name: "outData",
+											},
+										}},
+										Rbrace: nil,
+										With:   nil,
+									}},
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 111,
+												Line:   38,
+											},
+											File:   "spline_test.flux",
+											Source: "testing.loadMem(csv: outData)",
+											Start: ast.Position{
+												Column: 82,
+												Line:   38,
+											},
+										},
+									},
+									Callee: &ast.MemberExpression{
+										BaseNode: ast.BaseNode{
+											Comments: nil,
+											Errors:   nil,
+											Loc: &ast.SourceLocation{
+												End: ast.Position{
+													Column: 97,
+													Line:   38,
+												},
+												File:   "spline_test.flux",
+												Source: "testing.loadMem",
+												Start: ast.Position{
+													Column: 82,
+													Line:   38,
+												},
+											},
+										},
+										Lbrack: nil,
+										Object: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 89,
+														Line:   38,
+													},
+													File:   "spline_test.flux",
+													Source: "testing",
+													Start: ast.Position{
+														Column: 82,
+														Line:   38,
+													},
+												},
+											},
+											Name: "testing",
+										},
+										Property: &ast.Identifier{
+											BaseNode: ast.BaseNode{
+												Comments: nil,
+												Errors:   nil,
+												Loc: &ast.SourceLocation{
+													End: ast.Position{
+														Column: 97,
+														Line:   38,
+													},
+													File:   "spline_test.flux",
+													Source: "loadMem",
+													Start: ast.Position{
+														Column: 90,
+														Line:   38,
+													},
+												},
+											},
+											Name: "loadMem",
+										},
+										Rbrack: nil,
+									},
+									Lparen: nil,
+									Rparen: nil,
+								},
+							}, &ast.Property{
+								BaseNode: ast.BaseNode{
+									Comments: nil,
+									Errors:   nil,
+									Loc: &ast.SourceLocation{
+										End: ast.Position{
+											Column: 130,
+											Line:   38,
+										},
+										File:   "spline_test.flux",
+										Source: "fn: interpolateFn",
+										Start: ast.Position{
+											Column: 113,
+											Line:   38,
+										},
+									},
+								},
+								Comma: nil,
+								Key: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 115,
+												Line:   38,
+											},
+											File:   "spline_test.flux",
+											Source: "fn",
+											Start: ast.Position{
+												Column: 113,
+												Line:   38,
+											},
+										},
+									},
+									Name: "fn",
+								},
+								Separator: nil,
+								Value: &ast.Identifier{
+									BaseNode: ast.BaseNode{
+										Comments: nil,
+										Errors:   nil,
+										Loc: &ast.SourceLocation{
+											End: ast.Position{
+												Column: 130,
+												Line:   38,
+											},
+											File:   "spline_test.flux",
+											Source: "interpolateFn",
+											Start: ast.Position{
+												Column: 117,
+												Line:   38,
+											},
+										},
+									},
+									Name: "interpolateFn",
+								},
+							}},
+							Rbrace: nil,
+							With:   nil,
+						},
+						Lparen: nil,
+						Rparen: nil,
+					},
+					Lparen: nil,
+					Params: []*ast.Property{},
+					Rparan: nil,
+				},
+			},
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 132,
+						Line:   38,
+					},
+					File:   "spline_test.flux",
+					Source: "test interpolate_spline = () => ({input: testing.loadStorage(csv: inData), want: testing.loadMem(csv: outData), fn: interpolateFn})",
+					Start: ast.Position{
+						Column: 1,
+						Line:   38,
+					},
+				},
+			},
+		}},
+		Eof: nil,
+		Imports: []*ast.ImportDeclaration{&ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 17,
+						Line:   4,
+					},
+					File:   "spline_test.flux",
+					Source: "import \"testing\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   4,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 17,
+							Line:   4,
+						},
+						File:   "spline_test.flux",
+						Source: "\"testing\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   4,
+						},
+					},
+				},
+				Value: "testing",
+			},
+		}, &ast.ImportDeclaration{
+			As: nil,
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 21,
+						Line:   5,
+					},
+					File:   "spline_test.flux",
+					Source: "import \"interpolate\"",
+					Start: ast.Position{
+						Column: 1,
+						Line:   5,
+					},
+				},
+			},
+			Path: &ast.StringLiteral{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 21,
+							Line:   5,
+						},
+						File:   "spline_test.flux",
+						Source: "\"interpolate\"",
+						Start: ast.Position{
+							Column: 8,
+							Line:   5,
+						},
+					},
+				},
+				Value: "interpolate",
+			},
+		}},
+		Metadata: "parser-type=rust",
+		Name:     "spline_test.flux",
+		Package: &ast.PackageClause{
+			BaseNode: ast.BaseNode{
+				Comments: nil,
+				Errors:   nil,
+				Loc: &ast.SourceLocation{
+					End: ast.Position{
+						Column: 25,
+						Line:   1,
+					},
+					File:   "spline_test.flux",
+					Source: "package interpolate_test",
+					Start: ast.Position{
+						Column: 1,
+						Line:   1,
+					},
+				},
+			},
+			Name: &ast.Identifier{
+				BaseNode: ast.BaseNode{
+					Comments: nil,
+					Errors:   nil,
+					Loc: &ast.SourceLocation{
+						End: ast.Position{
+							Column: 25,
+							Line:   1,
+						},
+						File:   "spline_test.flux",
+						Source: "interpolate_test",
+						Start: ast.Position{
+							Column: 9,
+							Line:   1,
+						},
+					},
+				},
+				Name: "interpolate_test",
+			},
+		},
 	}},
 	Package: "interpolate_test",
 	Path:    "interpolate",
@@ -0,0 +1,492 @@
+package interpolate
+
+import (
+	"math"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	LinearInterpolateKind   = "linearInterpolateKind"
+	PreviousInterpolateKind = "previousInterpolateKind"
+	NextInterpolateKind     = "nextInterpolateKind"
+	NearestInterpolateKind  = "nearestInterpolateKind"
+	SplineInterpolateKind   = "splineInterpolateKind"
+)
+
+// The interpolation methods. Each method is a function of the interpolate package.
+const (
+	MethodLinear   = "linear"
+	MethodPrevious = "previous"
+	MethodNext     = "next"
+	MethodNearest  = "nearest"
+	MethodSpline   = "spline"
+)
+
+var methodKinds = map[string]flux.OperationKind{
+	MethodLinear:   LinearInterpolateKind,
+	MethodPrevious: PreviousInterpolateKind,
+	MethodNext:     NextInterpolateKind,
+	MethodNearest:  NearestInterpolateKind,
+	MethodSpline:   SplineInterpolateKind,
+}
+
+type InterpolateOpSpec struct {
+	Method string        `json:"method"`
+	Every  flux.Duration `json:"every"`
+	Column string        `json:"column"`
+	MaxGap flux.Duration `json:"maxGap"`
+}
+
+func init() {
+	for method, kind := range methodKinds {
+		method, kind := method, kind
+		runtime.RegisterPackageValue("interpolate", method,
+			flux.MustValue(flux.FunctionValue(method,
+				func(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+					return createInterpolateOpSpec(method, args, a)
+				},
+				runtime.MustLookupBuiltinType("interpolate", method),
+			)),
+		)
+		flux.RegisterOpSpec(kind,
+			func() flux.OperationSpec {
+				return &InterpolateOpSpec{Method: method}
+			},
+		)
+		plan.RegisterProcedureSpec(
+			plan.ProcedureKind(kind),
+			newInterpolateProcedure,
+			kind,
+		)
+		execute.RegisterTransformation(
+			plan.ProcedureKind(kind),
+			createInterpolateTransformation,
+		)
+	}
+}
+
+func createInterpolateOpSpec(method string, args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	every, err := args.GetRequiredDuration("every")
+	if err != nil {
+		return nil, err
+	}
+	if !every.IsPositive() {
+		return nil, errors.New(codes.Invalid, "every must be positive")
+	}
+
+	spec := &InterpolateOpSpec{
+		Method: method,
+		Every:  every,
+		Column: execute.DefaultValueColLabel,
+	}
+
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	}
+	if spec.Column == execute.DefaultTimeColLabel {
+		return nil, errors.Newf(codes.Invalid, "cannot interpolate the %s column", execute.DefaultTimeColLabel)
+	}
+
+	if maxGap, ok, err := args.GetDuration("maxGap"); err != nil {
+		return nil, err
+	} else if ok {
+		if !maxGap.IsPositive() {
+			return nil, errors.New(codes.Invalid, "maxGap must be positive")
+		}
+		spec.MaxGap = maxGap
+	}
+	return spec, nil
+}
+
+func (s *InterpolateOpSpec) Kind() flux.OperationKind {
+	return methodKinds[s.Method]
+}
+
+type InterpolateProcedureSpec struct {
+	plan.DefaultCost
+	Method string        `json:"method"`
+	Every  flux.Duration `json:"every"`
+	Column string        `json:"column"`
+	// MaxGap is the largest duration between two points
+	// that is filled. A zero duration does not limit the gaps.
+	MaxGap flux.Duration `json:"maxGap"`
+}
+
+func newInterpolateProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	var spec *InterpolateOpSpec
+	switch qs := qs.(type) {
+	case *InterpolateOpSpec:
+		spec = qs
+	case *LinearInterpolateOpSpec:
+		return &LinearInterpolateProcedureSpec{
+			Every: qs.Every,
+		}, nil
+	default:
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &InterpolateProcedureSpec{
+		Method: spec.Method,
+		Every:  spec.Every,
+		Column: spec.Column,
+		MaxGap: spec.MaxGap,
+	}, nil
+}
+
+func (s *InterpolateProcedureSpec) Kind() plan.ProcedureKind {
+	return plan.ProcedureKind(methodKinds[s.Method])
+}
+func (s *InterpolateProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *InterpolateProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createInterpolateTransformation(
+	id execute.DatasetID,
+	mode execute.AccumulationMode,
+	spec plan.ProcedureSpec,
+	a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	switch s := spec.(type) {
+	case *InterpolateProcedureSpec:
+		return NewInterpolateMethodTransformation(d, cache, s), d, nil
+	case *LinearInterpolateProcedureSpec:
+		return NewInterpolateTransformation(d, cache, s), d, nil
+	default:
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+}
+
+type interpolateTransformation struct {
+	execute.ExecutionNode
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	spec   InterpolateProcedureSpec
+	window execute.Window
+
+	// floatOnly rejects the value columns that are not floats
+	// as a LinearInterpolateProcedureSpec always has.
+	floatOnly bool
+}
+
+// NewInterpolateMethodTransformation creates a transformation that fills
+// the column of the spec with the interpolation method of the spec.
+func NewInterpolateMethodTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *InterpolateProcedureSpec) *interpolateTransformation {
+	return &interpolateTransformation{
+		d:     d,
+		cache: cache,
+		spec:  *spec,
+		window: execute.Window{
+			Every:  spec.Every,
+			Period: spec.Every,
+		},
+	}
+}
+
+func (t *interpolateTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *interpolateTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	key, columns := tbl.Key(), tbl.Cols()
+
+	for _, c := range columns {
+		if key.HasCol(c.Label) {
+			continue
+		}
+		if c.Label == execute.DefaultTimeColLabel {
+			continue
+		}
+		if c.Label == t.spec.Column {
+			continue
+		}
+		return errors.Newf(codes.FailedPrecondition,
+			"interpolate.%s requires column %q to be in group key", t.spec.Method, c.Label,
+		)
+	}
+
+	b, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition,
+			"duplicate table with key: %v", tbl.Key(),
+		)
+	}
+
+	if err := execute.AddTableCols(tbl, b); err != nil {
+		return err
+	}
+
+	ti := execute.ColIdx("_time", columns)
+	if ti < 0 {
+		return errors.New(codes.FailedPrecondition,
+			"_time column does not exist",
+		)
+	}
+
+	vi := execute.ColIdx(t.spec.Column, columns)
+	if vi < 0 {
+		return errors.Newf(codes.FailedPrecondition,
+			"%s column does not exist", t.spec.Column,
+		)
+	}
+	if key.HasCol(t.spec.Column) {
+		return errors.Newf(codes.FailedPrecondition,
+			"cannot interpolate group key column %q", t.spec.Column,
+		)
+	}
+
+	if ty := columns[vi].Type; t.floatOnly && ty != flux.TFloat {
+		return errors.Newf(codes.FailedPrecondition,
+			"cannot interpolate %v values; expected float values", ty,
+		)
+	}
+
+	switch ty := columns[vi].Type; ty {
+	case flux.TFloat, flux.TInt, flux.TUInt:
+	case flux.TString, flux.TBool, flux.TTime:
+		// Only the methods that copy the values of the points
+		// can fill columns that are not numeric.
+		if t.spec.Method == MethodLinear || t.spec.Method == MethodSpline {
+			return errors.Newf(codes.FailedPrecondition,
+				"interpolate.%s cannot interpolate %v values; expected float, int or uint values", t.spec.Method, ty,
+			)
+		}
+	default:
+		return errors.Newf(codes.FailedPrecondition,
+			"cannot interpolate %v values", ty,
+		)
+	}
+
+	p, err := t.readPoints(tbl, ti, vi)
+	if err != nil {
+		return err
+	}
+
+	// Points that are separated by more than the maximum gap are
+	// interpolated as separate runs of points.
+	n := 0
+	for start := 0; start < p.len(); {
+		end := start + 1
+		for end < p.len() && !t.exceedsMaxGap(p.times[end-1], p.times[end]) {
+			end++
+		}
+
+		fill := t.filler(b, ti, vi, p, start, end)
+		for i := start; i < end; i++ {
+			if err := p.appendPoint(b, ti, vi, p.times[i], i); err != nil {
+				return err
+			}
+			n++
+
+			if i+1 == end {
+				break
+			}
+			xi := int64(t.window.GetEarliestBounds(values.Time(p.times[i])).Stop)
+			for xi < p.times[i+1] {
+				if err := fill(i, xi); err != nil {
+					return err
+				}
+				n++
+
+				xi = int64(execute.Time(xi).Add(t.window.Every))
+			}
+		}
+		start = end
+	}
+	return execute.AppendKeyValuesN(key, b, n)
+}
+
+// readPoints reads the time and value of each row of the table.
+func (t *interpolateTransformation) readPoints(tbl flux.Table, ti, vi int) (*points, error) {
+	p := &points{typ: tbl.Cols()[vi].Type}
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		tc := cr.Times(ti)
+		for i := 0; i < cr.Len(); i++ {
+			if tc.IsNull(i) {
+				return errors.Newf(codes.FailedPrecondition,
+					"null _time found during %s interpolation", t.spec.Method,
+				)
+			}
+			x := tc.Value(i)
+			if l := p.len(); l > 0 {
+				if last := p.times[l-1]; x < last {
+					return errors.Newf(codes.FailedPrecondition,
+						"interpolate.%s requires the _time column to be sorted", t.spec.Method,
+					)
+				} else if x == last && t.spec.Method == MethodSpline {
+					return errors.New(codes.FailedPrecondition,
+						"interpolate.spline requires unique _time values",
+					)
+				}
+			}
+			p.times = append(p.times, x)
+		}
+
+		var valid bool
+		switch p.typ {
+		case flux.TFloat:
+			vs := cr.Floats(vi)
+			valid = vs.NullN() == 0
+			p.floats = append(p.floats, vs.Float64Values()...)
+		case flux.TInt:
+			vs := cr.Ints(vi)
+			valid = vs.NullN() == 0
+			p.ints = append(p.ints, vs.Int64Values()...)
+		case flux.TUInt:
+			vs := cr.UInts(vi)
+			valid = vs.NullN() == 0
+			p.uints = append(p.uints, vs.Uint64Values()...)
+		default:
+			valid = true
+			for i := 0; i < cr.Len() && valid; i++ {
+				v := execute.ValueForRow(cr, i, vi)
+				valid = !v.IsNull()
+				p.values = append(p.values, v)
+			}
+		}
+		if !valid {
+			return errors.Newf(codes.FailedPrecondition,
+				"null %s found during %s interpolation", t.spec.Column, t.spec.Method,
+			)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (t *interpolateTransformation) exceedsMaxGap(x0, xn int64) bool {
+	if t.spec.MaxGap.IsZero() {
+		return false
+	}
+	return int64(execute.Time(x0).Add(t.spec.MaxGap)) < xn
+}
+
+// filler returns a function that appends a row at time x
+// between the points i and i+1 of the run [start, end).
+func (t *interpolateTransformation) filler(b execute.TableBuilder, ti, vi int, p *points, start, end int) func(i int, x int64) error {
+	switch t.spec.Method {
+	case MethodPrevious:
+		return func(i int, x int64) error {
+			return p.appendPoint(b, ti, vi, x, i)
+		}
+	case MethodNext:
+		return func(i int, x int64) error {
+			return p.appendPoint(b, ti, vi, x, i+1)
+		}
+	case MethodNearest:
+		return func(i int, x int64) error {
+			if x-p.times[i] <= p.times[i+1]-x {
+				return p.appendPoint(b, ti, vi, x, i)
+			}
+			return p.appendPoint(b, ti, vi, x, i+1)
+		}
+	case MethodSpline:
+		ys := make([]float64, end-start)
+		for i := range ys {
+			ys[i] = p.value(start + i)
+		}
+		s := newNaturalSpline(p.times[start:end], ys)
+		return func(i int, x int64) error {
+			return p.appendEstimate(b, ti, vi, x, s.at(i-start, x))
+		}
+	default:
+		return func(i int, x int64) error {
+			x0, y0 := p.times[i], p.value(i)
+			xn, yn := p.times[i+1], p.value(i+1)
+			m := (yn - y0) / float64(xn-x0)
+			return p.appendEstimate(b, ti, vi, x, y0+m*float64(x-x0))
+		}
+	}
+}
+
+// points holds the times and values of a table.
+// Only the values slice for the type of the column is used.
+// Values of columns that are not numeric are held in values.
+type points struct {
+	typ    flux.ColType
+	times  []int64
+	floats []float64
+	ints   []int64
+	uints  []uint64
+	values []values.Value
+}
+
+func (p *points) len() int {
+	return len(p.times)
+}
+
+func (p *points) value(i int) float64 {
+	switch p.typ {
+	case flux.TInt:
+		return float64(p.ints[i])
+	case flux.TUInt:
+		return float64(p.uints[i])
+	default:
+		return p.floats[i]
+	}
+}
+
+// appendPoint appends a row at time x with the value of the point i.
+func (p *points) appendPoint(b execute.TableBuilder, ti, vi int, x int64, i int) error {
+	if err := b.AppendTime(ti, execute.Time(x)); err != nil {
+		return err
+	}
+	switch p.typ {
+	case flux.TInt:
+		return b.AppendInt(vi, p.ints[i])
+	case flux.TUInt:
+		return b.AppendUInt(vi, p.uints[i])
+	case flux.TFloat:
+		return b.AppendFloat(vi, p.floats[i])
+	default:
+		return b.AppendValue(vi, p.values[i])
+	}
+}
+
+// appendEstimate appends a row at time x with an estimated value.
+// Estimates of integer columns are rounded to the nearest integer.
+func (p *points) appendEstimate(b execute.TableBuilder, ti, vi int, x int64, v float64) error {
+	if err := b.AppendTime(ti, execute.Time(x)); err != nil {
+		return err
+	}
+	switch p.typ {
+	case flux.TInt:
+		return b.AppendInt(vi, int64(math.Round(v)))
+	case flux.TUInt:
+		// A spline may overshoot below zero between two unsigned values.
+		return b.AppendUInt(vi, uint64(math.Round(math.Max(v, 0))))
+	default:
+		return b.AppendFloat(vi, v)
+	}
+}
+
+func (t *interpolateTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *interpolateTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *interpolateTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
@@ -459,3 +459,430 @@ func TestLinearInterpolate(t *testing.T) {
 		})
 	}
 }
+
+func TestInterpolateMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *interpolate.InterpolateProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "linear ints",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodLinear,
+				Column: "_value",
+				Every:  flux.ConvertDuration(5 * time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(1)},
+					{execute.Time(9), int64(2)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(1)},
+					{execute.Time(5), int64(2)},
+					{execute.Time(9), int64(2)},
+				},
+			}},
+		},
+		{
+			name: "previous",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodPrevious,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(3), 4.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 1.0},
+					{execute.Time(3), 4.0},
+				},
+			}},
+		},
+		{
+			name: "next",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodNext,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(3), 4.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(1), 4.0},
+					{execute.Time(2), 4.0},
+					{execute.Time(3), 4.0},
+				},
+			}},
+		},
+		{
+			name: "nearest",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodNearest,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(3), 4.0},
+					{execute.Time(5), 6.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 4.0},
+					{execute.Time(3), 4.0},
+					// Ties use the earlier point.
+					{execute.Time(4), 4.0},
+					{execute.Time(5), 6.0},
+				},
+			}},
+		},
+		{
+			name: "spline",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodSpline,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(2), 2.0},
+					{execute.Time(4), 0.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(1), 1.375},
+					{execute.Time(2), 2.0},
+					{execute.Time(3), 1.375},
+					{execute.Time(4), 0.0},
+				},
+			}},
+		},
+		{
+			name: "spline two points",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodSpline,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(10), 0.0},
+					{execute.Time(14), 2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(10), 0.0},
+					{execute.Time(11), 0.5},
+					{execute.Time(12), 1.0},
+					{execute.Time(13), 1.5},
+					{execute.Time(14), 2.0},
+				},
+			}},
+		},
+		{
+			name: "max gap",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodLinear,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+				MaxGap: flux.ConvertDuration(2 * time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(2), 2.0},
+					{execute.Time(6), 6.0},
+					{execute.Time(8), 8.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 2.0},
+					{execute.Time(6), 6.0},
+					{execute.Time(7), 7.0},
+					{execute.Time(8), 8.0},
+				},
+			}},
+		},
+		{
+			name: "column",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodPrevious,
+				Column: "state",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "state", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a", int64(1)},
+					{execute.Time(2), "a", int64(3)},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "state", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a", int64(1)},
+					{execute.Time(1), "a", int64(1)},
+					{execute.Time(2), "a", int64(3)},
+				},
+			}},
+		},
+		{
+			name: "uints",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodLinear,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TUInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), uint64(0)},
+					{execute.Time(4), uint64(10)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TUInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), uint64(0)},
+					{execute.Time(1), uint64(3)},
+					{execute.Time(2), uint64(5)},
+					{execute.Time(3), uint64(8)},
+					{execute.Time(4), uint64(10)},
+				},
+			}},
+		},
+		{
+			name: "strings",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodPrevious,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a"},
+					{execute.Time(2), "b"},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a"},
+					{execute.Time(1), "a"},
+					{execute.Time(2), "b"},
+				},
+			}},
+		},
+		{
+			name: "nearest bools",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodNearest,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TBool},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), true},
+					{execute.Time(3), false},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TBool},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), true},
+					{execute.Time(1), true},
+					{execute.Time(2), false},
+					{execute.Time(3), false},
+				},
+			}},
+		},
+		{
+			name: "linear strings",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodLinear,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a"},
+					{execute.Time(2), "b"},
+				},
+			}},
+			wantErr: fmt.Errorf("interpolate.linear cannot interpolate string values; expected float, int or uint values"),
+		},
+		{
+			name: "unsorted",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodNearest,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), 2.0},
+					{execute.Time(0), 0.0},
+				},
+			}},
+			wantErr: fmt.Errorf("interpolate.nearest requires the _time column to be sorted"),
+		},
+		{
+			name: "spline duplicate times",
+			spec: &interpolate.InterpolateProcedureSpec{
+				Method: interpolate.MethodSpline,
+				Column: "_value",
+				Every:  flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(0), 1.0},
+					{execute.Time(2), 2.0},
+				},
+			}},
+			wantErr: fmt.Errorf("interpolate.spline requires unique _time values"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return interpolate.NewInterpolateMethodTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
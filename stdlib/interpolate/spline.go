@@ -0,0 +1,69 @@
+package interpolate
+
+// naturalSpline is a natural cubic spline through a run of points.
+// Its second derivative is zero at the first and last points.
+//
+// The times are scaled to the interval [0, 1] so that the
+// coefficients do not depend on the unit of the times.
+type naturalSpline struct {
+	t0   int64
+	span float64
+	xs   []float64
+	ys   []float64
+	// ms are the second derivatives of the spline at the points.
+	ms []float64
+}
+
+// newNaturalSpline computes the spline through the points.
+// The times must be strictly increasing.
+func newNaturalSpline(times []int64, ys []float64) *naturalSpline {
+	n := len(times)
+	s := &naturalSpline{
+		t0: times[0],
+		xs: make([]float64, n),
+		ys: ys,
+		ms: make([]float64, n),
+	}
+	if n < 3 {
+		// The spline through two points is a line.
+		s.span = 1
+		if n == 2 {
+			s.span = float64(times[1] - times[0])
+			s.xs[1] = 1
+		}
+		return s
+	}
+	s.span = float64(times[n-1] - times[0])
+	for i, t := range times {
+		s.xs[i] = float64(t-s.t0) / s.span
+	}
+
+	// Solve the tridiagonal system for the second derivatives
+	// at the interior points with the Thomas algorithm.
+	c := make([]float64, n)
+	d := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		h0, h1 := s.xs[i]-s.xs[i-1], s.xs[i+1]-s.xs[i]
+		a, b := h0, 2*(h0+h1)
+		rhs := 6 * ((ys[i+1]-ys[i])/h1 - (ys[i]-ys[i-1])/h0)
+		w := b - a*c[i-1]
+		c[i] = h1 / w
+		d[i] = (rhs - a*d[i-1]) / w
+	}
+	for i := n - 2; i > 0; i-- {
+		s.ms[i] = d[i] - c[i]*s.ms[i+1]
+	}
+	return s
+}
+
+// at evaluates the spline at time t between the points i and i+1.
+func (s *naturalSpline) at(i int, t int64) float64 {
+	x := float64(t-s.t0) / s.span
+	x0, x1 := s.xs[i], s.xs[i+1]
+	y0, y1 := s.ys[i], s.ys[i+1]
+	m0, m1 := s.ms[i], s.ms[i+1]
+	h := x1 - x0
+	a, b := x1-x, x-x0
+	return m0*a*a*a/(6*h) + m1*b*b*b/(6*h) +
+		(y0/h-m0*h/6)*a + (y1/h-m1*h/6)*b
+}
@@ -0,0 +1,282 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/messages"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// FromKafkaKind is the Kind for the FromKafka Flux function
+	FromKafkaKind = "fromKafka"
+)
+
+type FromKafkaOpSpec struct {
+	Brokers    []string      `json:"brokers"`
+	Topic      string        `json:"topic"`
+	Group      string        `json:"group"`
+	Partition  int           `json:"partition"`
+	Offset     int64         `json:"offset"`     // negative to read from the first available offset
+	StopOffset int64         `json:"stopOffset"` // negative to not stop at an offset
+	Stop       flux.Time     `json:"stop"`
+	Limit      int64         `json:"limit"`
+	Timeout    time.Duration `json:"timeout"`
+	Decoder    string        `json:"decoder"`
+}
+
+func init() {
+	fromKafkaSignature := runtime.MustLookupBuiltinType("kafka", "from")
+	runtime.RegisterPackageValue("kafka", "from", flux.MustValue(flux.FunctionValue(FromKafkaKind, createFromKafkaOpSpec, fromKafkaSignature)))
+	flux.RegisterOpSpec(FromKafkaKind, func() flux.OperationSpec { return &FromKafkaOpSpec{} })
+	plan.RegisterProcedureSpec(FromKafkaKind, newFromKafkaProcedure, FromKafkaKind)
+	execute.RegisterSource(FromKafkaKind, createFromKafkaSource)
+}
+
+// DefaultKafkaReaderFactory makes the kafkaReader of kafka.from and is injectable for testing
+var DefaultKafkaReaderFactory = func(conf kafka.ReaderConfig) KafkaReader {
+	return kafka.NewReader(conf)
+}
+
+// KafkaReader is an interface for what we need from DefaultKafkaReaderFactory
+type KafkaReader interface {
+	FetchMessage(context.Context) (kafka.Message, error)
+	CommitMessages(context.Context, ...kafka.Message) error
+	SetOffset(offset int64) error
+	Close() error
+}
+
+// ReadArgs loads a flux.Arguments into FromKafkaOpSpec. It sets several default values.
+func (o *FromKafkaOpSpec) ReadArgs(args flux.Arguments) error {
+	brokers, err := args.GetRequiredArray("brokers", semantic.String)
+	if err != nil {
+		return err
+	}
+	if brokers.Len() < 1 {
+		return errors.New(codes.Invalid, "at least one broker is required")
+	}
+	o.Brokers = make([]string, brokers.Len())
+	for i := range o.Brokers {
+		o.Brokers[i] = brokers.Get(i).Str()
+	}
+
+	if o.Topic, err = args.GetRequiredString("topic"); err != nil {
+		return err
+	}
+	if len(o.Topic) == 0 {
+		return errors.New(codes.Invalid, "invalid topic name")
+	}
+
+	if o.Group, _, err = args.GetString("group"); err != nil {
+		return err
+	}
+
+	partition, ok, err := args.GetInt("partition")
+	if err != nil {
+		return err
+	} else if ok && o.Group != "" {
+		return errors.New(codes.Invalid, "cannot read a partition of a consumer group")
+	} else if partition < 0 {
+		return errors.Newf(codes.Invalid, "partition must be non-negative, got %d", partition)
+	}
+	o.Partition = int(partition)
+
+	o.Offset = -1
+	if offset, ok, err := args.GetInt("offset"); err != nil {
+		return err
+	} else if ok {
+		if o.Group != "" {
+			return errors.New(codes.Invalid, "cannot set the offset of a consumer group")
+		} else if offset < 0 {
+			return errors.Newf(codes.Invalid, "offset must be non-negative, got %d", offset)
+		}
+		o.Offset = offset
+	}
+
+	o.StopOffset = -1
+	if offset, ok, err := args.GetInt("stopOffset"); err != nil {
+		return err
+	} else if ok {
+		if o.Group != "" {
+			return errors.New(codes.Invalid, "cannot set the stop offset of a consumer group")
+		} else if offset < 0 {
+			return errors.Newf(codes.Invalid, "stopOffset must be non-negative, got %d", offset)
+		}
+		o.StopOffset = offset
+	}
+
+	if stop, ok, err := args.GetTime("stop"); err != nil {
+		return err
+	} else if ok {
+		o.Stop = stop
+	}
+
+	if o.Limit, _, err = args.GetInt("limit"); err != nil {
+		return err
+	} else if o.Limit < 0 {
+		return errors.Newf(codes.Invalid, "limit must be non-negative, got %d", o.Limit)
+	}
+
+	if timeout, ok, err := args.GetDuration("timeout"); err != nil {
+		return err
+	} else if ok {
+		if o.Timeout = timeout.Duration(); o.Timeout <= 0 {
+			return errors.Newf(codes.Invalid, "timeout must be positive, got %v", o.Timeout)
+		}
+	}
+
+	o.Decoder, ok, err = args.GetString("decoder")
+	if err != nil {
+		return err
+	} else if !ok {
+		o.Decoder = messages.Decoders[0]
+	}
+	if _, err := messages.NewDecoder(o.Decoder, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func createFromKafkaOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	s := new(FromKafkaOpSpec)
+	if err := s.ReadArgs(args); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (FromKafkaOpSpec) Kind() flux.OperationKind {
+	return FromKafkaKind
+}
+
+type FromKafkaProcedureSpec struct {
+	plan.DefaultCost
+	Spec *FromKafkaOpSpec
+	Stop time.Time
+}
+
+func (o *FromKafkaProcedureSpec) Kind() plan.ProcedureKind {
+	return FromKafkaKind
+}
+
+func (o *FromKafkaProcedureSpec) Copy() plan.ProcedureSpec {
+	s := *o.Spec
+	s.Brokers = append([]string(nil), o.Spec.Brokers...)
+	return &FromKafkaProcedureSpec{
+		Spec: &s,
+		Stop: o.Stop,
+	}
+}
+
+func newFromKafkaProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromKafkaOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	ps := &FromKafkaProcedureSpec{Spec: spec}
+	if !spec.Stop.IsZero() {
+		ps.Stop = spec.Stop.Time(pa.Now())
+	}
+	return ps, nil
+}
+
+func createFromKafkaSource(s plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := s.(*FromKafkaProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", s)
+	}
+	return CreateFromKafkaSource(spec, dsid, a)
+}
+
+// CreateFromKafkaSource creates a source that reads the messages described by the spec.
+func CreateFromKafkaSource(spec *FromKafkaProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	deps := flux.GetDependencies(a.Context())
+	validator, err := deps.URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range spec.Spec.Brokers {
+		u, err := url.Parse(b)
+		if err != nil {
+			return nil, errors.Newf(codes.Invalid, "invalid kafka broker url: %v", err)
+		}
+		if err := validator.Validate(u); err != nil {
+			return nil, errors.Newf(codes.Invalid, "kafka broker url did not pass validation: %v", err)
+		}
+	}
+
+	decoder, err := messages.NewDecoder(spec.Spec.Decoder, messages.NowTimeProvider{})
+	if err != nil {
+		return nil, err
+	}
+
+	r := DefaultKafkaReaderFactory(kafka.ReaderConfig{
+		Brokers:   spec.Spec.Brokers,
+		GroupID:   spec.Spec.Group,
+		Topic:     spec.Spec.Topic,
+		Partition: spec.Spec.Partition,
+	})
+	if spec.Spec.Offset >= 0 {
+		if err := r.SetOffset(spec.Spec.Offset); err != nil {
+			_ = r.Close()
+			return nil, errors.Wrap(err, codes.Invalid, "failed to set the kafka offset")
+		}
+	}
+	reader := &kafkaMessageReader{
+		r:          r,
+		stopOffset: spec.Spec.StopOffset,
+		commit:     spec.Spec.Group != "",
+	}
+	return messages.NewSource(reader, decoder, messages.Bounds{
+		Limit:   spec.Spec.Limit,
+		Stop:    spec.Stop,
+		Timeout: spec.Spec.Timeout,
+	}, dsid), nil
+}
+
+// kafkaMessageReader reads the messages of a kafka reader
+// and commits them to the consumer group once they are processed.
+type kafkaMessageReader struct {
+	r          KafkaReader
+	stopOffset int64
+	commit     bool
+	read       []kafka.Message
+}
+
+func (k *kafkaMessageReader) ReadMessage(ctx context.Context) (messages.Message, error) {
+	m, err := k.r.FetchMessage(ctx)
+	if err != nil {
+		return messages.Message{}, err
+	}
+	if k.stopOffset >= 0 && m.Offset >= k.stopOffset {
+		return messages.Message{}, io.EOF
+	}
+	if k.commit {
+		k.read = append(k.read, m)
+	}
+	return messages.Message{Payload: m.Value, Time: m.Time}, nil
+}
+
+func (k *kafkaMessageReader) Commit(ctx context.Context, n int) error {
+	if !k.commit || n == 0 {
+		return nil
+	}
+	if err := k.r.CommitMessages(ctx, k.read[:n]...); err != nil {
+		return errors.Wrap(err, codes.Unavailable, "failed to commit the kafka messages")
+	}
+	return nil
+}
+
+func (k *kafkaMessageReader) Close() error {
+	return k.r.Close()
+}
@@ -0,0 +1,603 @@
+package kafka_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/querytest"
+	fkafka "github.com/influxdata/flux/stdlib/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestFromKafka_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "from with defaults",
+			Raw:  `import "kafka" kafka.from(brokers:["brokerurl:8989"], topic:"totallynotfaketopic")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "fromKafka0",
+						Spec: &fkafka.FromKafkaOpSpec{
+							Brokers:    []string{"brokerurl:8989"},
+							Topic:      "totallynotfaketopic",
+							Offset:     -1,
+							StopOffset: -1,
+							Decoder:    "lineprotocol",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "from with bounds",
+			Raw:  `import "kafka" kafka.from(brokers:["brokerurl:8989"], topic:"t", partition: 2, offset: 10, stopOffset: 20, limit: 5, timeout: 1s, decoder: "json")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "fromKafka0",
+						Spec: &fkafka.FromKafkaOpSpec{
+							Brokers:    []string{"brokerurl:8989"},
+							Topic:      "t",
+							Partition:  2,
+							Offset:     10,
+							StopOffset: 20,
+							Limit:      5,
+							Timeout:    time.Second,
+							Decoder:    "json",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:    "offset with group",
+			Raw:     `import "kafka" kafka.from(brokers:["brokerurl:8989"], topic:"t", group: "g", offset: 10)`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid decoder",
+			Raw:     `import "kafka" kafka.from(brokers:["brokerurl:8989"], topic:"t", decoder: "xml")`,
+			WantErr: true,
+		},
+		{
+			Name:    "no brokers",
+			Raw:     `import "kafka" kafka.from(brokers:[], topic:"t")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+// kafkaReaderMock is a reader of the messages of a single partition.
+// It waits for the context to be done once all of the messages were read.
+type kafkaReaderMock struct {
+	config    kafka.ReaderConfig
+	messages  []kafka.Message
+	offset    int64
+	committed []kafka.Message
+	closed    bool
+}
+
+func (k *kafkaReaderMock) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for _, m := range k.messages {
+		if m.Offset >= k.offset {
+			k.offset = m.Offset + 1
+			return m, nil
+		}
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (k *kafkaReaderMock) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	k.committed = append(k.committed, msgs...)
+	return nil
+}
+
+func (k *kafkaReaderMock) SetOffset(offset int64) error {
+	k.offset = offset
+	return nil
+}
+
+func (k *kafkaReaderMock) Close() error {
+	k.closed = true
+	return nil
+}
+
+func TestFromKafka_Run(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	lp := []kafka.Message{
+		{Offset: 0, Time: start, Value: []byte("cpu,host=a v=1 10")},
+		{Offset: 1, Time: start.Add(time.Second), Value: []byte("cpu,host=a v=2 20\ncpu,host=b v=3 20\n")},
+		{Offset: 2, Time: start.Add(2 * time.Second), Value: []byte("cpu,host=a v=4 30")},
+	}
+	cpu := func(host string, rows ...[]interface{}) *executetest.Table {
+		return &executetest.Table{
+			KeyCols: []string{"_field", "_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: rows,
+		}
+	}
+
+	factory := fkafka.DefaultKafkaReaderFactory
+	defer func() { fkafka.DefaultKafkaReaderFactory = factory }()
+
+	for _, tc := range []struct {
+		name          string
+		spec          *fkafka.FromKafkaOpSpec
+		stop          time.Time
+		messages      []kafka.Message
+		want          []*executetest.Table
+		wantCommitted []int64
+	}{
+		{
+			name:     "all messages",
+			spec:     &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1, Decoder: "lineprotocol"},
+			messages: lp,
+			want: []*executetest.Table{
+				cpu("a",
+					[]interface{}{execute.Time(10), 1.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(20), 2.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(30), 4.0, "v", "cpu", "a"},
+				),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+		},
+		{
+			name:     "offset and limit",
+			spec:     &fkafka.FromKafkaOpSpec{Offset: 1, StopOffset: -1, Limit: 1, Decoder: "lineprotocol"},
+			messages: lp,
+			want: []*executetest.Table{
+				cpu("a", []interface{}{execute.Time(20), 2.0, "v", "cpu", "a"}),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+		},
+		{
+			name:     "stop offset",
+			spec:     &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: 1, Decoder: "lineprotocol"},
+			messages: lp,
+			want: []*executetest.Table{
+				cpu("a", []interface{}{execute.Time(10), 1.0, "v", "cpu", "a"}),
+			},
+		},
+		{
+			name:     "stop time",
+			spec:     &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1, Decoder: "lineprotocol"},
+			stop:     start.Add(2 * time.Second),
+			messages: lp,
+			want: []*executetest.Table{
+				cpu("a",
+					[]interface{}{execute.Time(10), 1.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(20), 2.0, "v", "cpu", "a"},
+				),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+		},
+		{
+			name:     "group commit",
+			spec:     &fkafka.FromKafkaOpSpec{Group: "g", Offset: -1, StopOffset: -1, Limit: 2, Decoder: "lineprotocol"},
+			messages: lp,
+			want: []*executetest.Table{
+				cpu("a",
+					[]interface{}{execute.Time(10), 1.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(20), 2.0, "v", "cpu", "a"},
+				),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+			wantCommitted: []int64{0, 1},
+		},
+		{
+			name: "json",
+			spec: &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1, Decoder: "json"},
+			messages: []kafka.Message{
+				{Offset: 0, Value: []byte(`{"_time": "1970-01-01T00:00:00.000000010Z", "device": "d1", "temp": 21.5}`)},
+				{Offset: 1, Value: []byte(`{"_time": "1970-01-01T00:00:00.000000020Z", "device": "d2", "temp": 19}`)},
+			},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "device", Type: flux.TString},
+					{Label: "temp", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(10), "d1", 21.5},
+					{execute.Time(20), "d2", 19.0},
+				},
+			}},
+		},
+		{
+			name: "csv",
+			spec: &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1, Decoder: "csv"},
+			messages: []kafka.Message{
+				{Offset: 0, Value: []byte("#datatype,string,long,dateTime:RFC3339,double,string\n#group,false,false,false,false,true\n#default,_result,,,,\n,result,table,_time,_value,host\n,,0,1970-01-01T00:00:00Z,1,a\n")},
+				{Offset: 1, Value: []byte("#datatype,string,long,dateTime:RFC3339,double,string\n#group,false,false,false,false,true\n#default,_result,,,,\n,result,table,_time,_value,host\n,,0,1970-01-01T00:00:01Z,2,b\n")},
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "host", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), 1.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "host", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1e9), 2.0, "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "no messages",
+			spec: &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1, Decoder: "lineprotocol"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.Brokers = []string{"brokerurl:8989"}
+			tc.spec.Topic = "totallynotfaketopic"
+			tc.spec.Timeout = 10 * time.Millisecond
+
+			var reader *kafkaReaderMock
+			fkafka.DefaultKafkaReaderFactory = func(conf kafka.ReaderConfig) fkafka.KafkaReader {
+				reader = &kafkaReaderMock{config: conf, messages: tc.messages}
+				return reader
+			}
+			ctx := dependenciestest.Default().Inject(context.Background())
+			executetest.RunSourceHelper(t, tc.want, nil, func(id execute.DatasetID) execute.Source {
+				s, err := fkafka.CreateFromKafkaSource(&fkafka.FromKafkaProcedureSpec{
+					Spec: tc.spec,
+					Stop: tc.stop,
+				}, id, mock.AdministrationWithContext(ctx))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return s
+			})
+
+			if want, got := tc.spec.Group, reader.config.GroupID; want != got {
+				t.Errorf("unexpected group -want/+got:\n\t- %s\n\t+ %s", want, got)
+			}
+			var committed []int64
+			for _, m := range reader.committed {
+				committed = append(committed, m.Offset)
+			}
+			if !cmp.Equal(tc.wantCommitted, committed) {
+				t.Errorf("unexpected committed offsets -want/+got:\n%s", cmp.Diff(tc.wantCommitted, committed))
+			}
+			if !reader.closed {
+				t.Error("expected the reader to be closed")
+			}
+		})
+	}
+}
+
+// fakeBroker is a kafka broker that leads the first partition of each of
+// its topics. It speaks the metadata, list offsets and fetch requests that
+// the reader of a single partition sends.
+type fakeBroker struct {
+	l        net.Listener
+	messages map[string][]kafka.Message
+}
+
+func newFakeBroker(t *testing.T, messages map[string][]kafka.Message) *fakeBroker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{l: l, messages: messages}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// Addr returns the address of the broker. It uses the host name
+// so that it is also a valid url.
+func (b *fakeBroker) Addr() string {
+	return "localhost:" + strconv.Itoa(b.l.Addr().(*net.TCPAddr).Port)
+}
+
+func (b *fakeBroker) Close() {
+	_ = b.l.Close()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		var size int32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		req := &request{b: make([]byte, size)}
+		if _, err := io.ReadFull(r, req.b); err != nil {
+			return
+		}
+		apiKey, _ := req.int16(), req.int16()
+		id := req.int32()
+		_ = req.string() // client id
+
+		res := &response{}
+		res.int32(id)
+		switch apiKey {
+		case 1: // Fetch
+			b.fetch(req, res)
+		case 2: // ListOffsets
+			b.listOffsets(req, res)
+		case 3: // Metadata
+			b.metadata(req, res)
+		default:
+			return
+		}
+		if _, err := conn.Write(res.bytes()); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) metadata(req *request, res *response) {
+	addr := b.l.Addr().(*net.TCPAddr)
+	res.int32(1)
+	res.int32(0) // node id
+	res.string(addr.IP.String())
+	res.int32(int32(addr.Port))
+
+	topics := make([]string, req.int32())
+	for i := range topics {
+		topics[i] = req.string()
+	}
+	res.int32(int32(len(topics)))
+	for _, topic := range topics {
+		if _, ok := b.messages[topic]; !ok {
+			res.int16(3) // UnknownTopicOrPartition
+			res.string(topic)
+			res.int32(0)
+			continue
+		}
+		res.int16(0)
+		res.string(topic)
+		res.int32(1)
+		res.int16(0)
+		res.int32(0) // partition
+		res.int32(0) // leader
+		res.int32(1) // replicas
+		res.int32(0)
+		res.int32(1) // in sync replicas
+		res.int32(0)
+	}
+}
+
+// listOffsets answers a request for the first or last
+// offset of a single partition of a single topic.
+func (b *fakeBroker) listOffsets(req *request, res *response) {
+	_, _, topic := req.int32(), req.int32(), req.string()
+	_, partition, t := req.int32(), req.int32(), req.int64()
+
+	offset := int64(len(b.messages[topic]))
+	if t == -2 {
+		offset = 0
+	}
+	res.int32(1)
+	res.string(topic)
+	res.int32(1)
+	res.int32(partition)
+	res.int16(0)
+	res.int64(-1) // timestamp
+	res.int64(offset)
+}
+
+// fetch answers a request for the messages of a single partition
+// of a single topic with every message from the offset on.
+func (b *fakeBroker) fetch(req *request, res *response) {
+	_, _, _, _, topic := req.int32(), req.int32(), req.int32(), req.int32(), req.string()
+	_, partition, offset := req.int32(), req.int32(), req.int64()
+
+	set := &response{}
+	msgs := b.messages[topic]
+	for i := offset; i < int64(len(msgs)); i++ {
+		m := &response{}
+		m.int8(1) // magic
+		m.int8(0) // attributes
+		m.int64(msgs[i].Time.UnixNano() / int64(time.Millisecond))
+		m.int32(-1) // null key
+		m.int32(int32(len(msgs[i].Value)))
+		m.buf.Write(msgs[i].Value)
+
+		set.int64(i)
+		set.int32(int32(4 + m.buf.Len()))
+		set.int32(int32(crc32.ChecksumIEEE(m.buf.Bytes())))
+		set.buf.Write(m.buf.Bytes())
+	}
+
+	res.int32(0) // throttle time
+	res.int32(1)
+	res.string(topic)
+	res.int32(1)
+	res.int32(partition)
+	res.int16(0)
+	res.int64(int64(len(msgs))) // high water mark
+	res.int32(int32(set.buf.Len()))
+	res.buf.Write(set.buf.Bytes())
+}
+
+// request reads the big endian fields of a kafka request.
+type request struct {
+	b []byte
+}
+
+func (r *request) int16() int16 {
+	v := int16(binary.BigEndian.Uint16(r.b))
+	r.b = r.b[2:]
+	return v
+}
+
+func (r *request) int32() int32 {
+	v := int32(binary.BigEndian.Uint32(r.b))
+	r.b = r.b[4:]
+	return v
+}
+
+func (r *request) int64() int64 {
+	v := int64(binary.BigEndian.Uint64(r.b))
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *request) string() string {
+	n := int(r.int16())
+	v := string(r.b[:n])
+	r.b = r.b[n:]
+	return v
+}
+
+// response writes the big endian fields of a kafka response.
+type response struct {
+	buf bytes.Buffer
+}
+
+func (r *response) int8(v int8) {
+	r.buf.WriteByte(byte(v))
+}
+
+func (r *response) int16(v int16) {
+	_ = binary.Write(&r.buf, binary.BigEndian, v)
+}
+
+func (r *response) int32(v int32) {
+	_ = binary.Write(&r.buf, binary.BigEndian, v)
+}
+
+func (r *response) int64(v int64) {
+	_ = binary.Write(&r.buf, binary.BigEndian, v)
+}
+
+func (r *response) string(v string) {
+	r.int16(int16(len(v)))
+	r.buf.WriteString(v)
+}
+
+// bytes returns the response prefixed by its size.
+func (r *response) bytes() []byte {
+	b := make([]byte, 4, 4+r.buf.Len())
+	binary.BigEndian.PutUint32(b, uint32(r.buf.Len()))
+	return append(b, r.buf.Bytes()...)
+}
+
+func TestFromKafka_Broker(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	b := newFakeBroker(t, map[string][]kafka.Message{
+		"cpu": {
+			{Time: start, Value: []byte("cpu,host=a v=1 10")},
+			{Time: start.Add(time.Second), Value: []byte("cpu,host=a v=2 20\ncpu,host=b v=3 20\n")},
+			{Time: start.Add(2 * time.Second), Value: []byte("cpu,host=a v=4 30")},
+		},
+	})
+	defer b.Close()
+
+	cpu := func(host string, rows ...[]interface{}) *executetest.Table {
+		return &executetest.Table{
+			KeyCols: []string{"_field", "_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: rows,
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		spec *fkafka.FromKafkaOpSpec
+		want []*executetest.Table
+	}{
+		{
+			name: "until timeout",
+			spec: &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1},
+			want: []*executetest.Table{
+				cpu("a",
+					[]interface{}{execute.Time(10), 1.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(20), 2.0, "v", "cpu", "a"},
+					[]interface{}{execute.Time(30), 4.0, "v", "cpu", "a"},
+				),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+		},
+		{
+			name: "offset and stop offset",
+			spec: &fkafka.FromKafkaOpSpec{Offset: 1, StopOffset: 2},
+			want: []*executetest.Table{
+				cpu("a", []interface{}{execute.Time(20), 2.0, "v", "cpu", "a"}),
+				cpu("b", []interface{}{execute.Time(20), 3.0, "v", "cpu", "b"}),
+			},
+		},
+		{
+			name: "limit",
+			spec: &fkafka.FromKafkaOpSpec{Offset: -1, StopOffset: -1, Limit: 1},
+			want: []*executetest.Table{
+				cpu("a", []interface{}{execute.Time(10), 1.0, "v", "cpu", "a"}),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.Brokers = []string{b.Addr()}
+			tc.spec.Topic = "cpu"
+			tc.spec.Timeout = time.Second
+			tc.spec.Decoder = "lineprotocol"
+
+			ctx := dependenciestest.Default().Inject(context.Background())
+			executetest.RunSourceHelper(t, tc.want, nil, func(id execute.DatasetID) execute.Source {
+				s, err := fkafka.CreateFromKafkaSource(&fkafka.FromKafkaProcedureSpec{Spec: tc.spec}, id, mock.AdministrationWithContext(ctx))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return s
+			})
+		})
+	}
+}
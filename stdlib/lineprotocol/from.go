@@ -0,0 +1,172 @@
+package lineprotocol
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/line"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const FromLineProtocolKind = "fromLineProtocol"
+
+type FromLineProtocolOpSpec struct {
+	Text string `json:"text"`
+	File string `json:"file"`
+}
+
+func init() {
+	fromLineProtocolSignature := runtime.MustLookupBuiltinType("lineprotocol", "from")
+	runtime.RegisterPackageValue("lineprotocol", "from", flux.MustValue(flux.FunctionValue(FromLineProtocolKind, createFromLineProtocolOpSpec, fromLineProtocolSignature)))
+	flux.RegisterOpSpec(FromLineProtocolKind, newFromLineProtocolOp)
+	plan.RegisterProcedureSpec(FromLineProtocolKind, newFromLineProtocolProcedure, FromLineProtocolKind)
+	execute.RegisterSource(FromLineProtocolKind, createFromLineProtocolSource)
+}
+
+func createFromLineProtocolOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(FromLineProtocolOpSpec)
+
+	if text, ok, err := args.GetString("text"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Text = text
+	}
+
+	if file, ok, err := args.GetString("file"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.File = file
+	}
+
+	if spec.Text == "" && spec.File == "" {
+		return nil, errors.New(codes.Invalid, "must provide line protocol text or filename")
+	}
+
+	if spec.Text != "" && spec.File != "" {
+		return nil, errors.New(codes.Invalid, "must provide exactly one of the parameters text or file")
+	}
+	return spec, nil
+}
+
+func newFromLineProtocolOp() flux.OperationSpec {
+	return new(FromLineProtocolOpSpec)
+}
+
+func (s *FromLineProtocolOpSpec) Kind() flux.OperationKind {
+	return FromLineProtocolKind
+}
+
+type FromLineProtocolProcedureSpec struct {
+	plan.DefaultCost
+	Text string
+	File string
+}
+
+func newFromLineProtocolProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromLineProtocolOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &FromLineProtocolProcedureSpec{
+		Text: spec.Text,
+		File: spec.File,
+	}, nil
+}
+
+func (s *FromLineProtocolProcedureSpec) Kind() plan.ProcedureKind {
+	return FromLineProtocolKind
+}
+
+func (s *FromLineProtocolProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createFromLineProtocolSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromLineProtocolProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", prSpec)
+	}
+	return CreateSource(spec, dsid, a)
+}
+
+func CreateSource(spec *FromLineProtocolProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	var getDataStream func() (io.ReadCloser, error)
+	if spec.File != "" {
+		getDataStream = func() (io.ReadCloser, error) {
+			f, err := filesystem.OpenFile(a.Context(), spec.File)
+			if err != nil {
+				return nil, errors.Wrap(err, codes.Inherit, "lineprotocol.from() failed to read file")
+			}
+			return f, nil
+		}
+	} else { // if spec.File is empty then spec.Text is not empty
+		getDataStream = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(spec.Text)), nil
+		}
+	}
+	return &LineProtocolSource{
+		id:            dsid,
+		getDataStream: getDataStream,
+		alloc:         a.Allocator(),
+	}, nil
+}
+
+type LineProtocolSource struct {
+	execute.ExecutionNode
+	id            execute.DatasetID
+	getDataStream func() (io.ReadCloser, error)
+	ts            []execute.Transformation
+	alloc         *memory.Allocator
+}
+
+func (s *LineProtocolSource) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *LineProtocolSource) Run(ctx context.Context) {
+	var err error
+	for _, t := range s.ts {
+		// The input is decoded once for each downstream transformation
+		// so a table instance goes to one and only one transformation.
+		if err = s.decode(t); err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, codes.Inherit, "error in lineprotocol.from()")
+	}
+	for _, t := range s.ts {
+		t.Finish(s.id, err)
+	}
+}
+
+func (s *LineProtocolSource) decode(t execute.Transformation) error {
+	data, err := s.getDataStream()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = data.Close() }()
+
+	decoder := line.NewProtocolDecoder(&line.ProtocolDecoderConfig{
+		Allocator: s.alloc,
+	})
+	result, err := decoder.Decode(data)
+	if err != nil {
+		return err
+	}
+	return result.Tables().Do(func(tbl flux.Table) error {
+		return t.Process(s.id, tbl)
+	})
+}
@@ -0,0 +1,155 @@
+package lineprotocol_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static" // We need to init flux for the tests to work.
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/lineprotocol"
+)
+
+func TestFromLineProtocol_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "from no args",
+			Raw:     `import "lineprotocol" lineprotocol.from()`,
+			WantErr: true,
+		},
+		{
+			Name:    "from text and file",
+			Raw:     `import "lineprotocol" lineprotocol.from(text: "cpu v=1 1", file: "/data.lp")`,
+			WantErr: true,
+		},
+		{
+			Name: "from text",
+			Raw:  `import "lineprotocol" lineprotocol.from(text: "cpu v=1 1")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID:   "fromLineProtocol0",
+						Spec: &lineprotocol.FromLineProtocolOpSpec{Text: "cpu v=1 1"},
+					},
+				},
+			},
+		},
+		{
+			Name: "from file",
+			Raw:  `import "lineprotocol" lineprotocol.from(file: "/data.lp")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID:   "fromLineProtocol0",
+						Spec: &lineprotocol.FromLineProtocolOpSpec{File: "/data.lp"},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestFromLineProtocol_Run(t *testing.T) {
+	const data = "cpu,host=a usage=1.5 10\ncpu,host=a usage=2.5 20\nmem free=4i 10\n"
+
+	dir, err := ioutil.TempDir("", "flux-lineprotocol-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	fpath := filepath.Join(dir, "data.lp")
+	if err := ioutil.WriteFile(fpath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := filesystem.Inject(context.Background(), filesystem.SystemFS)
+
+	want := []*executetest.Table{
+		{
+			KeyCols: []string{"_field", "_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(10), 1.5, "usage", "cpu", "a"},
+				{execute.Time(20), 2.5, "usage", "cpu", "a"},
+			},
+		},
+		{
+			KeyCols: []string{"_field", "_measurement"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TInt},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(10), int64(4), "free", "mem"},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		spec *lineprotocol.FromLineProtocolProcedureSpec
+	}{
+		{
+			name: "text",
+			spec: &lineprotocol.FromLineProtocolProcedureSpec{Text: data},
+		},
+		{
+			name: "file",
+			spec: &lineprotocol.FromLineProtocolProcedureSpec{File: fpath},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.RunSourceHelper(t, want, nil,
+				func(id execute.DatasetID) execute.Source {
+					a := mock.AdministrationWithContext(ctx)
+					s, err := lineprotocol.CreateSource(tc.spec, id, a)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return s
+				},
+			)
+		})
+	}
+}
+
+func TestFromLineProtocol_Invalid(t *testing.T) {
+	store := executetest.NewDataStore()
+	s, err := lineprotocol.CreateSource(&lineprotocol.FromLineProtocolProcedureSpec{
+		Text: "cpu v=1 1\ncpu v=true 2\n",
+	}, executetest.RandomDatasetID(), mock.AdministrationWithContext(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddTransformation(store)
+	s.Run(context.Background())
+
+	if store.Err() == nil {
+		t.Fatal("expected error")
+	} else if want, got := `error in lineprotocol.from(): field "v" of measurement "cpu" has type float and bool`, store.Err().Error(); want != got {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
@@ -56,8 +56,10 @@ import (
 	_ "github.com/influxdata/flux/stdlib/interpolate"
 	_ "github.com/influxdata/flux/stdlib/json"
 	_ "github.com/influxdata/flux/stdlib/kafka"
+	_ "github.com/influxdata/flux/stdlib/lineprotocol"
 	_ "github.com/influxdata/flux/stdlib/math"
 	_ "github.com/influxdata/flux/stdlib/pagerduty"
+	_ "github.com/influxdata/flux/stdlib/parquet"
 	_ "github.com/influxdata/flux/stdlib/planner"
 	_ "github.com/influxdata/flux/stdlib/profiler"
 	_ "github.com/influxdata/flux/stdlib/pushbullet"
@@ -70,5 +72,6 @@ import (
 	_ "github.com/influxdata/flux/stdlib/system"
 	_ "github.com/influxdata/flux/stdlib/testing"
 	_ "github.com/influxdata/flux/stdlib/testing/expect"
+	_ "github.com/influxdata/flux/stdlib/timezone"
 	_ "github.com/influxdata/flux/stdlib/universe"
 )
@@ -0,0 +1,540 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/parquet"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const FromParquetKind = "fromParquet"
+
+type FromParquetOpSpec struct {
+	File    string   `json:"file"`
+	Columns []string `json:"columns,omitempty"`
+}
+
+func init() {
+	fromParquetSignature := runtime.MustLookupBuiltinType("parquet", "from")
+	runtime.RegisterPackageValue("parquet", "from", flux.MustValue(flux.FunctionValue(FromParquetKind, createFromParquetOpSpec, fromParquetSignature)))
+	flux.RegisterOpSpec(FromParquetKind, newFromParquetOp)
+	plan.RegisterProcedureSpec(FromParquetKind, newFromParquetProcedure, FromParquetKind)
+	execute.RegisterSource(FromParquetKind, createFromParquetSource)
+	plan.RegisterPhysicalRules(PushDownRangeRule{}, PushDownCountRule{})
+}
+
+func createFromParquetOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(FromParquetOpSpec)
+
+	file, err := args.GetRequiredString("file")
+	if err != nil {
+		return nil, err
+	} else if file == "" {
+		return nil, errors.New(codes.Invalid, "must provide a file name")
+	}
+	spec.File = file
+
+	if cols, ok, err := args.GetArray("columns", semantic.String); err != nil {
+		return nil, err
+	} else if ok {
+		columns, err := interpreter.ToStringArray(cols)
+		if err != nil {
+			return nil, err
+		}
+		spec.Columns = columns
+	}
+	return spec, nil
+}
+
+func newFromParquetOp() flux.OperationSpec {
+	return new(FromParquetOpSpec)
+}
+
+func (s *FromParquetOpSpec) Kind() flux.OperationKind {
+	return FromParquetKind
+}
+
+type FromParquetProcedureSpec struct {
+	File    string
+	Columns []string
+
+	// Bounds is set when a range has been pushed down
+	// so row groups outside of the range can be skipped.
+	Bounds flux.Bounds
+
+	// Count is set when a count of the rows within Bounds has been
+	// pushed down. The source then produces a single table grouped
+	// by _start and _stop with the count in the Count column.
+	Count string
+
+	// Statistics are read from the metadata of the file
+	// when the query is planned. They are nil until then.
+	Statistics *FileStatistics
+}
+
+// FileStatistics describe the row groups of a parquet file.
+// They are only used to estimate the cost of a plan, the file
+// may have changed by the time the query is executed.
+type FileStatistics struct {
+	// Columns are the names of the columns of the file.
+	Columns []string
+	// TimeColumn is the index of the _time column or -1.
+	TimeColumn int
+	RowGroups  []RowGroupStatistics
+}
+
+// RowGroupStatistics describe a row group of a parquet file.
+type RowGroupStatistics struct {
+	NumRows int64
+	// Sizes are the number of bytes read for each column.
+	Sizes []int64
+	// MinTime and MaxTime are the bounds of the _time column.
+	// HasTime is false when the bounds are unknown or when
+	// the row group has null times.
+	MinTime, MaxTime int64
+	HasTime          bool
+}
+
+// readStatistics reads the statistics of a parquet file from its metadata.
+func readStatistics(ctx context.Context, file string) (*FileStatistics, error) {
+	f, err := filesystem.OpenFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r, size, err := readerAt(f)
+	if err != nil {
+		return nil, err
+	}
+	pf, err := parquet.Open(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &FileStatistics{TimeColumn: timeColumn(pf)}
+	for _, c := range pf.Columns() {
+		stats.Columns = append(stats.Columns, c.Name)
+	}
+	for rg := 0; rg < pf.NumRowGroups(); rg++ {
+		rgs := RowGroupStatistics{
+			NumRows: pf.NumRows(rg),
+			Sizes:   make([]int64, len(stats.Columns)),
+		}
+		for i := range stats.Columns {
+			rgs.Sizes[i] = pf.ColumnSize(rg, i)
+		}
+		if stats.TimeColumn >= 0 {
+			nulls, ok := pf.NullCount(rg, stats.TimeColumn)
+			rgs.MinTime, rgs.MaxTime, rgs.HasTime = pf.Bounds(rg, stats.TimeColumn)
+			rgs.HasTime = rgs.HasTime && ok && nulls == 0
+		}
+		stats.RowGroups = append(stats.RowGroups, rgs)
+	}
+	return stats, nil
+}
+
+// timeColumn returns the index of the _time column of the file or -1.
+func timeColumn(f *parquet.File) int {
+	for i, c := range f.Columns() {
+		if c.Name == execute.DefaultTimeColLabel && c.Type == flux.TTime {
+			return i
+		}
+	}
+	return -1
+}
+
+func newFromParquetProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromParquetOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &FromParquetProcedureSpec{
+		File:    spec.File,
+		Columns: spec.Columns,
+	}, nil
+}
+
+func (s *FromParquetProcedureSpec) Kind() plan.ProcedureKind {
+	return FromParquetKind
+}
+
+func (s *FromParquetProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Columns != nil {
+		ns.Columns = make([]string, len(s.Columns))
+		copy(ns.Columns, s.Columns)
+	}
+	return &ns
+}
+
+// Cost estimates the number of bytes read from the file
+// using the statistics of the file. Nothing is known
+// about the output when there are no statistics.
+func (s *FromParquetProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	fs := s.Statistics
+	if fs == nil || len(fs.RowGroups) == 0 {
+		return plan.Cost{}, plan.Statistics{}
+	}
+
+	start, stop, bounded := s.timeBounds()
+	var (
+		cost plan.Cost
+		rows int64
+	)
+	for _, rg := range fs.RowGroups {
+		if rg.NumRows == 0 || bounded && rg.HasTime && (rg.MaxTime < start || rg.MinTime >= stop) {
+			continue
+		}
+		if s.Count != "" {
+			// Row groups within the bounds are counted from the
+			// metadata, only the times of the others are read.
+			if fs.TimeColumn >= 0 && !(rg.HasTime && rg.MinTime >= start && rg.MaxTime < stop) {
+				cost.Disk += rg.Sizes[fs.TimeColumn]
+			}
+			continue
+		}
+		rows += rg.NumRows
+		for i, label := range fs.Columns {
+			if s.Columns == nil || execute.ContainsStr(s.Columns, label) {
+				cost.Disk += rg.Sizes[i]
+			}
+		}
+	}
+	if s.Count != "" {
+		return cost, plan.Statistics{Cardinality: 1, GroupCardinality: 1}
+	}
+	return cost, plan.Statistics{Cardinality: rows, GroupCardinality: 1}
+}
+
+// timeBounds returns the bounds as nanoseconds since the epoch.
+// The returned bool is false when there are no bounds.
+func (s *FromParquetProcedureSpec) timeBounds() (start, stop int64, ok bool) {
+	if s.Bounds.IsEmpty() {
+		return 0, 0, false
+	}
+	return s.Bounds.Start.Time(s.Bounds.Now).UnixNano(), s.Bounds.Stop.Time(s.Bounds.Now).UnixNano(), true
+}
+
+func createFromParquetSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromParquetProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", prSpec)
+	}
+	return CreateSource(spec, dsid, a)
+}
+
+func CreateSource(spec *FromParquetProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	return &ParquetSource{
+		id:   dsid,
+		spec: spec,
+		open: func() (filesystem.File, error) {
+			return filesystem.OpenFile(a.Context(), spec.File)
+		},
+		alloc: a.Allocator(),
+	}, nil
+}
+
+type ParquetSource struct {
+	execute.ExecutionNode
+	id    execute.DatasetID
+	spec  *FromParquetProcedureSpec
+	open  func() (filesystem.File, error)
+	ts    []execute.Transformation
+	alloc *memory.Allocator
+}
+
+func (s *ParquetSource) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *ParquetSource) Run(ctx context.Context) {
+	err := s.run(ctx)
+	if err != nil {
+		err = errors.Wrap(err, codes.Inherit, "error in parquet.from()")
+	}
+	for _, t := range s.ts {
+		t.Finish(s.id, err)
+	}
+}
+
+func (s *ParquetSource) run(ctx context.Context) error {
+	f, err := s.open()
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "failed to open file")
+	}
+
+	r, size, err := readerAt(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	pf, err := parquet.Open(r, size)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if s.spec.Count != "" {
+		tbl, err := s.count(ctx, pf)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		return execute.TransformationSet(s.ts).Process(s.id, tbl)
+	}
+
+	tbl, err := s.newTable(ctx, pf)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	// The tables may be read after they have been processed
+	// so the file is closed when every copy has been read.
+	refs := int32(len(s.ts))
+	tbl.release = func() {
+		if atomic.AddInt32(&refs, -1) == 0 {
+			_ = f.Close()
+		}
+	}
+	for i, t := range s.ts {
+		// Each transformation reads its own copy of the table.
+		if err := t.Process(s.id, tbl.copy()); err != nil {
+			for range s.ts[i+1:] {
+				tbl.release()
+			}
+			return err
+		}
+	}
+	if len(s.ts) == 0 {
+		_ = f.Close()
+	}
+	return nil
+}
+
+// readerAt returns random access to the file. Files that do not
+// support random access are read into memory.
+func readerAt(f filesystem.File) (io.ReaderAt, int64, error) {
+	if r, ok := f.(io.ReaderAt); ok {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, 0, err
+		}
+		return r, fi.Size(), nil
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+func (s *ParquetSource) newTable(ctx context.Context, f *parquet.File) (*parquetTable, error) {
+	t := &parquetTable{
+		ctx:  ctx,
+		file: f,
+		key:  execute.NewGroupKey(nil, nil),
+		mem:  s.alloc,
+	}
+
+	columns := f.Columns()
+	if s.spec.Columns == nil {
+		for i, c := range columns {
+			t.cols = append(t.cols, flux.ColMeta{Label: c.Name, Type: c.Type})
+			t.indices = append(t.indices, i)
+		}
+	} else {
+		for _, label := range s.spec.Columns {
+			idx := -1
+			for i, c := range columns {
+				if c.Name == label {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				return nil, errors.Newf(codes.Invalid, "column %q does not exist in file", label)
+			}
+			t.cols = append(t.cols, flux.ColMeta{Label: label, Type: columns[idx].Type})
+			t.indices = append(t.indices, idx)
+		}
+	}
+
+	timeIdx := timeColumn(f)
+	start, stop, bounded := s.spec.timeBounds()
+	for rg := 0; rg < f.NumRowGroups(); rg++ {
+		if f.NumRows(rg) == 0 {
+			continue
+		}
+		if timeIdx >= 0 && bounded {
+			if min, max, ok := f.Bounds(rg, timeIdx); ok && (max < start || min >= stop) {
+				continue
+			}
+		}
+		t.rowGroups = append(t.rowGroups, rg)
+	}
+	return t, nil
+}
+
+// count produces the table that range and count would produce.
+// Row groups that are entirely within the bounds are counted
+// from the metadata of the file and the times of the other
+// row groups are read to count the rows within the bounds.
+func (s *ParquetSource) count(ctx context.Context, f *parquet.File) (flux.Table, error) {
+	columns := f.Columns()
+	timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, toColMeta(columns))
+	if timeIdx < 0 {
+		return nil, errors.Newf(codes.FailedPrecondition, "range error: supplied time column %s doesn't exist", execute.DefaultTimeColLabel)
+	} else if columns[timeIdx].Type != flux.TTime {
+		return nil, errors.Newf(codes.FailedPrecondition, "range error: provided time column %s is not of type time", execute.DefaultTimeColLabel)
+	}
+	countIdx := execute.ColIdx(s.spec.Count, toColMeta(columns))
+	if countIdx < 0 {
+		return nil, errors.Newf(codes.FailedPrecondition, "column %q does not exist", s.spec.Count)
+	} else if typ := columns[countIdx].Type; typ == flux.TTime {
+		return nil, errors.Newf(codes.FailedPrecondition, "unsupported aggregate column type %v", typ)
+	}
+
+	start, stop, _ := s.spec.timeBounds()
+	var n int64
+	for rg := 0; rg < f.NumRowGroups(); rg++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if f.NumRows(rg) == 0 {
+			continue
+		}
+		if min, max, ok := f.Bounds(rg, timeIdx); ok {
+			if max < start || min >= stop {
+				continue
+			}
+			if nulls, ok := f.NullCount(rg, timeIdx); ok && nulls == 0 && min >= start && max < stop {
+				n += f.NumRows(rg)
+				continue
+			}
+		}
+
+		arr, err := f.ReadColumn(rg, timeIdx, s.alloc)
+		if err != nil {
+			return nil, err
+		}
+		ts := arr.(*array.Int64)
+		for i := 0; i < ts.Len(); i++ {
+			if ts.IsValid(i) && ts.Value(i) >= start && ts.Value(i) < stop {
+				n++
+			}
+		}
+		arr.Release()
+	}
+
+	startTime := values.ConvertTime(s.spec.Bounds.Start.Time(s.spec.Bounds.Now))
+	stopTime := values.ConvertTime(s.spec.Bounds.Stop.Time(s.spec.Bounds.Now))
+	keyCols := []flux.ColMeta{
+		{Label: execute.DefaultStartColLabel, Type: flux.TTime},
+		{Label: execute.DefaultStopColLabel, Type: flux.TTime},
+	}
+	key := execute.NewGroupKey(keyCols, []values.Value{
+		values.NewTime(startTime),
+		values.NewTime(stopTime),
+	})
+	return table.FromBuffer(&arrow.TableBuffer{
+		GroupKey: key,
+		Columns:  append(keyCols, flux.ColMeta{Label: s.spec.Count, Type: flux.TInt}),
+		Values: []array.Interface{
+			arrow.NewInt([]int64{int64(startTime)}, s.alloc),
+			arrow.NewInt([]int64{int64(stopTime)}, s.alloc),
+			arrow.NewInt([]int64{n}, s.alloc),
+		},
+	}), nil
+}
+
+func toColMeta(columns []parquet.Column) []flux.ColMeta {
+	cols := make([]flux.ColMeta, len(columns))
+	for i, c := range columns {
+		cols[i] = flux.ColMeta{Label: c.Name, Type: c.Type}
+	}
+	return cols
+}
+
+// parquetTable is a table that reads the row groups
+// of a parquet file when it is consumed.
+type parquetTable struct {
+	ctx       context.Context
+	file      *parquet.File
+	key       flux.GroupKey
+	cols      []flux.ColMeta
+	indices   []int
+	rowGroups []int
+	mem       *memory.Allocator
+	release   func()
+	used      bool
+}
+
+func (t *parquetTable) copy() *parquetTable {
+	nt := *t
+	return &nt
+}
+
+func (t *parquetTable) Key() flux.GroupKey   { return t.key }
+func (t *parquetTable) Cols() []flux.ColMeta { return t.cols }
+func (t *parquetTable) Empty() bool          { return len(t.rowGroups) == 0 }
+
+func (t *parquetTable) Done() {
+	if !t.used {
+		t.used = true
+		t.release()
+	}
+}
+
+func (t *parquetTable) Do(f func(flux.ColReader) error) error {
+	if t.used {
+		return errors.New(codes.Internal, "table already read")
+	}
+	t.used = true
+	defer t.release()
+
+	for _, rg := range t.rowGroups {
+		if err := t.ctx.Err(); err != nil {
+			return err
+		}
+		buf := &arrow.TableBuffer{
+			GroupKey: t.key,
+			Columns:  t.cols,
+			Values:   make([]array.Interface, len(t.cols)),
+		}
+		for j, idx := range t.indices {
+			arr, err := t.file.ReadColumn(rg, idx, t.mem)
+			if err != nil {
+				buf.Values = buf.Values[:j]
+				buf.Release()
+				return err
+			}
+			buf.Values[j] = arr
+		}
+		err := f(buf)
+		buf.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+package parquet_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static" // We need to init flux for the tests to work.
+	iparquet "github.com/influxdata/flux/internal/parquet"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/parquet"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestFromParquet_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "from no args",
+			Raw:     `import "parquet" parquet.from()`,
+			WantErr: true,
+		},
+		{
+			Name: "from with columns",
+			Raw:  `import "parquet" parquet.from(file: "/data.parquet", columns: ["_time", "_value"])`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "fromParquet0",
+						Spec: &parquet.FromParquetOpSpec{
+							File:    "/data.parquet",
+							Columns: []string{"_time", "_value"},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func newTables() []*executetest.Table {
+	return []*executetest.Table{
+		{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(10), "a", 1.0},
+				{execute.Time(20), "a", nil},
+			},
+		},
+		{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "host", Type: flux.TString},
+				{Label: "count", Type: flux.TInt},
+			},
+			Data: [][]interface{}{
+				{execute.Time(30), "b", int64(4)},
+			},
+		},
+	}
+}
+
+// writeFile writes the tables with parquet.to
+// and returns the path of the file.
+func writeFile(t *testing.T, ctx context.Context, dir string) string {
+	t.Helper()
+
+	fpath := filepath.Join(dir, "data.parquet")
+	var data []flux.Table
+	for _, tbl := range newTables() {
+		data = append(data, tbl)
+	}
+	executetest.ProcessTestHelper2(t, data, newTables(), nil,
+		func(id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset) {
+			return parquet.NewToParquetTransformation(ctx, id, &parquet.ToParquetProcedureSpec{File: fpath})
+		},
+	)
+	return fpath
+}
+
+func TestToParquet_RoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-parquet-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := filesystem.Inject(context.Background(), filesystem.WritableSystemFS)
+	fpath := writeFile(t, ctx, dir)
+
+	for _, tc := range []struct {
+		name string
+		spec *parquet.FromParquetProcedureSpec
+		want []*executetest.Table
+	}{
+		{
+			name: "all columns",
+			spec: &parquet.FromParquetProcedureSpec{File: fpath},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "count", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(10), "a", 1.0, nil},
+					{execute.Time(20), "a", nil, nil},
+					{execute.Time(30), "b", nil, int64(4)},
+				},
+			}},
+		},
+		{
+			name: "columns",
+			spec: &parquet.FromParquetProcedureSpec{
+				File:    fpath,
+				Columns: []string{"count", "_time"},
+			},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "count", Type: flux.TInt},
+					{Label: "_time", Type: flux.TTime},
+				},
+				Data: [][]interface{}{
+					{nil, execute.Time(10)},
+					{nil, execute.Time(20)},
+					{int64(4), execute.Time(30)},
+				},
+			}},
+		},
+		{
+			name: "bounds",
+			spec: &parquet.FromParquetProcedureSpec{
+				File: fpath,
+				Bounds: flux.Bounds{
+					Start: flux.Time{Absolute: time.Unix(0, 25)},
+					Stop:  flux.Time{Absolute: time.Unix(0, 40)},
+				},
+			},
+			// Only the second row group is read.
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "count", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(30), "b", nil, int64(4)},
+				},
+			}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.RunSourceHelper(t, tc.want, nil,
+				func(id execute.DatasetID) execute.Source {
+					a := mock.AdministrationWithContext(ctx)
+					s, err := parquet.CreateSource(tc.spec, id, a)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return s
+				},
+			)
+		})
+	}
+}
+
+func TestFromParquet_MissingColumn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-parquet-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	ctx := filesystem.Inject(context.Background(), filesystem.WritableSystemFS)
+	fpath := writeFile(t, ctx, dir)
+
+	store := executetest.NewDataStore()
+	s, err := parquet.CreateSource(&parquet.FromParquetProcedureSpec{
+		File:    fpath,
+		Columns: []string{"missing"},
+	}, executetest.RandomDatasetID(), mock.AdministrationWithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddTransformation(store)
+	s.Run(ctx)
+
+	if store.Err() == nil {
+		t.Fatal("expected error")
+	} else if want, got := `error in parquet.from(): column "missing" does not exist in file`, store.Err().Error(); want != got {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestPushDownRangeRule(t *testing.T) {
+	bounds := flux.Bounds{
+		Start: flux.Time{Absolute: time.Unix(0, 10)},
+		Stop:  flux.Time{Absolute: time.Unix(0, 20)},
+	}
+	fromSpec := &parquet.FromParquetProcedureSpec{File: "/data.parquet"}
+	rangeSpec := &universe.RangeProcedureSpec{
+		Bounds:      bounds,
+		TimeColumn:  "_time",
+		StartColumn: "_start",
+		StopColumn:  "_stop",
+	}
+	tcs := []plantest.RuleTestCase{
+		{
+			Name:  "push down",
+			Rules: []plan.Rule{parquet.PushDownRangeRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", fromSpec),
+					plan.CreatePhysicalNode("range", rangeSpec),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", &parquet.FromParquetProcedureSpec{
+						File:   "/data.parquet",
+						Bounds: bounds,
+					}),
+					plan.CreatePhysicalNode("range", rangeSpec),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "multiple successors",
+			Rules: []plan.Rule{parquet.PushDownRangeRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", fromSpec),
+					plan.CreatePhysicalNode("range", rangeSpec),
+					plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{}),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+			NoChange: true,
+		},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
+
+// writeRowGroups writes a file with _time and _value columns.
+// The times are one second apart and each row group has n rows.
+func writeRowGroups(t *testing.T, fpath string, groups, n int) {
+	t.Helper()
+
+	f, err := os.Create(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+	}
+	mem := &memory.Allocator{}
+	w := iparquet.NewWriter(f)
+	for g := 0; g < groups; g++ {
+		times := make([]int64, n)
+		vs := make([]float64, n)
+		for i := range times {
+			times[i] = int64(g*n+i) * int64(time.Second)
+			vs[i] = float64(i)
+		}
+		arrs := []array.Interface{
+			arrow.NewInt(times, mem),
+			arrow.NewFloat(vs, mem),
+		}
+		if err := w.WriteRowGroup(cols, arrs); err != nil {
+			t.Fatal(err)
+		}
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPushDownCountRule_Query(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-parquet-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// Ten row groups of a thousand seconds and a single
+	// row group of ten thousand seconds.
+	groupsFile := filepath.Join(dir, "groups.parquet")
+	writeRowGroups(t, groupsFile, 10, 1000)
+	singleFile := filepath.Join(dir, "single.parquet")
+	writeRowGroups(t, singleFile, 1, 10000)
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		// pushed is true when the count should be read by parquet.from.
+		pushed bool
+		want   int64
+	}{
+		{
+			// Nothing else reads the file so counting
+			// the rows in the source is always cheaper.
+			name: "single successor",
+			query: `
+parquet.from(file: "` + singleFile + `")
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> count()
+	|> yield(name: "count")`,
+			pushed: true,
+			want:   3600,
+		},
+		{
+			// Only the times of the row group with the stop
+			// time are read again, which is cheaper than
+			// counting the rows that are read for raw.
+			name: "shared with whole row groups",
+			query: `
+data = parquet.from(file: "` + groupsFile + `")
+data
+	|> range(start: 1970-01-01T00:16:40Z, stop: 1970-01-01T02:35:00Z)
+	|> count()
+	|> yield(name: "count")
+data |> yield(name: "raw")`,
+			pushed: true,
+			want:   8300,
+		},
+		{
+			// All of the times would be read again to count
+			// the rows of the first hour, which is more expensive
+			// than counting the rows that are read for raw.
+			name: "shared with a partial row group",
+			query: `
+data = parquet.from(file: "` + singleFile + `")
+data
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> count()
+	|> yield(name: "count")
+data |> yield(name: "raw")`,
+			pushed: false,
+			want:   3600,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := &lang.FluxCompiler{
+				Query: `import "parquet"` + tc.query,
+			}
+			program, err := c.Compile(context.Background(), runtime.Default)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := filesystem.Inject(context.Background(), filesystem.SystemFS)
+			q, err := program.Start(ctx, &memory.Allocator{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer q.Done()
+
+			var counts []int64
+			for res := range q.Results() {
+				if err := res.Tables().Do(func(tbl flux.Table) error {
+					return tbl.Do(func(cr flux.ColReader) error {
+						if res.Name() != "count" {
+							return nil
+						}
+						idx := execute.ColIdx("_value", cr.Cols())
+						for i := 0; i < cr.Len(); i++ {
+							counts = append(counts, cr.Ints(idx).Value(i))
+						}
+						return nil
+					})
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			q.Done()
+			if err := q.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if want := []int64{tc.want}; !cmp.Equal(want, counts) {
+				t.Errorf("unexpected counts -want/+got:\n%s", cmp.Diff(want, counts))
+			}
+
+			pushed := false
+			ps := program.(*lang.AstProgram).PlanSpec
+			if err := ps.BottomUpWalk(func(node plan.Node) error {
+				if spec, ok := node.ProcedureSpec().(*parquet.FromParquetProcedureSpec); ok && spec.Count != "" {
+					pushed = true
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if tc.pushed != pushed {
+				t.Errorf("unexpected plan, count pushed down: %v\n%v", pushed, plan.Formatted(ps, plan.WithDetails(), plan.WithEstimates()))
+			}
+		})
+	}
+}
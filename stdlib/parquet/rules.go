@@ -0,0 +1,173 @@
+package parquet
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+// PushDownRangeRule copies the bounds of a range into parquet.from
+// so row groups outside of the range are not read. The range is
+// kept because the row groups may contain rows outside of it.
+type PushDownRangeRule struct{}
+
+func (PushDownRangeRule) Name() string {
+	return "parquet.PushDownRangeRule"
+}
+
+func (PushDownRangeRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.RangeKind, plan.Pat(FromParquetKind))
+}
+
+func (PushDownRangeRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	fromNode := node.Predecessors()[0]
+	if len(fromNode.Successors()) != 1 {
+		// Other successors need the rows outside of the range.
+		return node, false, nil
+	}
+	fromSpec := fromNode.ProcedureSpec().(*FromParquetProcedureSpec)
+	if !fromSpec.Bounds.IsEmpty() {
+		return node, false, nil
+	}
+
+	rangeSpec := node.ProcedureSpec().(*universe.RangeProcedureSpec)
+	if rangeSpec.TimeColumn != execute.DefaultTimeColLabel || rangeSpec.Bounds.IsEmpty() {
+		return node, false, nil
+	}
+
+	newFromSpec := fromSpec.Copy().(*FromParquetProcedureSpec)
+	newFromSpec.Bounds = rangeSpec.Bounds
+	if err := fromNode.ReplaceSpec(newFromSpec); err != nil {
+		return nil, false, err
+	}
+	return node, true, nil
+}
+
+// PushDownCountRule replaces a count of a range of parquet.from
+// with a source that counts the rows within the range. Row groups
+// that are entirely within the range are counted from the metadata
+// of the file, so only the times of the other row groups are read.
+//
+// The parquet.from may be used by other nodes, in which case the
+// file is read again by the new source. The rule is cost based so
+// that this is only done when reading the times is cheaper than
+// counting the rows that are read anyway.
+type PushDownCountRule struct{}
+
+func (PushDownCountRule) Name() string {
+	return "parquet.PushDownCountRule"
+}
+
+// Pattern matches any predecessor because a pattern cannot match
+// a parquet.from with more than one successor. The range and the
+// parquet.from are checked when the rule is applied.
+func (PushDownCountRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.CountKind, plan.Any())
+}
+
+func (r PushDownCountRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	spec, ok := r.countSpec(node)
+	if !ok {
+		return node, false, nil
+	}
+	newNode, err := r.rewrite(ctx, node, spec)
+	if err != nil {
+		return nil, false, err
+	}
+	return newNode, true, nil
+}
+
+func (r PushDownCountRule) Alternatives(ctx context.Context, node plan.Node) ([]plan.Alternative, error) {
+	spec, ok := r.countSpec(node)
+	if !ok {
+		return nil, nil
+	}
+
+	// The statistics are recorded on parquet.from so the
+	// cost of leaving the plan as it is can be estimated too.
+	fromNode := node.Predecessors()[0].Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(*FromParquetProcedureSpec)
+	if fromSpec.Statistics == nil {
+		stats, err := readStatistics(ctx, fromSpec.File)
+		if err != nil {
+			// The file may not exist until the query runs.
+			stats = &FileStatistics{}
+		}
+		newFromSpec := fromSpec.Copy().(*FromParquetProcedureSpec)
+		newFromSpec.Statistics = stats
+		if err := fromNode.ReplaceSpec(newFromSpec); err != nil {
+			return nil, err
+		}
+		spec.Statistics = stats
+	}
+
+	return []plan.Alternative{{
+		Spec: spec,
+		Rewrite: func(ctx context.Context, node plan.Node) (plan.Node, error) {
+			return r.rewrite(ctx, node, spec)
+		},
+	}}, nil
+}
+
+// countSpec returns the spec of the source that replaces the count.
+func (PushDownCountRule) countSpec(node plan.Node) (*FromParquetProcedureSpec, bool) {
+	countSpec := node.ProcedureSpec().(*universe.CountProcedureSpec)
+	if len(countSpec.Columns) != 1 {
+		return nil, false
+	}
+	column := countSpec.Columns[0]
+	if column == execute.DefaultStartColLabel || column == execute.DefaultStopColLabel {
+		return nil, false
+	}
+
+	rangeNode := node.Predecessors()[0]
+	if rangeNode.Kind() != universe.RangeKind || len(rangeNode.Predecessors()) != 1 {
+		return nil, false
+	}
+	fromNode := rangeNode.Predecessors()[0]
+	if fromNode.Kind() != FromParquetKind {
+		return nil, false
+	}
+	rangeSpec := rangeNode.ProcedureSpec().(*universe.RangeProcedureSpec)
+	if rangeSpec.TimeColumn != execute.DefaultTimeColLabel ||
+		rangeSpec.StartColumn != execute.DefaultStartColLabel ||
+		rangeSpec.StopColumn != execute.DefaultStopColLabel ||
+		rangeSpec.Bounds.IsEmpty() {
+		return nil, false
+	}
+
+	fromSpec := fromNode.ProcedureSpec().(*FromParquetProcedureSpec)
+	if fromSpec.Count != "" {
+		return nil, false
+	}
+	if fromSpec.Columns != nil &&
+		!(execute.ContainsStr(fromSpec.Columns, execute.DefaultTimeColLabel) && execute.ContainsStr(fromSpec.Columns, column)) {
+		return nil, false
+	}
+
+	spec := fromSpec.Copy().(*FromParquetProcedureSpec)
+	spec.Bounds = rangeSpec.Bounds
+	spec.Count = column
+	return spec, true
+}
+
+// rewrite replaces the count with a new source. The range is removed
+// from the successors of parquet.from, which is left in the plan
+// when it has other successors.
+func (PushDownCountRule) rewrite(ctx context.Context, node plan.Node, spec *FromParquetProcedureSpec) (plan.Node, error) {
+	rangeNode := node.Predecessors()[0]
+	fromNode := rangeNode.Predecessors()[0]
+
+	succs := make([]plan.Node, 0, len(fromNode.Successors()))
+	for _, succ := range fromNode.Successors() {
+		if succ != rangeNode {
+			succs = append(succs, succ)
+		}
+	}
+	fromNode.ClearSuccessors()
+	fromNode.AddSuccessors(succs...)
+
+	return plan.CreateUniquePhysicalNode(ctx, FromParquetKind, spec), nil
+}
@@ -0,0 +1,176 @@
+package parquet
+
+import (
+	"context"
+	"io"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/parquet"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const ToParquetKind = "toParquet"
+
+type ToParquetOpSpec struct {
+	File string `json:"file"`
+}
+
+func init() {
+	toParquetSignature := runtime.MustLookupBuiltinType("parquet", "to")
+	runtime.RegisterPackageValue("parquet", "to", flux.MustValue(flux.FunctionValueWithSideEffect(ToParquetKind, createToParquetOpSpec, toParquetSignature)))
+	flux.RegisterOpSpec(ToParquetKind, func() flux.OperationSpec { return &ToParquetOpSpec{} })
+	plan.RegisterProcedureSpecWithSideEffect(ToParquetKind, newToParquetProcedure, ToParquetKind)
+	execute.RegisterTransformation(ToParquetKind, createToParquetTransformation)
+}
+
+func createToParquetOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	file, err := args.GetRequiredString("file")
+	if err != nil {
+		return nil, err
+	} else if file == "" {
+		return nil, errors.New(codes.Invalid, "must provide a file name")
+	}
+	return &ToParquetOpSpec{File: file}, nil
+}
+
+func (ToParquetOpSpec) Kind() flux.OperationKind {
+	return ToParquetKind
+}
+
+type ToParquetProcedureSpec struct {
+	plan.DefaultCost
+	File string
+}
+
+func newToParquetProcedure(qs flux.OperationSpec, a plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*ToParquetOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &ToParquetProcedureSpec{File: spec.File}, nil
+}
+
+func (s *ToParquetProcedureSpec) Kind() plan.ProcedureKind {
+	return ToParquetKind
+}
+
+func (s *ToParquetProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createToParquetTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*ToParquetProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	t, d := NewToParquetTransformation(a.Context(), id, s)
+	return t, d, nil
+}
+
+// NewToParquetTransformation creates a transformation that writes
+// to the file using the filesystem service in the context.
+func NewToParquetTransformation(ctx context.Context, id execute.DatasetID, spec *ToParquetProcedureSpec) (*ToParquetTransformation, execute.Dataset) {
+	d := execute.NewPassthroughDataset(id)
+	t := &ToParquetTransformation{
+		d:    d,
+		spec: spec,
+		open: func() (io.WriteCloser, error) {
+			return filesystem.CreateFile(ctx, spec.File)
+		},
+	}
+	return t, d
+}
+
+// ToParquetTransformation writes each table to a parquet file
+// and passes it through unchanged. The file is created with the
+// filesystem service, which must be a filesystem.WritableService.
+type ToParquetTransformation struct {
+	execute.ExecutionNode
+	d    *execute.PassthroughDataset
+	spec *ToParquetProcedureSpec
+	open func() (io.WriteCloser, error)
+
+	f io.WriteCloser
+	w *parquet.Writer
+}
+
+func (t *ToParquetTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *ToParquetTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	if err := t.init(); err != nil {
+		return err
+	}
+
+	// The table is buffered so it can be written
+	// and then passed to the next transformation.
+	buffered, err := execute.CopyTable(tbl)
+	if err != nil {
+		return err
+	}
+	if err := buffered.Copy().Do(func(cr flux.ColReader) error {
+		if cr.Len() == 0 {
+			return nil
+		}
+		arrs := make([]array.Interface, len(cr.Cols()))
+		for j := range arrs {
+			arrs[j] = table.Values(cr, j)
+		}
+		return t.w.WriteRowGroup(cr.Cols(), arrs)
+	}); err != nil {
+		buffered.Done()
+		return errors.Wrap(err, codes.Inherit, "failed to write parquet file")
+	}
+	return t.d.Process(buffered)
+}
+
+// init creates the file when the first table is processed.
+func (t *ToParquetTransformation) init() error {
+	if t.w != nil {
+		return nil
+	}
+	f, err := t.open()
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "failed to create parquet file")
+	}
+	t.f, t.w = f, parquet.NewWriter(f)
+	return nil
+}
+
+func (t *ToParquetTransformation) UpdateWatermark(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateWatermark(pt)
+}
+
+func (t *ToParquetTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *ToParquetTransformation) Finish(id execute.DatasetID, err error) {
+	if err == nil {
+		// A file without any rows is still written.
+		err = t.init()
+	}
+	if t.w != nil {
+		if err == nil {
+			if err = t.w.Close(); err != nil {
+				err = errors.Wrap(err, codes.Inherit, "failed to write parquet file")
+			}
+		}
+		if cerr := t.f.Close(); err == nil && cerr != nil {
+			err = errors.Wrap(cerr, codes.Inherit, "failed to close parquet file")
+		}
+	}
+	t.d.Finish(err)
+}
@@ -1,17 +1,22 @@
 // Package socket implements a source that gets input from a socket connection and produces tables given a decoder.
-// This is a good candidate for streaming use cases. For now, it produces a single table for everything
-// that it receives from the start to the end of the connection.
+// It produces a single table for everything that it receives from the start to the end of the connection,
+// unless the query is executed continuously. Then the line based decoders decode the input as it arrives
+// and the source advances the watermark to the latest time it has read.
 package socket
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"io"
+	"math"
 	"net"
 	neturl "net/url"
 	"strings"
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow/ipc"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/execute"
@@ -46,7 +51,7 @@ func (a *nowTimeProvider) CurrentTime() values.Time {
 }
 
 var (
-	decoders = []string{"csv", "line"}
+	decoders = []string{"csv", "line", "arrow", "lineprotocol"}
 	schemes  = []string{"tcp", "unix"}
 )
 
@@ -168,6 +173,12 @@ func NewSocketSource(spec *FromSocketProcedureSpec, rc io.ReadCloser, tp line.Ti
 			Separator:    '\n',
 			TimeProvider: tp,
 		})
+	case "arrow":
+		decoder = ipc.NewResultDecoder(ipc.ResultDecoderConfig{})
+	case "lineprotocol":
+		decoder = line.NewProtocolDecoder(&line.ProtocolDecoderConfig{
+			TimeProvider: tp,
+		})
 	}
 
 	if decoder == nil {
@@ -175,18 +186,20 @@ func NewSocketSource(spec *FromSocketProcedureSpec, rc io.ReadCloser, tp line.Ti
 	}
 
 	return &socketSource{
-		d:       dsid,
-		rc:      rc,
-		decoder: decoder,
+		d:           dsid,
+		rc:          rc,
+		decoder:     decoder,
+		decoderName: spec.Decoder,
 	}, nil
 }
 
 type socketSource struct {
 	execute.ExecutionNode
-	d       execute.DatasetID
-	rc      io.ReadCloser
-	decoder flux.ResultDecoder
-	ts      []execute.Transformation
+	d           execute.DatasetID
+	rc          io.ReadCloser
+	decoder     flux.ResultDecoder
+	decoderName string
+	ts          []execute.Transformation
 }
 
 func (ss *socketSource) AddTransformation(t execute.Transformation) {
@@ -194,22 +207,126 @@ func (ss *socketSource) AddTransformation(t execute.Transformation) {
 }
 
 func (ss *socketSource) Run(ctx context.Context) {
-	defer ss.rc.Close()
-	result, err := ss.decoder.Decode(ss.rc)
-	if err != nil {
-		err = errors.Wrap(err, codes.Inherit, "decode error")
+	var err error
+	if execute.IsContinuousExecution(ctx) {
+		err = ss.stream(ctx)
 	} else {
-		err = result.Tables().Do(func(tbl flux.Table) error {
-			for _, t := range ss.ts {
-				if err := t.Process(ss.d, tbl); err != nil {
+		err = ss.decode(ss.rc)
+	}
+	_ = ss.rc.Close()
+
+	for _, t := range ss.ts {
+		t.Finish(ss.d, err)
+	}
+}
+
+// decode decodes r and processes the tables.
+func (ss *socketSource) decode(r io.Reader) error {
+	result, err := ss.decoder.Decode(r)
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "decode error")
+	}
+	return result.Tables().Do(func(tbl flux.Table) error {
+		for _, t := range ss.ts {
+			if err := t.Process(ss.d, tbl); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stream decodes the input as it arrives until the connection is closed
+// or the query is cancelled. The lines that are read together are decoded
+// as a batch, after which the watermark advances to the latest time that
+// has been read and the processing time advances to the current time.
+// The input is expected to arrive in time order, so rows older than the
+// watermark are late and may be left out of the windows that have fired.
+func (ss *socketSource) stream(ctx context.Context) error {
+	if ss.decoderName != "line" && ss.decoderName != "lineprotocol" {
+		return errors.Newf(codes.Invalid, "decoder %s cannot be read continuously, must be one of [line lineprotocol]", ss.decoderName)
+	}
+
+	// Closing the connection unblocks the read once the query is cancelled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = ss.rc.Close()
+		case <-done:
+		}
+	}()
+
+	var (
+		br        = bufio.NewReader(ss.rc)
+		batch     bytes.Buffer
+		watermark = execute.Time(math.MinInt64)
+	)
+	for {
+		l, err := br.ReadBytes('\n')
+		batch.Write(l)
+		if err != nil && err != io.EOF {
+			if ctx.Err() != nil {
+				// The query was cancelled.
+				return nil
+			}
+			return errors.Wrap(err, codes.Inherit, "error reading from socket")
+		}
+		if err == io.EOF || br.Buffered() == 0 {
+			if batch.Len() > 0 {
+				if err := ss.processBatch(&batch, &watermark); err != nil {
 					return err
 				}
+				batch.Reset()
 			}
+		}
+		if err == io.EOF {
 			return nil
-		})
+		}
 	}
+}
 
+// processBatch decodes and processes a batch of lines and then advances
+// the watermark and the processing time of the transformations.
+func (ss *socketSource) processBatch(r io.Reader, watermark *execute.Time) error {
+	result, err := ss.decoder.Decode(r)
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "decode error")
+	}
+	if err := result.Tables().Do(func(tbl flux.Table) error {
+		buffered, err := execute.CopyTable(tbl)
+		if err != nil {
+			return err
+		}
+		if j := execute.ColIdx(execute.DefaultTimeColLabel, buffered.Cols()); j >= 0 && buffered.Cols()[j].Type == flux.TTime {
+			for i, n := 0, buffered.BufferN(); i < n; i++ {
+				times := buffered.Buffer(i).Times(j)
+				for k := 0; k < times.Len(); k++ {
+					if times.IsValid(k) && execute.Time(times.Value(k)) > *watermark {
+						*watermark = execute.Time(times.Value(k))
+					}
+				}
+			}
+		}
+		for _, t := range ss.ts {
+			if err := t.Process(ss.d, buffered); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	now := values.ConvertTime(time.Now())
 	for _, t := range ss.ts {
-		t.Finish(ss.d, err)
+		if err := t.UpdateWatermark(ss.d, *watermark); err != nil {
+			return err
+		}
+		if err := t.UpdateProcessingTime(ss.d, now); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -3,18 +3,26 @@ package socket_test
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow/ipc"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/mock"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/stdlib/socket"
 	"github.com/influxdata/flux/stdlib/universe"
 
@@ -205,6 +213,53 @@ source
 				},
 			},
 		},
+		{
+			name: "arrow",
+			spec: &socket.FromSocketProcedureSpec{Decoder: "arrow"},
+			input: mustEncodeArrow(&executetest.Table{
+				KeyCols: []string{"tag1"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "tag1", Type: flux.TString},
+					{Label: "double", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a", 0.42},
+					{execute.Time(1), "a", nil},
+				},
+			}),
+			want: []*executetest.Table{{
+				KeyCols: []string{"tag1"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "tag1", Type: flux.TString},
+					{Label: "double", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a", 0.42},
+					{execute.Time(1), "a", nil},
+				},
+			}},
+		},
+		{
+			name:  "lineprotocol",
+			spec:  &socket.FromSocketProcedureSpec{Decoder: "lineprotocol"},
+			input: "cpu,host=a usage=0.42 1\ncpu,host=a usage=0.1\n",
+			want: []*executetest.Table{{
+				KeyCols: []string{"_field", "_measurement", "host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "_field", Type: flux.TString},
+					{Label: "_measurement", Type: flux.TString},
+					{Label: "host", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 0.42, "usage", "cpu", "a"},
+					{execute.Time(0), 0.1, "usage", "cpu", "a"},
+				},
+			}},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -243,3 +298,155 @@ source
 		})
 	}
 }
+
+// mustEncodeArrow encodes the tables as a single result in the Arrow IPC format.
+func mustEncodeArrow(tables ...*executetest.Table) string {
+	var buf bytes.Buffer
+	result := &executetest.Result{Nm: "_result", Tbls: tables}
+	if _, err := ipc.NewResultEncoder(ipc.ResultEncoderConfig{}).Encode(&buf, result); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+func TestFromSocket_Continuous(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conns := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}()
+
+	script := fmt.Sprintf(`import "socket"
+socket.from(url: "tcp://%s", decoder: "lineprotocol")
+	|> range(start: 0)
+	|> window(every: 10ns)
+	|> sum()`, l.Addr())
+	prog, err := lang.Compile(script, runtime.Default, time.Now(), lang.WithContinuousExecution())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(dependenciestest.Default().Inject(context.Background()))
+	defer cancel()
+	q, err := prog.Start(ctx, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Done()
+
+	var conn net.Conn
+	select {
+	case conn = <-conns:
+		defer conn.Close()
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the connection")
+	}
+
+	// nextSums reads the start of the windows and the sums of the next pane.
+	nextSums := func() map[execute.Time]float64 {
+		t.Helper()
+		select {
+		case res, ok := <-q.Results():
+			if !ok {
+				t.Fatalf("unexpected end of the results: %v", q.Err())
+			}
+			sums := make(map[execute.Time]float64)
+			if err := res.Tables().Do(func(tbl flux.Table) error {
+				return tbl.Do(func(cr flux.ColReader) error {
+					start := execute.ColIdx("_start", cr.Cols())
+					value := execute.ColIdx("_value", cr.Cols())
+					for i := 0; i < cr.Len(); i++ {
+						sums[execute.Time(cr.Times(start).Value(i))] = cr.Floats(value).Value(i)
+					}
+					return nil
+				})
+			}); err != nil {
+				t.Fatal(err)
+			}
+			return sums
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for a pane")
+			return nil
+		}
+	}
+
+	// A window is emitted once a later point is read
+	// while the connection stays open.
+	if _, err := io.WriteString(conn, "cpu v=1 1\ncpu v=2 2\ncpu v=3 12\n"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := map[execute.Time]float64{0: 3}, nextSums(); !cmp.Equal(want, got) {
+		t.Errorf("unexpected first pane -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	if _, err := io.WriteString(conn, "cpu v=4 25\n"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := map[execute.Time]float64{10: 3}, nextSums(); !cmp.Equal(want, got) {
+		t.Errorf("unexpected second pane -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	// Cancelling the query ends the results without an error.
+	q.Cancel()
+	for range q.Results() {
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFromSocketSource_RunContinuous(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		decoder       string
+		input         string
+		wantWatermark []execute.Time
+		wantErr       bool
+	}{
+		{
+			name:          "lineprotocol",
+			decoder:       "lineprotocol",
+			input:         "cpu v=1 12\ncpu v=2 5\n",
+			wantWatermark: []execute.Time{12},
+		},
+		{
+			name:    "csv",
+			decoder: "csv",
+			input:   "a,b\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			id := executetest.RandomDatasetID()
+			d := executetest.NewDataset(id)
+			c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+			c.SetTriggerSpec(plan.DefaultTriggerSpec)
+			r := ioutil.NopCloser(bytes.NewReader([]byte(tc.input)))
+			ss, err := socket.NewSocketSource(&socket.FromSocketProcedureSpec{Decoder: tc.decoder}, r, &mock.AscendingTimeProvider{}, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ss.AddTransformation(executetest.NewYieldTransformation(d, c))
+			ss.Run(execute.WithContinuousExecution(context.Background()))
+
+			if tc.wantErr {
+				if d.FinishedErr == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			} else if d.FinishedErr != nil {
+				t.Fatal(d.FinishedErr)
+			}
+			if !cmp.Equal(tc.wantWatermark, d.WatermarkUpdates) {
+				t.Errorf("unexpected watermarks -want/+got:\n%s", cmp.Diff(tc.wantWatermark, d.WatermarkUpdates))
+			}
+		})
+	}
+}
@@ -163,7 +163,7 @@ var fluxToBigQuery = map[flux.ColType]string{
 }
 
 // BigQueryTranslateColumn translates flux colTypes into their corresponding BigQuery column type
-func BigQueryColumnTranslateFunc() translationFunc {
+func BigQueryColumnTranslateFunc() TranslationFunc {
 	return func(f flux.ColType, colName string) (string, error) {
 		s, found := fluxToBigQuery[f]
 		if !found {
@@ -0,0 +1,226 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	_ "github.com/ClickHouse/clickhouse-go"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// ClickHouseRowReader reads the rows returned by the ClickHouse driver.
+// The driver scans integers and floats into Go types of the same width
+// as the ClickHouse type, so they are widened to the flux types.
+type ClickHouseRowReader struct {
+	Cursor      *sql.Rows
+	columns     []interface{}
+	columnTypes []flux.ColType
+	columnNames []string
+}
+
+func (m *ClickHouseRowReader) Next() bool {
+	next := m.Cursor.Next()
+	if next {
+		m.columns = make([]interface{}, len(m.columnNames))
+		columnPointers := make([]interface{}, len(m.columnNames))
+		for i := 0; i < len(m.columnNames); i++ {
+			columnPointers[i] = &m.columns[i]
+		}
+		if err := m.Cursor.Scan(columnPointers...); err != nil {
+			return false
+		}
+	}
+	return next
+}
+
+func (m *ClickHouseRowReader) GetNextRow() ([]values.Value, error) {
+	row := make([]values.Value, len(m.columns))
+	for i, col := range m.columns {
+		switch col := col.(type) {
+		case bool, uint64, float64, string:
+			row[i] = values.New(col)
+		case int64:
+			// Values may have been converted to int64 by database/sql.
+			switch m.columnTypes[i] {
+			case flux.TUInt:
+				row[i] = values.NewUInt(uint64(col))
+			case flux.TBool:
+				row[i] = values.NewBool(col != 0)
+			default:
+				row[i] = values.NewInt(col)
+			}
+		case int8:
+			row[i] = values.NewInt(int64(col))
+		case int16:
+			row[i] = values.NewInt(int64(col))
+		case int32:
+			row[i] = values.NewInt(int64(col))
+		case int:
+			row[i] = values.NewInt(int64(col))
+		case uint8:
+			if m.columnTypes[i] == flux.TBool {
+				// Older servers store booleans as UInt8.
+				row[i] = values.NewBool(col != 0)
+			} else {
+				row[i] = values.NewUInt(uint64(col))
+			}
+		case uint16:
+			row[i] = values.NewUInt(uint64(col))
+		case uint32:
+			row[i] = values.NewUInt(uint64(col))
+		case float32:
+			row[i] = values.NewFloat(float64(col))
+		case []byte:
+			row[i] = values.NewString(string(col))
+		case time.Time:
+			row[i] = values.NewTime(values.ConvertTime(col))
+		case nil:
+			row[i] = values.NewNull(flux.SemanticType(m.columnTypes[i]))
+		case fmt.Stringer:
+			// Decimal, UUID and IP values.
+			row[i] = values.NewString(col.String())
+		default:
+			return nil, errors.Newf(codes.Internal, "ClickHouse column %s has unsupported value type %T", m.columnNames[i], col)
+		}
+	}
+	return row, nil
+}
+
+func (m *ClickHouseRowReader) InitColumnNames(n []string) {
+	m.columnNames = n
+}
+
+func (m *ClickHouseRowReader) InitColumnTypes(types []*sql.ColumnType) {
+	stringTypes := make([]flux.ColType, len(types))
+	for i := 0; i < len(types); i++ {
+		stringTypes[i] = clickhouseColumnType(types[i].DatabaseTypeName())
+	}
+	m.columnTypes = stringTypes
+}
+
+// clickhouseColumnType returns the flux type for a ClickHouse type name
+// such as Nullable(Int32) or DateTime64(3, 'UTC').
+func clickhouseColumnType(name string) flux.ColType {
+	// Nullable and LowCardinality do not change how the values are read.
+	for _, wrapper := range []string{"LowCardinality(", "Nullable("} {
+		if strings.HasPrefix(name, wrapper) && strings.HasSuffix(name, ")") {
+			name = name[len(wrapper) : len(name)-1]
+		}
+	}
+	// Remove parameters such as the precision and time zone.
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+
+	switch name {
+	case "Int8", "Int16", "Int32", "Int64":
+		return flux.TInt
+	case "UInt8", "UInt16", "UInt32", "UInt64":
+		return flux.TUInt
+	case "Float32", "Float64":
+		return flux.TFloat
+	case "Bool", "Boolean":
+		return flux.TBool
+	case "Date", "Date32", "DateTime", "DateTime64":
+		return flux.TTime
+	default:
+		return flux.TString
+	}
+}
+
+func (m *ClickHouseRowReader) ColumnNames() []string {
+	return m.columnNames
+}
+
+func (m *ClickHouseRowReader) ColumnTypes() []flux.ColType {
+	return m.columnTypes
+}
+
+func (m *ClickHouseRowReader) SetColumns(i []interface{}) {
+	m.columns = i
+}
+
+func (m *ClickHouseRowReader) Close() error {
+	if err := m.Cursor.Err(); err != nil {
+		return err
+	}
+	return m.Cursor.Close()
+}
+
+func NewClickHouseRowReader(r *sql.Rows) (execute.RowReader, error) {
+	reader := &ClickHouseRowReader{
+		Cursor: r,
+	}
+	cols, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	reader.InitColumnNames(cols)
+
+	types, err := r.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	reader.InitColumnTypes(types)
+	return reader, nil
+}
+
+var fluxToClickHouse = map[flux.ColType]string{
+	flux.TFloat:  "Float64",
+	flux.TInt:    "Int64",
+	flux.TUInt:   "UInt64",
+	flux.TString: "String",
+	flux.TBool:   "Bool",
+	flux.TTime:   "DateTime64(9)",
+}
+
+// ClickHouseColumnTranslateFunc translates flux colTypes into their corresponding ClickHouse column type.
+// All columns are nullable as flux columns may contain nulls.
+func ClickHouseColumnTranslateFunc() TranslationFunc {
+	return func(f flux.ColType, colName string) (string, error) {
+		s, found := fluxToClickHouse[f]
+		if !found {
+			return "", errors.Newf(codes.Invalid, "ClickHouse does not support column type %s", f.String())
+		}
+		return colName + " Nullable(" + s + ")", nil
+	}
+}
+
+// clickhouseCreateTableStatement creates the table if it does not exist.
+// ClickHouse requires a table engine, so the table uses a MergeTree
+// without a sorting key.
+func clickhouseCreateTableStatement(table string, columns []string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = MergeTree() ORDER BY tuple()", table, strings.Join(columns, ","))
+}
+
+// parseClickHouseDataSource parses the data source of the ClickHouse driver.
+// The driver speaks the native protocol and reads the credentials and the
+// database from the query, for example:
+// tcp://localhost:9000?username=user&password=pass&database=db
+func parseClickHouseDataSource(dataSourceName string) (*neturl.URL, error) {
+	u, err := neturl.Parse(dataSourceName)
+	if err != nil {
+		return nil, errors.Newf(codes.Invalid, "invalid data source url: %v", err)
+	}
+	switch u.Scheme {
+	case "tcp":
+	default:
+		return nil, errors.Newf(codes.Invalid, "invalid data source url: unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.Newf(codes.Invalid, "invalid data source url: %v", "missing host")
+	}
+	if u.User == nil {
+		if params := u.Query(); params.Get("username") != "" {
+			u.User = neturl.UserPassword(params.Get("username"), params.Get("password"))
+		}
+	}
+	return u, nil
+}
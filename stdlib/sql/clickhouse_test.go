@@ -0,0 +1,301 @@
+package sql
+
+import (
+	"database/sql"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/values"
+)
+
+func TestClickHouseColumnType(t *testing.T) {
+	for name, want := range map[string]flux.ColType{
+		"Int8":                             flux.TInt,
+		"Int64":                            flux.TInt,
+		"Nullable(Int32)":                  flux.TInt,
+		"UInt8":                            flux.TUInt,
+		"UInt64":                           flux.TUInt,
+		"Float32":                          flux.TFloat,
+		"Nullable(Float64)":                flux.TFloat,
+		"Bool":                             flux.TBool,
+		"Date":                             flux.TTime,
+		"DateTime('UTC')":                  flux.TTime,
+		"Nullable(DateTime64(9, 'UTC'))":   flux.TTime,
+		"String":                           flux.TString,
+		"LowCardinality(String)":           flux.TString,
+		"LowCardinality(Nullable(String))": flux.TString,
+		"FixedString(16)":                  flux.TString,
+		"Decimal(10, 2)":                   flux.TString,
+		"UUID":                             flux.TString,
+	} {
+		if got := clickhouseColumnType(name); got != want {
+			t.Errorf("unexpected type for %s -want/+got:\n\t- %v\n\t+ %v", name, want, got)
+		}
+	}
+}
+
+func TestClickHouseParsing(t *testing.T) {
+	ts := createTestTimes()[0].(time.Time)
+	rows := mockRowsToSQLRows(sqlmock.NewRows([]string{"i8", "i32", "u8", "u32", "f32", "f64", "s", "b", "t"}).
+		AddRow(int8(-1), int32(42), uint8(1), uint32(7), float32(1.5), float64(2.5), "a", true, ts).
+		AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+	reader, err := NewClickHouseRowReader(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sqlmock does not report database type names so set the types
+	// that the ClickHouse driver would report for the columns.
+	reader.(*ClickHouseRowReader).columnTypes = []flux.ColType{
+		flux.TInt, flux.TInt, flux.TBool, flux.TUInt, flux.TFloat, flux.TFloat, flux.TString, flux.TBool, flux.TTime,
+	}
+
+	want := [][]values.Value{
+		{
+			values.NewInt(-1), values.NewInt(42), values.NewBool(true), values.NewUInt(7),
+			values.NewFloat(1.5), values.NewFloat(2.5), values.NewString("a"), values.NewBool(true),
+			values.NewTime(values.ConvertTime(ts)),
+		},
+		{
+			values.NewNull(flux.SemanticType(flux.TInt)), values.NewNull(flux.SemanticType(flux.TInt)),
+			values.NewNull(flux.SemanticType(flux.TBool)), values.NewNull(flux.SemanticType(flux.TUInt)),
+			values.NewNull(flux.SemanticType(flux.TFloat)), values.NewNull(flux.SemanticType(flux.TFloat)),
+			values.NewNull(flux.SemanticType(flux.TString)), values.NewNull(flux.SemanticType(flux.TBool)),
+			values.NewNull(flux.SemanticType(flux.TTime)),
+		},
+	}
+	var got [][]values.Value
+	for reader.Next() {
+		row, err := reader.GetNextRow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected number of rows -want/+got:\n\t- %d\n\t+ %d", len(want), len(got))
+	}
+	for i := range want {
+		for j := range want[i] {
+			if values.DisplayString(want[i][j]) != values.DisplayString(got[i][j]) ||
+				want[i][j].Type() != got[i][j].Type() {
+				t.Errorf("unexpected value in row %d column %d -want/+got:\n\t- %v\n\t+ %v", i, j, want[i][j], got[i][j])
+			}
+		}
+	}
+}
+
+func TestClickHouseTranslation(t *testing.T) {
+	clickhouseTypeTranslations := map[string]flux.ColType{
+		"Nullable(Float64)":       flux.TFloat,
+		"Nullable(Int64)":         flux.TInt,
+		"Nullable(UInt64)":        flux.TUInt,
+		"Nullable(String)":        flux.TString,
+		"Nullable(DateTime64(9))": flux.TTime,
+		"Nullable(Bool)":          flux.TBool,
+	}
+
+	columnLabel := "apples"
+	sqlT, err := getTranslationFunc("clickhouse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for dbTypeString, fluxType := range clickhouseTypeTranslations {
+		v, err := sqlT()(fluxType, columnLabel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := columnLabel + " " + dbTypeString; want != v {
+			t.Errorf("unexpected column -want/+got:\n\t- %s\n\t+ %s", want, v)
+		}
+	}
+
+	_, err = sqlT()(unsupportedType, columnLabel)
+	if err == nil {
+		t.Fatal("expected error")
+	} else if want := "ClickHouse does not support column type unknown"; want != err.Error() {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, err.Error())
+	}
+
+	d, err := lookupDriver("clickhouse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE IF NOT EXISTS t (a Nullable(Int64),b Nullable(String)) ENGINE = MergeTree() ORDER BY tuple()"
+	if got := d.createTableStatement("t", []string{"a Nullable(Int64)", "b Nullable(String)"}); want != got {
+		t.Errorf("unexpected create table statement -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestClickHouse_Connect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	// The server hangs up on the client so the connection fails
+	// once the driver has dialed the host of the data source.
+	accepted := make(chan struct{}, 1)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+			select {
+			case accepted <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	d, err := lookupDriver("clickhouse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := d.open("clickhouse", "tcp://"+l.Addr().String()+"?username=user&password=pass&database=db&timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if err := db.Ping(); err == nil {
+		t.Fatal("expected error")
+	}
+	select {
+	case <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the driver did not connect to the server")
+	}
+}
+
+func TestClickHouse_BatchInsert(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// The rows are inserted with a single prepared
+	// statement and sent as a batch on commit.
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("INSERT INTO t (a,b) VALUES (?,?)")
+	prep.ExpectExec().WithArgs(int64(1), "x").WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs(int64(2), "y").WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs(nil, "z").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := &ToSQLOpSpec{DriverName: "clickhouse", DataSourceName: "tcp://localhost:9000", Table: "t"}
+	valueStrings := []string{"(?,?)", "(?,?)", "(?,?)"}
+	valueArgs := []interface{}{int64(1), "x", int64(2), "y", nil, "z"}
+	if err := ExecuteQueries(tx, spec, []string{"a", "b"}, &valueStrings, &valueArgs); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteQueries_Placeholders(t *testing.T) {
+	for _, tc := range []struct {
+		driver string
+		want   string
+	}{
+		{driver: "mysql", want: "INSERT INTO t (a,b) VALUES (?,?),(?,?)"},
+		{driver: "postgres", want: "INSERT INTO t (a,b) VALUES ($1,$2),($3,$4)"},
+		{driver: "sqlserver", want: "INSERT INTO t (a,b) VALUES (@p1,@p2),(@p3,@p4)"},
+	} {
+		tc := tc
+		t.Run(tc.driver, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = db.Close() }()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(tc.want).WithArgs(int64(1), "x", int64(2), "y").WillReturnResult(sqlmock.NewResult(0, 2))
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := &ToSQLOpSpec{DriverName: tc.driver, Table: "t"}
+			valueStrings := []string{"(?,?)", "(?,?)"}
+			valueArgs := []interface{}{int64(1), "x", int64(2), "y"}
+			if err := ExecuteQueries(tx, spec, []string{"a", "b"}, &valueStrings, &valueArgs); err != nil {
+				t.Fatal(err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRegisterDriver(t *testing.T) {
+	RegisterDriver("testdriver", Driver{
+		NewRowReader:        NewPostgresRowReader,
+		ParseDataSource:     parseURLDataSource,
+		ColumnTranslateFunc: PostgresColumnTranslateFunc,
+		Open: func(driverName, dataSourceName string) (*sql.DB, error) {
+			db, _, err := sqlmock.New()
+			return db, err
+		},
+	})
+	defer func() {
+		drivers.Lock()
+		delete(drivers.m, "testdriver")
+		drivers.Unlock()
+	}()
+
+	if _, err := getTranslationFunc("testdriver"); err != nil {
+		t.Fatal(err)
+	}
+	db, err := getOpenFunc("testdriver", "testdriver://localhost")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	found := false
+	for _, name := range Drivers() {
+		found = found || name == "testdriver"
+	}
+	if !found {
+		t.Errorf("expected testdriver in %v", Drivers())
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected duplicate registration to panic")
+			}
+		}()
+		RegisterDriver("testdriver", Driver{
+			NewRowReader:    NewPostgresRowReader,
+			ParseDataSource: parseURLDataSource,
+		})
+	}()
+
+	// Drivers without a translation function are read-only.
+	_, err = getTranslationFunc("awsathena")
+	if want := "writing is not supported for awsathena"; err == nil || !cmp.Equal(want, err.Error()) {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %v", want, err)
+	}
+}
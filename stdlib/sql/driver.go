@@ -0,0 +1,218 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// Driver describes how sql.from and sql.to use a database/sql driver.
+//
+// Only NewRowReader and ParseDataSource are required. The remaining
+// functions customize how sql.to writes to the database.
+type Driver struct {
+	// NewRowReader creates the reader for the rows returned by a query.
+	NewRowReader func(rows *sql.Rows) (execute.RowReader, error)
+
+	// ParseDataSource parses the data source name into the URL
+	// that is checked by the URL validator of the query.
+	// A nil URL means the data source does not need to be validated.
+	ParseDataSource func(dataSourceName string) (*neturl.URL, error)
+
+	// ColumnTranslateFunc returns the function that translates a flux column
+	// into a column definition for the database.
+	// Drivers without it cannot be written to with sql.to.
+	ColumnTranslateFunc func() TranslationFunc
+
+	// Open opens the database.
+	// It defaults to calling sql.Open with the driver and data source names.
+	Open func(driverName, dataSourceName string) (*sql.DB, error)
+
+	// CreateTableStatement returns the statement that creates the table
+	// with the column definitions if it does not already exist.
+	// It defaults to CREATE TABLE IF NOT EXISTS.
+	CreateTableStatement func(table string, columns []string) string
+
+	// Placeholder returns the placeholder for the nth parameter of a
+	// statement, starting at 1. It defaults to a question mark.
+	Placeholder func(n int) string
+
+	// InsertStatement returns the statement that inserts the rows of values.
+	// Each row is a parenthesized list of placeholders.
+	// It defaults to a multi-row INSERT INTO statement.
+	InsertStatement func(spec *ToSQLOpSpec, columns []string, rows []string) string
+
+	// MaxBatchRows is the maximum number of rows that are inserted
+	// by a single statement. Zero means that there is no limit.
+	MaxBatchRows int
+
+	// PrepareInsert inserts each row with a prepared single row
+	// statement instead of a multi-row statement. Drivers such as
+	// ClickHouse send the rows inserted by a prepared statement as
+	// a single batch when the transaction is committed.
+	PrepareInsert bool
+}
+
+var drivers = struct {
+	sync.RWMutex
+	m map[string]*Driver
+}{m: make(map[string]*Driver)}
+
+// RegisterDriver registers the driver used by sql.from and sql.to
+// for the driver name. It panics if the name is already registered
+// or if the driver is missing a required function.
+//
+// The database/sql driver with the same name must also be registered
+// with the database/sql package, usually by importing its package.
+func RegisterDriver(name string, d Driver) {
+	if d.NewRowReader == nil || d.ParseDataSource == nil {
+		panic(fmt.Sprintf("sql driver %s must have a row reader and a data source parser", name))
+	}
+
+	drivers.Lock()
+	defer drivers.Unlock()
+	if _, ok := drivers.m[name]; ok {
+		panic(fmt.Sprintf("duplicate registration for sql driver %s", name))
+	}
+	drivers.m[name] = &d
+}
+
+// Drivers returns the names of the registered drivers.
+func Drivers() []string {
+	drivers.RLock()
+	defer drivers.RUnlock()
+	names := make([]string, 0, len(drivers.m))
+	for name := range drivers.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupDriver(name string) (*Driver, error) {
+	drivers.RLock()
+	defer drivers.RUnlock()
+	d, ok := drivers.m[name]
+	if !ok {
+		return nil, errors.Newf(codes.Invalid, "sql driver %s not supported", name)
+	}
+	return d, nil
+}
+
+func (d *Driver) open(driverName, dataSourceName string) (*sql.DB, error) {
+	if d.Open != nil {
+		return d.Open(driverName, dataSourceName)
+	}
+	return defaultOpenFunction(driverName, dataSourceName)()
+}
+
+func (d *Driver) createTableStatement(table string, columns []string) string {
+	if d.CreateTableStatement != nil {
+		return d.CreateTableStatement(table, columns)
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(columns, ","))
+}
+
+// valuePlaceholders returns the placeholders for the
+// values of the rows, each enclosed in parentheses.
+func (d *Driver) valuePlaceholders(rows, columns int) []string {
+	placeholders := make([]string, rows)
+	row := make([]string, columns)
+	for i := range placeholders {
+		for j := range row {
+			if d.Placeholder != nil {
+				row[j] = d.Placeholder(i*columns + j + 1)
+			} else {
+				row[j] = "?"
+			}
+		}
+		placeholders[i] = "(" + strings.Join(row, ",") + ")"
+	}
+	return placeholders
+}
+
+func (d *Driver) insertStatement(spec *ToSQLOpSpec, columns []string, rows []string) string {
+	if d.InsertStatement != nil {
+		return d.InsertStatement(spec, columns, rows)
+	}
+	return defaultInsertStatement(spec, columns, rows)
+}
+
+func defaultInsertStatement(spec *ToSQLOpSpec, columns []string, rows []string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", spec.Table, strings.Join(columns, ","), strings.Join(rows, ","))
+}
+
+func init() {
+	RegisterDriver("mysql", Driver{
+		NewRowReader:        NewMySQLRowReader,
+		ParseDataSource:     parseMySQLDataSource,
+		ColumnTranslateFunc: MysqlColumnTranslateFunc,
+	})
+	RegisterDriver("sqlite3", Driver{
+		NewRowReader:        NewSqliteRowReader,
+		ParseDataSource:     parseSqliteDataSource,
+		ColumnTranslateFunc: SqliteColumnTranslateFunc,
+	})
+	postgres := Driver{
+		NewRowReader:        NewPostgresRowReader,
+		ParseDataSource:     parseURLDataSource,
+		ColumnTranslateFunc: PostgresColumnTranslateFunc,
+		// PostgreSQL uses $n instead of ? for placeholders
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	}
+	RegisterDriver("postgres", postgres)
+	// sqlmock is used for testing and reads like postgres
+	// but uses the default placeholders.
+	sqlmock := postgres
+	sqlmock.Placeholder = nil
+	RegisterDriver("sqlmock", sqlmock)
+	RegisterDriver("snowflake", Driver{
+		NewRowReader:        NewSnowflakeRowReader,
+		ParseDataSource:     parseSnowflakeDataSource,
+		ColumnTranslateFunc: SnowflakeColumnTranslateFunc,
+	})
+	mssql := Driver{
+		NewRowReader:         NewMssqlRowReader,
+		ParseDataSource:      parseMssqlDataSource,
+		ColumnTranslateFunc:  MssqlColumnTranslateFunc,
+		Open:                 mssqlOpen,
+		CreateTableStatement: mssqlCreateTableStatement,
+		// SQLServer uses @p instead of ? for placeholders
+		Placeholder:     func(n int) string { return fmt.Sprintf("@p%d", n) },
+		InsertStatement: mssqlInsertStatement,
+	}
+	RegisterDriver("mssql", mssql)
+	RegisterDriver("sqlserver", mssql)
+	// read-only support for AWS Athena (see awsathena.go)
+	RegisterDriver("awsathena", Driver{
+		NewRowReader:    NewAwsAthenaRowReader,
+		ParseDataSource: parseURLDataSource,
+	})
+	RegisterDriver("bigquery", Driver{
+		NewRowReader:        NewBigQueryRowReader,
+		ParseDataSource:     parseBigQueryDataSource,
+		ColumnTranslateFunc: BigQueryColumnTranslateFunc,
+	})
+	RegisterDriver("hdb", Driver{
+		NewRowReader:         NewHdbRowReader,
+		ParseDataSource:      parseURLDataSource,
+		ColumnTranslateFunc:  HdbColumnTranslateFunc,
+		CreateTableStatement: hdbCreateTableStatement,
+		// SAP HANA does not support INSERT/UPDATE batching via a single SQL command
+		MaxBatchRows: 1,
+	})
+	RegisterDriver("clickhouse", Driver{
+		NewRowReader:         NewClickHouseRowReader,
+		ParseDataSource:      parseClickHouseDataSource,
+		ColumnTranslateFunc:  ClickHouseColumnTranslateFunc,
+		CreateTableStatement: clickhouseCreateTableStatement,
+		PrepareInsert:        true,
+	})
+}
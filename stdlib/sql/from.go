@@ -111,27 +111,11 @@ func createFromSQLSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a ex
 	}
 
 	// Retrieve the row reader implementation for the driver.
-	var newRowReader func(rows *sql.Rows) (execute.RowReader, error)
-	switch spec.DriverName {
-	case "mysql":
-		newRowReader = NewMySQLRowReader
-	case "sqlite3":
-		newRowReader = NewSqliteRowReader
-	case "postgres", "sqlmock":
-		newRowReader = NewPostgresRowReader
-	case "snowflake":
-		newRowReader = NewSnowflakeRowReader
-	case "mssql", "sqlserver":
-		newRowReader = NewMssqlRowReader
-	case "awsathena":
-		newRowReader = NewAwsAthenaRowReader
-	case "bigquery":
-		newRowReader = NewBigQueryRowReader
-	case "hdb":
-		newRowReader = NewHdbRowReader
-	default:
-		return nil, errors.Newf(codes.Invalid, "sql driver %s not supported", spec.DriverName)
+	driver, err := lookupDriver(spec.DriverName)
+	if err != nil {
+		return nil, err
 	}
+	newRowReader := driver.NewRowReader
 
 	readFn := func(ctx context.Context, rows *sql.Rows) (flux.Table, error) {
 		reader, err := newRowReader(rows)
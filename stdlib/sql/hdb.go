@@ -183,7 +183,7 @@ var fluxToHdb = map[flux.ColType]string{
 }
 
 // HdbTranslateColumn translates flux colTypes into their corresponding SAP HANA column type
-func HdbColumnTranslateFunc() translationFunc {
+func HdbColumnTranslateFunc() TranslationFunc {
 	return func(f flux.ColType, colName string) (string, error) {
 		s, found := fluxToHdb[f]
 		if !found {
@@ -194,6 +194,14 @@ func HdbColumnTranslateFunc() translationFunc {
 	}
 }
 
+// hdbCreateTableStatement creates the table if it does not exist.
+// SAP HANA does not support IF NOT EXIST so the CREATE TABLE statement
+// is wrapped with an HDB-specific "if not exists" SQLScript check.
+func hdbCreateTableStatement(table string, columns []string) string {
+	q := fmt.Sprintf("CREATE TABLE %s (%s)", hdbEscapeName(table, true), strings.Join(columns, ","))
+	return hdbAddIfNotExist(table, q)
+}
+
 // Template for conditional query by table existence check
 var hdbDoIfTableNotExistsTemplate = `DO
 BEGIN
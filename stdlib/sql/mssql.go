@@ -169,7 +169,7 @@ var fluxToSQLServer = map[flux.ColType]string{
 }
 
 // MssqlTranslateColumn translates flux colTypes into their corresponding SQL Server column type
-func MssqlColumnTranslateFunc() translationFunc {
+func MssqlColumnTranslateFunc() TranslationFunc {
 	return func(f flux.ColType, colName string) (string, error) {
 		s, found := fluxToSQLServer[f]
 		if !found {
@@ -179,9 +179,22 @@ func MssqlColumnTranslateFunc() translationFunc {
 	}
 }
 
-// Checks if the driver is SQL Server.
-func isMssqlDriver(driverName string) bool {
-	return driverName == "mssql" || driverName == "sqlserver"
+// mssqlCreateTableStatement creates the table if it does not exist.
+// SQL Server does not support IF NOT EXIST.
+func mssqlCreateTableStatement(table string, columns []string) string {
+	return fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NULL BEGIN CREATE TABLE %s (%s) END", table, table, strings.Join(columns, ","))
+}
+
+// mssqlInsertStatement inserts the rows, enabling inserts into
+// identity columns when requested in the data source name.
+func mssqlInsertStatement(spec *ToSQLOpSpec, columns []string, rows []string) string {
+	query := defaultInsertStatement(spec, columns, rows)
+	if mssqlCheckParameter(spec.DataSourceName, mssqlIdentityInsertEnabled) {
+		prologue := fmt.Sprintf("DECLARE @tableHasIdentity INT = OBJECTPROPERTY(OBJECT_ID('%s'), 'TableHasIdentity'); IF @tableHasIdentity = 1 BEGIN SET IDENTITY_INSERT %s ON END", spec.Table, spec.Table)
+		epilogue := fmt.Sprintf("IF @tableHasIdentity = 1 BEGIN SET IDENTITY_INSERT %s OFF END", spec.Table)
+		query = strings.Join([]string{prologue, query, epilogue}, "; ")
+	}
+	return query
 }
 
 // Config is a (minimalistic) configuration parsed from a DSN string.
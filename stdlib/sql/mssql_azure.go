@@ -99,6 +99,11 @@ func mssqlSetAzureConfig(params neturl.Values, cfg *mssqlConfig) {
 	}
 }
 
+// mssqlOpen opens a SQL Server database, authenticating against Azure AD when requested.
+func mssqlOpen(driverName, dataSourceName string) (*sql.DB, error) {
+	return mssqlOpenFunction(driverName, dataSourceName)()
+}
+
 func mssqlOpenFunction(driverName, dataSourceName string) openFunc {
 	cfg, err := mssqlParseDSN(dataSourceName)
 	if err != nil {
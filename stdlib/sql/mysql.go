@@ -174,7 +174,7 @@ func NewMySQLRowReader(r *sql.Rows) (execute.RowReader, error) {
 }
 
 // MysqlTranslateColumn translates flux colTypes into their corresponding MySQL column type
-func MysqlColumnTranslateFunc() translationFunc {
+func MysqlColumnTranslateFunc() TranslationFunc {
 	c := map[string]string{
 		flux.TFloat.String():  "FLOAT",
 		flux.TInt.String():    "BIGINT",
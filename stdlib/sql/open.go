@@ -19,7 +19,7 @@ import (
 // db := sql.OpenDB(conn)
 //
 // When user requests Azure AD authentication in SQL server connection string,
-// `getOpenFunc` returns a function with body like the above example.
+// the `Open` function of the registered driver has a body like the above example.
 
 type openFunc func() (*sql.DB, error)
 
@@ -30,15 +30,14 @@ func defaultOpenFunction(driverName, dataSourceName string) openFunc {
 	}
 }
 
-// Returns function that opens DB connection. For databases other than SQL Server,
-// and for SQL server with SQL or Windows authentication, it simply returns `defaultOpenFunction`.
-// For Azure SQL Server with Azure AD authentication, it returns a function that authenticates
-// against Azure AD and uses connector with access token to open DB connection.
+// Returns function that opens DB connection using the `Open` function of the
+// registered driver. Drivers without one simply call `sql.Open(driverName, dataSourceName)`.
 func getOpenFunc(driverName, dataSourceName string) openFunc {
-	switch driverName {
-	case "mssql", "sqlserver":
-		return mssqlOpenFunction(driverName, dataSourceName)
-	default:
-		return defaultOpenFunction(driverName, dataSourceName)
+	return func() (*sql.DB, error) {
+		driver, err := lookupDriver(driverName)
+		if err != nil {
+			return nil, err
+		}
+		return driver.open(driverName, dataSourceName)
 	}
 }
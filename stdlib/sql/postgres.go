@@ -145,7 +145,7 @@ func NewPostgresRowReader(r *sql.Rows) (execute.RowReader, error) {
 }
 
 // PostgresTranslateColumn translates flux colTypes into their corresponding postgres column type
-func PostgresColumnTranslateFunc() translationFunc {
+func PostgresColumnTranslateFunc() TranslationFunc {
 	c := map[string]string{
 		flux.TFloat.String():  "FLOAT",
 		flux.TInt.String():    "BIGINT",
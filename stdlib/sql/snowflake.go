@@ -191,7 +191,7 @@ var fluxToSnowflake = map[flux.ColType]string{
 }
 
 // SnowflakeTranslateColumn translates flux colTypes into their corresponding Snowflake column type
-func SnowflakeColumnTranslateFunc() translationFunc {
+func SnowflakeColumnTranslateFunc() TranslationFunc {
 	return func(f flux.ColType, colName string) (string, error) {
 		s, found := fluxToSnowflake[f]
 		if !found {
@@ -150,7 +150,7 @@ func NewSqliteRowReader(r *sql.Rows) (execute.RowReader, error) {
 }
 
 // SqliteTranslateColumn translates flux colTypes into their corresponding SQLite column type
-func SqliteColumnTranslateFunc() translationFunc {
+func SqliteColumnTranslateFunc() TranslationFunc {
 	c := map[string]string{
 		flux.TFloat.String():  "FLOAT",
 		flux.TInt.String():    "INT",
@@ -223,7 +223,9 @@ func (t *ToSQLTransformation) Finish(id execute.DatasetID, err error) {
 	t.d.Finish(err)
 }
 
-type translationFunc func(f flux.ColType, colname string) (string, error)
+// TranslationFunc translates a flux column into the definition
+// of the column in the database.
+type TranslationFunc func(f flux.ColType, colname string) (string, error)
 
 func correctBatchSize(batchSize, numberCols int) int {
 	/*
@@ -258,28 +260,17 @@ func correctBatchSize(batchSize, numberCols int) int {
 	return (batchSize - numberCols) / numberCols
 }
 
-func getTranslationFunc(driverName string) (func() translationFunc, error) {
-	// simply return the translationFunc that corresponds to the driver type
-	switch driverName {
-	case "sqlite3":
-		return SqliteColumnTranslateFunc, nil
-	case "postgres", "sqlmock":
-		return PostgresColumnTranslateFunc, nil
-	case "mysql":
-		return MysqlColumnTranslateFunc, nil
-	case "snowflake":
-		return SnowflakeColumnTranslateFunc, nil
-	case "mssql", "sqlserver":
-		return MssqlColumnTranslateFunc, nil
-	case "awsathena": // read-only support for AWS Athena (see awsathena.go)
-		return nil, errors.Newf(codes.Invalid, "writing is not supported for %s", driverName)
-	case "bigquery":
-		return BigQueryColumnTranslateFunc, nil
-	case "hdb":
-		return HdbColumnTranslateFunc, nil
-	default:
+func getTranslationFunc(driverName string) (func() TranslationFunc, error) {
+	// simply return the TranslationFunc that corresponds to the driver type
+	driver, err := lookupDriver(driverName)
+	if err != nil {
 		return nil, errors.Newf(codes.Internal, "invalid driverName: %s", driverName)
 	}
+	if driver.ColumnTranslateFunc == nil {
+		// read-only drivers such as AWS Athena (see awsathena.go)
+		return nil, errors.Newf(codes.Invalid, "writing is not supported for %s", driverName)
+	}
+	return driver.ColumnTranslateFunc, nil
 }
 
 func supportsTx(driverName string) bool {
@@ -289,6 +280,13 @@ func supportsTx(driverName string) bool {
 func CreateInsertComponents(t *ToSQLTransformation, tbl flux.Table) (colNames []string, valStringArray [][]string, valArgsArray [][]interface{}, err error) {
 	cols := tbl.Cols()
 	batchSize := correctBatchSize(t.spec.Spec.BatchSize, len(cols))
+	driver, err := lookupDriver(t.spec.Spec.DriverName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if driver.MaxBatchRows > 0 && batchSize > driver.MaxBatchRows {
+		batchSize = driver.MaxBatchRows
+	}
 
 	labels := make(map[string]idxType, len(cols))
 	var questionMarks, newSQLTableCols []string
@@ -336,18 +334,7 @@ func CreateInsertComponents(t *ToSQLTransformation, tbl flux.Table) (colNames []
 		valueArgs := make([]interface{}, 0, l*len(cols))
 
 		if t.spec.Spec.DriverName != "sqlmock" {
-			var q string
-			if isMssqlDriver(t.spec.Spec.DriverName) { // SQL Server does not support IF NOT EXIST
-				q = fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NULL BEGIN CREATE TABLE %s (%s) END", t.spec.Spec.Table, t.spec.Spec.Table, strings.Join(newSQLTableCols, ","))
-			} else if t.spec.Spec.DriverName == "hdb" { // SAP HANA does not support IF NOT EXIST
-				// wrap CREATE TABLE statement with HDB-specific "if not exists" SQLScript check
-				q = fmt.Sprintf("CREATE TABLE %s (%s)", hdbEscapeName(t.spec.Spec.Table, true), strings.Join(newSQLTableCols, ","))
-				q = hdbAddIfNotExist(t.spec.Spec.Table, q)
-				// SAP HANA does not support INSERT/UPDATE batching via a single SQL command
-				batchSize = 1
-			} else {
-				q = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.spec.Spec.Table, strings.Join(newSQLTableCols, ","))
-			}
+			q := driver.createTableStatement(t.spec.Spec.Table, newSQLTableCols)
 			_, err = t.tx.Exec(q)
 			if err != nil {
 				return err
@@ -424,37 +411,46 @@ func CreateInsertComponents(t *ToSQLTransformation, tbl flux.Table) (colNames []
 }
 
 func ExecuteQueries(tx *sql.Tx, s *ToSQLOpSpec, colNames []string, valueStrings *[]string, valueArgs *[]interface{}) (err error) {
-	concatValueStrings := strings.Join(*valueStrings, ",")
+	driver, err := lookupDriver(s.DriverName)
+	if err != nil {
+		return err
+	}
+	if s.DriverName == "sqlmock" {
+		return nil
+	}
 
-	// PostgreSQL uses $n instead of ? for placeholders
-	if s.DriverName == "postgres" {
-		for pqCounter := 1; strings.Contains(concatValueStrings, "?"); pqCounter++ {
-			concatValueStrings = strings.Replace(concatValueStrings, "?", fmt.Sprintf("$%v", pqCounter), 1)
-		}
+	if driver.PrepareInsert {
+		err = execPreparedInsert(tx, driver, s, colNames, *valueArgs)
+	} else {
+		// The placeholders are created by the driver as some
+		// databases use numbered placeholders instead of ?.
+		rows := driver.valuePlaceholders(len(*valueStrings), len(colNames))
+		query := driver.insertStatement(s, colNames, rows)
+		_, err = tx.Exec(query, *valueArgs...)
 	}
-	// SQLServer uses @p instead of ? for placeholders
-	if isMssqlDriver(s.DriverName) {
-		for pqCounter := 1; strings.Contains(concatValueStrings, "?"); pqCounter++ {
-			concatValueStrings = strings.Replace(concatValueStrings, "?", fmt.Sprintf("@p%v", pqCounter), 1)
+	if err != nil {
+		// this err which is extremely helpful as it comes from the SQL driver should be
+		// bubbled up further up the stack so user can see the issue
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Newf(codes.Aborted, "transaction failed (%s) while recovering from %s", err, rbErr)
 		}
+		return err
 	}
+	return nil
+}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", s.Table, strings.Join(colNames, ","), concatValueStrings)
-	if isMssqlDriver(s.DriverName) && mssqlCheckParameter(s.DataSourceName, mssqlIdentityInsertEnabled) {
-		prologue := fmt.Sprintf("DECLARE @tableHasIdentity INT = OBJECTPROPERTY(OBJECT_ID('%s'), 'TableHasIdentity'); IF @tableHasIdentity = 1 BEGIN SET IDENTITY_INSERT %s ON END", s.Table, s.Table)
-		epilogue := fmt.Sprintf("IF @tableHasIdentity = 1 BEGIN SET IDENTITY_INSERT %s OFF END", s.Table)
-		query = strings.Join([]string{prologue, query, epilogue}, "; ")
+// execPreparedInsert inserts the values one row at a time with a prepared statement.
+func execPreparedInsert(tx *sql.Tx, driver *Driver, s *ToSQLOpSpec, colNames []string, valueArgs []interface{}) error {
+	query := driver.insertStatement(s, colNames, driver.valuePlaceholders(1, len(colNames)))
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
 	}
-	if s.DriverName != "sqlmock" {
-		_, err := tx.Exec(query, *valueArgs...)
-		if err != nil {
-			// this err which is extremely helpful as it comes from the SQL driver should be
-			// bubbled up further up the stack so user can see the issue
-			if rbErr := tx.Rollback(); rbErr != nil {
-				return errors.Newf(codes.Aborted, "transaction failed (%s) while recovering from %s", err, rbErr)
-			}
+	for i := 0; i+len(colNames) <= len(valueArgs); i += len(colNames) {
+		if _, err := stmt.Exec(valueArgs[i : i+len(colNames)]...); err != nil {
+			_ = stmt.Close()
 			return err
 		}
 	}
-	return err
+	return stmt.Close()
 }
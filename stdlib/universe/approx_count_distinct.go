@@ -0,0 +1,242 @@
+package universe
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/cespare/xxhash/v2"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/sketch"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const ApproxCountDistinctKind = "approxCountDistinct"
+
+type ApproxCountDistinctOpSpec struct {
+	Precision int64 `json:"precision"`
+	Sketch    bool  `json:"sketch,omitempty"`
+	execute.AggregateConfig
+}
+
+func init() {
+	approxCountDistinctSignature := runtime.MustLookupBuiltinType("universe", "approxCountDistinct")
+
+	runtime.RegisterPackageValue("universe", ApproxCountDistinctKind, flux.MustValue(flux.FunctionValue(ApproxCountDistinctKind, createApproxCountDistinctOpSpec, approxCountDistinctSignature)))
+	flux.RegisterOpSpec(ApproxCountDistinctKind, newApproxCountDistinctOp)
+	plan.RegisterProcedureSpec(ApproxCountDistinctKind, newApproxCountDistinctProcedure, ApproxCountDistinctKind)
+	execute.RegisterTransformation(ApproxCountDistinctKind, createApproxCountDistinctTransformation)
+}
+
+func createApproxCountDistinctOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := new(ApproxCountDistinctOpSpec)
+
+	if p, ok, err := args.GetInt("precision"); err != nil {
+		return nil, err
+	} else if ok {
+		if p < sketch.MinPrecision || p > sketch.MaxPrecision {
+			return nil, errors.Newf(codes.Invalid, "precision must be between %d and %d, got %d", sketch.MinPrecision, sketch.MaxPrecision, p)
+		}
+		spec.Precision = p
+	} else {
+		spec.Precision = sketch.DefaultPrecision
+	}
+
+	if s, ok, err := args.GetBool("sketch"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Sketch = s
+	}
+
+	if err := spec.AggregateConfig.ReadArgs(args); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func newApproxCountDistinctOp() flux.OperationSpec {
+	return new(ApproxCountDistinctOpSpec)
+}
+
+func (s *ApproxCountDistinctOpSpec) Kind() flux.OperationKind {
+	return ApproxCountDistinctKind
+}
+
+type ApproxCountDistinctProcedureSpec struct {
+	Precision int64 `json:"precision"`
+	// Sketch reports whether the HyperLogLog is emitted
+	// instead of the count it estimates.
+	Sketch bool `json:"sketch"`
+	execute.AggregateConfig
+}
+
+func newApproxCountDistinctProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*ApproxCountDistinctOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &ApproxCountDistinctProcedureSpec{
+		Precision:       spec.Precision,
+		Sketch:          spec.Sketch,
+		AggregateConfig: spec.AggregateConfig,
+	}, nil
+}
+
+func (s *ApproxCountDistinctProcedureSpec) Kind() plan.ProcedureKind {
+	return ApproxCountDistinctKind
+}
+
+func (s *ApproxCountDistinctProcedureSpec) Copy() plan.ProcedureSpec {
+	return &ApproxCountDistinctProcedureSpec{
+		Precision:       s.Precision,
+		Sketch:          s.Sketch,
+		AggregateConfig: s.AggregateConfig.Copy(),
+	}
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *ApproxCountDistinctProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createApproxCountDistinctTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*ApproxCountDistinctProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	agg := &ApproxCountDistinctAgg{
+		Precision: int(s.Precision),
+		Sketch:    s.Sketch,
+	}
+	if err := a.Allocator().Account(1 << uint(agg.Precision)); err != nil {
+		return nil, nil, errors.Newf(codes.Internal, "could not allocate memory for hyperloglog: %s", err)
+	}
+	t, d := execute.NewAggregateTransformationAndDataset(id, mode, agg, s.AggregateConfig, a.Allocator())
+	return t, d, nil
+}
+
+// ApproxCountDistinctAgg estimates the number of distinct
+// values of a column with a HyperLogLog.
+type ApproxCountDistinctAgg struct {
+	Precision int
+	// Sketch reports whether the serialized HyperLogLog
+	// is the value of the aggregate.
+	Sketch bool
+
+	hll *sketch.HyperLogLog
+	buf [8]byte
+}
+
+func (a *ApproxCountDistinctAgg) copy() *ApproxCountDistinctAgg {
+	hll, err := sketch.NewHyperLogLog(a.Precision)
+	if err != nil {
+		// The precision is validated when the arguments are read.
+		panic(err)
+	}
+	return &ApproxCountDistinctAgg{
+		Precision: a.Precision,
+		Sketch:    a.Sketch,
+		hll:       hll,
+	}
+}
+
+func (a *ApproxCountDistinctAgg) NewBoolAgg() execute.DoBoolAgg {
+	return a.copy()
+}
+
+func (a *ApproxCountDistinctAgg) NewIntAgg() execute.DoIntAgg {
+	return a.copy()
+}
+
+func (a *ApproxCountDistinctAgg) NewUIntAgg() execute.DoUIntAgg {
+	return a.copy()
+}
+
+func (a *ApproxCountDistinctAgg) NewFloatAgg() execute.DoFloatAgg {
+	return a.copy()
+}
+
+func (a *ApproxCountDistinctAgg) NewStringAgg() execute.DoStringAgg {
+	return a.copy()
+}
+
+func (a *ApproxCountDistinctAgg) addUint64(v uint64) {
+	binary.LittleEndian.PutUint64(a.buf[:], v)
+	a.hll.Add(xxhash.Sum64(a.buf[:]))
+}
+
+func (a *ApproxCountDistinctAgg) DoBool(vs *array.Boolean) {
+	for i := 0; i < vs.Len(); i++ {
+		if vs.IsValid(i) {
+			v := uint64(0)
+			if vs.Value(i) {
+				v = 1
+			}
+			a.addUint64(v)
+		}
+	}
+}
+
+func (a *ApproxCountDistinctAgg) DoInt(vs *array.Int64) {
+	for i := 0; i < vs.Len(); i++ {
+		if vs.IsValid(i) {
+			a.addUint64(uint64(vs.Value(i)))
+		}
+	}
+}
+
+func (a *ApproxCountDistinctAgg) DoUInt(vs *array.Uint64) {
+	for i := 0; i < vs.Len(); i++ {
+		if vs.IsValid(i) {
+			a.addUint64(vs.Value(i))
+		}
+	}
+}
+
+func (a *ApproxCountDistinctAgg) DoFloat(vs *array.Float64) {
+	for i := 0; i < vs.Len(); i++ {
+		if vs.IsValid(i) {
+			v := vs.Value(i)
+			if v == 0 {
+				// Negative zero is the same value as zero.
+				v = 0
+			}
+			a.addUint64(math.Float64bits(v))
+		}
+	}
+}
+
+func (a *ApproxCountDistinctAgg) DoString(vs *array.Binary) {
+	for i := 0; i < vs.Len(); i++ {
+		if vs.IsValid(i) {
+			a.hll.Add(xxhash.Sum64(vs.Value(i)))
+		}
+	}
+}
+
+func (a *ApproxCountDistinctAgg) Type() flux.ColType {
+	if a.Sketch {
+		return flux.TString
+	}
+	return flux.TInt
+}
+
+func (a *ApproxCountDistinctAgg) ValueInt() int64 {
+	return int64(a.hll.Count())
+}
+
+func (a *ApproxCountDistinctAgg) ValueString() string {
+	return sketch.Marshal(a.hll)
+}
+
+func (a *ApproxCountDistinctAgg) IsNull() bool {
+	return false
+}
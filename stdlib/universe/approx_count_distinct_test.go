@@ -0,0 +1,166 @@
+package universe_test
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/sketch"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestApproxCountDistinct_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "approxCountDistinct",
+			Raw:  `from(bucket:"mydb") |> approxCountDistinct(precision: 10, sketch: true)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "approxCountDistinct1",
+						Spec: &universe.ApproxCountDistinctOpSpec{
+							Precision:       10,
+							Sketch:          true,
+							AggregateConfig: execute.DefaultAggregateConfig,
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "approxCountDistinct1"},
+				},
+			},
+		},
+		{
+			Name:    "invalid precision",
+			Raw:     `from(bucket:"mydb") |> approxCountDistinct(precision: 2)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestApproxCountDistinctOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"approxCountDistinct","kind":"approxCountDistinct","spec":{"precision":14}}`)
+	op := &flux.Operation{
+		ID: "approxCountDistinct",
+		Spec: &universe.ApproxCountDistinctOpSpec{
+			Precision: 14,
+		},
+	}
+
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestApproxCountDistinct_Process(t *testing.T) {
+	testCases := []struct {
+		name string
+		data func() *array.Float64
+		want int64
+	}{
+		{
+			name: "distinct",
+			data: func() *array.Float64 {
+				return arrow.NewFloat([]float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, nil)
+			},
+			want: 10,
+		},
+		{
+			name: "duplicates",
+			data: func() *array.Float64 {
+				return arrow.NewFloat([]float64{1, 2, 3, 1, 2, 3, 0, 0}, nil)
+			},
+			want: 4,
+		},
+		{
+			name: "empty",
+			data: func() *array.Float64 {
+				return arrow.NewFloat(nil, nil)
+			},
+			want: 0,
+		},
+		{
+			name: "with nulls",
+			data: func() *array.Float64 {
+				b := arrow.NewFloatBuilder(nil)
+				defer b.Release()
+				b.AppendValues([]float64{0, 1, 2}, nil)
+				b.AppendNull()
+				b.AppendValues([]float64{2, 1}, nil)
+				b.AppendNull()
+				return b.NewFloat64Array()
+			},
+			want: 3,
+		},
+		{
+			name: "only nulls",
+			data: func() *array.Float64 {
+				b := arrow.NewFloatBuilder(nil)
+				defer b.Release()
+				b.AppendNull()
+				b.AppendNull()
+				return b.NewFloat64Array()
+			},
+			want: 0,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			data := tc.data()
+			defer data.Release()
+
+			executetest.AggFuncTestHelper(
+				t,
+				&universe.ApproxCountDistinctAgg{Precision: sketch.DefaultPrecision},
+				data,
+				tc.want,
+			)
+		})
+	}
+}
+
+func TestApproxCountDistinct_Sketch(t *testing.T) {
+	agg := &universe.ApproxCountDistinctAgg{
+		Precision: 12,
+		Sketch:    true,
+	}
+	vs := agg.NewFloatAgg()
+	data := arrow.NewFloat([]float64{1, 2, 3, 3, 2, 1}, nil)
+	defer data.Release()
+	vs.DoFloat(data)
+
+	if want, got := flux.TString, vs.Type(); want != got {
+		t.Fatalf("unexpected type: want %v, got %v", want, got)
+	}
+	s, err := sketch.Unmarshal(vs.(execute.StringValueFunc).ValueString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, ok := s.(*sketch.HyperLogLog)
+	if !ok {
+		t.Fatalf("unexpected sketch type %T", s)
+	}
+	if want, got := 12, h.Precision(); want != got {
+		t.Errorf("unexpected precision: want %d, got %d", want, got)
+	}
+	if want, got := uint64(3), h.Count(); want != got {
+		t.Errorf("unexpected count: want %d, got %d", want, got)
+	}
+}
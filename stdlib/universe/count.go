@@ -67,6 +67,9 @@ func (s *CountProcedureSpec) Copy() plan.ProcedureSpec {
 	}
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *CountProcedureSpec) PartitionParallel() {}
+
 func (s *CountProcedureSpec) AggregateMethod() string {
 	return CountKind
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/interval"
 	"github.com/influxdata/flux/mock"
 	"github.com/influxdata/flux/plan"
 )
@@ -23,6 +24,10 @@ func (m mockAdministration) Now() time.Time {
 	return time.Now()
 }
 
+func (m mockAdministration) Location() interval.Location {
+	return interval.UTC
+}
+
 type MockProcedureSpec struct {
 	plan.DefaultCost
 }
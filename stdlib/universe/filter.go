@@ -115,6 +115,9 @@ func (s *FilterProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *FilterProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *FilterProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
@@ -304,6 +307,10 @@ func (t *filterTransformation) filterTable(fn *execute.RowPredicatePreparedFn, i
 }
 
 func (t *filterTransformation) filter(fn *execute.RowPredicatePreparedFn, cr flux.ColReader, record values.Object, indices []int) (*arrowmem.Buffer, error) {
+	if vfn := fn.Vectorized(); vfn != nil {
+		return t.filterVectorized(vfn, cr)
+	}
+
 	cols, l := cr.Cols(), cr.Len()
 	bitset := arrowmem.NewResizableBuffer(t.alloc)
 	bitset.Resize(l)
@@ -322,6 +329,25 @@ func (t *filterTransformation) filter(fn *execute.RowPredicatePreparedFn, cr flu
 	return bitset, nil
 }
 
+// filterVectorized evaluates the predicate for all of the rows at once.
+// Rows where the predicate is null are filtered out.
+func (t *filterTransformation) filterVectorized(fn compiler.VectorizedFunc, cr flux.ColReader) (*arrowmem.Buffer, error) {
+	vs, err := fn.Eval(t.ctx, cr, t.alloc)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Inherit, "failed to evaluate filter function")
+	}
+	result := vs[0].(*array.Boolean)
+	defer result.Release()
+
+	l := cr.Len()
+	bitset := arrowmem.NewResizableBuffer(t.alloc)
+	bitset.Resize(l)
+	for i := 0; i < l; i++ {
+		bitutil.SetBitTo(bitset.Buf(), i, result.IsValid(i) && result.Value(i))
+	}
+	return bitset, nil
+}
+
 func (t *filterTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
 	return t.d.UpdateWatermark(mark)
 }
@@ -712,6 +712,36 @@ func TestFilter_Process(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: `null predicate and guarded division`,
+			spec: &universe.FilterProcedureSpec{
+				Fn: interpreter.ResolvedFunction{
+					Fn:    executetest.FunctionExpression(t, `(r) => r.n != 0 and 12 / r.n > 3`),
+					Scope: valuestest.Scope(),
+				},
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "n", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(0)},
+					{execute.Time(2), int64(2)},
+					{execute.Time(3), nil},
+					{execute.Time(4), int64(4)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "n", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), int64(2)},
+				},
+			}},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -71,6 +71,9 @@ func (s *FirstProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *FirstProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *FirstProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
@@ -0,0 +1,243 @@
+package universe
+
+import (
+	"github.com/influxdata/flux"
+	fluxarrow "github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	fluxmemory "github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/universe/stl"
+)
+
+const ForecastKind = "forecast"
+
+const defaultForecastLevel = 0.95
+
+// The labels of the columns that hold the bounds of the prediction intervals.
+const (
+	forecastLowerColLabel = "lower"
+	forecastUpperColLabel = "upper"
+)
+
+type ForecastOpSpec struct {
+	N          int64   `json:"n"`
+	Period     int64   `json:"period,omitempty"`
+	Level      float64 `json:"level"`
+	Column     string  `json:"column"`
+	TimeColumn string  `json:"time_column"`
+}
+
+func init() {
+	forecastSignature := runtime.MustLookupBuiltinType("universe", "forecast")
+	runtime.RegisterPackageValue("universe", ForecastKind, flux.MustValue(flux.FunctionValue(ForecastKind, createForecastOpSpec, forecastSignature)))
+	flux.RegisterOpSpec(ForecastKind, newForecastOp)
+	plan.RegisterProcedureSpec(ForecastKind, newForecastProcedure, ForecastKind)
+	execute.RegisterTransformation(ForecastKind, createForecastTransformation)
+}
+
+func createForecastOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(ForecastOpSpec)
+	if n, err := args.GetRequiredInt("n"); err != nil {
+		return nil, err
+	} else if n <= 0 {
+		return nil, errors.Newf(codes.Invalid, "n must be positive, got %d", n)
+	} else {
+		spec.N = n
+	}
+	if p, ok, err := args.GetInt("period"); err != nil {
+		return nil, err
+	} else if ok {
+		if p < 2 {
+			return nil, errors.Newf(codes.Invalid, "period must be at least 2, got %d", p)
+		}
+		spec.Period = p
+	}
+	if l, ok, err := args.GetFloat("level"); err != nil {
+		return nil, err
+	} else if ok {
+		if l <= 0 || l >= 1 {
+			return nil, errors.Newf(codes.Invalid, "level must be between 0 and 1 exclusive, got %v", l)
+		}
+		spec.Level = l
+	} else {
+		spec.Level = defaultForecastLevel
+	}
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	} else {
+		spec.Column = execute.DefaultValueColLabel
+	}
+	if col, ok, err := args.GetString("timeColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.TimeColumn = col
+	} else {
+		spec.TimeColumn = execute.DefaultTimeColLabel
+	}
+	return spec, nil
+}
+
+func newForecastOp() flux.OperationSpec {
+	return new(ForecastOpSpec)
+}
+
+func (s *ForecastOpSpec) Kind() flux.OperationKind {
+	return ForecastKind
+}
+
+type ForecastProcedureSpec struct {
+	plan.DefaultCost
+	N          int64
+	Period     int64
+	Level      float64
+	Column     string
+	TimeColumn string
+}
+
+func newForecastProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*ForecastOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &ForecastProcedureSpec{
+		N:          spec.N,
+		Period:     spec.Period,
+		Level:      spec.Level,
+		Column:     spec.Column,
+		TimeColumn: spec.TimeColumn,
+	}, nil
+}
+
+func (s *ForecastProcedureSpec) Kind() plan.ProcedureKind {
+	return ForecastKind
+}
+func (s *ForecastProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(ForecastProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *ForecastProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createForecastTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*ForecastProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewForecastTransformation(d, cache, a.Allocator(), s)
+	return t, d, nil
+}
+
+type forecastTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+	alloc *fluxmemory.Allocator
+
+	n          int
+	period     int
+	level      float64
+	column     string
+	timeColumn string
+}
+
+func NewForecastTransformation(d execute.Dataset, cache execute.TableBuilderCache, alloc *fluxmemory.Allocator, spec *ForecastProcedureSpec) *forecastTransformation {
+	return &forecastTransformation{
+		d:          d,
+		cache:      cache,
+		alloc:      alloc,
+		n:          int(spec.N),
+		period:     int(spec.Period),
+		level:      spec.Level,
+		column:     spec.Column,
+		timeColumn: spec.TimeColumn,
+	}
+}
+
+// Process forecasts the next n values of the column of the table.
+// The output table has the group key columns, the forecast times and values,
+// and the bounds of their prediction intervals.
+// Tables that are too short to be forecast produce empty tables.
+func (t *forecastTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "forecast found duplicate table with key: %v", tbl.Key())
+	}
+	timeIdx, colIdx, err := seriesColIdxs(ForecastKind, tbl.Cols(), t.timeColumn, t.column)
+	if err != nil {
+		return err
+	}
+
+	// Building schema.
+	if err := execute.AddTableKeyCols(tbl.Key(), builder); err != nil {
+		return err
+	}
+	newTimeIdx, err := builder.AddCol(flux.ColMeta{
+		Label: execute.DefaultTimeColLabel,
+		Type:  flux.TTime,
+	})
+	if err != nil {
+		return err
+	}
+	var newValueIdxs [3]int
+	for i, label := range []string{execute.DefaultValueColLabel, forecastLowerColLabel, forecastUpperColLabel} {
+		j, err := addAnalyticCol(builder, label, flux.TFloat)
+		if err != nil {
+			return err
+		}
+		newValueIdxs[i] = j
+	}
+
+	times, vs, err := readSeries(ForecastKind, tbl, timeIdx, colIdx, nil)
+	if err != nil {
+		return err
+	}
+
+	f := stl.Predict(vs, t.period, t.n, t.level, fluxarrow.NewAllocator(t.alloc))
+	if f == nil {
+		return nil
+	}
+
+	// Timestamps are deduced by summing the interval of the series to its last timestamp.
+	last, interval := times[len(times)-1], times[1]-times[0]
+	for i := 0; i < t.n; i++ {
+		if err := builder.AppendTime(newTimeIdx, execute.Time(last+int64(i+1)*interval)); err != nil {
+			return err
+		}
+	}
+	for i, vs := range [][]float64{f.Mean, f.Lower, f.Upper} {
+		for _, v := range vs {
+			if err := builder.AppendFloat(newValueIdxs[i], v); err != nil {
+				return err
+			}
+		}
+	}
+	return execute.AppendKeyValuesN(tbl.Key(), builder, t.n)
+}
+
+func (t *forecastTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *forecastTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *forecastTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *forecastTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
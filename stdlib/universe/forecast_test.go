@@ -0,0 +1,305 @@
+package universe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestForecast_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "forecast defaults",
+			Raw:  `from(bucket:"mydb") |> forecast(n: 10)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "forecast1",
+						Spec: &universe.ForecastOpSpec{
+							N:          10,
+							Level:      0.95,
+							Column:     execute.DefaultValueColLabel,
+							TimeColumn: execute.DefaultTimeColLabel,
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "forecast1"},
+				},
+			},
+		},
+		{
+			Name: "forecast no defaults",
+			Raw:  `from(bucket:"mydb") |> forecast(n: 10, period: 24, level: 0.8, column: "v", timeColumn: "t")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "forecast1",
+						Spec: &universe.ForecastOpSpec{
+							N:          10,
+							Period:     24,
+							Level:      0.8,
+							Column:     "v",
+							TimeColumn: "t",
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "forecast1"},
+				},
+			},
+		},
+		{
+			Name:    "non positive n",
+			Raw:     `from(bucket:"mydb") |> forecast(n: 0)`,
+			WantErr: true,
+		},
+		{
+			Name:    "period too small",
+			Raw:     `from(bucket:"mydb") |> forecast(n: 10, period: 1)`,
+			WantErr: true,
+		},
+		{
+			Name:    "level out of range",
+			Raw:     `from(bucket:"mydb") |> forecast(n: 10, level: 1.0)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestForecast_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"forecast","kind":"forecast","spec":{"n":10,"period":24,"level":0.8,"column":"v","time_column":"t"}}`)
+	op := &flux.Operation{
+		ID: "forecast",
+		Spec: &universe.ForecastOpSpec{
+			N:          10,
+			Period:     24,
+			Level:      0.8,
+			Column:     "v",
+			TimeColumn: "t",
+		},
+	}
+
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestForecast_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := universe.NewForecastTransformation(
+			d,
+			c,
+			&memory.Allocator{},
+			&universe.ForecastProcedureSpec{},
+		)
+		return s
+	})
+}
+
+func TestForecast_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *universe.ForecastProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "seasonal",
+			spec: &universe.ForecastProcedureSpec{
+				N:          4,
+				Period:     4,
+				Level:      0.95,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "t0", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 11.0, "a"},
+					{execute.Time(10), 8.5, "a"},
+					{execute.Time(20), 14.0, "a"},
+					{execute.Time(30), 9.5, "a"},
+					{execute.Time(40), 13.0, "a"},
+					{execute.Time(50), 10.75, "a"},
+					{execute.Time(60), 16.0, "a"},
+					{execute.Time(70), 11.5, "a"},
+					{execute.Time(80), 15.0, "a"},
+					{execute.Time(90), 12.0, "a"},
+					{execute.Time(100), 18.0, "a"},
+					{execute.Time(110), 13.5, "a"},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "lower", Type: flux.TFloat},
+					{Label: "upper", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{"a", execute.Time(120), 16.83258676680752, 16.480646767061412, 17.184526766553628},
+					{"a", execute.Time(130), 13.989760464229324, 13.522360849370864, 14.457160079087783},
+					{"a", execute.Time(140), 19.85276428290956, 19.258291283182515, 20.447237282636607},
+					{"a", execute.Time(150), 15.369534459364841, 14.637542354793787, 16.101526563935895},
+				},
+			}},
+		},
+		{
+			name: "without period",
+			spec: &universe.ForecastProcedureSpec{
+				N:          2,
+				Level:      0.8,
+				Column:     "v",
+				TimeColumn: "t",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "t", Type: flux.TTime},
+					{Label: "v", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(1)},
+					{execute.Time(2), int64(3)},
+					{execute.Time(3), int64(2)},
+					{execute.Time(4), int64(4)},
+					{execute.Time(5), int64(3)},
+					{execute.Time(6), int64(5)},
+					{execute.Time(7), int64(4)},
+					{execute.Time(8), int64(6)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "lower", Type: flux.TFloat},
+					{Label: "upper", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(9), 6.124907267866079, 4.168553781537662, 8.081260754194496},
+					{execute.Time(10), 6.9998701739117415, 4.233236951740138, 9.766503396083344},
+				},
+			}},
+		},
+		{
+			name: "too short",
+			spec: &universe.ForecastProcedureSpec{
+				N:          2,
+				Period:     4,
+				Level:      0.95,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(10), 2.0},
+					{execute.Time(20), 3.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "lower", Type: flux.TFloat},
+					{Label: "upper", Type: flux.TFloat},
+				},
+			}},
+		},
+		{
+			name: "irregular times",
+			spec: &universe.ForecastProcedureSpec{
+				N:          2,
+				Level:      0.95,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(10), 2.0},
+					{execute.Time(15), 3.0},
+				},
+			}},
+			wantErr: errors.New("forecast requires regularly spaced data in column _time"),
+		},
+		{
+			name: "missing time column",
+			spec: &universe.ForecastProcedureSpec{
+				N:          2,
+				Level:      0.95,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0},
+				},
+			}},
+			wantErr: errors.New("cannot find time column _time"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			alloc := &memory.Allocator{}
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewForecastTransformation(d, c, alloc, tc.spec)
+				},
+			)
+
+			if m := alloc.Allocated(); m != 0 {
+				t.Errorf("forecast is using memory after finishing: %d", m)
+			}
+		})
+	}
+}
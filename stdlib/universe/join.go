@@ -33,6 +33,9 @@ func init() {
 // All supported join types in Flux
 var methods = map[string]bool{
 	"inner": true,
+	"left":  true,
+	"right": true,
+	"full":  true,
 }
 
 // JoinOpSpec specifies a particular join operation
@@ -163,6 +166,7 @@ type MergeJoinProcedureSpec struct {
 	plan.DefaultCost
 	TableNames []string `json:"table_names"`
 	On         []string `json:"keys"`
+	Method     string   `json:"method"`
 }
 
 func newMergeJoinProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -189,6 +193,7 @@ func newMergeJoinProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.
 	return &MergeJoinProcedureSpec{
 		On:         on,
 		TableNames: tableNames,
+		Method:     spec.Method,
 	}, nil
 }
 
@@ -201,6 +206,8 @@ func (s *MergeJoinProcedureSpec) Copy() plan.ProcedureSpec {
 	ns.On = make([]string, len(s.On))
 	copy(ns.On, s.On)
 
+	ns.Method = s.Method
+
 	return ns
 }
 
@@ -220,7 +227,8 @@ func createMergeJoinTransformation(id execute.DatasetID, mode execute.Accumulati
 		tableNames[parents[i]] = name
 	}
 
-	cache := NewMergeJoinCache(a.Allocator(), parents, tableNames, s.On)
+	cache := NewMergeJoinCache(a.Allocator(), parents, tableNames, s.On, s.Method)
+	cache.EnableSpilling(execute.SpillerFromContext(a.Context(), a.Allocator()))
 	d := execute.NewDataset(id, mode, cache)
 	t := NewMergeJoinTransformation(d, cache, s, parents, tableNames)
 	return t, d, nil
@@ -286,6 +294,11 @@ func (t *mergeJoinTransformation) Process(id execute.DatasetID, tbl flux.Table)
 		}
 	}
 	if numOnCols < len(t.cache.on) {
+		// An outer join still outputs the rows of this table,
+		// but they will never match a row from the other stream.
+		if t.cache.isOuter(id) {
+			return t.cache.insertUnjoinable(id, tbl)
+		}
 		// Discard this table
 		tbl.Done()
 		return nil
@@ -302,7 +315,9 @@ func (t *mergeJoinTransformation) Process(id execute.DatasetID, tbl flux.Table)
 
 	// Register any new output group keys that can be constructed from the new table
 	t.cache.registerKey(id, tbl.Key())
-	return nil
+
+	// Move the buffered tables into scratch space if memory is running low.
+	return t.cache.spill()
 }
 
 func (t *mergeJoinTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
@@ -351,6 +366,9 @@ func (t *mergeJoinTransformation) Finish(id execute.DatasetID, err error) {
 	}
 
 	if finished {
+		if t.err == nil {
+			t.err = t.cache.buildUnmatchedTables()
+		}
 		t.d.Finish(t.err)
 	}
 }
@@ -372,6 +390,14 @@ func (t *mergeJoinTransformation) Finish(id execute.DatasetID, err error) {
 //
 // tables:          All output tables are materialized and stored in this
 //                  map before being sent to downstream operators.
+//
+// method:          The join method. Outer join methods also output the
+//                  rows of the outer streams that did not match any row
+//                  of the opposing stream once both streams have finished.
+//
+// spiller:         When spilling is enabled, the buffered tables and the
+//                  output tables are moved into scratch space whenever
+//                  memory is running low.
 type MergeJoinCache struct {
 	leftID  execute.DatasetID
 	rightID execute.DatasetID
@@ -394,6 +420,14 @@ type MergeJoinCache struct {
 	tables      map[flux.GroupKey]flux.Table
 	alloc       *memory.Allocator
 	triggerSpec plan.TriggerSpec
+
+	method string
+
+	spiller *execute.Spiller
+	spilled map[flux.GroupKey]bool
+	// errs holds the errors from reading spilled tables back
+	// while iterating, so that Table can report them.
+	errs map[flux.GroupKey]error
 }
 
 type streamBuffer struct {
@@ -403,6 +437,26 @@ type streamBuffer struct {
 	stale    map[flux.GroupKey]bool
 	last     values.Value
 	alloc    *memory.Allocator
+
+	// matched marks the rows of each buffered table that have
+	// been joined with at least one row of the opposing stream.
+	// It is only maintained for the outer streams of a join.
+	matched map[flux.GroupKey][]bool
+
+	// unjoinable holds the tables of an outer stream that can
+	// never match the opposing stream because they are missing
+	// join columns or have null values in them.
+	unjoinable []*execute.ColListTableBuilder
+
+	// spilled holds the tables that have been moved into scratch
+	// space to save memory. A table is read back into memory
+	// when it is needed for a join.
+	spilled map[flux.GroupKey]spilledTable
+}
+
+type spilledTable struct {
+	table flux.Table
+	nrows int
 }
 
 func newStreamBuffer(alloc *memory.Allocator) *streamBuffer {
@@ -412,24 +466,101 @@ func newStreamBuffer(alloc *memory.Allocator) *streamBuffer {
 		ready:    make(map[values.Value]bool),
 		stale:    make(map[flux.GroupKey]bool),
 		alloc:    alloc,
+		matched:  make(map[flux.GroupKey][]bool),
+		spilled:  make(map[flux.GroupKey]spilledTable),
 	}
 }
 
-func (buf *streamBuffer) table(key flux.GroupKey) *execute.ColListTableBuilder {
-	return buf.data[key]
+// table returns the buffered table with the given key.
+// A spilled table is read back into memory.
+func (buf *streamBuffer) table(key flux.GroupKey) (*execute.ColListTableBuilder, error) {
+	if builder, ok := buf.data[key]; ok {
+		return builder, nil
+	}
+	s, ok := buf.spilled[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(buf.spilled, key)
+	builder, err := buf.copyTable(s.table)
+	if err != nil {
+		return nil, err
+	}
+	buf.data[key] = builder
+	return builder, nil
 }
 
-func (buf *streamBuffer) insert(table flux.Table) error {
+// nrows returns the number of rows in the buffered
+// table with the given key without restoring it.
+func (buf *streamBuffer) nrows(key flux.GroupKey) int {
+	if builder, ok := buf.data[key]; ok {
+		return builder.NRows()
+	}
+	return buf.spilled[key].nrows
+}
+
+// spill moves the tables held in memory into scratch space.
+func (buf *streamBuffer) spill(s *execute.Spiller) error {
+	for key, builder := range buf.data {
+		tbl, err := builder.Table()
+		if err != nil {
+			return err
+		}
+		nrows := builder.NRows()
+		builder.Release()
+		delete(buf.data, key)
+
+		spilled, err := s.SpillTable(tbl)
+		if err != nil {
+			return err
+		}
+		buf.spilled[key] = spilledTable{table: spilled, nrows: nrows}
+	}
+	return nil
+}
+
+// copyTable reads the input table into a new table builder.
+func (buf *streamBuffer) copyTable(table flux.Table) (*execute.ColListTableBuilder, error) {
 	// Construct a new table builder with same schema as input table
 	builder := execute.NewColListTableBuilder(table.Key(), buf.alloc)
 	// this will only error if we try to add a duplicate column to the builder.
 	// since this is a new table, that won't happen.
 	if err := execute.AddTableCols(table, builder); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Append the input table to this builder, safe to ignore errors
 	if err := execute.AppendTable(table, builder); err != nil {
+		return nil, err
+	}
+	return builder, nil
+}
+
+func (buf *streamBuffer) insertUnjoinable(table flux.Table) error {
+	builder, err := buf.copyTable(table)
+	if err != nil {
+		return err
+	}
+	buf.unjoinable = append(buf.unjoinable, builder)
+	return nil
+}
+
+// markMatched records that the rows in the subset of the
+// table with the given key have found a join partner.
+func (buf *streamBuffer) markMatched(key flux.GroupKey, rows subset, n int) {
+	matched := buf.matched[key]
+	if matched == nil {
+		matched = make([]bool, n)
+		buf.matched[key] = matched
+	}
+	for i := rows.Start; i < rows.Stop; i++ {
+		matched[i] = true
+	}
+}
+
+func (buf *streamBuffer) insert(table flux.Table) error {
+	builder, err := buf.copyTable(table)
+	if err != nil {
 		return err
 	}
 
@@ -455,6 +586,11 @@ func (buf *streamBuffer) insert(table flux.Table) error {
 }
 
 func (buf *streamBuffer) expire(key flux.GroupKey) {
+	// Tables made of unmatched rows only have a pre-join
+	// group key for one of the two streams.
+	if key == nil {
+		return
+	}
 	if !buf.stale[key] && len(key.Cols()) > 0 {
 		leftKeyValue := key.Value(0)
 		consumedTables := buf.consumed[leftKeyValue]
@@ -468,6 +604,10 @@ func (buf *streamBuffer) evict(key flux.GroupKey) {
 		builder.ClearData()
 		delete(buf.data, key)
 	}
+	if s, ok := buf.spilled[key]; ok {
+		s.table.Done()
+		delete(buf.spilled, key)
+	}
 }
 
 func (buf *streamBuffer) clear(f func(flux.GroupKey) bool) {
@@ -483,6 +623,9 @@ func (buf *streamBuffer) iterate(f func(flux.GroupKey)) {
 	for key := range buf.data {
 		f(key)
 	}
+	for key := range buf.spilled {
+		f(key)
+	}
 }
 
 type tableCol struct {
@@ -510,8 +653,9 @@ func (s schema) Swap(i int, j int) {
 	s.columns[i], s.columns[j] = s.columns[j], s.columns[i]
 }
 
-// NewMergeJoinCache constructs a new instance of a MergeJoinCache
-func NewMergeJoinCache(alloc *memory.Allocator, datasetIDs []execute.DatasetID, tableNames map[execute.DatasetID]string, key []string) *MergeJoinCache {
+// NewMergeJoinCache constructs a new instance of a MergeJoinCache.
+// An empty method is equivalent to an inner join.
+func NewMergeJoinCache(alloc *memory.Allocator, datasetIDs []execute.DatasetID, tableNames map[execute.DatasetID]string, key []string, method string) *MergeJoinCache {
 	// Join currently only accepts two data sources(streams) as input
 	if len(datasetIDs) != 2 {
 		panic("Join only accepts two data sources")
@@ -534,6 +678,10 @@ func NewMergeJoinCache(alloc *memory.Allocator, datasetIDs []execute.DatasetID,
 		intersection[k] = true
 	}
 
+	if method == "" {
+		method = "inner"
+	}
+
 	return &MergeJoinCache{
 		on:            on,
 		order:         key,
@@ -547,7 +695,43 @@ func NewMergeJoinCache(alloc *memory.Allocator, datasetIDs []execute.DatasetID,
 		postJoinKeys:  execute.NewGroupLookup(),
 		tables:        make(map[flux.GroupKey]flux.Table),
 		alloc:         alloc,
+		method:        method,
+		spilled:       make(map[flux.GroupKey]bool),
+		errs:          make(map[flux.GroupKey]error),
+	}
+}
+
+// EnableSpilling allows the cache to move the tables it holds
+// into scratch space when the Spiller reports that memory is
+// running low.
+func (c *MergeJoinCache) EnableSpilling(s *execute.Spiller) {
+	c.spiller = s
+}
+
+// spill moves the buffered tables and the output tables
+// into scratch space if memory is running low.
+func (c *MergeJoinCache) spill() error {
+	if !c.spiller.ShouldSpill() {
+		return nil
+	}
+	for _, buf := range c.buffers {
+		if err := buf.spill(c.spiller); err != nil {
+			return err
+		}
+	}
+	for key, table := range c.tables {
+		if c.spilled[key] {
+			continue
+		}
+		spilled, err := c.spiller.SpillTable(table)
+		if err != nil {
+			delete(c.tables, key)
+			return err
+		}
+		c.tables[key] = spilled
+		c.spilled[key] = true
 	}
+	return nil
 }
 
 // Table joins the two tables associated with a single output group key and returns the resulting table
@@ -558,15 +742,23 @@ func (c *MergeJoinCache) Table(key flux.GroupKey) (flux.Table, error) {
 		return nil, errors.Newf(codes.FailedPrecondition, "no table exists with group key: %v", key)
 	}
 
+	if err, ok := c.errs[key]; ok {
+		return nil, err
+	}
+
 	if _, ok := c.tables[key]; !ok {
 
-		left := c.buffers[c.leftID].table(preJoinGroupKeys.left)
-		if left == nil {
+		left, err := c.buffers[c.leftID].table(preJoinGroupKeys.left)
+		if err != nil {
+			return nil, err
+		} else if left == nil {
 			return nil, errors.Newf(codes.FailedPrecondition, "no table in left join buffer with key: %v", key)
 		}
 
-		right := c.buffers[c.rightID].table(preJoinGroupKeys.right)
-		if right == nil {
+		right, err := c.buffers[c.rightID].table(preJoinGroupKeys.right)
+		if err != nil {
+			return nil, err
+		} else if right == nil {
 			return nil, errors.Newf(codes.FailedPrecondition, "no table in right join buffer with key: %v", key)
 		}
 
@@ -591,8 +783,18 @@ func (c *MergeJoinCache) ForEach(f func(flux.GroupKey)) {
 			leftKey := preJoinGroupKeys.left
 			rightKey := preJoinGroupKeys.right
 
-			leftBuilder := c.buffers[c.leftID].table(leftKey)
-			rightBuilder := c.buffers[c.rightID].table(rightKey)
+			leftBuilder, err := c.buffers[c.leftID].table(leftKey)
+			var rightBuilder *execute.ColListTableBuilder
+			if err == nil {
+				rightBuilder, err = c.buffers[c.rightID].table(rightKey)
+			}
+			if err != nil {
+				// The spilled table could not be restored.
+				// Table will report the error for this key.
+				c.errs[key] = err
+				f(key)
+				return
+			}
 
 			table, err := c.join(leftBuilder, rightBuilder)
 			if err != nil || table.Empty() {
@@ -617,27 +819,37 @@ func (c *MergeJoinCache) ForEachWithContext(f func(flux.GroupKey, execute.Trigge
 		leftKey := preJoinGroupKeys.left
 		rightKey := preJoinGroupKeys.right
 
-		leftBuilder := c.buffers[c.leftID].table(leftKey)
-		rightBuilder := c.buffers[c.rightID].table(rightKey)
-
 		if _, ok := c.tables[key]; !ok {
+			leftBuilder, err := c.buffers[c.leftID].table(leftKey)
+			var rightBuilder *execute.ColListTableBuilder
+			if err == nil {
+				rightBuilder, err = c.buffers[c.rightID].table(rightKey)
+			}
+			if err == nil {
+				table, err := c.join(leftBuilder, rightBuilder)
 
-			table, err := c.join(leftBuilder, rightBuilder)
+				if err != nil || table.Empty() {
+					c.DiscardTable(key)
+					return
+				}
 
-			if err != nil || table.Empty() {
-				c.DiscardTable(key)
-				return
+				c.tables[key] = table
+			} else {
+				// The spilled table could not be restored.
+				// Table will report the error for this key.
+				c.errs[key] = err
 			}
-
-			c.tables[key] = table
 		}
 
-		leftsize := leftBuilder.NRows()
-		rightsize := rightBuilder.NRows()
-
 		ctx := execute.TableContext{
-			Key:   key,
-			Count: leftsize + rightsize,
+			Key: key,
+		}
+		// Tables of unmatched rows have a buffered table on one side only.
+		if leftKey != nil {
+			ctx.Count += c.buffers[c.leftID].nrows(leftKey)
+		}
+		if rightKey != nil {
+			ctx.Count += c.buffers[c.rightID].nrows(rightKey)
 		}
 
 		f(key, trigger, ctx)
@@ -647,6 +859,8 @@ func (c *MergeJoinCache) ForEachWithContext(f func(flux.GroupKey, execute.Trigge
 // DiscardTable removes a table from the output buffer
 func (c *MergeJoinCache) DiscardTable(key flux.GroupKey) {
 	delete(c.tables, key)
+	delete(c.spilled, key)
+	delete(c.errs, key)
 }
 
 // ExpireTable removes the a key from the set of postJoinKeys.
@@ -657,6 +871,8 @@ func (c *MergeJoinCache) ExpireTable(key flux.GroupKey) {
 	// Remove this group key from the cache
 	c.postJoinKeys.Delete(key)
 	delete(c.tables, key)
+	delete(c.spilled, key)
+	delete(c.errs, key)
 
 	// Clear any stale data
 	preJoinGroupKeys := c.reverseLookup[key]
@@ -688,16 +904,43 @@ func (c *MergeJoinCache) SetTriggerSpec(spec plan.TriggerSpec) {
 
 // Currently tables are the smallest unit of data that can be evicted from the join's internal
 // buffers. This is the rule that specifies whether a data cache can early evict tables.
+// Outer joins never evict tables because their unmatched rows are only output once
+// both streams have finished.
 func (c *MergeJoinCache) canEvictTables() bool {
+	if c.method != "inner" {
+		return false
+	}
 	leftKey := c.schemas[c.leftID].key
 	rightKey := c.schemas[c.rightID].key
 	return len(leftKey) > 0 && len(rightKey) > 0 &&
 		leftKey[0].Label == rightKey[0].Label && c.on[leftKey[0].Label]
 }
 
-// insertIntoBuffer adds the rows of an incoming table to one of the Join's internal buffers
-func (c *MergeJoinCache) insertIntoBuffer(id execute.DatasetID, tbl flux.Table) error {
-	// Initialize schema if tbl is first from its stream
+// isOuter reports whether the rows of the stream associated with id
+// are output even when they do not match any row of the opposing stream.
+func (c *MergeJoinCache) isOuter(id execute.DatasetID) bool {
+	switch c.method {
+	case "left":
+		return id == c.leftID
+	case "right":
+		return id == c.rightID
+	case "full":
+		return true
+	default:
+		return false
+	}
+}
+
+// insertUnjoinable adds the rows of an incoming table that cannot be joined
+// to the internal buffer of an outer stream.
+func (c *MergeJoinCache) insertUnjoinable(id execute.DatasetID, tbl flux.Table) error {
+	c.initSchema(id, tbl)
+	return c.buffers[id].insertUnjoinable(tbl)
+}
+
+// initSchema initializes the schema of the stream associated with id
+// if tbl is the first table from that stream.
+func (c *MergeJoinCache) initSchema(id execute.DatasetID, tbl flux.Table) {
 	if _, ok := c.schemas[id]; !ok {
 
 		c.schemas[id] = schema{
@@ -719,6 +962,12 @@ func (c *MergeJoinCache) insertIntoBuffer(id execute.DatasetID, tbl flux.Table)
 
 		c.intersection = intersection
 	}
+}
+
+// insertIntoBuffer adds the rows of an incoming table to one of the Join's internal buffers
+func (c *MergeJoinCache) insertIntoBuffer(id execute.DatasetID, tbl flux.Table) error {
+	// Initialize schema if tbl is first from its stream
+	c.initSchema(id, tbl)
 
 	// Optimization: if any group key columns overlap join key columns,
 	// and there are any nulls in those columns, we can discard this table,
@@ -727,6 +976,10 @@ func (c *MergeJoinCache) insertIntoBuffer(id execute.DatasetID, tbl flux.Table)
 	for j, col := range k.Cols() {
 		if c.on[col.Label] {
 			if k.IsNull(j) {
+				// Outer joins keep the table, but it will never be joined.
+				if c.isOuter(id) {
+					return c.buffers[id].insertUnjoinable(tbl)
+				}
 				// Discard the table and return.  Note: we need to iterate over the
 				// table at least once:
 				// https://github.com/influxdata/flux/issues/643
@@ -798,7 +1051,7 @@ func (c *MergeJoinCache) registerKey(id execute.DatasetID, key flux.GroupKey) {
 }
 
 func (c *MergeJoinCache) isBufferEmpty(id execute.DatasetID) bool {
-	return len(c.buffers[id].data) == 0
+	return len(c.buffers[id].data) == 0 && len(c.buffers[id].spilled) == 0
 }
 
 func (c *MergeJoinCache) postJoinSchemaBuilt() bool {
@@ -886,6 +1139,12 @@ func (c *MergeJoinCache) join(left, right *execute.ColListTableBuilder) (flux.Ta
 	// Perform sort merge join
 	for !leftSet.Empty() && !rightSet.Empty() {
 		if equalJoinkeys(leftKey, rightKey) {
+			if c.isOuter(c.leftID) {
+				c.buffers[c.leftID].markMatched(left.Key(), leftSet, left.NRows())
+			}
+			if c.isOuter(c.rightID) {
+				c.buffers[c.rightID].markMatched(right.Key(), rightSet, right.NRows())
+			}
 
 			for l := leftSet.Start; l < leftSet.Stop; l++ {
 				for r := rightSet.Start; r < rightSet.Stop; r++ {
@@ -931,6 +1190,230 @@ func (c *MergeJoinCache) join(left, right *execute.ColListTableBuilder) (flux.Ta
 	return builder.Table()
 }
 
+// buildUnmatchedTables adds the rows of the outer streams that did not match
+// any row of the opposing stream to the output tables. The rows of the
+// opposing stream are null. It must be called once both streams have finished.
+func (c *MergeJoinCache) buildUnmatchedTables() error {
+	if c.method == "inner" {
+		return nil
+	}
+
+	// One of the streams may not have produced any tables.
+	if !c.postJoinSchemaBuilt() {
+		c.buildPostJoinSchema()
+	}
+
+	// Make room for the tables that are read back below. The tables
+	// are not spilled again, since they would be read back right away.
+	if err := c.spill(); err != nil {
+		return err
+	}
+
+	// Join every table that is still pending so that
+	// all matched rows have been marked. The output tables
+	// are stored by group key instance, so keep track of the
+	// instances that are already in use.
+	var err error
+	keys := execute.NewGroupLookup()
+	c.postJoinKeys.Range(func(key flux.GroupKey, value interface{}) {
+		if err != nil {
+			return
+		}
+		keys.Set(key, key)
+		if _, ok := c.tables[key]; ok {
+			return
+		}
+		preJoinGroupKeys := c.reverseLookup[key]
+		var leftBuilder, rightBuilder *execute.ColListTableBuilder
+		if leftBuilder, err = c.buffers[c.leftID].table(preJoinGroupKeys.left); err != nil {
+			return
+		}
+		if rightBuilder, err = c.buffers[c.rightID].table(preJoinGroupKeys.right); err != nil {
+			return
+		}
+
+		var table flux.Table
+		if table, err = c.join(leftBuilder, rightBuilder); err == nil && !table.Empty() {
+			c.tables[key] = table
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range []execute.DatasetID{c.leftID, c.rightID} {
+		if !c.isOuter(id) {
+			continue
+		}
+		buf := c.buffers[id]
+		var bufferedKeys []flux.GroupKey
+		buf.iterate(func(key flux.GroupKey) {
+			bufferedKeys = append(bufferedKeys, key)
+		})
+		for _, key := range bufferedKeys {
+			builder, err := buf.table(key)
+			if err != nil {
+				return err
+			}
+			if err := c.addUnmatchedRows(id, builder, buf.matched[key], keys); err != nil {
+				return err
+			}
+		}
+		for _, builder := range buf.unjoinable {
+			if err := c.addUnmatchedRows(id, builder, nil, keys); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addUnmatchedRows adds the rows of a buffered table that are not marked as matched
+// to the output table for their post-join group key.
+func (c *MergeJoinCache) addUnmatchedRows(id execute.DatasetID, table *execute.ColListTableBuilder, matched []bool, keys *execute.GroupLookup) error {
+	n := 0
+	for i := 0; i < table.NRows(); i++ {
+		if i >= len(matched) || !matched[i] {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// Map each output column to its column in the buffered table.
+	colMap := make([]int, len(c.schema.columns))
+	for j := range colMap {
+		colMap[j] = -1
+	}
+	for j, column := range table.Cols() {
+		newColumn, ok := c.schemaMap[tableCol{table: c.names[id], col: column.Label}]
+		if !ok {
+			continue
+		}
+		newColumnIdx := execute.ColIdx(newColumn.Label, c.schema.columns)
+		if newColumnIdx < 0 {
+			continue
+		}
+		// The column may have another type in the output table when it is a join
+		// column of a different type in the opposing stream, or in another table
+		// of this stream.
+		if typ := c.schema.columns[newColumnIdx].Type; typ != column.Type {
+			return errors.Newf(codes.FailedPrecondition, "schema collision detected: column \"%s\" is both of type %s and %s", newColumn.Label, typ, column.Type)
+		}
+		colMap[newColumnIdx] = j
+	}
+
+	key := c.unmatchedGroupKey(id, table.Key())
+	if k, ok := keys.Lookup(key); ok {
+		key = k.(flux.GroupKey)
+	} else {
+		keys.Set(key, key)
+	}
+
+	builder := execute.NewColListTableBuilder(key, c.alloc)
+	for _, column := range c.schema.columns {
+		if _, err := builder.AddCol(column); err != nil {
+			return err
+		}
+	}
+
+	// Rows with the same post-join group key may already exist
+	// when the opposing stream has null values in its group key.
+	if existing, ok := c.tables[key]; ok {
+		if err := execute.AppendTable(existing, builder); err != nil {
+			return err
+		}
+	}
+
+	tbl, err := table.Table()
+	if err != nil {
+		return err
+	}
+	cr := tbl.(flux.ColReader)
+	for i := 0; i < cr.Len(); i++ {
+		if i < len(matched) && matched[i] {
+			continue
+		}
+		for j, idx := range colMap {
+			if idx < 0 {
+				if err := builder.AppendNil(j); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := builder.AppendValue(j, execute.ValueForRow(cr, i, idx)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Keep the output ordered by the join columns like the matched rows.
+	builder.Sort(c.order, false)
+
+	out, err := builder.Table()
+	if err != nil {
+		return err
+	}
+
+	var empty struct{}
+	if _, ok := c.reverseLookup[key]; !ok {
+		preJoinGroupKeys := preJoinGroupKeys{}
+		if id == c.leftID {
+			preJoinGroupKeys.left = table.Key()
+		} else {
+			preJoinGroupKeys.right = table.Key()
+		}
+		c.reverseLookup[key] = preJoinGroupKeys
+	}
+	c.postJoinKeys.Set(key, empty)
+	c.tables[key] = out
+	return nil
+}
+
+// unmatchedGroupKey produces the post-join group key for the unmatched rows of a table
+// from the stream associated with id. The group key columns of the opposing stream
+// are null, except for the join columns that are not already part of the key.
+func (c *MergeJoinCache) unmatchedGroupKey(id execute.DatasetID, preJoinKey flux.GroupKey) flux.GroupKey {
+	other := c.rightID
+	if id == c.rightID {
+		other = c.leftID
+	}
+
+	key := groupKey{
+		cols: make([]flux.ColMeta, 0, len(preJoinKey.Cols())+len(c.schemas[other].key)),
+		vals: make([]values.Value, 0, len(preJoinKey.Cols())+len(c.schemas[other].key)),
+	}
+	added := make(map[string]bool, cap(key.cols))
+
+	for j, column := range preJoinKey.Cols() {
+		colMeta, ok := c.schemaMap[tableCol{table: c.names[id], col: column.Label}]
+		if !ok || added[colMeta.Label] {
+			continue
+		}
+		key.cols = append(key.cols, colMeta)
+		key.vals = append(key.vals, preJoinKey.Value(j))
+		added[colMeta.Label] = true
+	}
+
+	for _, column := range c.schemas[other].key {
+		// The values of the join columns come from the unmatched rows.
+		if c.on[column.Label] {
+			continue
+		}
+		colMeta, ok := c.schemaMap[tableCol{table: c.names[other], col: column.Label}]
+		if !ok || added[colMeta.Label] {
+			continue
+		}
+		key.cols = append(key.cols, colMeta)
+		key.vals = append(key.vals, values.NewNull(flux.SemanticType(colMeta.Type)))
+		added[colMeta.Label] = true
+	}
+
+	sort.Sort(key)
+	return execute.NewGroupKey(key.cols, key.vals)
+}
+
 // postJoinGroupKey produces a new group key value from a left and a right group key value
 func (c *MergeJoinCache) postJoinGroupKey(keys map[execute.DatasetID]flux.GroupKey) flux.GroupKey {
 	key := groupKey{
@@ -2,12 +2,16 @@ package universe_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/scratch"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/plan"
@@ -1472,6 +1476,322 @@ func TestMergeJoin_Process(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "simple left",
+			spec: &universe.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				TableNames: tableNames,
+				Method:     "left",
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(3), 3.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, 10.0},
+						{execute.Time(2), 2.0, nil},
+						{execute.Time(3), 3.0, 30.0},
+					},
+				},
+			},
+		},
+		{
+			name: "simple right",
+			spec: &universe.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				TableNames: tableNames,
+				Method:     "right",
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(3), 3.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, 10.0},
+						{execute.Time(3), 3.0, 30.0},
+						{execute.Time(4), nil, 40.0},
+					},
+				},
+			},
+		},
+		{
+			name: "simple full",
+			spec: &universe.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				TableNames: tableNames,
+				Method:     "full",
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(3), 3.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, 10.0},
+						{execute.Time(2), 2.0, nil},
+						{execute.Time(3), 3.0, 30.0},
+						{execute.Time(4), nil, 40.0},
+					},
+				},
+			},
+		},
+		{
+			name: "left with nulls in join columns",
+			spec: &universe.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				TableNames: tableNames,
+				Method:     "left",
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{nil, 0.0},
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{nil, 10.0},
+						{execute.Time(2), 20.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{nil, 0.0, nil},
+						{execute.Time(1), 1.0, nil},
+						{execute.Time(2), 2.0, 20.0},
+					},
+				},
+			},
+		},
+		{
+			name: "left with unmatched tables",
+			spec: &universe.MergeJoinProcedureSpec{
+				On:         []string{"_time", "t1"},
+				TableNames: tableNames,
+				Method:     "left",
+			},
+			data0: []*executetest.Table{
+				{
+					KeyCols: []string{"t1"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "t1", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, "a"},
+						{execute.Time(2), 2.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"t1"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "t1", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.5, "b"},
+						{execute.Time(2), 2.5, "b"},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					KeyCols: []string{"t1", "t2"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "t1", Type: flux.TString},
+						{Label: "t2", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0, "a", "x"},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"t1", "t2"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+						{Label: "t1", Type: flux.TString},
+						{Label: "t2", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, 10.0, "a", "x"},
+					},
+				},
+				{
+					KeyCols:   []string{"t1", "t2"},
+					KeyValues: []interface{}{"a", nil},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+						{Label: "t1", Type: flux.TString},
+						{Label: "t2", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 2.0, nil, "a", nil},
+					},
+				},
+				{
+					KeyCols:   []string{"t1", "t2"},
+					KeyValues: []interface{}{"b", nil},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_a", Type: flux.TFloat},
+						{Label: "_value_b", Type: flux.TFloat},
+						{Label: "t1", Type: flux.TString},
+						{Label: "t2", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.5, nil, "b", nil},
+						{execute.Time(2), 2.5, nil, "b", nil},
+					},
+				},
+			},
+		},
+		{
+			name: "full with empty stream",
+			spec: &universe.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				TableNames: tableNames,
+				Method:     "full",
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+					},
+				},
+			},
+		},
 		{
 			name: "two failures",
 			spec: &universe.MergeJoinProcedureSpec{
@@ -1501,58 +1821,208 @@ func TestMergeJoin_Process(t *testing.T) {
 	for _, tc := range testCases {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			id0 := executetest.RandomDatasetID()
-			id1 := executetest.RandomDatasetID()
+			testMergeJoin(t, tc.spec, tc.data0, tc.data1, tc.want, false)
+		})
+		t.Run(tc.name+" with spilling", func(t *testing.T) {
+			testMergeJoin(t, tc.spec, tc.data0, tc.data1, tc.want, true)
+		})
+	}
+}
 
-			parents := []execute.DatasetID{
-				execute.DatasetID(id0),
-				execute.DatasetID(id1),
-			}
+func TestMergeJoin_UnmatchedSchemaCollision(t *testing.T) {
+	parents := []execute.DatasetID{
+		executetest.RandomDatasetID(),
+		executetest.RandomDatasetID(),
+	}
+	tableNames := map[execute.DatasetID]string{
+		parents[0]: "a",
+		parents[1]: "b",
+	}
+	spec := &universe.MergeJoinProcedureSpec{
+		On:         []string{"t1"},
+		TableNames: []string{"a", "b"},
+		Method:     "right",
+	}
 
-			tableNames := make(map[execute.DatasetID]string, len(tc.spec.TableNames))
-			for i, name := range tc.spec.TableNames {
-				tableNames[parents[i]] = name
-			}
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := universe.NewMergeJoinCache(executetest.UnlimitedAllocator, parents, tableNames, spec.On, spec.Method)
+	c.SetTriggerSpec(plan.DefaultTriggerSpec)
+	jt := universe.NewMergeJoinTransformation(d, c, spec, parents, tableNames)
+
+	// The join column is a string in the left stream and an integer
+	// in the right stream, so the unmatched right rows do not fit
+	// in the output table.
+	if err := jt.Process(parents[0], &executetest.Table{
+		ColMeta: []flux.ColMeta{
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "t1", Type: flux.TString},
+		},
+		Data: [][]interface{}{
+			{1.0, "a"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := jt.Process(parents[1], &executetest.Table{
+		ColMeta: []flux.ColMeta{
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "t1", Type: flux.TInt},
+		},
+		Data: [][]interface{}{
+			{10.0, int64(1)},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	jt.Finish(parents[0], nil)
+	jt.Finish(parents[1], nil)
+
+	want := `schema collision detected: column "t1" is both of type string and int`
+	if d.FinishedErr == nil {
+		t.Fatalf("expected error %q", want)
+	} else if got := d.FinishedErr.Error(); got != want {
+		t.Errorf("unexpected error -want/+got\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestMergeJoin_SpillRestoreError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-spill-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	parents := []execute.DatasetID{
+		executetest.RandomDatasetID(),
+		executetest.RandomDatasetID(),
+	}
+	tableNames := map[execute.DatasetID]string{
+		parents[0]: "a",
+		parents[1]: "b",
+	}
+	spec := &universe.MergeJoinProcedureSpec{
+		On:         []string{"t1"},
+		TableNames: []string{"a", "b"},
+		Method:     "inner",
+	}
+
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := universe.NewMergeJoinCache(executetest.UnlimitedAllocator, parents, tableNames, spec.On, spec.Method)
+	c.SetTriggerSpec(plan.DefaultTriggerSpec)
+	c.EnableSpilling(execute.NewSpiller(unreadableScratch{Service: scratch.Dir(dir)}, executetest.UnlimitedAllocator, 0))
+	jt := universe.NewMergeJoinTransformation(d, c, spec, parents, tableNames)
+
+	for _, id := range parents {
+		if err := jt.Process(id, &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "t1", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{1.0, "a"},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jt.Finish(parents[0], nil)
+	jt.Finish(parents[1], nil)
 
-			d := executetest.NewDataset(executetest.RandomDatasetID())
-			c := universe.NewMergeJoinCache(executetest.UnlimitedAllocator, parents, tableNames, tc.spec.On)
-			c.SetTriggerSpec(plan.DefaultTriggerSpec)
-			jt := universe.NewMergeJoinTransformation(d, c, tc.spec, parents, tableNames)
+	// The buffered tables were spilled and cannot be read back,
+	// so the cache must report the read error for the output table.
+	if _, err := executetest.TablesFromCache(c); err == nil {
+		t.Fatal("expected error")
+	} else if want, got := "disk failure", err.Error(); !strings.Contains(got, want) {
+		t.Errorf("expected error to contain %q, got %q", want, got)
+	}
+}
 
-			l := len(tc.data0)
-			if len(tc.data1) > l {
-				l = len(tc.data1)
+// unreadableScratch is a scratch service whose files cannot be read back.
+type unreadableScratch struct {
+	scratch.Service
+}
+
+func (s unreadableScratch) Create() (scratch.File, error) {
+	f, err := s.Service.Create()
+	if err != nil {
+		return nil, err
+	}
+	return unreadableFile{File: f}, nil
+}
+
+type unreadableFile struct {
+	scratch.File
+}
+
+func (f unreadableFile) Read(p []byte) (int, error) {
+	return 0, errors.New("disk failure")
+}
+
+func testMergeJoin(t *testing.T, spec *universe.MergeJoinProcedureSpec, data0, data1, want []*executetest.Table, spill bool) {
+	t.Helper()
+
+	id0 := executetest.RandomDatasetID()
+	id1 := executetest.RandomDatasetID()
+
+	parents := []execute.DatasetID{
+		execute.DatasetID(id0),
+		execute.DatasetID(id1),
+	}
+
+	tableNames := make(map[execute.DatasetID]string, len(spec.TableNames))
+	for i, name := range spec.TableNames {
+		tableNames[parents[i]] = name
+	}
+
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := universe.NewMergeJoinCache(executetest.UnlimitedAllocator, parents, tableNames, spec.On, spec.Method)
+	c.SetTriggerSpec(plan.DefaultTriggerSpec)
+	if spill {
+		spiller, cleanup := newTestSpiller(t, executetest.UnlimitedAllocator)
+		defer cleanup()
+		c.EnableSpilling(spiller)
+	}
+	jt := universe.NewMergeJoinTransformation(d, c, spec, parents, tableNames)
+
+	// The input tables are copied because a table can only be read once
+	// and each test case is run both with and without spilling.
+	process := func(id execute.DatasetID, tbl *executetest.Table) error {
+		cpy := *tbl
+		return jt.Process(id, &cpy)
+	}
+
+	l := len(data0)
+	if len(data1) > l {
+		l = len(data1)
+	}
+	var err error
+	for i := 0; i < l; i++ {
+		if i < len(data0) {
+			if err = process(parents[0], data0[i]); err != nil {
+				break
 			}
-			var err error
-			for i := 0; i < l; i++ {
-				if i < len(tc.data0) {
-					if err = jt.Process(parents[0], tc.data0[i]); err != nil {
-						break
-					}
-				}
-				if i < len(tc.data1) {
-					if err = jt.Process(parents[1], tc.data1[i]); err != nil {
-						break
-					}
-				}
+		}
+		if i < len(data1) {
+			if err = process(parents[1], data1[i]); err != nil {
+				break
 			}
-			jt.Finish(parents[0], err)
-			jt.Finish(parents[1], err)
+		}
+	}
+	jt.Finish(parents[0], err)
+	jt.Finish(parents[1], err)
 
-			got, err := executetest.TablesFromCache(c)
-			if err != nil {
-				t.Fatal(err)
-			}
+	got, err := executetest.TablesFromCache(c)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-			executetest.NormalizeTables(got)
-			executetest.NormalizeTables(tc.want)
+	executetest.NormalizeTables(got)
+	executetest.NormalizeTables(want)
 
-			sort.Sort(executetest.SortedTables(got))
-			sort.Sort(executetest.SortedTables(tc.want))
+	sort.Sort(executetest.SortedTables(got))
+	sort.Sort(executetest.SortedTables(want))
 
-			if !cmp.Equal(tc.want, got) {
-				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, got))
-			}
-		})
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(want, got))
 	}
 }
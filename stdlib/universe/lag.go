@@ -0,0 +1,298 @@
+package universe
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	LagKind  = "lag"
+	LeadKind = "lead"
+)
+
+type LagOpSpec struct {
+	Column     string       `json:"column"`
+	Offset     int64        `json:"offset"`
+	Default    values.Value `json:"default,omitempty"`
+	ColumnName string       `json:"columnName"`
+}
+
+type LeadOpSpec struct {
+	LagOpSpec
+}
+
+func init() {
+	lagSignature := runtime.MustLookupBuiltinType("universe", "lag")
+	leadSignature := runtime.MustLookupBuiltinType("universe", "lead")
+
+	runtime.RegisterPackageValue("universe", LagKind, flux.MustValue(flux.FunctionValue(LagKind, createLagOpSpec, lagSignature)))
+	runtime.RegisterPackageValue("universe", LeadKind, flux.MustValue(flux.FunctionValue(LeadKind, createLeadOpSpec, leadSignature)))
+	flux.RegisterOpSpec(LagKind, newLagOp)
+	flux.RegisterOpSpec(LeadKind, newLeadOp)
+	plan.RegisterProcedureSpec(LagKind, newLagProcedure, LagKind, LeadKind)
+	execute.RegisterTransformation(LagKind, createLagTransformation)
+}
+
+func createLagOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(LagOpSpec)
+	if err := spec.readArgs(args, LagKind); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func createLeadOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(LeadOpSpec)
+	if err := spec.readArgs(args, LeadKind); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func (s *LagOpSpec) readArgs(args flux.Arguments, kind string) error {
+	if col, ok, err := args.GetString("column"); err != nil {
+		return err
+	} else if ok {
+		s.Column = col
+	} else {
+		s.Column = execute.DefaultValueColLabel
+	}
+
+	if offset, ok, err := args.GetInt("offset"); err != nil {
+		return err
+	} else if ok {
+		if offset < 0 {
+			return errors.Newf(codes.Invalid, "%s offset must not be negative, got %d", kind, offset)
+		}
+		s.Offset = offset
+	} else {
+		s.Offset = 1
+	}
+
+	if def, ok := args.Get("default"); ok {
+		if flux.ColumnType(def.Type()) == flux.TInvalid {
+			return errors.Newf(codes.Invalid, "%s default must be a basic type, got %v", kind, def.Type())
+		}
+		s.Default = def
+	}
+
+	if name, ok, err := args.GetString("columnName"); err != nil {
+		return err
+	} else if ok {
+		s.ColumnName = name
+	} else {
+		s.ColumnName = kind
+	}
+	return nil
+}
+
+func newLagOp() flux.OperationSpec {
+	return new(LagOpSpec)
+}
+
+func (s *LagOpSpec) Kind() flux.OperationKind {
+	return LagKind
+}
+
+func newLeadOp() flux.OperationSpec {
+	return new(LeadOpSpec)
+}
+
+func (s *LeadOpSpec) Kind() flux.OperationKind {
+	return LeadKind
+}
+
+// LagProcedureSpec is the procedure of both lag and lead.
+// Lead reads the rows that follow each row instead of the
+// rows that precede it.
+type LagProcedureSpec struct {
+	plan.DefaultCost
+	Column     string
+	Offset     int64
+	Default    values.Value
+	ColumnName string
+	Lead       bool
+}
+
+func newLagProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	var (
+		spec *LagOpSpec
+		lead bool
+	)
+	switch s := qs.(type) {
+	case *LagOpSpec:
+		spec = s
+	case *LeadOpSpec:
+		spec, lead = &s.LagOpSpec, true
+	default:
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &LagProcedureSpec{
+		Column:     spec.Column,
+		Offset:     spec.Offset,
+		Default:    spec.Default,
+		ColumnName: spec.ColumnName,
+		Lead:       lead,
+	}, nil
+}
+
+func (s *LagProcedureSpec) Kind() plan.ProcedureKind {
+	return LagKind
+}
+
+func (s *LagProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createLagTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*LagProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewLagTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type lagTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	column     string
+	offset     int
+	def        values.Value
+	columnName string
+	lead       bool
+}
+
+func NewLagTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *LagProcedureSpec) *lagTransformation {
+	return &lagTransformation{
+		d:     d,
+		cache: cache,
+
+		column:     spec.Column,
+		offset:     int(spec.Offset),
+		def:        spec.Default,
+		columnName: spec.ColumnName,
+		lead:       spec.Lead,
+	}
+}
+
+func (t *lagTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *lagTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *lagTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *lagTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+func (t *lagTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "found duplicate table with key: %v", tbl.Key())
+	}
+	if err := execute.AddTableCols(tbl, builder); err != nil {
+		return err
+	}
+
+	cols := tbl.Cols()
+	valueIdx := execute.ColIdx(t.column, cols)
+	if valueIdx < 0 {
+		return errors.Newf(codes.FailedPrecondition, "column %q does not exist", t.column)
+	}
+	typ := cols[valueIdx].Type
+	def := t.def
+	if def == nil {
+		def = values.Null
+	} else if flux.ColumnType(def.Type()) != typ {
+		return errors.Newf(codes.FailedPrecondition, "default value of type %v does not match column %q of type %v", def.Type(), t.column, typ)
+	}
+	outIdx, err := addAnalyticCol(builder, t.columnName, typ)
+	if err != nil {
+		return err
+	}
+	colMap := execute.ColMap(nil, builder, cols)
+
+	// The ring holds the values of the last offset rows and starts
+	// with the default value. The lag value of a row is read from the
+	// ring before the value of the row replaces it. The lead value of
+	// a row is only known offset rows later, so the lead column is
+	// appended behind the other columns and completed with the default.
+	var ring []values.Value
+	if !t.lead {
+		ring = make([]values.Value, t.offset)
+		for k := range ring {
+			ring[k] = def
+		}
+	}
+	n := 0
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		if err := execute.AppendMappedCols(cr, builder, colMap); err != nil {
+			return err
+		}
+		for i, l := 0, cr.Len(); i < l; i++ {
+			v := execute.ValueForRow(cr, i, valueIdx)
+			switch {
+			case t.offset == 0:
+			case t.lead:
+				if n < t.offset {
+					n++
+					continue
+				}
+			default:
+				k := n % t.offset
+				v, ring[k] = ring[k], v
+			}
+			n++
+			if err := builder.AppendValue(outIdx, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if t.lead {
+		for i := 0; i < t.offset && i < n; i++ {
+			if err := builder.AppendValue(outIdx, def); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addAnalyticCol adds the column that an analytic function
+// computes for each row to the builder.
+func addAnalyticCol(builder execute.TableBuilder, label string, typ flux.ColType) (int, error) {
+	if execute.ColIdx(label, builder.Cols()) >= 0 {
+		return -1, errors.Newf(codes.FailedPrecondition, "column %q already exists", label)
+	}
+	return builder.AddCol(flux.ColMeta{
+		Label: label,
+		Type:  typ,
+	})
+}
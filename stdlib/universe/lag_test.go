@@ -0,0 +1,283 @@
+package universe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
+)
+
+func TestLag_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := universe.NewLagTransformation(
+			d,
+			c,
+			&universe.LagProcedureSpec{},
+		)
+		return s
+	})
+}
+
+func TestLag_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *universe.LagProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "lag",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_value",
+				Offset:     1,
+				ColumnName: "lag",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0},
+					{execute.Time(2), 1.0},
+					{execute.Time(3), nil},
+					{execute.Time(4), 4.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "lag", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0, nil},
+					{execute.Time(2), 1.0, 2.0},
+					{execute.Time(3), nil, 1.0},
+					{execute.Time(4), 4.0, nil},
+				},
+			}},
+		},
+		{
+			name: "lag with offset and default across reads",
+			spec: &universe.LagProcedureSpec{
+				Column:     "host",
+				Offset:     2,
+				Default:    values.NewString("none"),
+				ColumnName: "prev",
+			},
+			data: []flux.Table{&executetest.RowWiseTable{
+				Table: &executetest.Table{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "host", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), "a"},
+						{execute.Time(2), "b"},
+						{execute.Time(3), "c"},
+						{execute.Time(4), "d"},
+						{execute.Time(5), "e"},
+					},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "host", Type: flux.TString},
+					{Label: "prev", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "a", "none"},
+					{execute.Time(2), "b", "none"},
+					{execute.Time(3), "c", "a"},
+					{execute.Time(4), "d", "b"},
+					{execute.Time(5), "e", "c"},
+				},
+			}},
+		},
+		{
+			name: "lead",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_value",
+				Offset:     1,
+				ColumnName: "lead",
+				Lead:       true,
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(2)},
+					{execute.Time(2), int64(1)},
+					{execute.Time(3), nil},
+					{execute.Time(4), int64(4)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+					{Label: "lead", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(2), int64(1)},
+					{execute.Time(2), int64(1), nil},
+					{execute.Time(3), nil, int64(4)},
+					{execute.Time(4), int64(4), nil},
+				},
+			}},
+		},
+		{
+			name: "lead with offset and default across reads",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_time",
+				Offset:     2,
+				Default:    values.NewTime(execute.Time(0)),
+				ColumnName: "next",
+				Lead:       true,
+			},
+			data: []flux.Table{&executetest.RowWiseTable{
+				Table: &executetest.Table{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TBool},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), true},
+						{execute.Time(2), false},
+						{execute.Time(3), true},
+						{execute.Time(4), nil},
+					},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TBool},
+					{Label: "next", Type: flux.TTime},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), true, execute.Time(3)},
+					{execute.Time(2), false, execute.Time(4)},
+					{execute.Time(3), true, execute.Time(0)},
+					{execute.Time(4), nil, execute.Time(0)},
+				},
+			}},
+		},
+		{
+			name: "lead longer than table",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_value",
+				Offset:     3,
+				ColumnName: "lead",
+				Lead:       true,
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "_value", Type: flux.TUInt},
+				},
+				Data: [][]interface{}{
+					{"a", uint64(1)},
+					{"a", uint64(2)},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "_value", Type: flux.TUInt},
+					{Label: "lead", Type: flux.TUInt},
+				},
+				Data: [][]interface{}{
+					{"a", uint64(1), nil},
+					{"a", uint64(2), nil},
+				},
+			}},
+		},
+		{
+			name: "zero offset",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_value",
+				ColumnName: "lag",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0},
+					{2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "lag", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0, 1.0},
+					{2.0, 2.0},
+				},
+			}},
+		},
+		{
+			name: "default of another type",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_value",
+				Offset:     1,
+				Default:    values.NewInt(0),
+				ColumnName: "lag",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0},
+				},
+			}},
+			wantErr: errors.New(`default value of type int does not match column "_value" of type float`),
+		},
+		{
+			name: "existing column",
+			spec: &universe.LagProcedureSpec{
+				Column:     "_value",
+				Offset:     1,
+				ColumnName: "_value",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0},
+				},
+			}},
+			wantErr: errors.New(`column "_value" already exists`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewLagTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
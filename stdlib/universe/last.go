@@ -69,6 +69,9 @@ func (s *LastProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *LastProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *LastProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
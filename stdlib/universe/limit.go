@@ -93,6 +93,22 @@ func (s *LimitProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
 }
 
+// Cost estimates that at most N rows are kept from each table.
+func (s *LimitProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	return limitCost(s.N, inStats)
+}
+
+// limitCost estimates the cost and statistics of
+// keeping at most n rows from each table.
+func limitCost(n int64, inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	stats := plan.CombineStatistics(inStats)
+	cost := plan.Cost{CPU: stats.Cardinality}
+	if stats.GroupCardinality > 0 && n < stats.Cardinality/stats.GroupCardinality {
+		stats.Cardinality = n * stats.GroupCardinality
+	}
+	return cost, stats
+}
+
 func createLimitTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
 	s, ok := spec.(*LimitProcedureSpec)
 	if !ok {
@@ -4,12 +4,14 @@ import (
 	"context"
 	"sort"
 
+	"github.com/apache/arrow/go/arrow/array"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/compiler"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
@@ -108,6 +110,7 @@ func createMapTransformation(id execute.DatasetID, mode execute.AccumulationMode
 	if err != nil {
 		return nil, nil, err
 	}
+	t.alloc = a.Allocator()
 	return t, d, nil
 }
 
@@ -118,6 +121,10 @@ type mapTransformation struct {
 	ctx      context.Context
 	fn       *execute.RowMapFn
 	mergeKey bool
+
+	// alloc is used for the arrays produced by the vectorized
+	// map function. A nil allocator uses the default allocator.
+	alloc *memory.Allocator
 }
 
 func NewMapTransformation(ctx context.Context, spec *MapProcedureSpec, d execute.Dataset, cache execute.TableBuilderCache) (*mapTransformation, error) {
@@ -148,56 +155,174 @@ func (t *mapTransformation) Process(id execute.DatasetID, tbl flux.Table) error
 	// didn't really use type inference at all so I removed its usage
 	// in favor of the real returned type.
 
+	if vfn := fn.Vectorized(); vfn != nil && !t.mergeKey {
+		if key, ok := vectorizedGroupKey(vfn, tbl); ok {
+			return t.processVectorized(fn, vfn, key, tbl)
+		}
+	}
+
 	var on map[string]bool
 	return tbl.Do(func(cr flux.ColReader) error {
-		l := cr.Len()
-		for i := 0; i < l; i++ {
-			m, err := fn.Eval(t.ctx, i, cr)
+		return t.processRows(fn, tbl, cr, &on)
+	})
+}
+
+// processRows evaluates the map function one row at a time.
+func (t *mapTransformation) processRows(fn *execute.RowMapPreparedFn, tbl flux.Table, cr flux.ColReader, on *map[string]bool) error {
+	l := cr.Len()
+	for i := 0; i < l; i++ {
+		m, err := fn.Eval(t.ctx, i, cr)
+		if err != nil {
+			return errors.Wrap(err, codes.Invalid, "failed to evaluate map function")
+		}
+
+		// If we haven't determined the columns to group on, do that now.
+		if *on == nil {
+			var err error
+			*on, err = t.groupOn(tbl.Key(), m.Type())
 			if err != nil {
-				return errors.Wrap(err, codes.Invalid, "failed to evaluate map function")
+				return err
 			}
+		}
 
-			// If we haven't determined the columns to group on, do that now.
-			if on == nil {
-				var err error
-				on, err = t.groupOn(tbl.Key(), m.Type())
-				if err != nil {
-					return err
-				}
+		key := groupKeyForObject(i, cr, m, *on)
+		builder, created := t.cache.TableBuilder(key)
+		if created {
+			if err := t.createSchema(fn, builder, m); err != nil {
+				return err
 			}
+		}
 
-			key := groupKeyForObject(i, cr, m, on)
-			builder, created := t.cache.TableBuilder(key)
-			if created {
-				if err := t.createSchema(fn, builder, m); err != nil {
-					return err
+		for j, c := range builder.Cols() {
+			v, ok := m.Get(c.Label)
+			if !ok {
+				if idx := execute.ColIdx(c.Label, tbl.Key().Cols()); t.mergeKey && idx >= 0 {
+					v = tbl.Key().Value(idx)
+				} else {
+					// This should be unreachable
+					return errors.Newf(codes.Internal, "could not find value for column %q", c.Label)
 				}
 			}
+			if !v.IsNull() && c.Type.String() != v.Type().Nature().String() {
+				return errors.Newf(codes.Internal, "column %s:%s is not of type %v",
+					c.Label, c.Type, v.Type(),
+				)
+			}
+			if err := builder.AppendValue(j, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-			for j, c := range builder.Cols() {
-				v, ok := m.Get(c.Label)
-				if !ok {
-					if idx := execute.ColIdx(c.Label, tbl.Key().Cols()); t.mergeKey && idx >= 0 {
-						v = tbl.Key().Value(idx)
-					} else {
-						// This should be unreachable
-						return errors.Newf(codes.Internal, "could not find value for column %q", c.Label)
-					}
-				}
-				if !v.IsNull() && c.Type.String() != v.Type().Nature().String() {
-					return errors.Newf(codes.Internal, "column %s:%s is not of type %v",
-						c.Label, c.Type, v.Type(),
-					)
-				}
-				if err := builder.AppendValue(j, v); err != nil {
+// vectorizedGroupKey returns the group key of the rows produced by the
+// vectorized map function. Every row has the same group key when the
+// function either drops or does not modify the group key columns.
+func vectorizedGroupKey(fn compiler.VectorizedFunc, tbl flux.Table) (flux.GroupKey, bool) {
+	labels := fn.Labels()
+	key := tbl.Key()
+	cols := make([]flux.ColMeta, 0, len(key.Cols()))
+	vs := make([]values.Value, 0, len(key.Cols()))
+	for _, c := range tbl.Cols() {
+		idx := execute.ColIdx(c.Label, key.Cols())
+		if idx < 0 {
+			continue
+		}
+		if i := sort.SearchStrings(labels, c.Label); i == len(labels) || labels[i] != c.Label {
+			continue
+		}
+		if !fn.Passthrough(c.Label) {
+			return nil, false
+		}
+		cols = append(cols, key.Cols()[idx])
+		vs = append(vs, key.Value(idx))
+	}
+	return execute.NewGroupKey(cols, vs), true
+}
+
+// processVectorized evaluates the map function for all of the rows
+// of each column reader at once.
+func (t *mapTransformation) processVectorized(fn *execute.RowMapPreparedFn, vfn compiler.VectorizedFunc, key flux.GroupKey, tbl flux.Table) error {
+	labels := vfn.Labels()
+	props := vfn.Type()
+	types := make([]flux.ColType, len(labels))
+	for i := range labels {
+		prop, err := props.RecordProperty(i)
+		if err != nil {
+			return err
+		}
+		typ, err := prop.TypeOf()
+		if err != nil {
+			return err
+		}
+		types[i] = execute.ConvertFromKind(typ.Nature())
+	}
+
+	var on map[string]bool
+	return tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() == 0 {
+			return nil
+		}
+
+		builder, created := t.cache.TableBuilder(key)
+		if created {
+			for i, label := range labels {
+				if _, err := builder.AddCol(flux.ColMeta{Label: label, Type: types[i]}); err != nil {
 					return err
 				}
 			}
+		} else if !hasColumns(builder.Cols(), labels, types) {
+			// A previous table produced a different schema
+			// for the same group key.
+			return t.processRows(fn, tbl, cr, &on)
+		}
+
+		vs, err := vfn.Eval(t.ctx, cr, t.alloc)
+		if err != nil {
+			return errors.Wrap(err, codes.Invalid, "failed to evaluate map function")
+		}
+		defer func() {
+			for _, v := range vs {
+				v.Release()
+			}
+		}()
+
+		for j, v := range vs {
+			switch types[j] {
+			case flux.TBool:
+				err = builder.AppendBools(j, v.(*array.Boolean))
+			case flux.TInt:
+				err = builder.AppendInts(j, v.(*array.Int64))
+			case flux.TUInt:
+				err = builder.AppendUInts(j, v.(*array.Uint64))
+			case flux.TFloat:
+				err = builder.AppendFloats(j, v.(*array.Float64))
+			case flux.TString:
+				err = builder.AppendStrings(j, v.(*array.Binary))
+			case flux.TTime:
+				err = builder.AppendTimes(j, v.(*array.Int64))
+			}
+			if err != nil {
+				return err
+			}
 		}
 		return nil
 	})
 }
 
+func hasColumns(cols []flux.ColMeta, labels []string, types []flux.ColType) bool {
+	if len(cols) != len(labels) {
+		return false
+	}
+	for j, c := range cols {
+		if c.Label != labels[j] || c.Type != types[j] {
+			return false
+		}
+	}
+	return true
+}
+
 func (t *mapTransformation) groupOn(key flux.GroupKey, m semantic.MonoType) (map[string]bool, error) {
 	on := make(map[string]bool, len(key.Cols()))
 	for _, c := range key.Cols() {
@@ -69,6 +69,9 @@ func (s *MaxProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *MaxProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *MaxProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
@@ -70,6 +70,9 @@ func (s *MeanProcedureSpec) Copy() plan.ProcedureSpec {
 	}
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *MeanProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *MeanProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
@@ -0,0 +1,251 @@
+package universe
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/sketch"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const MergeSketchesKind = "mergeSketches"
+
+type MergeSketchesOpSpec struct {
+	Column string `json:"column"`
+	// Quantile overrides the quantile that merged t-digests estimate.
+	Quantile *float64 `json:"quantile,omitempty"`
+	Sketch   bool     `json:"sketch,omitempty"`
+}
+
+func init() {
+	mergeSketchesSignature := runtime.MustLookupBuiltinType("universe", "mergeSketches")
+
+	runtime.RegisterPackageValue("universe", MergeSketchesKind, flux.MustValue(flux.FunctionValue(MergeSketchesKind, createMergeSketchesOpSpec, mergeSketchesSignature)))
+	flux.RegisterOpSpec(MergeSketchesKind, newMergeSketchesOp)
+	plan.RegisterProcedureSpec(MergeSketchesKind, newMergeSketchesProcedure, MergeSketchesKind)
+	execute.RegisterTransformation(MergeSketchesKind, createMergeSketchesTransformation)
+}
+
+func createMergeSketchesOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := new(MergeSketchesOpSpec)
+
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	} else {
+		spec.Column = execute.DefaultValueColLabel
+	}
+
+	if q, ok, err := args.GetFloat("q"); err != nil {
+		return nil, err
+	} else if ok {
+		if q < 0 || q > 1 {
+			return nil, errors.New(codes.Invalid, "quantile must be between 0 and 1")
+		}
+		spec.Quantile = &q
+	}
+
+	if s, ok, err := args.GetBool("sketch"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Sketch = s
+	}
+
+	if spec.Quantile != nil && spec.Sketch {
+		return nil, errors.New(codes.Invalid, "q cannot be used when the merged sketch is returned")
+	}
+	return spec, nil
+}
+
+func newMergeSketchesOp() flux.OperationSpec {
+	return new(MergeSketchesOpSpec)
+}
+
+func (s *MergeSketchesOpSpec) Kind() flux.OperationKind {
+	return MergeSketchesKind
+}
+
+type MergeSketchesProcedureSpec struct {
+	plan.DefaultCost
+	Column   string
+	Quantile *float64
+	Sketch   bool
+}
+
+func newMergeSketchesProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*MergeSketchesOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &MergeSketchesProcedureSpec{
+		Column:   spec.Column,
+		Quantile: spec.Quantile,
+		Sketch:   spec.Sketch,
+	}, nil
+}
+
+func (s *MergeSketchesProcedureSpec) Kind() plan.ProcedureKind {
+	return MergeSketchesKind
+}
+
+func (s *MergeSketchesProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Quantile != nil {
+		q := *s.Quantile
+		ns.Quantile = &q
+	}
+	return &ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *MergeSketchesProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createMergeSketchesTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*MergeSketchesProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewMergeSketchesTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type mergeSketchesTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	column   string
+	quantile *float64
+	sketch   bool
+}
+
+func NewMergeSketchesTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *MergeSketchesProcedureSpec) *mergeSketchesTransformation {
+	return &mergeSketchesTransformation{
+		d:     d,
+		cache: cache,
+
+		column:   spec.Column,
+		quantile: spec.Quantile,
+		sketch:   spec.Sketch,
+	}
+}
+
+func (t *mergeSketchesTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *mergeSketchesTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *mergeSketchesTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *mergeSketchesTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// Process merges the sketches of the table into a single row
+// like an aggregate. The type of the output column depends on
+// the kind of the sketches unless the merged sketch is returned.
+func (t *mergeSketchesTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "mergeSketches found duplicate table with key: %v", tbl.Key())
+	}
+	if err := execute.AddTableKeyCols(tbl.Key(), builder); err != nil {
+		return err
+	}
+
+	idx := execute.ColIdx(t.column, tbl.Cols())
+	if idx < 0 {
+		return errors.Newf(codes.FailedPrecondition, "column %q does not exist", t.column)
+	}
+	if tbl.Key().HasCol(t.column) {
+		return errors.New(codes.FailedPrecondition, "cannot aggregate columns that are part of the group key")
+	}
+	if typ := tbl.Cols()[idx].Type; typ != flux.TString {
+		return errors.Newf(codes.FailedPrecondition, "sketches must be strings, column %q is of type %v", t.column, typ)
+	}
+
+	var merged sketch.Sketch
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		vs := cr.Strings(idx)
+		for i := 0; i < vs.Len(); i++ {
+			if vs.IsNull(i) {
+				continue
+			}
+			s, err := sketch.Unmarshal(vs.ValueString(i))
+			if err != nil {
+				return err
+			}
+			if merged == nil {
+				merged = s
+			} else if err := sketch.Merge(merged, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := t.appendValue(builder, merged); err != nil {
+		return err
+	}
+	return execute.AppendKeyValues(tbl.Key(), builder)
+}
+
+func (t *mergeSketchesTransformation) appendValue(builder execute.TableBuilder, merged sketch.Sketch) error {
+	typ := flux.TString
+	if !t.sketch {
+		switch merged.(type) {
+		case *sketch.HyperLogLog:
+			typ = flux.TInt
+		case *sketch.TDigest:
+			typ = flux.TFloat
+		}
+	}
+	j, err := builder.AddCol(flux.ColMeta{
+		Label: t.column,
+		Type:  typ,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch s := merged.(type) {
+	case nil:
+		// There is no sketch to estimate a value from.
+		return builder.AppendNil(j)
+	case *sketch.HyperLogLog:
+		if t.sketch {
+			return builder.AppendString(j, sketch.Marshal(s))
+		}
+		return builder.AppendInt(j, int64(s.Count()))
+	case *sketch.TDigest:
+		if t.sketch {
+			return builder.AppendString(j, sketch.Marshal(s))
+		}
+		q := s.Quantile
+		if t.quantile != nil {
+			q = *t.quantile
+		}
+		return builder.AppendFloat(j, s.Digest.Quantile(q))
+	default:
+		return errors.Newf(codes.Internal, "unexpected sketch type %T", merged)
+	}
+}
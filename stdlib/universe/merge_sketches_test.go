@@ -0,0 +1,270 @@
+package universe_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/sketch"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func hllSketch(t *testing.T, from, to int) string {
+	t.Helper()
+	h, err := sketch.NewHyperLogLog(sketch.DefaultPrecision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := from; i < to; i++ {
+		h.Add(xxhash.Sum64String(strconv.Itoa(i)))
+	}
+	return sketch.Marshal(h)
+}
+
+func tdigestSketch(q float64, vs ...float64) string {
+	td := sketch.NewTDigest(1000, q)
+	for _, v := range vs {
+		td.Digest.Add(v, 1)
+	}
+	return sketch.Marshal(td)
+}
+
+func TestMergeSketches_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "mergeSketches",
+			Raw:  `from(bucket:"mydb") |> mergeSketches(q: 0.9)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "mergeSketches1",
+						Spec: &universe.MergeSketchesOpSpec{
+							Column:   "_value",
+							Quantile: func(q float64) *float64 { return &q }(0.9),
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "mergeSketches1"},
+				},
+			},
+		},
+		{
+			Name:    "quantile out of range",
+			Raw:     `from(bucket:"mydb") |> mergeSketches(q: 1.5)`,
+			WantErr: true,
+		},
+		{
+			Name:    "quantile with sketch",
+			Raw:     `from(bucket:"mydb") |> mergeSketches(q: 0.5, sketch: true)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestMergeSketchesOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"mergeSketches","kind":"mergeSketches","spec":{"column":"_value","sketch":true}}`)
+	op := &flux.Operation{
+		ID: "mergeSketches",
+		Spec: &universe.MergeSketchesOpSpec{
+			Column: "_value",
+			Sketch: true,
+		},
+	}
+
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestMergeSketches_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := universe.NewMergeSketchesTransformation(
+			d,
+			c,
+			&universe.MergeSketchesProcedureSpec{Column: "_value"},
+		)
+		return s
+	})
+}
+
+func TestMergeSketches_Process(t *testing.T) {
+	q := 1.0
+	testCases := []struct {
+		name    string
+		spec    *universe.MergeSketchesProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "hyperloglog",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value"},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "t0", Type: flux.TString},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "a", hllSketch(t, 0, 6)},
+					{execute.Time(2), "a", nil},
+					{execute.Time(3), "a", hllSketch(t, 4, 10)},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{"a", int64(10)},
+				},
+			}},
+		},
+		{
+			name: "tdigest",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value"},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{tdigestSketch(0.5, 1, 2, 3)},
+					{tdigestSketch(0.5, 4, 5)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{3.0},
+				},
+			}},
+		},
+		{
+			name: "tdigest with quantile",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value", Quantile: &q},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{tdigestSketch(0.5, 1, 2, 3)},
+					{tdigestSketch(0.5, 4, 5)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{5.0},
+				},
+			}},
+		},
+		{
+			name: "sketch",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value", Sketch: true},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{hllSketch(t, 0, 6)},
+					{hllSketch(t, 4, 10)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{hllSketch(t, 0, 10)},
+				},
+			}},
+		},
+		{
+			name: "only nulls",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value"},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{nil},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{nil},
+				},
+			}},
+		},
+		{
+			name: "different kinds",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value"},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{hllSketch(t, 0, 6)},
+					{tdigestSketch(0.5, 1)},
+				},
+			}},
+			wantErr: errors.New(codes.Invalid, "cannot merge a tdigest sketch into a hll sketch"),
+		},
+		{
+			name: "not a sketch",
+			spec: &universe.MergeSketchesProcedureSpec{Column: "_value"},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0},
+				},
+			}},
+			wantErr: errors.New(codes.FailedPrecondition, `sketches must be strings, column "_value" is of type float`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewMergeSketchesTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
@@ -69,6 +69,9 @@ func (s *MinProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *MinProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *MinProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
@@ -0,0 +1,178 @@
+package universe
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const NtileKind = "ntile"
+
+type NtileOpSpec struct {
+	N          int64  `json:"n"`
+	ColumnName string `json:"columnName"`
+}
+
+func init() {
+	ntileSignature := runtime.MustLookupBuiltinType("universe", "ntile")
+
+	runtime.RegisterPackageValue("universe", NtileKind, flux.MustValue(flux.FunctionValue(NtileKind, createNtileOpSpec, ntileSignature)))
+	flux.RegisterOpSpec(NtileKind, newNtileOp)
+	plan.RegisterProcedureSpec(NtileKind, newNtileProcedure, NtileKind)
+	execute.RegisterTransformation(NtileKind, createNtileTransformation)
+}
+
+func createNtileOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := new(NtileOpSpec)
+
+	n, err := args.GetRequiredInt("n")
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, errors.Newf(codes.Invalid, "ntile n must be greater than zero, got %d", n)
+	}
+	spec.N = n
+
+	if name, ok, err := args.GetString("columnName"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.ColumnName = name
+	} else {
+		spec.ColumnName = NtileKind
+	}
+
+	return spec, nil
+}
+
+func newNtileOp() flux.OperationSpec {
+	return new(NtileOpSpec)
+}
+
+func (s *NtileOpSpec) Kind() flux.OperationKind {
+	return NtileKind
+}
+
+type NtileProcedureSpec struct {
+	plan.DefaultCost
+	N          int64
+	ColumnName string
+}
+
+func newNtileProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*NtileOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &NtileProcedureSpec{
+		N:          spec.N,
+		ColumnName: spec.ColumnName,
+	}, nil
+}
+
+func (s *NtileProcedureSpec) Kind() plan.ProcedureKind {
+	return NtileKind
+}
+
+func (s *NtileProcedureSpec) Copy() plan.ProcedureSpec {
+	return &NtileProcedureSpec{
+		N:          s.N,
+		ColumnName: s.ColumnName,
+	}
+}
+
+func createNtileTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*NtileProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewNtileTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type ntileTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	n          int64
+	columnName string
+}
+
+func NewNtileTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *NtileProcedureSpec) *ntileTransformation {
+	return &ntileTransformation{
+		d:     d,
+		cache: cache,
+
+		n:          spec.N,
+		columnName: spec.ColumnName,
+	}
+}
+
+func (t *ntileTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *ntileTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *ntileTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *ntileTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+func (t *ntileTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "found duplicate table with key: %v", tbl.Key())
+	}
+	if err := execute.AddTableCols(tbl, builder); err != nil {
+		return err
+	}
+	outIdx, err := addAnalyticCol(builder, t.columnName, flux.TInt)
+	if err != nil {
+		return err
+	}
+	colMap := execute.ColMap(nil, builder, tbl.Cols())
+
+	// The buckets depend on the number of rows in the table, so the
+	// rows are appended first and the buckets once they are counted.
+	var rows int64
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		rows += int64(cr.Len())
+		return execute.AppendMappedCols(cr, builder, colMap)
+	}); err != nil {
+		return err
+	}
+	for i := int64(0); i < rows; i++ {
+		if err := builder.AppendInt(outIdx, ntileBucket(i, rows, t.n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ntileBucket returns the bucket of row i when rows are divided into
+// n buckets. The buckets hold the same number of rows except that the
+// first rows%n buckets hold one more row. Buckets are numbered from 1.
+func ntileBucket(i, rows, n int64) int64 {
+	size, large := rows/n, rows%n
+	if i < large*(size+1) {
+		return i/(size+1) + 1
+	}
+	return large + (i-large*(size+1))/size + 1
+}
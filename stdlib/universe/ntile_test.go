@@ -0,0 +1,102 @@
+package universe_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestNtileOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"ntile","kind":"ntile","spec":{"n":4,"columnName":"quartile"}}`)
+	op := &flux.Operation{
+		ID: "ntile",
+		Spec: &universe.NtileOpSpec{
+			N:          4,
+			ColumnName: "quartile",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestNtile_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := universe.NewNtileTransformation(
+			d,
+			c,
+			&universe.NtileProcedureSpec{N: 1},
+		)
+		return s
+	})
+}
+
+func TestNtile_Process(t *testing.T) {
+	testCases := []struct {
+		name  string
+		n     int64
+		rows  int
+		wantN []int64
+	}{
+		{
+			name:  "even",
+			n:     2,
+			rows:  4,
+			wantN: []int64{1, 1, 2, 2},
+		},
+		{
+			name:  "remainder in first buckets",
+			n:     3,
+			rows:  8,
+			wantN: []int64{1, 1, 1, 2, 2, 2, 3, 3},
+		},
+		{
+			name:  "more buckets than rows",
+			n:     5,
+			rows:  3,
+			wantN: []int64{1, 2, 3},
+		},
+		{
+			name:  "single bucket",
+			n:     1,
+			rows:  3,
+			wantN: []int64{1, 1, 1},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			in := &executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+			}
+			want := &executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "ntile", Type: flux.TInt},
+				},
+			}
+			for i := 0; i < tc.rows; i++ {
+				in.Data = append(in.Data, []interface{}{execute.Time(i), float64(i)})
+				want.Data = append(want.Data, []interface{}{execute.Time(i), float64(i), tc.wantN[i]})
+			}
+			executetest.ProcessTestHelper(
+				t,
+				[]flux.Table{&executetest.RowWiseTable{Table: in}},
+				[]*executetest.Table{want},
+				nil,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewNtileTransformation(d, c, &universe.NtileProcedureSpec{
+						N:          tc.n,
+						ColumnName: "ntile",
+					})
+				},
+			)
+		})
+	}
+}
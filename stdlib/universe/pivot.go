@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/table"
+	"github.com/influxdata/flux/internal/arrowutil"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/memory"
@@ -168,12 +169,15 @@ func createPivotTransformation(id execute.DatasetID, mode execute.AccumulationMo
 		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
 	}
 
+	spiller := execute.SpillerFromContext(a.Context(), a.Allocator())
+
 	// Attempt to use the new pivot transformation if it is implemented for our inputs.
-	if t, d, err := newPivotTransformation2(a.Context(), *s, id, a.Allocator()); err == nil || flux.ErrorCode(err) != codes.Unimplemented {
+	if t, d, err := newPivotTransformation2(a.Context(), *s, id, a.Allocator(), spiller); err == nil || flux.ErrorCode(err) != codes.Unimplemented {
 		return t, d, err
 	}
 
 	cache := execute.NewTableBuilderCache(a.Allocator())
+	cache.EnableSpilling(spiller)
 	d := execute.NewDataset(id, mode, cache)
 	t := NewPivotTransformation(d, cache, s)
 	return t, d, nil
@@ -270,7 +274,10 @@ func (t *pivotTransformation) Process(id execute.DatasetID, tbl flux.Table) erro
 	}
 
 	newGroupKey := execute.NewGroupKey(keyCols, keyValues)
-	builder, created := t.cache.TableBuilder(newGroupKey)
+	builder, created, err := t.tableBuilder(newGroupKey)
+	if err != nil {
+		return err
+	}
 	groupKeyString := newGroupKey.String()
 	if created {
 		for _, c := range cols {
@@ -285,7 +292,7 @@ func (t *pivotTransformation) Process(id execute.DatasetID, tbl flux.Table) erro
 		t.nextRowCol[groupKeyString] = rowCol{nextCol: len(cols), nextRow: 0}
 	}
 
-	return tbl.Do(func(cr flux.ColReader) error {
+	if err := tbl.Do(func(cr flux.ColReader) error {
 		for row := 0; row < cr.Len(); row++ {
 			rowKey := ""
 			colKey := ""
@@ -352,7 +359,25 @@ func (t *pivotTransformation) Process(id execute.DatasetID, tbl flux.Table) erro
 
 		}
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	// Move the pivoted tables into scratch space if memory is running low.
+	if cache, ok := t.cache.(execute.SpillingTableBuilderCache); ok {
+		return cache.Spill()
+	}
+	return nil
+}
+
+// tableBuilder returns the builder for the group key and reports
+// whether it was created. A builder that has been spilled is read back.
+func (t *pivotTransformation) tableBuilder(key flux.GroupKey) (execute.TableBuilder, bool, error) {
+	if cache, ok := t.cache.(execute.SpillingTableBuilderCache); ok {
+		return cache.RestoreTableBuilder(key)
+	}
+	builder, created := t.cache.TableBuilder(key)
+	return builder, created, nil
 }
 
 func growColumn(builder execute.TableBuilder, colIdx, nRows int) error {
@@ -435,12 +460,15 @@ type pivotTransformation2 struct {
 	alloc  *memory.Allocator
 	spec   PivotProcedureSpec
 	groups *execute.GroupLookup
+	// spiller moves the buffered columns into scratch
+	// space when memory is running low.
+	spiller *execute.Spiller
 
 	watermark  execute.Time
 	processing execute.Time
 }
 
-func newPivotTransformation2(ctx context.Context, spec PivotProcedureSpec, id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset, error) {
+func newPivotTransformation2(ctx context.Context, spec PivotProcedureSpec, id execute.DatasetID, alloc *memory.Allocator, spiller *execute.Spiller) (execute.Transformation, execute.Dataset, error) {
 	if len(spec.RowKey) != 1 {
 		return nil, nil, errors.New(codes.Unimplemented, "only pivots with 1 row key are implemented")
 	} else if !spec.isSortedBy(spec.RowKey, false) {
@@ -452,11 +480,12 @@ func newPivotTransformation2(ctx context.Context, spec PivotProcedureSpec, id ex
 		return nil, nil, errors.New(codes.Unimplemented, "column key must be part of the group key")
 	}
 	t := &pivotTransformation2{
-		d:      execute.NewPassthroughDataset(id),
-		ctx:    ctx,
-		alloc:  alloc,
-		spec:   spec,
-		groups: execute.NewGroupLookup(),
+		d:       execute.NewPassthroughDataset(id),
+		ctx:     ctx,
+		alloc:   alloc,
+		spec:    spec,
+		groups:  execute.NewGroupLookup(),
+		spiller: spiller,
 	}
 	return t, t.d, nil
 }
@@ -499,7 +528,7 @@ func (t *pivotTransformation2) Process(id execute.DatasetID, tbl flux.Table) err
 
 	// Read the table and insert each of the column readers
 	// into the table group.
-	return tbl.Do(func(cr flux.ColReader) error {
+	if err := tbl.Do(func(cr flux.ColReader) error {
 		colKey := t.spec.ColumnKey[0]
 		key := cr.Key().LabelValue(colKey)
 		if key == nil {
@@ -532,7 +561,31 @@ func (t *pivotTransformation2) Process(id execute.DatasetID, tbl flux.Table) err
 		k, v := t.getColumn(cr, rowIndex), t.getColumn(cr, valueIndex)
 		buf.Insert(k, v)
 		return nil
+	}); err != nil {
+		return err
+	}
+	return t.spill()
+}
+
+// spill moves the buffered columns of every group
+// into scratch space if memory is running low.
+func (t *pivotTransformation2) spill() error {
+	if !t.spiller.ShouldSpill() {
+		return nil
+	}
+	var err error
+	t.groups.Range(func(key flux.GroupKey, value interface{}) {
+		if err != nil {
+			return
+		}
+		gr := value.(*pivotTableGroup)
+		for _, buf := range gr.buffers {
+			if err = buf.spill(t.spiller, key, gr.rowCol); err != nil {
+				return
+			}
+		}
 	})
+	return err
 }
 
 func (t *pivotTransformation2) validateTable(tbl flux.Table) error {
@@ -634,6 +687,9 @@ func (t *pivotTransformation2) Finish(id execute.DatasetID, err error) {
 
 		var tbl flux.Table
 		gr := value.(*pivotTableGroup)
+		if err = gr.restore(t.alloc); err != nil {
+			return
+		}
 		tbl, err = gr.doPivot(key, t.alloc)
 		if err != nil {
 			return
@@ -642,6 +698,13 @@ func (t *pivotTransformation2) Finish(id execute.DatasetID, err error) {
 			return
 		}
 	})
+	t.groups.Range(func(key flux.GroupKey, value interface{}) {
+		// Remove the spill files of the groups that were not
+		// pivoted because of an error.
+		for _, buf := range value.(*pivotTableGroup).buffers {
+			buf.closeSpill()
+		}
+	})
 	t.groups.Clear()
 
 	if err = t.d.UpdateWatermark(t.watermark); err != nil {
@@ -656,6 +719,84 @@ type pivotTableBuffer struct {
 	keys      []array.Interface
 	valueType flux.ColType
 	values    []array.Interface
+	// spilled holds the keys and values that were moved into
+	// scratch space. They come before the ones in memory.
+	spilled *execute.SpillFile
+}
+
+// spill writes the keys and values in memory into scratch space and releases them.
+func (b *pivotTableBuffer) spill(spiller *execute.Spiller, key flux.GroupKey, rowCol flux.ColMeta) error {
+	if len(b.keys) == 0 {
+		return nil
+	}
+	cols := []flux.ColMeta{
+		rowCol,
+		{Label: execute.DefaultValueColLabel, Type: b.valueType},
+	}
+	if b.spilled == nil {
+		f, err := spiller.Create(key, cols)
+		if err != nil {
+			return err
+		}
+		b.spilled = f
+	}
+	for i, k := range b.keys {
+		if err := b.spilled.Write(&arrow.TableBuffer{
+			GroupKey: key,
+			Columns:  cols,
+			Values:   []array.Interface{k, b.values[i]},
+		}); err != nil {
+			return err
+		}
+	}
+	b.Release()
+	b.keys, b.values = nil, nil
+	return nil
+}
+
+// restore reads the spilled keys and values back into memory.
+func (b *pivotTableBuffer) restore(mem arrowmemory.Allocator) error {
+	if b.spilled == nil {
+		return nil
+	}
+	tbl, err := b.spilled.Table()
+	b.spilled = nil
+	if err != nil {
+		return err
+	}
+	var keys, values []array.Interface
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		// The buffers read from scratch space are released
+		// once this returns, so the arrays are copied.
+		for j, col := range cr.Cols() {
+			arr := table.Values(cr, j)
+			builder := arrow.NewBuilder(col.Type, mem)
+			arrowutil.CopyTo(builder, arr)
+			if j == 0 {
+				keys = append(keys, builder.NewArray())
+			} else {
+				values = append(values, builder.NewArray())
+			}
+			builder.Release()
+		}
+		return nil
+	}); err != nil {
+		for _, arr := range append(keys, values...) {
+			arr.Release()
+		}
+		return err
+	}
+	b.keys = append(keys, b.keys...)
+	b.values = append(values, b.values...)
+	return nil
+}
+
+// closeSpill removes the spilled keys and values, if any.
+func (b *pivotTableBuffer) closeSpill() {
+	if b.spilled != nil {
+		_ = b.spilled.Close()
+		b.spilled = nil
+	}
 }
 
 func (b *pivotTableBuffer) Insert(k, v array.Interface) {
@@ -679,6 +820,16 @@ type pivotTableGroup struct {
 	buffers map[string]*pivotTableBuffer
 }
 
+// restore reads the spilled columns of the group back into memory.
+func (gr *pivotTableGroup) restore(mem arrowmemory.Allocator) error {
+	for _, buf := range gr.buffers {
+		if err := buf.restore(mem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (gr *pivotTableGroup) doPivot(key flux.GroupKey, mem arrowmemory.Allocator) (flux.Table, error) {
 	// Merge all of the keys from each buffer.
 	keys := gr.mergeKeys(mem)
@@ -12,5 +12,11 @@ import (
 // externally as the new pivot is not meant to be exposed publically
 // at the moment and the name will probably change when it is exposed.
 func NewPivotTransformation2(ctx context.Context, spec PivotProcedureSpec, id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset, error) {
-	return newPivotTransformation2(ctx, spec, id, alloc)
+	return newPivotTransformation2(ctx, spec, id, alloc, nil)
+}
+
+// NewSpillingPivotTransformation2 is exposed so the tests can
+// spill the columns buffered by the new transformation.
+func NewSpillingPivotTransformation2(ctx context.Context, spec PivotProcedureSpec, id execute.DatasetID, alloc *memory.Allocator, spiller *execute.Spiller) (execute.Transformation, execute.Dataset, error) {
+	return newPivotTransformation2(ctx, spec, id, alloc, spiller)
 }
@@ -786,6 +786,71 @@ func TestPivot_Process(t *testing.T) {
 	}
 }
 
+func TestPivot_Process_Spill(t *testing.T) {
+	spec := &universe.PivotProcedureSpec{
+		RowKey:      []string{"_time"},
+		ColumnKey:   []string{"_field"},
+		ValueColumn: "_value",
+	}
+	data := []flux.Table{
+		&executetest.Table{
+			KeyCols: []string{"_measurement", "_field"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 1.0, "m1", "f1"},
+				{execute.Time(2), 3.0, "m1", "f1"},
+			},
+		},
+		&executetest.Table{
+			KeyCols: []string{"_measurement", "_field"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 2.0, "m1", "f2"},
+				{execute.Time(3), 4.0, "m1", "f2"},
+			},
+		},
+	}
+	want := []*executetest.Table{
+		{
+			KeyCols: []string{"_measurement"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "f1", Type: flux.TFloat},
+				{Label: "f2", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "m1", 1.0, 2.0},
+				{execute.Time(2), "m1", 3.0, nil},
+				{execute.Time(3), "m1", nil, 4.0},
+			},
+		},
+	}
+
+	spiller, cleanup := newTestSpiller(t, executetest.UnlimitedAllocator)
+	defer cleanup()
+	executetest.ProcessTestHelper(
+		t,
+		data,
+		want,
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			c.(execute.SpillingTableBuilderCache).EnableSpilling(spiller)
+			return universe.NewPivotTransformation(d, c, spec)
+		},
+	)
+}
+
 func TestPivot2_Process(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -1346,6 +1411,13 @@ func TestPivot2_Process(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		tc := tc
+		// The input tables are copied because a table can only be read
+		// once and each test case is run both with and without spilling.
+		data := make([]flux.Table, len(tc.data))
+		for i, tbl := range tc.data {
+			cpy := *tbl.(*executetest.Table)
+			data[i] = &cpy
+		}
 		t.Run(tc.name, func(t *testing.T) {
 			executetest.ProcessTestHelper2(
 				t,
@@ -1367,6 +1439,35 @@ func TestPivot2_Process(t *testing.T) {
 					return tr, d
 				})
 		})
+		t.Run(tc.name+" with spilling", func(t *testing.T) {
+			var cleanup func()
+			defer func() {
+				if cleanup != nil {
+					cleanup()
+				}
+			}()
+			executetest.ProcessTestHelper2(
+				t,
+				data,
+				tc.want,
+				nil,
+				func(id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset) {
+					spec := *tc.spec
+					spec.IsKeyColumnFunc = func(label string) bool {
+						return true
+					}
+					spec.IsSortedByFunc = func(cols []string, desc bool) bool {
+						return true
+					}
+					var spiller *execute.Spiller
+					spiller, cleanup = newTestSpiller(t, alloc)
+					tr, d, err := universe.NewSpillingPivotTransformation2(context.Background(), spec, id, alloc, spiller)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return tr, d
+				})
+		})
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/sketch"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
@@ -32,6 +33,7 @@ type QuantileOpSpec struct {
 	Quantile    float64 `json:"quantile"`
 	Compression float64 `json:"compression"`
 	Method      string  `json:"method"`
+	Sketch      bool    `json:"sketch,omitempty"`
 	// quantile is either an aggregate, or a selector based on the options
 	execute.AggregateConfig
 	execute.SelectorConfig
@@ -83,6 +85,16 @@ func CreateQuantileOpSpec(args flux.Arguments, a *flux.Administration) (flux.Ope
 		return nil, errors.New(codes.Invalid, "compression parameter is only valid for method estimate_tdigest")
 	}
 
+	if s, ok, err := args.GetBool("sketch"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Sketch = s
+	}
+
+	if spec.Sketch && spec.Method != methodEstimateTdigest {
+		return nil, errors.New(codes.Invalid, "sketch parameter is only valid for method estimate_tdigest")
+	}
+
 	// Set default Compression if not exact
 	if spec.Method == methodEstimateTdigest && spec.Compression == 0 {
 		spec.Compression = 1000
@@ -115,6 +127,9 @@ func (s *QuantileOpSpec) Kind() flux.OperationKind {
 type TDigestQuantileProcedureSpec struct {
 	Quantile    float64 `json:"quantile"`
 	Compression float64 `json:"compression"`
+	// Sketch reports whether the t-digest is emitted
+	// instead of the quantile it estimates.
+	Sketch bool `json:"sketch"`
 	execute.AggregateConfig
 }
 
@@ -125,6 +140,7 @@ func (s *TDigestQuantileProcedureSpec) Copy() plan.ProcedureSpec {
 	return &TDigestQuantileProcedureSpec{
 		Quantile:        s.Quantile,
 		Compression:     s.Compression,
+		Sketch:          s.Sketch,
 		AggregateConfig: s.AggregateConfig,
 	}
 }
@@ -191,6 +207,7 @@ func newQuantileProcedure(qs flux.OperationSpec, a plan.Administration) (plan.Pr
 		return &TDigestQuantileProcedureSpec{
 			Quantile:        spec.Quantile,
 			Compression:     spec.Compression,
+			Sketch:          spec.Sketch,
 			AggregateConfig: spec.AggregateConfig,
 		}, nil
 	}
@@ -199,6 +216,9 @@ func newQuantileProcedure(qs flux.OperationSpec, a plan.Administration) (plan.Pr
 type QuantileAgg struct {
 	Quantile,
 	Compression float64
+	// Sketch reports whether the serialized t-digest
+	// is the value of the aggregate.
+	Sketch bool
 
 	digest *tdigest.TDigest
 	ok     bool
@@ -218,6 +238,7 @@ func createQuantileTransformation(id execute.DatasetID, mode execute.Accumulatio
 		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", ps)
 	}
 	agg := NewQuantileAgg(ps.Quantile, ps.Compression)
+	agg.Sketch = ps.Sketch
 	err := a.Allocator().Account(tdigest.ByteSizeForCompression(agg.Compression))
 	if err != nil {
 		return nil, nil, errors.Newf(codes.Internal, "could not allocate memory for tdigest: %s", err)
@@ -263,6 +284,9 @@ func (a *QuantileAgg) DoFloat(vs *array.Float64) {
 }
 
 func (a *QuantileAgg) Type() flux.ColType {
+	if a.Sketch {
+		return flux.TString
+	}
 	return flux.TFloat
 }
 
@@ -270,8 +294,17 @@ func (a *QuantileAgg) ValueFloat() float64 {
 	return a.digest.Quantile(a.Quantile)
 }
 
+func (a *QuantileAgg) ValueString() string {
+	return sketch.Marshal(&sketch.TDigest{
+		Digest:      a.digest,
+		Compression: a.Compression,
+		Quantile:    a.Quantile,
+	})
+}
+
 func (a *QuantileAgg) IsNull() bool {
-	return !a.ok
+	// An empty sketch can still be merged with other sketches.
+	return !a.ok && !a.Sketch
 }
 
 type ExactQuantileAgg struct {
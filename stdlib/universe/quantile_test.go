@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/flux/arrow"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/sketch"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/querytest"
 	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
@@ -470,6 +471,34 @@ func TestQuantile_Process(t *testing.T) {
 	}
 }
 
+func TestQuantile_Sketch(t *testing.T) {
+	agg := universe.NewQuantileAgg(0.75, 1000)
+	agg.Sketch = true
+	vs := agg.NewFloatAgg()
+	data := arrow.NewFloat([]float64{1, 2, 3, 4, 5}, nil)
+	defer data.Release()
+	vs.DoFloat(data)
+
+	if want, got := flux.TString, vs.Type(); want != got {
+		t.Fatalf("unexpected type: want %v, got %v", want, got)
+	}
+	s, err := sketch.Unmarshal(vs.(execute.StringValueFunc).ValueString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	td, ok := s.(*sketch.TDigest)
+	if !ok {
+		t.Fatalf("unexpected sketch type %T", s)
+	}
+	if td.Compression != 1000 || td.Quantile != 0.75 {
+		t.Errorf("unexpected parameters: want (1000, 0.75), got (%v, %v)", td.Compression, td.Quantile)
+	}
+	// The serialized t-digest estimates the same quantile as the aggregate.
+	if want, got := vs.(execute.FloatValueFunc).ValueFloat(), td.Digest.Quantile(td.Quantile); want != got {
+		t.Errorf("unexpected quantile: want %v, got %v", want, got)
+	}
+}
+
 func TestQuantileSelector_Process(t *testing.T) {
 	testCases := []struct {
 		name     string
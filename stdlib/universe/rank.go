@@ -0,0 +1,301 @@
+package universe
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	RowNumberKind = "rowNumber"
+	RankKind      = "rank"
+	DenseRankKind = "denseRank"
+)
+
+type RowNumberOpSpec struct {
+	ColumnName string `json:"columnName"`
+}
+
+type RankOpSpec struct {
+	Columns    []string `json:"columns"`
+	ColumnName string   `json:"columnName"`
+}
+
+type DenseRankOpSpec struct {
+	RankOpSpec
+}
+
+func init() {
+	rowNumberSignature := runtime.MustLookupBuiltinType("universe", "rowNumber")
+	rankSignature := runtime.MustLookupBuiltinType("universe", "rank")
+	denseRankSignature := runtime.MustLookupBuiltinType("universe", "denseRank")
+
+	runtime.RegisterPackageValue("universe", RowNumberKind, flux.MustValue(flux.FunctionValue(RowNumberKind, createRowNumberOpSpec, rowNumberSignature)))
+	runtime.RegisterPackageValue("universe", RankKind, flux.MustValue(flux.FunctionValue(RankKind, createRankOpSpec, rankSignature)))
+	runtime.RegisterPackageValue("universe", DenseRankKind, flux.MustValue(flux.FunctionValue(DenseRankKind, createDenseRankOpSpec, denseRankSignature)))
+	flux.RegisterOpSpec(RowNumberKind, newRowNumberOp)
+	flux.RegisterOpSpec(RankKind, newRankOp)
+	flux.RegisterOpSpec(DenseRankKind, newDenseRankOp)
+	plan.RegisterProcedureSpec(RankKind, newRankProcedure, RowNumberKind, RankKind, DenseRankKind)
+	execute.RegisterTransformation(RankKind, createRankTransformation)
+}
+
+func createRowNumberOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := new(RowNumberOpSpec)
+
+	if name, ok, err := args.GetString("columnName"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.ColumnName = name
+	} else {
+		spec.ColumnName = RowNumberKind
+	}
+
+	return spec, nil
+}
+
+func createRankOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(RankOpSpec)
+	if err := spec.readArgs(args, RankKind); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func createDenseRankOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(DenseRankOpSpec)
+	if err := spec.readArgs(args, DenseRankKind); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func (s *RankOpSpec) readArgs(args flux.Arguments, kind string) error {
+	if cols, ok, err := args.GetArray("columns", semantic.String); err != nil {
+		return err
+	} else if ok {
+		columns, err := interpreter.ToStringArray(cols)
+		if err != nil {
+			return err
+		}
+		s.Columns = columns
+	} else {
+		s.Columns = []string{execute.DefaultValueColLabel}
+	}
+
+	if name, ok, err := args.GetString("columnName"); err != nil {
+		return err
+	} else if ok {
+		s.ColumnName = name
+	} else {
+		s.ColumnName = kind
+	}
+	return nil
+}
+
+func newRowNumberOp() flux.OperationSpec {
+	return new(RowNumberOpSpec)
+}
+
+func (s *RowNumberOpSpec) Kind() flux.OperationKind {
+	return RowNumberKind
+}
+
+func newRankOp() flux.OperationSpec {
+	return new(RankOpSpec)
+}
+
+func (s *RankOpSpec) Kind() flux.OperationKind {
+	return RankKind
+}
+
+func newDenseRankOp() flux.OperationSpec {
+	return new(DenseRankOpSpec)
+}
+
+func (s *DenseRankOpSpec) Kind() flux.OperationKind {
+	return DenseRankKind
+}
+
+// RankProcedureSpec is the procedure of rowNumber, rank and denseRank.
+// The method is the kind of the operation that computes the ranks.
+type RankProcedureSpec struct {
+	plan.DefaultCost
+	Method     string
+	Columns    []string
+	ColumnName string
+}
+
+func newRankProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	switch spec := qs.(type) {
+	case *RowNumberOpSpec:
+		return &RankProcedureSpec{
+			Method:     RowNumberKind,
+			ColumnName: spec.ColumnName,
+		}, nil
+	case *RankOpSpec:
+		return &RankProcedureSpec{
+			Method:     RankKind,
+			Columns:    spec.Columns,
+			ColumnName: spec.ColumnName,
+		}, nil
+	case *DenseRankOpSpec:
+		return &RankProcedureSpec{
+			Method:     DenseRankKind,
+			Columns:    spec.Columns,
+			ColumnName: spec.ColumnName,
+		}, nil
+	default:
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+}
+
+func (s *RankProcedureSpec) Kind() plan.ProcedureKind {
+	return RankKind
+}
+
+func (s *RankProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Columns != nil {
+		ns.Columns = make([]string, len(s.Columns))
+		copy(ns.Columns, s.Columns)
+	}
+	return &ns
+}
+
+func createRankTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*RankProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewRankTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type rankTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	method     string
+	columns    []string
+	columnName string
+}
+
+func NewRankTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *RankProcedureSpec) *rankTransformation {
+	return &rankTransformation{
+		d:     d,
+		cache: cache,
+
+		method:     spec.Method,
+		columns:    spec.Columns,
+		columnName: spec.ColumnName,
+	}
+}
+
+func (t *rankTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *rankTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *rankTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *rankTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+func (t *rankTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "found duplicate table with key: %v", tbl.Key())
+	}
+	if err := execute.AddTableCols(tbl, builder); err != nil {
+		return err
+	}
+
+	cols := tbl.Cols()
+	var rankCols []int
+	if t.method != RowNumberKind {
+		rankCols = make([]int, len(t.columns))
+		for i, label := range t.columns {
+			j := execute.ColIdx(label, cols)
+			if j < 0 {
+				return errors.Newf(codes.FailedPrecondition, "column %q does not exist", label)
+			}
+			rankCols[i] = j
+		}
+	}
+	outIdx, err := addAnalyticCol(builder, t.columnName, flux.TInt)
+	if err != nil {
+		return err
+	}
+	colMap := execute.ColMap(nil, builder, cols)
+
+	// The rows are ranked in the order they are read. Consecutive
+	// rows with equal values in the rank columns are peers and share
+	// the rank of the first of them.
+	var (
+		n, rank, dense int64
+		prev           = make([]values.Value, len(rankCols))
+	)
+	return tbl.Do(func(cr flux.ColReader) error {
+		if err := execute.AppendMappedCols(cr, builder, colMap); err != nil {
+			return err
+		}
+		for i, l := 0, cr.Len(); i < l; i++ {
+			n++
+			peer := n > 1
+			for k, j := range rankCols {
+				v := execute.ValueForRow(cr, i, j)
+				if peer && !rankValuesEqual(prev[k], v) {
+					peer = false
+				}
+				prev[k] = v
+			}
+			if !peer || t.method == RowNumberKind {
+				rank = n
+				dense++
+			}
+			out := rank
+			if t.method == DenseRankKind {
+				out = dense
+			}
+			if err := builder.AppendInt(outIdx, out); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// rankValuesEqual reports whether two values of a rank column are
+// equal. Null values are equal to each other so that they rank together.
+func rankValuesEqual(a, b values.Value) bool {
+	if a.IsNull() || b.IsNull() {
+		return a.IsNull() && b.IsNull()
+	}
+	return a.Equal(b)
+}
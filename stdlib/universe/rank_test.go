@@ -0,0 +1,187 @@
+package universe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestRankOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"rank","kind":"rank","spec":{"columns":["_value"],"columnName":"rank"}}`)
+	op := &flux.Operation{
+		ID: "rank",
+		Spec: &universe.RankOpSpec{
+			Columns:    []string{"_value"},
+			ColumnName: "rank",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestRowNumberOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"rowNumber","kind":"rowNumber","spec":{"columnName":"n"}}`)
+	op := &flux.Operation{
+		ID: "rowNumber",
+		Spec: &universe.RowNumberOpSpec{
+			ColumnName: "n",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestRank_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := universe.NewRankTransformation(
+			d,
+			c,
+			&universe.RankProcedureSpec{},
+		)
+		return s
+	})
+}
+
+func TestRank_Process(t *testing.T) {
+	data := func() []flux.Table {
+		return []flux.Table{&executetest.RowWiseTable{
+			Table: &executetest.Table{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{"a", execute.Time(1), 5.0},
+					{"a", execute.Time(2), 5.0},
+					{"a", execute.Time(3), 3.0},
+					{"a", execute.Time(4), nil},
+					{"a", execute.Time(5), nil},
+					{"a", execute.Time(6), 1.0},
+				},
+			},
+		}}
+	}
+	want := func(label string, ranks ...int64) []*executetest.Table {
+		tbl := &executetest.Table{
+			KeyCols: []string{"t0"},
+			ColMeta: []flux.ColMeta{
+				{Label: "t0", Type: flux.TString},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: label, Type: flux.TInt},
+			},
+			Data: [][]interface{}{
+				{"a", execute.Time(1), 5.0},
+				{"a", execute.Time(2), 5.0},
+				{"a", execute.Time(3), 3.0},
+				{"a", execute.Time(4), nil},
+				{"a", execute.Time(5), nil},
+				{"a", execute.Time(6), 1.0},
+			},
+		}
+		for i := range tbl.Data {
+			tbl.Data[i] = append(tbl.Data[i], ranks[i])
+		}
+		return []*executetest.Table{tbl}
+	}
+
+	testCases := []struct {
+		name    string
+		spec    *universe.RankProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "rowNumber",
+			spec: &universe.RankProcedureSpec{
+				Method:     universe.RowNumberKind,
+				ColumnName: "rowNumber",
+			},
+			data: data(),
+			want: want("rowNumber", 1, 2, 3, 4, 5, 6),
+		},
+		{
+			name: "rank",
+			spec: &universe.RankProcedureSpec{
+				Method:     universe.RankKind,
+				Columns:    []string{"_value"},
+				ColumnName: "rank",
+			},
+			data: data(),
+			want: want("rank", 1, 1, 3, 4, 4, 6),
+		},
+		{
+			name: "denseRank",
+			spec: &universe.RankProcedureSpec{
+				Method:     universe.DenseRankKind,
+				Columns:    []string{"_value"},
+				ColumnName: "denseRank",
+			},
+			data: data(),
+			want: want("denseRank", 1, 1, 2, 3, 3, 4),
+		},
+		{
+			name: "rank multiple columns",
+			spec: &universe.RankProcedureSpec{
+				Method:     universe.RankKind,
+				Columns:    []string{"host", "_value"},
+				ColumnName: "rank",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{"a", int64(1)},
+					{"a", int64(1)},
+					{"b", int64(1)},
+					{"b", int64(2)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "host", Type: flux.TString},
+					{Label: "_value", Type: flux.TInt},
+					{Label: "rank", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{"a", int64(1), int64(1)},
+					{"a", int64(1), int64(1)},
+					{"b", int64(1), int64(3)},
+					{"b", int64(2), int64(4)},
+				},
+			}},
+		},
+		{
+			name: "missing column",
+			spec: &universe.RankProcedureSpec{
+				Method:     universe.RankKind,
+				Columns:    []string{"host"},
+				ColumnName: "rank",
+			},
+			data:    data(),
+			wantErr: errors.New(`column "host" does not exist`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewRankTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/table"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
@@ -110,6 +112,10 @@ func createSortTransformation(id execute.DatasetID, mode execute.AccumulationMod
 		return newSortTransformation2(id, s, a)
 	}
 
+	if spiller := execute.SpillerFromContext(a.Context(), a.Allocator()); spiller != nil {
+		return NewSpillingSortTransformation(id, s, spiller, a.Allocator())
+	}
+
 	cache := execute.NewTableBuilderCache(a.Allocator())
 	d := execute.NewDataset(id, mode, cache)
 	t := NewSortTransformation(d, cache, s)
@@ -139,14 +145,7 @@ func (t *sortTransformation) RetractTable(id execute.DatasetID, key flux.GroupKe
 }
 
 func (t *sortTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
-	key := tbl.Key()
-	for _, label := range t.cols {
-		if key.HasCol(label) {
-			key = t.sortedKey(key)
-			break
-		}
-	}
-
+	key := sortedKey(tbl.Key(), t.cols)
 	builder, created := t.cache.TableBuilder(key)
 	if !created {
 		return errors.Newf(codes.FailedPrecondition, "sort found duplicate table with key: %v", tbl.Key())
@@ -172,11 +171,23 @@ func (t *sortTransformation) Finish(id execute.DatasetID, err error) {
 	t.d.Finish(err)
 }
 
-func (t *sortTransformation) sortedKey(key flux.GroupKey) flux.GroupKey {
+// sortedKey reorders the group key so the sort columns come first.
+func sortedKey(key flux.GroupKey, sortCols []string) flux.GroupKey {
+	hasSortCol := false
+	for _, label := range sortCols {
+		if key.HasCol(label) {
+			hasSortCol = true
+			break
+		}
+	}
+	if !hasSortCol {
+		return key
+	}
+
 	cols := make([]flux.ColMeta, len(key.Cols()))
 	vs := make([]values.Value, len(key.Cols()))
 	j := 0
-	for _, label := range t.cols {
+	for _, label := range sortCols {
 		idx := execute.ColIdx(label, key.Cols())
 		if idx >= 0 {
 			cols[j] = key.Cols()[idx]
@@ -185,7 +196,7 @@ func (t *sortTransformation) sortedKey(key flux.GroupKey) flux.GroupKey {
 		}
 	}
 	for idx, c := range key.Cols() {
-		if !execute.ContainsStr(t.cols, c.Label) {
+		if !execute.ContainsStr(sortCols, c.Label) {
 			cols[j] = c
 			vs[j] = key.Value(idx)
 			j++
@@ -193,3 +204,234 @@ func (t *sortTransformation) sortedKey(key flux.GroupKey) flux.GroupKey {
 	}
 	return execute.NewGroupKey(cols, vs)
 }
+
+const (
+	// defaultMinSortRunRows is the minimum number of rows in a sorted run.
+	// Memory can stay above the spill threshold once it has been passed,
+	// so this keeps a run from being written for every buffer.
+	defaultMinSortRunRows = 16 * table.BufferSize
+	// defaultMaxSortRuns is the number of sorted runs that are merged
+	// together at once. This bounds the number of spill files that are
+	// open for a table.
+	defaultMaxSortRuns = 16
+)
+
+// spillingSortTransformation sorts tables the same way as sortTransformation,
+// but it writes sorted runs of a table into scratch space when memory is
+// running low. The runs are merged back together once the table is complete.
+type spillingSortTransformation struct {
+	execute.ExecutionNode
+	d       *execute.PassthroughDataset
+	alloc   *memory.Allocator
+	spiller *execute.Spiller
+
+	cols []string
+	desc bool
+
+	minRunRows int
+	maxRuns    int
+}
+
+func NewSpillingSortTransformation(id execute.DatasetID, spec *SortProcedureSpec, spiller *execute.Spiller, alloc *memory.Allocator) (execute.Transformation, execute.Dataset, error) {
+	return newSpillingSortTransformation(id, spec, spiller, alloc, defaultMinSortRunRows, defaultMaxSortRuns)
+}
+
+func newSpillingSortTransformation(id execute.DatasetID, spec *SortProcedureSpec, spiller *execute.Spiller, alloc *memory.Allocator, minRunRows, maxRuns int) (execute.Transformation, execute.Dataset, error) {
+	t := &spillingSortTransformation{
+		d:          execute.NewPassthroughDataset(id),
+		alloc:      alloc,
+		spiller:    spiller,
+		cols:       spec.Columns,
+		desc:       spec.Desc,
+		minRunRows: minRunRows,
+		maxRuns:    maxRuns,
+	}
+	return t, t.d, nil
+}
+
+func (t *spillingSortTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+// sortRun is a sorted run in scratch space. Its level is the
+// number of times the rows in it have been merged.
+type sortRun struct {
+	file  *execute.SpillFile
+	level int
+}
+
+func closeSortRuns(runs []sortRun) {
+	for _, run := range runs {
+		_ = run.file.Close()
+	}
+}
+
+func (t *spillingSortTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	key := sortedKey(tbl.Key(), t.cols)
+	builder, err := t.newBuilder(key, tbl.Cols())
+	if err != nil {
+		return err
+	}
+
+	var runs []sortRun
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		if err := execute.AppendCols(cr, builder); err != nil {
+			return err
+		}
+		if builder.NRows() < t.minRunRows || !t.spiller.ShouldSpill() {
+			return nil
+		}
+
+		file, err := t.spill(builder)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, sortRun{file: file})
+		builder.Release()
+		builder = nil
+
+		merged, err := t.mergeRuns(runs)
+		if err != nil {
+			// The runs are closed when they cannot be merged.
+			runs = nil
+			return err
+		}
+		runs = merged
+		builder, err = t.newBuilder(key, tbl.Cols())
+		return err
+	}); err != nil {
+		if builder != nil {
+			builder.Release()
+		}
+		closeSortRuns(runs)
+		return err
+	}
+
+	// If nothing was spilled, the table is sorted in memory.
+	if len(runs) == 0 {
+		builder.Sort(t.cols, t.desc)
+		out, err := builder.Table()
+		builder.Release()
+		if err != nil {
+			return err
+		}
+		return t.d.Process(out)
+	}
+
+	if builder.NRows() > 0 {
+		file, err := t.spill(builder)
+		if err != nil {
+			builder.Release()
+			closeSortRuns(runs)
+			return err
+		}
+		runs = append(runs, sortRun{file: file})
+	}
+	builder.Release()
+
+	// Merge the oldest runs until the remaining
+	// runs can be merged together at once.
+	for len(runs) > t.maxRuns {
+		file, err := t.merge(runs[:t.maxRuns])
+		if err != nil {
+			closeSortRuns(runs[t.maxRuns:])
+			return err
+		}
+		runs = append([]sortRun{{file: file}}, runs[t.maxRuns:]...)
+	}
+
+	files := make([]*execute.SpillFile, len(runs))
+	for i, run := range runs {
+		files[i] = run.file
+	}
+	out, err := execute.MergeSpillFiles(files, t.cols, t.desc)
+	if err != nil {
+		return err
+	}
+	return t.d.Process(out)
+}
+
+// mergeRuns merges the last runs together while there are
+// maxRuns of them at the same level. Each merge moves the rows
+// to the next level, so every row is merged a logarithmic number
+// of times and there are at most maxRuns-1 runs at each level.
+// All of the runs are closed if they cannot be merged.
+func (t *spillingSortTransformation) mergeRuns(runs []sortRun) ([]sortRun, error) {
+	for n := len(runs); n >= t.maxRuns; n = len(runs) {
+		level := runs[n-1].level
+		if runs[n-t.maxRuns].level != level {
+			break
+		}
+		file, err := t.merge(runs[n-t.maxRuns:])
+		if err != nil {
+			closeSortRuns(runs[:n-t.maxRuns])
+			return nil, err
+		}
+		runs = append(runs[:n-t.maxRuns], sortRun{file: file, level: level + 1})
+	}
+	return runs, nil
+}
+
+// merge merges the runs into a single run in scratch space.
+// The runs are closed once they have been merged or when merging
+// them fails, along with the partially written run.
+func (t *spillingSortTransformation) merge(runs []sortRun) (*execute.SpillFile, error) {
+	files := make([]*execute.SpillFile, len(runs))
+	for i, run := range runs {
+		files[i] = run.file
+	}
+	merged, err := execute.MergeSpillFiles(files, t.cols, t.desc)
+	if err != nil {
+		return nil, err
+	}
+	file, err := t.spiller.Create(merged.Key(), merged.Cols())
+	if err != nil {
+		merged.Done()
+		return nil, err
+	}
+	// The merged table closes the runs once it has been read.
+	if err := merged.Do(file.Write); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func (t *spillingSortTransformation) newBuilder(key flux.GroupKey, cols []flux.ColMeta) (*execute.ColListTableBuilder, error) {
+	builder := execute.NewColListTableBuilder(key, t.alloc)
+	for _, c := range cols {
+		if _, err := builder.AddCol(c); err != nil {
+			return nil, err
+		}
+	}
+	return builder, nil
+}
+
+// spill sorts the rows in the builder and writes them into scratch space.
+func (t *spillingSortTransformation) spill(builder *execute.ColListTableBuilder) (*execute.SpillFile, error) {
+	builder.Sort(t.cols, t.desc)
+	tbl, err := builder.Table()
+	if err != nil {
+		return nil, err
+	}
+	run, err := t.spiller.Create(builder.Key(), builder.Cols())
+	if err != nil {
+		tbl.Done()
+		return nil, err
+	}
+	if err := tbl.Do(run.Write); err != nil {
+		_ = run.Close()
+		return nil, err
+	}
+	return run, nil
+}
+
+func (t *spillingSortTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *spillingSortTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *spillingSortTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
@@ -0,0 +1,12 @@
+package universe
+
+import (
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+)
+
+// NewSpillingSortTransformationWithRuns is exposed so the tests can
+// write sorted runs of any size and merge them in small batches.
+func NewSpillingSortTransformationWithRuns(id execute.DatasetID, spec *SortProcedureSpec, spiller *execute.Spiller, alloc *memory.Allocator, minRunRows, maxRuns int) (execute.Transformation, execute.Dataset, error) {
+	return newSpillingSortTransformation(id, spec, spiller, alloc, minRunRows, maxRuns)
+}
@@ -1,11 +1,17 @@
 package universe_test
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/scratch"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/querytest"
 	"github.com/influxdata/flux/stdlib/universe"
 )
@@ -406,3 +412,376 @@ func TestSort_Process(t *testing.T) {
 		})
 	}
 }
+
+// newTestSpiller returns a Spiller that spills on every opportunity
+// into a temporary directory that is removed by the returned function.
+func newTestSpiller(t *testing.T, alloc *memory.Allocator) (*execute.Spiller, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "flux-spill-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return execute.NewSpiller(scratch.Dir(dir), alloc, 0), func() {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestSort_Process_Spill(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec *universe.SortProcedureSpec
+		data []flux.Table
+		want []*executetest.Table
+	}{
+		{
+			name: "one table",
+			spec: &universe.SortProcedureSpec{
+				Columns: []string{"_value"},
+			},
+			data: []flux.Table{&executetest.RowWiseTable{Table: &executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 3.0},
+					{execute.Time(2), nil},
+					{execute.Time(3), 1.0},
+					{execute.Time(4), 2.0},
+				},
+			}}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), nil},
+					{execute.Time(3), 1.0},
+					{execute.Time(4), 2.0},
+					{execute.Time(1), 3.0},
+				},
+			}},
+		},
+		{
+			name: "merged runs",
+			spec: &universe.SortProcedureSpec{
+				Columns: []string{"_value"},
+			},
+			data: []flux.Table{&executetest.RowWiseTable{Table: &executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(7)},
+					{execute.Time(2), int64(3)},
+					{execute.Time(3), int64(9)},
+					{execute.Time(4), int64(1)},
+					{execute.Time(5), nil},
+					{execute.Time(6), int64(8)},
+					{execute.Time(7), int64(2)},
+					{execute.Time(8), int64(6)},
+					{execute.Time(9), int64(4)},
+					{execute.Time(10), int64(5)},
+				},
+			}}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(5), nil},
+					{execute.Time(4), int64(1)},
+					{execute.Time(7), int64(2)},
+					{execute.Time(2), int64(3)},
+					{execute.Time(9), int64(4)},
+					{execute.Time(10), int64(5)},
+					{execute.Time(8), int64(6)},
+					{execute.Time(1), int64(7)},
+					{execute.Time(6), int64(8)},
+					{execute.Time(3), int64(9)},
+				},
+			}},
+		},
+		{
+			name: "multiple tables desc",
+			spec: &universe.SortProcedureSpec{
+				Columns: []string{"_value"},
+				Desc:    true,
+			},
+			data: []flux.Table{
+				&executetest.RowWiseTable{Table: &executetest.Table{
+					KeyCols: []string{"t1"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "t1", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), "b", "a"},
+						{execute.Time(2), "c", "a"},
+						{execute.Time(3), "a", "a"},
+					},
+				}},
+				&executetest.RowWiseTable{Table: &executetest.Table{
+					KeyCols: []string{"t1"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "t1", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), "y", "b"},
+						{execute.Time(2), "z", "b"},
+					},
+				}},
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"t1"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "t1", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), "c", "a"},
+						{execute.Time(1), "b", "a"},
+						{execute.Time(3), "a", "a"},
+					},
+				},
+				{
+					KeyCols: []string{"t1"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TString},
+						{Label: "t1", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), "z", "b"},
+						{execute.Time(1), "y", "b"},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var cleanup func()
+			defer func() {
+				if cleanup != nil {
+					cleanup()
+				}
+			}()
+			executetest.ProcessTestHelper2(
+				t,
+				tc.data,
+				tc.want,
+				nil,
+				func(id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset) {
+					var spiller *execute.Spiller
+					spiller, cleanup = newTestSpiller(t, alloc)
+					// Write a run for every buffer and merge them in pairs.
+					tr, d, err := universe.NewSpillingSortTransformationWithRuns(id, tc.spec, spiller, alloc, 1, 2)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return tr, d
+				},
+			)
+		})
+	}
+}
+
+// countingScratch is a scratch service that tracks
+// the largest number of files open at once.
+type countingScratch struct {
+	scratch.Service
+	open, peak int
+	files      []*countingFile
+
+	// failCreate makes the nth file fail to be created.
+	failCreate int
+	// failRead makes the files fail to be read back.
+	failRead bool
+	// failSeek makes the nth file fail to be rewound.
+	failSeek int
+}
+
+func (s *countingScratch) Create() (scratch.File, error) {
+	if len(s.files)+1 == s.failCreate {
+		return nil, errors.New("create failed")
+	}
+	f, err := s.Service.Create()
+	if err != nil {
+		return nil, err
+	}
+	s.open++
+	if s.open > s.peak {
+		s.peak = s.open
+	}
+	cf := &countingFile{File: f, s: s, n: len(s.files) + 1}
+	s.files = append(s.files, cf)
+	return cf, nil
+}
+
+type countingFile struct {
+	scratch.File
+	s      *countingScratch
+	n      int
+	closed int
+}
+
+func (f *countingFile) Seek(offset int64, whence int) (int64, error) {
+	if f.n == f.s.failSeek {
+		return 0, errors.New("seek failed")
+	}
+	return f.File.Seek(offset, whence)
+}
+
+func (f *countingFile) Read(p []byte) (int, error) {
+	if f.s.failRead {
+		return 0, errors.New("read failed")
+	}
+	return f.File.Read(p)
+}
+
+func (f *countingFile) Close() error {
+	f.s.open--
+	f.closed++
+	return f.File.Close()
+}
+
+func TestSort_Process_SpillOpenFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-spill-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	const n = 256
+	data := &executetest.Table{
+		ColMeta: []flux.ColMeta{
+			{Label: "_value", Type: flux.TInt},
+		},
+	}
+	for i := 0; i < n; i++ {
+		data.Data = append(data.Data, []interface{}{int64((i * 7) % n)})
+	}
+
+	alloc := executetest.UnlimitedAllocator
+	fs := &countingScratch{Service: scratch.Dir(dir)}
+	spiller := execute.NewSpiller(fs, alloc, 0)
+	tr, d, err := universe.NewSpillingSortTransformationWithRuns(
+		executetest.RandomDatasetID(),
+		&universe.SortProcedureSpec{Columns: []string{"_value"}},
+		spiller, alloc, 1, 4,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := executetest.NewDataStore()
+	d.AddTransformation(store)
+	d.SetTriggerSpec(plan.DefaultTriggerSpec)
+
+	if err := tr.Process(executetest.RandomDatasetID(), &executetest.RowWiseTable{Table: data}); err != nil {
+		t.Fatal(err)
+	}
+	tr.Finish(executetest.RandomDatasetID(), nil)
+
+	// Every row is written as its own run, and runs are merged four
+	// at a time. At most, there are three runs at each of the three
+	// upper levels, four runs being merged and the run they are merged into.
+	if fs.peak > 14 {
+		t.Errorf("expected at most 14 spill files open at once, got %d", fs.peak)
+	}
+	if fs.open != 0 {
+		t.Errorf("expected all spill files to be closed, got %d", fs.open)
+	}
+
+	got, err := executetest.TablesFromCache(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].Data) != n {
+		t.Fatalf("expected one table with %d rows", n)
+	}
+	for i, row := range got[0].Data {
+		if v := row[0].(int64); v != int64(i) {
+			t.Fatalf("unexpected value at row %d: %d", i, v)
+		}
+	}
+}
+
+func TestSort_Process_SpillMergeError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fs   countingScratch
+	}{
+		{
+			// The third file is the run the first two runs are merged into.
+			name: "create",
+			fs:   countingScratch{failCreate: 3},
+		},
+		{
+			name: "read",
+			fs:   countingScratch{failRead: true},
+		},
+		{
+			// The second run fails once the first has been rewound to be merged.
+			name: "seek",
+			fs:   countingScratch{failSeek: 2},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "flux-spill-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			data := &executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TInt},
+				},
+			}
+			for i := 0; i < 8; i++ {
+				data.Data = append(data.Data, []interface{}{int64(8 - i)})
+			}
+
+			alloc := &memory.Allocator{}
+			fs := &tc.fs
+			fs.Service = scratch.Dir(dir)
+			spiller := execute.NewSpiller(fs, alloc, 0)
+			tr, d, err := universe.NewSpillingSortTransformationWithRuns(
+				executetest.RandomDatasetID(),
+				&universe.SortProcedureSpec{Columns: []string{"_value"}},
+				spiller, alloc, 1, 2,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.AddTransformation(executetest.NewDataStore())
+			d.SetTriggerSpec(plan.DefaultTriggerSpec)
+
+			if err := tr.Process(executetest.RandomDatasetID(), &executetest.RowWiseTable{Table: data}); err == nil {
+				t.Fatal("expected error")
+			}
+			if len(fs.files) < 2 {
+				t.Fatalf("expected the runs to be spilled, got %d spill files", len(fs.files))
+			}
+			for i, f := range fs.files {
+				if f.closed != 1 {
+					t.Errorf("expected spill file %d to be closed once, got %d", i, f.closed)
+				}
+			}
+			if got := alloc.Allocated(); got != 0 {
+				t.Errorf("expected all memory to be released, got %d bytes", got)
+			}
+		})
+	}
+}
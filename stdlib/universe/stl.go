@@ -0,0 +1,338 @@
+package universe
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	fluxmemory "github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/universe/stl"
+)
+
+const STLKind = "stl"
+
+// The labels of the columns that hold the components of the decomposition.
+const (
+	stlTrendColLabel    = "trend"
+	stlSeasonalColLabel = "seasonal"
+	stlResidualColLabel = "residual"
+)
+
+type STLOpSpec struct {
+	Period         int64  `json:"period"`
+	SeasonalWindow int64  `json:"seasonal_window,omitempty"`
+	TrendWindow    int64  `json:"trend_window,omitempty"`
+	Robust         bool   `json:"robust,omitempty"`
+	Column         string `json:"column"`
+	TimeColumn     string `json:"time_column"`
+}
+
+func init() {
+	stlSignature := runtime.MustLookupBuiltinType("universe", "stl")
+	runtime.RegisterPackageValue("universe", STLKind, flux.MustValue(flux.FunctionValue(STLKind, createSTLOpSpec, stlSignature)))
+	flux.RegisterOpSpec(STLKind, newSTLOp)
+	plan.RegisterProcedureSpec(STLKind, newSTLProcedure, STLKind)
+	execute.RegisterTransformation(STLKind, createSTLTransformation)
+}
+
+func createSTLOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(STLOpSpec)
+	if p, err := args.GetRequiredInt("period"); err != nil {
+		return nil, err
+	} else if p < 2 {
+		return nil, errors.Newf(codes.Invalid, "period must be at least 2, got %d", p)
+	} else {
+		spec.Period = p
+	}
+	for _, w := range []struct {
+		name  string
+		value *int64
+	}{
+		{name: "seasonalWindow", value: &spec.SeasonalWindow},
+		{name: "trendWindow", value: &spec.TrendWindow},
+	} {
+		if v, ok, err := args.GetInt(w.name); err != nil {
+			return nil, err
+		} else if ok {
+			if v < 3 || v%2 == 0 {
+				return nil, errors.Newf(codes.Invalid, "%s must be an odd integer of at least 3, got %d", w.name, v)
+			}
+			*w.value = v
+		}
+	}
+	if r, ok, err := args.GetBool("robust"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Robust = r
+	}
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	} else {
+		spec.Column = execute.DefaultValueColLabel
+	}
+	if col, ok, err := args.GetString("timeColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.TimeColumn = col
+	} else {
+		spec.TimeColumn = execute.DefaultTimeColLabel
+	}
+	return spec, nil
+}
+
+func newSTLOp() flux.OperationSpec {
+	return new(STLOpSpec)
+}
+
+func (s *STLOpSpec) Kind() flux.OperationKind {
+	return STLKind
+}
+
+type STLProcedureSpec struct {
+	plan.DefaultCost
+	Period         int64
+	SeasonalWindow int64
+	TrendWindow    int64
+	Robust         bool
+	Column         string
+	TimeColumn     string
+}
+
+func newSTLProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*STLOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &STLProcedureSpec{
+		Period:         spec.Period,
+		SeasonalWindow: spec.SeasonalWindow,
+		TrendWindow:    spec.TrendWindow,
+		Robust:         spec.Robust,
+		Column:         spec.Column,
+		TimeColumn:     spec.TimeColumn,
+	}, nil
+}
+
+func (s *STLProcedureSpec) Kind() plan.ProcedureKind {
+	return STLKind
+}
+func (s *STLProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(STLProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *STLProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createSTLTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*STLProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewSTLTransformation(d, cache, a.Allocator(), s)
+	return t, d, nil
+}
+
+type stlTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+	alloc *fluxmemory.Allocator
+
+	params     stl.Params
+	column     string
+	timeColumn string
+}
+
+func NewSTLTransformation(d execute.Dataset, cache execute.TableBuilderCache, alloc *fluxmemory.Allocator, spec *STLProcedureSpec) *stlTransformation {
+	return &stlTransformation{
+		d:     d,
+		cache: cache,
+		alloc: alloc,
+		params: stl.Params{
+			Period:         int(spec.Period),
+			SeasonalWindow: int(spec.SeasonalWindow),
+			TrendWindow:    int(spec.TrendWindow),
+			Robust:         spec.Robust,
+		},
+		column:     spec.Column,
+		timeColumn: spec.TimeColumn,
+	}
+}
+
+// Process decomposes the column of the table into its trend, seasonal and
+// residual components. The rows are kept and a column is added for each
+// component. Tables that are shorter than two seasonal cycles get null components.
+func (t *stlTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "stl found duplicate table with key: %v", tbl.Key())
+	}
+	cols := tbl.Cols()
+	timeIdx, colIdx, err := seriesColIdxs(STLKind, cols, t.timeColumn, t.column)
+	if err != nil {
+		return err
+	}
+
+	if err := execute.AddTableCols(tbl, builder); err != nil {
+		return err
+	}
+	colMap := make([]int, len(cols), len(cols)+3)
+	for j := range colMap {
+		colMap[j] = j
+	}
+	var componentIdxs [3]int
+	for i, label := range []string{stlTrendColLabel, stlSeasonalColLabel, stlResidualColLabel} {
+		j, err := addAnalyticCol(builder, label, flux.TFloat)
+		if err != nil {
+			return err
+		}
+		componentIdxs[i] = j
+		colMap = append(colMap, -1)
+	}
+
+	_, vs, err := readSeries(STLKind, tbl, timeIdx, colIdx, func(cr flux.ColReader) error {
+		return execute.AppendMappedCols(cr, builder, colMap)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(vs) < stl.MinLen(t.params.Period) {
+		for _, j := range componentIdxs {
+			for range vs {
+				if err := builder.AppendNil(j); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	// The decomposition uses about ten slices of the length of the series.
+	size := 10 * 8 * (len(vs) + 2*t.params.Period)
+	if err := t.alloc.Account(size); err != nil {
+		return err
+	}
+	defer func() { _ = t.alloc.Account(-size) }()
+
+	d := stl.Decompose(vs, t.params)
+	for i, components := range [][]float64{d.Trend, d.Seasonal, d.Residual} {
+		for _, v := range components {
+			if err := builder.AppendFloat(componentIdxs[i], v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *stlTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *stlTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *stlTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *stlTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// seriesColIdxs returns the indexes of the time column
+// and of the numeric column of a series.
+func seriesColIdxs(kind string, cols []flux.ColMeta, timeColumn, column string) (int, int, error) {
+	timeIdx := execute.ColIdx(timeColumn, cols)
+	if timeIdx < 0 {
+		return -1, -1, errors.Newf(codes.FailedPrecondition, "cannot find time column %s", timeColumn)
+	}
+	if typ := cols[timeIdx].Type; typ != flux.TTime {
+		return -1, -1, errors.Newf(codes.FailedPrecondition, "time column %s must be of type time, got %s", timeColumn, typ.String())
+	}
+	colIdx := execute.ColIdx(column, cols)
+	if colIdx < 0 {
+		return -1, -1, errors.Newf(codes.FailedPrecondition, "cannot find column %s", column)
+	}
+	typ := cols[colIdx].Type
+	if typ != flux.TInt &&
+		typ != flux.TUInt &&
+		typ != flux.TFloat {
+		return -1, -1, errors.Newf(codes.FailedPrecondition, "%s can work only on numerical types, got %s", kind, typ.String())
+	}
+	return timeIdx, colIdx, nil
+}
+
+// readSeries reads the times and the values of a series from the table.
+// The series must not have null values and its times must be regularly spaced.
+// The function fn, if any, is called with each column reader of the table.
+func readSeries(kind string, tbl flux.Table, timeIdx, colIdx int, fn func(cr flux.ColReader) error) ([]int64, []float64, error) {
+	var (
+		times []int64
+		vs    []float64
+	)
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		if fn != nil {
+			if err := fn(cr); err != nil {
+				return err
+			}
+		}
+		ts := cr.Times(timeIdx)
+		for i := 0; i < cr.Len(); i++ {
+			if ts.IsNull(i) {
+				return errors.Newf(codes.FailedPrecondition, "%s found a null time in column %s", kind, tbl.Cols()[timeIdx].Label)
+			}
+			times = append(times, ts.Value(i))
+		}
+		switch tbl.Cols()[colIdx].Type {
+		case flux.TInt:
+			c := cr.Ints(colIdx)
+			for i := 0; i < c.Len(); i++ {
+				vs = append(vs, float64(c.Value(i)))
+			}
+			if c.NullN() > 0 {
+				return errors.Newf(codes.FailedPrecondition, "%s found a null value in column %s", kind, tbl.Cols()[colIdx].Label)
+			}
+		case flux.TUInt:
+			c := cr.UInts(colIdx)
+			for i := 0; i < c.Len(); i++ {
+				vs = append(vs, float64(c.Value(i)))
+			}
+			if c.NullN() > 0 {
+				return errors.Newf(codes.FailedPrecondition, "%s found a null value in column %s", kind, tbl.Cols()[colIdx].Label)
+			}
+		case flux.TFloat:
+			c := cr.Floats(colIdx)
+			vs = append(vs, c.Float64Values()...)
+			if c.NullN() > 0 {
+				return errors.Newf(codes.FailedPrecondition, "%s found a null value in column %s", kind, tbl.Cols()[colIdx].Label)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	for i := 2; i < len(times); i++ {
+		if times[i]-times[i-1] != times[1]-times[0] {
+			return nil, nil, errors.Newf(codes.FailedPrecondition, "%s requires regularly spaced data in column %s", kind, tbl.Cols()[timeIdx].Label)
+		}
+	}
+	if len(times) > 1 && times[1] <= times[0] {
+		return nil, nil, errors.Newf(codes.FailedPrecondition, "%s requires regularly spaced data in column %s", kind, tbl.Cols()[timeIdx].Label)
+	}
+	return times, vs, nil
+}
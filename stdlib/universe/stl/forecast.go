@@ -0,0 +1,145 @@
+package stl
+
+import (
+	"math"
+
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux/internal/mutable"
+	"github.com/influxdata/flux/stdlib/universe/holt_winters"
+)
+
+const (
+	// Epsilon value for the minimization of the sum of squared errors.
+	forecastEpsilon = 1.0e-6
+)
+
+// The initial guesses for the smoothing parameters of the level and the slope.
+var (
+	alphaGuesses = []float64{0.1, 0.5, 0.9}
+	betaGuesses  = []float64{0.1, 0.5}
+)
+
+// Forecast holds the predicted values of a series and their prediction intervals.
+type Forecast struct {
+	Mean  []float64
+	Lower []float64
+	Upper []float64
+}
+
+// MinForecastLen returns the minimum length of a series that
+// can be forecast with the given period.
+// A period lower than 2 forecasts a series without seasonality.
+func MinForecastLen(period int) int {
+	if period < 2 {
+		return 3
+	}
+	return MinLen(period)
+}
+
+// Predict forecasts the next n values of a regularly spaced series.
+//
+// When the period is at least 2, the seasonal component is extracted with
+// STL and forecast by repeating its last cycle. The seasonally adjusted series
+// is forecast with Holt's linear trend method, which gives the prediction intervals
+// for the given confidence level. The uncertainty of the seasonal component is ignored.
+//
+// Predict returns nil if the series is shorter than MinForecastLen(period).
+func Predict(ys []float64, period, n int, level float64, alloc memory.Allocator) *Forecast {
+	if len(ys) < MinForecastLen(period) {
+		return nil
+	}
+
+	adjusted := ys
+	var seasonal []float64
+	if period >= 2 {
+		d := Decompose(ys, Params{Period: period})
+		seasonal = d.Seasonal
+		adjusted = make([]float64, len(ys))
+		for i, y := range ys {
+			adjusted[i] = y - seasonal[i]
+		}
+	}
+
+	h := &holt{ys: adjusted}
+	alpha, beta := h.fit(alloc)
+	l, b, sse := h.run(alpha, beta)
+	sigma2 := sse / float64(len(ys)-1)
+	z := math.Sqrt2 * math.Erfinv(level)
+
+	f := &Forecast{
+		Mean:  make([]float64, n),
+		Lower: make([]float64, n),
+		Upper: make([]float64, n),
+	}
+	for i := 0; i < n; i++ {
+		step := float64(i + 1)
+		mean := l + step*b
+		if seasonal != nil {
+			mean += seasonal[len(ys)-period+i%period]
+		}
+		// The variance of the forecast errors of the additive error and trend model.
+		variance := sigma2 * (1 + (step-1)*(alpha*alpha+alpha*beta*step+beta*beta*step*(2*step-1)/6))
+		width := z * math.Sqrt(variance)
+		f.Mean[i] = mean
+		f.Lower[i] = mean - width
+		f.Upper[i] = mean + width
+	}
+	return f
+}
+
+// holt is Holt's linear trend method.
+type holt struct {
+	ys []float64
+}
+
+// fit returns the smoothing parameters of the level and the slope
+// that minimize the sum of the squared one-step errors.
+// The smoothing parameter of the slope is relative to alpha.
+func (h *holt) fit(alloc memory.Allocator) (alpha, beta float64) {
+	optim := holt_winters.NewOptimizer(alloc)
+	params := mutable.NewFloat64Array(alloc)
+	defer params.Release()
+	params.Resize(2)
+
+	minSSE := math.Inf(1)
+	for _, a := range alphaGuesses {
+		for _, b := range betaGuesses {
+			params.Set(0, a)
+			params.Set(1, b)
+			sse, best := optim.Optimize(h.sse, params, forecastEpsilon, 1)
+			if sse < minSSE {
+				minSSE = sse
+				alpha, beta = constrain(best.Value(0)), constrain(best.Value(1))
+			}
+			best.Release()
+		}
+	}
+	return alpha, alpha * beta
+}
+
+func (h *holt) sse(params *mutable.Float64Array) float64 {
+	alpha := constrain(params.Value(0))
+	_, _, sse := h.run(alpha, alpha*constrain(params.Value(1)))
+	if math.IsNaN(sse) {
+		return math.Inf(1)
+	}
+	return sse
+}
+
+// run smooths the series and returns the final level and slope
+// together with the sum of the squared one-step errors.
+func (h *holt) run(alpha, beta float64) (level, slope, sse float64) {
+	level, slope = h.ys[0], h.ys[1]-h.ys[0]
+	for _, y := range h.ys[1:] {
+		e := y - (level + slope)
+		sse += e * e
+		level += slope + alpha*e
+		slope += beta * e
+	}
+	return level, slope, sse
+}
+
+// constrain limits a smoothing parameter to the range [0, 1].
+func constrain(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
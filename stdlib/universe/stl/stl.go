@@ -0,0 +1,366 @@
+// Package stl implements the seasonal-trend decomposition procedure
+// based on loess (STL) and forecasting from the decomposed components.
+//
+// The decomposition follows the algorithm described in
+// Cleveland, R. B., Cleveland, W. S., McRae, J. E. and Terpenning, I. (1990)
+// "STL: A Seasonal-Trend Decomposition Procedure Based on Loess".
+package stl
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultSeasonalWindow is the default span, in seasonal cycles,
+	// of the loess smoothing of the cycle-subseries.
+	DefaultSeasonalWindow = 7
+
+	// The degrees of the locally fitted polynomials.
+	seasonalDegree = 1
+	trendDegree    = 1
+	lowPassDegree  = 1
+
+	// The number of passes of the inner and outer loops.
+	innerIterations       = 2
+	robustInnerIterations = 1
+	robustOuterIterations = 15
+)
+
+// Params configures a decomposition.
+type Params struct {
+	// Period is the number of observations in a seasonal cycle.
+	Period int
+	// SeasonalWindow is the span of the loess smoothing of the
+	// cycle-subseries. It defaults to DefaultSeasonalWindow.
+	SeasonalWindow int
+	// TrendWindow is the span of the loess smoothing of the trend.
+	// It defaults to a span derived from the period and the seasonal window.
+	TrendWindow int
+	// Robust reports whether the decomposition is made
+	// resistant to outliers with robustness weights.
+	Robust bool
+}
+
+// Decomposition holds the components of a series.
+// The components add up to the series.
+type Decomposition struct {
+	Trend    []float64
+	Seasonal []float64
+	Residual []float64
+}
+
+// MinLen returns the minimum length of a series that can be
+// decomposed with the given period.
+func MinLen(period int) int {
+	return 2 * period
+}
+
+// Decompose decomposes the series into its trend, seasonal and residual components.
+// The series must be regularly spaced and must contain at least MinLen(p.Period) values.
+func Decompose(ys []float64, p Params) *Decomposition {
+	n := len(ys)
+	np := max(2, p.Period)
+	ns := p.SeasonalWindow
+	if ns <= 0 {
+		ns = DefaultSeasonalWindow
+	}
+	ns = oddAtLeast3(ns)
+	nt := p.TrendWindow
+	if nt <= 0 {
+		nt = int(1.5*float64(np)/(1-1.5/float64(ns)) + 0.5)
+	}
+	nt = oddAtLeast3(nt)
+	nl := oddAtLeast3(np)
+
+	inner, outer := innerIterations, 0
+	if p.Robust {
+		inner, outer = robustInnerIterations, robustOuterIterations
+	}
+
+	s := &stl{
+		ys: ys,
+		np: np,
+		ns: ns,
+		nt: nt,
+		nl: nl,
+
+		trend:  make([]float64, n),
+		season: make([]float64, n),
+		w:      make([]float64, n+2*np),
+		work1:  make([]float64, n+2*np),
+		work2:  make([]float64, n+2*np),
+		work3:  make([]float64, n+2*np),
+		work4:  make([]float64, n+2*np),
+	}
+	var rw []float64
+	for k := 0; ; {
+		s.step(inner, rw)
+		k++
+		if k > outer {
+			break
+		}
+		if rw == nil {
+			rw = make([]float64, n)
+		}
+		s.robustnessWeights(rw)
+	}
+
+	d := &Decomposition{
+		Trend:    s.trend,
+		Seasonal: s.season,
+		Residual: make([]float64, n),
+	}
+	for i, y := range ys {
+		d.Residual[i] = y - d.Trend[i] - d.Seasonal[i]
+	}
+	return d
+}
+
+type stl struct {
+	ys             []float64
+	np, ns, nt, nl int
+
+	trend, season []float64
+	// w holds the weights of a local fit.
+	w                          []float64
+	work1, work2, work3, work4 []float64
+}
+
+// step runs the inner loop, which updates the seasonal
+// and trend components of the decomposition.
+func (s *stl) step(iterations int, rw []float64) {
+	n, np := len(s.ys), s.np
+	for it := 0; it < iterations; it++ {
+		// Detrend the series and smooth its cycle-subseries.
+		detrended := s.work1[:n]
+		for i, y := range s.ys {
+			detrended[i] = y - s.trend[i]
+		}
+		cycle := s.work2[:n+2*np]
+		s.smoothSubseries(detrended, rw, cycle)
+
+		// Low-pass filter the smoothed cycle-subseries.
+		lowPass := s.work3[:n]
+		s.lowPassFilter(cycle, lowPass)
+		smoothed := s.work1[:n]
+		s.smooth(lowPass, s.nl, lowPassDegree, nil, smoothed)
+
+		// Detrend the smoothed cycle-subseries to
+		// get the seasonal component.
+		for i := range s.season {
+			s.season[i] = cycle[np+i] - smoothed[i]
+		}
+
+		// Deseasonalize the series and smooth it
+		// to get the trend component.
+		deseasonalized := s.work1[:n]
+		for i, y := range s.ys {
+			deseasonalized[i] = y - s.season[i]
+		}
+		s.smooth(deseasonalized, s.nt, trendDegree, rw, s.trend)
+	}
+}
+
+// smoothSubseries smooths each cycle-subseries of ys and extends it
+// by one period at each end. The result has len(ys)+2*np values.
+func (s *stl) smoothSubseries(ys, rw, cycle []float64) {
+	n, np := len(ys), s.np
+	var sub, subRW []float64
+	for j := 0; j < np; j++ {
+		k := (n-1-j)/np + 1
+		sub = s.work4[:k]
+		for i := range sub {
+			sub[i] = ys[i*np+j]
+		}
+		if rw != nil {
+			subRW = s.work3[:k]
+			for i := range subRW {
+				subRW[i] = rw[i*np+j]
+			}
+		}
+
+		smoothed := make([]float64, k+2)
+		s.smooth(sub, s.ns, seasonalDegree, subRW, smoothed[1:k+1])
+
+		right := min(s.ns, k) - 1
+		if v, ok := s.estimate(sub, s.ns, seasonalDegree, -1, 0, right, subRW); ok {
+			smoothed[0] = v
+		} else {
+			smoothed[0] = smoothed[1]
+		}
+		left := max(0, k-s.ns)
+		if v, ok := s.estimate(sub, s.ns, seasonalDegree, float64(k), left, k-1, subRW); ok {
+			smoothed[k+1] = v
+		} else {
+			smoothed[k+1] = smoothed[k]
+		}
+
+		for m, v := range smoothed {
+			cycle[m*np+j] = v
+		}
+	}
+}
+
+// lowPassFilter applies moving averages of lengths np, np and 3
+// to the cycle, which has 2*np more values than out.
+func (s *stl) lowPassFilter(cycle, out []float64) {
+	n, np := len(out), s.np
+	a := make([]float64, n+np+1)
+	movingAverage(cycle, np, a)
+	b := make([]float64, n+2)
+	movingAverage(a, np, b)
+	movingAverage(b, 3, out)
+}
+
+func movingAverage(xs []float64, length int, out []float64) {
+	v := 0.0
+	for _, x := range xs[:length] {
+		v += x
+	}
+	l := float64(length)
+	out[0] = v / l
+	for j := 1; j < len(xs)-length+1; j++ {
+		v += xs[j+length-1] - xs[j-1]
+		out[j] = v / l
+	}
+}
+
+// smooth computes the loess smoothing of ys at each position.
+func (s *stl) smooth(ys []float64, window, degree int, rw, out []float64) {
+	n := len(ys)
+	if n < 2 {
+		out[0] = ys[0]
+		return
+	}
+	left, right := 0, n-1
+	if window < n {
+		right = window - 1
+	}
+	half := (window + 1) / 2
+	for i := 0; i < n; i++ {
+		if window < n && i+1 > half && right != n-1 {
+			left++
+			right++
+		}
+		if v, ok := s.estimate(ys, window, degree, float64(i), left, right, rw); ok {
+			out[i] = v
+		} else {
+			out[i] = ys[i]
+		}
+	}
+}
+
+// estimate computes the loess fit at position x from the values
+// between the positions left and right included. It reports false
+// when all of the values have a zero weight.
+func (s *stl) estimate(ys []float64, window, degree int, x float64, left, right int, rw []float64) (float64, bool) {
+	n := len(ys)
+	w := s.w
+	h := math.Max(x-float64(left), float64(right)-x)
+	if window > n {
+		h += float64((window - n) / 2)
+	}
+	h9, h1 := 0.999*h, 0.001*h
+
+	a := 0.0
+	for j := left; j <= right; j++ {
+		w[j] = 0
+		r := math.Abs(float64(j) - x)
+		if r > h9 {
+			continue
+		}
+		if r <= h1 {
+			w[j] = 1
+		} else {
+			q := r / h
+			q = 1 - q*q*q
+			w[j] = q * q * q
+		}
+		if rw != nil {
+			w[j] *= rw[j]
+		}
+		a += w[j]
+	}
+	if a <= 0 {
+		return 0, false
+	}
+	for j := left; j <= right; j++ {
+		w[j] /= a
+	}
+
+	if h > 0 && degree > 0 {
+		// Adjust the weights to fit a line instead of a constant.
+		a = 0
+		for j := left; j <= right; j++ {
+			a += w[j] * float64(j)
+		}
+		b := x - a
+		c := 0.0
+		for j := left; j <= right; j++ {
+			d := float64(j) - a
+			c += w[j] * d * d
+		}
+		if math.Sqrt(c) > 0.001*float64(n-1) {
+			b /= c
+			for j := left; j <= right; j++ {
+				w[j] *= b*(float64(j)-a) + 1
+			}
+		}
+	}
+
+	v := 0.0
+	for j := left; j <= right; j++ {
+		v += w[j] * ys[j]
+	}
+	return v, true
+}
+
+// robustnessWeights computes the bisquare weights of the
+// residuals of the current decomposition.
+func (s *stl) robustnessWeights(rw []float64) {
+	for i, y := range s.ys {
+		rw[i] = math.Abs(y - s.trend[i] - s.season[i])
+	}
+	sorted := append([]float64(nil), rw...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	median := (sorted[n/2] + sorted[(n-1)/2]) / 2
+
+	h := 6 * median
+	c9, c1 := 0.999*h, 0.001*h
+	for i, r := range rw {
+		switch {
+		case r <= c1:
+			rw[i] = 1
+		case r <= c9:
+			q := r / h
+			q = 1 - q*q
+			rw[i] = q * q
+		default:
+			rw[i] = 0
+		}
+	}
+}
+
+func oddAtLeast3(n int) int {
+	n = max(3, n)
+	if n%2 == 0 {
+		n++
+	}
+	return n
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
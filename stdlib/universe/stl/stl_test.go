@@ -0,0 +1,113 @@
+package stl_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux/stdlib/universe/stl"
+)
+
+const tolerance = 1e-9
+
+var pattern = []float64{1, -2, 3, -2}
+
+// seasonalSeries returns a series with a linear trend and a seasonal pattern.
+func seasonalSeries(n int) []float64 {
+	ys := make([]float64, n)
+	for i := range ys {
+		ys[i] = 10 + 0.5*float64(i) + pattern[i%len(pattern)]
+	}
+	return ys
+}
+
+func TestDecompose(t *testing.T) {
+	ys := seasonalSeries(48)
+	d := stl.Decompose(ys, stl.Params{Period: len(pattern)})
+	for i, y := range ys {
+		if got, want := d.Trend[i], 10+0.5*float64(i); math.Abs(got-want) > tolerance {
+			t.Errorf("unexpected trend at %d: want %v, got %v", i, want, got)
+		}
+		if got, want := d.Seasonal[i], pattern[i%len(pattern)]; math.Abs(got-want) > tolerance {
+			t.Errorf("unexpected seasonal at %d: want %v, got %v", i, want, got)
+		}
+		if got := d.Trend[i] + d.Seasonal[i] + d.Residual[i]; math.Abs(got-y) > tolerance {
+			t.Errorf("components do not add up at %d: want %v, got %v", i, y, got)
+		}
+	}
+}
+
+func TestDecompose_Robust(t *testing.T) {
+	const outlier = 20
+	ys := seasonalSeries(48)
+	// The robustness weights are relative to the median residual,
+	// so the series needs some noise.
+	for i := range ys {
+		ys[i] += 0.2 * math.Sin(1.7*float64(i))
+	}
+	ys[outlier] += 50
+
+	// The outlier is absorbed by the residual when the decomposition is robust.
+	d := stl.Decompose(ys, stl.Params{Period: len(pattern), Robust: true})
+	if got := d.Residual[outlier]; math.Abs(got-50) > 0.5 {
+		t.Errorf("unexpected residual of the outlier: want 50, got %v", got)
+	}
+	for i, r := range d.Residual {
+		if i != outlier && math.Abs(r) > 0.5 {
+			t.Errorf("unexpected residual at %d: %v", i, r)
+		}
+	}
+}
+
+func TestPredict(t *testing.T) {
+	ys := seasonalSeries(48)
+	f := stl.Predict(ys, len(pattern), 6, 0.95, memory.NewGoAllocator())
+	if f == nil {
+		t.Fatal("expected a forecast")
+	}
+	for i, got := range f.Mean {
+		want := seasonalSeries(len(ys) + 6)[len(ys)+i]
+		if math.Abs(got-want) > 1e-6 {
+			t.Errorf("unexpected forecast at %d: want %v, got %v", i, want, got)
+		}
+		// The series is exactly explained by the model.
+		if f.Upper[i]-f.Lower[i] > 1e-6 {
+			t.Errorf("unexpected prediction interval at %d: [%v, %v]", i, f.Lower[i], f.Upper[i])
+		}
+	}
+}
+
+func TestPredict_Intervals(t *testing.T) {
+	ys := []float64{1, 3, 2, 4, 3, 5, 4, 6}
+	f := stl.Predict(ys, 0, 3, 0.95, memory.NewGoAllocator())
+	if f == nil {
+		t.Fatal("expected a forecast")
+	}
+	width := 0.0
+	for i := range f.Mean {
+		if !(f.Lower[i] < f.Mean[i] && f.Mean[i] < f.Upper[i]) {
+			t.Errorf("forecast %v at %d is not within its prediction interval [%v, %v]", f.Mean[i], i, f.Lower[i], f.Upper[i])
+		}
+		// The prediction intervals widen with the horizon.
+		if w := f.Upper[i] - f.Lower[i]; w <= width {
+			t.Errorf("prediction interval at %d is not wider than the previous one: %v <= %v", i, w, width)
+		} else {
+			width = w
+		}
+	}
+
+	// A lower confidence level gives a narrower interval.
+	narrow := stl.Predict(ys, 0, 3, 0.5, memory.NewGoAllocator())
+	if got := narrow.Upper[0] - narrow.Lower[0]; got >= f.Upper[0]-f.Lower[0] {
+		t.Errorf("unexpected prediction interval width for a lower level: %v", got)
+	}
+}
+
+func TestPredict_TooShort(t *testing.T) {
+	if f := stl.Predict(seasonalSeries(7), len(pattern), 2, 0.95, memory.NewGoAllocator()); f != nil {
+		t.Errorf("expected no forecast for less than two seasonal cycles, got %v", f.Mean)
+	}
+	if f := stl.Predict([]float64{1, 2}, 0, 2, 0.95, memory.NewGoAllocator()); f != nil {
+		t.Errorf("expected no forecast for two values, got %v", f.Mean)
+	}
+}
@@ -0,0 +1,354 @@
+package universe_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestSTL_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "stl defaults",
+			Raw:  `from(bucket:"mydb") |> stl(period: 24)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "stl1",
+						Spec: &universe.STLOpSpec{
+							Period:     24,
+							Column:     execute.DefaultValueColLabel,
+							TimeColumn: execute.DefaultTimeColLabel,
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "stl1"},
+				},
+			},
+		},
+		{
+			Name: "stl no defaults",
+			Raw:  `from(bucket:"mydb") |> stl(period: 7, seasonalWindow: 13, trendWindow: 11, robust: true, column: "v", timeColumn: "t")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "stl1",
+						Spec: &universe.STLOpSpec{
+							Period:         7,
+							SeasonalWindow: 13,
+							TrendWindow:    11,
+							Robust:         true,
+							Column:         "v",
+							TimeColumn:     "t",
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "stl1"},
+				},
+			},
+		},
+		{
+			Name:    "period too small",
+			Raw:     `from(bucket:"mydb") |> stl(period: 1)`,
+			WantErr: true,
+		},
+		{
+			Name:    "even seasonal window",
+			Raw:     `from(bucket:"mydb") |> stl(period: 4, seasonalWindow: 8)`,
+			WantErr: true,
+		},
+		{
+			Name:    "trend window too small",
+			Raw:     `from(bucket:"mydb") |> stl(period: 4, trendWindow: 1)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestSTL_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"stl","kind":"stl","spec":{"period":24,"seasonal_window":13,"robust":true,"column":"v","time_column":"t"}}`)
+	op := &flux.Operation{
+		ID: "stl",
+		Spec: &universe.STLOpSpec{
+			Period:         24,
+			SeasonalWindow: 13,
+			Robust:         true,
+			Column:         "v",
+			TimeColumn:     "t",
+		},
+	}
+
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestSTL_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := universe.NewSTLTransformation(
+			d,
+			c,
+			&memory.Allocator{},
+			&universe.STLProcedureSpec{},
+		)
+		return s
+	})
+}
+
+func TestSTL_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *universe.STLProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "seasonal",
+			spec: &universe.STLProcedureSpec{
+				Period:     4,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "t0", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 11.0, "a"},
+					{execute.Time(10), 8.5, "a"},
+					{execute.Time(20), 14.0, "a"},
+					{execute.Time(30), 9.5, "a"},
+					{execute.Time(40), 13.0, "a"},
+					{execute.Time(50), 10.75, "a"},
+					{execute.Time(60), 16.0, "a"},
+					{execute.Time(70), 11.5, "a"},
+					{execute.Time(80), 15.0, "a"},
+					{execute.Time(90), 12.0, "a"},
+					{execute.Time(100), 18.0, "a"},
+					{execute.Time(110), 13.5, "a"},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "t0", Type: flux.TString},
+					{Label: "trend", Type: flux.TFloat},
+					{Label: "seasonal", Type: flux.TFloat},
+					{Label: "residual", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 11.0, "a", 9.936797980422044, 1.0069080910740722, 0.056293928503883484},
+					{execute.Time(10), 8.5, "a", 10.468287626517597, -1.86720912690287, -0.10107849961472692},
+					{execute.Time(20), 14.0, "a", 10.9982927306858, 2.965908109463769, 0.035799159850431916},
+					{execute.Time(30), 9.5, "a", 11.526498425757362, -2.0414245664888457, 0.014926140731483795},
+					{execute.Time(40), 13.0, "a", 12.057378360794274, 1.0166300025553303, -0.0740083633496047},
+					{execute.Time(50), 10.75, "a", 12.54958440303833, -2.0467690632352653, 0.24718466019693608},
+					{execute.Time(60), 16.0, "a", 13.023759659836621, 3.0379893928006134, -0.0617490526372344},
+					{execute.Time(70), 11.5, "a", 13.473652399052217, -1.9389518552329823, -0.034700543819234264},
+					{execute.Time(80), 15.0, "a", 13.924212258208371, 1.0431467590333698, 0.03264098275825922},
+					{execute.Time(90), 12.0, "a", 14.378795124791257, -2.2686124142812427, -0.11018271051001394},
+					{execute.Time(100), 18.0, "a", 14.834708402968094, 3.125458533662577, 0.03983306336932957},
+					{execute.Time(110), 13.5, "a", 15.293673735017713, -1.8267041606185566, 0.033030425600844016},
+				},
+			}},
+		},
+		{
+			name: "ints",
+			spec: &universe.STLProcedureSpec{
+				Period:     2,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), int64(2)},
+					{execute.Time(1), int64(0)},
+					{execute.Time(2), int64(2)},
+					{execute.Time(3), int64(0)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+					{Label: "trend", Type: flux.TFloat},
+					{Label: "seasonal", Type: flux.TFloat},
+					{Label: "residual", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), int64(2), 1.0, 0.9999999999999999, 1.1102230246251565e-16},
+					{execute.Time(1), int64(0), 1.0, -1.0, 0.0},
+					{execute.Time(2), int64(2), 1.0, 1.0, 0.0},
+					{execute.Time(3), int64(0), 1.0, -1.0, 0.0},
+				},
+			}},
+		},
+		{
+			name: "too short",
+			spec: &universe.STLProcedureSpec{
+				Period:     4,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(10), 2.0},
+					{execute.Time(20), 3.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "trend", Type: flux.TFloat},
+					{Label: "seasonal", Type: flux.TFloat},
+					{Label: "residual", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0, nil, nil, nil},
+					{execute.Time(10), 2.0, nil, nil, nil},
+					{execute.Time(20), 3.0, nil, nil, nil},
+				},
+			}},
+		},
+		{
+			name: "irregular times",
+			spec: &universe.STLProcedureSpec{
+				Period:     2,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(10), 2.0},
+					{execute.Time(30), 3.0},
+					{execute.Time(40), 4.0},
+				},
+			}},
+			wantErr: errors.New("stl requires regularly spaced data in column _time"),
+		},
+		{
+			name: "null value",
+			spec: &universe.STLProcedureSpec{
+				Period:     2,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0},
+					{execute.Time(10), nil},
+					{execute.Time(20), 3.0},
+					{execute.Time(30), 4.0},
+				},
+			}},
+			wantErr: errors.New("stl found a null value in column _value"),
+		},
+		{
+			name: "non numeric",
+			spec: &universe.STLProcedureSpec{
+				Period:     2,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), "a"},
+				},
+			}},
+			wantErr: errors.New("stl can work only on numerical types, got string"),
+		},
+		{
+			name: "existing column",
+			spec: &universe.STLProcedureSpec{
+				Period:     2,
+				Column:     "_value",
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "trend", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0, 1.0},
+				},
+			}},
+			wantErr: errors.New(`column "trend" already exists`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			alloc := &memory.Allocator{}
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewSTLTransformation(d, c, alloc, tc.spec)
+				},
+			)
+
+			if m := alloc.Allocated(); m != 0 {
+				t.Errorf("stl is using memory after finishing: %d", m)
+			}
+		})
+	}
+}
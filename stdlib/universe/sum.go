@@ -69,6 +69,9 @@ func (s *SumProcedureSpec) Copy() plan.ProcedureSpec {
 	}
 }
 
+// PartitionParallel implements plan.PartitionParallelProcedureSpec
+func (s *SumProcedureSpec) PartitionParallel() {}
+
 // TriggerSpec implements plan.TriggerAwareProcedureSpec
 func (s *SumProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
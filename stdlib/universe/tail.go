@@ -87,6 +87,11 @@ func (s *TailProcedureSpec) TriggerSpec() plan.TriggerSpec {
 	return plan.NarrowTransformationTriggerSpec{}
 }
 
+// Cost estimates that at most N rows are kept from each table.
+func (s *TailProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	return limitCost(s.N, inStats)
+}
+
 func createTailTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
 	s, ok := spec.(*TailProcedureSpec)
 	if !ok {
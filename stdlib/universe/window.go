@@ -17,13 +17,14 @@ import (
 const WindowKind = "window"
 
 type WindowOpSpec struct {
-	Every       flux.Duration `json:"every"`
-	Period      flux.Duration `json:"period"`
-	Offset      flux.Duration `json:"offset"`
-	TimeColumn  string        `json:"timeColumn"`
-	StopColumn  string        `json:"stopColumn"`
-	StartColumn string        `json:"startColumn"`
-	CreateEmpty bool          `json:"createEmpty"`
+	Every       flux.Duration      `json:"every"`
+	Period      flux.Duration      `json:"period"`
+	Offset      flux.Duration      `json:"offset"`
+	Location    *interval.Location `json:"location,omitempty"`
+	TimeColumn  string             `json:"timeColumn"`
+	StopColumn  string             `json:"stopColumn"`
+	StartColumn string             `json:"startColumn"`
+	CreateEmpty bool               `json:"createEmpty"`
 }
 
 var infinityVar = values.NewDuration(values.ConvertDurationNsecs(math.MaxInt64))
@@ -79,6 +80,15 @@ func CreateWindowOpSpec(args flux.Arguments, a *flux.Administration) (flux.Opera
 	} else if ok {
 		spec.Offset = offset
 	}
+	if location, ok, err := args.GetObject("location"); err != nil {
+		return nil, err
+	} else if ok {
+		loc, err := execute.LocationFromValue(location)
+		if err != nil {
+			return nil, err
+		}
+		spec.Location = &loc
+	}
 
 	if !everySet && !periodSet {
 		const docURL = "https://v2.docs.influxdata.com/v2.0/reference/flux/stdlib/built-in/transformations/window/"
@@ -150,11 +160,17 @@ func newWindowProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.Pro
 	if !ok {
 		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
 	}
+	// Windows without a location follow the location option of the query.
+	location := pa.Location()
+	if s.Location != nil {
+		location = *s.Location
+	}
 	p := &WindowProcedureSpec{
 		Window: plan.WindowSpec{
-			Every:  s.Every,
-			Period: s.Period,
-			Offset: s.Offset,
+			Every:    s.Every,
+			Period:   s.Period,
+			Offset:   s.Offset,
+			Location: location,
 		},
 		TimeColumn:  s.TimeColumn,
 		StartColumn: s.StartColumn,
@@ -194,10 +210,11 @@ func createWindowTransformation(id execute.DatasetID, mode execute.AccumulationM
 
 	newBounds := interval.NewBounds(bounds.Start, bounds.Stop)
 
-	w, err := interval.NewWindow(
+	w, err := interval.NewWindowInLocation(
 		s.Window.Every,
 		s.Window.Period,
 		s.Window.Offset,
+		s.Window.Location,
 	)
 
 	if err != nil {
@@ -441,7 +458,12 @@ func (WindowTriggerPhysicalRule) Pattern() plan.Pattern {
 // Rewrite modifies a window's trigger spec so long as it doesn't have any
 // window descendents that occur earlier in the plan and as long as none
 // of its descendents merge multiple streams together like union and join.
+// The windows of a continuous query keep the default trigger so that
+// they are emitted as the watermark passes them.
 func (WindowTriggerPhysicalRule) Rewrite(ctx context.Context, window plan.Node) (plan.Node, bool, error) {
+	if execute.IsContinuousExecution(ctx) {
+		return window, false, nil
+	}
 	// This rule's pattern ensures us only one predecessor
 	if !hasValidPredecessors(window.Predecessors()[0]) {
 		return window, false, nil